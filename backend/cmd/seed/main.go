@@ -288,10 +288,10 @@ func main() {
 	device := models.Device{
 		SchoolID:    school1.ID,
 		DeviceCode:  "ESP32-SMPN1-001",
-		APIKey:      "sk_test_device_api_key_12345",
 		Description: "RFID Reader - Gerbang Utama",
 		IsActive:    true,
 	}
+	device.SetAPIKey("sk_test_device_api_key_12345")
 	if err := db.FirstOrCreate(&device, models.Device{DeviceCode: "ESP32-SMPN1-001"}).Error; err != nil {
 		log.Fatalf("Failed to create device: %v", err)
 	}
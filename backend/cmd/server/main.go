@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -17,6 +18,7 @@ import (
 	"github.com/school-management/backend/internal/domain/models"
 	"github.com/school-management/backend/internal/middleware"
 	"github.com/school-management/backend/internal/modules/attendance"
+	"github.com/school-management/backend/internal/modules/auditlog"
 	"github.com/school-management/backend/internal/modules/auth"
 	"github.com/school-management/backend/internal/modules/bk"
 	"github.com/school-management/backend/internal/modules/device"
@@ -24,17 +26,24 @@ import (
 	"github.com/school-management/backend/internal/modules/grade"
 	"github.com/school-management/backend/internal/modules/homeroom"
 	importmodule "github.com/school-management/backend/internal/modules/import"
+	"github.com/school-management/backend/internal/modules/leave"
 	"github.com/school-management/backend/internal/modules/notification"
 	"github.com/school-management/backend/internal/modules/parent"
 	"github.com/school-management/backend/internal/modules/publicdisplay"
 	"github.com/school-management/backend/internal/modules/realtime"
+	"github.com/school-management/backend/internal/modules/reportcard"
 	"github.com/school-management/backend/internal/modules/schedule"
 	"github.com/school-management/backend/internal/modules/school"
 	"github.com/school-management/backend/internal/modules/settings"
 	"github.com/school-management/backend/internal/modules/student"
 	"github.com/school-management/backend/internal/modules/tenant"
+	"github.com/school-management/backend/internal/policy"
+	"github.com/school-management/backend/internal/shared/audit"
 	"github.com/school-management/backend/internal/shared/database"
 	"github.com/school-management/backend/internal/shared/fcm"
+	"github.com/school-management/backend/internal/shared/messaging"
+	"github.com/school-management/backend/internal/shared/outbox"
+	"github.com/school-management/backend/internal/shared/ratelimit"
 	"github.com/school-management/backend/internal/shared/redis"
 )
 
@@ -66,6 +75,11 @@ func main() {
 	}
 	log.Println("Database connected successfully")
 
+	// Record changes to school data made by signed-in users in the audit trail
+	if err := db.Use(audit.Plugin{}); err != nil {
+		log.Fatalf("Failed to register audit plugin: %v", err)
+	}
+
 	// Run migrations
 	if err := database.Migrate(db); err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
@@ -80,10 +94,19 @@ func main() {
 	log.Println("Redis connected successfully")
 
 	// Create Fiber app
-	app := fiber.New(fiber.Config{
+	// Behind a load balancer the client IP, which logins and devices are throttled by, comes from
+	// the proxy header, but only for requests from the trusted proxies
+	fiberConfig := fiber.Config{
 		AppName:      "School Management SaaS API",
 		ErrorHandler: customErrorHandler,
-	})
+	}
+	if len(cfg.Server.TrustedProxies) > 0 {
+		fiberConfig.ProxyHeader = cfg.Server.ProxyHeader
+		fiberConfig.EnableTrustedProxyCheck = true
+		fiberConfig.TrustedProxies = cfg.Server.TrustedProxies
+		fiberConfig.EnableIPValidation = true
+	}
+	app := fiber.New(fiberConfig)
 
 	// Global middleware
 	app.Use(recover.New())
@@ -104,6 +127,9 @@ func main() {
 		AllowCredentials: true,
 	}))
 
+	// Client IP and endpoint of the request for audit log entries
+	app.Use(middleware.AuditMiddleware())
+
 	// Health check endpoint
 	app.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -119,13 +145,29 @@ func main() {
 	jwtManager := auth.NewJWTManager(cfg.JWT)
 
 	// Initialize Auth Module
+	// Failed logins are counted per username and per IP address in Redis
 	authRepo := auth.NewRepository(db)
-	authService := auth.NewService(authRepo, jwtManager)
+	loginThrottle := auth.NewLoginThrottle(redisClient, cfg.Login, authRepo) // Locks are recorded in the audit trail
+	// Password reset codes go out by email over SMTP, or to the log (redacted) in development
+	messageSender, err := messaging.NewSender(cfg.Messaging, cfg.Server.Environment)
+	if err != nil {
+		log.Fatalf("Failed to configure messaging: %v", err)
+	}
+	authService := auth.NewService(authRepo, jwtManager, loginThrottle, messageSender, cfg.Login, cfg.TwoFactor)
 	authHandler := auth.NewHandler(authService)
 
-	// Register public auth routes (login, refresh, logout)
+	// Register public auth routes (login, refresh, logout, forgot-password)
 	authHandler.RegisterRoutes(api)
 
+	// Addresses whose device API keys or display tokens are rejected too often get locked out
+	publicLockout := time.Duration(cfg.Login.LockoutMinutes) * time.Minute
+	publicPolicy := ratelimit.Policy{
+		MaxFailures: cfg.Login.PublicMaxFailures,
+		Window:      publicLockout,
+		Lockout:     publicLockout,
+	}
+	displayLimiter := ratelimit.NewLimiter(redisClient, "display", publicPolicy)
+
 	// Initialize Display Token Module (needed for public display)
 	// Requirements: 5.1, 6.1 - Display token management for public display access
 	displayTokenRepo := displaytoken.NewRepository(db)
@@ -134,10 +176,12 @@ func main() {
 
 	// Initialize Real-Time Module (needed for public display)
 	// Requirements: 4.1, 4.2, 4.3 - Real-time attendance dashboard with WebSocket
-	realtimeHub := realtime.NewHub()
+	// Broadcasts fan out through Redis, so clients on every instance receive them
+	realtimeHub := realtime.NewHub(redisClient)
 	go realtimeHub.Run() // Start the hub in a goroutine
 	realtimeRepo := realtime.NewRepository(db)
-	realtimeService := realtime.NewService(realtimeRepo, realtimeHub)
+	accessPolicy := policy.NewAccessPolicy(db) // Filters WebSocket topic subscriptions
+	realtimeService := realtime.NewService(realtimeRepo, realtimeHub, accessPolicy)
 	realtimeHandler := realtime.NewHandler(realtimeService, jwtManager)
 
 	// Initialize Public Display Module BEFORE protected routes
@@ -145,7 +189,7 @@ func main() {
 	// IMPORTANT: Must be registered before protected group to avoid auth middleware
 	publicDisplayRepo := publicdisplay.NewRepository(db)
 	publicDisplayService := publicdisplay.NewService(publicDisplayRepo, displayTokenService)
-	publicDisplayHandler := publicdisplay.NewHandler(publicDisplayService, realtimeHub, realtimeRepo)
+	publicDisplayHandler := publicdisplay.NewHandler(publicDisplayService, realtimeHub, realtimeRepo, displayLimiter)
 
 	// Public display routes (no auth required - uses display token)
 	publicDisplayHandler.RegisterPublicRoutes(api)
@@ -156,14 +200,44 @@ func main() {
 
 	// Initialize Device Module (needed for public routes)
 	deviceRepo := device.NewRepository(db)
-	deviceService := device.NewService(deviceRepo)
+	deviceService := device.NewService(deviceRepo, cfg.Device)
 	deviceHandler := device.NewHandler(deviceService)
 
+	// Hash API keys of devices registered before keys were stored hashed
+	if hashed, err := deviceRepo.HashLegacyAPIKeys(context.Background()); err != nil {
+		log.Fatalf("Failed to hash device API keys: %v", err)
+	} else if hashed > 0 {
+		log.Printf("Hashed API keys of %d devices", hashed)
+	}
+
+	// Device request signing (HMAC with timestamp and nonce, replay protection in Redis)
+	deviceSignature := device.NewSignatureVerifier(
+		deviceRepo,
+		redisClient,
+		cfg.Device.SigningPepper,
+		time.Duration(cfg.Device.SignatureMaxSkewSeconds)*time.Second,
+		cfg.Device.RequireSignature,
+	)
+	deviceAuth := deviceSignature.Middleware()
+	// Rejected requests are counted per client IP, which guessing API keys cannot spread out.
+	// Signed requests pass a locked out address to be verified, so an attacker sharing a school's
+	// NAT cannot lock out its signing devices; only unsigned requests from the address are refused.
+	deviceLimit := middleware.RateLimitVerifiable(ratelimit.NewLimiter(redisClient, "device", publicPolicy), middleware.ClientIP, device.IsSigned)
+	if !cfg.Device.RequireSignature {
+		log.Println("Unsigned device requests are accepted; set DEVICE_REQUIRE_SIGNATURE=true once all devices sign their requests")
+	}
+
 	// Initialize Pairing Module (needed for public routes)
 	deviceStudentRepo := device.NewStudentRepository(db)
-	pairingService := device.NewPairingService(deviceRepo, deviceStudentRepo)
+	pairingManager := device.NewPairingManager(redisClient) // Sessions shared by all instances
+	pairingService := device.NewPairingService(deviceRepo, deviceStudentRepo, pairingManager, realtimeService)
 	pairingHandler := device.NewPairingHandler(pairingService)
 
+	// Initialize Firmware Module (OTA updates, needed for public routes)
+	firmwareRepo := device.NewFirmwareRepository(db)
+	firmwareService := device.NewFirmwareService(firmwareRepo, deviceRepo, cfg.Device.FirmwareDir)
+	firmwareHandler := device.NewFirmwareHandler(firmwareService)
+
 	// Initialize Attendance Module EARLY (needed for public routes)
 	attendanceRepo := attendance.NewRepository(db)
 	attendancePolicy := attendance.NewAttendancePolicy(db)
@@ -171,18 +245,25 @@ func main() {
 	attendanceHandler := attendance.NewHandler(attendanceService, attendanceRepo)
 
 	// IMPORTANT: Register public ESP32 routes directly on app (not using groups)
-	// This ensures they are NOT affected by any middleware
+	// This ensures they are NOT affected by any middleware except rate limiting and device request signing
 	
 	// Public device routes (for ESP32 API key validation)
-	app.Post("/api/v1/public/devices/validate-key", deviceHandler.ValidateAPIKey)
+	app.Post("/api/v1/public/devices/validate-key", deviceLimit, deviceAuth, deviceHandler.ValidateAPIKey)
+	app.Post("/api/v1/public/devices/heartbeat", deviceLimit, deviceAuth, deviceHandler.Heartbeat)
 
 	// Public pairing routes (for ESP32 RFID pairing)
-	app.Post("/api/v1/public/pairing/rfid", pairingHandler.ProcessRFIDPairing)
+	app.Post("/api/v1/public/pairing/rfid", deviceLimit, deviceAuth, pairingHandler.ProcessRFIDPairing)
 	app.Get("/api/v1/public/pairing/status/:deviceId", pairingHandler.GetPairingStatus)
 	app.Post("/api/v1/public/pairing/start-test", pairingHandler.StartPairingTest) // For testing
 
+	// Public firmware routes (OTA updates for NodeMCU readers)
+	app.Post("/api/v1/public/firmware/check", deviceLimit, deviceAuth, firmwareHandler.CheckUpdate)
+	app.Get("/api/v1/public/firmware/:id/download", deviceLimit, deviceAuth, firmwareHandler.Download) // Supports Range resume
+	app.Post("/api/v1/public/firmware/report", deviceLimit, deviceAuth, firmwareHandler.ReportInstall)
+
 	// Public attendance routes (for ESP32 RFID devices)
-	app.Post("/api/v1/public/attendance/rfid", attendanceHandler.RecordRFIDAttendance)
+	app.Post("/api/v1/public/attendance/rfid", deviceLimit, deviceAuth, attendanceHandler.RecordRFIDAttendance)
+	app.Post("/api/v1/public/attendance/rfid/batch", deviceLimit, deviceAuth, attendanceHandler.RecordRFIDBatch) // Offline tap sync
 
 	// Protected routes group with auth middleware
 	protected := api.Group("", middleware.AuthMiddleware(jwtManager))
@@ -204,6 +285,10 @@ func main() {
 	devicesAdmin := protected.Group("/devices", middleware.SuperAdminOnly())
 	deviceHandler.RegisterRoutesWithoutGroup(devicesAdmin)
 
+	// Firmware management (Super Admin only)
+	firmwareAdmin := protected.Group("/firmware", middleware.SuperAdminOnly())
+	firmwareHandler.RegisterRoutesWithoutGroup(firmwareAdmin)
+
 	// Tenant-scoped routes (for non-super_admin users)
 	tenantScoped := protected.Group("", middleware.TenantMiddleware())
 
@@ -224,13 +309,19 @@ func main() {
 	// Initialize School Module (Admin Sekolah)
 	schoolRepo := school.NewRepository(db)
 	schoolUserRepo := school.NewUserRepository(db)
-	schoolService := school.NewService(schoolRepo, schoolUserRepo)
+	schoolService := school.NewService(schoolRepo, schoolUserRepo, loginThrottle) // Admins unlock accounts locked by failed logins
 	schoolHandler := school.NewHandler(schoolService)
 
 	// School routes for admin sekolah (classes, students, parents)
 	adminSekolahRoutes := tenantScoped.Group("/school")
 	schoolHandler.RegisterRoutes(adminSekolahRoutes)
 
+	// Academic year rollover (clone classes, promote students, archive the previous year)
+	rolloverRepo := school.NewRolloverRepository(db)
+	rolloverService := school.NewRolloverService(rolloverRepo, db)
+	rolloverHandler := school.NewRolloverHandler(rolloverService)
+	rolloverHandler.RegisterRoutes(adminSekolahRoutes)
+
 	// Initialize Import Module (Admin Sekolah only)
 	// Requirements: 1.1, 1.2, 2.1 - Bulk import for students and parents
 	importService := importmodule.NewService(db)
@@ -253,10 +344,6 @@ func main() {
 	displayTokenRoutes := tenantScoped.Group("/display-tokens", middleware.AdminSekolahOnly())
 	displayTokenHandler.RegisterRoutesWithoutGroup(displayTokenRoutes)
 
-	// Connect attendance service to real-time broadcaster
-	// Requirements: 4.2 - Broadcast attendance updates in real-time
-	attendanceService.SetRealtimeBroadcaster(realtimeService)
-
 	// Attendance routes for admin sekolah and wali kelas
 	attendanceRoutes := tenantScoped.Group("/attendance")
 	attendanceHandler.RegisterRoutesWithoutGroup(attendanceRoutes)
@@ -279,13 +366,25 @@ func main() {
 	// Initialize Grade Module
 	// Requirements: 10.1, 10.2, 10.4, 10.5
 	gradeRepo := grade.NewRepository(db)
-	gradeService := grade.NewService(gradeRepo, db)
+	gradeSubjectRepo := grade.NewSubjectRepository(db)
+	gradeAssignmentRepo := grade.NewAssignmentRepository(db)
+	gradeService := grade.NewService(gradeRepo, gradeSubjectRepo, gradeAssignmentRepo, db)
 	gradeHandler := grade.NewHandler(gradeService)
+	subjectService := grade.NewSubjectService(gradeSubjectRepo)
+	subjectHandler := grade.NewSubjectHandler(subjectService)
+	assignmentService := grade.NewAssignmentService(gradeAssignmentRepo, gradeSubjectRepo, db)
+	assignmentHandler := grade.NewAssignmentHandler(assignmentService)
 
-	// Grade routes for Wali Kelas (full access to their class)
+	// Grade routes for Wali Kelas (full access to their class) and Guru (their teaching assignments)
 	gradeRoutes := tenantScoped.Group("/grades")
 	gradeHandler.RegisterRoutesWithoutGroup(gradeRoutes)
 
+	// Subject and assessment category routes (/subjects, /assessment-categories)
+	subjectHandler.RegisterRoutes(tenantScoped)
+
+	// Teaching assignment routes (/teaching-assignments)
+	assignmentHandler.RegisterRoutes(tenantScoped)
+
 	// Initialize Homeroom Module
 	// Requirements: 11.1, 11.3, 11.4, 11.5
 	homeroomRepo := homeroom.NewRepository(db)
@@ -300,6 +399,33 @@ func main() {
 	})
 	homeroomHandler.RegisterRoutesWithoutGroup(homeroomRoutes)
 
+	// Initialize Report Card Module
+	// Rapor PDFs are generated by a background worker and stored on disk
+	reportCardRepo := reportcard.NewRepository(db)
+	reportCardService := reportcard.NewService(reportCardRepo, db, cfg.ReportCard.StorageDir)
+	reportCardHandler := reportcard.NewHandler(reportCardService)
+
+	// Report card routes for Admin Sekolah and Wali Kelas (/report-cards)
+	reportCardHandler.RegisterRoutes(tenantScoped)
+
+	// Initialize Leave Request Module
+	// Parents ask sick/excused leave, wali kelas approve it into attendance records
+	leaveRepo := leave.NewRepository(db)
+	leaveService := leave.NewService(leaveRepo, cfg.Leave.AttachmentDir)
+	leaveHandler := leave.NewHandler(leaveService)
+
+	// Leave request review routes for Admin Sekolah and Wali Kelas (/leave-requests)
+	leaveHandler.RegisterRoutes(tenantScoped)
+
+	// Initialize Audit Log Module
+	// Who changed school data and who read internal counseling notes, written by the audit plugin
+	auditLogRepo := auditlog.NewRepository(db)
+	auditLogService := auditlog.NewService(auditLogRepo)
+	auditLogHandler := auditlog.NewHandler(auditLogService)
+
+	// Audit log routes for Admin Sekolah and Super Admin (/audit-logs)
+	auditLogHandler.RegisterRoutes(tenantScoped)
+
 	// Initialize FCM Client
 	// Requirements: 13.1, 13.2 - Firebase Cloud Messaging integration
 	fcmClient, err := fcm.NewClient(cfg.FCM)
@@ -316,12 +442,20 @@ func main() {
 	// Initialize Notification Module
 	// Requirements: 17.1, 17.2, 17.3, 17.4, 17.5 - Notification system with queue and FCM
 	notificationRepo := notification.NewRepository(db)
-	notificationService := notification.NewService(notificationRepo, redisClient)
+	notificationService := notification.NewService(notificationRepo, redisClient, realtimeService)
 	notificationHandler := notification.NewHandler(notificationService)
 
 	// Notification routes (accessible by all authenticated users)
 	notificationHandler.RegisterRoutes(protected)
 
+	// Initialize Outbox Relay
+	// Publishes domain events written with attendance, BK, grade, homeroom and leave records
+	// to the notification queue and the real-time hub
+	outboxRelay := outbox.NewRelay(db,
+		realtime.NewOutboxPublisher(realtimeService, realtimeRepo),
+		notification.NewOutboxPublisher(notificationService, notificationRepo),
+	)
+
 	// Initialize Parent Module
 	// Requirements: 12.2, 14.4, 15.1, 15.2 - Parent data access for linked children
 	parentRepo := parent.NewRepository(db)
@@ -333,6 +467,7 @@ func main() {
 		models.RoleParent,
 	))
 	parentHandler.RegisterRoutes(parentRoutes)
+	leaveHandler.RegisterParentRoutes(parentRoutes)
 
 	// Initialize Student Module
 	// Requirements: 16.1-16.5 - Student self-monitoring
@@ -351,6 +486,28 @@ func main() {
 	notificationWorker := notification.NewWorker(redisClient, fcmClient, notificationRepo)
 	notificationWorker.Start()
 
+	outboxRelay.Start()
+
+	// Initialize and start Absence Scheduler
+	// Marks students without attendance as absent when each schedule window closes
+	absenceScheduler := attendance.NewAbsenceScheduler(attendanceRepo)
+	absenceScheduler.Start()
+
+	// Initialize and start Device Offline Monitor
+	// Alerts admins when a device goes silent during an open attendance schedule
+	deviceOfflineMonitor := device.NewOfflineMonitor(deviceRepo, cfg.Device)
+	deviceOfflineMonitor.Start()
+
+	// Initialize and start Counseling Follow-up Reminder
+	// Notifies the assignee of a counseling case follow-up once it falls due
+	followUpReminder := bk.NewFollowUpReminder(bkRepo)
+	followUpReminder.Start()
+
+	// Initialize and start Report Card Worker
+	// Generates queued rapor PDFs and class bundles
+	reportCardWorker := reportcard.NewWorker(reportCardService, time.Duration(cfg.ReportCard.PollIntervalSeconds)*time.Second)
+	reportCardWorker.Start()
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -359,7 +516,13 @@ func main() {
 		<-quit
 		log.Println("Shutting down server...")
 
-		// Stop notification worker
+		// Stop background jobs
+		absenceScheduler.Stop()
+		deviceOfflineMonitor.Stop()
+		followUpReminder.Stop()
+		reportCardWorker.Stop()
+		realtimeHub.Stop()
+		outboxRelay.Stop()
 		notificationWorker.Stop()
 
 		if err := app.Shutdown(); err != nil {
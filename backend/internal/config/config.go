@@ -4,15 +4,22 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	FCM      FCMConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Redis      RedisConfig
+	JWT        JWTConfig
+	Login      LoginConfig
+	Messaging  MessagingConfig
+	TwoFactor  TwoFactorConfig
+	FCM        FCMConfig
+	Device     DeviceConfig
+	ReportCard ReportCardConfig
+	Leave      LeaveConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -20,6 +27,8 @@ type ServerConfig struct {
 	Port           string
 	Environment    string
 	AllowedOrigins string
+	TrustedProxies []string // Load balancers or reverse proxies (IPs or CIDRs) whose ProxyHeader is trusted
+	ProxyHeader    string   // Header holding the client IP, set by the trusted proxies
 }
 
 // DatabaseConfig holds database-related configuration
@@ -52,15 +61,66 @@ type JWTConfig struct {
 	SecretKey            string
 	AccessTokenDuration  int // in minutes
 	RefreshTokenDuration int // in hours
+	ChallengeDuration    int // in minutes; how long a password stays accepted while the 2FA code is entered
 	Issuer               string
 }
 
+// LoginConfig holds configuration for brute-force protection of logins and public endpoints
+type LoginConfig struct {
+	MaxAttempts        int // Failed logins per username before the account is locked
+	DelayAfterAttempts int // Failed logins per username after which every retry has to wait longer
+	IPMaxAttempts      int // Failed logins per IP address before the address is locked
+	LockoutMinutes     int // How long a lock lasts; failures are counted over the same period
+	PublicMaxFailures  int // Rejected device or display token requests per IP address before it is locked
+	ResetCodeMinutes   int // How long a password reset code stays valid
+	ResetCodeAttempts  int // Wrong guesses after which a password reset code stops working
+	TwoFactorAttempts  int // Wrong authenticator or recovery codes per username before it is locked
+}
+
+// MessagingConfig holds configuration for delivering one-time codes
+type MessagingConfig struct {
+	Driver       string // "log" prints messages for development (refused in production), "smtp" sends email
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+}
+
+// TwoFactorConfig holds configuration for authenticator app (TOTP) logins
+type TwoFactorConfig struct {
+	Issuer        string   // Account name prefix shown in the authenticator app
+	RequiredRoles []string // Roles that must use 2FA at every school; schools can require more in their settings
+}
+
 // FCMConfig holds Firebase Cloud Messaging configuration
 type FCMConfig struct {
 	CredentialsFile string
 	ProjectID       string
 }
 
+// DeviceConfig holds configuration for ESP32 device authentication
+type DeviceConfig struct {
+	RequireSignature        bool   // Reject unsigned requests that send the API key in the body
+	SigningPepper           string // Server-side key the request signing secrets are derived with, never stored in the database
+	SignatureMaxSkewSeconds int    // Allowed clock difference between device and server
+	KeyRotationGraceHours   int    // How long a replaced API key keeps working
+	OfflineThresholdMinutes int    // Silence after which a device is considered offline
+	HeartbeatRetentionDays  int    // How long heartbeat history is kept
+	FirmwareDir             string // Where uploaded OTA firmware binaries are stored
+}
+
+// ReportCardConfig holds configuration for report card (rapor) generation
+type ReportCardConfig struct {
+	StorageDir          string // Where generated PDFs and class bundles are stored
+	PollIntervalSeconds int    // Delay between polls of the generation queue
+}
+
+// LeaveConfig holds configuration for leave requests submitted by parents
+type LeaveConfig struct {
+	AttachmentDir string // Where photos of doctor's notes are stored
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{
@@ -68,6 +128,8 @@ func Load() (*Config, error) {
 			Port:           getEnv("SERVER_PORT", "8080"),
 			Environment:    getEnv("ENVIRONMENT", "development"),
 			AllowedOrigins: getEnv("ALLOWED_ORIGINS", "*"),
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES", nil),
+			ProxyHeader:    getEnv("PROXY_HEADER", "X-Real-IP"),
 		},
 		Database: DatabaseConfig{
 			Host:                   getEnv("DB_HOST", "localhost"),
@@ -94,12 +156,52 @@ func Load() (*Config, error) {
 			SecretKey:            getEnv("JWT_SECRET_KEY", "your-secret-key-change-in-production"),
 			AccessTokenDuration:  getEnvAsInt("JWT_ACCESS_TOKEN_DURATION", 15),   // 15 minutes
 			RefreshTokenDuration: getEnvAsInt("JWT_REFRESH_TOKEN_DURATION", 168), // 7 days (168 hours)
+			ChallengeDuration:    getEnvAsInt("JWT_CHALLENGE_TOKEN_DURATION", 5), // 5 minutes
 			Issuer:               getEnv("JWT_ISSUER", "school-management-api"),
 		},
+		Login: LoginConfig{
+			MaxAttempts:        getEnvAsInt("LOGIN_MAX_ATTEMPTS", 10),
+			DelayAfterAttempts: getEnvAsInt("LOGIN_DELAY_AFTER_ATTEMPTS", 3),
+			IPMaxAttempts:      getEnvAsInt("LOGIN_IP_MAX_ATTEMPTS", 50),
+			LockoutMinutes:     getEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15),
+			PublicMaxFailures:  getEnvAsInt("PUBLIC_MAX_FAILURES", 30),
+			ResetCodeMinutes:   getEnvAsInt("PASSWORD_RESET_CODE_MINUTES", 10),
+			ResetCodeAttempts:  getEnvAsInt("PASSWORD_RESET_CODE_ATTEMPTS", 5),
+			TwoFactorAttempts:  getEnvAsInt("TWO_FACTOR_MAX_ATTEMPTS", 5),
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:        getEnv("TWO_FACTOR_ISSUER", "School Management"),
+			RequiredRoles: getEnvAsList("TWO_FACTOR_REQUIRED_ROLES", nil),
+		},
+		Messaging: MessagingConfig{
+			Driver:       getEnv("MESSAGING_DRIVER", "log"),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			SMTPFrom:     getEnv("SMTP_FROM", ""),
+		},
 		FCM: FCMConfig{
 			CredentialsFile: getEnv("FCM_CREDENTIALS_FILE", ""),
 			ProjectID:       getEnv("FCM_PROJECT_ID", ""),
 		},
+		Device: DeviceConfig{
+			// Turned on once every device runs firmware 2.3.0 or later, which signs its requests
+			RequireSignature:        getEnvAsBool("DEVICE_REQUIRE_SIGNATURE", false),
+			SigningPepper:           getEnv("DEVICE_SIGNING_PEPPER", "device-signing-pepper-change-in-production"),
+			SignatureMaxSkewSeconds: getEnvAsInt("DEVICE_SIGNATURE_MAX_SKEW_SECONDS", 300), // 5 minutes
+			KeyRotationGraceHours:   getEnvAsInt("DEVICE_KEY_ROTATION_GRACE_HOURS", 24),
+			OfflineThresholdMinutes: getEnvAsInt("DEVICE_OFFLINE_THRESHOLD_MINUTES", 5),
+			HeartbeatRetentionDays:  getEnvAsInt("DEVICE_HEARTBEAT_RETENTION_DAYS", 30),
+			FirmwareDir:             getEnv("DEVICE_FIRMWARE_DIR", "./storage/firmware"),
+		},
+		ReportCard: ReportCardConfig{
+			StorageDir:          getEnv("REPORT_CARD_DIR", "./storage/report-cards"),
+			PollIntervalSeconds: getEnvAsInt("REPORT_CARD_POLL_INTERVAL_SECONDS", 5),
+		},
+		Leave: LeaveConfig{
+			AttachmentDir: getEnv("LEAVE_ATTACHMENT_DIR", "./storage/leave-attachments"),
+		},
 	}
 
 	// Validate required configuration
@@ -128,6 +230,9 @@ func (c *Config) Validate() error {
 		if c.JWT.SecretKey == "your-secret-key-change-in-production" {
 			return fmt.Errorf("JWT_SECRET_KEY must be changed in production")
 		}
+		if c.Device.SigningPepper == "device-signing-pepper-change-in-production" || len(c.Device.SigningPepper) < 32 {
+			return fmt.Errorf("DEVICE_SIGNING_PEPPER must be set to a random value of at least 32 characters in production")
+		}
 	}
 
 	return nil
@@ -169,3 +274,18 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvAsList(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
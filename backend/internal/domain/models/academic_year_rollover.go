@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// RolloverAction is what happens to a student at the academic year rollover
+type RolloverAction string
+
+const (
+	RolloverActionPromote  RolloverAction = "promote"  // Moves up to the next grade level
+	RolloverActionRepeat   RolloverAction = "repeat"   // Stays at the same grade level (tinggal kelas)
+	RolloverActionGraduate RolloverAction = "graduate" // Leaves the school (lulus)
+)
+
+// IsValid checks if the rollover action is valid
+func (a RolloverAction) IsValid() bool {
+	switch a {
+	case RolloverActionPromote, RolloverActionRepeat, RolloverActionGraduate:
+		return true
+	}
+	return false
+}
+
+// AcademicYearRollover records a school moving from one academic year to the next
+// The classes of FromYear are cloned into ToYear, students are promoted into the
+// clones and the FromYear classes are archived read-only.
+type AcademicYearRollover struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	SchoolID        uint      `gorm:"uniqueIndex:idx_rollovers_school_year;not null" json:"school_id"`
+	FromYear        string    `gorm:"type:varchar(10);not null" json:"from_year"`
+	ToYear          string    `gorm:"type:varchar(10);uniqueIndex:idx_rollovers_school_year;not null" json:"to_year"`
+	ClassesCreated  int       `gorm:"not null;default:0" json:"classes_created"`
+	ClassesArchived int       `gorm:"not null;default:0" json:"classes_archived"`
+	PromotedCount   int       `gorm:"not null;default:0" json:"promoted_count"`
+	RepeatedCount   int       `gorm:"not null;default:0" json:"repeated_count"`
+	GraduatedCount  int       `gorm:"not null;default:0" json:"graduated_count"`
+	UnplacedCount   int       `gorm:"not null;default:0" json:"unplaced_count"` // Promoted students without a matching class in ToYear
+	PerformedBy     uint      `gorm:"not null" json:"performed_by"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	// Relations
+	School    School `gorm:"foreignKey:SchoolID" json:"-"`
+	Performer User   `gorm:"foreignKey:PerformedBy" json:"performer,omitempty"`
+}
+
+// TableName specifies the table name for AcademicYearRollover
+func (AcademicYearRollover) TableName() string {
+	return "academic_year_rollovers"
+}
@@ -10,6 +10,7 @@ import (
 type Achievement struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
 	StudentID   uint      `gorm:"index;not null" json:"student_id"`
+	ClassID     *uint     `gorm:"index" json:"class_id"` // Class at the time (see Attendance.ClassID)
 	Title       string    `gorm:"type:varchar(255);not null" json:"title"`
 	Point       int       `gorm:"not null" json:"point"`
 	Description string    `gorm:"type:text" json:"description"`
@@ -11,12 +11,13 @@ type AttendanceMethod string
 const (
 	AttendanceMethodRFID   AttendanceMethod = "rfid"
 	AttendanceMethodManual AttendanceMethod = "manual"
+	AttendanceMethodAuto   AttendanceMethod = "auto" // Recorded by the system (e.g. absence marking)
 )
 
 // IsValid checks if the attendance method is valid
 func (m AttendanceMethod) IsValid() bool {
 	switch m {
-	case AttendanceMethodRFID, AttendanceMethodManual:
+	case AttendanceMethodRFID, AttendanceMethodManual, AttendanceMethodAuto:
 		return true
 	}
 	return false
@@ -45,16 +46,20 @@ func (s AttendanceStatus) IsValid() bool {
 
 // Attendance represents daily attendance record
 type Attendance struct {
-	ID           uint             `gorm:"primaryKey" json:"id"`
-	StudentID    uint             `gorm:"index;not null" json:"student_id"`
-	ScheduleID   *uint            `gorm:"index" json:"schedule_id"`
-	Date         time.Time        `gorm:"type:date;index;not null" json:"date"`
-	CheckInTime  *time.Time       `json:"check_in_time"`
-	CheckOutTime *time.Time       `json:"check_out_time"`
-	Status       AttendanceStatus `gorm:"type:varchar(20)" json:"status"`
-	Method       AttendanceMethod `gorm:"type:varchar(10);not null" json:"method"`
-	CreatedAt    time.Time        `json:"created_at"`
-	UpdatedAt    time.Time        `json:"updated_at"`
+	ID             uint             `gorm:"primaryKey" json:"id"`
+	StudentID      uint             `gorm:"index;not null" json:"student_id"`
+	ClassID        *uint            `gorm:"index" json:"class_id"` // Class at the time, frozen at rollover; nil means the student's current class
+	ScheduleID     *uint            `gorm:"index" json:"schedule_id"`
+	Date           time.Time        `gorm:"type:date;index;not null" json:"date"`
+	CheckInTime    *time.Time       `json:"check_in_time"`
+	CheckOutTime   *time.Time       `json:"check_out_time"`
+	Status         AttendanceStatus `gorm:"type:varchar(20)" json:"status"`
+	Method         AttendanceMethod `gorm:"type:varchar(10);not null" json:"method"`
+	IsEarlyLeave   bool             `gorm:"default:false" json:"is_early_leave"`     // Checked out before the schedule's dismissal time
+	LeaveRequestID *uint            `gorm:"index" json:"leave_request_id"`           // Approved leave the record was created from or conflicts with
+	NeedsReview    bool             `gorm:"default:false;index" json:"needs_review"` // RFID tap on a day the student is on approved leave
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
 
 	// Relations
 	Student  Student             `gorm:"foreignKey:StudentID" json:"student,omitempty"`
@@ -76,7 +81,7 @@ func (a *Attendance) Validate() error {
 		return errors.New("date is required")
 	}
 	if !a.Method.IsValid() {
-		return errors.New("method must be one of: rfid, manual")
+		return errors.New("method must be one of: rfid, manual, auto")
 	}
 	return nil
 }
@@ -91,6 +96,11 @@ func (a *Attendance) HasCheckedOut() bool {
 	return a.CheckOutTime != nil
 }
 
+// IsAutoAbsent checks if the record is an absence marked by the scheduler without any tap
+func (a *Attendance) IsAutoAbsent() bool {
+	return a.Method == AttendanceMethodAuto && a.Status == AttendanceStatusAbsent && a.CheckInTime == nil
+}
+
 // SetCheckIn sets the check-in time
 func (a *Attendance) SetCheckIn(t time.Time) {
 	a.CheckInTime = &t
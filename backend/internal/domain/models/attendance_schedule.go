@@ -7,21 +7,40 @@ import (
 	"time"
 )
 
+// ScheduleType represents what a tap inside the schedule window records
+type ScheduleType string
+
+const (
+	ScheduleTypeCheckIn  ScheduleType = "check_in"
+	ScheduleTypeCheckOut ScheduleType = "check_out"
+)
+
+// IsValid checks if the schedule type is valid
+func (t ScheduleType) IsValid() bool {
+	switch t {
+	case ScheduleTypeCheckIn, ScheduleTypeCheckOut:
+		return true
+	}
+	return false
+}
+
 // AttendanceSchedule represents a configurable attendance time slot
 // Requirements: 3.1, 3.2, 3.3 - Multi-schedule support for different activities
 type AttendanceSchedule struct {
-	ID                uint       `gorm:"primaryKey" json:"id"`
-	SchoolID          uint       `gorm:"index;not null" json:"school_id"`
-	Name              string     `gorm:"type:varchar(100);not null" json:"name"`
-	StartTime         string     `gorm:"type:time without time zone;not null" json:"start_time"`
-	EndTime           string     `gorm:"type:time without time zone;not null" json:"end_time"`
-	LateThreshold     int        `gorm:"not null;default:15" json:"late_threshold"`
-	VeryLateThreshold *int       `gorm:"" json:"very_late_threshold"`
-	DaysOfWeek        string     `gorm:"type:varchar(20);default:'1,2,3,4,5'" json:"days_of_week"`
-	IsActive          bool       `gorm:"default:true" json:"is_active"`
-	IsDefault         bool       `gorm:"default:false" json:"is_default"`
-	CreatedAt         time.Time  `json:"created_at"`
-	UpdatedAt         time.Time  `json:"updated_at"`
+	ID                uint         `gorm:"primaryKey" json:"id"`
+	SchoolID          uint         `gorm:"index;not null" json:"school_id"`
+	Name              string       `gorm:"type:varchar(100);not null" json:"name"`
+	StartTime         string       `gorm:"type:time without time zone;not null" json:"start_time"`
+	EndTime           string       `gorm:"type:time without time zone;not null" json:"end_time"`
+	LateThreshold     int          `gorm:"not null;default:15" json:"late_threshold"`
+	VeryLateThreshold *int         `gorm:"" json:"very_late_threshold"`
+	DaysOfWeek        string       `gorm:"type:varchar(20);default:'1,2,3,4,5'" json:"days_of_week"`
+	Type              ScheduleType `gorm:"type:varchar(20);not null;default:'check_in'" json:"type"`
+	DismissalTime     *string      `gorm:"type:time without time zone" json:"dismissal_time"` // check_out only: taps before this are early leave
+	IsActive          bool         `gorm:"default:true" json:"is_active"`
+	IsDefault         bool         `gorm:"default:false" json:"is_default"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
 
 	// Relations
 	School School `gorm:"foreignKey:SchoolID;constraint:OnDelete:CASCADE" json:"school,omitempty"`
@@ -65,6 +84,14 @@ func (s *AttendanceSchedule) Validate() error {
 		return errors.New("end_time must be in HH:MM or HH:MM:SS format")
 	}
 
+	// Validate schedule type and dismissal time
+	if s.Type != "" && !s.Type.IsValid() {
+		return errors.New("type must be one of: check_in, check_out")
+	}
+	if s.DismissalTime != nil && *s.DismissalTime != "" && !isValidTimeFormat(*s.DismissalTime) {
+		return errors.New("dismissal_time must be in HH:MM or HH:MM:SS format")
+	}
+
 	// Validate days_of_week format
 	if s.DaysOfWeek != "" {
 		if err := s.ValidateDaysOfWeek(); err != nil {
@@ -144,6 +171,38 @@ func (s *AttendanceSchedule) IsTimeInRange(t time.Time) bool {
 	return timeStr >= startTime && timeStr <= endTime
 }
 
+// IsCheckOut checks if taps inside this schedule window record check-outs
+func (s *AttendanceSchedule) IsCheckOut() bool {
+	return s.Type == ScheduleTypeCheckOut
+}
+
+// IsEarlyLeave checks if a check-out at the given time happens before the dismissal time
+func (s *AttendanceSchedule) IsEarlyLeave(checkOutTime time.Time) bool {
+	if !s.IsCheckOut() || s.DismissalTime == nil || *s.DismissalTime == "" {
+		return false
+	}
+	return checkOutTime.Before(clockTimeOn(checkOutTime, *s.DismissalTime))
+}
+
+// StartTimeOn returns the schedule's start time on the given date, in the date's location
+func (s *AttendanceSchedule) StartTimeOn(date time.Time) time.Time {
+	return clockTimeOn(date, s.StartTime)
+}
+
+// EndTimeOn returns the schedule's end time on the given date, in the date's location
+func (s *AttendanceSchedule) EndTimeOn(date time.Time) time.Time {
+	return clockTimeOn(date, s.EndTime)
+}
+
+// clockTimeOn combines a date with a HH:MM or HH:MM:SS clock string
+func clockTimeOn(date time.Time, clock string) time.Time {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		t, _ = time.Parse("15:04:05", clock)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), t.Second(), 0, date.Location())
+}
+
 // GetLateStatus determines the attendance status based on check-in time
 // Returns: on_time, late, or very_late
 func (s *AttendanceSchedule) GetLateStatus(checkInTime time.Time) AttendanceStatus {
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// AttendanceSyncStatus represents the processing state of a synced tap
+type AttendanceSyncStatus string
+
+const (
+	AttendanceSyncStatusProcessing AttendanceSyncStatus = "processing" // Tap claimed, result not stored yet
+	AttendanceSyncStatusCompleted  AttendanceSyncStatus = "completed"  // Result stored; replays return it
+)
+
+// AttendanceSyncReceipt records a tap uploaded by a device with its idempotency key
+// A device that resends a tap (e.g. after losing the response) gets the stored result
+// instead of recording the tap twice
+type AttendanceSyncReceipt struct {
+	ID             uint                 `gorm:"primaryKey" json:"id"`
+	DeviceID       uint                 `gorm:"not null;uniqueIndex:idx_sync_receipt_device_key" json:"device_id"`
+	IdempotencyKey string               `gorm:"type:varchar(64);not null;uniqueIndex:idx_sync_receipt_device_key" json:"idempotency_key"`
+	RFIDCode       string               `gorm:"type:varchar(50);not null" json:"rfid_code"`
+	TapTime        time.Time            `gorm:"not null" json:"tap_time"`
+	Status         AttendanceSyncStatus `gorm:"type:varchar(20);not null;default:'processing'" json:"status"`
+	Result         string               `gorm:"type:text" json:"result,omitempty"` // Per-tap result (JSON) returned to the device
+	CreatedAt      time.Time            `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+
+	// Relations
+	Device Device `gorm:"foreignKey:DeviceID;constraint:OnDelete:CASCADE" json:"device,omitempty"`
+}
+
+// TableName specifies the table name for AttendanceSyncReceipt
+func (AttendanceSyncReceipt) TableName() string {
+	return "attendance_sync_receipts"
+}
+
+// Validate validates the sync receipt data
+func (r *AttendanceSyncReceipt) Validate() error {
+	if r.DeviceID == 0 {
+		return errors.New("device_id is required")
+	}
+	if strings.TrimSpace(r.IdempotencyKey) == "" {
+		return errors.New("idempotency_key is required")
+	}
+	if len(r.IdempotencyKey) > 64 {
+		return errors.New("idempotency_key must be at most 64 characters")
+	}
+	if r.TapTime.IsZero() {
+		return errors.New("tap_time is required")
+	}
+	return nil
+}
+
+// IsCompleted checks if the tap has been processed and its result stored
+func (r *AttendanceSyncReceipt) IsCompleted() bool {
+	return r.Status == AttendanceSyncStatusCompleted
+}
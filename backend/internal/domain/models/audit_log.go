@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// AuditAction represents what was done to an audited record
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+	AuditActionRead   AuditAction = "read"   // Only for records whose reads are sensitive, e.g. internal counseling notes
+	AuditActionLock   AuditAction = "lock"   // A username or address locked out after too many failed attempts
+	AuditActionUnlock AuditAction = "unlock" // A login lock lifted by an admin
+)
+
+// IsValid checks if the audit action is valid
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionRead, AuditActionLock, AuditActionUnlock:
+		return true
+	}
+	return false
+}
+
+// AuditLog is an entry of the append-only audit trail of school data
+// Before and After hold the changed columns as JSON objects: both for an update, only After for
+// a create and only Before for a delete. Columns the API never shows, such as password hashes or
+// internal counseling notes, are masked so the trail shows that they changed but not their value.
+type AuditLog struct {
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	SchoolID      *uint       `gorm:"index:idx_audit_logs_school_time" json:"school_id"`
+	ActorID       uint        `gorm:"index;not null" json:"actor_id"`
+	ActorRole     string      `gorm:"type:varchar(20)" json:"actor_role"`
+	ActorUsername string      `gorm:"type:varchar(100)" json:"actor_username"`
+	IPAddress     string      `gorm:"type:varchar(45)" json:"ip_address"`
+	Method        string      `gorm:"type:varchar(10)" json:"method"`
+	Path          string      `gorm:"type:varchar(255)" json:"path"`
+	Action        AuditAction `gorm:"type:varchar(10);index;not null" json:"action"`
+	Module        string      `gorm:"type:varchar(20);index;not null" json:"module"`
+	EntityType    string      `gorm:"type:varchar(50);index:idx_audit_logs_entity;not null" json:"entity_type"` // Table name
+	EntityID      uint        `gorm:"index:idx_audit_logs_entity" json:"entity_id"`
+	Before        *string     `gorm:"type:jsonb" json:"before"`
+	After         *string     `gorm:"type:jsonb" json:"after"`
+	CreatedAt     time.Time   `gorm:"index:idx_audit_logs_school_time" json:"created_at"`
+}
+
+// TableName specifies the table name for AuditLog
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -14,6 +14,8 @@ type Class struct {
 	Grade             int       `gorm:"not null" json:"grade"` // e.g., 7, 8, 9 for SMP
 	Year              string    `gorm:"type:varchar(10);not null" json:"year"` // e.g., "2024/2025"
 	HomeroomTeacherID *uint     `gorm:"index" json:"homeroom_teacher_id"`
+	IsArchived        bool       `gorm:"default:false;index" json:"is_archived"` // Read-only after the academic year rollover
+	ArchivedAt        *time.Time `json:"archived_at"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// CounselingCaseStatus represents the state of a counseling case
+type CounselingCaseStatus string
+
+const (
+	CounselingCaseStatusOpen       CounselingCaseStatus = "open"
+	CounselingCaseStatusInProgress CounselingCaseStatus = "in_progress"
+	CounselingCaseStatusResolved   CounselingCaseStatus = "resolved"
+	CounselingCaseStatusReferred   CounselingCaseStatus = "referred" // Handed over outside the school, e.g. to a psychologist
+)
+
+// IsValid checks if the case status is valid
+func (s CounselingCaseStatus) IsValid() bool {
+	switch s {
+	case CounselingCaseStatusOpen, CounselingCaseStatusInProgress,
+		CounselingCaseStatusResolved, CounselingCaseStatusReferred:
+		return true
+	}
+	return false
+}
+
+// IsClosed checks if the status ends the case
+func (s CounselingCaseStatus) IsClosed() bool {
+	return s == CounselingCaseStatusResolved || s == CounselingCaseStatusReferred
+}
+
+// CounselingCase tracks a student's counseling from referral through sessions to closure
+// Description is internal to Guru BK, like CounselingNote.InternalNote.
+type CounselingCase struct {
+	ID          uint                 `gorm:"primaryKey" json:"id"`
+	SchoolID    uint                 `gorm:"index;not null" json:"school_id"`
+	StudentID   uint                 `gorm:"index;not null" json:"student_id"`
+	ClassID     *uint                `gorm:"index" json:"class_id"` // Class when the case was opened
+	Title       string               `gorm:"type:varchar(200);not null" json:"title"`
+	Description string               `gorm:"type:text" json:"-"`
+	Status      CounselingCaseStatus `gorm:"type:varchar(20);not null;default:'open';index" json:"status"`
+	CounselorID *uint                `gorm:"index" json:"counselor_id"` // Guru BK handling the case
+	OpenedBy    uint                 `gorm:"not null" json:"opened_by"`
+	Outcome     string               `gorm:"type:text" json:"outcome"`
+	ReferredTo  string               `gorm:"type:varchar(200)" json:"referred_to"`
+	ClosedBy    *uint                `json:"closed_by"`
+	ClosedAt    *time.Time           `gorm:"index" json:"closed_at"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+
+	// Relations
+	Student    Student              `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	Class      *Class               `gorm:"foreignKey:ClassID" json:"class,omitempty"`
+	Counselor  *User                `gorm:"foreignKey:CounselorID" json:"counselor,omitempty"`
+	Opener     User                 `gorm:"foreignKey:OpenedBy" json:"opener,omitempty"`
+	Closer     *User                `gorm:"foreignKey:ClosedBy" json:"closer,omitempty"`
+	Sessions   []CounselingSession  `gorm:"foreignKey:CaseID" json:"sessions,omitempty"`
+	FollowUps  []CounselingFollowUp `gorm:"foreignKey:CaseID" json:"follow_ups,omitempty"`
+	Violations []Violation          `gorm:"many2many:counseling_case_violations;constraint:OnDelete:CASCADE" json:"violations,omitempty"`
+	Permits    []Permit             `gorm:"many2many:counseling_case_permits;constraint:OnDelete:CASCADE" json:"permits,omitempty"`
+}
+
+// TableName specifies the table name for CounselingCase
+func (CounselingCase) TableName() string {
+	return "counseling_cases"
+}
+
+// Validate validates the case data
+func (c *CounselingCase) Validate() error {
+	if c.SchoolID == 0 {
+		return errors.New("school_id is required")
+	}
+	if c.StudentID == 0 {
+		return errors.New("student_id is required")
+	}
+	if strings.TrimSpace(c.Title) == "" {
+		return errors.New("title is required")
+	}
+	if !c.Status.IsValid() {
+		return errors.New("status must be one of: open, in_progress, resolved, referred")
+	}
+	return nil
+}
+
+// IsClosed checks if the case has been resolved or referred
+func (c *CounselingCase) IsClosed() bool {
+	return c.Status.IsClosed()
+}
+
+// CounselingSessionStatus represents the state of a counseling session
+type CounselingSessionStatus string
+
+const (
+	CounselingSessionStatusScheduled CounselingSessionStatus = "scheduled"
+	CounselingSessionStatusHeld      CounselingSessionStatus = "held"
+	CounselingSessionStatusCancelled CounselingSessionStatus = "cancelled"
+	CounselingSessionStatusMissed    CounselingSessionStatus = "missed" // The student did not come
+)
+
+// IsValid checks if the session status is valid
+func (s CounselingSessionStatus) IsValid() bool {
+	switch s {
+	case CounselingSessionStatusScheduled, CounselingSessionStatusHeld,
+		CounselingSessionStatusCancelled, CounselingSessionStatusMissed:
+		return true
+	}
+	return false
+}
+
+// CounselingSession is a scheduled meeting of a counseling case
+// The notes follow CounselingNote: the internal note stays with Guru BK.
+type CounselingSession struct {
+	ID              uint                    `gorm:"primaryKey" json:"id"`
+	CaseID          uint                    `gorm:"index;not null" json:"case_id"`
+	ScheduledAt     time.Time               `gorm:"index;not null" json:"scheduled_at"`
+	DurationMinutes int                     `gorm:"not null;default:45" json:"duration_minutes"`
+	Location        string                  `gorm:"type:varchar(100)" json:"location"`
+	Status          CounselingSessionStatus `gorm:"type:varchar(20);not null;default:'scheduled'" json:"status"`
+	InternalNote    string                  `gorm:"type:text" json:"-"`
+	ParentSummary   string                  `gorm:"type:text" json:"parent_summary"`
+	HeldAt          *time.Time              `json:"held_at"`
+	CreatedBy       uint                    `gorm:"not null" json:"created_by"`
+	CreatedAt       time.Time               `json:"created_at"`
+	UpdatedAt       time.Time               `json:"updated_at"`
+
+	// Relations
+	Creator User `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+}
+
+// TableName specifies the table name for CounselingSession
+func (CounselingSession) TableName() string {
+	return "counseling_sessions"
+}
+
+// CounselingFollowUp is a dated task of a counseling case, e.g. checking in with the student
+// after two weeks. The assignee is reminded once the task falls due.
+type CounselingFollowUp struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	CaseID         uint       `gorm:"index;not null" json:"case_id"`
+	DueAt          time.Time  `gorm:"index;not null" json:"due_at"`
+	Note           string     `gorm:"type:text;not null" json:"note"`
+	AssignedTo     uint       `gorm:"index;not null" json:"assigned_to"`
+	ReminderSentAt *time.Time `json:"reminder_sent_at"`
+	CompletedBy    *uint      `json:"completed_by"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CreatedBy      uint       `gorm:"not null" json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// Relations
+	Assignee  User  `gorm:"foreignKey:AssignedTo" json:"assignee,omitempty"`
+	Completer *User `gorm:"foreignKey:CompletedBy" json:"completer,omitempty"`
+}
+
+// TableName specifies the table name for CounselingFollowUp
+func (CounselingFollowUp) TableName() string {
+	return "counseling_follow_ups"
+}
+
+// IsCompleted checks if the follow-up has been done
+func (f *CounselingFollowUp) IsCompleted() bool {
+	return f.CompletedAt != nil
+}
@@ -10,6 +10,7 @@ import (
 type CounselingNote struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
 	StudentID     uint      `gorm:"index;not null" json:"student_id"`
+	ClassID       *uint     `gorm:"index" json:"class_id"`       // Class at the time (see Attendance.ClassID)
 	InternalNote  string    `gorm:"type:text;not null" json:"-"` // Hidden from JSON by default
 	ParentSummary string    `gorm:"type:text" json:"parent_summary"`
 	CreatedBy     uint      `gorm:"not null" json:"created_by"`
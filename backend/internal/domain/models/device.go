@@ -1,24 +1,46 @@
 package models
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"errors"
 	"strings"
 	"time"
 )
 
+// APIKeyPrefixLength is the number of leading API key characters used as the key ID.
+// Signed requests identify their key by this prefix instead of sending the key itself.
+const APIKeyPrefixLength = 16
+
 // Device represents RFID device (ESP32)
 type Device struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	SchoolID    uint       `gorm:"index;not null" json:"school_id"`
-	DeviceCode  string     `gorm:"type:varchar(50);uniqueIndex;not null" json:"device_code"`
-	APIKey      string     `gorm:"type:varchar(255);uniqueIndex;not null" json:"-"` // Hidden from JSON
-	Description string     `gorm:"type:varchar(255)" json:"description"`
-	IsActive    bool       `gorm:"default:true" json:"is_active"`
-	LastSeenAt  *time.Time `json:"last_seen_at"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	SchoolID     uint       `gorm:"index;not null" json:"school_id"`
+	DeviceCode   string     `gorm:"type:varchar(50);uniqueIndex;not null" json:"device_code"`
+	APIKeyHash   string     `gorm:"column:api_key;type:varchar(255);uniqueIndex;not null" json:"-"` // SHA-256 of the API key, only used to look the key up
+	APIKeyPrefix string     `gorm:"type:varchar(16);index" json:"api_key_prefix"`
+	Description  string     `gorm:"type:varchar(255)" json:"description"`
+	IsActive     bool       `gorm:"default:true" json:"is_active"`
+	LastSeenAt   *time.Time `json:"last_seen_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Health: latest heartbeat and offline alert state
+	FirmwareVersion  string     `gorm:"type:varchar(32)" json:"firmware_version"`
+	LastHeartbeatAt  *time.Time `json:"last_heartbeat_at"`
+	OfflineAlertedAt *time.Time `json:"offline_alerted_at,omitempty"` // Set when an offline alert was sent, cleared on the next contact
+
+	// Key rotation: the replaced key keeps working until PreviousKeyExpiresAt
+	PreviousKeyHash      string     `gorm:"type:varchar(64);index" json:"-"`
+	PreviousKeyPrefix    string     `gorm:"type:varchar(16);index" json:"-"`
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at,omitempty"`
+
+	// APIKey is the plaintext key. It is only set right after generation so it can be
+	// shown once; the database stores the hash only.
+	APIKey string `gorm:"-" json:"-"`
 
 	// Relations
 	School School `gorm:"foreignKey:SchoolID" json:"school,omitempty"`
@@ -41,16 +63,119 @@ func (d *Device) Validate() error {
 	return nil
 }
 
+// DeviceConnectionStatus represents whether a device is reachable
+type DeviceConnectionStatus string
+
+const (
+	DeviceStatusOnline   DeviceConnectionStatus = "online"
+	DeviceStatusOffline  DeviceConnectionStatus = "offline"
+	DeviceStatusInactive DeviceConnectionStatus = "inactive" // API key revoked
+)
+
+// ConnectionStatus returns the device status; a device is online if it contacted the
+// server within the offline threshold
+func (d *Device) ConnectionStatus(now time.Time, offlineAfter time.Duration) DeviceConnectionStatus {
+	if !d.IsActive {
+		return DeviceStatusInactive
+	}
+	if d.LastSeenAt == nil || now.Sub(*d.LastSeenAt) > offlineAfter {
+		return DeviceStatusOffline
+	}
+	return DeviceStatusOnline
+}
+
 // GenerateAPIKey generates a new unique API key for the device
+// Only the hash is persisted; the plaintext key is kept in APIKey to be shown once
 func (d *Device) GenerateAPIKey() error {
 	bytes := make([]byte, 32)
 	if _, err := rand.Read(bytes); err != nil {
 		return err
 	}
-	d.APIKey = hex.EncodeToString(bytes)
+	d.SetAPIKey(hex.EncodeToString(bytes))
 	return nil
 }
 
+// SetAPIKey sets the plaintext key together with its hash and prefix
+func (d *Device) SetAPIKey(key string) {
+	d.APIKey = key
+	d.APIKeyHash = HashAPIKey(key)
+	d.APIKeyPrefix = APIKeyPrefix(key)
+}
+
+// RotateAPIKey generates a new API key while the current one keeps working for the
+// grace period. A zero grace period invalidates the current key immediately.
+func (d *Device) RotateAPIKey(grace time.Duration) error {
+	previousHash, previousPrefix := d.APIKeyHash, d.APIKeyPrefix
+	if err := d.GenerateAPIKey(); err != nil {
+		return err
+	}
+
+	if grace <= 0 || previousHash == "" {
+		d.PreviousKeyHash = ""
+		d.PreviousKeyPrefix = ""
+		d.PreviousKeyExpiresAt = nil
+		return nil
+	}
+
+	expiresAt := time.Now().Add(grace)
+	d.PreviousKeyHash = previousHash
+	d.PreviousKeyPrefix = previousPrefix
+	d.PreviousKeyExpiresAt = &expiresAt
+	return nil
+}
+
+// SigningSecrets returns the signing secrets of the keys identified by the prefix.
+// During a rotation window both the current and the previous key may match.
+func (d *Device) SigningSecrets(pepper, prefix string, now time.Time) []string {
+	var secrets []string
+	if d.APIKeyPrefix == prefix && d.APIKeyHash != "" {
+		secrets = append(secrets, SigningSecret(pepper, d.APIKeyHash))
+	}
+	if d.PreviousKeyPrefix == prefix && d.previousKeyValid(now) {
+		secrets = append(secrets, SigningSecret(pepper, d.PreviousKeyHash))
+	}
+	return secrets
+}
+
+// MatchesAPIKey checks a plaintext key against the current key and, during a
+// rotation window, the previous key
+func (d *Device) MatchesAPIKey(key string, now time.Time) bool {
+	hash := HashAPIKey(key)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(d.APIKeyHash)) == 1 {
+		return true
+	}
+	return d.previousKeyValid(now) && subtle.ConstantTimeCompare([]byte(hash), []byte(d.PreviousKeyHash)) == 1
+}
+
+// previousKeyValid checks if the key replaced by the last rotation is still accepted
+func (d *Device) previousKeyValid(now time.Time) bool {
+	return d.PreviousKeyHash != "" && d.PreviousKeyExpiresAt != nil && now.Before(*d.PreviousKeyExpiresAt)
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 hash of an API key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// SigningSecret derives the request signing secret of an API key from its hash.
+// The pepper is server configuration and never stored, so reading the hashes in the
+// database is not enough to sign device requests. The secret is handed to the device
+// together with the key and is not stored either.
+func SigningSecret(pepper, keyHash string) string {
+	mac := hmac.New(sha256.New, []byte(pepper))
+	mac.Write([]byte(keyHash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// APIKeyPrefix returns the key ID of an API key
+func APIKeyPrefix(key string) string {
+	if len(key) <= APIKeyPrefixLength {
+		return key
+	}
+	return key[:APIKeyPrefixLength]
+}
+
 // UpdateLastSeen updates the last seen timestamp
 func (d *Device) UpdateLastSeen() {
 	now := time.Now()
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// DeviceHeartbeat is a health report sent periodically by an RFID device
+type DeviceHeartbeat struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	DeviceID        uint      `gorm:"not null;index:idx_device_heartbeat_device_time" json:"device_id"`
+	FirmwareVersion string    `gorm:"type:varchar(32)" json:"firmware_version"`
+	RSSI            int       `json:"rssi"`           // WiFi signal strength in dBm
+	UptimeSeconds   int64     `json:"uptime_seconds"` // Time since the device booted
+	FreeHeap        int64     `json:"free_heap"`      // Free memory in bytes
+	QueuedTaps      int       `json:"queued_taps"`    // Offline taps waiting to be synced
+	IPAddress       string    `gorm:"type:varchar(45)" json:"ip_address,omitempty"`
+	CreatedAt       time.Time `gorm:"index:idx_device_heartbeat_device_time" json:"created_at"`
+
+	// Relations
+	Device Device `gorm:"foreignKey:DeviceID;constraint:OnDelete:CASCADE" json:"device,omitempty"`
+}
+
+// TableName specifies the table name for DeviceHeartbeat
+func (DeviceHeartbeat) TableName() string {
+	return "device_heartbeats"
+}
+
+// Validate validates the heartbeat data
+func (h *DeviceHeartbeat) Validate() error {
+	if h.DeviceID == 0 {
+		return errors.New("device_id is required")
+	}
+	if len(h.FirmwareVersion) > 32 {
+		return errors.New("firmware_version must be at most 32 characters")
+	}
+	if h.UptimeSeconds < 0 || h.FreeHeap < 0 || h.QueuedTaps < 0 {
+		return errors.New("uptime_seconds, free_heap and queued_taps cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSigningSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		pepper string
+		key    string
+		want   string
+	}{
+		{
+			name:   "known secret",
+			pepper: "test-pepper",
+			key:    "dev_0123456789abcdef",
+			want:   "69dfef50e9d2ba174c3f52fac39d6af37055926da4f5f63ff233a547ef662b73",
+		},
+		{
+			name:   "other pepper",
+			pepper: "other-pepper",
+			key:    "dev_0123456789abcdef",
+			want:   "b074d32f3af3c1b3e7a97866b5b635e162bbd94550f18f9ff0a02ff6ffad55d3",
+		},
+		{
+			name:   "other key",
+			pepper: "test-pepper",
+			key:    "dev_fedcba9876543210",
+			want:   "c708c3ad3f5cbcd8306ecf9d437c75cd39ceef8f0e424facd4d5ffcef9a89785",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SigningSecret(tt.pepper, HashAPIKey(tt.key)); got != tt.want {
+				t.Errorf("SigningSecret() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigningSecrets(t *testing.T) {
+	const pepper = "test-pepper"
+	const current = "dev_aaaaaaaaaaaaaaaa-current"
+	const previous = "dev_bbbbbbbbbbbbbbbb-previous"
+
+	now := time.Date(2026, 1, 5, 7, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+
+	rotated := func(expiresAt *time.Time) *Device {
+		d := &Device{}
+		d.SetAPIKey(current)
+		d.PreviousKeyHash = HashAPIKey(previous)
+		d.PreviousKeyPrefix = APIKeyPrefix(previous)
+		d.PreviousKeyExpiresAt = expiresAt
+		return d
+	}
+	currentSecret := SigningSecret(pepper, HashAPIKey(current))
+	previousSecret := SigningSecret(pepper, HashAPIKey(previous))
+
+	tests := []struct {
+		name   string
+		device *Device
+		prefix string
+		now    time.Time
+		want   []string
+	}{
+		{
+			name:   "current key",
+			device: rotated(&expiresAt),
+			prefix: APIKeyPrefix(current),
+			now:    now,
+			want:   []string{currentSecret},
+		},
+		{
+			name:   "previous key within the grace window",
+			device: rotated(&expiresAt),
+			prefix: APIKeyPrefix(previous),
+			now:    now,
+			want:   []string{previousSecret},
+		},
+		{
+			name:   "previous key when the grace window ends",
+			device: rotated(&expiresAt),
+			prefix: APIKeyPrefix(previous),
+			now:    expiresAt,
+			want:   nil,
+		},
+		{
+			name:   "previous key without a grace window",
+			device: rotated(nil),
+			prefix: APIKeyPrefix(previous),
+			now:    now,
+			want:   nil,
+		},
+		{
+			name:   "unknown prefix",
+			device: rotated(&expiresAt),
+			prefix: "dev_cccccccccccc",
+			now:    now,
+			want:   nil,
+		},
+		{
+			name:   "device without a key",
+			device: &Device{},
+			prefix: "",
+			now:    now,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.device.SigningSecrets(pepper, tt.prefix, tt.now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SigningSecrets() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("SigningSecrets()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		grace       time.Duration
+		keepsOldKey bool
+	}{
+		{name: "with grace period", grace: time.Hour, keepsOldKey: true},
+		{name: "without grace period", grace: 0, keepsOldKey: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Device{}
+			if err := d.GenerateAPIKey(); err != nil {
+				t.Fatal(err)
+			}
+			oldKey := d.APIKey
+
+			if err := d.RotateAPIKey(tt.grace); err != nil {
+				t.Fatal(err)
+			}
+			now := time.Now()
+
+			if !d.MatchesAPIKey(d.APIKey, now) {
+				t.Error("new key does not match")
+			}
+			if got := d.MatchesAPIKey(oldKey, now); got != tt.keepsOldKey {
+				t.Errorf("old key matches = %v, want %v", got, tt.keepsOldKey)
+			}
+			if tt.keepsOldKey && d.MatchesAPIKey(oldKey, now.Add(tt.grace+time.Second)) {
+				t.Error("old key still matches after the grace period")
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"errors"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FirmwareRelease is an uploaded firmware build for the RFID readers
+type FirmwareRelease struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Version    string    `gorm:"type:varchar(32);uniqueIndex;not null" json:"version"`
+	Checksum   string    `gorm:"type:varchar(64);not null" json:"checksum"` // Hex SHA-256 of the binary
+	MD5        string    `gorm:"type:varchar(32);not null" json:"md5"`      // Hex MD5, checked by the ESP8266 updater
+	Size       int64     `gorm:"not null" json:"size"`
+	FileName   string    `gorm:"type:varchar(255)" json:"file_name"`
+	FilePath   string    `gorm:"type:varchar(500);not null" json:"-"`
+	Notes      string    `gorm:"type:text" json:"notes"`
+	UploadedBy uint      `gorm:"index" json:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for FirmwareRelease
+func (FirmwareRelease) TableName() string {
+	return "firmware_releases"
+}
+
+// Validate validates the firmware release data
+func (f *FirmwareRelease) Validate() error {
+	if strings.TrimSpace(f.Version) == "" {
+		return errors.New("version is required")
+	}
+	if len(f.Version) > 32 {
+		return errors.New("version must be at most 32 characters")
+	}
+	if len(f.Checksum) != 64 || len(f.MD5) != 32 {
+		return errors.New("checksum and md5 are required")
+	}
+	if f.Size <= 0 {
+		return errors.New("size must be positive")
+	}
+	return nil
+}
+
+// FirmwareTargetType represents the scope of a firmware rollout
+type FirmwareTargetType string
+
+const (
+	FirmwareTargetDevice FirmwareTargetType = "device" // A single device
+	FirmwareTargetSchool FirmwareTargetType = "school" // A percentage of one school's devices
+	FirmwareTargetAll    FirmwareTargetType = "all"    // A percentage of all devices
+)
+
+// IsValid checks if the target type is valid
+func (t FirmwareTargetType) IsValid() bool {
+	switch t {
+	case FirmwareTargetDevice, FirmwareTargetSchool, FirmwareTargetAll:
+		return true
+	}
+	return false
+}
+
+// Priority orders overlapping rollouts: a more specific target wins
+func (t FirmwareTargetType) Priority() int {
+	switch t {
+	case FirmwareTargetDevice:
+		return 3
+	case FirmwareTargetSchool:
+		return 2
+	case FirmwareTargetAll:
+		return 1
+	}
+	return 0
+}
+
+// FirmwareRollout assigns a firmware release to a device, a school or all devices
+// School and global rollouts can be staged: only the given percentage of devices gets the
+// update, and raising the percentage keeps the devices that were already included.
+type FirmwareRollout struct {
+	ID         uint               `gorm:"primaryKey" json:"id"`
+	FirmwareID uint               `gorm:"index;not null" json:"firmware_id"`
+	TargetType FirmwareTargetType `gorm:"type:varchar(20);not null" json:"target_type"`
+	TargetID   *uint              `gorm:"index" json:"target_id,omitempty"` // Device or school ID
+	Percentage int                `gorm:"not null;default:100" json:"percentage"`
+	IsActive   bool               `gorm:"default:true" json:"is_active"`
+	CreatedBy  uint               `json:"created_by"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+
+	// Relations
+	Firmware FirmwareRelease `gorm:"foreignKey:FirmwareID;constraint:OnDelete:CASCADE" json:"firmware,omitempty"`
+}
+
+// TableName specifies the table name for FirmwareRollout
+func (FirmwareRollout) TableName() string {
+	return "firmware_rollouts"
+}
+
+// Validate validates the rollout data
+func (r *FirmwareRollout) Validate() error {
+	if r.FirmwareID == 0 {
+		return errors.New("firmware_id is required")
+	}
+	if !r.TargetType.IsValid() {
+		return errors.New("target_type must be one of: device, school, all")
+	}
+	if r.TargetType == FirmwareTargetAll && r.TargetID != nil {
+		return errors.New("target_id must be empty for target_type all")
+	}
+	if r.TargetType != FirmwareTargetAll && (r.TargetID == nil || *r.TargetID == 0) {
+		return errors.New("target_id is required")
+	}
+	if r.Percentage < 1 || r.Percentage > 100 {
+		return errors.New("percentage must be between 1 and 100")
+	}
+	if r.TargetType == FirmwareTargetDevice && r.Percentage != 100 {
+		return errors.New("percentage must be 100 for target_type device")
+	}
+	return nil
+}
+
+// Includes checks if the rollout applies to the device
+func (r *FirmwareRollout) Includes(device *Device) bool {
+	if !r.IsActive {
+		return false
+	}
+	switch r.TargetType {
+	case FirmwareTargetDevice:
+		return r.TargetID != nil && *r.TargetID == device.ID
+	case FirmwareTargetSchool:
+		if r.TargetID == nil || *r.TargetID != device.SchoolID {
+			return false
+		}
+	case FirmwareTargetAll:
+	default:
+		return false
+	}
+	return r.bucket(device.ID) < r.Percentage
+}
+
+// bucket places a device in one of 100 stable buckets for this rollout
+func (r *FirmwareRollout) bucket(deviceID uint) int {
+	h := fnv.New32a()
+	h.Write([]byte(strconv.FormatUint(uint64(r.ID), 10) + ":" + strconv.FormatUint(uint64(deviceID), 10)))
+	return int(h.Sum32() % 100)
+}
+
+// FirmwareInstallStatus represents the state reported by a device for an update
+type FirmwareInstallStatus string
+
+const (
+	FirmwareInstallDownloading FirmwareInstallStatus = "downloading"
+	FirmwareInstallInstalled   FirmwareInstallStatus = "installed"
+	FirmwareInstallFailed      FirmwareInstallStatus = "failed"
+)
+
+// IsValid checks if the install status is valid
+func (s FirmwareInstallStatus) IsValid() bool {
+	switch s {
+	case FirmwareInstallDownloading, FirmwareInstallInstalled, FirmwareInstallFailed:
+		return true
+	}
+	return false
+}
+
+// FirmwareInstall is an update progress or result reported by a device
+type FirmwareInstall struct {
+	ID              uint                  `gorm:"primaryKey" json:"id"`
+	DeviceID        uint                  `gorm:"index:idx_firmware_install_device_firmware;not null" json:"device_id"`
+	FirmwareID      uint                  `gorm:"index:idx_firmware_install_device_firmware;not null" json:"firmware_id"`
+	Status          FirmwareInstallStatus `gorm:"type:varchar(20);not null" json:"status"`
+	PreviousVersion string                `gorm:"type:varchar(32)" json:"previous_version"`
+	Message         string                `gorm:"type:text" json:"message,omitempty"`
+	CreatedAt       time.Time             `gorm:"index" json:"created_at"`
+
+	// Relations
+	Device   Device          `gorm:"foreignKey:DeviceID;constraint:OnDelete:CASCADE" json:"device,omitempty"`
+	Firmware FirmwareRelease `gorm:"foreignKey:FirmwareID;constraint:OnDelete:CASCADE" json:"firmware,omitempty"`
+}
+
+// TableName specifies the table name for FirmwareInstall
+func (FirmwareInstall) TableName() string {
+	return "firmware_installs"
+}
+
+// Validate validates the install report
+func (i *FirmwareInstall) Validate() error {
+	if i.DeviceID == 0 || i.FirmwareID == 0 {
+		return errors.New("device_id and firmware_id are required")
+	}
+	if !i.Status.IsValid() {
+		return errors.New("status must be one of: downloading, installed, failed")
+	}
+	return nil
+}
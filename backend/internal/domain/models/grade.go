@@ -7,19 +7,28 @@ import (
 )
 
 // Grade represents student grade entry
+// Grades recorded before subjects were introduced have no subject, category or period and
+// are left out of the weighted report-card averages.
 type Grade struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	StudentID   uint      `gorm:"index;not null" json:"student_id"`
-	Title       string    `gorm:"type:varchar(255);not null" json:"title"`
-	Score       float64   `gorm:"not null" json:"score"`
-	Description string    `gorm:"type:text" json:"description"`
-	CreatedBy   uint      `gorm:"not null" json:"created_by"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                   uint      `gorm:"primaryKey" json:"id"`
+	StudentID            uint      `gorm:"index;not null" json:"student_id"`
+	ClassID              *uint     `gorm:"index" json:"class_id"` // Class at the time (see Attendance.ClassID)
+	SubjectID            *uint     `gorm:"index" json:"subject_id"`
+	AssessmentCategoryID *uint     `gorm:"index" json:"assessment_category_id"`
+	AcademicYear         string    `gorm:"type:varchar(10);index:idx_grades_period" json:"academic_year"` // From SchoolSettings when recorded
+	Semester             int       `gorm:"index:idx_grades_period" json:"semester"`
+	Title                string    `gorm:"type:varchar(255);not null" json:"title"`
+	Score                float64   `gorm:"not null" json:"score"`
+	Description          string    `gorm:"type:text" json:"description"`
+	CreatedBy            uint      `gorm:"not null" json:"created_by"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
 
 	// Relations
-	Student Student `gorm:"foreignKey:StudentID" json:"student,omitempty"`
-	Creator User    `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+	Student            Student             `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	Subject            *Subject            `gorm:"foreignKey:SubjectID" json:"subject,omitempty"`
+	AssessmentCategory *AssessmentCategory `gorm:"foreignKey:AssessmentCategoryID" json:"assessment_category,omitempty"`
+	Creator            User                `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
 }
 
 // TableName specifies the table name for Grade
@@ -39,6 +48,9 @@ func (g *Grade) Validate() error {
 	if g.Score < 0 || g.Score > 100 {
 		return errors.New("score must be between 0 and 100")
 	}
+	if g.Semester != 0 && g.Semester != 1 && g.Semester != 2 {
+		return errors.New("semester must be 1 or 2")
+	}
 	if g.CreatedBy == 0 {
 		return errors.New("created_by is required")
 	}
@@ -10,6 +10,7 @@ import (
 type HomeroomNote struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	StudentID uint      `gorm:"index;not null" json:"student_id"`
+	ClassID   *uint     `gorm:"index" json:"class_id"` // Class at the time (see Attendance.ClassID)
 	TeacherID uint      `gorm:"not null" json:"teacher_id"`
 	Content   string    `gorm:"type:text;not null" json:"content"`
 	CreatedAt time.Time `json:"created_at"`
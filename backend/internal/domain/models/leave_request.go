@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// LeaveType represents the kind of absence a parent asks leave for
+type LeaveType string
+
+const (
+	LeaveTypeSick    LeaveType = "sick"    // Sakit
+	LeaveTypeExcused LeaveType = "excused" // Izin
+)
+
+// IsValid checks if the leave type is valid
+func (t LeaveType) IsValid() bool {
+	switch t {
+	case LeaveTypeSick, LeaveTypeExcused:
+		return true
+	}
+	return false
+}
+
+// AttendanceStatus returns the attendance status recorded for the days of an approved leave
+func (t LeaveType) AttendanceStatus() AttendanceStatus {
+	if t == LeaveTypeSick {
+		return AttendanceStatusSick
+	}
+	return AttendanceStatusExcused
+}
+
+// LeaveStatus represents where a leave request is in the approval flow
+type LeaveStatus string
+
+const (
+	LeaveStatusPending   LeaveStatus = "pending"
+	LeaveStatusApproved  LeaveStatus = "approved"
+	LeaveStatusRejected  LeaveStatus = "rejected"
+	LeaveStatusCancelled LeaveStatus = "cancelled" // Withdrawn by the parent before review
+)
+
+// IsValid checks if the leave status is valid
+func (s LeaveStatus) IsValid() bool {
+	switch s {
+	case LeaveStatusPending, LeaveStatusApproved, LeaveStatusRejected, LeaveStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// LeaveRequest is a parent's request to excuse a student from school for a range of days
+// Approving it records sick or excused attendance for every school day in the range.
+type LeaveRequest struct {
+	ID             uint        `gorm:"primaryKey" json:"id"`
+	SchoolID       uint        `gorm:"index;not null" json:"school_id"`
+	StudentID      uint        `gorm:"index;not null" json:"student_id"`
+	ClassID        *uint       `gorm:"index" json:"class_id"` // Class when submitted, reviewed by its wali kelas
+	Type           LeaveType   `gorm:"type:varchar(20);not null" json:"type"`
+	StartDate      time.Time   `gorm:"type:date;not null" json:"start_date"`
+	EndDate        time.Time   `gorm:"type:date;not null" json:"end_date"`
+	Reason         string      `gorm:"type:text;not null" json:"reason"`
+	AttachmentPath string      `gorm:"type:varchar(500)" json:"-"` // Photo of the doctor's note on disk
+	AttachmentName string      `gorm:"type:varchar(255)" json:"attachment_name"`
+	AttachmentType string      `gorm:"type:varchar(50)" json:"attachment_type"`
+	Status         LeaveStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	SubmittedBy    uint        `gorm:"not null" json:"submitted_by"`
+	ReviewedBy     *uint       `json:"reviewed_by"`
+	ReviewedAt     *time.Time  `json:"reviewed_at"`
+	ReviewNote     string      `gorm:"type:text" json:"review_note"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+
+	// Relations
+	Student   Student `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	Class     *Class  `gorm:"foreignKey:ClassID" json:"class,omitempty"`
+	Submitter User    `gorm:"foreignKey:SubmittedBy" json:"submitter,omitempty"`
+	Reviewer  *User   `gorm:"foreignKey:ReviewedBy" json:"reviewer,omitempty"`
+}
+
+// TableName specifies the table name for LeaveRequest
+func (LeaveRequest) TableName() string {
+	return "leave_requests"
+}
+
+// Validate validates the leave request data
+func (l *LeaveRequest) Validate() error {
+	if l.SchoolID == 0 {
+		return errors.New("school_id is required")
+	}
+	if l.StudentID == 0 {
+		return errors.New("student_id is required")
+	}
+	if !l.Type.IsValid() {
+		return errors.New("type must be sick or excused")
+	}
+	if l.StartDate.IsZero() || l.EndDate.IsZero() {
+		return errors.New("start_date and end_date are required")
+	}
+	if l.EndDate.Before(l.StartDate) {
+		return errors.New("end_date must not be before start_date")
+	}
+	if strings.TrimSpace(l.Reason) == "" {
+		return errors.New("reason is required")
+	}
+	if !l.Status.IsValid() {
+		return errors.New("invalid status")
+	}
+	if l.SubmittedBy == 0 {
+		return errors.New("submitted_by is required")
+	}
+	return nil
+}
+
+// IsPending checks if the request is still waiting for review
+func (l *LeaveRequest) IsPending() bool {
+	return l.Status == LeaveStatusPending
+}
+
+// Covers checks if the leave includes the date
+func (l *LeaveRequest) Covers(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	return day >= l.StartDate.Format("2006-01-02") && day <= l.EndDate.Format("2006-01-02")
+}
@@ -10,26 +10,41 @@ import "errors"
 // Core Models:
 //   - school.go: School (tenant) model
 //   - user.go: User model with roles
+//   - user_session.go: Signed-in devices backing rotated refresh tokens
+//   - password_reset_code.go: One-time codes for self-service password reset
+//   - user_two_factor.go: Authenticator app (TOTP) secrets and recovery codes
 //   - class.go: Class model
+//   - academic_year_rollover.go: Academic year rollovers (class cloning, promotion and archiving)
 //   - student.go: Student model
+//   - student_enrollment.go: Class membership timeline of students with reason codes
 //   - parent.go: Parent model
 //
 // Attendance:
 //   - attendance.go: Attendance record model
 //   - attendance_schedule.go: Attendance schedule model for multi-schedule support
+//   - school_calendar.go: School holiday calendar and per-date schedule overrides
+//   - attendance_sync.go: Idempotency receipts for taps synced by offline devices
+//   - leave_request.go: Sick/excused leave requests submitted by parents
 //
 // BK (Counseling) Models:
 //   - violation.go: Violation record model
 //   - achievement.go: Achievement record model
 //   - permit.go: Exit permit model
 //   - counseling_note.go: Counseling note model
+//   - counseling_case.go: Counseling cases with their sessions and follow-ups
+//   - violation_escalation.go: Violation point thresholds and the escalations they trigger
 //
 // Academic Models:
 //   - grade.go: Grade entry model
+//   - subject.go: Subjects with KKM and weighted assessment categories
+//   - teaching_assignment.go: Teacher x subject x class x semester teaching assignments
 //   - homeroom_note.go: Homeroom teacher note model
+//   - report_card.go: Report card (rapor) templates, narratives and generation jobs
 //
 // Device & Notification:
 //   - device.go: RFID device (ESP32) model
+//   - device_heartbeat.go: Device health telemetry history
+//   - firmware.go: Firmware releases, rollouts and install reports for OTA updates
 //   - notification.go: Notification and FCM token models
 //
 // Display:
@@ -40,6 +55,9 @@ import "errors"
 //
 // Event Outbox:
 //   - outbox.go: Outbox event model for reliable event publishing
+//
+// Audit:
+//   - audit_log.go: Append-only audit trail of changes to school data
 
 // Common validation errors
 var (
@@ -55,28 +73,53 @@ func AllModels() []interface{} {
 		// Core models
 		&School{},
 		&User{},
+		&UserSession{},
+		&PasswordResetCode{},
+		&UserTwoFactor{},
+		&TwoFactorRecoveryCode{},
 		&Class{},
 		&Student{},
 		&Parent{},
 		&StudentParent{},
+		&StudentEnrollment{},
 		&ClassCounselor{},
+		&AcademicYearRollover{},
 
 		// Attendance
 		&Attendance{},
 		&AttendanceSchedule{},
+		&SchoolHoliday{},
+		&ScheduleOverride{},
+		&AttendanceSyncReceipt{},
+		&LeaveRequest{},
 
 		// BK models
 		&Violation{},
 		&Achievement{},
 		&Permit{},
 		&CounselingNote{},
+		&CounselingCase{},
+		&CounselingSession{},
+		&CounselingFollowUp{},
+		&ViolationThreshold{},
+		&ViolationEscalation{},
 
 		// Academic models
+		&Subject{},
+		&AssessmentCategory{},
+		&TeachingAssignment{},
 		&Grade{},
 		&HomeroomNote{},
+		&ReportCardTemplate{},
+		&ReportCardNarrative{},
+		&ReportCardJob{},
 
 		// Device & Notification
 		&Device{},
+		&DeviceHeartbeat{},
+		&FirmwareRelease{},
+		&FirmwareRollout{},
+		&FirmwareInstall{},
 		&Notification{},
 		&FCMToken{},
 
@@ -88,6 +131,9 @@ func AllModels() []interface{} {
 
 		// Outbox
 		&OutboxEvent{},
+
+		// Audit
+		&AuditLog{},
 	}
 }
 
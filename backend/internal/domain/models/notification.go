@@ -11,23 +11,27 @@ import (
 type NotificationType string
 
 const (
-	NotificationTypeAttendanceIn  NotificationType = "attendance_in"
-	NotificationTypeAttendanceOut NotificationType = "attendance_out"
-	NotificationTypeViolation     NotificationType = "violation"
-	NotificationTypeAchievement   NotificationType = "achievement"
-	NotificationTypePermit        NotificationType = "permit"
-	NotificationTypeCounseling    NotificationType = "counseling"
-	NotificationTypeGrade         NotificationType = "grade"
-	NotificationTypeHomeroomNote  NotificationType = "homeroom_note"
+	NotificationTypeAttendanceIn     NotificationType = "attendance_in"
+	NotificationTypeAttendanceOut    NotificationType = "attendance_out"
+	NotificationTypeAttendanceAbsent NotificationType = "attendance_absent" // Absent, sick or excused
+	NotificationTypeViolation        NotificationType = "violation"
+	NotificationTypeAchievement      NotificationType = "achievement"
+	NotificationTypePermit           NotificationType = "permit"
+	NotificationTypeCounseling       NotificationType = "counseling"
+	NotificationTypeGrade            NotificationType = "grade"
+	NotificationTypeHomeroomNote     NotificationType = "homeroom_note"
+	NotificationTypeDevice           NotificationType = "device"
+	NotificationTypeLeaveRequest     NotificationType = "leave_request"
 )
 
 // IsValid checks if the notification type is valid
 func (t NotificationType) IsValid() bool {
 	switch t {
-	case NotificationTypeAttendanceIn, NotificationTypeAttendanceOut,
+	case NotificationTypeAttendanceIn, NotificationTypeAttendanceOut, NotificationTypeAttendanceAbsent,
 		NotificationTypeViolation, NotificationTypeAchievement,
 		NotificationTypePermit, NotificationTypeCounseling,
-		NotificationTypeGrade, NotificationTypeHomeroomNote:
+		NotificationTypeGrade, NotificationTypeHomeroomNote,
+		NotificationTypeDevice, NotificationTypeLeaveRequest:
 		return true
 	}
 	return false
@@ -31,8 +31,12 @@ type OutboxEvent struct {
 	AggregateID uint              `gorm:"index;not null" json:"aggregate_id"`
 	EventType   string            `gorm:"type:varchar(100);not null" json:"event_type"`
 	Payload     string            `gorm:"type:jsonb;not null" json:"payload"`
-	Status      OutboxEventStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	Status      OutboxEventStatus `gorm:"type:varchar(20);default:'pending';index" json:"status"`
 	RetryCount  int               `gorm:"default:0" json:"retry_count"`
+	LastError   string            `gorm:"type:text" json:"last_error,omitempty"`
+	NextRetryAt *time.Time        `gorm:"index" json:"next_retry_at,omitempty"`
+	LockedUntil *time.Time        `gorm:"index" json:"locked_until,omitempty"`              // Lease of the relay publishing the event
+	DeliveredTo string            `gorm:"type:varchar(255);default:''" json:"delivered_to"` // Comma-separated publishers that delivered the event
 	CreatedAt   time.Time         `json:"created_at"`
 	PublishedAt *time.Time        `json:"published_at"`
 }
@@ -84,6 +88,35 @@ func (o *OutboxEvent) ResetForRetry() {
 	o.Status = OutboxEventStatusPending
 }
 
+// ScheduleRetry resets the event to pending and delays the next attempt
+func (o *OutboxEvent) ScheduleRetry(delay time.Duration) {
+	o.ResetForRetry()
+	next := time.Now().Add(delay)
+	o.NextRetryAt = &next
+}
+
+// IsDeliveredTo checks if a publisher already delivered the event
+func (o *OutboxEvent) IsDeliveredTo(publisher string) bool {
+	for _, name := range strings.Split(o.DeliveredTo, ",") {
+		if name == publisher {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkDeliveredTo records that a publisher delivered the event
+func (o *OutboxEvent) MarkDeliveredTo(publisher string) {
+	if o.IsDeliveredTo(publisher) {
+		return
+	}
+	if o.DeliveredTo == "" {
+		o.DeliveredTo = publisher
+		return
+	}
+	o.DeliveredTo += "," + publisher
+}
+
 // SetPayload sets the payload from a map
 func (o *OutboxEvent) SetPayload(data map[string]interface{}) error {
 	jsonData, err := json.Marshal(data)
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// PasswordResetCode is a one-time code a user requested to reset a forgotten password
+// Only the hash of the code is stored. A code works once, until it expires or is guessed
+// wrong too often; requesting a new code retires the previous ones.
+type PasswordResetCode struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash    string     `gorm:"type:varchar(64);not null" json:"-"`
+	Channel     string     `gorm:"type:varchar(20);not null" json:"channel"` // email or whatsapp
+	Destination string     `gorm:"type:varchar(255);not null" json:"-"`
+	Attempts    int        `gorm:"default:0" json:"attempts"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for PasswordResetCode
+func (PasswordResetCode) TableName() string {
+	return "password_reset_codes"
+}
+
+// IsUsable checks if the code can still be verified at the given time
+func (c *PasswordResetCode) IsUsable(now time.Time, maxAttempts int) bool {
+	return c.UsedAt == nil && now.Before(c.ExpiresAt) && c.Attempts < maxAttempts
+}
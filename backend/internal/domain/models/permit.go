@@ -10,6 +10,7 @@ import (
 type Permit struct {
 	ID                 uint       `gorm:"primaryKey" json:"id"`
 	StudentID          uint       `gorm:"index;not null" json:"student_id"`
+	ClassID            *uint      `gorm:"index" json:"class_id"` // Class at the time (see Attendance.ClassID)
 	Reason             string     `gorm:"type:text;not null" json:"reason"`
 	ExitTime           time.Time  `gorm:"not null" json:"exit_time"`
 	ReturnTime         *time.Time `json:"return_time"`
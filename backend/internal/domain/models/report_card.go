@@ -0,0 +1,151 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ReportCardPaperSize represents the paper a report card is printed on
+type ReportCardPaperSize string
+
+const (
+	ReportCardPaperA4 ReportCardPaperSize = "A4"
+	ReportCardPaperF4 ReportCardPaperSize = "F4" // Folio, 215 x 330 mm
+)
+
+// IsValid checks if the paper size is valid
+func (p ReportCardPaperSize) IsValid() bool {
+	switch p {
+	case ReportCardPaperA4, ReportCardPaperF4:
+		return true
+	}
+	return false
+}
+
+// ReportCardTemplate is a school's report card (rapor) layout
+// HeaderLines and FooterNote are Go text/templates rendered with the report card data,
+// e.g. "{{.School.Name}}" or "{{.Student.Name}}".
+type ReportCardTemplate struct {
+	ID               uint                `gorm:"primaryKey" json:"id"`
+	SchoolID         uint                `gorm:"uniqueIndex;not null" json:"school_id"`
+	Title            string              `gorm:"type:varchar(255);not null" json:"title"`
+	HeaderLines      string              `gorm:"type:text" json:"header_lines"` // One line per row, the first is printed bold
+	FooterNote       string              `gorm:"type:text" json:"footer_note"`
+	PaperSize        ReportCardPaperSize `gorm:"type:varchar(5);not null;default:'A4'" json:"paper_size"`
+	SignaturePlace   string              `gorm:"type:varchar(100)" json:"signature_place"` // City printed before the signature date
+	PrincipalName    string              `gorm:"type:varchar(255)" json:"principal_name"`
+	PrincipalNIP     string              `gorm:"type:varchar(30)" json:"principal_nip"`
+	ShowAchievements bool                `gorm:"not null" json:"show_achievements"`
+	ShowViolations   bool                `gorm:"not null" json:"show_violations"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+
+	// Relations
+	School School `gorm:"foreignKey:SchoolID" json:"school,omitempty"`
+}
+
+// TableName specifies the table name for ReportCardTemplate
+func (ReportCardTemplate) TableName() string {
+	return "report_card_templates"
+}
+
+// Validate validates the report card template data
+func (t *ReportCardTemplate) Validate() error {
+	if t.SchoolID == 0 {
+		return errors.New("school_id is required")
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		return errors.New("title is required")
+	}
+	if !t.PaperSize.IsValid() {
+		return errors.New("paper_size must be A4 or F4")
+	}
+	if _, err := template.New("header").Parse(t.HeaderLines); err != nil {
+		return errors.New("header_lines is not a valid template: " + err.Error())
+	}
+	if _, err := template.New("footer").Parse(t.FooterNote); err != nil {
+		return errors.New("footer_note is not a valid template: " + err.Error())
+	}
+	return nil
+}
+
+// DefaultReportCardTemplate returns the layout used until a school customizes its own
+func DefaultReportCardTemplate(schoolID uint) ReportCardTemplate {
+	return ReportCardTemplate{
+		SchoolID:         schoolID,
+		Title:            "LAPORAN HASIL BELAJAR PESERTA DIDIK",
+		HeaderLines:      "{{.School.Name}}\n{{.School.Address}}\n{{if .School.Phone}}Telp. {{.School.Phone}}{{end}}",
+		PaperSize:        ReportCardPaperA4,
+		ShowAchievements: true,
+		ShowViolations:   true,
+	}
+}
+
+// ReportCardNarrative is the homeroom teacher's narrative for a student's report card of one semester
+type ReportCardNarrative struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	StudentID    uint      `gorm:"uniqueIndex:idx_report_card_narratives_period;not null" json:"student_id"`
+	AcademicYear string    `gorm:"uniqueIndex:idx_report_card_narratives_period;type:varchar(10);not null" json:"academic_year"`
+	Semester     int       `gorm:"uniqueIndex:idx_report_card_narratives_period;not null" json:"semester"`
+	Narrative    string    `gorm:"type:text;not null" json:"narrative"`
+	WrittenBy    uint      `gorm:"not null" json:"written_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Relations
+	Student Student `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	Writer  User    `gorm:"foreignKey:WrittenBy" json:"writer,omitempty"`
+}
+
+// TableName specifies the table name for ReportCardNarrative
+func (ReportCardNarrative) TableName() string {
+	return "report_card_narratives"
+}
+
+// ReportCardJobStatus represents the state of a report card generation job
+type ReportCardJobStatus string
+
+const (
+	ReportCardJobPending    ReportCardJobStatus = "pending"
+	ReportCardJobProcessing ReportCardJobStatus = "processing"
+	ReportCardJobCompleted  ReportCardJobStatus = "completed"
+	ReportCardJobFailed     ReportCardJobStatus = "failed"
+)
+
+// ReportCardJob is a queued report card generation
+// A job for a single student produces a PDF; a job for a whole class produces a zip of PDFs.
+type ReportCardJob struct {
+	ID           uint                `gorm:"primaryKey" json:"id"`
+	SchoolID     uint                `gorm:"index;not null" json:"school_id"`
+	ClassID      uint                `gorm:"index;not null" json:"class_id"`
+	StudentID    *uint               `gorm:"index" json:"student_id"` // Nil for a class bundle
+	AcademicYear string              `gorm:"type:varchar(10);not null" json:"academic_year"`
+	Semester     int                 `gorm:"not null" json:"semester"`
+	Status       ReportCardJobStatus `gorm:"type:varchar(20);index;not null;default:'pending'" json:"status"`
+	Total        int                 `gorm:"not null;default:0" json:"total"`     // Report cards to render
+	Generated    int                 `gorm:"not null;default:0" json:"generated"` // Report cards rendered so far
+	FileName     string              `gorm:"type:varchar(255)" json:"file_name"`
+	FilePath     string              `gorm:"type:varchar(500)" json:"-"`
+	Error        string              `gorm:"type:text" json:"error,omitempty"`
+	RequestedBy  uint                `gorm:"index;not null" json:"requested_by"`
+	StartedAt    *time.Time          `json:"started_at"`
+	CompletedAt  *time.Time          `json:"completed_at"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+
+	// Relations
+	Class   Class    `gorm:"foreignKey:ClassID" json:"class,omitempty"`
+	Student *Student `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+}
+
+// TableName specifies the table name for ReportCardJob
+func (ReportCardJob) TableName() string {
+	return "report_card_jobs"
+}
+
+// IsClassBundle reports whether the job renders the whole class
+func (j *ReportCardJob) IsClassBundle() bool {
+	return j.StudentID == nil
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// HolidayType represents the kind of non-school day
+type HolidayType string
+
+const (
+	HolidayTypeNational HolidayType = "national" // Libur nasional / cuti bersama
+	HolidayTypeSchool   HolidayType = "school"   // Libur khusus sekolah
+	HolidayTypeBreak    HolidayType = "break"    // Libur semester / kenaikan kelas
+	HolidayTypeExam     HolidayType = "exam"     // Pekan ujian tanpa absensi reguler
+	HolidayTypeOther    HolidayType = "other"
+)
+
+// IsValid checks if the holiday type is valid
+func (t HolidayType) IsValid() bool {
+	switch t {
+	case HolidayTypeNational, HolidayTypeSchool, HolidayTypeBreak, HolidayTypeExam, HolidayTypeOther:
+		return true
+	}
+	return false
+}
+
+// SchoolHoliday represents a non-school day (or range of days) in a school's calendar
+// No attendance is expected on these dates: schedules are inactive and the days are
+// excluded from working-day counts
+type SchoolHoliday struct {
+	ID          uint        `gorm:"primaryKey" json:"id"`
+	SchoolID    uint        `gorm:"index;not null" json:"school_id"`
+	Name        string      `gorm:"type:varchar(255);not null" json:"name"`
+	Type        HolidayType `gorm:"type:varchar(20);not null;default:'school'" json:"type"`
+	StartDate   time.Time   `gorm:"type:date;index;not null" json:"start_date"`
+	EndDate     time.Time   `gorm:"type:date;index;not null" json:"end_date"`
+	Description string      `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+
+	// Relations
+	School School `gorm:"foreignKey:SchoolID;constraint:OnDelete:CASCADE" json:"school,omitempty"`
+}
+
+// TableName specifies the table name for SchoolHoliday
+func (SchoolHoliday) TableName() string {
+	return "school_holidays"
+}
+
+// Validate validates the holiday data
+func (h *SchoolHoliday) Validate() error {
+	if h.SchoolID == 0 {
+		return errors.New("school_id is required")
+	}
+	if strings.TrimSpace(h.Name) == "" {
+		return errors.New("name is required")
+	}
+	if !h.Type.IsValid() {
+		return errors.New("type must be one of: national, school, break, exam, other")
+	}
+	if h.StartDate.IsZero() || h.EndDate.IsZero() {
+		return errors.New("start_date and end_date are required")
+	}
+	if h.EndDate.Before(h.StartDate) {
+		return errors.New("end_date must not be before start_date")
+	}
+	return nil
+}
+
+// Covers checks if the holiday includes the given calendar date
+func (h *SchoolHoliday) Covers(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	return day >= h.StartDate.Format("2006-01-02") && day <= h.EndDate.Format("2006-01-02")
+}
+
+// ScheduleOverride replaces an attendance schedule's timetable for a date range
+// (e.g. a shortened Ramadan timetable) or cancels the schedule on those dates
+type ScheduleOverride struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	SchoolID          uint      `gorm:"index;not null" json:"school_id"`
+	ScheduleID        uint      `gorm:"index;not null" json:"schedule_id"`
+	Name              string    `gorm:"type:varchar(100);not null" json:"name"`
+	StartDate         time.Time `gorm:"type:date;index;not null" json:"start_date"`
+	EndDate           time.Time `gorm:"type:date;index;not null" json:"end_date"`
+	StartTime         *string   `gorm:"type:time without time zone" json:"start_time"`
+	EndTime           *string   `gorm:"type:time without time zone" json:"end_time"`
+	LateThreshold     *int      `json:"late_threshold"`
+	VeryLateThreshold *int      `json:"very_late_threshold"`
+	DismissalTime     *string   `gorm:"type:time without time zone" json:"dismissal_time"`
+	IsCancelled       bool      `gorm:"default:false" json:"is_cancelled"` // Schedule is not used on these dates
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	// Relations
+	Schedule AttendanceSchedule `gorm:"foreignKey:ScheduleID;constraint:OnDelete:CASCADE" json:"schedule,omitempty"`
+}
+
+// TableName specifies the table name for ScheduleOverride
+func (ScheduleOverride) TableName() string {
+	return "schedule_overrides"
+}
+
+// Validate validates the override data
+func (o *ScheduleOverride) Validate() error {
+	if o.SchoolID == 0 {
+		return errors.New("school_id is required")
+	}
+	if o.ScheduleID == 0 {
+		return errors.New("schedule_id is required")
+	}
+	if strings.TrimSpace(o.Name) == "" {
+		return errors.New("name is required")
+	}
+	if o.StartDate.IsZero() || o.EndDate.IsZero() {
+		return errors.New("start_date and end_date are required")
+	}
+	if o.EndDate.Before(o.StartDate) {
+		return errors.New("end_date must not be before start_date")
+	}
+	for _, t := range []*string{o.StartTime, o.EndTime, o.DismissalTime} {
+		if t != nil && *t != "" && !isValidTimeFormat(*t) {
+			return errors.New("times must be in HH:MM or HH:MM:SS format")
+		}
+	}
+	if o.LateThreshold != nil && *o.LateThreshold < 0 {
+		return errors.New("late_threshold must be non-negative")
+	}
+	return nil
+}
+
+// Covers checks if the override includes the given calendar date
+func (o *ScheduleOverride) Covers(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	return day >= o.StartDate.Format("2006-01-02") && day <= o.EndDate.Format("2006-01-02")
+}
+
+// Apply returns a copy of the schedule with the overridden fields replaced
+func (o *ScheduleOverride) Apply(schedule AttendanceSchedule) AttendanceSchedule {
+	if o.StartTime != nil && *o.StartTime != "" {
+		schedule.StartTime = *o.StartTime
+	}
+	if o.EndTime != nil && *o.EndTime != "" {
+		schedule.EndTime = *o.EndTime
+	}
+	if o.LateThreshold != nil {
+		schedule.LateThreshold = *o.LateThreshold
+	}
+	if o.VeryLateThreshold != nil {
+		schedule.VeryLateThreshold = o.VeryLateThreshold
+	}
+	if o.DismissalTime != nil && *o.DismissalTime != "" {
+		schedule.DismissalTime = o.DismissalTime
+	}
+	return schedule
+}
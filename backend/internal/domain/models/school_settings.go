@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -27,6 +28,9 @@ type SchoolSettings struct {
 	AcademicYear string `gorm:"type:varchar(10)" json:"academic_year"` // e.g., "2024/2025"
 	Semester     int    `gorm:"default:1" json:"semester"`             // 1 or 2
 
+	// Security Settings
+	TwoFactorRequiredRoles string `gorm:"type:varchar(100);default:''" json:"two_factor_required_roles"` // Comma-separated roles that must sign in with an authenticator app
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -67,9 +71,38 @@ func (s *SchoolSettings) Validate() error {
 		return errors.New("semester must be 1 or 2")
 	}
 
+	// Validate roles that require two-factor authentication
+	for _, role := range s.TwoFactorRoles() {
+		if !role.SupportsTwoFactor() || role == RoleSuperAdmin {
+			return errors.New("two_factor_required_roles may only contain admin_sekolah and guru_bk")
+		}
+	}
+
 	return nil
 }
 
+// TwoFactorRoles returns the roles that have to sign in with an authenticator app
+func (s *SchoolSettings) TwoFactorRoles() []UserRole {
+	var roles []UserRole
+	for _, role := range strings.Split(s.TwoFactorRequiredRoles, ",") {
+		role = strings.TrimSpace(role)
+		if role != "" {
+			roles = append(roles, UserRole(role))
+		}
+	}
+	return roles
+}
+
+// RequiresTwoFactor checks if users with the role have to sign in with an authenticator app
+func (s *SchoolSettings) RequiresTwoFactor(role UserRole) bool {
+	for _, required := range s.TwoFactorRoles() {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
 // AttendanceTimeWindow represents the valid time window for attendance
 type AttendanceTimeWindow struct {
 	StartTime    time.Time `json:"start_time"`
@@ -97,7 +130,7 @@ func DefaultSchoolSettings(schoolID uint) *SchoolSettings {
 // ShouldSendNotification checks if a notification should be sent based on settings
 func (s *SchoolSettings) ShouldSendNotification(notificationType NotificationType) bool {
 	switch notificationType {
-	case NotificationTypeAttendanceIn, NotificationTypeAttendanceOut:
+	case NotificationTypeAttendanceIn, NotificationTypeAttendanceOut, NotificationTypeAttendanceAbsent:
 		return s.EnableAttendanceNotification
 	case NotificationTypeGrade:
 		return s.EnableGradeNotification
@@ -17,6 +17,7 @@ type Student struct {
 	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
 	RFIDCode  string    `gorm:"column:rf_id_code;type:varchar(50);index" json:"rfid_code"`
 	IsActive  bool      `gorm:"default:false" json:"is_active"` // Default false, true only when ClassID is set
+	GraduatedAt *time.Time `json:"graduated_at"` // Set when the student graduates at the academic year rollover
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -57,6 +58,11 @@ func (s *Student) CanBeActive() bool {
 	return s.ClassID != nil && *s.ClassID > 0
 }
 
+// IsGraduated checks if the student has graduated
+func (s *Student) IsGraduated() bool {
+	return s.GraduatedAt != nil
+}
+
 // StudentParent represents the many-to-many relationship between students and parents
 type StudentParent struct {
 	StudentID uint `gorm:"primaryKey"`
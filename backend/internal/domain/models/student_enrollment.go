@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// EnrollmentReason is why a student's enrollment in a class starts or ends
+type EnrollmentReason string
+
+const (
+	EnrollmentReasonNew         EnrollmentReason = "new"          // First class at the school
+	EnrollmentReasonTransferIn  EnrollmentReason = "transfer_in"  // Moved in from another school or class
+	EnrollmentReasonTransferOut EnrollmentReason = "transfer_out" // Moved out to another school or class
+	EnrollmentReasonPromoted    EnrollmentReason = "promoted"     // Naik kelas at the academic year rollover
+	EnrollmentReasonRepeated    EnrollmentReason = "repeated"     // Tinggal kelas at the academic year rollover
+	EnrollmentReasonGraduated   EnrollmentReason = "graduated"
+	EnrollmentReasonDroppedOut  EnrollmentReason = "dropped_out"
+)
+
+// IsValid checks if the enrollment reason is valid
+func (r EnrollmentReason) IsValid() bool {
+	return r.IsStartReason() || r.IsEndReason()
+}
+
+// IsStartReason checks if the reason can start an enrollment
+func (r EnrollmentReason) IsStartReason() bool {
+	switch r {
+	case EnrollmentReasonNew, EnrollmentReasonTransferIn, EnrollmentReasonPromoted, EnrollmentReasonRepeated:
+		return true
+	}
+	return false
+}
+
+// IsEndReason checks if the reason can end an enrollment
+func (r EnrollmentReason) IsEndReason() bool {
+	switch r {
+	case EnrollmentReasonTransferOut, EnrollmentReasonPromoted, EnrollmentReasonRepeated,
+		EnrollmentReasonGraduated, EnrollmentReasonDroppedOut:
+		return true
+	}
+	return false
+}
+
+// StudentEnrollment is a period a student spent in a class
+// StartDate is inclusive and EndDate exclusive; the open enrollment (EndDate nil)
+// matches Student.ClassID.
+type StudentEnrollment struct {
+	ID           uint             `gorm:"primaryKey" json:"id"`
+	SchoolID     uint             `gorm:"index;not null" json:"school_id"`
+	StudentID    uint             `gorm:"index:idx_enrollments_student_start;not null" json:"student_id"`
+	ClassID      uint             `gorm:"index;not null" json:"class_id"`
+	AcademicYear string           `gorm:"type:varchar(10);not null" json:"academic_year"` // Year of the class
+	StartDate    time.Time        `gorm:"type:date;index:idx_enrollments_student_start;not null" json:"start_date"`
+	EndDate      *time.Time       `gorm:"type:date" json:"end_date"`
+	Reason       EnrollmentReason `gorm:"type:varchar(20);not null" json:"reason"`
+	EndReason    EnrollmentReason `gorm:"type:varchar(20)" json:"end_reason,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+
+	// Relations
+	Student Student `gorm:"foreignKey:StudentID" json:"-"`
+	Class   Class   `gorm:"foreignKey:ClassID" json:"class,omitempty"`
+}
+
+// TableName specifies the table name for StudentEnrollment
+func (StudentEnrollment) TableName() string {
+	return "student_enrollments"
+}
+
+// IsOpen checks if the student is still in the class
+func (e *StudentEnrollment) IsOpen() bool {
+	return e.EndDate == nil
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// DefaultKKM is the passing threshold used when a subject does not set one
+const DefaultKKM = 75
+
+// Subject represents a school-defined subject (mata pelajaran)
+type Subject struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	SchoolID    uint      `gorm:"uniqueIndex:idx_subjects_school_code;not null" json:"school_id"`
+	Code        string    `gorm:"uniqueIndex:idx_subjects_school_code;type:varchar(20);not null" json:"code"`
+	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
+	KKM         float64   `gorm:"not null;default:75" json:"kkm"` // Kriteria Ketuntasan Minimal, 0-100
+	Description string    `gorm:"type:text" json:"description"`
+	IsActive    bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relations
+	School School `gorm:"foreignKey:SchoolID" json:"school,omitempty"`
+}
+
+// TableName specifies the table name for Subject
+func (Subject) TableName() string {
+	return "subjects"
+}
+
+// Validate validates the subject data
+func (s *Subject) Validate() error {
+	if s.SchoolID == 0 {
+		return errors.New("school_id is required")
+	}
+	if strings.TrimSpace(s.Code) == "" {
+		return errors.New("code is required")
+	}
+	if strings.TrimSpace(s.Name) == "" {
+		return errors.New("name is required")
+	}
+	if s.KKM < 0 || s.KKM > 100 {
+		return errors.New("kkm must be between 0 and 100")
+	}
+	return nil
+}
+
+// AssessmentCategory represents a weighted kind of assessment (tugas, UH, UTS, UAS, praktik)
+// Weights are relative: a subject's report-card average is weighted by the categories it has
+// grades in, so they do not need to add up to 100.
+type AssessmentCategory struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SchoolID  uint      `gorm:"uniqueIndex:idx_assessment_categories_school_code;not null" json:"school_id"`
+	Code      string    `gorm:"uniqueIndex:idx_assessment_categories_school_code;type:varchar(20);not null" json:"code"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	Weight    float64   `gorm:"not null" json:"weight"`
+	IsActive  bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	School School `gorm:"foreignKey:SchoolID" json:"school,omitempty"`
+}
+
+// TableName specifies the table name for AssessmentCategory
+func (AssessmentCategory) TableName() string {
+	return "assessment_categories"
+}
+
+// Validate validates the assessment category data
+func (ac *AssessmentCategory) Validate() error {
+	if ac.SchoolID == 0 {
+		return errors.New("school_id is required")
+	}
+	if strings.TrimSpace(ac.Code) == "" {
+		return errors.New("code is required")
+	}
+	if strings.TrimSpace(ac.Name) == "" {
+		return errors.New("name is required")
+	}
+	if ac.Weight <= 0 || ac.Weight > 100 {
+		return errors.New("weight must be greater than 0 and at most 100")
+	}
+	return nil
+}
+
+// DefaultAssessmentCategories returns the default assessment categories for a new school
+func DefaultAssessmentCategories(schoolID uint) []AssessmentCategory {
+	return []AssessmentCategory{
+		{SchoolID: schoolID, Code: "tugas", Name: "Tugas", Weight: 20, IsActive: true},
+		{SchoolID: schoolID, Code: "uh", Name: "Ulangan Harian", Weight: 20, IsActive: true},
+		{SchoolID: schoolID, Code: "uts", Name: "Ujian Tengah Semester", Weight: 20, IsActive: true},
+		{SchoolID: schoolID, Code: "uas", Name: "Ujian Akhir Semester", Weight: 30, IsActive: true},
+		{SchoolID: schoolID, Code: "praktik", Name: "Praktik", Weight: 10, IsActive: true},
+	}
+}
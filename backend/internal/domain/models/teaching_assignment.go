@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// TeachingAssignment assigns a teacher to teach a subject in a class for one semester
+// Assigned teachers (role guru, or a wali kelas teaching outside their homeroom class)
+// may record and manage grades of that subject for the students of that class.
+type TeachingAssignment struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SchoolID     uint      `gorm:"index;not null" json:"school_id"`
+	TeacherID    uint      `gorm:"uniqueIndex:idx_teaching_assignments_unique;index;not null" json:"teacher_id"`
+	SubjectID    uint      `gorm:"uniqueIndex:idx_teaching_assignments_unique;not null" json:"subject_id"`
+	ClassID      uint      `gorm:"uniqueIndex:idx_teaching_assignments_unique;index;not null" json:"class_id"`
+	AcademicYear string    `gorm:"uniqueIndex:idx_teaching_assignments_unique;type:varchar(10);not null" json:"academic_year"`
+	Semester     int       `gorm:"uniqueIndex:idx_teaching_assignments_unique;not null" json:"semester"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Relations
+	School  School  `gorm:"foreignKey:SchoolID" json:"-"`
+	Teacher User    `gorm:"foreignKey:TeacherID" json:"teacher,omitempty"`
+	Subject Subject `gorm:"foreignKey:SubjectID" json:"subject,omitempty"`
+	Class   Class   `gorm:"foreignKey:ClassID" json:"class,omitempty"`
+}
+
+// TableName specifies the table name for TeachingAssignment
+func (TeachingAssignment) TableName() string {
+	return "teaching_assignments"
+}
+
+// Validate validates the teaching assignment data
+func (a *TeachingAssignment) Validate() error {
+	if a.SchoolID == 0 {
+		return errors.New("school_id is required")
+	}
+	if a.TeacherID == 0 {
+		return errors.New("teacher_id is required")
+	}
+	if a.SubjectID == 0 {
+		return errors.New("subject_id is required")
+	}
+	if a.ClassID == 0 {
+		return errors.New("class_id is required")
+	}
+	if strings.TrimSpace(a.AcademicYear) == "" {
+		return errors.New("academic_year is required")
+	}
+	if a.Semester != 1 && a.Semester != 2 {
+		return errors.New("semester must be 1 or 2")
+	}
+	return nil
+}
+
+// CanTeach checks if a user with the given role can be assigned to teach subjects
+func CanTeach(role UserRole) bool {
+	return role == RoleGuru || role == RoleWaliKelas
+}
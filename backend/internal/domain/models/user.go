@@ -13,6 +13,7 @@ const (
 	RoleSuperAdmin   UserRole = "super_admin"
 	RoleAdminSekolah UserRole = "admin_sekolah"
 	RoleGuruBK       UserRole = "guru_bk"
+	RoleGuru         UserRole = "guru" // Subject teacher, see TeachingAssignment
 	RoleWaliKelas    UserRole = "wali_kelas"
 	RoleParent       UserRole = "parent"
 	RoleStudent      UserRole = "student"
@@ -21,7 +22,17 @@ const (
 // IsValid checks if the user role is valid
 func (r UserRole) IsValid() bool {
 	switch r {
-	case RoleSuperAdmin, RoleAdminSekolah, RoleGuruBK, RoleWaliKelas, RoleParent, RoleStudent:
+	case RoleSuperAdmin, RoleAdminSekolah, RoleGuruBK, RoleGuru, RoleWaliKelas, RoleParent, RoleStudent:
+		return true
+	}
+	return false
+}
+
+// SupportsTwoFactor checks if users with the role can protect their login with an authenticator app
+// These are the staff roles with access to sensitive data such as internal counseling notes.
+func (r UserRole) SupportsTwoFactor() bool {
+	switch r {
+	case RoleSuperAdmin, RoleAdminSekolah, RoleGuruBK:
 		return true
 	}
 	return false
@@ -82,6 +93,11 @@ func (u *User) IsGuruBK() bool {
 	return u.Role == RoleGuruBK
 }
 
+// IsGuru checks if the user is a subject teacher
+func (u *User) IsGuru() bool {
+	return u.Role == RoleGuru
+}
+
 // IsWaliKelas checks if the user is a homeroom teacher
 func (u *User) IsWaliKelas() bool {
 	return u.Role == RoleWaliKelas
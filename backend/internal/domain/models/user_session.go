@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// SessionRevokeReason explains why a session ended before it expired
+type SessionRevokeReason string
+
+const (
+	SessionRevokedLogout          SessionRevokeReason = "logout"
+	SessionRevokedByUser          SessionRevokeReason = "revoked_by_user"  // From the user's list of active sessions
+	SessionRevokedByAdmin         SessionRevokeReason = "revoked_by_admin" // By the school admin, e.g. for a lost phone
+	SessionRevokedTokenReuse      SessionRevokeReason = "token_reuse"      // A rotated refresh token was presented again
+	SessionRevokedPasswordChanged SessionRevokeReason = "password_changed"
+	SessionRevokedPasswordReset   SessionRevokeReason = "password_reset"
+	SessionRevokedDeactivated     SessionRevokeReason = "deactivated"
+)
+
+// UserSession is a signed-in device of a user and backs its refresh token
+// The refresh token is rotated on every use; only the hash of the ID of the current one is
+// kept, so presenting an older token reveals that it was copied.
+type UserSession struct {
+	ID            uint                `gorm:"primaryKey" json:"id"`
+	UserID        uint                `gorm:"index;not null" json:"user_id"`
+	TokenHash     string              `gorm:"type:varchar(64);not null" json:"-"`
+	DeviceName    string              `gorm:"type:varchar(100)" json:"device_name"`
+	UserAgent     string              `gorm:"type:varchar(500)" json:"user_agent"`
+	IPAddress     string              `gorm:"type:varchar(45)" json:"ip_address"`
+	LastUsedAt    time.Time           `json:"last_used_at"`
+	ExpiresAt     time.Time           `gorm:"index;not null" json:"expires_at"`
+	RevokedAt     *time.Time          `json:"revoked_at"`
+	RevokedReason SessionRevokeReason `gorm:"type:varchar(30)" json:"revoked_reason,omitempty"`
+	ViaResetCode  bool                `gorm:"default:false" json:"-"` // Started with a password reset code; limited until a new password is set
+	CreatedAt     time.Time           `json:"created_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for UserSession
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// IsActive checks if the session can still refresh tokens at the given time
+func (s *UserSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// UserTwoFactor holds the authenticator app (TOTP) secret of a user
+// The record is created when enrollment starts and only protects logins once ConfirmedAt
+// is set, i.e. after the user entered a first code from the app.
+type UserTwoFactor struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	Secret       string     `gorm:"type:varchar(64);not null" json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `gorm:"default:0" json:"-"` // Time step of the last accepted code, so a code works only once
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for UserTwoFactor
+func (UserTwoFactor) TableName() string {
+	return "user_two_factors"
+}
+
+// IsEnabled checks if the enrollment was confirmed
+func (t *UserTwoFactor) IsEnabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// TwoFactorRecoveryCode is a single-use code that replaces an authenticator code,
+// e.g. when the phone with the app is lost. Only the hash of the code is stored.
+type TwoFactorRecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for TwoFactorRecoveryCode
+func (TwoFactorRecoveryCode) TableName() string {
+	return "two_factor_recovery_codes"
+}
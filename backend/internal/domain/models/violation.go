@@ -28,6 +28,7 @@ func (v ViolationLevel) IsValid() bool {
 type Violation struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
 	StudentID   uint           `gorm:"index;not null" json:"student_id"`
+	ClassID     *uint          `gorm:"index" json:"class_id"` // Class at the time (see Attendance.ClassID)
 	CategoryID  *uint          `gorm:"index" json:"category_id"`
 	Category    string         `gorm:"type:varchar(100);not null" json:"category"`
 	Level       ViolationLevel `gorm:"type:varchar(20);not null" json:"level"`
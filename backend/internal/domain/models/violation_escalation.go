@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// EscalationAction is the sanction a violation point threshold triggers
+type EscalationAction string
+
+const (
+	EscalationActionParentCall       EscalationAction = "parent_call"       // Pemanggilan orang tua
+	EscalationActionSummonsLetter    EscalationAction = "summons_letter"    // Surat panggilan orang tua
+	EscalationActionSuspensionReview EscalationAction = "suspension_review" // Peninjauan skorsing
+)
+
+// IsValid checks if the escalation action is valid
+func (a EscalationAction) IsValid() bool {
+	switch a {
+	case EscalationActionParentCall, EscalationActionSummonsLetter, EscalationActionSuspensionReview:
+		return true
+	}
+	return false
+}
+
+// LetterTitle returns the title printed on the letter generated for the action
+func (a EscalationAction) LetterTitle() string {
+	switch a {
+	case EscalationActionSummonsLetter:
+		return "Surat Panggilan Orang Tua/Wali"
+	case EscalationActionSuspensionReview:
+		return "Surat Pemberitahuan Peninjauan Skorsing"
+	default:
+		return "Surat Pemberitahuan Orang Tua/Wali"
+	}
+}
+
+// ViolationThreshold is a school's accumulated violation point level that triggers a sanction
+// Points are counted as positive numbers, e.g. 25 for a student whose violations sum to -25.
+type ViolationThreshold struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	SchoolID    uint             `gorm:"uniqueIndex:idx_violation_threshold_points;not null" json:"school_id"`
+	Points      int              `gorm:"uniqueIndex:idx_violation_threshold_points;not null" json:"points"`
+	Name        string           `gorm:"type:varchar(100);not null" json:"name"`
+	Action      EscalationAction `gorm:"type:varchar(30);not null" json:"action"`
+	Description string           `gorm:"type:text" json:"description"` // Printed in the letter, e.g. when and where parents must come
+	IsActive    bool             `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// TableName specifies the table name for ViolationThreshold
+func (ViolationThreshold) TableName() string {
+	return "violation_thresholds"
+}
+
+// Validate validates the threshold data
+func (t *ViolationThreshold) Validate() error {
+	if t.SchoolID == 0 {
+		return errors.New("school_id is required")
+	}
+	if t.Points <= 0 {
+		return errors.New("points must be greater than 0")
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("name is required")
+	}
+	if !t.Action.IsValid() {
+		return errors.New("action must be one of: parent_call, summons_letter, suspension_review")
+	}
+	return nil
+}
+
+// EscalationStatus represents the state of the counselor's follow-up task
+type EscalationStatus string
+
+const (
+	EscalationStatusOpen      EscalationStatus = "open"
+	EscalationStatusCompleted EscalationStatus = "completed"
+)
+
+// ViolationEscalation records a student crossing a violation point threshold
+// It carries the letter sent to the parents and the follow-up task of the class counselor.
+// The threshold is copied so the history survives later edits of the school's thresholds.
+type ViolationEscalation struct {
+	ID              uint             `gorm:"primaryKey" json:"id"`
+	SchoolID        uint             `gorm:"index;not null" json:"school_id"`
+	StudentID       uint             `gorm:"uniqueIndex:idx_escalation_student_threshold;not null" json:"student_id"`
+	ThresholdID     uint             `gorm:"uniqueIndex:idx_escalation_student_threshold;not null" json:"threshold_id"`
+	ViolationID     uint             `gorm:"index;not null" json:"violation_id"` // Violation that crossed the threshold
+	ClassID         *uint            `gorm:"index" json:"class_id"`
+	ThresholdPoints int              `gorm:"not null" json:"threshold_points"`
+	ThresholdName   string           `gorm:"type:varchar(100);not null" json:"threshold_name"`
+	Action          EscalationAction `gorm:"type:varchar(30);not null" json:"action"`
+	Description     string           `gorm:"type:text" json:"description"`
+	TotalPoints     int              `gorm:"not null" json:"total_points"` // Accumulated points when crossed
+	LetterNumber    string           `gorm:"type:varchar(50)" json:"letter_number"`
+	CounselorID     *uint            `gorm:"index" json:"counselor_id"` // Class counselor the follow-up is assigned to
+	Status          EscalationStatus `gorm:"type:varchar(20);not null;default:'open';index" json:"status"`
+	FollowUpNote    string           `gorm:"type:text" json:"follow_up_note"`
+	CompletedBy     *uint            `json:"completed_by"`
+	CompletedAt     *time.Time       `json:"completed_at"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+
+	// Relations
+	Student   Student `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	Class     *Class  `gorm:"foreignKey:ClassID" json:"class,omitempty"`
+	Counselor *User   `gorm:"foreignKey:CounselorID" json:"counselor,omitempty"`
+	Completer *User   `gorm:"foreignKey:CompletedBy" json:"completer,omitempty"`
+}
+
+// TableName specifies the table name for ViolationEscalation
+func (ViolationEscalation) TableName() string {
+	return "violation_escalations"
+}
+
+// IsOpen checks if the follow-up task is still open
+func (e *ViolationEscalation) IsOpen() bool {
+	return e.Status == EscalationStatusOpen
+}
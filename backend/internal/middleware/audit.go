@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/shared/audit"
+)
+
+// AuditMiddleware stores the client IP, method and path of a request for the audit trail.
+// The user a change is attributed to comes from the locals set by AuthMiddleware.
+func AuditMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(audit.RequestLocal, audit.Request{
+			IPAddress: c.IP(),
+			Method:    c.Method(),
+			Path:      c.Path(),
+		})
+		return c.Next()
+	}
+}
@@ -53,6 +53,17 @@ func AuthMiddleware(jwtManager *auth.JWTManager) fiber.Handler {
 		fmt.Printf("[DEBUG AuthMiddleware] Token valid - UserID: %d, Role: %s, SchoolID: %v\n", 
 			claims.UserID, claims.Role, claims.SchoolID)
 
+		// Signed in with a password reset code: only the auth routes until a new password is set
+		if claims.ResetRequired && !strings.HasPrefix(c.Path(), "/api/v1/auth/") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "AUTH_PASSWORD_CHANGE_REQUIRED",
+					"message": "Buat password baru terlebih dahulu",
+				},
+			})
+		}
+
 		// Store claims in context for use by handlers
 		// Using both camelCase and snake_case for backward compatibility
 		c.Locals("userID", claims.UserID)
@@ -91,7 +102,7 @@ func OptionalAuthMiddleware(jwtManager *auth.JWTManager) fiber.Handler {
 
 		tokenString := parts[1]
 		claims, err := jwtManager.ValidateAccessToken(tokenString)
-		if err != nil {
+		if err != nil || claims.ResetRequired {
 			return c.Next()
 		}
 
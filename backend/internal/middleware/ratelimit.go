@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/shared/ratelimit"
+)
+
+// KeyFunc returns the key the failures of a request are counted under
+type KeyFunc func(c *fiber.Ctx) string
+
+// ClientIP counts failures per client IP address
+func ClientIP(c *fiber.Ctx) string {
+	return c.IP()
+}
+
+// RateLimit locks out keys, such as an IP address, whose requests are rejected too often
+// Responses with status 401 count as failures, so guessing credentials gets the key locked
+// while requests under other keys are never slowed down.
+func RateLimit(limiter *ratelimit.Limiter, keyFunc KeyFunc) fiber.Handler {
+	return RateLimitVerifiable(limiter, keyFunc, nil)
+}
+
+// RateLimitVerifiable is RateLimit for clients that can prove who they are, such as devices signing
+// their requests. A locked out key still lets through the requests for which verifiable returns
+// true, to be verified by the handlers after it. Their failures count like any other, but a
+// client whose credentials verify is never locked out by failures of others sharing its address.
+func RateLimitVerifiable(limiter *ratelimit.Limiter, keyFunc KeyFunc, verifiable func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := keyFunc(c)
+
+		if err := limiter.Check(c.Context(), key); err != nil {
+			var blocked *ratelimit.BlockedError
+			if !errors.As(err, &blocked) {
+				// Keep public endpoints available when Redis cannot be reached
+				log.Printf("Rate limit: failed to check %s: %v", key, err)
+			} else if verifiable == nil || !verifiable(c) {
+				return TooManyRequests(c, blocked)
+			}
+		}
+
+		err := c.Next()
+
+		if c.Response().StatusCode() == fiber.StatusUnauthorized {
+			if _, failErr := limiter.Fail(c.Context(), key); failErr != nil {
+				log.Printf("Rate limit: failed to record failure of %s from %s: %v", key, c.IP(), failErr)
+			}
+		}
+
+		return err
+	}
+}
+
+// TooManyRequests responds to a blocked request
+func TooManyRequests(c *fiber.Ctx, blocked *ratelimit.BlockedError) error {
+	retryAfter := blocked.RetryAfterSeconds()
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":        "RATE_LIMITED",
+			"message":     "Terlalu banyak permintaan yang ditolak, coba lagi nanti",
+			"retry_after": retryAfter,
+		},
+	})
+}
@@ -192,14 +192,16 @@ func SchoolStaffOnly() fiber.Handler {
 	return RoleMiddleware(
 		models.RoleAdminSekolah,
 		models.RoleGuruBK,
+		models.RoleGuru,
 		models.RoleWaliKelas,
 	)
 }
 
-// TeachersOnly restricts access to teachers (BK, homeroom)
+// TeachersOnly restricts access to teachers (BK, subject, homeroom)
 func TeachersOnly() fiber.Handler {
 	return RoleMiddleware(
 		models.RoleGuruBK,
+		models.RoleGuru,
 		models.RoleWaliKelas,
 	)
 }
@@ -224,11 +226,13 @@ func BKWriteAccess() fiber.Handler {
 
 // GradeWriteAccess restricts write access to grades
 // Requirements: 10.5 - Wali_Kelas can only input grades for students in their assigned class
+// Guru can only input grades of the subjects and classes they are assigned to teach.
 func GradeWriteAccess() fiber.Handler {
 	return RoleMiddleware(
 		models.RoleSuperAdmin,
 		models.RoleAdminSekolah,
 		models.RoleWaliKelas,
+		models.RoleGuru,
 	)
 }
 
@@ -351,6 +355,9 @@ func GradeAccessMiddleware() fiber.Handler {
 		case models.RoleWaliKelas:
 			// Can only modify grades for their class
 			c.Locals("gradeAccessLevel", "class_only")
+		case models.RoleGuru:
+			// Can only modify grades of their assigned subjects and classes
+			c.Locals("gradeAccessLevel", "assignment_only")
 		case models.RoleParent, models.RoleStudent:
 			c.Locals("gradeAccessLevel", "readonly")
 		default:
@@ -379,6 +386,8 @@ func GradeAccessMiddlewareWithPolicy(accessPolicy policy.AccessPolicy) fiber.Han
 				c.Locals("gradeAccessLevel", string(policy.AccessLevelFull))
 			case models.RoleWaliKelas:
 				c.Locals("gradeAccessLevel", "class_only")
+			case models.RoleGuru:
+				c.Locals("gradeAccessLevel", "assignment_only")
 			case models.RoleParent, models.RoleStudent:
 				c.Locals("gradeAccessLevel", string(policy.AccessLevelReadOnly))
 			default:
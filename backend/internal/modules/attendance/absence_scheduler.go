@@ -0,0 +1,142 @@
+package attendance
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// AbsenceScheduler marks students as absent once an attendance schedule window closes.
+// Each school is evaluated in its own timezone. The underlying insert is idempotent, so a
+// schedule that is processed twice (restart, several instances) never creates duplicates.
+type AbsenceScheduler struct {
+	repo      Repository
+	interval  time.Duration
+	processed map[string]time.Time // schedule runs already completed, keyed by school/schedule/date
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	running   bool
+	mu        sync.Mutex
+}
+
+// NewAbsenceScheduler creates a new absence scheduler that checks schedules every minute
+func NewAbsenceScheduler(repo Repository) *AbsenceScheduler {
+	return &AbsenceScheduler{
+		repo:      repo,
+		interval:  time.Minute,
+		processed: make(map[string]time.Time),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start starts the absence scheduler
+func (a *AbsenceScheduler) Start() {
+	a.mu.Lock()
+	if a.running {
+		a.mu.Unlock()
+		return
+	}
+	a.running = true
+	a.mu.Unlock()
+
+	a.wg.Add(1)
+	go a.runLoop()
+
+	log.Println("Absence scheduler started")
+}
+
+// Stop stops the absence scheduler gracefully
+func (a *AbsenceScheduler) Stop() {
+	a.mu.Lock()
+	if !a.running {
+		a.mu.Unlock()
+		return
+	}
+	a.running = false
+	a.mu.Unlock()
+
+	close(a.stopCh)
+	a.wg.Wait()
+
+	log.Println("Absence scheduler stopped")
+}
+
+// runLoop runs a check immediately and then on every tick
+func (a *AbsenceScheduler) runLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	a.RunOnce(context.Background(), time.Now())
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case now := <-ticker.C:
+			a.RunOnce(context.Background(), now)
+		}
+	}
+}
+
+// RunOnce marks absences for every schedule whose window has closed today, in each school's timezone
+func (a *AbsenceScheduler) RunOnce(ctx context.Context, now time.Time) {
+	schools, err := a.repo.FindActiveSchools(ctx)
+	if err != nil {
+		log.Printf("Absence scheduler: failed to load schools: %v", err)
+		return
+	}
+
+	for _, school := range schools {
+		localNow := now.In(school.GetLocation())
+		date := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, localNow.Location())
+
+		// Holidays yield no schedules; date overrides are already applied
+		schedules, err := a.repo.FindSchedulesForDate(ctx, school.ID, date)
+		if err != nil {
+			log.Printf("Absence scheduler: failed to load schedules for school %d: %v", school.ID, err)
+			continue
+		}
+
+		for i := range schedules {
+			schedule := &schedules[i]
+			if schedule.IsCheckOut() {
+				continue // Check-out windows never produce absences
+			}
+			if localNow.Before(schedule.EndTimeOn(date)) {
+				continue // Window still open
+			}
+
+			key := fmt.Sprintf("%d:%d:%s", school.ID, schedule.ID, date.Format("2006-01-02"))
+			if _, done := a.processed[key]; done {
+				continue
+			}
+
+			marked, err := a.repo.MarkAbsentForSchedule(ctx, school.ID, schedule, date)
+			if err != nil {
+				log.Printf("Absence scheduler: failed to mark absences for school %d schedule '%s': %v", school.ID, schedule.Name, err)
+				continue
+			}
+			a.processed[key] = now
+
+			if marked > 0 {
+				log.Printf("Absence scheduler: marked %d students absent for school %d schedule '%s' on %s",
+					marked, school.ID, schedule.Name, date.Format("2006-01-02"))
+			}
+		}
+	}
+
+	a.pruneProcessed(now)
+}
+
+// pruneProcessed forgets runs older than two days
+func (a *AbsenceScheduler) pruneProcessed(now time.Time) {
+	for key, at := range a.processed {
+		if now.Sub(at) > 48*time.Hour {
+			delete(a.processed, key)
+		}
+	}
+}
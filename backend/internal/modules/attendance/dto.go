@@ -11,11 +11,24 @@ import (
 // RFIDAttendanceRequest represents the request from ESP32 device
 // Requirements: 5.1 - WHEN a student taps RFID card, THE ESP32 SHALL send student identifier and timestamp
 type RFIDAttendanceRequest struct {
-	APIKey    string    `json:"api_key" validate:"required"`
+	APIKey    string    `json:"api_key"` // Not needed for signed requests
 	RFIDCode  string    `json:"rfid_code" validate:"required"`
 	Timestamp time.Time `json:"timestamp" validate:"required"`
 }
 
+// RFIDBatchRequest represents taps buffered by an ESP32 device while it was offline
+type RFIDBatchRequest struct {
+	APIKey string         `json:"api_key"` // Not needed for signed requests
+	Taps   []RFIDBatchTap `json:"taps" validate:"required,min=1,max=200"`
+}
+
+// RFIDBatchTap represents a single buffered tap
+type RFIDBatchTap struct {
+	IdempotencyKey string    `json:"idempotency_key" validate:"required,max=64"` // Generated by the device, unique per tap
+	RFIDCode       string    `json:"rfid_code" validate:"required"`
+	Timestamp      time.Time `json:"timestamp" validate:"required"` // Device time of the tap
+}
+
 // ManualAttendanceRequest represents manual attendance entry
 // Requirements: 5.5 - IF RFID system fails, THEN THE System SHALL allow manual attendance entry
 type ManualAttendanceRequest struct {
@@ -50,11 +63,14 @@ type AttendanceResponse struct {
 	ClassName    string                  `json:"class_name,omitempty"`
 	ScheduleID   *uint                   `json:"schedule_id,omitempty"`
 	ScheduleName string                  `json:"schedule_name,omitempty"` // Requirements: 3.10 - Show which schedule the attendance belongs to
-	Date         string                  `json:"date"` // Format: YYYY-MM-DD
+	Date         string                  `json:"date"`                    // Format: YYYY-MM-DD
 	CheckInTime  *string                 `json:"check_in_time,omitempty"`
 	CheckOutTime *string                 `json:"check_out_time,omitempty"`
 	Status       models.AttendanceStatus `json:"status"`
 	Method       models.AttendanceMethod `json:"method"`
+	IsEarlyLeave bool                    `json:"is_early_leave"`
+	NeedsReview  bool                    `json:"needs_review"`               // Tap on a day the student is on approved leave
+	LeaveID      *uint                   `json:"leave_request_id,omitempty"` // Leave the record was created from or conflicts with
 	CreatedAt    time.Time               `json:"created_at"`
 	UpdatedAt    time.Time               `json:"updated_at"`
 }
@@ -81,9 +97,36 @@ type RFIDAttendanceResponse struct {
 	Type        string                  `json:"type"` // "check_in" or "check_out"
 	Status      models.AttendanceStatus `json:"status,omitempty"`
 	Time        time.Time               `json:"time"`
+	EarlyLeave  bool                    `json:"early_leave,omitempty"` // check_out before dismissal time
 	Message     string                  `json:"message"`
 }
 
+// RFIDBatchResponse represents the result of a batch sync, one result per tap in request order
+type RFIDBatchResponse struct {
+	Total    int                  `json:"total"`
+	Recorded int                  `json:"recorded"`
+	Rejected int                  `json:"rejected"`
+	Failed   int                  `json:"failed"` // Retryable failures; the device must resend these taps
+	Results  []RFIDBatchTapResult `json:"results"`
+}
+
+// RFIDBatchTapResult represents the result of a single synced tap
+// The device may drop a tap from its queue unless Retryable is set
+type RFIDBatchTapResult struct {
+	IdempotencyKey string                  `json:"idempotency_key"`
+	Success        bool                    `json:"success"`
+	Type           string                  `json:"type,omitempty"` // check_in, check_out, or the rejection type
+	Status         models.AttendanceStatus `json:"status,omitempty"`
+	StudentID      uint                    `json:"student_id,omitempty"`
+	StudentName    string                  `json:"student_name,omitempty"`
+	Time           time.Time               `json:"time"`
+	EarlyLeave     bool                    `json:"early_leave,omitempty"`
+	Message        string                  `json:"message"`
+	ErrorCode      string                  `json:"error_code,omitempty"`
+	Duplicate      bool                    `json:"duplicate,omitempty"` // Synced before; the stored result is returned
+	Retryable      bool                    `json:"retryable"`
+}
+
 // AttendanceSummaryResponse represents attendance summary for a class
 // Requirements: 5.4 - WHEN an Admin_Sekolah views attendance dashboard, THE System SHALL display summary statistics
 // Requirements: 5.1, 5.2 - Include sick and excused counts in the response
@@ -133,14 +176,15 @@ type ClassSummaryItem struct {
 
 // AttendanceFilter represents filter options for listing attendance
 type AttendanceFilter struct {
-	StudentID *uint   `query:"student_id"`
-	ClassID   *uint   `query:"class_id"`
-	StartDate *string `query:"start_date"` // Format: YYYY-MM-DD
-	EndDate   *string `query:"end_date"`   // Format: YYYY-MM-DD
-	Status    *string `query:"status"`
-	Method    *string `query:"method"`
-	Page      int     `query:"page"`
-	PageSize  int     `query:"page_size"`
+	StudentID   *uint   `query:"student_id"`
+	ClassID     *uint   `query:"class_id"`
+	StartDate   *string `query:"start_date"` // Format: YYYY-MM-DD
+	EndDate     *string `query:"end_date"`   // Format: YYYY-MM-DD
+	Status      *string `query:"status"`
+	Method      *string `query:"method"`
+	NeedsReview *bool   `query:"needs_review"`
+	Page        int     `query:"page"`
+	PageSize    int     `query:"page_size"`
 }
 
 // DefaultAttendanceFilter returns default filter values
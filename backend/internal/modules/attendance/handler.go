@@ -58,6 +58,7 @@ func (h *Handler) RegisterRoutesWithoutGroup(router fiber.Router) {
 // RegisterPublicRoutes registers public routes for ESP32 devices
 func (h *Handler) RegisterPublicRoutes(router fiber.Router) {
 	router.Post("/attendance/rfid", h.RecordRFIDAttendance)
+	router.Post("/attendance/rfid/batch", h.RecordRFIDBatch)
 }
 
 // RecordRFIDAttendance handles RFID attendance recording from ESP32 devices
@@ -83,7 +84,7 @@ func (h *Handler) RecordRFIDAttendance(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.service.RecordRFIDAttendance(c.Context(), req)
+	response, err := h.service.RecordRFIDAttendance(device.AuthenticatedContext(c), req)
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -94,6 +95,40 @@ func (h *Handler) RecordRFIDAttendance(c *fiber.Ctx) error {
 	})
 }
 
+// RecordRFIDBatch handles syncing taps buffered by an ESP32 device while offline
+// @Summary Sync buffered RFID taps
+// @Description Record many buffered taps at once. Each tap is evaluated against the schedule active at its timestamp; resending a tap with the same idempotency key returns the stored result.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Param request body RFIDBatchRequest true "Buffered taps"
+// @Success 200 {object} RFIDBatchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/public/attendance/rfid/batch [post]
+func (h *Handler) RecordRFIDBatch(c *fiber.Ctx) error {
+	var req RFIDBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.RecordRFIDBatch(device.AuthenticatedContext(c), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
 // RecordManualAttendance handles manual attendance recording
 // @Summary Record manual attendance
 // @Description Record student attendance manually (fallback when RFID fails)
@@ -203,6 +238,7 @@ func (h *Handler) RecordBulkManualAttendance(c *fiber.Ctx) error {
 // @Param end_date query string false "Filter by end date (YYYY-MM-DD)"
 // @Param status query string false "Filter by status (on_time, late, very_late, absent)"
 // @Param method query string false "Filter by method (rfid, manual)"
+// @Param needs_review query bool false "Only taps on days the student is on approved leave"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
 // @Success 200 {object} AttendanceListResponse
@@ -255,6 +291,12 @@ func (h *Handler) GetAttendance(c *fiber.Ctx) error {
 		filter.Method = &method
 	}
 
+	if needsReviewStr := c.Query("needs_review"); needsReviewStr != "" {
+		if needsReview, err := strconv.ParseBool(needsReviewStr); err == nil {
+			filter.NeedsReview = &needsReview
+		}
+	}
+
 	if page, err := strconv.Atoi(c.Query("page", "1")); err == nil && page > 0 {
 		filter.Page = page
 	}
@@ -838,6 +880,30 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 				"message": "Tidak ada jadwal absensi untuk waktu ini",
 			},
 		})
+	case errors.Is(err, ErrNoCheckIn):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_NO_CHECK_IN",
+				"message": "Belum ada absen masuk hari ini",
+			},
+		})
+	case errors.Is(err, ErrSyncBatchEmpty):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Daftar tap tidak boleh kosong",
+			},
+		})
+	case errors.Is(err, ErrSyncBatchTooLarge):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_BATCH_TOO_LARGE",
+				"message": "Maksimal 200 tap per sinkronisasi",
+			},
+		})
 	case errors.Is(err, ErrAlreadyCheckedIn):
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
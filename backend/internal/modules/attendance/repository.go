@@ -6,8 +6,13 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/audit"
+	"github.com/school-management/backend/internal/shared/calendar"
+	"github.com/school-management/backend/internal/shared/enrollment"
+	"github.com/school-management/backend/internal/shared/outbox"
 )
 
 var (
@@ -24,9 +29,14 @@ type Repository interface {
 	FindByID(ctx context.Context, id uint) (*models.Attendance, error)
 	FindByStudentAndDate(ctx context.Context, studentID uint, date time.Time) (*models.Attendance, error)
 	FindByStudentDateAndSchedule(ctx context.Context, studentID uint, date time.Time, scheduleID uint) (*models.Attendance, error)
+	FindLatestCheckIn(ctx context.Context, studentID uint, date time.Time) (*models.Attendance, error)
 	Update(ctx context.Context, attendance *models.Attendance) error
 	Delete(ctx context.Context, id uint) error
 
+	// Outbox-aware writes: the event is stored in the same transaction as the record
+	CreateWithEvent(ctx context.Context, attendance *models.Attendance, event *outbox.Event) error
+	UpdateWithEvent(ctx context.Context, attendance *models.Attendance, event *outbox.Event) error
+
 	// Query operations
 	FindByStudent(ctx context.Context, studentID uint, startDate, endDate time.Time) ([]models.Attendance, error)
 	FindByClassAndDate(ctx context.Context, classID uint, date time.Time) ([]models.Attendance, error)
@@ -62,6 +72,19 @@ type Repository interface {
 	// Wali Kelas operations
 	// Requirements: 2.7 - Find class assigned to wali_kelas
 	FindClassByHomeroomTeacher(ctx context.Context, schoolID uint, teacherID uint) (*models.Class, error)
+
+	// Leave operations (taps on days of approved leave are flagged for review)
+	FindApprovedLeave(ctx context.Context, studentID uint, date time.Time) (*models.LeaveRequest, error)
+
+	// Absence marking operations
+	FindActiveSchools(ctx context.Context) ([]models.School, error)
+	FindSchedulesForDate(ctx context.Context, schoolID uint, date time.Time) ([]models.AttendanceSchedule, error)
+	MarkAbsentForSchedule(ctx context.Context, schoolID uint, schedule *models.AttendanceSchedule, date time.Time) (int64, error)
+
+	// Offline sync receipts (idempotency keys of taps uploaded in batches)
+	ClaimSyncReceipt(ctx context.Context, receipt *models.AttendanceSyncReceipt, staleAfter time.Duration) (*models.AttendanceSyncReceipt, bool, error)
+	CompleteSyncReceipt(ctx context.Context, id uint, result string) error
+	ReleaseSyncReceipt(ctx context.Context, id uint) error
 }
 
 // repository implements the Repository interface
@@ -150,18 +173,72 @@ func (r *repository) FindByStudentDateAndSchedule(ctx context.Context, studentID
 	return &attendance, nil
 }
 
+// CreateWithEvent creates an attendance record and its outbox event atomically
+func (r *repository) CreateWithEvent(ctx context.Context, attendance *models.Attendance, event *outbox.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attendance).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, attendance.ID, event)
+	})
+}
+
+// UpdateWithEvent updates an attendance record and stores its outbox event atomically
+func (r *repository) UpdateWithEvent(ctx context.Context, attendance *models.Attendance, event *outbox.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updateAttendance(tx, attendance); err != nil {
+			return err
+		}
+		return outbox.Append(tx, attendance.ID, event)
+	})
+}
+
+// FindLatestCheckIn retrieves the student's most recent check-in record on a specific date
+// Requirements: 5.2 - A check-out tap SHALL complete the day's check-in record
+func (r *repository) FindLatestCheckIn(ctx context.Context, studentID uint, date time.Time) (*models.Attendance, error) {
+	var attendance models.Attendance
+
+	// Normalize date to start of day
+	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	err := r.db.WithContext(ctx).
+		Preload("Student").
+		Preload("Student.Class").
+		Preload("Schedule").
+		Where("student_id = ? AND date = ? AND check_in_time IS NOT NULL", studentID, dateOnly).
+		Order("check_in_time DESC").
+		First(&attendance).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttendanceNotFound
+		}
+		return nil, err
+	}
+
+	return &attendance, nil
+}
+
 // Update updates an attendance record
 func (r *repository) Update(ctx context.Context, attendance *models.Attendance) error {
-	result := r.db.WithContext(ctx).
+	return updateAttendance(r.db.WithContext(ctx), attendance)
+}
+
+// updateAttendance writes the mutable attendance fields using the given connection or transaction
+func updateAttendance(db *gorm.DB, attendance *models.Attendance) error {
+	result := db.
 		Model(&models.Attendance{}).
 		Where("id = ?", attendance.ID).
 		Updates(map[string]interface{}{
-			"student_id":     attendance.StudentID,
-			"date":           attendance.Date,
-			"check_in_time":  attendance.CheckInTime,
-			"check_out_time": attendance.CheckOutTime,
-			"status":         attendance.Status,
-			"method":         attendance.Method,
+			"student_id":       attendance.StudentID,
+			"date":             attendance.Date,
+			"check_in_time":    attendance.CheckInTime,
+			"check_out_time":   attendance.CheckOutTime,
+			"status":           attendance.Status,
+			"method":           attendance.Method,
+			"is_early_leave":   attendance.IsEarlyLeave,
+			"leave_request_id": attendance.LeaveRequestID,
+			"needs_review":     attendance.NeedsReview,
 		})
 	if result.Error != nil {
 		return result.Error
@@ -218,7 +295,7 @@ func (r *repository) FindByClassAndDate(ctx context.Context, classID uint, date
 		Preload("Student.Class").
 		Preload("Schedule").
 		Joins("JOIN students ON students.id = attendances.student_id").
-		Where("students.class_id = ? AND attendances.date = ?", classID, dateOnly).
+		Where(enrollment.ClassAt("attendances", "attendances.date")+" = ? AND attendances.date = ?", classID, dateOnly).
 		Order("students.name ASC").
 		Find(&attendances).Error
 
@@ -259,7 +336,7 @@ func (r *repository) FindAll(ctx context.Context, schoolID uint, filter Attendan
 		query = query.Where("attendances.student_id = ?", *filter.StudentID)
 	}
 	if filter.ClassID != nil {
-		query = query.Where("students.class_id = ?", *filter.ClassID)
+		query = query.Where(enrollment.ClassAt("attendances", "attendances.date")+" = ?", *filter.ClassID)
 	}
 	if filter.StartDate != nil {
 		startDate, err := time.Parse("2006-01-02", *filter.StartDate)
@@ -279,6 +356,9 @@ func (r *repository) FindAll(ctx context.Context, schoolID uint, filter Attendan
 	if filter.Method != nil && *filter.Method != "" {
 		query = query.Where("attendances.method = ?", *filter.Method)
 	}
+	if filter.NeedsReview != nil {
+		query = query.Where("attendances.needs_review = ?", *filter.NeedsReview)
+	}
 
 	// Count total records
 	if err := query.Count(&total).Error; err != nil {
@@ -470,11 +550,12 @@ func (r *repository) GetAttendanceSummaryByClass(ctx context.Context, schoolID u
 	}
 
 	for _, class := range classes {
-		// Get total students in class
+		// Get total active students in class on the date
 		var totalStudents int64
 		if err := r.db.WithContext(ctx).
 			Model(&models.Student{}).
-			Where("class_id = ? AND is_active = ?", class.ID, true).
+			Scopes(enrollment.EnrolledOn(class.ID, dateOnly)).
+			Where("is_active = ?", true).
 			Count(&totalStudents).Error; err != nil {
 			continue
 		}
@@ -494,7 +575,7 @@ func (r *repository) GetAttendanceSummaryByClass(ctx context.Context, schoolID u
 			Model(&models.Attendance{}).
 			Select("status, COUNT(*) as count").
 			Joins("JOIN students ON students.id = attendances.student_id").
-			Where("students.class_id = ? AND attendances.date = ?", class.ID, dateOnly).
+			Where(enrollment.ClassAt("attendances", "attendances.date")+" = ? AND attendances.date = ?", class.ID, dateOnly).
 			Group("status").
 			Scan(&statusCounts).Error
 
@@ -556,11 +637,12 @@ func (r *repository) GetClassAttendanceSummary(ctx context.Context, classID uint
 		return nil, err
 	}
 
-	// Get total active students in class
+	// Get total active students in class on the date
 	var totalStudents int64
 	if err := r.db.WithContext(ctx).
 		Model(&models.Student{}).
-		Where("class_id = ? AND is_active = ?", classID, true).
+		Scopes(enrollment.EnrolledOn(classID, dateOnly)).
+		Where("is_active = ?", true).
 		Count(&totalStudents).Error; err != nil {
 		return nil, err
 	}
@@ -609,14 +691,17 @@ func (r *repository) GetClassAttendanceSummary(ctx context.Context, classID uint
 // toAttendanceResponse converts an Attendance model to AttendanceResponse DTO
 func toAttendanceResponse(attendance *models.Attendance) *AttendanceResponse {
 	response := &AttendanceResponse{
-		ID:         attendance.ID,
-		StudentID:  attendance.StudentID,
-		ScheduleID: attendance.ScheduleID,
-		Date:       attendance.Date.Format("2006-01-02"),
-		Status:     attendance.Status,
-		Method:     attendance.Method,
-		CreatedAt:  attendance.CreatedAt,
-		UpdatedAt:  attendance.UpdatedAt,
+		ID:           attendance.ID,
+		StudentID:    attendance.StudentID,
+		ScheduleID:   attendance.ScheduleID,
+		Date:         attendance.Date.Format("2006-01-02"),
+		Status:       attendance.Status,
+		Method:       attendance.Method,
+		IsEarlyLeave: attendance.IsEarlyLeave,
+		NeedsReview:  attendance.NeedsReview,
+		LeaveID:      attendance.LeaveRequestID,
+		CreatedAt:    attendance.CreatedAt,
+		UpdatedAt:    attendance.UpdatedAt,
 	}
 
 	if attendance.CheckInTime != nil {
@@ -652,39 +737,15 @@ func toAttendanceResponse(attendance *models.Attendance) *AttendanceResponse {
 // Requirements: 3.4, 3.5 - Determine which schedule is currently active based on current time
 // STRICT MODE: Only allows attendance within schedule time window
 func (r *repository) FindActiveSchedule(ctx context.Context, schoolID uint, timestamp time.Time) (*models.AttendanceSchedule, error) {
-	var schedules []models.AttendanceSchedule
-	
-	// Get current time in HH:MM:SS format
-	currentTime := timestamp.Format("15:04:05")
-	
-	// Find all active schedules for this school
-	err := r.db.WithContext(ctx).
-		Where("school_id = ? AND is_active = ?", schoolID, true).
-		Find(&schedules).Error
-
+	// Schedules for this date: none on holidays, date overrides applied
+	schedules, err := calendar.SchedulesForDate(ctx, r.db, schoolID, timestamp)
 	if err != nil {
 		return nil, err
 	}
 
-	// Find the schedule that matches the current time and day
+	// Find the schedule that matches the current time
 	for _, schedule := range schedules {
-		// Check if schedule is active on this day
-		if !schedule.IsActiveOnDay(timestamp.Weekday()) {
-			continue
-		}
-
-		// Normalize times for comparison
-		startTime := schedule.StartTime
-		if len(startTime) == 5 {
-			startTime += ":00"
-		}
-		endTime := schedule.EndTime
-		if len(endTime) == 5 {
-			endTime += ":00"
-		}
-
-		// Check if current time is within schedule range
-		if currentTime >= startTime && currentTime <= endTime {
+		if schedule.IsTimeInRange(timestamp) {
 			return &schedule, nil
 		}
 	}
@@ -741,14 +802,14 @@ func (r *repository) GetAttendanceForExport(ctx context.Context, schoolID uint,
 			COALESCE(attendance_schedules.name, '') as schedule_name
 		`).
 		Joins("JOIN students ON students.id = attendances.student_id").
-		Joins("JOIN classes ON classes.id = students.class_id").
+		Joins("JOIN classes ON classes.id = "+enrollment.ClassAt("attendances", "attendances.date")).
 		Joins("LEFT JOIN attendance_schedules ON attendance_schedules.id = attendances.schedule_id").
 		Where("students.school_id = ?", schoolID).
 		Where("attendances.date >= ? AND attendances.date <= ?", startDate, endDate)
 
 	// Apply class filter if provided
 	if filter.ClassID != nil {
-		query = query.Where("students.class_id = ?", *filter.ClassID)
+		query = query.Where(enrollment.ClassAt("attendances", "attendances.date")+" = ?", *filter.ClassID)
 	}
 
 	// Order by date and student name
@@ -818,14 +879,24 @@ func (r *repository) GetMonthlyRecap(ctx context.Context, schoolID uint, filter
 	startDate := time.Date(filter.Year, time.Month(filter.Month), 1, 0, 0, 0, 0, time.UTC)
 	endDate := startDate.AddDate(0, 1, -1) // Last day of the month
 
-	// Get all students in the school (optionally filtered by class)
-	students, err := r.GetStudentsBySchool(ctx, schoolID, filter.ClassID)
+	// Calculate total school days (weekdays only, Mon-Fri, excluding the school's holidays)
+	holidays, err := calendar.HolidayDates(ctx, r.db, schoolID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
+	totalDays := calendar.CountSchoolDays(startDate, endDate, holidays)
 
-	// Calculate total school days (weekdays only, Mon-Fri)
-	totalDays := countWeekdays(startDate, endDate)
+	// Get all students in the school, or the students who were in the class during the month
+	var students []models.Student
+	var memberDays map[uint]int
+	if filter.ClassID != nil {
+		students, memberDays, err = r.findClassMembers(ctx, schoolID, *filter.ClassID, startDate, endDate, holidays)
+	} else {
+		students, err = r.GetStudentsBySchool(ctx, schoolID, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	// Get class name if filtered by class
 	var className string
@@ -855,24 +926,36 @@ func (r *repository) GetMonthlyRecap(ctx context.Context, schoolID uint, filter
 		}
 		var statusCounts []StatusCount
 
-		err := r.db.WithContext(ctx).
+		query := r.db.WithContext(ctx).
 			Model(&models.Attendance{}).
 			Select("status, COUNT(*) as count").
-			Where("student_id = ? AND date >= ? AND date <= ?", student.ID, startDate, endDate).
-			Group("status").
-			Scan(&statusCounts).Error
+			Where("student_id = ? AND date >= ? AND date <= ?", student.ID, startDate, endDate)
+		if filter.ClassID != nil {
+			query = query.Where(enrollment.ClassAt("attendances", "attendances.date")+" = ?", *filter.ClassID)
+		}
+		err := query.Group("status").Scan(&statusCounts).Error
 
 		if err != nil {
 			continue
 		}
 
+		// Students who joined or left the class during the month only count their days in it
+		studentDays := totalDays
+		if days, ok := memberDays[student.ID]; ok {
+			studentDays = days
+		}
+
 		// Build student summary
 		summary := StudentRecapSummary{
 			StudentID:   student.ID,
 			StudentNIS:  student.NIS,
 			StudentNISN: student.NISN,
 			StudentName: student.Name,
-			ClassName:   student.Class.Name,
+		}
+		if filter.ClassID != nil {
+			summary.ClassName = className
+		} else if student.Class != nil {
+			summary.ClassName = student.Class.Name
 		}
 
 		for _, sc := range statusCounts {
@@ -892,15 +975,15 @@ func (r *repository) GetMonthlyRecap(ctx context.Context, schoolID uint, filter
 
 		// Calculate absent days (excluding sick and excused which are tracked separately)
 		totalAttended := summary.TotalPresent + summary.TotalLate + summary.TotalVeryLate + summary.TotalSick + summary.TotalExcused
-		summary.TotalAbsent = totalDays - totalAttended
+		summary.TotalAbsent = studentDays - totalAttended
 		if summary.TotalAbsent < 0 {
 			summary.TotalAbsent = 0
 		}
 
 		// Calculate attendance percentage (present / total_days * 100)
 		// Requirements: 2.2 - Calculate and display attendance percentage
-		if totalDays > 0 {
-			summary.AttendancePercent = float64(summary.TotalPresent) / float64(totalDays) * 100
+		if studentDays > 0 {
+			summary.AttendancePercent = float64(summary.TotalPresent) / float64(studentDays) * 100
 		}
 
 		response.StudentRecaps = append(response.StudentRecaps, summary)
@@ -913,16 +996,35 @@ func (r *repository) GetMonthlyRecap(ctx context.Context, schoolID uint, filter
 	return response, nil
 }
 
-// countWeekdays counts the number of weekdays (Mon-Fri) between two dates
-func countWeekdays(start, end time.Time) int {
-	count := 0
-	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
-		weekday := d.Weekday()
-		if weekday != time.Saturday && weekday != time.Sunday {
-			count++
+// findClassMembers retrieves the students who were in a class at some point from start to end,
+// with the number of school days each of them spent in it
+func (r *repository) findClassMembers(ctx context.Context, schoolID uint, classID uint, start, end time.Time, holidays map[string]bool) ([]models.Student, map[uint]int, error) {
+	enrollments, err := enrollment.InClass(ctx, r.db, classID, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	students := make([]models.Student, 0, len(enrollments))
+	days := make(map[uint]int, len(enrollments))
+	for _, e := range enrollments {
+		if e.Student.SchoolID != schoolID {
+			continue
+		}
+		if _, ok := days[e.StudentID]; !ok {
+			students = append(students, e.Student)
 		}
+
+		from, to := start, end
+		if e.StartDate.After(from) {
+			from = e.StartDate
+		}
+		if e.EndDate != nil && e.EndDate.AddDate(0, 0, -1).Before(to) {
+			to = e.EndDate.AddDate(0, 0, -1)
+		}
+		days[e.StudentID] += calendar.CountSchoolDays(from, to, holidays)
 	}
-	return count
+
+	return students, days, nil
 }
 
 // sortStudentRecapsByPercentage sorts student recaps by attendance percentage descending
@@ -941,7 +1043,7 @@ func sortStudentRecapsByPercentage(recaps []StudentRecapSummary) {
 func (r *repository) FindClassByHomeroomTeacher(ctx context.Context, schoolID uint, teacherID uint) (*models.Class, error) {
 	var class models.Class
 	err := r.db.WithContext(ctx).
-		Where("school_id = ? AND homeroom_teacher_id = ?", schoolID, teacherID).
+		Where("school_id = ? AND homeroom_teacher_id = ? AND is_archived = ?", schoolID, teacherID, false).
 		First(&class).Error
 
 	if err != nil {
@@ -953,3 +1055,168 @@ func (r *repository) FindClassByHomeroomTeacher(ctx context.Context, schoolID ui
 
 	return &class, nil
 }
+
+// ==================== Absence Marking ====================
+
+// FindActiveSchools retrieves all active schools
+func (r *repository) FindActiveSchools(ctx context.Context) ([]models.School, error) {
+	var schools []models.School
+	err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Find(&schools).Error
+	return schools, err
+}
+
+// FindApprovedLeave finds the approved leave of a student that covers the date
+func (r *repository) FindApprovedLeave(ctx context.Context, studentID uint, date time.Time) (*models.LeaveRequest, error) {
+	var leave models.LeaveRequest
+	err := r.db.WithContext(ctx).
+		Where("student_id = ? AND status = ? AND start_date <= ?::date AND end_date >= ?::date",
+			studentID, models.LeaveStatusApproved, date.Format("2006-01-02"), date.Format("2006-01-02")).
+		First(&leave).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil // Not on leave is not an error
+		}
+		return nil, err
+	}
+
+	return &leave, nil
+}
+
+// FindSchedulesForDate retrieves the schedules that apply on a date, following the school calendar
+func (r *repository) FindSchedulesForDate(ctx context.Context, schoolID uint, date time.Time) ([]models.AttendanceSchedule, error) {
+	return calendar.SchedulesForDate(ctx, r.db, schoolID, date)
+}
+
+// MarkAbsentForSchedule creates absent records for active students that have no attendance
+// for the schedule on the given date. Students with a sick/excused record that day or with a
+// permit overlapping the schedule window are skipped.
+// The schedule row is locked for the duration of the insert so concurrent runs cannot create
+// duplicates; running it again for the same schedule and date inserts nothing.
+func (r *repository) MarkAbsentForSchedule(ctx context.Context, schoolID uint, schedule *models.AttendanceSchedule, date time.Time) (int64, error) {
+	dateStr := date.Format("2006-01-02")
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	windowStart := schedule.StartTimeOn(date)
+	windowEnd := schedule.EndTimeOn(date)
+
+	var inserted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked models.AttendanceSchedule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", schedule.ID).
+			First(&locked).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`
+			INSERT INTO attendances (student_id, schedule_id, date, status, method, created_at, updated_at)
+			SELECT s.id, ?, ?::date, ?, ?, NOW(), NOW()
+			FROM students s
+			WHERE s.school_id = ?
+				AND s.is_active = true
+				AND s.class_id IS NOT NULL
+				AND NOT EXISTS (
+					SELECT 1 FROM attendances a
+					WHERE a.student_id = s.id AND a.date = ?::date AND a.schedule_id = ?
+				)
+				AND NOT EXISTS (
+					SELECT 1 FROM attendances a
+					WHERE a.student_id = s.id AND a.date = ?::date AND a.status IN (?, ?)
+				)
+				AND NOT EXISTS (
+					SELECT 1 FROM permits p
+					WHERE p.student_id = s.id
+						AND p.exit_time >= ? AND p.exit_time <= ?
+						AND (p.return_time IS NULL OR p.return_time >= ?)
+				)`,
+			schedule.ID, dateStr, models.AttendanceStatusAbsent, models.AttendanceMethodAuto,
+			schoolID,
+			dateStr, schedule.ID,
+			dateStr, models.AttendanceStatusSick, models.AttendanceStatusExcused,
+			dayStart, windowEnd, windowStart,
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		inserted = result.RowsAffected
+
+		return audit.RecordBulk(ctx, tx, audit.BulkChange{
+			Action:       models.AuditActionCreate,
+			Table:        "attendances",
+			SchoolID:     schoolID,
+			Filter:       "absent students without attendance for schedule_id = ? on date = ?",
+			Args:         []interface{}{schedule.ID, dateStr},
+			Set:          map[string]interface{}{"status": models.AttendanceStatusAbsent, "method": models.AttendanceMethodAuto},
+			RowsAffected: result.RowsAffected,
+			Job:          "absence-scheduler",
+		})
+	})
+
+	return inserted, err
+}
+
+// ClaimSyncReceipt claims a device tap by its idempotency key before it is processed.
+// It returns the stored receipt and whether the caller now owns the tap. A tap that is
+// already claimed is not owned, unless its receipt was left in processing for longer than
+// staleAfter (the request that claimed it crashed), in which case it is taken over.
+func (r *repository) ClaimSyncReceipt(ctx context.Context, receipt *models.AttendanceSyncReceipt, staleAfter time.Duration) (*models.AttendanceSyncReceipt, bool, error) {
+	receipt.Status = models.AttendanceSyncStatusProcessing
+	if err := receipt.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "device_id"}, {Name: "idempotency_key"}},
+			DoNothing: true,
+		}).
+		Create(receipt)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return receipt, true, nil
+	}
+
+	var existing models.AttendanceSyncReceipt
+	if err := r.db.WithContext(ctx).
+		Where("device_id = ? AND idempotency_key = ?", receipt.DeviceID, receipt.IdempotencyKey).
+		First(&existing).Error; err != nil {
+		return nil, false, err
+	}
+	if existing.IsCompleted() {
+		return &existing, false, nil
+	}
+
+	now := time.Now()
+	takeover := r.db.WithContext(ctx).
+		Model(&models.AttendanceSyncReceipt{}).
+		Where("id = ? AND status = ? AND updated_at < ?", existing.ID, models.AttendanceSyncStatusProcessing, now.Add(-staleAfter)).
+		Update("updated_at", now)
+	if takeover.Error != nil {
+		return nil, false, takeover.Error
+	}
+
+	return &existing, takeover.RowsAffected == 1, nil
+}
+
+// CompleteSyncReceipt stores the result of a processed tap so replays return it
+func (r *repository) CompleteSyncReceipt(ctx context.Context, id uint, result string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AttendanceSyncReceipt{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     models.AttendanceSyncStatusCompleted,
+			"result":     result,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// ReleaseSyncReceipt removes the claim on a tap that failed to process, so the device can resend it
+func (r *repository) ReleaseSyncReceipt(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Where("id = ? AND status = ?", id, models.AttendanceSyncStatusProcessing).
+		Delete(&models.AttendanceSyncReceipt{}).Error
+}
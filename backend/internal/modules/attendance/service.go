@@ -3,11 +3,13 @@ package attendance
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/school-management/backend/internal/domain/models"
 	"github.com/school-management/backend/internal/modules/device"
+	"github.com/school-management/backend/internal/shared/outbox"
 )
 
 var (
@@ -28,6 +30,8 @@ var (
 type Service interface {
 	// RFID attendance (from ESP32 devices)
 	RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRequest) (*RFIDAttendanceResponse, error)
+	// Batch sync of taps buffered by a device while offline
+	RecordRFIDBatch(ctx context.Context, req RFIDBatchRequest) (*RFIDBatchResponse, error)
 	
 	// Manual attendance (fallback)
 	RecordManualAttendance(ctx context.Context, schoolID uint, req ManualAttendanceRequest) (*AttendanceResponse, error)
@@ -50,24 +54,15 @@ type Service interface {
 	GetMonthlyRecap(ctx context.Context, schoolID uint, filter MonthlyRecapFilter) (*MonthlyRecapResponse, error)
 	// Requirements: 2.5 - Export monthly recap to Excel
 	ExportMonthlyRecapToExcel(ctx context.Context, schoolID uint, schoolName string, filter MonthlyRecapFilter) ([]byte, string, error)
-
-	// Real-time integration
-	// Requirements: 4.2 - Set broadcaster for real-time updates
-	SetRealtimeBroadcaster(broadcaster RealtimeBroadcaster)
-}
-
-// RealtimeBroadcaster defines the interface for broadcasting real-time attendance events
-// Requirements: 4.2 - WHEN a student taps RFID card, THE System SHALL update the dashboard within 3 seconds
-type RealtimeBroadcaster interface {
-	BroadcastAttendance(ctx context.Context, schoolID uint, attendance *models.Attendance, student *models.Student, attendanceType string)
 }
 
 // service implements the Service interface
+// Realtime updates and parent notifications are written to the outbox together with
+// the attendance record and delivered by the outbox relay.
 type service struct {
 	repo          Repository
 	deviceService device.Service
 	policy        AttendancePolicy
-	realtime      RealtimeBroadcaster
 }
 
 // NewService creates a new attendance service
@@ -79,17 +74,11 @@ func NewService(repo Repository, deviceService device.Service, policy Attendance
 	}
 }
 
-// SetRealtimeBroadcaster sets the real-time broadcaster for the service
-// This is called after initialization to avoid circular dependencies
-func (s *service) SetRealtimeBroadcaster(broadcaster RealtimeBroadcaster) {
-	s.realtime = broadcaster
-}
-
 // RecordRFIDAttendance records attendance from RFID device
 // Requirements: 5.1, 5.2 - WHEN a student taps RFID card, record check-in or check-out
 func (s *service) RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRequest) (*RFIDAttendanceResponse, error) {
-	// Validate required fields
-	if req.APIKey == "" {
+	// Validate required fields; signed requests are authenticated without the API key
+	if _, signed := device.AuthenticatedDevice(ctx); !signed && req.APIKey == "" {
 		return nil, ErrAPIKeyRequired
 	}
 	if req.RFIDCode == "" {
@@ -105,16 +94,22 @@ func (s *service) RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRe
 		return nil, device.ErrInvalidAPIKey
 	}
 
+	return s.recordTap(ctx, validation.SchoolID, req.RFIDCode, req.Timestamp)
+}
+
+// recordTap records a single RFID tap from a device of the given school
+// The tap is evaluated against the schedule that was active at tapTime (now when zero)
+func (s *service) recordTap(ctx context.Context, deviceSchoolID uint, rfidCode string, tapTime time.Time) (*RFIDAttendanceResponse, error) {
 	// Find student by RFID code
-	student, err := s.repo.FindStudentByRFID(ctx, req.RFIDCode)
+	student, err := s.repo.FindStudentByRFID(ctx, rfidCode)
 	if err != nil {
-		log.Printf("RFID attendance failed: student not found for RFID %s", req.RFIDCode)
+		log.Printf("RFID attendance failed: student not found for RFID %s", rfidCode)
 		return nil, err
 	}
 
 	// Verify student belongs to the same school as the device
-	if student.SchoolID != validation.SchoolID {
-		log.Printf("RFID attendance failed: student school %d doesn't match device school %d", student.SchoolID, validation.SchoolID)
+	if student.SchoolID != deviceSchoolID {
+		log.Printf("RFID attendance failed: student school %d doesn't match device school %d", student.SchoolID, deviceSchoolID)
 		return nil, ErrInvalidRFIDCode
 	}
 
@@ -126,7 +121,7 @@ func (s *service) RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRe
 
 	// Use current time in school's timezone
 	var timestamp time.Time
-	if req.Timestamp.IsZero() {
+	if tapTime.IsZero() {
 		if school != nil {
 			timestamp = school.GetCurrentTime()
 			log.Printf("Using school timezone: %s, current time: %s", school.Timezone, timestamp.Format("15:04:05"))
@@ -136,9 +131,9 @@ func (s *service) RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRe
 	} else {
 		// If timestamp provided, convert to school's timezone
 		if school != nil {
-			timestamp = req.Timestamp.In(school.GetLocation())
+			timestamp = tapTime.In(school.GetLocation())
 		} else {
-			timestamp = req.Timestamp
+			timestamp = tapTime
 		}
 	}
 
@@ -172,6 +167,11 @@ func (s *service) RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRe
 		}, ErrOutsideAttendanceWindow
 	}
 
+	// Requirements: 5.2 - A tap inside a check-out window records check-out
+	if activeSchedule.IsCheckOut() {
+		return s.recordRFIDCheckOut(ctx, student, activeSchedule, timestamp, date)
+	}
+
 	var response *RFIDAttendanceResponse
 
 	// Check if student already has attendance for this specific schedule today
@@ -180,6 +180,11 @@ func (s *service) RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRe
 		return nil, err
 	}
 
+	if existingForSchedule != nil && existingForSchedule.IsAutoAbsent() {
+		// A tap synced after the window closed replaces the absence marked by the scheduler
+		return s.replaceAutoAbsence(ctx, student, activeSchedule, existingForSchedule, timestamp)
+	}
+
 	if existingForSchedule != nil {
 		// Student already checked in for this schedule
 		log.Printf("RFID attendance rejected: student %s already checked in for schedule '%s'", 
@@ -212,8 +217,11 @@ func (s *service) RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRe
 			Status:     status,
 		}
 		attendance.SetCheckIn(timestamp)
+		s.flagLeaveTap(ctx, attendance)
 
-		if err := s.repo.Create(ctx, attendance); err != nil {
+		// Requirements: 4.2, 5.3 - Realtime update and parent notification are relayed through the outbox
+		event := newAttendanceEvent(student, attendance, activeSchedule, outbox.EventAttendanceCheckIn, "check_in")
+		if err := s.repo.CreateWithEvent(ctx, attendance, event); err != nil {
 			return nil, err
 		}
 
@@ -230,11 +238,6 @@ func (s *service) RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRe
 		log.Printf("RFID check-in recorded: student %s (%d) at %s, status: %s", 
 			student.Name, student.ID, timestamp.Format("15:04"), status)
 
-		// Requirements: 4.2 - Broadcast real-time update
-		if s.realtime != nil {
-			go s.realtime.BroadcastAttendance(ctx, student.SchoolID, attendance, student, "check_in")
-		}
-
 	} else {
 		// Student has attendance today but for a different schedule
 		// This is a new schedule, so record new attendance
@@ -251,8 +254,11 @@ func (s *service) RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRe
 			Status:     status,
 		}
 		attendance.SetCheckIn(timestamp)
+		s.flagLeaveTap(ctx, attendance)
 
-		if err := s.repo.Create(ctx, attendance); err != nil {
+		// Requirements: 4.2, 5.3 - Realtime update and parent notification are relayed through the outbox
+		event := newAttendanceEvent(student, attendance, activeSchedule, outbox.EventAttendanceCheckIn, "check_in")
+		if err := s.repo.CreateWithEvent(ctx, attendance, event); err != nil {
 			return nil, err
 		}
 
@@ -268,19 +274,212 @@ func (s *service) RecordRFIDAttendance(ctx context.Context, req RFIDAttendanceRe
 
 		log.Printf("RFID check-in recorded for new schedule: student %s (%d) at %s, status: %s", 
 			student.Name, student.ID, timestamp.Format("15:04"), status)
+	}
 
-		// Requirements: 4.2 - Broadcast real-time update
-		if s.realtime != nil {
-			go s.realtime.BroadcastAttendance(ctx, student.SchoolID, attendance, student, "check_in")
+	return response, nil
+}
+
+// replaceAutoAbsence turns an absence marked by the scheduler into a check-in
+// This happens when a device syncs taps it buffered while offline after the window closed
+func (s *service) replaceAutoAbsence(ctx context.Context, student *models.Student, schedule *models.AttendanceSchedule, attendance *models.Attendance, timestamp time.Time) (*RFIDAttendanceResponse, error) {
+	status := schedule.GetLateStatus(timestamp)
+	attendance.Status = status
+	attendance.Method = models.AttendanceMethodRFID
+	attendance.SetCheckIn(timestamp)
+	s.flagLeaveTap(ctx, attendance)
+
+	event := newAttendanceEvent(student, attendance, schedule, outbox.EventAttendanceCheckIn, "check_in")
+	if err := s.repo.UpdateWithEvent(ctx, attendance, event); err != nil {
+		return nil, err
+	}
+
+	log.Printf("RFID check-in replaced automatic absence: student %s (%d) at %s, status: %s",
+		student.Name, student.ID, timestamp.Format("15:04"), status)
+
+	return &RFIDAttendanceResponse{
+		Success:     true,
+		StudentID:   student.ID,
+		StudentName: student.Name,
+		Type:        "check_in",
+		Status:      status,
+		Time:        timestamp,
+		Message:     "Check-in recorded successfully",
+	}, nil
+}
+
+// flagLeaveTap marks a check-in for review when the student is on approved leave that day
+// The tap is still recorded; the wali kelas decides whether the leave or the tap is right.
+func (s *service) flagLeaveTap(ctx context.Context, attendance *models.Attendance) {
+	leave, err := s.repo.FindApprovedLeave(ctx, attendance.StudentID, attendance.Date)
+	if err != nil {
+		log.Printf("Warning: Failed to check leave for student %d: %v", attendance.StudentID, err)
+		return
+	}
+	if leave == nil {
+		return
+	}
+
+	attendance.LeaveRequestID = &leave.ID
+	attendance.NeedsReview = true
+	log.Printf("RFID tap flagged for review: student %d is on approved leave %d on %s",
+		attendance.StudentID, leave.ID, attendance.Date.Format("2006-01-02"))
+}
+
+// recordRFIDCheckOut sets the check-out time on the student's latest check-in of the day
+// Requirements: 5.2 - Second attendance record SHALL be recorded as check-out
+func (s *service) recordRFIDCheckOut(ctx context.Context, student *models.Student, schedule *models.AttendanceSchedule, timestamp, date time.Time) (*RFIDAttendanceResponse, error) {
+	attendance, err := s.repo.FindLatestCheckIn(ctx, student.ID, date)
+	if err != nil {
+		if errors.Is(err, ErrAttendanceNotFound) {
+			log.Printf("RFID check-out rejected: student %s has no check-in on %s", student.Name, date.Format("2006-01-02"))
+			return &RFIDAttendanceResponse{
+				Success:     false,
+				StudentID:   student.ID,
+				StudentName: student.Name,
+				Type:        "no_check_in",
+				Time:        timestamp,
+				Message:     "Belum ada absen masuk hari ini",
+			}, ErrNoCheckIn
 		}
+		return nil, err
 	}
 
-	// TODO: Trigger notification to parent (async)
-	// Requirements: 5.3 - WHEN attendance is recorded, THE System SHALL trigger notification to parent
+	if attendance.HasCheckedOut() {
+		log.Printf("RFID check-out rejected: student %s already checked out at %s", student.Name, attendance.CheckOutTime.Format("15:04"))
+		return &RFIDAttendanceResponse{
+			Success:     false,
+			StudentID:   student.ID,
+			StudentName: student.Name,
+			Type:        "already_checked_out",
+			Status:      attendance.Status,
+			Time:        timestamp,
+			Message:     "Anda sudah absen pulang hari ini",
+		}, ErrAlreadyCheckedOut
+	}
 
-	return response, nil
+	if err := attendance.SetCheckOut(timestamp); err != nil {
+		return nil, ErrCheckOutBeforeIn
+	}
+	attendance.IsEarlyLeave = schedule.IsEarlyLeave(timestamp)
+
+	// Requirements: 4.2, 5.3 - Realtime check_out event and parent notification are relayed through the outbox
+	event := newAttendanceEvent(student, attendance, schedule, outbox.EventAttendanceCheckOut, "check_out")
+	if err := s.repo.UpdateWithEvent(ctx, attendance, event); err != nil {
+		return nil, err
+	}
+
+	message := "Check-out recorded successfully"
+	if attendance.IsEarlyLeave {
+		message = "Check-out recorded (pulang lebih awal)"
+	}
+
+	log.Printf("RFID check-out recorded: student %s (%d) at %s, early leave: %t",
+		student.Name, student.ID, timestamp.Format("15:04"), attendance.IsEarlyLeave)
+
+	return &RFIDAttendanceResponse{
+		Success:     true,
+		StudentID:   student.ID,
+		StudentName: student.Name,
+		Type:        "check_out",
+		Status:      attendance.Status,
+		Time:        timestamp,
+		EarlyLeave:  attendance.IsEarlyLeave,
+		Message:     message,
+	}, nil
+}
+
+// newAttendanceEvent builds the outbox event for an attendance write
+// Requirements: 5.3 - WHEN attendance is recorded, THE System SHALL trigger notification to parent
+func newAttendanceEvent(student *models.Student, attendance *models.Attendance, schedule *models.AttendanceSchedule, eventType, attendanceType string) *outbox.Event {
+	payload := outbox.Payload{
+		SchoolID:       student.SchoolID,
+		StudentID:      student.ID,
+		AttendanceType: attendanceType,
+	}
+
+	notifType := models.NotificationTypeAttendanceIn
+	if attendanceType == "check_out" {
+		notifType = models.NotificationTypeAttendanceOut
+	}
+
+	if title, message := buildAttendanceNotification(student, attendance, schedule, notifType); message != "" {
+		data := map[string]interface{}{
+			"student_id": fmt.Sprintf("%d", student.ID),
+			"status":     string(attendance.Status),
+			"date":       attendance.Date.Format("2006-01-02"),
+		}
+		if schedule != nil {
+			data["schedule_id"] = fmt.Sprintf("%d", schedule.ID)
+			data["schedule_name"] = schedule.Name
+		}
+		if attendance.IsEarlyLeave {
+			data["early_leave"] = "true"
+		}
+		payload.Notification = outbox.ParentNotification(notifType, title, message, data)
+	}
+
+	return outbox.NewEvent(eventType, payload)
 }
 
+// buildAttendanceNotification builds the title and message of an attendance notification
+func buildAttendanceNotification(student *models.Student, attendance *models.Attendance, schedule *models.AttendanceSchedule, notifType models.NotificationType) (string, string) {
+	scheduleName := ""
+	if schedule != nil {
+		scheduleName = fmt.Sprintf(" (%s)", schedule.Name)
+	}
+
+	switch notifType {
+	case models.NotificationTypeAttendanceIn:
+		if attendance.CheckInTime == nil {
+			return "", ""
+		}
+		return "Kehadiran Siswa",
+			fmt.Sprintf("%s telah absen masuk pukul %s%s dengan status %s.",
+				student.Name, attendance.CheckInTime.Format("15:04"), scheduleName, translateStatus(string(attendance.Status)))
+	case models.NotificationTypeAttendanceOut:
+		if attendance.CheckOutTime == nil {
+			return "", ""
+		}
+		earlyLeave := ""
+		if attendance.IsEarlyLeave {
+			earlyLeave = " sebelum jam pulang"
+		}
+		return "Kepulangan Siswa",
+			fmt.Sprintf("%s telah absen pulang pukul %s%s%s.",
+				student.Name, attendance.CheckOutTime.Format("15:04"), scheduleName, earlyLeave)
+	}
+	return "", ""
+}
+
+// newManualAttendanceEvent builds the outbox event for a manually recorded attendance
+func newManualAttendanceEvent(student *models.Student, attendance *models.Attendance) *outbox.Event {
+	payload := outbox.Payload{
+		SchoolID:  student.SchoolID,
+		StudentID: student.ID,
+	}
+
+	data := map[string]interface{}{
+		"student_id": fmt.Sprintf("%d", student.ID),
+		"status":     string(attendance.Status),
+		"date":       attendance.Date.Format("2006-01-02"),
+	}
+	message := fmt.Sprintf("Kehadiran %s pada %s dicatat oleh sekolah dengan status %s.",
+		student.Name, attendance.Date.Format("02/01/2006"), translateStatus(string(attendance.Status)))
+	notifType, title := manualAttendanceNotificationType(attendance.Status)
+	payload.Notification = outbox.ParentNotification(notifType, title, message, data)
+
+	return outbox.NewEvent(outbox.EventAttendanceRecorded, payload)
+}
+
+// manualAttendanceNotificationType returns the notification type and title for a manually
+// recorded status, so parents of an absent student are not told they checked in
+func manualAttendanceNotificationType(status models.AttendanceStatus) (models.NotificationType, string) {
+	switch status {
+	case models.AttendanceStatusAbsent, models.AttendanceStatusSick, models.AttendanceStatusExcused:
+		return models.NotificationTypeAttendanceAbsent, "Ketidakhadiran Siswa"
+	}
+	return models.NotificationTypeAttendanceIn, "Kehadiran Siswa"
+}
 
 // RecordManualAttendance records manual attendance entry
 // Requirements: 5.5 - IF RFID system fails, THEN THE System SHALL allow manual attendance entry
@@ -358,7 +557,7 @@ func (s *service) RecordManualAttendance(ctx context.Context, schoolID uint, req
 			Status:       status,
 		}
 
-		if err := s.repo.Create(ctx, attendance); err != nil {
+		if err := s.repo.CreateWithEvent(ctx, attendance, newManualAttendanceEvent(student, attendance)); err != nil {
 			return nil, err
 		}
 	} else if err != nil {
@@ -374,7 +573,7 @@ func (s *service) RecordManualAttendance(ctx context.Context, schoolID uint, req
 		}
 		existing.Method = models.AttendanceMethodManual
 
-		if err := s.repo.Update(ctx, existing); err != nil {
+		if err := s.repo.UpdateWithEvent(ctx, existing, newManualAttendanceEvent(student, existing)); err != nil {
 			return nil, err
 		}
 		attendance = existing
@@ -0,0 +1,211 @@
+package attendance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/modules/device"
+)
+
+const (
+	// MaxSyncBatchSize is the maximum number of taps accepted in one batch
+	MaxSyncBatchSize = 200
+	// MaxSyncTapAge is how old a buffered tap may be when it is synced
+	MaxSyncTapAge = 7 * 24 * time.Hour
+	// MaxSyncClockSkew is how far in the future a device timestamp may be
+	MaxSyncClockSkew = 5 * time.Minute
+	// syncClaimTimeout is how long a claimed tap may stay in processing before another request takes it over
+	syncClaimTimeout = 2 * time.Minute
+)
+
+var (
+	ErrSyncBatchEmpty    = errors.New("daftar tap tidak boleh kosong")
+	ErrSyncBatchTooLarge = errors.New("maksimal 200 tap per sinkronisasi")
+)
+
+// RecordRFIDBatch records taps buffered by a device while it was offline
+// Taps are processed in chronological order so a check-in is recorded before the check-out
+// that follows it. Each tap is evaluated against the schedule active at its device timestamp,
+// and its idempotency key makes resending the same tap safe.
+func (s *service) RecordRFIDBatch(ctx context.Context, req RFIDBatchRequest) (*RFIDBatchResponse, error) {
+	if _, signed := device.AuthenticatedDevice(ctx); !signed && req.APIKey == "" {
+		return nil, ErrAPIKeyRequired
+	}
+	if len(req.Taps) == 0 {
+		return nil, ErrSyncBatchEmpty
+	}
+	if len(req.Taps) > MaxSyncBatchSize {
+		return nil, ErrSyncBatchTooLarge
+	}
+
+	validation, err := s.deviceService.ValidateAPIKey(ctx, req.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	if !validation.Valid {
+		return nil, device.ErrInvalidAPIKey
+	}
+
+	order := make([]int, len(req.Taps))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return req.Taps[order[a]].Timestamp.Before(req.Taps[order[b]].Timestamp)
+	})
+
+	now := time.Now()
+	response := &RFIDBatchResponse{
+		Total:   len(req.Taps),
+		Results: make([]RFIDBatchTapResult, len(req.Taps)),
+	}
+	for _, i := range order {
+		result := s.syncTap(ctx, validation, req.Taps[i], now)
+		response.Results[i] = result
+
+		switch {
+		case result.Success:
+			response.Recorded++
+		case result.Retryable:
+			response.Failed++
+		default:
+			response.Rejected++
+		}
+	}
+
+	log.Printf("RFID batch sync from device %d: %d taps, %d recorded, %d rejected, %d failed",
+		validation.DeviceID, response.Total, response.Recorded, response.Rejected, response.Failed)
+
+	return response, nil
+}
+
+// syncTap records a single buffered tap, or returns the stored result if it was synced before
+func (s *service) syncTap(ctx context.Context, validation *device.APIKeyValidationResponse, tap RFIDBatchTap, now time.Time) RFIDBatchTapResult {
+	result := RFIDBatchTapResult{
+		IdempotencyKey: tap.IdempotencyKey,
+		Time:           tap.Timestamp,
+	}
+
+	// Invalid taps can never succeed, so they are rejected without a receipt
+	switch {
+	case strings.TrimSpace(tap.IdempotencyKey) == "" || len(tap.IdempotencyKey) > 64:
+		return rejectTap(result, "VAL_REQUIRED_FIELD", "Idempotency key wajib diisi (maksimal 64 karakter)")
+	case tap.RFIDCode == "":
+		return rejectTap(result, "VAL_REQUIRED_FIELD", "Kode RFID wajib diisi")
+	case tap.Timestamp.IsZero():
+		return rejectTap(result, "VAL_REQUIRED_FIELD", "Waktu tap wajib diisi")
+	case tap.Timestamp.After(now.Add(MaxSyncClockSkew)):
+		return rejectTap(result, "VAL_INVALID_TIME", "Waktu tap berada di masa depan")
+	case tap.Timestamp.Before(now.Add(-MaxSyncTapAge)):
+		return rejectTap(result, "VAL_TAP_TOO_OLD", "Tap terlalu lama untuk disinkronkan")
+	}
+
+	receipt, claimed, err := s.repo.ClaimSyncReceipt(ctx, &models.AttendanceSyncReceipt{
+		DeviceID:       validation.DeviceID,
+		IdempotencyKey: tap.IdempotencyKey,
+		RFIDCode:       tap.RFIDCode,
+		TapTime:        tap.Timestamp,
+	}, syncClaimTimeout)
+	if err != nil {
+		log.Printf("RFID batch sync: failed to claim tap %s from device %d: %v", tap.IdempotencyKey, validation.DeviceID, err)
+		return retryTap(result, "Gagal memproses tap, kirim ulang nanti")
+	}
+
+	if !claimed {
+		if receipt.IsCompleted() {
+			var stored RFIDBatchTapResult
+			if err := json.Unmarshal([]byte(receipt.Result), &stored); err == nil {
+				stored.Duplicate = true
+				return stored
+			}
+		}
+		// Another request is still processing the same tap
+		return retryTap(result, "Tap sedang diproses, kirim ulang nanti")
+	}
+
+	response, err := s.recordTap(ctx, validation.SchoolID, tap.RFIDCode, tap.Timestamp)
+	if response != nil {
+		result.Success = response.Success
+		result.Type = response.Type
+		result.Status = response.Status
+		result.StudentID = response.StudentID
+		result.StudentName = response.StudentName
+		result.Time = response.Time
+		result.EarlyLeave = response.EarlyLeave
+		result.Message = response.Message
+	}
+	if err != nil {
+		code, final := tapErrorCode(err)
+		if !final {
+			// Unexpected failure: release the claim so the resent tap is processed again
+			log.Printf("RFID batch sync: failed to record tap %s from device %d: %v", tap.IdempotencyKey, validation.DeviceID, err)
+			if releaseErr := s.repo.ReleaseSyncReceipt(ctx, receipt.ID); releaseErr != nil {
+				log.Printf("RFID batch sync: failed to release tap %s: %v", tap.IdempotencyKey, releaseErr)
+			}
+			return retryTap(result, "Gagal memproses tap, kirim ulang nanti")
+		}
+		result.Success = false
+		result.ErrorCode = code
+		if result.Type == "" {
+			result.Type = "rejected"
+		}
+		if result.Message == "" {
+			result.Message = err.Error()
+		}
+	}
+
+	stored, err := json.Marshal(result)
+	if err == nil {
+		err = s.repo.CompleteSyncReceipt(ctx, receipt.ID, string(stored))
+	}
+	if err != nil {
+		// The tap is recorded; a resend will be rejected as a duplicate attendance instead
+		log.Printf("RFID batch sync: failed to store result of tap %s: %v", tap.IdempotencyKey, err)
+	}
+
+	return result
+}
+
+// rejectTap marks a tap as permanently rejected; the device may drop it
+func rejectTap(result RFIDBatchTapResult, code, message string) RFIDBatchTapResult {
+	result.Success = false
+	result.Type = "rejected"
+	result.ErrorCode = code
+	result.Message = message
+	return result
+}
+
+// retryTap marks a tap as failed; the device must keep it queued and resend it
+func retryTap(result RFIDBatchTapResult, message string) RFIDBatchTapResult {
+	result.Success = false
+	result.ErrorCode = "SYNC_RETRY"
+	result.Message = message
+	result.Retryable = true
+	return result
+}
+
+// tapErrorCode maps a tap rejection to its error code
+// It reports false for unexpected errors, which are retried instead of rejected
+func tapErrorCode(err error) (string, bool) {
+	switch {
+	case errors.Is(err, ErrInvalidRFIDCode), errors.Is(err, ErrStudentNotFound):
+		return "VAL_INVALID_RFID", true
+	case errors.Is(err, ErrOutsideAttendanceWindow):
+		return "VAL_NO_SCHEDULE", true
+	case errors.Is(err, ErrAlreadyCheckedIn):
+		return "VAL_ALREADY_CHECKED_IN", true
+	case errors.Is(err, ErrAlreadyCheckedOut):
+		return "VAL_ALREADY_CHECKED_OUT", true
+	case errors.Is(err, ErrNoCheckIn):
+		return "VAL_NO_CHECK_IN", true
+	case errors.Is(err, ErrCheckOutBeforeIn):
+		return "VAL_INVALID_TIME", true
+	}
+	return "", false
+}
@@ -0,0 +1,64 @@
+package auditlog
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// ==================== Request DTOs ====================
+
+// AuditLogFilter represents filter options for listing and exporting audit log entries
+type AuditLogFilter struct {
+	SchoolID   *uint // Nil only for a super admin looking at all schools
+	ActorID    *uint
+	ActorRole  string
+	Action     string
+	Module     string
+	EntityType string
+	EntityID   *uint
+	StartDate  string // YYYY-MM-DD
+	EndDate    string // YYYY-MM-DD, inclusive
+	Page       int
+	PageSize   int
+
+	// Set by the service from StartDate and EndDate
+	From *time.Time
+	To   *time.Time
+}
+
+// ==================== Response DTOs ====================
+
+// AuditLogResponse represents an audit log entry in API responses
+type AuditLogResponse struct {
+	ID            uint               `json:"id"`
+	SchoolID      *uint              `json:"school_id"`
+	ActorID       uint               `json:"actor_id"`
+	ActorRole     string             `json:"actor_role"`
+	ActorUsername string             `json:"actor_username"`
+	IPAddress     string             `json:"ip_address"`
+	Method        string             `json:"method"`
+	Path          string             `json:"path"`
+	Action        models.AuditAction `json:"action"`
+	Module        string             `json:"module"`
+	EntityType    string             `json:"entity_type"`
+	EntityID      uint               `json:"entity_id"`
+	Before        json.RawMessage    `json:"before"` // Changed columns before the change, null for a create or read
+	After         json.RawMessage    `json:"after"`  // Changed columns after the change, null for a delete or read
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+// AuditLogListResponse represents a paginated list of audit log entries
+type AuditLogListResponse struct {
+	Logs       []AuditLogResponse `json:"logs"`
+	Pagination PaginationMeta     `json:"pagination"`
+}
+
+// PaginationMeta represents pagination metadata
+type PaginationMeta struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
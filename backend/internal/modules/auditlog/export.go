@@ -0,0 +1,176 @@
+package auditlog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// generateAuditLogExcel generates an Excel file from audit log entries
+func generateAuditLogExcel(logs []models.AuditLog) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheetName := "Audit Log"
+	f.SetSheetName("Sheet1", sheetName)
+
+	headers := []string{
+		"No",
+		"Waktu",
+		"Pengguna",
+		"Peran",
+		"Alamat IP",
+		"Aksi",
+		"Modul",
+		"Data",
+		"ID Data",
+		"Sebelum",
+		"Sesudah",
+		"Permintaan",
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{
+			Bold:  true,
+			Color: "#FFFFFF",
+		},
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{"#4472C4"},
+			Pattern: 1,
+		},
+		Alignment: &excelize.Alignment{
+			Horizontal: "center",
+			Vertical:   "center",
+		},
+		Border: []excelize.Border{
+			{Type: "left", Color: "#000000", Style: 1},
+			{Type: "top", Color: "#000000", Style: 1},
+			{Type: "bottom", Color: "#000000", Style: 1},
+			{Type: "right", Color: "#000000", Style: 1},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create header style: %w", err)
+	}
+
+	for i, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheetName, cell, header)
+		f.SetCellStyle(sheetName, cell, cell, headerStyle)
+	}
+
+	columnWidths := map[string]float64{
+		"A": 6,  // No
+		"B": 20, // Waktu
+		"C": 20, // Pengguna
+		"D": 15, // Peran
+		"E": 16, // Alamat IP
+		"F": 10, // Aksi
+		"G": 12, // Modul
+		"H": 22, // Data
+		"I": 10, // ID Data
+		"J": 50, // Sebelum
+		"K": 50, // Sesudah
+		"L": 40, // Permintaan
+	}
+	for col, width := range columnWidths {
+		f.SetColWidth(sheetName, col, col, width)
+	}
+
+	// Before and after values can be long, so they wrap at the top of the cell
+	dataStyle, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{
+			Vertical: "top",
+			WrapText: true,
+		},
+		Border: []excelize.Border{
+			{Type: "left", Color: "#000000", Style: 1},
+			{Type: "top", Color: "#000000", Style: 1},
+			{Type: "bottom", Color: "#000000", Style: 1},
+			{Type: "right", Color: "#000000", Style: 1},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data style: %w", err)
+	}
+
+	for i, entry := range logs {
+		row := i + 2 // Start from row 2 (after header)
+
+		rowData := []interface{}{
+			i + 1,
+			entry.CreatedAt.In(time.Local).Format("2006-01-02 15:04:05"),
+			entry.ActorUsername,
+			entry.ActorRole,
+			entry.IPAddress,
+			translateAction(entry.Action),
+			entry.Module,
+			entry.EntityType,
+			entry.EntityID,
+			valueOrEmpty(entry.Before),
+			valueOrEmpty(entry.After),
+			entry.Method + " " + entry.Path,
+		}
+
+		for j, value := range rowData {
+			cell, _ := excelize.CoordinatesToCellName(j+1, row)
+			f.SetCellValue(sheetName, cell, value)
+			f.SetCellStyle(sheetName, cell, cell, dataStyle)
+		}
+	}
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write Excel to buffer: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// generateExportFilename generates the filename of an export for its date range
+func generateExportFilename(startDate, endDate string) string {
+	switch {
+	case startDate != "" && endDate != "":
+		return fmt.Sprintf("audit_log_%s_to_%s.xlsx", startDate, endDate)
+	case startDate != "":
+		return fmt.Sprintf("audit_log_from_%s.xlsx", startDate)
+	case endDate != "":
+		return fmt.Sprintf("audit_log_until_%s.xlsx", endDate)
+	}
+	return fmt.Sprintf("audit_log_%s.xlsx", time.Now().Format("2006-01-02"))
+}
+
+// translateAction translates an audit action to Indonesian
+func translateAction(action models.AuditAction) string {
+	switch action {
+	case models.AuditActionCreate:
+		return "Tambah"
+	case models.AuditActionUpdate:
+		return "Ubah"
+	case models.AuditActionDelete:
+		return "Hapus"
+	case models.AuditActionRead:
+		return "Lihat"
+	case models.AuditActionLock:
+		return "Kunci Login"
+	case models.AuditActionUnlock:
+		return "Buka Kunci Login"
+	}
+	return string(action)
+}
+
+// valueOrEmpty returns the JSON of a before or after value, cut to what a cell holds, or an empty cell
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	if len(*value) > excelize.TotalCellChars {
+		return strings.ToValidUTF8((*value)[:excelize.TotalCellChars], "")
+	}
+	return *value
+}
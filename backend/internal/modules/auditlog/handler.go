@@ -0,0 +1,268 @@
+package auditlog
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/middleware"
+)
+
+// Handler handles HTTP requests for the audit trail
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new audit log handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the audit log routes
+// Admin sekolah see the entries of their school; super admins see all schools.
+func (h *Handler) RegisterRoutes(router fiber.Router) {
+	logs := router.Group("/audit-logs", middleware.AdminOrSuperAdmin())
+	logs.Get("", h.GetAuditLogs)
+	logs.Get("/export", h.ExportAuditLogs)
+	logs.Get("/:id", h.GetAuditLog)
+}
+
+// GetAuditLogs handles listing audit log entries
+// @Summary List audit log entries
+// @Description List who changed school data, newest first. Reads of internal counseling notes are listed with action "read".
+// @Tags Audit Log
+// @Produce json
+// @Param school_id query int false "Filter by school ID (super admin only)"
+// @Param actor_id query int false "Filter by user ID of the actor"
+// @Param actor_role query string false "Filter by role of the actor"
+// @Param action query string false "Filter by action (create, update, delete, read)"
+// @Param module query string false "Filter by module (school, bk, grade, homeroom, attendance, device, settings)"
+// @Param entity_type query string false "Filter by table, e.g. attendances"
+// @Param entity_id query int false "Filter by record ID"
+// @Param start_date query string false "From date (YYYY-MM-DD)"
+// @Param end_date query string false "Until date, inclusive (YYYY-MM-DD)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} AuditLogListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/audit-logs [get]
+func (h *Handler) GetAuditLogs(c *fiber.Ctx) error {
+	filter, ok := h.parseFilter(c)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+	filter.Page, _ = strconv.Atoi(c.Query("page", "1"))
+	filter.PageSize, _ = strconv.Atoi(c.Query("page_size", "20"))
+
+	response, err := h.service.GetAuditLogs(c.Context(), filter)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetAuditLog handles getting a single audit log entry
+// @Summary Get audit log entry
+// @Description Get an audit log entry with the values before and after the change
+// @Tags Audit Log
+// @Produce json
+// @Param id path int true "Audit log entry ID"
+// @Success 200 {object} AuditLogResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/audit-logs/{id} [get]
+func (h *Handler) GetAuditLog(c *fiber.Ctx) error {
+	schoolID, ok := h.schoolScope(c)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_ID",
+				"message": "ID catatan audit tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.GetAuditLog(c.Context(), schoolID, uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// ExportAuditLogs handles exporting audit log entries to Excel
+// @Summary Export audit log to Excel
+// @Description Export the audit log entries matching the filters, oldest first, to an Excel file
+// @Tags Audit Log
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param school_id query int false "Filter by school ID (super admin only)"
+// @Param actor_id query int false "Filter by user ID of the actor"
+// @Param actor_role query string false "Filter by role of the actor"
+// @Param action query string false "Filter by action (create, update, delete, read)"
+// @Param module query string false "Filter by module"
+// @Param entity_type query string false "Filter by table"
+// @Param entity_id query int false "Filter by record ID"
+// @Param start_date query string false "From date (YYYY-MM-DD)"
+// @Param end_date query string false "Until date, inclusive (YYYY-MM-DD)"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/audit-logs/export [get]
+func (h *Handler) ExportAuditLogs(c *fiber.Ctx) error {
+	filter, ok := h.parseFilter(c)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	excelData, filename, err := h.service.ExportAuditLogs(c.Context(), filter)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Set("Content-Disposition", "attachment; filename="+filename)
+	c.Set("Content-Length", strconv.Itoa(len(excelData)))
+
+	return c.Send(excelData)
+}
+
+// parseFilter reads the filter query parameters within the school scope of the user
+func (h *Handler) parseFilter(c *fiber.Ctx) (AuditLogFilter, bool) {
+	schoolID, ok := h.schoolScope(c)
+	if !ok {
+		return AuditLogFilter{}, false
+	}
+
+	filter := AuditLogFilter{
+		SchoolID:   schoolID,
+		ActorRole:  c.Query("actor_role"),
+		Action:     c.Query("action"),
+		Module:     c.Query("module"),
+		EntityType: c.Query("entity_type"),
+		StartDate:  c.Query("start_date"),
+		EndDate:    c.Query("end_date"),
+	}
+	if actorID, err := strconv.ParseUint(c.Query("actor_id"), 10, 32); err == nil {
+		id := uint(actorID)
+		filter.ActorID = &id
+	}
+	if entityID, err := strconv.ParseUint(c.Query("entity_id"), 10, 32); err == nil {
+		id := uint(entityID)
+		filter.EntityID = &id
+	}
+
+	return filter, true
+}
+
+// schoolScope returns the school whose entries the user may see: their own school for an
+// admin sekolah, and the optional school_id query parameter for a super admin (nil for all schools)
+func (h *Handler) schoolScope(c *fiber.Ctx) (*uint, bool) {
+	if middleware.IsSuperAdmin(c) {
+		if schoolID, err := strconv.ParseUint(c.Query("school_id"), 10, 32); err == nil {
+			id := uint(schoolID)
+			return &id, true
+		}
+		return nil, true
+	}
+
+	schoolID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return nil, false
+	}
+	return &schoolID, true
+}
+
+func (h *Handler) tenantRequiredError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "AUTHZ_TENANT_REQUIRED",
+			"message": "Konteks sekolah diperlukan",
+		},
+	})
+}
+
+// handleError handles service errors and returns appropriate HTTP responses
+func (h *Handler) handleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrAuditLogNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_AUDIT_LOG",
+				"message": "Catatan audit tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrInvalidDate):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format tanggal harus YYYY-MM-DD",
+			},
+		})
+	case errors.Is(err, ErrDateOrder):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_DATE_RANGE",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrInvalidAction), errors.Is(err, ErrInvalidModule):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_VALUE",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrExportTooLarge):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "EXPORT_TOO_LARGE",
+				"message": err.Error(),
+			},
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "INTERNAL_ERROR",
+				"message": "Terjadi kesalahan pada server",
+			},
+		})
+	}
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Success bool `json:"success"`
+	Error   struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
@@ -0,0 +1,121 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+var (
+	ErrAuditLogNotFound = errors.New("catatan audit tidak ditemukan")
+)
+
+// Repository defines data operations for the audit trail.
+// Entries are written by the audit plugin; the trail is append-only, so there is no update or delete.
+type Repository interface {
+	FindAll(ctx context.Context, filter AuditLogFilter) ([]models.AuditLog, int64, error)
+	FindByID(ctx context.Context, schoolID *uint, id uint) (*models.AuditLog, error)
+	Count(ctx context.Context, filter AuditLogFilter) (int64, error)
+	FindForExport(ctx context.Context, filter AuditLogFilter) ([]models.AuditLog, error)
+}
+
+// repository implements the Repository interface
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new audit log repository
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// FindAll retrieves a page of entries, newest first
+func (r *repository) FindAll(ctx context.Context, filter AuditLogFilter) ([]models.AuditLog, int64, error) {
+	var logs []models.AuditLog
+	var total int64
+
+	query := r.filtered(ctx, filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC, id DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&logs).Error
+
+	return logs, total, err
+}
+
+// FindByID retrieves an entry, within a school unless schoolID is nil
+func (r *repository) FindByID(ctx context.Context, schoolID *uint, id uint) (*models.AuditLog, error) {
+	var entry models.AuditLog
+	query := r.db.WithContext(ctx).Where("id = ?", id)
+	if schoolID != nil {
+		query = query.Where("school_id = ?", *schoolID)
+	}
+
+	if err := query.First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAuditLogNotFound
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Count counts the entries matching a filter
+func (r *repository) Count(ctx context.Context, filter AuditLogFilter) (int64, error) {
+	var total int64
+	err := r.filtered(ctx, filter).Count(&total).Error
+	return total, err
+}
+
+// FindForExport retrieves all entries matching a filter, oldest first
+func (r *repository) FindForExport(ctx context.Context, filter AuditLogFilter) ([]models.AuditLog, error) {
+	var logs []models.AuditLog
+	err := r.filtered(ctx, filter).
+		Order("created_at ASC, id ASC").
+		Find(&logs).Error
+	return logs, err
+}
+
+// filtered returns a query for the entries matching a filter
+func (r *repository) filtered(ctx context.Context, filter AuditLogFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.AuditLog{})
+
+	if filter.SchoolID != nil {
+		query = query.Where("school_id = ?", *filter.SchoolID)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.ActorRole != "" {
+		query = query.Where("actor_role = ?", filter.ActorRole)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Module != "" {
+		query = query.Where("module = ?", filter.Module)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	return query
+}
@@ -0,0 +1,175 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/audit"
+)
+
+// MaxExportRows is the largest number of entries exported to a single Excel file
+const MaxExportRows = 10000
+
+var (
+	ErrInvalidDate    = errors.New("format tanggal harus YYYY-MM-DD")
+	ErrDateOrder      = errors.New("tanggal selesai tidak boleh sebelum tanggal mulai")
+	ErrInvalidAction  = errors.New("aksi harus create, update, delete atau read")
+	ErrInvalidModule  = errors.New("modul harus school, bk, grade, homeroom, attendance, device atau settings")
+	ErrExportTooLarge = fmt.Errorf("maksimal %d catatan per ekspor, persempit filter atau rentang tanggal", MaxExportRows)
+)
+
+// Service defines the business logic for querying the audit trail
+type Service interface {
+	GetAuditLogs(ctx context.Context, filter AuditLogFilter) (*AuditLogListResponse, error)
+	GetAuditLog(ctx context.Context, schoolID *uint, id uint) (*AuditLogResponse, error)
+	ExportAuditLogs(ctx context.Context, filter AuditLogFilter) ([]byte, string, error)
+}
+
+// service implements the Service interface
+type service struct {
+	repo Repository
+}
+
+// NewService creates a new audit log service
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+// GetAuditLogs retrieves a page of entries matching a filter
+func (s *service) GetAuditLogs(ctx context.Context, filter AuditLogFilter) (*AuditLogListResponse, error) {
+	if err := validateFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	logs, total, err := s.repo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]AuditLogResponse, len(logs))
+	for i := range logs {
+		responses[i] = *toAuditLogResponse(&logs[i])
+	}
+
+	totalPages := int(total) / filter.PageSize
+	if int(total)%filter.PageSize > 0 {
+		totalPages++
+	}
+
+	return &AuditLogListResponse{
+		Logs: responses,
+		Pagination: PaginationMeta{
+			Page:       filter.Page,
+			PageSize:   filter.PageSize,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+// GetAuditLog retrieves a single entry
+func (s *service) GetAuditLog(ctx context.Context, schoolID *uint, id uint) (*AuditLogResponse, error) {
+	entry, err := s.repo.FindByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toAuditLogResponse(entry), nil
+}
+
+// ExportAuditLogs exports the entries matching a filter to an Excel file
+func (s *service) ExportAuditLogs(ctx context.Context, filter AuditLogFilter) ([]byte, string, error) {
+	if err := validateFilter(&filter); err != nil {
+		return nil, "", err
+	}
+
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	if total > MaxExportRows {
+		return nil, "", ErrExportTooLarge
+	}
+
+	logs, err := s.repo.FindForExport(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := generateAuditLogExcel(logs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, generateExportFilename(filter.StartDate, filter.EndDate), nil
+}
+
+// validateFilter checks the filter values and sets the time range from the dates
+func validateFilter(filter *AuditLogFilter) error {
+	if filter.Action != "" && !models.AuditAction(filter.Action).IsValid() {
+		return ErrInvalidAction
+	}
+	if filter.Module != "" && !audit.IsModule(filter.Module) {
+		return ErrInvalidModule
+	}
+
+	if filter.StartDate != "" {
+		from, err := time.ParseInLocation("2006-01-02", filter.StartDate, time.Local)
+		if err != nil {
+			return ErrInvalidDate
+		}
+		filter.From = &from
+	}
+	if filter.EndDate != "" {
+		end, err := time.ParseInLocation("2006-01-02", filter.EndDate, time.Local)
+		if err != nil {
+			return ErrInvalidDate
+		}
+		to := end.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return ErrDateOrder
+	}
+
+	return nil
+}
+
+// toAuditLogResponse converts an entry to its response
+func toAuditLogResponse(entry *models.AuditLog) *AuditLogResponse {
+	response := &AuditLogResponse{
+		ID:            entry.ID,
+		SchoolID:      entry.SchoolID,
+		ActorID:       entry.ActorID,
+		ActorRole:     entry.ActorRole,
+		ActorUsername: entry.ActorUsername,
+		IPAddress:     entry.IPAddress,
+		Method:        entry.Method,
+		Path:          entry.Path,
+		Action:        entry.Action,
+		Module:        entry.Module,
+		EntityType:    entry.EntityType,
+		EntityID:      entry.EntityID,
+		CreatedAt:     entry.CreatedAt,
+	}
+	if entry.Before != nil {
+		response.Before = json.RawMessage(*entry.Before)
+	}
+	if entry.After != nil {
+		response.After = json.RawMessage(*entry.After)
+	}
+	return response
+}
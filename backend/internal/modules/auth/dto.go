@@ -4,8 +4,9 @@ import "time"
 
 // LoginRequest represents the login request payload
 type LoginRequest struct {
-	Username string `json:"username" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Username   string `json:"username" validate:"required"`
+	Password   string `json:"password" validate:"required"`
+	DeviceName string `json:"device_name"` // Shown in the list of active sessions, e.g. "Samsung A14"
 }
 
 // LoginResponse represents the login response payload
@@ -15,6 +16,62 @@ type LoginResponse struct {
 	ExpiresIn    int64        `json:"expires_in"` // seconds until access token expires
 	TokenType    string       `json:"token_type"`
 	User         UserResponse `json:"user"`
+	// Shown once when 2FA enrollment was finished during this login
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+	// Set instead of the tokens when the password was right but a second factor is needed.
+	// The handler answers with the challenge alone.
+	TwoFactor *TwoFactorChallengeResponse `json:"-"`
+}
+
+// TwoFactorChallengeResponse asks for the authenticator code after a correct password
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired  bool   `json:"two_factor_required"`
+	ChallengeToken     string `json:"challenge_token"`
+	ExpiresIn          int64  `json:"expires_in"`          // seconds until the challenge token expires
+	EnrollmentRequired bool   `json:"enrollment_required"` // 2FA is mandatory but no authenticator app is set up yet
+}
+
+// TwoFactorVerifyRequest finishes a login with an authenticator or recovery code
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"` // 6-digit authenticator code or a recovery code
+	DeviceName     string `json:"device_name"`
+}
+
+// TwoFactorEnrollRequest starts enrollment during a login that requires 2FA
+type TwoFactorEnrollRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// TwoFactorCodeRequest carries an authenticator code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// DisableTwoFactorRequest represents the request to turn 2FA off
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"` // Authenticator or recovery code
+}
+
+// TwoFactorSetupResponse holds the secret to add to an authenticator app
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`      // For manual entry
+	OTPAuthURI string `json:"otpauth_uri"` // For a QR code
+}
+
+// TwoFactorStatusResponse represents the 2FA state of the current user
+type TwoFactorStatusResponse struct {
+	Available         bool       `json:"available"` // The user's role can use 2FA
+	Enabled           bool       `json:"enabled"`
+	Required          bool       `json:"required"` // Required for the role by the school or platform
+	EnabledAt         *time.Time `json:"enabled_at"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`
+}
+
+// RecoveryCodesResponse holds newly generated recovery codes; they are shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 // RefreshTokenRequest represents the refresh token request payload
@@ -30,6 +87,32 @@ type RefreshTokenResponse struct {
 	TokenType    string `json:"token_type"`
 }
 
+// ForgotPasswordRequest represents a request for a password reset code
+type ForgotPasswordRequest struct {
+	Username string `json:"username" validate:"required"`
+	Channel  string `json:"channel"` // "email" or "whatsapp"; empty picks what the account has
+}
+
+// VerifyResetCodeRequest represents the verification of a password reset code
+type VerifyResetCodeRequest struct {
+	Username   string `json:"username" validate:"required"`
+	Code       string `json:"code" validate:"required"`
+	DeviceName string `json:"device_name"`
+}
+
+// ChangePasswordResponse represents the change password response payload
+// A session started with a reset code gets an access token without the restriction.
+type ChangePasswordResponse struct {
+	AccessToken string `json:"access_token,omitempty"`
+	ExpiresIn   int64  `json:"expires_in,omitempty"`
+	TokenType   string `json:"token_type,omitempty"`
+}
+
+// LogoutRequest represents the logout request payload
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"` // Ends the session of this token
+}
+
 // ChangePasswordRequest represents the change password request payload
 // Requirements: 12.5 - System SHALL enforce password reset on first login
 type ChangePasswordRequest struct {
@@ -59,9 +142,32 @@ type TokenPair struct {
 
 // TokenClaims represents the JWT token claims
 type TokenClaims struct {
-	UserID   uint   `json:"user_id"`
-	SchoolID *uint  `json:"school_id"`
-	Role     string `json:"role"`
-	Username string `json:"username"`
-	Type     string `json:"type"` // "access" or "refresh"
+	UserID    uint   `json:"user_id"`
+	SchoolID  *uint  `json:"school_id"`
+	Role      string `json:"role"`
+	Username  string `json:"username"`
+	SessionID uint   `json:"sid"`
+	TokenID   string `json:"jti,omitempty"` // Refresh tokens only
+	Type      string `json:"type"`          // "access", "refresh" or "2fa_challenge"
+	// Signed in with a password reset code and has to set a new password first
+	ResetRequired bool `json:"reset_required,omitempty"`
+}
+
+// DeviceInfo describes the device a session is used from
+type DeviceInfo struct {
+	Name      string
+	UserAgent string
+	IPAddress string
+}
+
+// SessionResponse represents an active session in responses
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	IsCurrent  bool      `json:"is_current"` // The session of the token making the request
 }
@@ -2,8 +2,12 @@ package auth
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/shared/ratelimit"
 )
 
 // Handler handles HTTP requests for authentication
@@ -22,6 +26,10 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 	auth.Post("/login", h.Login)
 	auth.Post("/refresh", h.RefreshToken)
 	auth.Post("/logout", h.Logout)
+	auth.Post("/forgot-password", h.ForgotPassword)
+	auth.Post("/forgot-password/verify", h.VerifyResetCode)
+	auth.Post("/2fa/verify", h.VerifyTwoFactor)
+	auth.Post("/2fa/enroll", h.EnrollTwoFactor)
 }
 
 // RegisterProtectedRoutes registers routes that require authentication
@@ -29,11 +37,19 @@ func (h *Handler) RegisterProtectedRoutes(router fiber.Router) {
 	auth := router.Group("/auth")
 	auth.Post("/change-password", h.ChangePassword)
 	auth.Get("/me", h.GetCurrentUser)
+	auth.Get("/sessions", h.GetSessions)
+	auth.Delete("/sessions", h.RevokeOtherSessions)
+	auth.Delete("/sessions/:id", h.RevokeSession)
+	auth.Get("/2fa", h.GetTwoFactorStatus)
+	auth.Post("/2fa/setup", h.SetupTwoFactor)
+	auth.Post("/2fa/confirm", h.ConfirmTwoFactor)
+	auth.Post("/2fa/recovery-codes", h.RegenerateRecoveryCodes)
+	auth.Post("/2fa/disable", h.DisableTwoFactor)
 }
 
 // Login handles user login
 // @Summary User login
-// @Description Authenticate user and return JWT tokens
+// @Description Authenticate user and return JWT tokens. Staff with two-factor authentication get a challenge token instead, to finish at /auth/2fa/verify.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -41,6 +57,7 @@ func (h *Handler) RegisterProtectedRoutes(router fiber.Router) {
 // @Success 200 {object} LoginResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many failed logins, see Retry-After"
 // @Router /api/v1/auth/login [post]
 func (h *Handler) Login(c *fiber.Ctx) error {
 	var req LoginRequest
@@ -66,10 +83,17 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 	}
 
 	// Authenticate user
-	response, err := h.service.Authenticate(c.Context(), req.Username, req.Password)
+	response, err := h.service.Authenticate(c.Context(), req.Username, req.Password, DeviceInfo{
+		Name:      req.DeviceName,
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	})
 	if err != nil {
 		return h.handleAuthError(c, err)
 	}
+	if response.TwoFactor != nil {
+		return h.twoFactorChallenge(c, response.TwoFactor)
+	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -79,7 +103,7 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 
 // RefreshToken handles token refresh
 // @Summary Refresh access token
-// @Description Refresh access token using refresh token
+// @Description Refresh access token using refresh token. The refresh token is rotated; presenting an already used one ends its session.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -110,7 +134,10 @@ func (h *Handler) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.service.RefreshAccessToken(c.Context(), req.RefreshToken)
+	response, err := h.service.RefreshAccessToken(c.Context(), req.RefreshToken, DeviceInfo{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	})
 	if err != nil {
 		return h.handleAuthError(c, err)
 	}
@@ -123,14 +150,22 @@ func (h *Handler) RefreshToken(c *fiber.Ctx) error {
 
 // Logout handles user logout
 // @Summary User logout
-// @Description Logout user (client should discard tokens)
+// @Description Logout user by ending the session of the refresh token. Access tokens stay valid until they expire, so clients should still discard them.
 // @Tags Auth
+// @Accept json
 // @Produce json
+// @Param request body LogoutRequest false "Refresh token of the session to end"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/auth/logout [post]
 func (h *Handler) Logout(c *fiber.Ctx) error {
-	// For JWT-based auth, logout is handled client-side by discarding tokens
-	// Server-side token revocation can be implemented using Redis blacklist if needed
+	// The body is optional; older clients log out without sending their refresh token
+	var req LogoutRequest
+	_ = c.BodyParser(&req)
+
+	if err := h.service.Logout(c.Context(), req.RefreshToken); err != nil {
+		return h.handleAuthError(c, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Berhasil keluar",
@@ -139,12 +174,13 @@ func (h *Handler) Logout(c *fiber.Ctx) error {
 
 // ChangePassword handles password change
 // @Summary Change password
-// @Description Change user password (requires authentication)
+// @Description Change user password (requires authentication). Other sessions of the user are ended.
+// @Description A session started with a password reset code needs no old password and gets a new access token.
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param request body ChangePasswordRequest true "Password change request"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} ChangePasswordResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Security BearerAuth
@@ -173,7 +209,11 @@ func (h *Handler) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
-	if req.OldPassword == "" || req.NewPassword == "" {
+	// After a password reset code the old password is not known
+	claims, _ := c.Locals("claims").(*TokenClaims)
+	resetRequired := claims != nil && claims.ResetRequired
+
+	if (req.OldPassword == "" && !resetRequired) || req.NewPassword == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
@@ -193,13 +233,14 @@ func (h *Handler) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
-	err := h.service.ChangePassword(c.Context(), userID, req.OldPassword, req.NewPassword)
+	response, err := h.service.ChangePassword(c.Context(), userID, currentSessionID(c), req.OldPassword, req.NewPassword)
 	if err != nil {
 		return h.handleAuthError(c, err)
 	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
+		"data":    response,
 		"message": "Password berhasil diubah",
 	})
 }
@@ -260,7 +301,29 @@ func (h *Handler) GetCurrentUser(c *fiber.Ctx) error {
 
 // handleAuthError handles authentication errors and returns appropriate responses
 func (h *Handler) handleAuthError(c *fiber.Ctx, err error) error {
+	var blocked *ratelimit.BlockedError
 	switch {
+	case errors.As(err, &blocked):
+		retryAfter := blocked.RetryAfterSeconds()
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		if blocked.Locked {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":        "AUTH_ACCOUNT_LOCKED",
+					"message":     fmt.Sprintf("Terlalu banyak percobaan masuk yang gagal. Coba lagi dalam %d menit", (retryAfter+59)/60),
+					"retry_after": retryAfter,
+				},
+			})
+		}
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":        "AUTH_TOO_MANY_ATTEMPTS",
+				"message":     fmt.Sprintf("Terlalu banyak percobaan masuk. Coba lagi dalam %d detik", retryAfter),
+				"retry_after": retryAfter,
+			},
+		})
 	case errors.Is(err, ErrInvalidCredentials):
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -325,6 +388,94 @@ func (h *Handler) handleAuthError(c *fiber.Ctx, err error) error {
 				"message": "Password minimal 8 karakter",
 			},
 		})
+	case errors.Is(err, ErrSessionRevoked):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_SESSION_REVOKED",
+				"message": "Sesi sudah berakhir, silakan masuk kembali",
+			},
+		})
+	case errors.Is(err, ErrTokenReused):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_TOKEN_REUSED",
+				"message": "Refresh token sudah pernah digunakan, silakan masuk kembali",
+			},
+		})
+	case errors.Is(err, ErrSessionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_SESSION",
+				"message": "Sesi tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrInvalidResetCode):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_INVALID_RESET_CODE",
+				"message": "Kode reset password salah atau sudah tidak berlaku",
+			},
+		})
+	case errors.Is(err, ErrResetChannelUnavailable):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_CHANNEL",
+				"message": "Pengiriman kode lewat saluran ini belum tersedia",
+			},
+		})
+	case errors.Is(err, ErrInvalidTwoFactorCode):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_INVALID_2FA_CODE",
+				"message": "Kode autentikasi salah",
+			},
+		})
+	case errors.Is(err, ErrTwoFactorNotAvailable):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_2FA_NOT_AVAILABLE",
+				"message": "Autentikasi dua langkah tidak tersedia untuk peran ini",
+			},
+		})
+	case errors.Is(err, ErrTwoFactorMandatory):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_2FA_MANDATORY",
+				"message": "Autentikasi dua langkah wajib untuk peran ini dan tidak dapat dinonaktifkan",
+			},
+		})
+	case errors.Is(err, ErrTwoFactorAlreadyEnabled):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_2FA_ALREADY_ENABLED",
+				"message": "Autentikasi dua langkah sudah aktif",
+			},
+		})
+	case errors.Is(err, ErrTwoFactorNotEnabled):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_2FA_NOT_ENABLED",
+				"message": "Autentikasi dua langkah belum aktif",
+			},
+		})
+	case errors.Is(err, ErrTwoFactorNotFound):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_2FA_NOT_SET_UP",
+				"message": "Autentikasi dua langkah belum disiapkan",
+			},
+		})
 	case errors.Is(err, ErrUserNotFound):
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
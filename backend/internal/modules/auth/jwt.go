@@ -10,6 +10,9 @@ import (
 	"github.com/school-management/backend/internal/config"
 )
 
+// tokenTypeChallenge is the type of tokens issued between the password and the second factor
+const tokenTypeChallenge = "2fa_challenge"
+
 var (
 	ErrTokenExpired   = errors.New("token has expired")
 	ErrTokenInvalid   = errors.New("token is invalid")
@@ -18,19 +21,23 @@ var (
 
 // JWTManager handles JWT token operations
 type JWTManager struct {
-	secretKey            []byte
-	accessTokenDuration  time.Duration
-	refreshTokenDuration time.Duration
-	issuer               string
+	secretKey              []byte
+	accessTokenDuration    time.Duration
+	refreshTokenDuration   time.Duration
+	challengeTokenDuration time.Duration
+	issuer                 string
 }
 
 // jwtClaims represents the JWT claims structure
 type jwtClaims struct {
-	UserID   uint    `json:"user_id"`
-	SchoolID *uint   `json:"school_id"`
-	Role     string  `json:"role"`
-	Username string  `json:"username"`
-	Type     string  `json:"type"` // "access" or "refresh"
+	UserID    uint   `json:"user_id"`
+	SchoolID  *uint  `json:"school_id"`
+	Role      string `json:"role"`
+	Username  string `json:"username"`
+	SessionID uint   `json:"sid,omitempty"` // Server-side session, see models.UserSession
+	Type      string `json:"type"`          // "access", "refresh" or "2fa_challenge"
+	// Signed in with a password reset code; only /auth routes are allowed until a new password is set
+	ResetRequired bool `json:"reset_required,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -72,10 +79,11 @@ func (c *jwtClaims) UnmarshalJSON(data []byte) error {
 // NewJWTManager creates a new JWT manager
 func NewJWTManager(cfg config.JWTConfig) *JWTManager {
 	return &JWTManager{
-		secretKey:            []byte(cfg.SecretKey),
-		accessTokenDuration:  time.Duration(cfg.AccessTokenDuration) * time.Minute,
-		refreshTokenDuration: time.Duration(cfg.RefreshTokenDuration) * time.Hour,
-		issuer:               cfg.Issuer,
+		secretKey:              []byte(cfg.SecretKey),
+		accessTokenDuration:    time.Duration(cfg.AccessTokenDuration) * time.Minute,
+		refreshTokenDuration:   time.Duration(cfg.RefreshTokenDuration) * time.Hour,
+		challengeTokenDuration: time.Duration(cfg.ChallengeDuration) * time.Minute,
+		issuer:                 cfg.Issuer,
 	}
 }
 
@@ -99,17 +107,30 @@ func (m *JWTManager) GenerateTokenPair(claims TokenClaims) (*TokenPair, error) {
 	}, nil
 }
 
+// GenerateAccessToken generates an access token alone, e.g. after the claims of a session changed
+func (m *JWTManager) GenerateAccessToken(claims TokenClaims) (string, error) {
+	return m.generateToken(claims, "access", m.accessTokenDuration)
+}
+
+// GenerateChallengeToken generates a short-lived token proving that the password was correct
+// It only works to finish a login with a second factor, never as an access token.
+func (m *JWTManager) GenerateChallengeToken(claims TokenClaims) (string, error) {
+	return m.generateToken(claims, tokenTypeChallenge, m.challengeTokenDuration)
+}
+
 // generateToken generates a single JWT token
 func (m *JWTManager) generateToken(claims TokenClaims, tokenType string, duration time.Duration) (string, error) {
 	now := time.Now()
 	expiresAt := now.Add(duration)
 
 	jwtClaims := jwtClaims{
-		UserID:   claims.UserID,
-		SchoolID: claims.SchoolID,
-		Role:     claims.Role,
-		Username: claims.Username,
-		Type:     tokenType,
+		UserID:        claims.UserID,
+		SchoolID:      claims.SchoolID,
+		Role:          claims.Role,
+		Username:      claims.Username,
+		SessionID:     claims.SessionID,
+		Type:          tokenType,
+		ResetRequired: claims.ResetRequired,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    m.issuer,
 			Subject:   claims.Username,
@@ -118,6 +139,11 @@ func (m *JWTManager) generateToken(claims TokenClaims, tokenType string, duratio
 		},
 	}
 
+	// The refresh token ID is what the session rotates; access tokens do not need one
+	if tokenType == "refresh" {
+		jwtClaims.ID = claims.TokenID
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtClaims)
 	return token.SignedString(m.secretKey)
 }
@@ -148,11 +174,14 @@ func (m *JWTManager) ValidateToken(tokenString string) (*TokenClaims, error) {
 	}
 
 	return &TokenClaims{
-		UserID:   claims.UserID,
-		SchoolID: claims.SchoolID,
-		Role:     claims.Role,
-		Username: claims.Username,
-		Type:     claims.Type,
+		UserID:        claims.UserID,
+		SchoolID:      claims.SchoolID,
+		Role:          claims.Role,
+		Username:      claims.Username,
+		SessionID:     claims.SessionID,
+		TokenID:       claims.ID,
+		Type:          claims.Type,
+		ResetRequired: claims.ResetRequired,
 	}, nil
 }
 
@@ -184,7 +213,31 @@ func (m *JWTManager) ValidateRefreshToken(tokenString string) (*TokenClaims, err
 	return claims, nil
 }
 
+// ValidateChallengeToken validates a two-factor challenge token
+func (m *JWTManager) ValidateChallengeToken(tokenString string) (*TokenClaims, error) {
+	claims, err := m.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Type != tokenTypeChallenge {
+		return nil, ErrTokenInvalid
+	}
+
+	return claims, nil
+}
+
 // GetAccessTokenDuration returns the access token duration in seconds
 func (m *JWTManager) GetAccessTokenDuration() int64 {
 	return int64(m.accessTokenDuration.Seconds())
 }
+
+// GetRefreshTokenDuration returns how long a refresh token, and so an idle session, stays valid
+func (m *JWTManager) GetRefreshTokenDuration() time.Duration {
+	return m.refreshTokenDuration
+}
+
+// GetChallengeTokenDuration returns the challenge token duration in seconds
+func (m *JWTManager) GetChallengeTokenDuration() int64 {
+	return int64(m.challengeTokenDuration.Seconds())
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/shared/messaging"
+)
+
+// ForgotPassword handles requests for a password reset code
+// @Summary Request password reset code
+// @Description Send a one-time code to the email address or WhatsApp number of the account. The answer is the same whether or not the account exists.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Username and optional channel"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many codes requested, see Retry-After"
+// @Router /api/v1/auth/forgot-password [post]
+func (h *Handler) ForgotPassword(c *fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	if strings.TrimSpace(req.Username) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Username wajib diisi",
+			},
+		})
+	}
+
+	channel := messaging.Channel(strings.ToLower(strings.TrimSpace(req.Channel)))
+	if err := h.service.RequestPasswordReset(c.Context(), req.Username, channel, c.IP()); err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Jika akun terdaftar, kode reset password telah dikirim ke email atau WhatsApp akun tersebut",
+	})
+}
+
+// VerifyResetCode handles the verification of a password reset code
+// @Summary Verify password reset code
+// @Description Sign in with a password reset code. The session can only use the auth routes until a new password is set through change-password, which then needs no old password. Staff with two-factor authentication get a challenge token first.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body VerifyResetCodeRequest true "Username and code"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many wrong codes, see Retry-After"
+// @Router /api/v1/auth/forgot-password/verify [post]
+func (h *Handler) VerifyResetCode(c *fiber.Ctx) error {
+	var req VerifyResetCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	if strings.TrimSpace(req.Username) == "" || strings.TrimSpace(req.Code) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Username dan kode wajib diisi",
+			},
+		})
+	}
+
+	response, err := h.service.VerifyPasswordReset(c.Context(), req.Username, req.Code, DeviceInfo{
+		Name:      req.DeviceName,
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	})
+	if err != nil {
+		return h.handleAuthError(c, err)
+	}
+	if response.TwoFactor != nil {
+		return h.twoFactorChallenge(c, response.TwoFactor)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Kode terverifikasi, silakan buat password baru",
+	})
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/session"
+)
+
+var (
+	ErrResetCodeNotFound = errors.New("kode reset password tidak ditemukan")
+)
+
+// FindParentPhone finds the phone number of the parent account of a user
+// It returns an empty string for users that are not parents.
+func (r *repository) FindParentPhone(ctx context.Context, userID uint) (string, error) {
+	var parent models.Parent
+	err := r.db.WithContext(ctx).
+		Select("phone").
+		Where("user_id = ?", userID).
+		First(&parent).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return parent.Phone, nil
+}
+
+// CreateResetCode creates a password reset code and retires the user's earlier codes
+func (r *repository) CreateResetCode(ctx context.Context, code *models.PasswordResetCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PasswordResetCode{}).
+			Where("user_id = ? AND used_at IS NULL", code.UserID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(code).Error
+	})
+}
+
+// FindLatestResetCode finds the most recent unused password reset code of a user
+func (r *repository) FindLatestResetCode(ctx context.Context, userID uint) (*models.PasswordResetCode, error) {
+	var code models.PasswordResetCode
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Order("created_at DESC").
+		First(&code).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResetCodeNotFound
+		}
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+// IncrementResetCodeAttempts records a wrong guess of a password reset code
+func (r *repository) IncrementResetCodeAttempts(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.PasswordResetCode{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// ConsumeResetCode marks a password reset code used, requires the user to set a new password
+// and ends the user's sessions, all at once.
+// It returns false when the code was already used by a concurrent request.
+func (r *repository) ConsumeResetCode(ctx context.Context, id uint, userID uint) (bool, error) {
+	consumed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PasswordResetCode{}).
+			Where("id = ? AND used_at IS NULL", id).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Update("must_reset_pwd", true).Error; err != nil {
+			return err
+		}
+
+		if _, err := session.RevokeUser(ctx, tx, userID, models.SessionRevokedPasswordReset); err != nil {
+			return err
+		}
+
+		consumed = true
+		return nil
+	})
+
+	return consumed, err
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/messaging"
+)
+
+var (
+	ErrInvalidResetCode        = errors.New("kode reset password salah atau sudah tidak berlaku")
+	ErrResetChannelUnavailable = errors.New("pengiriman kode lewat saluran ini belum tersedia")
+)
+
+// resetCodeDigits is the length of a password reset code
+const resetCodeDigits = 6
+
+// RequestPasswordReset sends a one-time code to the email address or WhatsApp number of an account.
+// Unknown usernames and accounts without a destination get the same answer as existing ones,
+// so the endpoint does not reveal which accounts exist.
+func (s *service) RequestPasswordReset(ctx context.Context, username string, channel messaging.Channel, ipAddress string) error {
+	if channel != "" && (!channel.IsValid() || !s.sender.Supports(channel)) {
+		return ErrResetChannelUnavailable
+	}
+
+	if err := s.throttle.AllowResetRequest(ctx, username, ipAddress); err != nil {
+		return err
+	}
+
+	user, err := s.repo.FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.IsActive || (user.School != nil && !user.School.IsActive) {
+		return nil
+	}
+
+	channel, destination, err := s.resetDestination(ctx, user, channel)
+	if err != nil {
+		return err
+	}
+	if destination == "" {
+		log.Printf("Password reset: user %d has no email or phone number to send a code to", user.ID)
+		return nil
+	}
+
+	code, err := newResetCode()
+	if err != nil {
+		return err
+	}
+
+	resetCode := &models.PasswordResetCode{
+		UserID:      user.ID,
+		CodeHash:    hashResetCode(user.ID, code),
+		Channel:     string(channel),
+		Destination: destination,
+		ExpiresAt:   time.Now().Add(s.resetCodeTTL),
+	}
+	if err := s.repo.CreateResetCode(ctx, resetCode); err != nil {
+		return err
+	}
+
+	msg := messaging.Message{
+		Channel: channel,
+		To:      destination,
+		Subject: "Kode Reset Password",
+		Body: fmt.Sprintf("Kode reset password akun %s: %s. Berlaku %d menit. Jangan berikan kode ini kepada siapa pun.",
+			user.Username, code, int(s.resetCodeTTL.Minutes())),
+		Secret: code,
+	}
+	// A failed delivery is only logged; the answer must not differ from other requests
+	if err := s.sender.Send(ctx, msg); err != nil {
+		log.Printf("Password reset: failed to send code to user %d via %s: %v", user.ID, channel, err)
+		return nil
+	}
+
+	log.Printf("Password reset: code sent to user %d via %s", user.ID, channel)
+	return nil
+}
+
+// VerifyPasswordReset checks a password reset code and signs the user in.
+// The user's other sessions end, and the new session is limited to the auth routes
+// until the user sets a new password. Users with 2FA still have to pass its challenge.
+func (s *service) VerifyPasswordReset(ctx context.Context, username, code string, device DeviceInfo) (*LoginResponse, error) {
+	if err := s.throttle.CheckResetCode(ctx, username, device.IPAddress); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, s.resetCodeFailed(ctx, username, device)
+		}
+		return nil, err
+	}
+
+	resetCode, err := s.repo.FindLatestResetCode(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, ErrResetCodeNotFound) {
+			return nil, s.resetCodeFailed(ctx, username, device)
+		}
+		return nil, err
+	}
+	if !resetCode.IsUsable(time.Now(), s.resetCodeAttempts) {
+		return nil, s.resetCodeFailed(ctx, username, device)
+	}
+
+	hash := hashResetCode(user.ID, strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(resetCode.CodeHash)) != 1 {
+		if err := s.repo.IncrementResetCodeAttempts(ctx, resetCode.ID); err != nil {
+			return nil, err
+		}
+		return nil, s.resetCodeFailed(ctx, username, device)
+	}
+
+	// Same account checks as a login
+	if !user.IsActive {
+		return nil, ErrAccountInactive
+	}
+	if user.School != nil && !user.School.IsActive {
+		return nil, ErrSchoolInactive
+	}
+
+	consumed, err := s.repo.ConsumeResetCode(ctx, resetCode.ID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, s.resetCodeFailed(ctx, username, device)
+	}
+	s.throttle.SucceedResetCode(ctx, username)
+	user.MustResetPwd = true
+
+	challenge, err := s.twoFactorChallenge(ctx, user, true)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &LoginResponse{TwoFactor: challenge}, nil
+	}
+
+	tokenPair, err := s.startSession(ctx, user, device, true)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.repo.UpdateLastLogin(ctx, user.ID)
+	log.Printf("Password reset: user %d verified a code sent via %s", user.ID, resetCode.Channel)
+
+	return &LoginResponse{
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		ExpiresIn:    tokenPair.ExpiresIn,
+		TokenType:    "Bearer",
+		User:         toUserResponse(user),
+	}, nil
+}
+
+// resetDestination picks the channel and address to send a reset code to.
+// Without a requested channel, email is preferred over WhatsApp.
+func (s *service) resetDestination(ctx context.Context, user *models.User, channel messaging.Channel) (messaging.Channel, string, error) {
+	candidates := []messaging.Channel{channel}
+	if channel == "" {
+		candidates = []messaging.Channel{messaging.ChannelEmail, messaging.ChannelWhatsApp}
+	}
+
+	for _, candidate := range candidates {
+		if !s.sender.Supports(candidate) {
+			continue
+		}
+
+		switch candidate {
+		case messaging.ChannelEmail:
+			if user.Email != "" {
+				return candidate, user.Email, nil
+			}
+		case messaging.ChannelWhatsApp:
+			// Only parent accounts have a phone number
+			phone, err := s.repo.FindParentPhone(ctx, user.ID)
+			if err != nil {
+				return "", "", err
+			}
+			if phone != "" {
+				return candidate, phone, nil
+			}
+		}
+	}
+
+	return "", "", nil
+}
+
+// resetCodeFailed records a wrong password reset code and returns the error to answer it with
+func (s *service) resetCodeFailed(ctx context.Context, username string, device DeviceInfo) error {
+	if err := s.throttle.FailResetCode(ctx, username, device.IPAddress); err != nil {
+		return err
+	}
+	return ErrInvalidResetCode
+}
+
+// newResetCode generates a random numeric password reset code
+func newResetCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < resetCodeDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", resetCodeDigits, n), nil
+}
+
+// hashResetCode hashes a password reset code for storage, salted with the user ID
+func hashResetCode(userID uint, code string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", userID, code)))
+	return hex.EncodeToString(sum[:])
+}
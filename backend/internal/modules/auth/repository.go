@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/audit"
 )
 
 var (
@@ -22,6 +23,34 @@ type Repository interface {
 	UpdatePassword(ctx context.Context, id uint, passwordHash string) error
 	UpdateLastLogin(ctx context.Context, id uint) error
 	ClearPasswordReset(ctx context.Context, id uint) error
+	RecordLock(ctx context.Context, username, ipAddress, reason string, lockout time.Duration) error
+
+	// Sessions
+	CreateSession(ctx context.Context, userSession *models.UserSession) error
+	FindSessionByID(ctx context.Context, id uint) (*models.UserSession, error)
+	FindActiveSessions(ctx context.Context, userID uint, now time.Time) ([]models.UserSession, error)
+	RotateSession(ctx context.Context, id uint, currentHash, newHash string, expiresAt time.Time, device DeviceInfo) (bool, error)
+	RevokeSession(ctx context.Context, id uint, reason models.SessionRevokeReason) error
+	RevokeUserSessions(ctx context.Context, userID uint, reason models.SessionRevokeReason, keep ...uint) (int64, error)
+	DeleteStaleSessions(ctx context.Context, userID uint, before time.Time) error
+
+	// Password reset codes
+	FindParentPhone(ctx context.Context, userID uint) (string, error)
+	CreateResetCode(ctx context.Context, code *models.PasswordResetCode) error
+	FindLatestResetCode(ctx context.Context, userID uint) (*models.PasswordResetCode, error)
+	IncrementResetCodeAttempts(ctx context.Context, id uint) error
+	ConsumeResetCode(ctx context.Context, id uint, userID uint) (bool, error)
+
+	// Two-factor authentication
+	FindTwoFactor(ctx context.Context, userID uint) (*models.UserTwoFactor, error)
+	SaveTwoFactorSecret(ctx context.Context, userID uint, secret string) error
+	ConfirmTwoFactor(ctx context.Context, id uint, userID uint, step int64, codeHashes []string) (bool, error)
+	UseTwoFactorStep(ctx context.Context, id uint, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uint) (int64, error)
+	DeleteTwoFactor(ctx context.Context, userID uint) error
+	SchoolRequiresTwoFactor(ctx context.Context, schoolID uint, role models.UserRole) (bool, error)
 }
 
 // repository implements the Repository interface
@@ -71,6 +100,28 @@ func (r *repository) FindByID(ctx context.Context, id uint) (*models.User, error
 	return &user, nil
 }
 
+// RecordLock records a locked username or address in the audit trail, with the user when the username exists
+func (r *repository) RecordLock(ctx context.Context, username, ipAddress, reason string, lockout time.Duration) error {
+	event := audit.LockEvent{
+		Username:  username,
+		IPAddress: ipAddress,
+		Reason:    reason,
+		Lockout:   lockout,
+	}
+
+	if username != "" {
+		var user models.User
+		err := r.db.WithContext(ctx).Where("LOWER(username) = ?", username).First(&user).Error
+		if err == nil {
+			event.User = &user
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	return audit.RecordLock(ctx, r.db, event)
+}
+
 // UpdatePassword updates the user's password hash
 // Requirements: 12.5 - System SHALL enforce password reset on first login
 func (r *repository) UpdatePassword(ctx context.Context, id uint, passwordHash string) error {
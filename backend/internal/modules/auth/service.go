@@ -3,10 +3,13 @@ package auth
 import (
 	"context"
 	"errors"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/school-management/backend/internal/config"
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/messaging"
 )
 
 var (
@@ -19,34 +22,77 @@ var (
 
 // Service defines the interface for auth business logic
 type Service interface {
-	Authenticate(ctx context.Context, username, password string) (*LoginResponse, error)
-	RefreshAccessToken(ctx context.Context, refreshToken string) (*RefreshTokenResponse, error)
-	ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error
+	Authenticate(ctx context.Context, username, password string, device DeviceInfo) (*LoginResponse, error)
+	RefreshAccessToken(ctx context.Context, refreshToken string, device DeviceInfo) (*RefreshTokenResponse, error)
+	ChangePassword(ctx context.Context, userID, currentSessionID uint, oldPassword, newPassword string) (*ChangePasswordResponse, error)
 	GetUserByID(ctx context.Context, userID uint) (*models.User, error)
+
+	// Password reset
+	RequestPasswordReset(ctx context.Context, username string, channel messaging.Channel, ipAddress string) error
+	VerifyPasswordReset(ctx context.Context, username, code string, device DeviceInfo) (*LoginResponse, error)
+
+	// Two-factor authentication
+	VerifyTwoFactorLogin(ctx context.Context, challengeToken, code string, device DeviceInfo) (*LoginResponse, error)
+	BeginTwoFactorEnrollment(ctx context.Context, challengeToken string) (*TwoFactorSetupResponse, error)
+	GetTwoFactorStatus(ctx context.Context, userID uint) (*TwoFactorStatusResponse, error)
+	SetupTwoFactor(ctx context.Context, userID uint) (*TwoFactorSetupResponse, error)
+	ConfirmTwoFactor(ctx context.Context, userID uint, code string) (*RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) (*RecoveryCodesResponse, error)
+	DisableTwoFactor(ctx context.Context, userID uint, password, code string) error
+
+	// Sessions
+	Logout(ctx context.Context, refreshToken string) error
+	GetSessions(ctx context.Context, userID, currentSessionID uint) ([]SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID uint) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID uint) (int64, error)
 }
 
 // service implements the Service interface
 type service struct {
-	repo       Repository
-	jwtManager *JWTManager
+	repo              Repository
+	jwtManager        *JWTManager
+	throttle          *LoginThrottle
+	sender            messaging.Sender
+	resetCodeTTL      time.Duration
+	resetCodeAttempts int
+	twoFactorIssuer   string
+	twoFactorRoles    []models.UserRole // Roles that must use 2FA at every school
 }
 
 // NewService creates a new auth service
-func NewService(repo Repository, jwtManager *JWTManager) Service {
+// Password reset codes are delivered through the sender.
+func NewService(repo Repository, jwtManager *JWTManager, throttle *LoginThrottle, sender messaging.Sender, cfg config.LoginConfig, twoFactorCfg config.TwoFactorConfig) Service {
+	twoFactorRoles := make([]models.UserRole, 0, len(twoFactorCfg.RequiredRoles))
+	for _, role := range twoFactorCfg.RequiredRoles {
+		twoFactorRoles = append(twoFactorRoles, models.UserRole(role))
+	}
+
 	return &service{
-		repo:       repo,
-		jwtManager: jwtManager,
+		repo:              repo,
+		jwtManager:        jwtManager,
+		throttle:          throttle,
+		sender:            sender,
+		resetCodeTTL:      time.Duration(cfg.ResetCodeMinutes) * time.Minute,
+		resetCodeAttempts: cfg.ResetCodeAttempts,
+		twoFactorIssuer:   twoFactorCfg.Issuer,
+		twoFactorRoles:    twoFactorRoles,
 	}
 }
 
 // Authenticate authenticates a user and returns tokens
+// Staff with 2FA get a challenge instead, answered through VerifyTwoFactorLogin.
 // Requirements: 12.1 - WHEN a parent enters NISN and password, THE System SHALL authenticate and return JWT tokens
-func (s *service) Authenticate(ctx context.Context, username, password string) (*LoginResponse, error) {
+func (s *service) Authenticate(ctx context.Context, username, password string, device DeviceInfo) (*LoginResponse, error) {
+	// Refuse usernames and addresses that failed too often, before any password is checked
+	if err := s.throttle.Check(ctx, username, device.IPAddress); err != nil {
+		return nil, err
+	}
+
 	// Find user by username
 	user, err := s.repo.FindByUsername(ctx, username)
 	if err != nil {
 		if errors.Is(err, ErrUserNotFound) {
-			return nil, ErrInvalidCredentials
+			return nil, s.loginFailed(ctx, username, device)
 		}
 		return nil, err
 	}
@@ -65,18 +111,21 @@ func (s *service) Authenticate(ctx context.Context, username, password string) (
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, s.loginFailed(ctx, username, device)
 	}
+	s.throttle.Succeed(ctx, username)
 
-	// Generate token pair
-	tokenClaims := TokenClaims{
-		UserID:   user.ID,
-		SchoolID: user.SchoolID,
-		Role:     string(user.Role),
-		Username: user.Username,
+	// Ask for the second factor before any session exists
+	challenge, err := s.twoFactorChallenge(ctx, user, false)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &LoginResponse{TwoFactor: challenge}, nil
 	}
 
-	tokenPair, err := s.jwtManager.GenerateTokenPair(tokenClaims)
+	// Start a session for this device and issue its token pair
+	tokenPair, err := s.startSession(ctx, user, device, false)
 	if err != nil {
 		return nil, err
 	}
@@ -93,39 +142,25 @@ func (s *service) Authenticate(ctx context.Context, username, password string) (
 	}, nil
 }
 
+// loginFailed records a failed login and returns the error to answer it with
+func (s *service) loginFailed(ctx context.Context, username string, device DeviceInfo) error {
+	if err := s.throttle.Fail(ctx, username, device.IPAddress); err != nil {
+		return err
+	}
+	return ErrInvalidCredentials
+}
+
 // RefreshAccessToken refreshes the access token using a refresh token
+// The refresh token is rotated: the returned one replaces it and the old one can no longer be used.
 // Requirements: 12.3 - Token refresh functionality
-func (s *service) RefreshAccessToken(ctx context.Context, refreshToken string) (*RefreshTokenResponse, error) {
+func (s *service) RefreshAccessToken(ctx context.Context, refreshToken string, device DeviceInfo) (*RefreshTokenResponse, error) {
 	// Validate refresh token
 	claims, err := s.jwtManager.ValidateRefreshToken(refreshToken)
 	if err != nil {
 		return nil, err
 	}
 
-	// Verify user still exists and is active
-	user, err := s.repo.FindByID(ctx, claims.UserID)
-	if err != nil {
-		return nil, err
-	}
-
-	if !user.IsActive {
-		return nil, ErrAccountInactive
-	}
-
-	// Check if school is still active
-	if user.School != nil && !user.School.IsActive {
-		return nil, ErrSchoolInactive
-	}
-
-	// Generate new token pair
-	tokenClaims := TokenClaims{
-		UserID:   user.ID,
-		SchoolID: user.SchoolID,
-		Role:     string(user.Role),
-		Username: user.Username,
-	}
-
-	tokenPair, err := s.jwtManager.GenerateTokenPair(tokenClaims)
+	tokenPair, err := s.rotateSession(ctx, claims, device)
 	if err != nil {
 		return nil, err
 	}
@@ -138,47 +173,86 @@ func (s *service) RefreshAccessToken(ctx context.Context, refreshToken string) (
 	}, nil
 }
 
-// ChangePassword changes the user's password
+// ChangePassword changes the user's password and signs out the user's other devices
+// A session started with a password reset code sets the new password without the old one
+// and gets an access token that is no longer limited to the auth routes.
 // Requirements: 12.5 - THE System SHALL enforce password reset on first login
-func (s *service) ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error {
+func (s *service) ChangePassword(ctx context.Context, userID, currentSessionID uint, oldPassword, newPassword string) (*ChangePasswordResponse, error) {
 	// Validate new password length
 	if len(newPassword) < 8 {
-		return ErrPasswordTooShort
+		return nil, ErrPasswordTooShort
 	}
 
 	// Get user
 	user, err := s.repo.FindByID(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Get the current session, if the token carries one
+	var currentSession *models.UserSession
+	if currentSessionID != 0 {
+		currentSession, err = s.repo.FindSessionByID(ctx, currentSessionID)
+		if err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return nil, err
+		}
 	}
+	resetRequired := currentSession != nil && currentSession.UserID == userID &&
+		currentSession.ViaResetCode && user.MustResetPwd
 
 	// Verify old password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(oldPassword)); err != nil {
-		return ErrPasswordMismatch
+	if !resetRequired {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(oldPassword)); err != nil {
+			return nil, ErrPasswordMismatch
+		}
 	}
 
 	// Check if new password is different from old
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(newPassword)); err == nil {
-		return ErrSamePassword
+		return nil, ErrSamePassword
 	}
 
 	// Hash new password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Update password
 	if err := s.repo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Clear password reset flag
 	if err := s.repo.ClearPasswordReset(ctx, userID); err != nil {
-		return err
+		return nil, err
+	}
+
+	// End the sessions of other devices; the device changing the password stays signed in
+	keep := []uint{}
+	if currentSessionID != 0 {
+		keep = append(keep, currentSessionID)
+	}
+	if _, err := s.repo.RevokeUserSessions(ctx, userID, models.SessionRevokedPasswordChanged, keep...); err != nil {
+		return nil, err
+	}
+
+	if !resetRequired {
+		return &ChangePasswordResponse{}, nil
+	}
+
+	// Lift the limit of the current access token; the refresh token already follows the cleared flag
+	user.MustResetPwd = false
+	accessToken, err := s.jwtManager.GenerateAccessToken(sessionClaims(user, currentSession, ""))
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &ChangePasswordResponse{
+		AccessToken: accessToken,
+		ExpiresIn:   s.jwtManager.GetAccessTokenDuration(),
+		TokenType:   "Bearer",
+	}, nil
 }
 
 // GetUserByID retrieves a user by ID
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSessions returns the active sessions of the current user
+// @Summary Get active sessions
+// @Description Get the devices the current user is signed in on
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} []SessionResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/sessions [get]
+func (h *Handler) GetSessions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_TOKEN_INVALID",
+				"message": "Autentikasi tidak valid",
+			},
+		})
+	}
+
+	sessions, err := h.service.GetSessions(c.Context(), userID, currentSessionID(c))
+	if err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    sessions,
+	})
+}
+
+// RevokeSession ends one of the current user's sessions
+// @Summary Revoke session
+// @Description Sign out one of the current user's devices
+// @Tags Auth
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_TOKEN_INVALID",
+				"message": "Autentikasi tidak valid",
+			},
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID sesi tidak valid",
+			},
+		})
+	}
+
+	if err := h.service.RevokeSession(c.Context(), userID, uint(id)); err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Sesi berhasil diakhiri",
+	})
+}
+
+// RevokeOtherSessions ends all sessions of the current user except the current one
+// @Summary Revoke other sessions
+// @Description Sign out all other devices of the current user
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/sessions [delete]
+func (h *Handler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_TOKEN_INVALID",
+				"message": "Autentikasi tidak valid",
+			},
+		})
+	}
+
+	revoked, err := h.service.RevokeOtherSessions(c.Context(), userID, currentSessionID(c))
+	if err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"revoked": revoked,
+		},
+		"message": "Sesi perangkat lain berhasil diakhiri",
+	})
+}
+
+// currentSessionID returns the session of the access token making the request, or 0 for tokens without one
+func currentSessionID(c *fiber.Ctx) uint {
+	if claims, ok := c.Locals("claims").(*TokenClaims); ok {
+		return claims.SessionID
+	}
+	return 0
+}
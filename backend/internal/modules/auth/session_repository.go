@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/session"
+)
+
+var (
+	ErrSessionNotFound = errors.New("sesi tidak ditemukan")
+)
+
+// CreateSession creates a new session
+func (r *repository) CreateSession(ctx context.Context, userSession *models.UserSession) error {
+	return r.db.WithContext(ctx).Create(userSession).Error
+}
+
+// FindSessionByID finds a session by ID
+func (r *repository) FindSessionByID(ctx context.Context, id uint) (*models.UserSession, error) {
+	var userSession models.UserSession
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&userSession).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &userSession, nil
+}
+
+// FindActiveSessions finds the sessions of a user that can still refresh tokens, most recently used first
+func (r *repository) FindActiveSessions(ctx context.Context, userID uint, now time.Time) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+
+	return sessions, err
+}
+
+// RotateSession replaces the token hash of a session if it still holds the expected one.
+// It returns false when another request rotated or revoked the session first.
+func (r *repository) RotateSession(ctx context.Context, id uint, currentHash, newHash string, expiresAt time.Time, device DeviceInfo) (bool, error) {
+	updates := map[string]interface{}{
+		"token_hash":   newHash,
+		"last_used_at": time.Now(),
+		"expires_at":   expiresAt,
+	}
+	if device.UserAgent != "" {
+		updates["user_agent"] = device.UserAgent
+	}
+	if device.IPAddress != "" {
+		updates["ip_address"] = device.IPAddress
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&models.UserSession{}).
+		Where("id = ? AND token_hash = ? AND revoked_at IS NULL", id, currentHash).
+		Updates(updates)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// RevokeSession revokes a single session if it is not revoked yet
+func (r *repository) RevokeSession(ctx context.Context, id uint, reason models.SessionRevokeReason) error {
+	return r.db.WithContext(ctx).
+		Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		}).Error
+}
+
+// RevokeUserSessions revokes the active sessions of a user except the ones to keep
+func (r *repository) RevokeUserSessions(ctx context.Context, userID uint, reason models.SessionRevokeReason, keep ...uint) (int64, error) {
+	return session.RevokeUser(ctx, r.db, userID, reason, keep...)
+}
+
+// DeleteStaleSessions deletes the sessions of a user that expired or were revoked before the given time
+func (r *repository) DeleteStaleSessions(ctx context.Context, userID uint, before time.Time) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND (expires_at < ? OR revoked_at < ?)", userID, before, before).
+		Delete(&models.UserSession{}).Error
+}
@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+var (
+	ErrSessionRevoked = errors.New("sesi sudah berakhir, silakan masuk kembali")
+	ErrTokenReused    = errors.New("refresh token sudah pernah digunakan, sesi diakhiri demi keamanan")
+)
+
+// staleSessionRetention is how long expired and revoked sessions are kept before they are deleted
+const staleSessionRetention = 30 * 24 * time.Hour
+
+// startSession creates a session for the user and issues the first token pair for it
+// A session started with a password reset code is limited to the auth routes until a new password is set.
+func (s *service) startSession(ctx context.Context, user *models.User, device DeviceInfo, viaResetCode bool) (*TokenPair, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	userSession := &models.UserSession{
+		UserID:       user.ID,
+		TokenHash:    hashTokenID(tokenID),
+		DeviceName:   truncate(device.Name, 100),
+		UserAgent:    truncate(device.UserAgent, 500),
+		IPAddress:    truncate(device.IPAddress, 45),
+		LastUsedAt:   now,
+		ExpiresAt:    now.Add(s.jwtManager.GetRefreshTokenDuration()),
+		ViaResetCode: viaResetCode,
+	}
+	if err := s.repo.CreateSession(ctx, userSession); err != nil {
+		return nil, err
+	}
+
+	// Housekeeping only; a failure must not block the login
+	if err := s.repo.DeleteStaleSessions(ctx, user.ID, now.Add(-staleSessionRetention)); err != nil {
+		log.Printf("Auth: failed to delete stale sessions of user %d: %v", user.ID, err)
+	}
+
+	return s.jwtManager.GenerateTokenPair(sessionClaims(user, userSession, tokenID))
+}
+
+// rotateSession checks the refresh token against its session and issues a new token pair.
+// A refresh token that no longer matches its session was already rotated, so whoever presents it
+// holds a copy; the session is revoked so neither copy can be used again.
+func (s *service) rotateSession(ctx context.Context, claims *TokenClaims, device DeviceInfo) (*TokenPair, error) {
+	// Tokens issued before sessions were tracked carry no session
+	if claims.SessionID == 0 || claims.TokenID == "" {
+		return nil, ErrSessionRevoked
+	}
+
+	userSession, err := s.repo.FindSessionByID(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return nil, ErrSessionRevoked
+		}
+		return nil, err
+	}
+	if userSession.UserID != claims.UserID {
+		return nil, ErrTokenInvalid
+	}
+	if !userSession.IsActive(time.Now()) {
+		return nil, ErrSessionRevoked
+	}
+
+	currentHash := hashTokenID(claims.TokenID)
+	if userSession.TokenHash != currentHash {
+		return nil, s.revokeReusedSession(ctx, userSession.ID, userSession.UserID)
+	}
+
+	// Verify user still exists and is active
+	user, err := s.repo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		if err := s.repo.RevokeSession(ctx, userSession.ID, models.SessionRevokedDeactivated); err != nil {
+			return nil, err
+		}
+		return nil, ErrAccountInactive
+	}
+
+	// Check if school is still active
+	if user.School != nil && !user.School.IsActive {
+		return nil, ErrSchoolInactive
+	}
+
+	tokenID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.jwtManager.GetRefreshTokenDuration())
+	device.UserAgent = truncate(device.UserAgent, 500)
+	device.IPAddress = truncate(device.IPAddress, 45)
+	rotated, err := s.repo.RotateSession(ctx, userSession.ID, currentHash, hashTokenID(tokenID), expiresAt, device)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Another request presented the same token first
+		return nil, s.revokeReusedSession(ctx, userSession.ID, userSession.UserID)
+	}
+
+	return s.jwtManager.GenerateTokenPair(sessionClaims(user, userSession, tokenID))
+}
+
+// revokeReusedSession revokes a session whose refresh token was presented after rotation
+func (s *service) revokeReusedSession(ctx context.Context, sessionID, userID uint) error {
+	log.Printf("Auth: refresh token reuse detected on session %d of user %d, revoking session", sessionID, userID)
+	if err := s.repo.RevokeSession(ctx, sessionID, models.SessionRevokedTokenReuse); err != nil {
+		return err
+	}
+	return ErrTokenReused
+}
+
+// Logout ends the session of a refresh token.
+// Invalid or already revoked tokens are ignored so clients can always complete a logout.
+func (s *service) Logout(ctx context.Context, refreshToken string) error {
+	if refreshToken == "" {
+		return nil
+	}
+
+	claims, err := s.jwtManager.ValidateRefreshToken(refreshToken)
+	if err != nil || claims.SessionID == 0 {
+		return nil
+	}
+
+	userSession, err := s.repo.FindSessionByID(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Only the current refresh token of a session may end it
+	if userSession.UserID != claims.UserID || userSession.TokenHash != hashTokenID(claims.TokenID) {
+		return nil
+	}
+
+	return s.repo.RevokeSession(ctx, userSession.ID, models.SessionRevokedLogout)
+}
+
+// GetSessions retrieves the active sessions of a user
+func (s *service) GetSessions(ctx context.Context, userID, currentSessionID uint) ([]SessionResponse, error) {
+	sessions, err := s.repo.FindActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]SessionResponse, len(sessions))
+	for i := range sessions {
+		responses[i] = toSessionResponse(&sessions[i], currentSessionID)
+	}
+
+	return responses, nil
+}
+
+// RevokeSession revokes one of the user's own sessions
+func (s *service) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	userSession, err := s.repo.FindSessionByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if userSession.UserID != userID || !userSession.IsActive(time.Now()) {
+		return ErrSessionNotFound
+	}
+
+	return s.repo.RevokeSession(ctx, userSession.ID, models.SessionRevokedByUser)
+}
+
+// RevokeOtherSessions revokes all sessions of the user except the current one
+func (s *service) RevokeOtherSessions(ctx context.Context, userID, currentSessionID uint) (int64, error) {
+	if currentSessionID == 0 {
+		return s.repo.RevokeUserSessions(ctx, userID, models.SessionRevokedByUser)
+	}
+	return s.repo.RevokeUserSessions(ctx, userID, models.SessionRevokedByUser, currentSessionID)
+}
+
+// sessionClaims builds the token claims of a user's session
+func sessionClaims(user *models.User, userSession *models.UserSession, tokenID string) TokenClaims {
+	return TokenClaims{
+		UserID:        user.ID,
+		SchoolID:      user.SchoolID,
+		Role:          string(user.Role),
+		Username:      user.Username,
+		SessionID:     userSession.ID,
+		TokenID:       tokenID,
+		ResetRequired: userSession.ViaResetCode && user.MustResetPwd,
+	}
+}
+
+// newTokenID generates a random refresh token ID
+func newTokenID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashTokenID hashes a refresh token ID for storage
+func hashTokenID(tokenID string) string {
+	sum := sha256.Sum256([]byte(tokenID))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate shortens client-supplied device info to fit its column
+func truncate(value string, limit int) string {
+	if len(value) > limit {
+		return value[:limit]
+	}
+	return value
+}
+
+// toSessionResponse converts a UserSession model to SessionResponse DTO
+func toSessionResponse(userSession *models.UserSession, currentSessionID uint) SessionResponse {
+	return SessionResponse{
+		ID:         userSession.ID,
+		DeviceName: userSession.DeviceName,
+		UserAgent:  userSession.UserAgent,
+		IPAddress:  userSession.IPAddress,
+		CreatedAt:  userSession.CreatedAt,
+		LastUsedAt: userSession.LastUsedAt,
+		ExpiresAt:  userSession.ExpiresAt,
+		IsCurrent:  userSession.ID == currentSessionID,
+	}
+}
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/school-management/backend/internal/config"
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// sessionRepository keeps users and sessions in memory
+// Methods the session tests do not need panic through the nil embedded Repository.
+type sessionRepository struct {
+	Repository
+	users    map[uint]*models.User
+	sessions map[uint]*models.UserSession
+	// rotateRace makes RotateSession lose against a concurrent rotation
+	rotateRace bool
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *sessionRepository) FindSessionByID(ctx context.Context, id uint) (*models.UserSession, error) {
+	userSession, ok := r.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	copied := *userSession
+	return &copied, nil
+}
+
+func (r *sessionRepository) RotateSession(ctx context.Context, id uint, currentHash, newHash string, expiresAt time.Time, device DeviceInfo) (bool, error) {
+	userSession, ok := r.sessions[id]
+	if !ok || r.rotateRace || userSession.TokenHash != currentHash || userSession.RevokedAt != nil {
+		return false, nil
+	}
+	userSession.TokenHash = newHash
+	userSession.ExpiresAt = expiresAt
+	return true, nil
+}
+
+func (r *sessionRepository) RevokeSession(ctx context.Context, id uint, reason models.SessionRevokeReason) error {
+	if userSession, ok := r.sessions[id]; ok && userSession.RevokedAt == nil {
+		now := time.Now()
+		userSession.RevokedAt = &now
+		userSession.RevokedReason = reason
+	}
+	return nil
+}
+
+const sessionTestTokenID = "token-1"
+
+func newSessionTestService() (*service, *sessionRepository) {
+	repo := &sessionRepository{
+		users: map[uint]*models.User{
+			1: {ID: 1, Username: "guru", Role: models.RoleGuru, IsActive: true},
+		},
+		sessions: map[uint]*models.UserSession{
+			10: {ID: 10, UserID: 1, TokenHash: hashTokenID(sessionTestTokenID), ExpiresAt: time.Now().Add(time.Hour)},
+		},
+	}
+	jwtManager := NewJWTManager(config.JWTConfig{
+		SecretKey:            "test-secret",
+		AccessTokenDuration:  15,
+		RefreshTokenDuration: 24,
+		Issuer:               "test",
+	})
+	return &service{repo: repo, jwtManager: jwtManager}, repo
+}
+
+func TestRotateSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		claims     TokenClaims
+		setup      func(repo *sessionRepository)
+		wantErr    error
+		wantReason models.SessionRevokeReason
+	}{
+		{
+			name:   "current token",
+			claims: TokenClaims{UserID: 1, SessionID: 10, TokenID: sessionTestTokenID},
+		},
+		{
+			name:       "rotated token presented again",
+			claims:     TokenClaims{UserID: 1, SessionID: 10, TokenID: "token-0"},
+			wantErr:    ErrTokenReused,
+			wantReason: models.SessionRevokedTokenReuse,
+		},
+		{
+			name:       "concurrent rotation wins",
+			claims:     TokenClaims{UserID: 1, SessionID: 10, TokenID: sessionTestTokenID},
+			setup:      func(repo *sessionRepository) { repo.rotateRace = true },
+			wantErr:    ErrTokenReused,
+			wantReason: models.SessionRevokedTokenReuse,
+		},
+		{
+			name:    "token without a session",
+			claims:  TokenClaims{UserID: 1},
+			wantErr: ErrSessionRevoked,
+		},
+		{
+			name:    "unknown session",
+			claims:  TokenClaims{UserID: 1, SessionID: 11, TokenID: sessionTestTokenID},
+			wantErr: ErrSessionRevoked,
+		},
+		{
+			name:    "session of another user",
+			claims:  TokenClaims{UserID: 2, SessionID: 10, TokenID: sessionTestTokenID},
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name:   "revoked session",
+			claims: TokenClaims{UserID: 1, SessionID: 10, TokenID: sessionTestTokenID},
+			setup: func(repo *sessionRepository) {
+				repo.RevokeSession(context.Background(), 10, models.SessionRevokedByUser)
+			},
+			wantErr:    ErrSessionRevoked,
+			wantReason: models.SessionRevokedByUser,
+		},
+		{
+			name:   "expired session",
+			claims: TokenClaims{UserID: 1, SessionID: 10, TokenID: sessionTestTokenID},
+			setup: func(repo *sessionRepository) {
+				repo.sessions[10].ExpiresAt = time.Now().Add(-time.Minute)
+			},
+			wantErr: ErrSessionRevoked,
+		},
+		{
+			name:       "deactivated user",
+			claims:     TokenClaims{UserID: 1, SessionID: 10, TokenID: sessionTestTokenID},
+			setup:      func(repo *sessionRepository) { repo.users[1].IsActive = false },
+			wantErr:    ErrAccountInactive,
+			wantReason: models.SessionRevokedDeactivated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newSessionTestService()
+			if tt.setup != nil {
+				tt.setup(repo)
+			}
+
+			claims := tt.claims
+			pair, err := s.rotateSession(context.Background(), &claims, DeviceInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("rotateSession() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := repo.sessions[10].RevokedReason; got != tt.wantReason {
+				t.Errorf("revoked reason = %q, want %q", got, tt.wantReason)
+			}
+			if tt.wantErr == nil && pair == nil {
+				t.Error("rotateSession() returned no tokens")
+			}
+		})
+	}
+}
+
+func TestRotateSessionReuse(t *testing.T) {
+	s, repo := newSessionTestService()
+	ctx := context.Background()
+
+	first := &TokenClaims{UserID: 1, SessionID: 10, TokenID: sessionTestTokenID}
+	pair, err := s.rotateSession(ctx, first, DeviceInfo{})
+	if err != nil {
+		t.Fatalf("first rotation: %v", err)
+	}
+	rotated, err := s.jwtManager.ValidateRefreshToken(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("rotated refresh token: %v", err)
+	}
+	if rotated.SessionID != 10 || rotated.TokenID == sessionTestTokenID {
+		t.Fatalf("rotated claims = %+v, want session 10 with a new token ID", rotated)
+	}
+
+	// Whoever holds a copy of the first token replays it and ends the session
+	if _, err := s.rotateSession(ctx, first, DeviceInfo{}); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("replayed token: error = %v, want %v", err, ErrTokenReused)
+	}
+
+	// The legitimate holder of the rotated token is signed out as well
+	if _, err := s.rotateSession(ctx, rotated, DeviceInfo{}); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("rotated token after reuse: error = %v, want %v", err, ErrSessionRevoked)
+	}
+	if got := repo.sessions[10].RevokedReason; got != models.SessionRevokedTokenReuse {
+		t.Errorf("revoked reason = %q, want %q", got, models.SessionRevokedTokenReuse)
+	}
+}
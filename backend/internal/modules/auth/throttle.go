@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/school-management/backend/internal/config"
+	"github.com/school-management/backend/internal/shared/ratelimit"
+	"github.com/school-management/backend/internal/shared/redis"
+)
+
+const (
+	// loginBaseDelay is the wait after the first delayed login failure; it doubles with every further failure
+	loginBaseDelay = 2 * time.Second
+	// resetRequestWindow is the period over which password reset code requests are counted
+	resetRequestWindow = time.Hour
+	// resetRequestsPerUsername and resetRequestsPerAddress limit how many codes can be requested per window
+	resetRequestsPerUsername = 3
+	resetRequestsPerAddress  = 20
+)
+
+// LoginThrottle slows down password guessing per username and per IP address.
+// Usernames of parents and students are predictable, so a username is locked even if it
+// does not exist; otherwise the lockout would reveal which accounts do.
+// Password reset codes and 2FA codes are throttled the same way, and wrong codes count against the address.
+// When Redis is unavailable logins are let through rather than blocked.
+// Locks are recorded in the audit trail, since the counters only live in Redis.
+type LoginThrottle struct {
+	recorder      LockRecorder
+	usernames     *ratelimit.Limiter
+	addresses     *ratelimit.Limiter
+	resetCodes    *ratelimit.Limiter
+	resetRequests *ratelimit.Limiter
+	resetSenders  *ratelimit.Limiter
+	twoFactor     *ratelimit.Limiter
+}
+
+// LockRecorder records the locks of usernames and addresses
+type LockRecorder interface {
+	RecordLock(ctx context.Context, username, ipAddress, reason string, lockout time.Duration) error
+}
+
+// NewLoginThrottle creates a new login throttle
+func NewLoginThrottle(redisClient *redis.Client, cfg config.LoginConfig, recorder LockRecorder) *LoginThrottle {
+	lockout := time.Duration(cfg.LockoutMinutes) * time.Minute
+	return &LoginThrottle{
+		recorder: recorder,
+		usernames: ratelimit.NewLimiter(redisClient, "login", ratelimit.Policy{
+			MaxFailures: cfg.MaxAttempts,
+			Window:      lockout,
+			Lockout:     lockout,
+			DelayAfter:  cfg.DelayAfterAttempts,
+			BaseDelay:   loginBaseDelay,
+		}),
+		// Many users of a school can share one address, so addresses are only locked, never delayed
+		addresses: ratelimit.NewLimiter(redisClient, "login-ip", ratelimit.Policy{
+			MaxFailures: cfg.IPMaxAttempts,
+			Window:      lockout,
+			Lockout:     lockout,
+		}),
+		resetCodes: ratelimit.NewLimiter(redisClient, "reset-code", ratelimit.Policy{
+			MaxFailures: cfg.ResetCodeAttempts,
+			Window:      lockout,
+			Lockout:     lockout,
+		}),
+		// Every request counts, so the same account cannot be flooded with codes
+		resetRequests: ratelimit.NewLimiter(redisClient, "reset-request", ratelimit.Policy{
+			MaxFailures: resetRequestsPerUsername,
+			Window:      resetRequestWindow,
+			Lockout:     resetRequestWindow,
+		}),
+		resetSenders: ratelimit.NewLimiter(redisClient, "reset-request-ip", ratelimit.Policy{
+			MaxFailures: resetRequestsPerAddress,
+			Window:      resetRequestWindow,
+			Lockout:     resetRequestWindow,
+		}),
+		twoFactor: ratelimit.NewLimiter(redisClient, "two-factor", ratelimit.Policy{
+			MaxFailures: cfg.TwoFactorAttempts,
+			Window:      lockout,
+			Lockout:     lockout,
+		}),
+	}
+}
+
+// Check returns a ratelimit.BlockedError if the username or address has to wait before trying again
+func (t *LoginThrottle) Check(ctx context.Context, username, ipAddress string) error {
+	if err := t.check(ctx, t.usernames, usernameKey(username)); err != nil {
+		return err
+	}
+	if ipAddress == "" {
+		return nil
+	}
+	return t.check(ctx, t.addresses, ipAddress)
+}
+
+// Fail records a failed login and returns the lock it caused, if any
+func (t *LoginThrottle) Fail(ctx context.Context, username, ipAddress string) error {
+	blocked, err := t.usernames.Fail(ctx, usernameKey(username))
+	if err != nil {
+		log.Printf("Login throttle: failed to record failure of %q: %v", username, err)
+	}
+	t.recordLock(ctx, blocked, "login", usernameKey(username), ipAddress)
+
+	if ipAddress != "" {
+		addressBlocked, err := t.addresses.Fail(ctx, ipAddress)
+		if err != nil {
+			log.Printf("Login throttle: failed to record failure from %s: %v", ipAddress, err)
+		}
+		t.recordLock(ctx, addressBlocked, "login-ip", "", ipAddress)
+		if addressBlocked != nil && (blocked == nil || !blocked.Locked) {
+			blocked = addressBlocked
+		}
+	}
+
+	// Delays only apply to the next attempt; this one is answered as a wrong password
+	if blocked != nil && blocked.Locked {
+		return blocked
+	}
+	return nil
+}
+
+// Succeed forgets the failed logins of a username
+func (t *LoginThrottle) Succeed(ctx context.Context, username string) {
+	if err := t.usernames.Reset(ctx, usernameKey(username)); err != nil {
+		log.Printf("Login throttle: failed to reset failures of %q: %v", username, err)
+	}
+}
+
+// LockedUntil returns until when a username is locked, or nil if it is not
+func (t *LoginThrottle) LockedUntil(ctx context.Context, username string) (*time.Time, error) {
+	blocked, err := t.usernames.Status(ctx, usernameKey(username))
+	if err != nil || blocked == nil || !blocked.Locked {
+		return nil, err
+	}
+
+	lockedUntil := time.Now().Add(blocked.RetryAfter)
+	return &lockedUntil, nil
+}
+
+// Unlock lifts the lock of a username and forgets its failed logins and wrong codes.
+// It returns whether the username was locked or delayed.
+func (t *LoginThrottle) Unlock(ctx context.Context, username string) (bool, error) {
+	unlocked := false
+	for _, limiter := range []*ratelimit.Limiter{t.usernames, t.resetCodes, t.twoFactor} {
+		limiterUnlocked, err := limiter.Unlock(ctx, usernameKey(username))
+		if err != nil {
+			return false, err
+		}
+		unlocked = unlocked || limiterUnlocked
+	}
+
+	return unlocked, nil
+}
+
+// AllowResetRequest counts a request for a password reset code.
+// It returns a ratelimit.BlockedError once the username or address requested too many codes.
+func (t *LoginThrottle) AllowResetRequest(ctx context.Context, username, ipAddress string) error {
+	if err := t.check(ctx, t.resetRequests, usernameKey(username)); err != nil {
+		return err
+	}
+	if ipAddress != "" {
+		if err := t.check(ctx, t.resetSenders, ipAddress); err != nil {
+			return err
+		}
+	}
+
+	// The request that reaches the limit is still served; the block applies to the next ones
+	if _, err := t.resetRequests.Fail(ctx, usernameKey(username)); err != nil {
+		log.Printf("Login throttle: failed to count reset request for %q: %v", username, err)
+	}
+	if ipAddress != "" {
+		if _, err := t.resetSenders.Fail(ctx, ipAddress); err != nil {
+			log.Printf("Login throttle: failed to count reset request from %s: %v", ipAddress, err)
+		}
+	}
+	return nil
+}
+
+// recordLock records a lock caused by a failure; usernames are empty for locks of an address
+func (t *LoginThrottle) recordLock(ctx context.Context, blocked *ratelimit.BlockedError, reason, username, ipAddress string) {
+	if blocked == nil || !blocked.Locked || t.recorder == nil {
+		return
+	}
+	if err := t.recorder.RecordLock(ctx, username, ipAddress, reason, blocked.RetryAfter); err != nil {
+		log.Printf("Login throttle: failed to record %s lock of %q from %s: %v", reason, username, ipAddress, err)
+	}
+}
+
+// CheckResetCode returns a ratelimit.BlockedError if the username or address has to wait before verifying a code
+func (t *LoginThrottle) CheckResetCode(ctx context.Context, username, ipAddress string) error {
+	return t.checkCode(ctx, t.resetCodes, username, ipAddress)
+}
+
+// FailResetCode records a wrong password reset code and returns the lock it caused, if any
+func (t *LoginThrottle) FailResetCode(ctx context.Context, username, ipAddress string) error {
+	return t.failCode(ctx, t.resetCodes, "reset code", "reset-code", username, ipAddress)
+}
+
+// SucceedResetCode forgets the wrong reset codes of a username
+func (t *LoginThrottle) SucceedResetCode(ctx context.Context, username string) {
+	t.succeedCode(ctx, t.resetCodes, "reset codes", username)
+}
+
+// CheckTwoFactor returns a ratelimit.BlockedError if the username or address has to wait before entering a 2FA code
+func (t *LoginThrottle) CheckTwoFactor(ctx context.Context, username, ipAddress string) error {
+	return t.checkCode(ctx, t.twoFactor, username, ipAddress)
+}
+
+// FailTwoFactor records a wrong authenticator or recovery code and returns the lock it caused, if any
+func (t *LoginThrottle) FailTwoFactor(ctx context.Context, username, ipAddress string) error {
+	return t.failCode(ctx, t.twoFactor, "2FA code", "two-factor", username, ipAddress)
+}
+
+// SucceedTwoFactor forgets the wrong 2FA codes of a username
+func (t *LoginThrottle) SucceedTwoFactor(ctx context.Context, username string) {
+	t.succeedCode(ctx, t.twoFactor, "2FA codes", username)
+}
+
+// checkCode returns the block of a username for a kind of code, or of the address
+func (t *LoginThrottle) checkCode(ctx context.Context, limiter *ratelimit.Limiter, username, ipAddress string) error {
+	if err := t.check(ctx, limiter, usernameKey(username)); err != nil {
+		return err
+	}
+	if ipAddress == "" {
+		return nil
+	}
+	return t.check(ctx, t.addresses, ipAddress)
+}
+
+// failCode records a wrong code against the username and the address and returns the lock it caused, if any
+func (t *LoginThrottle) failCode(ctx context.Context, limiter *ratelimit.Limiter, kind, reason, username, ipAddress string) error {
+	blocked, err := limiter.Fail(ctx, usernameKey(username))
+	if err != nil {
+		log.Printf("Login throttle: failed to record wrong %s for %q: %v", kind, username, err)
+	}
+	t.recordLock(ctx, blocked, reason, usernameKey(username), ipAddress)
+
+	if ipAddress != "" {
+		addressBlocked, err := t.addresses.Fail(ctx, ipAddress)
+		if err != nil {
+			log.Printf("Login throttle: failed to record wrong %s from %s: %v", kind, ipAddress, err)
+		}
+		t.recordLock(ctx, addressBlocked, "login-ip", "", ipAddress)
+		if blocked == nil {
+			blocked = addressBlocked
+		}
+	}
+
+	if blocked != nil && blocked.Locked {
+		return blocked
+	}
+	return nil
+}
+
+// succeedCode forgets the wrong codes of a username
+func (t *LoginThrottle) succeedCode(ctx context.Context, limiter *ratelimit.Limiter, kind, username string) {
+	if err := limiter.Reset(ctx, usernameKey(username)); err != nil {
+		log.Printf("Login throttle: failed to reset wrong %s of %q: %v", kind, username, err)
+	}
+}
+
+// check returns the block of a key, letting the attempt through if Redis cannot be reached
+func (t *LoginThrottle) check(ctx context.Context, limiter *ratelimit.Limiter, key string) error {
+	blocked, err := limiter.Status(ctx, key)
+	if err != nil {
+		log.Printf("Login throttle: failed to check %q: %v", key, err)
+		return nil
+	}
+	if blocked != nil {
+		return blocked
+	}
+	return nil
+}
+
+// usernameKey normalizes a username so differently cased guesses share one counter
+func usernameKey(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,351 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// VerifyTwoFactor handles the second step of a login
+// @Summary Verify two-factor code
+// @Description Finish a login with the challenge token from login and a code from the authenticator app or a recovery code. During enrollment the first code confirms the app and the response holds the recovery codes.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorVerifyRequest true "Challenge token and code"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many wrong codes, see Retry-After"
+// @Router /api/v1/auth/2fa/verify [post]
+func (h *Handler) VerifyTwoFactor(c *fiber.Ctx) error {
+	var req TwoFactorVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	if req.ChallengeToken == "" || strings.TrimSpace(req.Code) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Challenge token dan kode wajib diisi",
+			},
+		})
+	}
+
+	response, err := h.service.VerifyTwoFactorLogin(c.Context(), req.ChallengeToken, req.Code, DeviceInfo{
+		Name:      req.DeviceName,
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	})
+	if err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// EnrollTwoFactor handles enrollment during a login that requires 2FA
+// @Summary Enroll two-factor during login
+// @Description Get an authenticator secret with the challenge token of a login that requires 2FA before it is set up. Finish with /auth/2fa/verify.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorEnrollRequest true "Challenge token"
+// @Success 200 {object} TwoFactorSetupResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/auth/2fa/enroll [post]
+func (h *Handler) EnrollTwoFactor(c *fiber.Ctx) error {
+	var req TwoFactorEnrollRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	if req.ChallengeToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Challenge token wajib diisi",
+			},
+		})
+	}
+
+	response, err := h.service.BeginTwoFactorEnrollment(c.Context(), req.ChallengeToken)
+	if err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Pindai kode QR dengan aplikasi autentikator, lalu masukkan kode yang muncul",
+	})
+}
+
+// GetTwoFactorStatus returns the 2FA state of the current user
+// @Summary Get two-factor status
+// @Description Get whether two-factor authentication is available, enabled and required for the current user
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} TwoFactorStatusResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/2fa [get]
+func (h *Handler) GetTwoFactorStatus(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_TOKEN_INVALID",
+				"message": "Autentikasi tidak valid",
+			},
+		})
+	}
+
+	status, err := h.service.GetTwoFactorStatus(c.Context(), userID)
+	if err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// SetupTwoFactor starts enrollment of an authenticator app
+// @Summary Set up two-factor
+// @Description Get a new authenticator secret for the current user. 2FA is enabled once a code is confirmed.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} TwoFactorSetupResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/auth/2fa/setup [post]
+func (h *Handler) SetupTwoFactor(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_TOKEN_INVALID",
+				"message": "Autentikasi tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.SetupTwoFactor(c.Context(), userID)
+	if err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Pindai kode QR dengan aplikasi autentikator, lalu masukkan kode yang muncul",
+	})
+}
+
+// ConfirmTwoFactor enables 2FA with the first code from the authenticator app
+// @Summary Confirm two-factor
+// @Description Enable two-factor authentication with a code from the authenticator app. The recovery codes are shown only in this response.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "Authenticator code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many wrong codes, see Retry-After"
+// @Security BearerAuth
+// @Router /api/v1/auth/2fa/confirm [post]
+func (h *Handler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_TOKEN_INVALID",
+				"message": "Autentikasi tidak valid",
+			},
+		})
+	}
+
+	req, err := h.parseTwoFactorCode(c)
+	if err != nil || req == nil {
+		return err
+	}
+
+	response, err := h.service.ConfirmTwoFactor(c.Context(), userID, req.Code)
+	if err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Autentikasi dua langkah aktif. Simpan kode pemulihan di tempat yang aman",
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes after checking a code from the authenticator app
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "Authenticator code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many wrong codes, see Retry-After"
+// @Security BearerAuth
+// @Router /api/v1/auth/2fa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_TOKEN_INVALID",
+				"message": "Autentikasi tidak valid",
+			},
+		})
+	}
+
+	req, err := h.parseTwoFactorCode(c)
+	if err != nil || req == nil {
+		return err
+	}
+
+	response, err := h.service.RegenerateRecoveryCodes(c.Context(), userID, req.Code)
+	if err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Kode pemulihan baru telah dibuat, kode lama tidak berlaku lagi",
+	})
+}
+
+// DisableTwoFactor turns 2FA off for the current user
+// @Summary Disable two-factor
+// @Description Turn two-factor authentication off with the password and an authenticator or recovery code. Not allowed while 2FA is required for the role.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body DisableTwoFactorRequest true "Password and code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many wrong codes, see Retry-After"
+// @Security BearerAuth
+// @Router /api/v1/auth/2fa/disable [post]
+func (h *Handler) DisableTwoFactor(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_TOKEN_INVALID",
+				"message": "Autentikasi tidak valid",
+			},
+		})
+	}
+
+	var req DisableTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	if req.Password == "" || strings.TrimSpace(req.Code) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Password dan kode wajib diisi",
+			},
+		})
+	}
+
+	if err := h.service.DisableTwoFactor(c.Context(), userID, req.Password, req.Code); err != nil {
+		return h.handleAuthError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Autentikasi dua langkah dinonaktifkan",
+	})
+}
+
+// twoFactorChallenge answers a correct password or reset code of a user with 2FA
+func (h *Handler) twoFactorChallenge(c *fiber.Ctx, challenge *TwoFactorChallengeResponse) error {
+	message := "Masukkan kode dari aplikasi autentikator"
+	if challenge.EnrollmentRequired {
+		message = "Autentikasi dua langkah wajib untuk akun ini, silakan siapkan aplikasi autentikator"
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    challenge,
+		"message": message,
+	})
+}
+
+// parseTwoFactorCode parses a request carrying an authenticator code.
+// It returns a nil request after answering an invalid one.
+func (h *Handler) parseTwoFactorCode(c *fiber.Ctx) (*TwoFactorCodeRequest, error) {
+	var req TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	if strings.TrimSpace(req.Code) == "" {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Kode wajib diisi",
+			},
+		})
+	}
+
+	return &req, nil
+}
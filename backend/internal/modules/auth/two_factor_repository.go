@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+var (
+	ErrTwoFactorNotFound = errors.New("autentikasi dua langkah belum disiapkan")
+)
+
+// FindTwoFactor finds the authenticator app enrollment of a user, confirmed or not
+func (r *repository) FindTwoFactor(ctx context.Context, userID uint) (*models.UserTwoFactor, error) {
+	var twoFactor models.UserTwoFactor
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&twoFactor).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotFound
+		}
+		return nil, err
+	}
+
+	return &twoFactor, nil
+}
+
+// SaveTwoFactorSecret starts a new enrollment, replacing an unconfirmed one
+func (r *repository) SaveTwoFactorSecret(ctx context.Context, userID uint, secret string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Delete(&models.UserTwoFactor{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.UserTwoFactor{
+			UserID: userID,
+			Secret: secret,
+		}).Error
+	})
+}
+
+// ConfirmTwoFactor enables an enrollment and stores its first recovery codes, all at once.
+// It returns false when the enrollment was confirmed by a concurrent request.
+func (r *repository) ConfirmTwoFactor(ctx context.Context, id uint, userID uint, step int64, codeHashes []string) (bool, error) {
+	confirmed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserTwoFactor{}).
+			Where("id = ? AND confirmed_at IS NULL", id).
+			Updates(map[string]interface{}{
+				"confirmed_at":   time.Now(),
+				"last_used_step": step,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+			return err
+		}
+
+		confirmed = true
+		return nil
+	})
+
+	return confirmed, err
+}
+
+// UseTwoFactorStep records the time step of an accepted authenticator code.
+// It returns false when a code of this or a later step was already used, i.e. the code is replayed.
+func (r *repository) UseTwoFactorStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.UserTwoFactor{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of a user
+func (r *repository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code used and returns whether there was one
+func (r *repository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// CountRecoveryCodes counts the unused recovery codes of a user
+func (r *repository) CountRecoveryCodes(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+
+	return count, err
+}
+
+// DeleteTwoFactor turns 2FA off for a user by removing the secret and the recovery codes
+func (r *repository) DeleteTwoFactor(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error
+	})
+}
+
+// SchoolRequiresTwoFactor checks if a school requires 2FA for a role in its settings
+func (r *repository) SchoolRequiresTwoFactor(ctx context.Context, schoolID uint, role models.UserRole) (bool, error) {
+	var settings models.SchoolSettings
+	err := r.db.WithContext(ctx).
+		Select("id", "two_factor_required_roles").
+		Where("school_id = ?", schoolID).
+		First(&settings).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return settings.RequiresTwoFactor(role), nil
+}
+
+// replaceRecoveryCodes deletes the recovery codes of a user and stores new ones within a transaction
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]models.TwoFactorRecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, models.TwoFactorRecoveryCode{
+			UserID:   userID,
+			CodeHash: hash,
+		})
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	return tx.Create(&codes).Error
+}
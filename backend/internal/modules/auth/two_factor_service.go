@@ -0,0 +1,470 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/totp"
+)
+
+var (
+	ErrInvalidTwoFactorCode    = errors.New("kode autentikasi salah")
+	ErrTwoFactorNotAvailable   = errors.New("autentikasi dua langkah tidak tersedia untuk peran ini")
+	ErrTwoFactorAlreadyEnabled = errors.New("autentikasi dua langkah sudah aktif")
+	ErrTwoFactorNotEnabled     = errors.New("autentikasi dua langkah belum aktif")
+	ErrTwoFactorMandatory      = errors.New("autentikasi dua langkah wajib untuk peran ini")
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes generated at once
+	recoveryCodeCount = 10
+	// recoveryCodeLength is the number of characters of a recovery code, shown in two halves
+	recoveryCodeLength = 10
+	// recoveryCodeAlphabet leaves out characters that are easily confused, such as 0/o and 1/l
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// twoFactorChallenge returns the challenge to answer before a login of the user completes,
+// or nil when the user signs in with a password alone.
+// Users whose role requires 2FA but who have not set it up yet are asked to enroll first.
+func (s *service) twoFactorChallenge(ctx context.Context, user *models.User, viaResetCode bool) (*TwoFactorChallengeResponse, error) {
+	if !user.Role.SupportsTwoFactor() {
+		return nil, nil
+	}
+
+	enabled, err := s.twoFactorEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.twoFactorRequired(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled && !required {
+		return nil, nil
+	}
+
+	// A password reset code replaces the password, not the second factor
+	challengeToken, err := s.jwtManager.GenerateChallengeToken(TokenClaims{
+		UserID:        user.ID,
+		SchoolID:      user.SchoolID,
+		Role:          string(user.Role),
+		Username:      user.Username,
+		ResetRequired: viaResetCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorChallengeResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     challengeToken,
+		ExpiresIn:          s.jwtManager.GetChallengeTokenDuration(),
+		EnrollmentRequired: !enabled,
+	}, nil
+}
+
+// VerifyTwoFactorLogin finishes a login with an authenticator or recovery code.
+// During enrollment the first authenticator code confirms the app, and the recovery codes
+// are returned with the tokens.
+func (s *service) VerifyTwoFactorLogin(ctx context.Context, challengeToken, code string, device DeviceInfo) (*LoginResponse, error) {
+	claims, err := s.jwtManager.ValidateChallengeToken(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.challengedUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.repo.FindTwoFactor(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	if twoFactor.IsEnabled() {
+		if err := s.verifyTwoFactorCode(ctx, user, twoFactor, code, device.IPAddress, true); err != nil {
+			return nil, err
+		}
+	} else {
+		recoveryCodes, err = s.confirmTwoFactor(ctx, user, twoFactor, code, device.IPAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tokenPair, err := s.startSession(ctx, user, device, claims.ResetRequired)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.repo.UpdateLastLogin(ctx, user.ID)
+
+	return &LoginResponse{
+		AccessToken:   tokenPair.AccessToken,
+		RefreshToken:  tokenPair.RefreshToken,
+		ExpiresIn:     tokenPair.ExpiresIn,
+		TokenType:     "Bearer",
+		User:          toUserResponse(user),
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// BeginTwoFactorEnrollment starts enrollment for a user whose login requires 2FA before it is set up.
+// The challenge token stands in for a session, so it cannot replace an authenticator that is already confirmed.
+func (s *service) BeginTwoFactorEnrollment(ctx context.Context, challengeToken string) (*TwoFactorSetupResponse, error) {
+	claims, err := s.jwtManager.ValidateChallengeToken(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.challengedUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.setupTwoFactor(ctx, user)
+}
+
+// GetTwoFactorStatus returns the 2FA state of a user
+func (s *service) GetTwoFactorStatus(ctx context.Context, userID uint) (*TwoFactorStatusResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TwoFactorStatusResponse{Available: user.Role.SupportsTwoFactor()}
+	if !status.Available {
+		return status, nil
+	}
+
+	if status.Required, err = s.twoFactorRequired(ctx, user); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.repo.FindTwoFactor(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, ErrTwoFactorNotFound) {
+			return status, nil
+		}
+		return nil, err
+	}
+	if !twoFactor.IsEnabled() {
+		return status, nil
+	}
+
+	status.Enabled = true
+	status.EnabledAt = twoFactor.ConfirmedAt
+	if status.RecoveryCodesLeft, err = s.repo.CountRecoveryCodes(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// SetupTwoFactor starts enrollment of an authenticator app for a signed-in user
+func (s *service) SetupTwoFactor(ctx context.Context, userID uint) (*TwoFactorSetupResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.setupTwoFactor(ctx, user)
+}
+
+// ConfirmTwoFactor enables 2FA with the first code from the authenticator app and returns the recovery codes
+func (s *service) ConfirmTwoFactor(ctx context.Context, userID uint, code string) (*RecoveryCodesResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.repo.FindTwoFactor(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	recoveryCodes, err := s.confirmTwoFactor(ctx, user, twoFactor, code, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after checking an authenticator code
+func (s *service) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) (*RecoveryCodesResponse, error) {
+	user, twoFactor, err := s.enabledTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A recovery code cannot be traded for a fresh set
+	if err := s.verifyTwoFactorCode(ctx, user, twoFactor, code, "", false); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, hashes, err := newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Two-factor: user %d generated new recovery codes", user.ID)
+	return &RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// DisableTwoFactor turns 2FA off after checking the password and a code.
+// It is refused while the school or platform requires 2FA for the user's role.
+func (s *service) DisableTwoFactor(ctx context.Context, userID uint, password, code string) error {
+	user, twoFactor, err := s.enabledTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	required, err := s.twoFactorRequired(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorMandatory
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return ErrPasswordMismatch
+	}
+	if err := s.verifyTwoFactorCode(ctx, user, twoFactor, code, "", true); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTwoFactor(ctx, user.ID); err != nil {
+		return err
+	}
+
+	log.Printf("Two-factor: user %d turned 2FA off", user.ID)
+	return nil
+}
+
+// twoFactorEnabled checks if a user has a confirmed authenticator app
+func (s *service) twoFactorEnabled(ctx context.Context, userID uint) (bool, error) {
+	twoFactor, err := s.repo.FindTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrTwoFactorNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.IsEnabled(), nil
+}
+
+// twoFactorRequired checks if the platform or the user's school requires 2FA for the user's role
+func (s *service) twoFactorRequired(ctx context.Context, user *models.User) (bool, error) {
+	if !user.Role.SupportsTwoFactor() {
+		return false, nil
+	}
+
+	for _, role := range s.twoFactorRoles {
+		if role == user.Role {
+			return true, nil
+		}
+	}
+
+	if user.SchoolID == nil {
+		return false, nil
+	}
+	return s.repo.SchoolRequiresTwoFactor(ctx, *user.SchoolID, user.Role)
+}
+
+// challengedUser loads the user of a challenge token with the same account checks as a login
+func (s *service) challengedUser(ctx context.Context, claims *TokenClaims) (*models.User, error) {
+	user, err := s.repo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrTokenInvalid
+		}
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountInactive
+	}
+	if user.School != nil && !user.School.IsActive {
+		return nil, ErrSchoolInactive
+	}
+
+	return user, nil
+}
+
+// enabledTwoFactor loads a user together with the confirmed authenticator app
+func (s *service) enabledTwoFactor(ctx context.Context, userID uint) (*models.User, *models.UserTwoFactor, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	twoFactor, err := s.repo.FindTwoFactor(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, ErrTwoFactorNotFound) {
+			return nil, nil, ErrTwoFactorNotEnabled
+		}
+		return nil, nil, err
+	}
+	if !twoFactor.IsEnabled() {
+		return nil, nil, ErrTwoFactorNotEnabled
+	}
+
+	return user, twoFactor, nil
+}
+
+// setupTwoFactor generates a new secret for a user, replacing an unconfirmed one
+func (s *service) setupTwoFactor(ctx context.Context, user *models.User) (*TwoFactorSetupResponse, error) {
+	if !user.Role.SupportsTwoFactor() {
+		return nil, ErrTwoFactorNotAvailable
+	}
+
+	enabled, err := s.twoFactorEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveTwoFactorSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.twoFactorIssuer, user.Username, secret),
+	}, nil
+}
+
+// confirmTwoFactor enables an unconfirmed enrollment with a code from the authenticator app
+// and returns the new recovery codes
+func (s *service) confirmTwoFactor(ctx context.Context, user *models.User, twoFactor *models.UserTwoFactor, code, ipAddress string) ([]string, error) {
+	if err := s.throttle.CheckTwoFactor(ctx, user.Username, ipAddress); err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return nil, s.twoFactorFailed(ctx, user.Username, ipAddress)
+	}
+
+	recoveryCodes, hashes, err := newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmed, err := s.repo.ConfirmTwoFactor(ctx, twoFactor.ID, user.ID, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	s.throttle.SucceedTwoFactor(ctx, user.Username)
+
+	log.Printf("Two-factor: user %d turned 2FA on", user.ID)
+	return recoveryCodes, nil
+}
+
+// verifyTwoFactorCode checks an authenticator code, or a recovery code if allowed, of a confirmed enrollment.
+// Each authenticator code and each recovery code works only once.
+func (s *service) verifyTwoFactorCode(ctx context.Context, user *models.User, twoFactor *models.UserTwoFactor, code, ipAddress string, allowRecovery bool) error {
+	if err := s.throttle.CheckTwoFactor(ctx, user.Username, ipAddress); err != nil {
+		return err
+	}
+
+	if step, ok := totp.Validate(twoFactor.Secret, code, time.Now()); ok {
+		used, err := s.repo.UseTwoFactorStep(ctx, twoFactor.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return s.twoFactorFailed(ctx, user.Username, ipAddress)
+		}
+
+		s.throttle.SucceedTwoFactor(ctx, user.Username)
+		return nil
+	}
+
+	if allowRecovery {
+		used, err := s.repo.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(user.ID, code))
+		if err != nil {
+			return err
+		}
+		if used {
+			s.throttle.SucceedTwoFactor(ctx, user.Username)
+			log.Printf("Two-factor: user %d used a recovery code", user.ID)
+			return nil
+		}
+	}
+
+	return s.twoFactorFailed(ctx, user.Username, ipAddress)
+}
+
+// twoFactorFailed records a wrong 2FA code and returns the error to answer it with
+func (s *service) twoFactorFailed(ctx context.Context, username, ipAddress string) error {
+	if err := s.throttle.FailTwoFactor(ctx, username, ipAddress); err != nil {
+		return err
+	}
+	return ErrInvalidTwoFactorCode
+}
+
+// newRecoveryCodes generates a set of recovery codes and the hashes to store
+func newRecoveryCodes(userID uint) ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	limit := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < recoveryCodeCount; i++ {
+		var code strings.Builder
+		for j := 0; j < recoveryCodeLength; j++ {
+			if j == recoveryCodeLength/2 {
+				code.WriteByte('-')
+			}
+			n, err := rand.Int(rand.Reader, limit)
+			if err != nil {
+				return nil, nil, err
+			}
+			code.WriteByte(recoveryCodeAlphabet[n.Int64()])
+		}
+
+		codes = append(codes, code.String())
+		hashes = append(hashes, hashRecoveryCode(userID, code.String()))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code for storage, salted with the user ID
+// Case, spaces and the dash are ignored so codes can be typed as they are read.
+func hashRecoveryCode(userID uint, code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", userID, normalized)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d of each", len(codes), len(hashes), recoveryCodeCount)
+	}
+
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		halves := strings.Split(code, "-")
+		if len(halves) != 2 || len(halves[0]) != recoveryCodeLength/2 || len(halves[1]) != recoveryCodeLength-recoveryCodeLength/2 {
+			t.Errorf("code %q is not two halves of %d characters", code, recoveryCodeLength/2)
+		}
+		for _, c := range strings.ReplaceAll(code, "-", "") {
+			if !strings.ContainsRune(recoveryCodeAlphabet, c) {
+				t.Errorf("code %q contains %q, which is not in the alphabet", code, c)
+			}
+		}
+		if seen[code] {
+			t.Errorf("code %q generated twice", code)
+		}
+		seen[code] = true
+
+		if hashes[i] != hashRecoveryCode(7, code) {
+			t.Errorf("hash %d does not match code %q", i, code)
+		}
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	const code = "abcde-fghjk"
+	stored := hashRecoveryCode(7, code)
+
+	tests := []struct {
+		name   string
+		userID uint
+		code   string
+		match  bool
+	}{
+		{name: "as shown", userID: 7, code: code, match: true},
+		{name: "upper case", userID: 7, code: "ABCDE-FGHJK", match: true},
+		{name: "without dash", userID: 7, code: "abcdefghjk", match: true},
+		{name: "with spaces", userID: 7, code: " abcde fghjk ", match: true},
+		{name: "other user", userID: 8, code: code, match: false},
+		{name: "other code", userID: 7, code: "abcde-fghjm", match: false},
+		{name: "empty", userID: 7, code: "", match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hashRecoveryCode(tt.userID, tt.code) == stored; got != tt.match {
+				t.Errorf("hashRecoveryCode(%d, %q) matches = %v, want %v", tt.userID, tt.code, got, tt.match)
+			}
+		})
+	}
+}
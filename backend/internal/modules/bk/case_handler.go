@@ -0,0 +1,484 @@
+package bk
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ==================== Counseling Cases ====================
+
+// GetCounselingCases handles listing counseling cases (with internal descriptions)
+// @Summary List counseling cases
+// @Description Get a paginated list of counseling cases with their session and follow-up summary
+// @Tags BK - Counseling Cases
+// @Produce json
+// @Param student_id query int false "Filter by student ID"
+// @Param class_id query int false "Filter by class ID"
+// @Param counselor_id query int false "Filter by counselor ID"
+// @Param status query string false "Filter by status (open, in_progress, resolved, referred, active)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} CounselingCaseListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/counseling-cases [get]
+func (h *Handler) GetCounselingCases(c *fiber.Ctx) error {
+	return h.listCounselingCases(c, true)
+}
+
+// GetCounselingCasesReadOnly handles listing counseling cases (without internal descriptions)
+func (h *Handler) GetCounselingCasesReadOnly(c *fiber.Ctx) error {
+	return h.listCounselingCases(c, false)
+}
+
+func (h *Handler) listCounselingCases(c *fiber.Ctx, includeInternal bool) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	var filter CounselingCaseFilter
+	if studentID, err := strconv.ParseUint(c.Query("student_id"), 10, 32); err == nil {
+		id := uint(studentID)
+		filter.StudentID = &id
+	}
+	if classID, err := strconv.ParseUint(c.Query("class_id"), 10, 32); err == nil {
+		id := uint(classID)
+		filter.ClassID = &id
+	}
+	if counselorID, err := strconv.ParseUint(c.Query("counselor_id"), 10, 32); err == nil {
+		id := uint(counselorID)
+		filter.CounselorID = &id
+	}
+	if status := c.Query("status"); status != "" {
+		filter.Status = &status
+	}
+	filter.Page, _ = strconv.Atoi(c.Query("page", "1"))
+	filter.PageSize, _ = strconv.Atoi(c.Query("page_size", "20"))
+
+	response, err := h.service.GetCounselingCases(c.Context(), schoolID, filter, includeInternal)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetCounselingCaseByID handles getting a single counseling case (with internal notes)
+// @Summary Get counseling case by ID
+// @Description Get a counseling case with its sessions, follow-ups and the violations and permits that triggered it
+// @Tags BK - Counseling Cases
+// @Produce json
+// @Param id path int true "Case ID"
+// @Success 200 {object} CounselingCaseResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/counseling-cases/{id} [get]
+func (h *Handler) GetCounselingCaseByID(c *fiber.Ctx) error {
+	return h.getCounselingCase(c, true)
+}
+
+// GetCounselingCaseByIDReadOnly handles getting a single counseling case (without internal notes)
+// Like counseling notes, Wali Kelas only see the parent summaries of the sessions.
+func (h *Handler) GetCounselingCaseByIDReadOnly(c *fiber.Ctx) error {
+	return h.getCounselingCase(c, false)
+}
+
+func (h *Handler) getCounselingCase(c *fiber.Ctx, includeInternal bool) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "case")
+	}
+
+	response, err := h.service.GetCounselingCaseByID(c.Context(), schoolID, uint(id), includeInternal)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// CreateCounselingCase handles opening a counseling case
+// @Summary Open counseling case
+// @Description Open a counseling case for a student, optionally linked to the violations and exit permits that triggered it
+// @Tags BK - Counseling Cases
+// @Accept json
+// @Produce json
+// @Param request body CreateCounselingCaseRequest true "Case data"
+// @Success 201 {object} CounselingCaseResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/counseling-cases [post]
+func (h *Handler) CreateCounselingCase(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	var req CreateCounselingCaseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.CreateCounselingCase(c.Context(), schoolID, userID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Kasus konseling berhasil dibuat",
+	})
+}
+
+// UpdateCounselingCase handles updating a counseling case
+// @Summary Update counseling case
+// @Description Update a case that is not closed; sending violation_ids or permit_ids replaces the linked records
+// @Tags BK - Counseling Cases
+// @Accept json
+// @Produce json
+// @Param id path int true "Case ID"
+// @Param request body UpdateCounselingCaseRequest true "Case data"
+// @Success 200 {object} CounselingCaseResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/counseling-cases/{id} [put]
+func (h *Handler) UpdateCounselingCase(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "case")
+	}
+
+	var req UpdateCounselingCaseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.UpdateCounselingCase(c.Context(), schoolID, uint(id), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Kasus konseling berhasil diperbarui",
+	})
+}
+
+// CloseCounselingCase handles closing a counseling case
+// @Summary Close counseling case
+// @Description Resolve a case or refer it outside the school, recording the outcome. Sessions still scheduled are cancelled.
+// @Tags BK - Counseling Cases
+// @Accept json
+// @Produce json
+// @Param id path int true "Case ID"
+// @Param request body CloseCounselingCaseRequest true "Case outcome"
+// @Success 200 {object} CounselingCaseResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/counseling-cases/{id}/close [post]
+func (h *Handler) CloseCounselingCase(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "case")
+	}
+
+	var req CloseCounselingCaseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.CloseCounselingCase(c.Context(), schoolID, userID, uint(id), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Kasus konseling berhasil ditutup",
+	})
+}
+
+// ReopenCounselingCase handles reopening a closed counseling case
+// @Summary Reopen counseling case
+// @Description Put a resolved or referred case back in progress
+// @Tags BK - Counseling Cases
+// @Produce json
+// @Param id path int true "Case ID"
+// @Success 200 {object} CounselingCaseResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/counseling-cases/{id}/reopen [post]
+func (h *Handler) ReopenCounselingCase(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "case")
+	}
+
+	response, err := h.service.ReopenCounselingCase(c.Context(), schoolID, uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Kasus konseling dibuka kembali",
+	})
+}
+
+// ==================== Counseling Sessions ====================
+
+// ScheduleCounselingSession handles scheduling a session of a case
+// @Summary Schedule counseling session
+// @Description Schedule a session of a counseling case
+// @Tags BK - Counseling Cases
+// @Accept json
+// @Produce json
+// @Param id path int true "Case ID"
+// @Param request body CreateCounselingSessionRequest true "Session data"
+// @Success 201 {object} CounselingSessionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/counseling-cases/{id}/sessions [post]
+func (h *Handler) ScheduleCounselingSession(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	caseID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "case")
+	}
+
+	var req CreateCounselingSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.ScheduleCounselingSession(c.Context(), schoolID, userID, uint(caseID), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Sesi konseling berhasil dijadwalkan",
+	})
+}
+
+// UpdateCounselingSession handles rescheduling a session or recording its result
+// @Summary Update counseling session
+// @Description Reschedule a session or record it as held, cancelled or missed. A held session requires the internal note and moves an open case to in_progress.
+// @Tags BK - Counseling Cases
+// @Accept json
+// @Produce json
+// @Param id path int true "Case ID"
+// @Param sessionId path int true "Session ID"
+// @Param request body UpdateCounselingSessionRequest true "Session data"
+// @Success 200 {object} CounselingSessionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/counseling-cases/{id}/sessions/{sessionId} [put]
+func (h *Handler) UpdateCounselingSession(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	caseID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "case")
+	}
+
+	sessionID, err := strconv.ParseUint(c.Params("sessionId"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "session")
+	}
+
+	var req UpdateCounselingSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.UpdateCounselingSession(c.Context(), schoolID, uint(caseID), uint(sessionID), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Sesi konseling berhasil diperbarui",
+	})
+}
+
+// ==================== Counseling Follow-ups ====================
+
+// AddCounselingFollowUp handles adding a follow-up to a case
+// @Summary Add counseling follow-up
+// @Description Add a dated follow-up to a case; the assignee is reminded when it falls due
+// @Tags BK - Counseling Cases
+// @Accept json
+// @Produce json
+// @Param id path int true "Case ID"
+// @Param request body CreateCounselingFollowUpRequest true "Follow-up data"
+// @Success 201 {object} CounselingFollowUpResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/counseling-cases/{id}/follow-ups [post]
+func (h *Handler) AddCounselingFollowUp(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	caseID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "case")
+	}
+
+	var req CreateCounselingFollowUpRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.AddCounselingFollowUp(c.Context(), schoolID, userID, uint(caseID), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Tindak lanjut berhasil ditambahkan",
+	})
+}
+
+// CompleteCounselingFollowUp handles marking a follow-up as done
+// @Summary Complete counseling follow-up
+// @Description Mark a follow-up of a case as done
+// @Tags BK - Counseling Cases
+// @Produce json
+// @Param id path int true "Case ID"
+// @Param followUpId path int true "Follow-up ID"
+// @Success 200 {object} CounselingFollowUpResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/counseling-cases/{id}/follow-ups/{followUpId}/complete [post]
+func (h *Handler) CompleteCounselingFollowUp(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	caseID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "case")
+	}
+
+	followUpID, err := strconv.ParseUint(c.Params("followUpId"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "follow-up")
+	}
+
+	response, err := h.service.CompleteCounselingFollowUp(c.Context(), schoolID, userID, uint(caseID), uint(followUpID))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Tindak lanjut selesai",
+	})
+}
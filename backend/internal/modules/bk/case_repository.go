@@ -0,0 +1,376 @@
+package bk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/outbox"
+)
+
+var (
+	ErrCounselingCaseNotFound     = errors.New("kasus konseling tidak ditemukan")
+	ErrCounselingSessionNotFound  = errors.New("sesi konseling tidak ditemukan")
+	ErrCounselingFollowUpNotFound = errors.New("tindak lanjut kasus tidak ditemukan")
+)
+
+// activeCaseStatuses are the statuses of cases that are still being handled
+var activeCaseStatuses = []models.CounselingCaseStatus{
+	models.CounselingCaseStatusOpen,
+	models.CounselingCaseStatusInProgress,
+}
+
+// caseRelations are skipped when a case row is written; links are written through their join tables
+var caseRelations = []string{"Student", "Class", "Counselor", "Opener", "Closer", "Sessions", "FollowUps", "Violations.*", "Permits.*"}
+
+// DueFollowUp is a follow-up whose assignee has not been reminded yet, with what the reminder needs
+type DueFollowUp struct {
+	ID          uint
+	CaseID      uint
+	DueAt       time.Time
+	Note        string
+	AssignedTo  uint
+	SchoolID    uint
+	StudentID   uint
+	StudentName string
+	CaseTitle   string
+}
+
+// ==================== Counseling Case Repository ====================
+
+// CreateCounselingCase creates a counseling case with its links to violations and permits
+func (r *repository) CreateCounselingCase(ctx context.Context, counselingCase *models.CounselingCase) error {
+	return r.db.WithContext(ctx).Omit(caseRelations...).Create(counselingCase).Error
+}
+
+// FindCounselingCaseByID retrieves a case of a school with its sessions, follow-ups and linked records
+func (r *repository) FindCounselingCaseByID(ctx context.Context, schoolID, id uint) (*models.CounselingCase, error) {
+	var counselingCase models.CounselingCase
+	err := r.db.WithContext(ctx).
+		Preload("Student").
+		Preload("Class").
+		Preload("Counselor").
+		Preload("Opener").
+		Preload("Closer").
+		Preload("Sessions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("scheduled_at ASC")
+		}).
+		Preload("Sessions.Creator").
+		Preload("FollowUps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("due_at ASC")
+		}).
+		Preload("FollowUps.Assignee").
+		Preload("FollowUps.Completer").
+		Preload("Violations", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at DESC")
+		}).
+		Preload("Violations.Creator").
+		Preload("Permits", func(db *gorm.DB) *gorm.DB {
+			return db.Order("exit_time DESC")
+		}).
+		Preload("Permits.Teacher").
+		Where("id = ? AND school_id = ?", id, schoolID).
+		First(&counselingCase).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCounselingCaseNotFound
+		}
+		return nil, err
+	}
+	return &counselingCase, nil
+}
+
+// FindCounselingCases retrieves cases with pagination and filtering, newest first
+func (r *repository) FindCounselingCases(ctx context.Context, schoolID uint, filter CounselingCaseFilter) ([]models.CounselingCase, int64, error) {
+	var cases []models.CounselingCase
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&models.CounselingCase{}).
+		Where("school_id = ?", schoolID)
+
+	if filter.StudentID != nil {
+		query = query.Where("student_id = ?", *filter.StudentID)
+	}
+	if filter.ClassID != nil {
+		query = query.Where("class_id = ?", *filter.ClassID)
+	}
+	if filter.CounselorID != nil {
+		query = query.Where("counselor_id = ?", *filter.CounselorID)
+	}
+	if filter.Status != nil && *filter.Status != "" {
+		if *filter.Status == "active" {
+			query = query.Where("status IN ?", activeCaseStatuses)
+		} else {
+			query = query.Where("status = ?", *filter.Status)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Student").
+		Preload("Class").
+		Preload("Counselor").
+		Preload("Opener").
+		Preload("Closer").
+		Preload("Sessions").
+		Preload("FollowUps").
+		Order("created_at DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&cases).Error
+
+	return cases, total, err
+}
+
+// UpdateCounselingCase updates a case; links are replaced when violations or permits is not nil
+func (r *repository) UpdateCounselingCase(ctx context.Context, counselingCase *models.CounselingCase, violations *[]models.Violation, permits *[]models.Permit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(caseRelations...).Save(counselingCase).Error; err != nil {
+			return err
+		}
+		if violations != nil {
+			if err := tx.Model(counselingCase).Omit("Violations.*").Association("Violations").Replace(*violations); err != nil {
+				return err
+			}
+		}
+		if permits != nil {
+			if err := tx.Model(counselingCase).Omit("Permits.*").Association("Permits").Replace(*permits); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CloseCounselingCase records the outcome of a case and cancels its sessions still scheduled
+func (r *repository) CloseCounselingCase(ctx context.Context, counselingCase *models.CounselingCase) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.CounselingCase{}).
+			Where("id = ?", counselingCase.ID).
+			Updates(map[string]interface{}{
+				"status":      counselingCase.Status,
+				"outcome":     counselingCase.Outcome,
+				"referred_to": counselingCase.ReferredTo,
+				"closed_by":   counselingCase.ClosedBy,
+				"closed_at":   counselingCase.ClosedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.CounselingSession{}).
+			Where("case_id = ? AND status = ?", counselingCase.ID, models.CounselingSessionStatusScheduled).
+			Update("status", models.CounselingSessionStatusCancelled).Error
+	})
+}
+
+// FindStudentViolationsByIDs retrieves the given violations that belong to a student
+func (r *repository) FindStudentViolationsByIDs(ctx context.Context, studentID uint, ids []uint) ([]models.Violation, error) {
+	var violations []models.Violation
+	if len(ids) == 0 {
+		return violations, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("student_id = ? AND id IN ?", studentID, ids).
+		Find(&violations).Error
+	return violations, err
+}
+
+// FindStudentPermitsByIDs retrieves the given exit permits that belong to a student
+func (r *repository) FindStudentPermitsByIDs(ctx context.Context, studentID uint, ids []uint) ([]models.Permit, error) {
+	var permits []models.Permit
+	if len(ids) == 0 {
+		return permits, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("student_id = ? AND id IN ?", studentID, ids).
+		Find(&permits).Error
+	return permits, err
+}
+
+// ==================== Counseling Session Repository ====================
+
+// CreateCounselingSession creates a session of a case
+func (r *repository) CreateCounselingSession(ctx context.Context, session *models.CounselingSession) error {
+	return r.db.WithContext(ctx).Omit("Creator").Create(session).Error
+}
+
+// FindCounselingSessionByID retrieves a session of a case
+func (r *repository) FindCounselingSessionByID(ctx context.Context, caseID, id uint) (*models.CounselingSession, error) {
+	var session models.CounselingSession
+	err := r.db.WithContext(ctx).
+		Preload("Creator").
+		Where("id = ? AND case_id = ?", id, caseID).
+		First(&session).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCounselingSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// UpdateCounselingSession updates a session; holding one moves an open case to in_progress
+func (r *repository) UpdateCounselingSession(ctx context.Context, session *models.CounselingSession) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Creator").Save(session).Error; err != nil {
+			return err
+		}
+		if session.Status != models.CounselingSessionStatusHeld {
+			return nil
+		}
+
+		return tx.Model(&models.CounselingCase{}).
+			Where("id = ? AND status = ?", session.CaseID, models.CounselingCaseStatusOpen).
+			Update("status", models.CounselingCaseStatusInProgress).Error
+	})
+}
+
+// ==================== Counseling Follow-up Repository ====================
+
+// CreateCounselingFollowUp creates a follow-up of a case
+func (r *repository) CreateCounselingFollowUp(ctx context.Context, followUp *models.CounselingFollowUp) error {
+	return r.db.WithContext(ctx).Omit("Assignee", "Completer").Create(followUp).Error
+}
+
+// FindCounselingFollowUpByID retrieves a follow-up of a case
+func (r *repository) FindCounselingFollowUpByID(ctx context.Context, caseID, id uint) (*models.CounselingFollowUp, error) {
+	var followUp models.CounselingFollowUp
+	err := r.db.WithContext(ctx).
+		Preload("Assignee").
+		Preload("Completer").
+		Where("id = ? AND case_id = ?", id, caseID).
+		First(&followUp).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCounselingFollowUpNotFound
+		}
+		return nil, err
+	}
+	return &followUp, nil
+}
+
+// CompleteCounselingFollowUp marks a follow-up as done
+func (r *repository) CompleteCounselingFollowUp(ctx context.Context, followUp *models.CounselingFollowUp) error {
+	return r.db.WithContext(ctx).
+		Model(&models.CounselingFollowUp{}).
+		Where("id = ?", followUp.ID).
+		Updates(map[string]interface{}{
+			"completed_by": followUp.CompletedBy,
+			"completed_at": followUp.CompletedAt,
+		}).Error
+}
+
+// FindDueFollowUps retrieves follow-ups of active cases that fell due without a reminder, oldest first
+func (r *repository) FindDueFollowUps(ctx context.Context, now time.Time, limit int) ([]DueFollowUp, error) {
+	var followUps []DueFollowUp
+	err := r.db.WithContext(ctx).
+		Table("counseling_follow_ups").
+		Select("counseling_follow_ups.id, counseling_follow_ups.case_id, counseling_follow_ups.due_at, "+
+			"counseling_follow_ups.note, counseling_follow_ups.assigned_to, counseling_cases.school_id, "+
+			"counseling_cases.student_id, students.name AS student_name, counseling_cases.title AS case_title").
+		Joins("JOIN counseling_cases ON counseling_cases.id = counseling_follow_ups.case_id").
+		Joins("JOIN students ON students.id = counseling_cases.student_id").
+		Where("counseling_follow_ups.due_at <= ?", now).
+		Where("counseling_follow_ups.completed_at IS NULL AND counseling_follow_ups.reminder_sent_at IS NULL").
+		Where("counseling_cases.status IN ?", activeCaseStatuses).
+		Order("counseling_follow_ups.due_at ASC").
+		Limit(limit).
+		Scan(&followUps).Error
+	return followUps, err
+}
+
+// MarkFollowUpReminded records the reminder of a follow-up and writes its event in one transaction
+// It returns false when another instance reminded the assignee first.
+func (r *repository) MarkFollowUpReminded(ctx context.Context, id uint, at time.Time, event *outbox.Event) (bool, error) {
+	marked := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.CounselingFollowUp{}).
+			Where("id = ? AND reminder_sent_at IS NULL", id).
+			Update("reminder_sent_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		marked = true
+		return outbox.Append(tx, id, event)
+	})
+	return marked, err
+}
+
+// ==================== Counseling Case Dashboard ====================
+
+// GetCounselingCaseCounts returns the number of cases of a school per status
+func (r *repository) GetCounselingCaseCounts(ctx context.Context, schoolID uint) (map[models.CounselingCaseStatus]int64, error) {
+	var rows []struct {
+		Status models.CounselingCaseStatus
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.CounselingCase{}).
+		Select("status, COUNT(*) AS count").
+		Where("school_id = ?", schoolID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.CounselingCaseStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// GetOverdueFollowUpCount returns the number of follow-ups of active cases past their due time
+func (r *repository) GetOverdueFollowUpCount(ctx context.Context, schoolID uint, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.CounselingFollowUp{}).
+		Joins("JOIN counseling_cases ON counseling_cases.id = counseling_follow_ups.case_id").
+		Where("counseling_cases.school_id = ? AND counseling_cases.status IN ?", schoolID, activeCaseStatuses).
+		Where("counseling_follow_ups.completed_at IS NULL AND counseling_follow_ups.due_at < ?", now).
+		Count(&count).Error
+	return count, err
+}
+
+// GetUpcomingSessionCount returns the number of sessions of a school scheduled in [from, to)
+func (r *repository) GetUpcomingSessionCount(ctx context.Context, schoolID uint, from, to time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.CounselingSession{}).
+		Joins("JOIN counseling_cases ON counseling_cases.id = counseling_sessions.case_id").
+		Where("counseling_cases.school_id = ? AND counseling_sessions.status = ?", schoolID, models.CounselingSessionStatusScheduled).
+		Where("counseling_sessions.scheduled_at >= ? AND counseling_sessions.scheduled_at < ?", from, to).
+		Count(&count).Error
+	return count, err
+}
+
+// FindRecentlyClosedCases retrieves the last resolved or referred cases of a school
+func (r *repository) FindRecentlyClosedCases(ctx context.Context, schoolID uint, limit int) ([]models.CounselingCase, error) {
+	var cases []models.CounselingCase
+	err := r.db.WithContext(ctx).
+		Preload("Student").
+		Preload("Class").
+		Preload("Counselor").
+		Preload("Closer").
+		Where("school_id = ? AND closed_at IS NOT NULL", schoolID).
+		Order("closed_at DESC").
+		Limit(limit).
+		Find(&cases).Error
+	return cases, err
+}
@@ -0,0 +1,673 @@
+package bk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// DefaultSessionMinutes is the length of a counseling session when none is given
+const DefaultSessionMinutes = 45
+
+var (
+	ErrInvalidCaseStatus      = errors.New("status kasus harus open atau in_progress")
+	ErrInvalidCaseClosure     = errors.New("kasus hanya dapat ditutup dengan status resolved atau referred")
+	ErrCaseOutcomeRequired    = errors.New("hasil penanganan kasus wajib diisi")
+	ErrReferredToRequired     = errors.New("tujuan rujukan wajib diisi")
+	ErrCaseClosed             = errors.New("kasus konseling sudah ditutup")
+	ErrCaseNotClosed          = errors.New("kasus konseling belum ditutup")
+	ErrInvalidCounselor       = errors.New("konselor harus Guru BK aktif di sekolah ini")
+	ErrInvalidAssignee        = errors.New("penanggung jawab tindak lanjut harus staf aktif di sekolah ini")
+	ErrCaseViolationMismatch  = errors.New("pelanggaran yang ditautkan bukan milik siswa ini")
+	ErrCasePermitMismatch     = errors.New("izin keluar yang ditautkan bukan milik siswa ini")
+	ErrSessionTimeRequired    = errors.New("waktu sesi wajib diisi")
+	ErrInvalidSessionDuration = errors.New("durasi sesi harus antara 1 dan 240 menit")
+	ErrInvalidSessionStatus   = errors.New("status sesi harus scheduled, held, cancelled atau missed")
+	ErrFollowUpDueRequired    = errors.New("waktu tindak lanjut wajib diisi")
+	ErrFollowUpAlreadyDone    = errors.New("tindak lanjut sudah selesai")
+)
+
+// ==================== Counseling Case Service ====================
+
+// CreateCounselingCase opens a counseling case for a student
+// Without a counselor in the request the case goes to the Guru BK opening it, or else to the class counselor.
+func (s *service) CreateCounselingCase(ctx context.Context, schoolID, userID uint, req CreateCounselingCaseRequest) (*CounselingCaseResponse, error) {
+	if req.StudentID == 0 {
+		return nil, ErrStudentIDRequired
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, ErrTitleRequired
+	}
+
+	student, err := s.repo.FindStudentByID(ctx, req.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	if student.SchoolID != schoolID {
+		return nil, ErrStudentNotInSchool
+	}
+
+	counselorID := req.CounselorID
+	if counselorID != nil {
+		if err := s.checkCounselor(ctx, schoolID, *counselorID); err != nil {
+			return nil, err
+		}
+	} else {
+		opener, err := s.repo.FindUserByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if opener.Role == models.RoleGuruBK {
+			counselorID = &opener.ID
+		} else if counselorID, _, err = s.findCounselors(ctx, schoolID, student); err != nil {
+			return nil, err
+		}
+	}
+
+	violations, permits, err := s.findCaseLinks(ctx, student.ID, req.ViolationIDs, req.PermitIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	counselingCase := &models.CounselingCase{
+		SchoolID:    schoolID,
+		StudentID:   student.ID,
+		ClassID:     student.ClassID,
+		Title:       title,
+		Description: strings.TrimSpace(req.Description),
+		Status:      models.CounselingCaseStatusOpen,
+		CounselorID: counselorID,
+		OpenedBy:    userID,
+		Violations:  violations,
+		Permits:     permits,
+	}
+	if err := counselingCase.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateCounselingCase(ctx, counselingCase); err != nil {
+		return nil, err
+	}
+
+	return s.writtenCounselingCase(ctx, schoolID, counselingCase.ID)
+}
+
+// GetCounselingCases retrieves cases with pagination and filtering
+func (s *service) GetCounselingCases(ctx context.Context, schoolID uint, filter CounselingCaseFilter, includeInternal bool) (*CounselingCaseListResponse, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	cases, total, err := s.repo.FindCounselingCases(ctx, schoolID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if includeInternal && len(cases) > 0 {
+		ids := make([]uint, len(cases))
+		for i := range cases {
+			ids[i] = cases[i].ID
+		}
+		if err := s.repo.RecordInternalNoteReads(ctx, "counseling_cases", ids...); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	responses := make([]CounselingCaseResponse, len(cases))
+	for i := range cases {
+		responses[i] = *toCounselingCaseResponse(&cases[i], includeInternal, now)
+	}
+
+	totalPages := int(total) / filter.PageSize
+	if int(total)%filter.PageSize > 0 {
+		totalPages++
+	}
+
+	return &CounselingCaseListResponse{
+		Cases: responses,
+		Pagination: PaginationMeta{
+			Page:       filter.Page,
+			PageSize:   filter.PageSize,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+// GetCounselingCaseByID retrieves a case with its sessions, follow-ups and linked records
+func (s *service) GetCounselingCaseByID(ctx context.Context, schoolID, id uint, includeInternal bool) (*CounselingCaseResponse, error) {
+	counselingCase, err := s.repo.FindCounselingCaseByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The description and the internal notes of held sessions are shown to Guru BK only
+	if includeInternal {
+		if err := s.repo.RecordInternalNoteReads(ctx, "counseling_cases", counselingCase.ID); err != nil {
+			return nil, err
+		}
+
+		sessionIDs := make([]uint, 0, len(counselingCase.Sessions))
+		for i := range counselingCase.Sessions {
+			if counselingCase.Sessions[i].InternalNote != "" {
+				sessionIDs = append(sessionIDs, counselingCase.Sessions[i].ID)
+			}
+		}
+		if err := s.repo.RecordInternalNoteReads(ctx, "counseling_sessions", sessionIDs...); err != nil {
+			return nil, err
+		}
+	}
+
+	return toCounselingCaseDetailResponse(counselingCase, includeInternal, time.Now()), nil
+}
+
+// writtenCounselingCase retrieves a case for the response to Guru BK changing it.
+// Unlike GetCounselingCaseByID it does not record a read, as the change itself is audited.
+func (s *service) writtenCounselingCase(ctx context.Context, schoolID, id uint) (*CounselingCaseResponse, error) {
+	counselingCase, err := s.repo.FindCounselingCaseByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toCounselingCaseDetailResponse(counselingCase, true, time.Now()), nil
+}
+
+// UpdateCounselingCase updates a case that is not closed
+func (s *service) UpdateCounselingCase(ctx context.Context, schoolID, id uint, req UpdateCounselingCaseRequest) (*CounselingCaseResponse, error) {
+	counselingCase, err := s.repo.FindCounselingCaseByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	if counselingCase.IsClosed() {
+		return nil, ErrCaseClosed
+	}
+
+	if title := strings.TrimSpace(req.Title); title != "" {
+		counselingCase.Title = title
+	}
+	if req.Description != nil {
+		counselingCase.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Status != "" {
+		if req.Status != models.CounselingCaseStatusOpen && req.Status != models.CounselingCaseStatusInProgress {
+			return nil, ErrInvalidCaseStatus
+		}
+		counselingCase.Status = req.Status
+	}
+	if req.CounselorID != nil {
+		if err := s.checkCounselor(ctx, schoolID, *req.CounselorID); err != nil {
+			return nil, err
+		}
+		counselingCase.CounselorID = req.CounselorID
+	}
+
+	var violations *[]models.Violation
+	var permits *[]models.Permit
+	if req.ViolationIDs != nil {
+		found, _, err := s.findCaseLinks(ctx, counselingCase.StudentID, *req.ViolationIDs, nil)
+		if err != nil {
+			return nil, err
+		}
+		violations = &found
+	}
+	if req.PermitIDs != nil {
+		_, found, err := s.findCaseLinks(ctx, counselingCase.StudentID, nil, *req.PermitIDs)
+		if err != nil {
+			return nil, err
+		}
+		permits = &found
+	}
+
+	if err := s.repo.UpdateCounselingCase(ctx, counselingCase, violations, permits); err != nil {
+		return nil, err
+	}
+
+	return s.writtenCounselingCase(ctx, schoolID, id)
+}
+
+// CloseCounselingCase resolves or refers a case with its outcome
+// Sessions still scheduled are cancelled; open follow-ups stay on record but are no longer reminded.
+func (s *service) CloseCounselingCase(ctx context.Context, schoolID, userID, id uint, req CloseCounselingCaseRequest) (*CounselingCaseResponse, error) {
+	if !req.Status.IsClosed() {
+		return nil, ErrInvalidCaseClosure
+	}
+	outcome := strings.TrimSpace(req.Outcome)
+	if outcome == "" {
+		return nil, ErrCaseOutcomeRequired
+	}
+	referredTo := strings.TrimSpace(req.ReferredTo)
+	if req.Status == models.CounselingCaseStatusReferred && referredTo == "" {
+		return nil, ErrReferredToRequired
+	}
+	if req.Status == models.CounselingCaseStatusResolved {
+		referredTo = ""
+	}
+
+	counselingCase, err := s.repo.FindCounselingCaseByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	if counselingCase.IsClosed() {
+		return nil, ErrCaseClosed
+	}
+
+	now := time.Now()
+	counselingCase.Status = req.Status
+	counselingCase.Outcome = outcome
+	counselingCase.ReferredTo = referredTo
+	counselingCase.ClosedBy = &userID
+	counselingCase.ClosedAt = &now
+	if err := s.repo.CloseCounselingCase(ctx, counselingCase); err != nil {
+		return nil, err
+	}
+
+	return s.writtenCounselingCase(ctx, schoolID, id)
+}
+
+// ReopenCounselingCase puts a closed case back in progress, keeping its last outcome until it is closed again
+func (s *service) ReopenCounselingCase(ctx context.Context, schoolID, id uint) (*CounselingCaseResponse, error) {
+	counselingCase, err := s.repo.FindCounselingCaseByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !counselingCase.IsClosed() {
+		return nil, ErrCaseNotClosed
+	}
+
+	counselingCase.Status = models.CounselingCaseStatusInProgress
+	counselingCase.ClosedBy = nil
+	counselingCase.ClosedAt = nil
+	if err := s.repo.UpdateCounselingCase(ctx, counselingCase, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return s.writtenCounselingCase(ctx, schoolID, id)
+}
+
+// checkCounselor verifies that a user can handle cases of the school
+func (s *service) checkCounselor(ctx context.Context, schoolID, userID uint) error {
+	user, err := s.repo.FindUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrInvalidCounselor
+		}
+		return err
+	}
+	if user.Role != models.RoleGuruBK || !user.IsActive || user.SchoolID == nil || *user.SchoolID != schoolID {
+		return ErrInvalidCounselor
+	}
+	return nil
+}
+
+// findCaseLinks loads the violations and permits to link, all of which must belong to the student
+func (s *service) findCaseLinks(ctx context.Context, studentID uint, violationIDs, permitIDs []uint) ([]models.Violation, []models.Permit, error) {
+	violationIDs = uniqueIDs(violationIDs)
+	violations, err := s.repo.FindStudentViolationsByIDs(ctx, studentID, violationIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(violations) != len(violationIDs) {
+		return nil, nil, ErrCaseViolationMismatch
+	}
+
+	permitIDs = uniqueIDs(permitIDs)
+	permits, err := s.repo.FindStudentPermitsByIDs(ctx, studentID, permitIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(permits) != len(permitIDs) {
+		return nil, nil, ErrCasePermitMismatch
+	}
+
+	return violations, permits, nil
+}
+
+// uniqueIDs drops repeated IDs, keeping their order
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// ==================== Counseling Session Service ====================
+
+// ScheduleCounselingSession schedules a session of a case
+func (s *service) ScheduleCounselingSession(ctx context.Context, schoolID, userID, caseID uint, req CreateCounselingSessionRequest) (*CounselingSessionResponse, error) {
+	if req.ScheduledAt.IsZero() {
+		return nil, ErrSessionTimeRequired
+	}
+	duration := req.DurationMinutes
+	if duration == 0 {
+		duration = DefaultSessionMinutes
+	}
+	if duration < 0 || duration > 240 {
+		return nil, ErrInvalidSessionDuration
+	}
+
+	counselingCase, err := s.repo.FindCounselingCaseByID(ctx, schoolID, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if counselingCase.IsClosed() {
+		return nil, ErrCaseClosed
+	}
+
+	session := &models.CounselingSession{
+		CaseID:          caseID,
+		ScheduledAt:     req.ScheduledAt,
+		DurationMinutes: duration,
+		Location:        strings.TrimSpace(req.Location),
+		Status:          models.CounselingSessionStatusScheduled,
+		CreatedBy:       userID,
+	}
+	if err := s.repo.CreateCounselingSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	session, err = s.repo.FindCounselingSessionByID(ctx, caseID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toCounselingSessionResponse(session, true), nil
+}
+
+// UpdateCounselingSession reschedules a session or records how it went
+// Recording a held session requires the internal note and moves an open case to in_progress.
+func (s *service) UpdateCounselingSession(ctx context.Context, schoolID, caseID, sessionID uint, req UpdateCounselingSessionRequest) (*CounselingSessionResponse, error) {
+	counselingCase, err := s.repo.FindCounselingCaseByID(ctx, schoolID, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if counselingCase.IsClosed() {
+		return nil, ErrCaseClosed
+	}
+
+	session, err := s.repo.FindCounselingSessionByID(ctx, caseID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ScheduledAt != nil {
+		if req.ScheduledAt.IsZero() {
+			return nil, ErrSessionTimeRequired
+		}
+		session.ScheduledAt = *req.ScheduledAt
+	}
+	if req.DurationMinutes != nil {
+		if *req.DurationMinutes <= 0 || *req.DurationMinutes > 240 {
+			return nil, ErrInvalidSessionDuration
+		}
+		session.DurationMinutes = *req.DurationMinutes
+	}
+	if req.Location != nil {
+		session.Location = strings.TrimSpace(*req.Location)
+	}
+	if req.InternalNote != nil {
+		session.InternalNote = strings.TrimSpace(*req.InternalNote)
+	}
+	if req.ParentSummary != nil {
+		session.ParentSummary = strings.TrimSpace(*req.ParentSummary)
+	}
+	if req.Status != "" {
+		if !req.Status.IsValid() {
+			return nil, ErrInvalidSessionStatus
+		}
+		session.Status = req.Status
+	}
+
+	if session.Status == models.CounselingSessionStatusHeld {
+		if session.InternalNote == "" {
+			return nil, ErrInternalNoteRequired
+		}
+		if session.HeldAt == nil {
+			heldAt := time.Now()
+			if session.ScheduledAt.Before(heldAt) {
+				heldAt = session.ScheduledAt
+			}
+			session.HeldAt = &heldAt
+		}
+	} else {
+		session.HeldAt = nil
+	}
+
+	if err := s.repo.UpdateCounselingSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return toCounselingSessionResponse(session, true), nil
+}
+
+// ==================== Counseling Follow-up Service ====================
+
+// AddCounselingFollowUp adds a dated follow-up to a case
+// The assignee, by default the case counselor, is reminded once the follow-up falls due.
+func (s *service) AddCounselingFollowUp(ctx context.Context, schoolID, userID, caseID uint, req CreateCounselingFollowUpRequest) (*CounselingFollowUpResponse, error) {
+	if req.DueAt.IsZero() {
+		return nil, ErrFollowUpDueRequired
+	}
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, ErrFollowUpNoteRequired
+	}
+
+	counselingCase, err := s.repo.FindCounselingCaseByID(ctx, schoolID, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if counselingCase.IsClosed() {
+		return nil, ErrCaseClosed
+	}
+
+	assignee := userID
+	if req.AssignedTo != nil {
+		assignee = *req.AssignedTo
+	} else if counselingCase.CounselorID != nil {
+		assignee = *counselingCase.CounselorID
+	}
+	if err := s.checkAssignee(ctx, schoolID, assignee); err != nil {
+		return nil, err
+	}
+
+	followUp := &models.CounselingFollowUp{
+		CaseID:     caseID,
+		DueAt:      req.DueAt,
+		Note:       note,
+		AssignedTo: assignee,
+		CreatedBy:  userID,
+	}
+	if err := s.repo.CreateCounselingFollowUp(ctx, followUp); err != nil {
+		return nil, err
+	}
+
+	followUp, err = s.repo.FindCounselingFollowUpByID(ctx, caseID, followUp.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toCounselingFollowUpResponse(followUp, time.Now()), nil
+}
+
+// CompleteCounselingFollowUp marks a follow-up of a case as done
+func (s *service) CompleteCounselingFollowUp(ctx context.Context, schoolID, userID, caseID, followUpID uint) (*CounselingFollowUpResponse, error) {
+	if _, err := s.repo.FindCounselingCaseByID(ctx, schoolID, caseID); err != nil {
+		return nil, err
+	}
+
+	followUp, err := s.repo.FindCounselingFollowUpByID(ctx, caseID, followUpID)
+	if err != nil {
+		return nil, err
+	}
+	if followUp.IsCompleted() {
+		return nil, ErrFollowUpAlreadyDone
+	}
+
+	now := time.Now()
+	followUp.CompletedBy = &userID
+	followUp.CompletedAt = &now
+	if err := s.repo.CompleteCounselingFollowUp(ctx, followUp); err != nil {
+		return nil, err
+	}
+
+	followUp, err = s.repo.FindCounselingFollowUpByID(ctx, caseID, followUpID)
+	if err != nil {
+		return nil, err
+	}
+	return toCounselingFollowUpResponse(followUp, now), nil
+}
+
+// checkAssignee verifies that a follow-up can be assigned to a user: any active staff member of the school
+func (s *service) checkAssignee(ctx context.Context, schoolID, userID uint) error {
+	user, err := s.repo.FindUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrInvalidAssignee
+		}
+		return err
+	}
+	if !user.IsActive || user.SchoolID == nil || *user.SchoolID != schoolID {
+		return ErrInvalidAssignee
+	}
+	switch user.Role {
+	case models.RoleAdminSekolah, models.RoleGuruBK, models.RoleWaliKelas, models.RoleGuru:
+		return nil
+	}
+	return ErrInvalidAssignee
+}
+
+// ==================== Response Converters ====================
+
+// toCounselingCaseResponse converts a case for lists, summarising its sessions and follow-ups
+func toCounselingCaseResponse(c *models.CounselingCase, includeInternal bool, now time.Time) *CounselingCaseResponse {
+	response := &CounselingCaseResponse{
+		ID:          c.ID,
+		StudentID:   c.StudentID,
+		StudentName: c.Student.Name,
+		StudentNIS:  c.Student.NIS,
+		ClassID:     c.ClassID,
+		Title:       c.Title,
+		Status:      c.Status,
+		CounselorID: c.CounselorID,
+		OpenedBy:    c.OpenedBy,
+		Outcome:     c.Outcome,
+		ReferredTo:  c.ReferredTo,
+		ClosedBy:    c.ClosedBy,
+		ClosedAt:    c.ClosedAt,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+	if includeInternal {
+		response.Description = c.Description
+	}
+	if c.Class != nil {
+		response.ClassName = c.Class.Name
+	}
+	if c.Counselor != nil {
+		response.CounselorName = userDisplayName(c.Counselor)
+	}
+	if c.Opener.ID != 0 {
+		response.OpenerName = userDisplayName(&c.Opener)
+	}
+	if c.Closer != nil {
+		response.CloserName = userDisplayName(c.Closer)
+	}
+
+	for i := range c.Sessions {
+		session := &c.Sessions[i]
+		switch {
+		case session.Status == models.CounselingSessionStatusHeld:
+			response.SessionCount++
+		case session.Status == models.CounselingSessionStatusScheduled && session.ScheduledAt.After(now):
+			if response.NextSessionAt == nil || session.ScheduledAt.Before(*response.NextSessionAt) {
+				next := session.ScheduledAt
+				response.NextSessionAt = &next
+			}
+		}
+	}
+	for i := range c.FollowUps {
+		if !c.FollowUps[i].IsCompleted() {
+			response.PendingFollowUps++
+		}
+	}
+	return response
+}
+
+// toCounselingCaseDetailResponse converts a case with its sessions, follow-ups and linked records
+func toCounselingCaseDetailResponse(c *models.CounselingCase, includeInternal bool, now time.Time) *CounselingCaseResponse {
+	response := toCounselingCaseResponse(c, includeInternal, now)
+
+	response.Sessions = make([]CounselingSessionResponse, len(c.Sessions))
+	for i := range c.Sessions {
+		response.Sessions[i] = *toCounselingSessionResponse(&c.Sessions[i], includeInternal)
+	}
+	response.FollowUps = make([]CounselingFollowUpResponse, len(c.FollowUps))
+	for i := range c.FollowUps {
+		response.FollowUps[i] = *toCounselingFollowUpResponse(&c.FollowUps[i], now)
+	}
+	response.Violations = make([]ViolationResponse, len(c.Violations))
+	for i := range c.Violations {
+		response.Violations[i] = *toViolationResponse(&c.Violations[i])
+	}
+	response.Permits = make([]PermitResponse, len(c.Permits))
+	for i := range c.Permits {
+		response.Permits[i] = *toPermitResponse(&c.Permits[i])
+	}
+	return response
+}
+
+func toCounselingSessionResponse(s *models.CounselingSession, includeInternal bool) *CounselingSessionResponse {
+	response := &CounselingSessionResponse{
+		ID:              s.ID,
+		CaseID:          s.CaseID,
+		ScheduledAt:     s.ScheduledAt,
+		DurationMinutes: s.DurationMinutes,
+		Location:        s.Location,
+		Status:          s.Status,
+		ParentSummary:   s.ParentSummary,
+		HeldAt:          s.HeldAt,
+		CreatedBy:       s.CreatedBy,
+		CreatedAt:       s.CreatedAt,
+	}
+	if includeInternal {
+		response.InternalNote = s.InternalNote
+	}
+	if s.Creator.ID != 0 {
+		response.CreatorName = userDisplayName(&s.Creator)
+	}
+	return response
+}
+
+func toCounselingFollowUpResponse(f *models.CounselingFollowUp, now time.Time) *CounselingFollowUpResponse {
+	response := &CounselingFollowUpResponse{
+		ID:             f.ID,
+		CaseID:         f.CaseID,
+		DueAt:          f.DueAt,
+		Note:           f.Note,
+		AssignedTo:     f.AssignedTo,
+		IsOverdue:      !f.IsCompleted() && f.DueAt.Before(now),
+		ReminderSentAt: f.ReminderSentAt,
+		CompletedBy:    f.CompletedBy,
+		CompletedAt:    f.CompletedAt,
+		CreatedAt:      f.CreatedAt,
+	}
+	if f.Assignee.ID != 0 {
+		response.AssigneeName = userDisplayName(&f.Assignee)
+	}
+	if f.Completer != nil {
+		response.CompleterName = userDisplayName(f.Completer)
+	}
+	return response
+}
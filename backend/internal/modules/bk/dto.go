@@ -294,6 +294,228 @@ type CounselingNoteFilter struct {
 	PageSize  int     `query:"page_size"`
 }
 
+// ==================== Violation Threshold DTOs ====================
+
+// CreateViolationThresholdRequest represents the request to create a violation point threshold
+type CreateViolationThresholdRequest struct {
+	Points      int                     `json:"points" validate:"required,min=1"` // Accumulated violation points, e.g. 50
+	Name        string                  `json:"name" validate:"required"`
+	Action      models.EscalationAction `json:"action" validate:"required"` // parent_call, summons_letter, suspension_review
+	Description string                  `json:"description"`                // Printed in the letter
+}
+
+// UpdateViolationThresholdRequest represents the request to update a violation point threshold
+type UpdateViolationThresholdRequest struct {
+	Points      *int                    `json:"points"`
+	Name        string                  `json:"name"`
+	Action      models.EscalationAction `json:"action"`
+	Description *string                 `json:"description"`
+	IsActive    *bool                   `json:"is_active"`
+}
+
+// ViolationThresholdResponse represents a violation point threshold in responses
+type ViolationThresholdResponse struct {
+	ID          uint                    `json:"id"`
+	SchoolID    uint                    `json:"school_id"`
+	Points      int                     `json:"points"`
+	Name        string                  `json:"name"`
+	Action      models.EscalationAction `json:"action"`
+	Description string                  `json:"description"`
+	IsActive    bool                    `json:"is_active"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+// ViolationThresholdListResponse represents a list of violation point thresholds
+type ViolationThresholdListResponse struct {
+	Thresholds []ViolationThresholdResponse `json:"thresholds"`
+}
+
+// ==================== Escalation DTOs ====================
+
+// CompleteEscalationRequest represents the counselor closing the follow-up of an escalation
+type CompleteEscalationRequest struct {
+	Note string `json:"note" validate:"required"` // Outcome of the follow-up, e.g. result of the parent meeting
+}
+
+// EscalationResponse represents a crossed violation point threshold in responses
+type EscalationResponse struct {
+	ID              uint                    `json:"id"`
+	StudentID       uint                    `json:"student_id"`
+	StudentName     string                  `json:"student_name,omitempty"`
+	StudentNIS      string                  `json:"student_nis,omitempty"`
+	ClassID         *uint                   `json:"class_id,omitempty"`
+	ClassName       string                  `json:"class_name,omitempty"`
+	ViolationID     uint                    `json:"violation_id"`
+	ThresholdID     uint                    `json:"threshold_id"`
+	ThresholdPoints int                     `json:"threshold_points"`
+	ThresholdName   string                  `json:"threshold_name"`
+	Action          models.EscalationAction `json:"action"`
+	TotalPoints     int                     `json:"total_points"`
+	LetterNumber    string                  `json:"letter_number"`
+	LetterTitle     string                  `json:"letter_title"`
+	CounselorID     *uint                   `json:"counselor_id,omitempty"`
+	CounselorName   string                  `json:"counselor_name,omitempty"`
+	Status          models.EscalationStatus `json:"status"`
+	FollowUpNote    string                  `json:"follow_up_note,omitempty"`
+	CompletedBy     *uint                   `json:"completed_by,omitempty"`
+	CompleterName   string                  `json:"completer_name,omitempty"`
+	CompletedAt     *time.Time              `json:"completed_at,omitempty"`
+	CreatedAt       time.Time               `json:"created_at"`
+}
+
+// EscalationListResponse represents a paginated list of escalations
+type EscalationListResponse struct {
+	Escalations []EscalationResponse `json:"escalations"`
+	Pagination  PaginationMeta       `json:"pagination"`
+}
+
+// EscalationFilter represents filter options for listing escalations
+type EscalationFilter struct {
+	StudentID   *uint   `query:"student_id"`
+	ClassID     *uint   `query:"class_id"`
+	CounselorID *uint   `query:"counselor_id"`
+	Status      *string `query:"status"` // open or completed
+	Action      *string `query:"action"`
+	Page        int     `query:"page"`
+	PageSize    int     `query:"page_size"`
+}
+
+// ==================== Counseling Case DTOs ====================
+
+// CreateCounselingCaseRequest represents the request to open a counseling case
+type CreateCounselingCaseRequest struct {
+	StudentID    uint   `json:"student_id" validate:"required"`
+	Title        string `json:"title" validate:"required"`
+	Description  string `json:"description"`   // Internal to Guru BK
+	CounselorID  *uint  `json:"counselor_id"`  // Defaults to the user opening the case
+	ViolationIDs []uint `json:"violation_ids"` // Violations that triggered the case
+	PermitIDs    []uint `json:"permit_ids"`    // Exit permits that triggered the case
+}
+
+// UpdateCounselingCaseRequest represents the request to update a case that is not closed
+// Sending violation_ids or permit_ids replaces the linked records.
+type UpdateCounselingCaseRequest struct {
+	Title        string                      `json:"title"`
+	Description  *string                     `json:"description"`
+	Status       models.CounselingCaseStatus `json:"status"` // open or in_progress; closing goes through /close
+	CounselorID  *uint                       `json:"counselor_id"`
+	ViolationIDs *[]uint                     `json:"violation_ids"`
+	PermitIDs    *[]uint                     `json:"permit_ids"`
+}
+
+// CloseCounselingCaseRequest represents the request to close a case with its outcome
+type CloseCounselingCaseRequest struct {
+	Status     models.CounselingCaseStatus `json:"status" validate:"required"` // resolved or referred
+	Outcome    string                      `json:"outcome" validate:"required"`
+	ReferredTo string                      `json:"referred_to"` // Required when referred, e.g. "Psikolog Puskesmas"
+}
+
+// CreateCounselingSessionRequest represents the request to schedule a session of a case
+type CreateCounselingSessionRequest struct {
+	ScheduledAt     time.Time `json:"scheduled_at" validate:"required"`
+	DurationMinutes int       `json:"duration_minutes"` // Defaults to 45
+	Location        string    `json:"location"`
+}
+
+// UpdateCounselingSessionRequest represents the request to reschedule a session or record how it went
+type UpdateCounselingSessionRequest struct {
+	ScheduledAt     *time.Time                     `json:"scheduled_at"`
+	DurationMinutes *int                           `json:"duration_minutes"`
+	Location        *string                        `json:"location"`
+	Status          models.CounselingSessionStatus `json:"status"`        // held, cancelled, missed or scheduled
+	InternalNote    *string                        `json:"internal_note"` // Required when held
+	ParentSummary   *string                        `json:"parent_summary"`
+}
+
+// CreateCounselingFollowUpRequest represents the request to add a follow-up to a case
+type CreateCounselingFollowUpRequest struct {
+	DueAt      time.Time `json:"due_at" validate:"required"`
+	Note       string    `json:"note" validate:"required"`
+	AssignedTo *uint     `json:"assigned_to"` // Defaults to the case counselor
+}
+
+// CounselingSessionResponse represents a counseling session in responses
+// InternalNote is only filled for Guru BK.
+type CounselingSessionResponse struct {
+	ID              uint                           `json:"id"`
+	CaseID          uint                           `json:"case_id"`
+	ScheduledAt     time.Time                      `json:"scheduled_at"`
+	DurationMinutes int                            `json:"duration_minutes"`
+	Location        string                         `json:"location"`
+	Status          models.CounselingSessionStatus `json:"status"`
+	InternalNote    string                         `json:"internal_note,omitempty"`
+	ParentSummary   string                         `json:"parent_summary"`
+	HeldAt          *time.Time                     `json:"held_at"`
+	CreatedBy       uint                           `json:"created_by"`
+	CreatorName     string                         `json:"creator_name,omitempty"`
+	CreatedAt       time.Time                      `json:"created_at"`
+}
+
+// CounselingFollowUpResponse represents a follow-up of a case in responses
+type CounselingFollowUpResponse struct {
+	ID             uint       `json:"id"`
+	CaseID         uint       `json:"case_id"`
+	DueAt          time.Time  `json:"due_at"`
+	Note           string     `json:"note"`
+	AssignedTo     uint       `json:"assigned_to"`
+	AssigneeName   string     `json:"assignee_name,omitempty"`
+	IsOverdue      bool       `json:"is_overdue"`
+	ReminderSentAt *time.Time `json:"reminder_sent_at"`
+	CompletedBy    *uint      `json:"completed_by"`
+	CompleterName  string     `json:"completer_name,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CounselingCaseResponse represents a counseling case in responses
+// Sessions, follow-ups and linked records are only included when getting a single case.
+type CounselingCaseResponse struct {
+	ID               uint                         `json:"id"`
+	StudentID        uint                         `json:"student_id"`
+	StudentName      string                       `json:"student_name,omitempty"`
+	StudentNIS       string                       `json:"student_nis,omitempty"`
+	ClassID          *uint                        `json:"class_id"`
+	ClassName        string                       `json:"class_name,omitempty"`
+	Title            string                       `json:"title"`
+	Description      string                       `json:"description,omitempty"`
+	Status           models.CounselingCaseStatus  `json:"status"`
+	CounselorID      *uint                        `json:"counselor_id"`
+	CounselorName    string                       `json:"counselor_name,omitempty"`
+	OpenedBy         uint                         `json:"opened_by"`
+	OpenerName       string                       `json:"opener_name,omitempty"`
+	Outcome          string                       `json:"outcome"`
+	ReferredTo       string                       `json:"referred_to,omitempty"`
+	ClosedBy         *uint                        `json:"closed_by"`
+	CloserName       string                       `json:"closer_name,omitempty"`
+	ClosedAt         *time.Time                   `json:"closed_at"`
+	SessionCount     int                          `json:"session_count"` // Sessions held
+	NextSessionAt    *time.Time                   `json:"next_session_at"`
+	PendingFollowUps int                          `json:"pending_follow_ups"`
+	Sessions         []CounselingSessionResponse  `json:"sessions,omitempty"`
+	FollowUps        []CounselingFollowUpResponse `json:"follow_ups,omitempty"`
+	Violations       []ViolationResponse          `json:"violations,omitempty"`
+	Permits          []PermitResponse             `json:"permits,omitempty"`
+	CreatedAt        time.Time                    `json:"created_at"`
+	UpdatedAt        time.Time                    `json:"updated_at"`
+}
+
+// CounselingCaseListResponse represents a paginated list of counseling cases
+type CounselingCaseListResponse struct {
+	Cases      []CounselingCaseResponse `json:"cases"`
+	Pagination PaginationMeta           `json:"pagination"`
+}
+
+// CounselingCaseFilter represents filter options for listing counseling cases
+type CounselingCaseFilter struct {
+	StudentID   *uint   `query:"student_id"`
+	ClassID     *uint   `query:"class_id"`
+	CounselorID *uint   `query:"counselor_id"`
+	Status      *string `query:"status"` // open, in_progress, resolved, referred or "active" for open and in_progress
+	Page        int     `query:"page"`
+	PageSize    int     `query:"page_size"`
+}
+
 // ==================== Student BK Profile DTOs ====================
 
 // StudentBKProfileResponse represents a student's complete BK profile
@@ -313,6 +535,8 @@ type StudentBKProfileResponse struct {
 	RecentAchievements []AchievementResponse       `json:"recent_achievements,omitempty"`
 	RecentPermits     []PermitResponse             `json:"recent_permits,omitempty"`
 	RecentCounseling  []CounselingNoteResponse     `json:"recent_counseling,omitempty"`
+	ViolationPoints   int                          `json:"violation_points"` // Accumulated, counted against the thresholds
+	Escalations       []EscalationResponse         `json:"escalations"`
 }
 
 // StudentBKProfileFullResponse includes internal counseling notes (for Guru BK)
@@ -331,6 +555,8 @@ type StudentBKProfileFullResponse struct {
 	RecentAchievements []AchievementResponse       `json:"recent_achievements,omitempty"`
 	RecentPermits     []PermitResponse             `json:"recent_permits,omitempty"`
 	RecentCounseling  []CounselingNoteFullResponse `json:"recent_counseling,omitempty"`
+	ViolationPoints   int                          `json:"violation_points"` // Accumulated, counted against the thresholds
+	Escalations       []EscalationResponse         `json:"escalations"`
 }
 
 // ==================== Dashboard DTOs ====================
@@ -343,9 +569,16 @@ type BKDashboardResponse struct {
 	TotalPermits       int                   `json:"total_permits"`
 	ActivePermits      int                   `json:"active_permits"`
 	TotalCounseling    int                   `json:"total_counseling"`
+	OpenCases          int                   `json:"open_cases"`
+	InProgressCases    int                   `json:"in_progress_cases"`
+	ResolvedCases      int                   `json:"resolved_cases"`
+	ReferredCases      int                   `json:"referred_cases"`
+	OverdueFollowUps   int                   `json:"overdue_follow_ups"`
+	UpcomingSessions   int                   `json:"upcoming_sessions"` // Scheduled in the next 7 days
 	RecentViolations   []ViolationResponse   `json:"recent_violations"`
 	RecentAchievements []AchievementResponse `json:"recent_achievements"`
 	StudentsNeedingAttention []StudentAttentionItem `json:"students_needing_attention"`
+	RecentCaseOutcomes []CounselingCaseResponse `json:"recent_case_outcomes"`
 }
 
 // StudentAttentionItem represents a student that needs attention
@@ -0,0 +1,328 @@
+package bk
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ==================== Violation Thresholds ====================
+
+// GetViolationThresholds handles listing violation point thresholds
+// @Summary List violation thresholds
+// @Description Get the school's accumulated violation point thresholds, lowest first
+// @Tags BK - Escalations
+// @Produce json
+// @Param active_only query bool false "Filter active thresholds only"
+// @Success 200 {object} ViolationThresholdListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/violation-thresholds [get]
+func (h *Handler) GetViolationThresholds(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	activeOnly := c.Query("active_only", "false") == "true"
+
+	response, err := h.service.GetViolationThresholds(c.Context(), schoolID, activeOnly)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// CreateViolationThreshold handles creating a violation point threshold
+// @Summary Create violation threshold
+// @Description Create a threshold of accumulated violation points, e.g. a parent call at 25, a summons letter at 50 and suspension review at 75. Crossing it creates a follow-up task for the class counselor, notifies the parents and generates a letter.
+// @Tags BK - Escalations
+// @Accept json
+// @Produce json
+// @Param request body CreateViolationThresholdRequest true "Threshold data"
+// @Success 201 {object} ViolationThresholdResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/violation-thresholds [post]
+func (h *Handler) CreateViolationThreshold(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	var req CreateViolationThresholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.CreateViolationThreshold(c.Context(), schoolID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Ambang poin pelanggaran berhasil dibuat",
+	})
+}
+
+// UpdateViolationThreshold handles updating a violation point threshold
+// @Summary Update violation threshold
+// @Description Update a violation point threshold; escalations already recorded are not changed
+// @Tags BK - Escalations
+// @Accept json
+// @Produce json
+// @Param id path int true "Threshold ID"
+// @Param request body UpdateViolationThresholdRequest true "Threshold data"
+// @Success 200 {object} ViolationThresholdResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/violation-thresholds/{id} [put]
+func (h *Handler) UpdateViolationThreshold(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "threshold")
+	}
+
+	var req UpdateViolationThresholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.UpdateViolationThreshold(c.Context(), schoolID, uint(id), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Ambang poin pelanggaran berhasil diperbarui",
+	})
+}
+
+// DeleteViolationThreshold handles deleting a violation point threshold
+// @Summary Delete violation threshold
+// @Description Delete a violation point threshold; its escalation history is kept
+// @Tags BK - Escalations
+// @Produce json
+// @Param id path int true "Threshold ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/violation-thresholds/{id} [delete]
+func (h *Handler) DeleteViolationThreshold(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "threshold")
+	}
+
+	if err := h.service.DeleteViolationThreshold(c.Context(), schoolID, uint(id)); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Ambang poin pelanggaran berhasil dihapus",
+	})
+}
+
+// ==================== Escalations ====================
+
+// GetEscalations handles listing escalations
+// @Summary List escalations
+// @Description Get the thresholds students have crossed with their follow-up tasks
+// @Tags BK - Escalations
+// @Produce json
+// @Param student_id query int false "Filter by student ID"
+// @Param class_id query int false "Filter by class ID"
+// @Param counselor_id query int false "Filter by assigned counselor ID"
+// @Param status query string false "Filter by follow-up status (open, completed)"
+// @Param action query string false "Filter by action (parent_call, summons_letter, suspension_review)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} EscalationListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/escalations [get]
+func (h *Handler) GetEscalations(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	var filter EscalationFilter
+	if studentID, err := strconv.ParseUint(c.Query("student_id"), 10, 32); err == nil {
+		id := uint(studentID)
+		filter.StudentID = &id
+	}
+	if classID, err := strconv.ParseUint(c.Query("class_id"), 10, 32); err == nil {
+		id := uint(classID)
+		filter.ClassID = &id
+	}
+	if counselorID, err := strconv.ParseUint(c.Query("counselor_id"), 10, 32); err == nil {
+		id := uint(counselorID)
+		filter.CounselorID = &id
+	}
+	if status := c.Query("status"); status != "" {
+		filter.Status = &status
+	}
+	if action := c.Query("action"); action != "" {
+		filter.Action = &action
+	}
+	filter.Page, _ = strconv.Atoi(c.Query("page", "1"))
+	filter.PageSize, _ = strconv.Atoi(c.Query("page_size", "20"))
+
+	response, err := h.service.GetEscalations(c.Context(), schoolID, filter)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetEscalationByID handles getting a single escalation
+// @Summary Get escalation by ID
+// @Description Get a crossed threshold with its follow-up task
+// @Tags BK - Escalations
+// @Produce json
+// @Param id path int true "Escalation ID"
+// @Success 200 {object} EscalationResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/escalations/{id} [get]
+func (h *Handler) GetEscalationByID(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "escalation")
+	}
+
+	response, err := h.service.GetEscalationByID(c.Context(), schoolID, uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// CompleteEscalation handles closing the follow-up task of an escalation
+// @Summary Complete escalation follow-up
+// @Description Close the counselor's follow-up task of an escalation with its outcome
+// @Tags BK - Escalations
+// @Accept json
+// @Produce json
+// @Param id path int true "Escalation ID"
+// @Param request body CompleteEscalationRequest true "Follow-up outcome"
+// @Success 200 {object} EscalationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/escalations/{id}/complete [post]
+func (h *Handler) CompleteEscalation(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "escalation")
+	}
+
+	var req CompleteEscalationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.CompleteEscalation(c.Context(), schoolID, userID, uint(id), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Tindak lanjut eskalasi selesai",
+	})
+}
+
+// GetEscalationLetter handles downloading the letter of an escalation
+// @Summary Download escalation letter
+// @Description Download the letter to the parents generated when the student crossed the threshold
+// @Tags BK - Escalations
+// @Produce application/pdf
+// @Param id path int true "Escalation ID"
+// @Success 200 {file} binary
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/bk/escalations/{id}/letter [get]
+func (h *Handler) GetEscalationLetter(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "escalation")
+	}
+
+	content, fileName, err := h.service.GetEscalationLetter(c.Context(), schoolID, uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", "attachment; filename="+fileName)
+
+	return c.Send(content)
+}
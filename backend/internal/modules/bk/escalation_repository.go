@@ -0,0 +1,251 @@
+package bk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/outbox"
+)
+
+var (
+	ErrViolationThresholdNotFound = errors.New("ambang poin pelanggaran tidak ditemukan")
+	ErrEscalationNotFound         = errors.New("eskalasi pelanggaran tidak ditemukan")
+)
+
+// ==================== Violation Threshold Repository ====================
+
+// CreateViolationThreshold creates a new violation point threshold
+func (r *repository) CreateViolationThreshold(ctx context.Context, threshold *models.ViolationThreshold) error {
+	return r.db.WithContext(ctx).Create(threshold).Error
+}
+
+// FindViolationThresholdByID retrieves a threshold of a school
+func (r *repository) FindViolationThresholdByID(ctx context.Context, schoolID, id uint) (*models.ViolationThreshold, error) {
+	var threshold models.ViolationThreshold
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		First(&threshold).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrViolationThresholdNotFound
+		}
+		return nil, err
+	}
+	return &threshold, nil
+}
+
+// FindViolationThresholds retrieves the thresholds of a school, lowest first
+func (r *repository) FindViolationThresholds(ctx context.Context, schoolID uint, activeOnly bool) ([]models.ViolationThreshold, error) {
+	var thresholds []models.ViolationThreshold
+	query := r.db.WithContext(ctx).Where("school_id = ?", schoolID)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("points ASC").Find(&thresholds).Error
+	return thresholds, err
+}
+
+// ViolationThresholdPointsExist checks if another threshold of the school uses the same points
+func (r *repository) ViolationThresholdPointsExist(ctx context.Context, schoolID uint, points int, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.ViolationThreshold{}).
+		Where("school_id = ? AND points = ? AND id <> ?", schoolID, points, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateViolationThreshold updates a threshold
+func (r *repository) UpdateViolationThreshold(ctx context.Context, threshold *models.ViolationThreshold) error {
+	return r.db.WithContext(ctx).Save(threshold).Error
+}
+
+// DeleteViolationThreshold deletes a threshold; escalations it triggered keep their copy of it
+func (r *repository) DeleteViolationThreshold(ctx context.Context, schoolID, id uint) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		Delete(&models.ViolationThreshold{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrViolationThresholdNotFound
+	}
+	return nil
+}
+
+// ==================== Escalation Repository ====================
+
+// FindEscalatedThresholdIDs retrieves the thresholds a student has already crossed
+func (r *repository) FindEscalatedThresholdIDs(ctx context.Context, studentID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.ViolationEscalation{}).
+		Where("student_id = ?", studentID).
+		Pluck("threshold_id", &ids).Error
+	return ids, err
+}
+
+// FindClassCounselorIDs retrieves the BK teachers assigned to a class, first assigned first
+func (r *repository) FindClassCounselorIDs(ctx context.Context, classID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.ClassCounselor{}).
+		Where("class_id = ?", classID).
+		Order("id ASC").
+		Pluck("counselor_id", &ids).Error
+	return ids, err
+}
+
+// FindSchoolCounselorIDs retrieves the active BK teachers of a school
+func (r *repository) FindSchoolCounselorIDs(ctx context.Context, schoolID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("school_id = ? AND role = ? AND is_active = ?", schoolID, models.RoleGuruBK, true).
+		Order("id ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// CreateEscalation records a crossed threshold, numbers its letter and writes its events in one transaction
+// It returns false without writing anything when the student already crossed the threshold.
+func (r *repository) CreateEscalation(ctx context.Context, escalation *models.ViolationEscalation, events ...*outbox.Event) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Letter numbers run per school and year; the school row serialises numbering
+		var school models.School
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&school, escalation.SchoolID).Error; err != nil {
+			return err
+		}
+
+		var exists int64
+		if err := tx.Model(&models.ViolationEscalation{}).
+			Where("student_id = ? AND threshold_id = ?", escalation.StudentID, escalation.ThresholdID).
+			Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists > 0 {
+			return nil
+		}
+
+		now := time.Now()
+		yearStart := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+		var issued int64
+		if err := tx.Model(&models.ViolationEscalation{}).
+			Where("school_id = ? AND created_at >= ?", escalation.SchoolID, yearStart).
+			Count(&issued).Error; err != nil {
+			return err
+		}
+		escalation.LetterNumber = letterNumber(int(issued)+1, now)
+
+		if err := tx.Omit("Student", "Class", "Counselor", "Completer").Create(escalation).Error; err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := outbox.Append(tx, escalation.ID, event); err != nil {
+				return err
+			}
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// FindEscalationByID retrieves an escalation of a school
+func (r *repository) FindEscalationByID(ctx context.Context, schoolID, id uint) (*models.ViolationEscalation, error) {
+	var escalation models.ViolationEscalation
+	err := r.db.WithContext(ctx).
+		Preload("Student").
+		Preload("Student.School").
+		Preload("Class").
+		Preload("Counselor").
+		Preload("Completer").
+		Where("id = ? AND school_id = ?", id, schoolID).
+		First(&escalation).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEscalationNotFound
+		}
+		return nil, err
+	}
+	return &escalation, nil
+}
+
+// FindEscalationsByStudent retrieves a student's escalation history, newest first
+func (r *repository) FindEscalationsByStudent(ctx context.Context, studentID uint) ([]models.ViolationEscalation, error) {
+	var escalations []models.ViolationEscalation
+	err := r.db.WithContext(ctx).
+		Preload("Class").
+		Preload("Counselor").
+		Preload("Completer").
+		Where("student_id = ?", studentID).
+		Order("created_at DESC").
+		Find(&escalations).Error
+	return escalations, err
+}
+
+// FindEscalations retrieves escalations with pagination and filtering
+func (r *repository) FindEscalations(ctx context.Context, schoolID uint, filter EscalationFilter) ([]models.ViolationEscalation, int64, error) {
+	var escalations []models.ViolationEscalation
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&models.ViolationEscalation{}).
+		Where("school_id = ?", schoolID)
+
+	if filter.StudentID != nil {
+		query = query.Where("student_id = ?", *filter.StudentID)
+	}
+	if filter.ClassID != nil {
+		query = query.Where("class_id = ?", *filter.ClassID)
+	}
+	if filter.CounselorID != nil {
+		query = query.Where("counselor_id = ?", *filter.CounselorID)
+	}
+	if filter.Status != nil && *filter.Status != "" {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.Action != nil && *filter.Action != "" {
+		query = query.Where("action = ?", *filter.Action)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Student").
+		Preload("Class").
+		Preload("Counselor").
+		Preload("Completer").
+		Order("created_at DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&escalations).Error
+
+	return escalations, total, err
+}
+
+// CompleteEscalation closes the follow-up task of an escalation
+func (r *repository) CompleteEscalation(ctx context.Context, escalation *models.ViolationEscalation) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ViolationEscalation{}).
+		Where("id = ?", escalation.ID).
+		Updates(map[string]interface{}{
+			"status":         escalation.Status,
+			"follow_up_note": escalation.FollowUpNote,
+			"completed_by":   escalation.CompletedBy,
+			"completed_at":   escalation.CompletedAt,
+		}).Error
+}
@@ -0,0 +1,389 @@
+package bk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/outbox"
+)
+
+var (
+	ErrThresholdPointsRequired = errors.New("poin ambang harus lebih dari 0")
+	ErrThresholdNameRequired   = errors.New("nama ambang wajib diisi")
+	ErrInvalidEscalationAction = errors.New("aksi harus parent_call, summons_letter atau suspension_review")
+	ErrThresholdPointsTaken    = errors.New("sudah ada ambang dengan jumlah poin yang sama")
+	ErrFollowUpNoteRequired    = errors.New("catatan tindak lanjut wajib diisi")
+	ErrEscalationCompleted     = errors.New("tindak lanjut eskalasi sudah selesai")
+)
+
+// ==================== Violation Threshold Service ====================
+
+// CreateViolationThreshold creates a new violation point threshold
+// Students already above the points are escalated on their next violation.
+func (s *service) CreateViolationThreshold(ctx context.Context, schoolID uint, req CreateViolationThresholdRequest) (*ViolationThresholdResponse, error) {
+	threshold := &models.ViolationThreshold{
+		SchoolID:    schoolID,
+		Points:      req.Points,
+		Name:        strings.TrimSpace(req.Name),
+		Action:      req.Action,
+		Description: strings.TrimSpace(req.Description),
+		IsActive:    true,
+	}
+	if err := s.validateThreshold(ctx, threshold); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateViolationThreshold(ctx, threshold); err != nil {
+		return nil, err
+	}
+
+	return toViolationThresholdResponse(threshold), nil
+}
+
+// GetViolationThresholds retrieves the thresholds of a school, lowest first
+func (s *service) GetViolationThresholds(ctx context.Context, schoolID uint, activeOnly bool) (*ViolationThresholdListResponse, error) {
+	thresholds, err := s.repo.FindViolationThresholds(ctx, schoolID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]ViolationThresholdResponse, len(thresholds))
+	for i := range thresholds {
+		responses[i] = *toViolationThresholdResponse(&thresholds[i])
+	}
+	return &ViolationThresholdListResponse{Thresholds: responses}, nil
+}
+
+// UpdateViolationThreshold updates a violation point threshold
+// Escalations already recorded keep the threshold as it was when crossed.
+func (s *service) UpdateViolationThreshold(ctx context.Context, schoolID, id uint, req UpdateViolationThresholdRequest) (*ViolationThresholdResponse, error) {
+	threshold, err := s.repo.FindViolationThresholdByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Points != nil {
+		threshold.Points = *req.Points
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		threshold.Name = name
+	}
+	if req.Action != "" {
+		threshold.Action = req.Action
+	}
+	if req.Description != nil {
+		threshold.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.IsActive != nil {
+		threshold.IsActive = *req.IsActive
+	}
+	if err := s.validateThreshold(ctx, threshold); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateViolationThreshold(ctx, threshold); err != nil {
+		return nil, err
+	}
+
+	return toViolationThresholdResponse(threshold), nil
+}
+
+// DeleteViolationThreshold deletes a violation point threshold
+func (s *service) DeleteViolationThreshold(ctx context.Context, schoolID, id uint) error {
+	return s.repo.DeleteViolationThreshold(ctx, schoolID, id)
+}
+
+func (s *service) validateThreshold(ctx context.Context, threshold *models.ViolationThreshold) error {
+	if threshold.Points <= 0 {
+		return ErrThresholdPointsRequired
+	}
+	if threshold.Name == "" {
+		return ErrThresholdNameRequired
+	}
+	if !threshold.Action.IsValid() {
+		return ErrInvalidEscalationAction
+	}
+
+	taken, err := s.repo.ViolationThresholdPointsExist(ctx, threshold.SchoolID, threshold.Points, threshold.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrThresholdPointsTaken
+	}
+	return threshold.Validate()
+}
+
+// ==================== Escalation Service ====================
+
+// escalate records every active threshold the student's accumulated violation points have reached
+// Each threshold is crossed once per student. A threshold missed because an earlier run failed
+// is caught up on the student's next violation.
+func (s *service) escalate(ctx context.Context, schoolID uint, student *models.Student, violation *models.Violation) error {
+	sum, err := s.repo.GetStudentViolationPoints(ctx, student.ID)
+	if err != nil {
+		return err
+	}
+	total := -sum // Violation points are stored as negative numbers
+	if total <= 0 {
+		return nil
+	}
+
+	thresholds, err := s.repo.FindViolationThresholds(ctx, schoolID, true)
+	if err != nil || len(thresholds) == 0 {
+		return err
+	}
+
+	crossedIDs, err := s.repo.FindEscalatedThresholdIDs(ctx, student.ID)
+	if err != nil {
+		return err
+	}
+	crossed := make(map[uint]bool, len(crossedIDs))
+	for _, id := range crossedIDs {
+		crossed[id] = true
+	}
+
+	var counselorID *uint
+	var recipients []uint
+	resolved := false
+
+	for _, threshold := range thresholds {
+		if threshold.Points > total || crossed[threshold.ID] {
+			continue
+		}
+
+		if !resolved {
+			if counselorID, recipients, err = s.findCounselors(ctx, schoolID, student); err != nil {
+				return err
+			}
+			resolved = true
+		}
+
+		escalation := &models.ViolationEscalation{
+			SchoolID:        schoolID,
+			StudentID:       student.ID,
+			ThresholdID:     threshold.ID,
+			ViolationID:     violation.ID,
+			ClassID:         student.ClassID,
+			ThresholdPoints: threshold.Points,
+			ThresholdName:   threshold.Name,
+			Action:          threshold.Action,
+			Description:     threshold.Description,
+			TotalPoints:     total,
+			CounselorID:     counselorID,
+			Status:          models.EscalationStatusOpen,
+		}
+
+		created, err := s.repo.CreateEscalation(ctx, escalation, escalationEvents(student, escalation, recipients)...)
+		if err != nil {
+			return err
+		}
+		if created {
+			log.Printf("Student %d reached %d violation points, escalated %q (%s)", student.ID, total, threshold.Name, escalation.LetterNumber)
+		}
+	}
+	return nil
+}
+
+// findCounselors picks the class counselor who gets the follow-up task
+// Without a class counselor the task stays unassigned and every BK teacher of the school is notified.
+func (s *service) findCounselors(ctx context.Context, schoolID uint, student *models.Student) (*uint, []uint, error) {
+	if student.ClassID != nil {
+		ids, err := s.repo.FindClassCounselorIDs(ctx, *student.ClassID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(ids) > 0 {
+			return &ids[0], ids[:1], nil
+		}
+	}
+
+	ids, err := s.repo.FindSchoolCounselorIDs(ctx, schoolID)
+	return nil, ids, err
+}
+
+// escalationEvents builds the notifications for the parents and the counselor
+func escalationEvents(student *models.Student, escalation *models.ViolationEscalation, counselorIDs []uint) []*outbox.Event {
+	data := map[string]interface{}{
+		"student_id":   fmt.Sprintf("%d", student.ID),
+		"action":       string(escalation.Action),
+		"total_points": fmt.Sprintf("%d", escalation.TotalPoints),
+	}
+
+	events := []*outbox.Event{
+		outbox.NewEvent(outbox.EventViolationEscalated, outbox.Payload{
+			SchoolID:  escalation.SchoolID,
+			StudentID: student.ID,
+			Notification: outbox.ParentNotification(
+				models.NotificationTypeViolation,
+				escalation.Action.LetterTitle(),
+				fmt.Sprintf("%s telah mencapai %d poin pelanggaran (%s). %s", student.Name, escalation.TotalPoints, escalation.ThresholdName, actionParagraph(escalation.Action)),
+				data,
+			),
+		}),
+	}
+
+	if len(counselorIDs) > 0 {
+		events = append(events, outbox.NewEvent(outbox.EventViolationEscalated, outbox.Payload{
+			SchoolID:  escalation.SchoolID,
+			StudentID: student.ID,
+			Notification: &outbox.NotificationPayload{
+				Type:    models.NotificationTypeViolation,
+				Title:   "Tindak Lanjut Poin Pelanggaran",
+				Message: fmt.Sprintf("%s mencapai %d poin pelanggaran (%s). Mohon tindak lanjuti.", student.Name, escalation.TotalPoints, escalation.ThresholdName),
+				Data:    data,
+				UserIDs: counselorIDs,
+			},
+		}))
+	}
+	return events
+}
+
+// GetEscalations retrieves escalations with pagination and filtering
+func (s *service) GetEscalations(ctx context.Context, schoolID uint, filter EscalationFilter) (*EscalationListResponse, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	escalations, total, err := s.repo.FindEscalations(ctx, schoolID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]EscalationResponse, len(escalations))
+	for i := range escalations {
+		responses[i] = *toEscalationResponse(&escalations[i])
+	}
+
+	totalPages := int(total) / filter.PageSize
+	if int(total)%filter.PageSize > 0 {
+		totalPages++
+	}
+
+	return &EscalationListResponse{
+		Escalations: responses,
+		Pagination: PaginationMeta{
+			Page:       filter.Page,
+			PageSize:   filter.PageSize,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+// GetEscalationByID retrieves an escalation
+func (s *service) GetEscalationByID(ctx context.Context, schoolID, id uint) (*EscalationResponse, error) {
+	escalation, err := s.repo.FindEscalationByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toEscalationResponse(escalation), nil
+}
+
+// CompleteEscalation closes the follow-up task of an escalation with its outcome
+func (s *service) CompleteEscalation(ctx context.Context, schoolID, userID, id uint, req CompleteEscalationRequest) (*EscalationResponse, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, ErrFollowUpNoteRequired
+	}
+
+	escalation, err := s.repo.FindEscalationByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !escalation.IsOpen() {
+		return nil, ErrEscalationCompleted
+	}
+
+	now := time.Now()
+	escalation.Status = models.EscalationStatusCompleted
+	escalation.FollowUpNote = note
+	escalation.CompletedBy = &userID
+	escalation.CompletedAt = &now
+	if err := s.repo.CompleteEscalation(ctx, escalation); err != nil {
+		return nil, err
+	}
+
+	escalation, err = s.repo.FindEscalationByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toEscalationResponse(escalation), nil
+}
+
+// GetEscalationLetter renders the letter of an escalation as PDF
+func (s *service) GetEscalationLetter(ctx context.Context, schoolID, id uint) ([]byte, string, error) {
+	escalation, err := s.repo.FindEscalationByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	content, err := renderLetter(escalation)
+	if err != nil {
+		return nil, "", fmt.Errorf("render letter: %w", err)
+	}
+
+	fileName := fmt.Sprintf("surat-bk-%s-%s.pdf", strings.ReplaceAll(escalation.LetterNumber, "/", "-"), escalation.Student.NIS)
+	return content, fileName, nil
+}
+
+// ==================== Response Converters ====================
+
+func toViolationThresholdResponse(t *models.ViolationThreshold) *ViolationThresholdResponse {
+	return &ViolationThresholdResponse{
+		ID:          t.ID,
+		SchoolID:    t.SchoolID,
+		Points:      t.Points,
+		Name:        t.Name,
+		Action:      t.Action,
+		Description: t.Description,
+		IsActive:    t.IsActive,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+func toEscalationResponse(e *models.ViolationEscalation) *EscalationResponse {
+	response := &EscalationResponse{
+		ID:              e.ID,
+		StudentID:       e.StudentID,
+		StudentName:     e.Student.Name,
+		StudentNIS:      e.Student.NIS,
+		ClassID:         e.ClassID,
+		ViolationID:     e.ViolationID,
+		ThresholdID:     e.ThresholdID,
+		ThresholdPoints: e.ThresholdPoints,
+		ThresholdName:   e.ThresholdName,
+		Action:          e.Action,
+		TotalPoints:     e.TotalPoints,
+		LetterNumber:    e.LetterNumber,
+		LetterTitle:     e.Action.LetterTitle(),
+		CounselorID:     e.CounselorID,
+		Status:          e.Status,
+		FollowUpNote:    e.FollowUpNote,
+		CompletedBy:     e.CompletedBy,
+		CompletedAt:     e.CompletedAt,
+		CreatedAt:       e.CreatedAt,
+	}
+	if e.Class != nil {
+		response.ClassName = e.Class.Name
+	}
+	if e.Counselor != nil {
+		response.CounselorName = userDisplayName(e.Counselor)
+	}
+	if e.Completer != nil {
+		response.CompleterName = userDisplayName(e.Completer)
+	}
+	return response
+}
@@ -0,0 +1,127 @@
+package bk
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/outbox"
+)
+
+// followUpReminderBatch is the number of due follow-ups reminded per tick
+const followUpReminderBatch = 100
+
+// FollowUpReminder notifies the assignee of a counseling follow-up once it falls due.
+// Each follow-up is reminded once; follow-ups of closed cases are skipped.
+type FollowUpReminder struct {
+	repo     Repository
+	interval time.Duration
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+	running  bool
+	mu       sync.Mutex
+}
+
+// NewFollowUpReminder creates a new follow-up reminder that checks follow-ups every minute
+func NewFollowUpReminder(repo Repository) *FollowUpReminder {
+	return &FollowUpReminder{
+		repo:     repo,
+		interval: time.Minute,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start starts the follow-up reminder
+func (f *FollowUpReminder) Start() {
+	f.mu.Lock()
+	if f.running {
+		f.mu.Unlock()
+		return
+	}
+	f.running = true
+	f.mu.Unlock()
+
+	f.wg.Add(1)
+	go f.runLoop()
+
+	log.Println("Counseling follow-up reminder started")
+}
+
+// Stop stops the follow-up reminder gracefully
+func (f *FollowUpReminder) Stop() {
+	f.mu.Lock()
+	if !f.running {
+		f.mu.Unlock()
+		return
+	}
+	f.running = false
+	f.mu.Unlock()
+
+	close(f.stopCh)
+	f.wg.Wait()
+
+	log.Println("Counseling follow-up reminder stopped")
+}
+
+// runLoop runs a check immediately and then on every tick
+func (f *FollowUpReminder) runLoop() {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	f.RunOnce(context.Background(), time.Now())
+
+	for {
+		select {
+		case <-f.stopCh:
+			return
+		case now := <-ticker.C:
+			f.RunOnce(context.Background(), now)
+		}
+	}
+}
+
+// RunOnce reminds the assignees of follow-ups that fell due
+func (f *FollowUpReminder) RunOnce(ctx context.Context, now time.Time) {
+	followUps, err := f.repo.FindDueFollowUps(ctx, now, followUpReminderBatch)
+	if err != nil {
+		log.Printf("Counseling follow-up reminder: failed to load due follow-ups: %v", err)
+		return
+	}
+
+	for i := range followUps {
+		followUp := &followUps[i]
+		marked, err := f.repo.MarkFollowUpReminded(ctx, followUp.ID, now, followUpEvent(followUp))
+		if err != nil {
+			log.Printf("Counseling follow-up reminder: failed to remind follow-up %d: %v", followUp.ID, err)
+			continue
+		}
+		if marked {
+			log.Printf("Counseling follow-up reminder: reminded user %d of follow-up %d (case %d)", followUp.AssignedTo, followUp.ID, followUp.CaseID)
+		}
+	}
+}
+
+// followUpEvent builds the outbox event that reminds the assignee of a due follow-up
+func followUpEvent(followUp *DueFollowUp) *outbox.Event {
+	return outbox.NewEvent(outbox.EventCounselingFollowUpDue, outbox.Payload{
+		SchoolID:  followUp.SchoolID,
+		StudentID: followUp.StudentID,
+		Notification: &outbox.NotificationPayload{
+			Type:    models.NotificationTypeCounseling,
+			Title:   "Pengingat Tindak Lanjut Konseling",
+			Message: fmt.Sprintf("Tindak lanjut kasus \"%s\" (%s) jatuh tempo: %s", followUp.CaseTitle, followUp.StudentName, followUp.Note),
+			Data: map[string]interface{}{
+				"case_id":      fmt.Sprintf("%d", followUp.CaseID),
+				"follow_up_id": fmt.Sprintf("%d", followUp.ID),
+				"student_id":   fmt.Sprintf("%d", followUp.StudentID),
+				"due_at":       followUp.DueAt.Format(time.RFC3339),
+			},
+			UserIDs: []uint{followUp.AssignedTo},
+		},
+	})
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/middleware"
 )
 
 // Handler handles HTTP requests for BK (Bimbingan Konseling) management
@@ -66,6 +69,31 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 	counseling.Put("/:id", h.UpdateCounselingNote)
 	counseling.Delete("/:id", h.DeleteCounselingNote)
 
+	// Violation point thresholds and the escalations they trigger
+	thresholds := bk.Group("/violation-thresholds")
+	thresholds.Get("", h.GetViolationThresholds)
+	thresholds.Post("", staffOnly(), h.CreateViolationThreshold)
+	thresholds.Put("/:id", staffOnly(), h.UpdateViolationThreshold)
+	thresholds.Delete("/:id", staffOnly(), h.DeleteViolationThreshold)
+	escalations := bk.Group("/escalations")
+	escalations.Get("", h.GetEscalations)
+	escalations.Get("/:id", h.GetEscalationByID)
+	escalations.Post("/:id/complete", staffOnly(), h.CompleteEscalation)
+	escalations.Get("/:id/letter", h.GetEscalationLetter)
+
+	// Counseling cases with their sessions and follow-ups
+	cases := bk.Group("/counseling-cases")
+	cases.Get("", h.GetCounselingCases)
+	cases.Post("", staffOnly(), h.CreateCounselingCase)
+	cases.Get("/:id", h.GetCounselingCaseByID)
+	cases.Put("/:id", staffOnly(), h.UpdateCounselingCase)
+	cases.Post("/:id/close", staffOnly(), h.CloseCounselingCase)
+	cases.Post("/:id/reopen", staffOnly(), h.ReopenCounselingCase)
+	cases.Post("/:id/sessions", staffOnly(), h.ScheduleCounselingSession)
+	cases.Put("/:id/sessions/:sessionId", staffOnly(), h.UpdateCounselingSession)
+	cases.Post("/:id/follow-ups", staffOnly(), h.AddCounselingFollowUp)
+	cases.Post("/:id/follow-ups/:followUpId/complete", staffOnly(), h.CompleteCounselingFollowUp)
+
 	// Student BK Profile
 	bk.Get("/students/:studentId/profile", h.GetStudentBKProfile)
 	bk.Get("/students/:studentId/violations", h.GetStudentViolations)
@@ -116,6 +144,28 @@ func (h *Handler) RegisterRoutesWithoutGroup(router fiber.Router) {
 	router.Put("/counseling/:id", h.UpdateCounselingNote)
 	router.Delete("/counseling/:id", h.DeleteCounselingNote)
 
+	// Violation point thresholds and the escalations they trigger
+	router.Get("/violation-thresholds", h.GetViolationThresholds)
+	router.Post("/violation-thresholds", staffOnly(), h.CreateViolationThreshold)
+	router.Put("/violation-thresholds/:id", staffOnly(), h.UpdateViolationThreshold)
+	router.Delete("/violation-thresholds/:id", staffOnly(), h.DeleteViolationThreshold)
+	router.Get("/escalations", h.GetEscalations)
+	router.Get("/escalations/:id", h.GetEscalationByID)
+	router.Post("/escalations/:id/complete", staffOnly(), h.CompleteEscalation)
+	router.Get("/escalations/:id/letter", h.GetEscalationLetter)
+
+	// Counseling cases with their sessions and follow-ups
+	router.Get("/counseling-cases", h.GetCounselingCases)
+	router.Post("/counseling-cases", staffOnly(), h.CreateCounselingCase)
+	router.Get("/counseling-cases/:id", h.GetCounselingCaseByID)
+	router.Put("/counseling-cases/:id", staffOnly(), h.UpdateCounselingCase)
+	router.Post("/counseling-cases/:id/close", staffOnly(), h.CloseCounselingCase)
+	router.Post("/counseling-cases/:id/reopen", staffOnly(), h.ReopenCounselingCase)
+	router.Post("/counseling-cases/:id/sessions", staffOnly(), h.ScheduleCounselingSession)
+	router.Put("/counseling-cases/:id/sessions/:sessionId", staffOnly(), h.UpdateCounselingSession)
+	router.Post("/counseling-cases/:id/follow-ups", staffOnly(), h.AddCounselingFollowUp)
+	router.Post("/counseling-cases/:id/follow-ups/:followUpId/complete", staffOnly(), h.CompleteCounselingFollowUp)
+
 	// Student BK Profile
 	router.Get("/students/:studentId/profile", h.GetStudentBKProfile)
 	router.Get("/students/:studentId/violations", h.GetStudentViolations)
@@ -144,6 +194,14 @@ func (h *Handler) RegisterReadOnlyRoutes(router fiber.Router) {
 	bk.Get("/counseling", h.GetCounselingNotesReadOnly)
 	bk.Get("/counseling/:id", h.GetCounselingNoteByIDReadOnly)
 
+	// Escalations of crossed violation point thresholds
+	bk.Get("/escalations", h.GetEscalations)
+	bk.Get("/escalations/:id", h.GetEscalationByID)
+
+	// Counseling cases - only parent summaries of sessions visible
+	bk.Get("/counseling-cases", h.GetCounselingCasesReadOnly)
+	bk.Get("/counseling-cases/:id", h.GetCounselingCaseByIDReadOnly)
+
 	// Student BK Profile (read-only, no internal notes)
 	bk.Get("/students/:studentId/profile", h.GetStudentBKProfileReadOnly)
 	bk.Get("/students/:studentId/violations", h.GetStudentViolations)
@@ -152,6 +210,11 @@ func (h *Handler) RegisterReadOnlyRoutes(router fiber.Router) {
 	bk.Get("/students/:studentId/counseling", h.GetStudentCounselingNotesReadOnly)
 }
 
+// staffOnly limits BK configuration, follow-ups and case management to admin sekolah and Guru BK
+func staffOnly() fiber.Handler {
+	return middleware.RoleMiddleware(models.RoleAdminSekolah, models.RoleGuruBK)
+}
+
 // ==================== Dashboard ====================
 
 // GetDashboard handles getting BK dashboard data
@@ -1530,6 +1593,9 @@ func (h *Handler) invalidIDError(c *fiber.Ctx, resource string) error {
 		"permit":          "izin keluar",
 		"counseling note": "catatan konseling",
 		"student":         "siswa",
+		"case":            "kasus konseling",
+		"session":         "sesi konseling",
+		"follow-up":       "tindak lanjut",
 	}
 	resourceName := resourceMap[resource]
 	if resourceName == "" {
@@ -1722,6 +1788,142 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 				"message": "Level pelanggaran tidak valid. Harus salah satu dari: ringan, sedang, berat",
 			},
 		})
+	case errors.Is(err, ErrViolationThresholdNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_VIOLATION_THRESHOLD",
+				"message": "Ambang poin pelanggaran tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrEscalationNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_ESCALATION",
+				"message": "Eskalasi pelanggaran tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrThresholdPointsRequired), errors.Is(err, ErrThresholdNameRequired), errors.Is(err, ErrFollowUpNoteRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrInvalidEscalationAction):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_ACTION",
+				"message": "Aksi harus parent_call, summons_letter atau suspension_review",
+			},
+		})
+	case errors.Is(err, ErrThresholdPointsTaken):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VIOLATION_THRESHOLD_EXISTS",
+				"message": "Sudah ada ambang dengan jumlah poin yang sama",
+			},
+		})
+	case errors.Is(err, ErrEscalationCompleted):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "ESCALATION_COMPLETED",
+				"message": "Tindak lanjut eskalasi sudah selesai",
+			},
+		})
+	case errors.Is(err, ErrCounselingCaseNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_COUNSELING_CASE",
+				"message": "Kasus konseling tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrCounselingSessionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_COUNSELING_SESSION",
+				"message": "Sesi konseling tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrCounselingFollowUpNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_COUNSELING_FOLLOW_UP",
+				"message": "Tindak lanjut kasus tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrCaseOutcomeRequired), errors.Is(err, ErrReferredToRequired), errors.Is(err, ErrSessionTimeRequired), errors.Is(err, ErrFollowUpDueRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrInvalidCaseStatus), errors.Is(err, ErrInvalidCaseClosure), errors.Is(err, ErrInvalidSessionStatus):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_STATUS",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrInvalidSessionDuration):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_DURATION",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrInvalidCounselor), errors.Is(err, ErrInvalidAssignee):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_USER",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrCaseViolationMismatch), errors.Is(err, ErrCasePermitMismatch):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_LINK",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrCaseClosed):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "COUNSELING_CASE_CLOSED",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrCaseNotClosed):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "COUNSELING_CASE_NOT_CLOSED",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrFollowUpAlreadyDone):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "FOLLOW_UP_COMPLETED",
+				"message": err.Error(),
+			},
+		})
 	default:
 		// Return the actual error message for better debugging
 		errMsg := err.Error()
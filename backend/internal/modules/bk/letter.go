@@ -0,0 +1,149 @@
+package bk
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/pdf"
+)
+
+// Letter layout in points
+const (
+	letterMargin   = 60.0
+	letterBodySize = 11.0
+	letterLeading  = letterBodySize + 5
+)
+
+var letterMonths = [12]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+var romanMonths = [12]string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+
+// letterNumber formats the number of the n-th BK letter of the year, e.g. 007/BK/X/2026
+func letterNumber(n int, at time.Time) string {
+	return fmt.Sprintf("%03d/BK/%s/%d", n, romanMonths[at.Month()-1], at.Year())
+}
+
+// actionParagraph is the sentence telling the parents what the sanction means for them
+func actionParagraph(action models.EscalationAction) string {
+	switch action {
+	case models.EscalationActionSummonsLetter:
+		return "Sehubungan dengan hal tersebut, kami mengundang Bapak/Ibu untuk hadir ke sekolah dan bertemu dengan Guru BK guna membicarakan pembinaan putra/putri Bapak/Ibu."
+	case models.EscalationActionSuspensionReview:
+		return "Sesuai tata tertib sekolah, jumlah poin tersebut mengharuskan sekolah meninjau pemberian sanksi skorsing. Kami mengundang Bapak/Ibu untuk hadir ke sekolah sebelum keputusan diambil."
+	default:
+		return "Sehubungan dengan hal tersebut, Guru BK akan menghubungi Bapak/Ibu untuk membicarakan pembinaan putra/putri Bapak/Ibu."
+	}
+}
+
+// renderLetter lays out the letter sent to the parents when a student crosses a threshold
+func renderLetter(escalation *models.ViolationEscalation) ([]byte, error) {
+	doc := pdf.New(pdf.A4)
+	doc.SetTitle(escalation.Action.LetterTitle() + " - " + escalation.Student.Name)
+	doc.AddPage()
+	width := pdf.A4.Width - 2*letterMargin
+	y := letterMargin
+
+	// Letterhead
+	school := escalation.Student.School
+	y += 16
+	doc.TextAligned(letterMargin, y, width, pdf.Bold, 16, pdf.AlignCenter, strings.ToUpper(school.Name))
+	for _, line := range []string{school.Address, contactLine(school)} {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		y += letterBodySize + 3
+		doc.TextAligned(letterMargin, y, width, pdf.Regular, letterBodySize-1, pdf.AlignCenter, line)
+	}
+	y += 8
+	doc.Line(letterMargin, y, letterMargin+width, y, 1.5)
+	y += 2
+	doc.Line(letterMargin, y, letterMargin+width, y, 0.5)
+
+	// Title and number
+	y += 30
+	doc.TextAligned(letterMargin, y, width, pdf.Bold, 13, pdf.AlignCenter, strings.ToUpper(escalation.Action.LetterTitle()))
+	y += letterLeading
+	doc.TextAligned(letterMargin, y, width, pdf.Regular, letterBodySize, pdf.AlignCenter, "Nomor: "+escalation.LetterNumber)
+
+	y += letterLeading * 2
+	doc.Text(letterMargin, y, pdf.Regular, letterBodySize, "Kepada Yth.")
+	y += letterLeading
+	doc.Text(letterMargin, y, pdf.Regular, letterBodySize, "Bapak/Ibu Orang Tua/Wali dari:")
+
+	className := "-"
+	if escalation.Class != nil {
+		className = escalation.Class.Name
+	}
+	for _, field := range [][2]string{
+		{"Nama", escalation.Student.Name},
+		{"NIS / NISN", escalation.Student.NIS + " / " + escalation.Student.NISN},
+		{"Kelas", className},
+	} {
+		y += letterLeading
+		doc.Text(letterMargin+20, y, pdf.Regular, letterBodySize, field[0])
+		doc.Text(letterMargin+120, y, pdf.Regular, letterBodySize, ": "+field[1])
+	}
+
+	y += letterLeading * 2
+	doc.Text(letterMargin, y, pdf.Regular, letterBodySize, "Dengan hormat,")
+
+	paragraphs := []string{
+		fmt.Sprintf("Bersama surat ini kami sampaikan bahwa putra/putri Bapak/Ibu telah mencapai akumulasi %d poin pelanggaran tata tertib sekolah, sehingga melewati batas %d poin (%s).",
+			escalation.TotalPoints, escalation.ThresholdPoints, escalation.ThresholdName),
+		actionParagraph(escalation.Action),
+	}
+	if note := strings.TrimSpace(escalation.Description); note != "" {
+		paragraphs = append(paragraphs, note)
+	}
+	paragraphs = append(paragraphs, "Demikian surat ini kami sampaikan. Atas perhatian dan kerja sama Bapak/Ibu, kami ucapkan terima kasih.")
+
+	for _, paragraph := range paragraphs {
+		y += letterLeading / 2
+		for _, line := range pdf.WrapText(pdf.Regular, letterBodySize, paragraph, width) {
+			y += letterLeading
+			doc.Text(letterMargin, y, pdf.Regular, letterBodySize, line)
+		}
+	}
+
+	// Signature of the counselor on the right
+	half := width / 2
+	issued := escalation.CreatedAt
+	y += letterLeading * 2
+	doc.TextAligned(letterMargin+half, y, half, pdf.Regular, letterBodySize, pdf.AlignCenter,
+		fmt.Sprintf("%d %s %d", issued.Day(), letterMonths[issued.Month()-1], issued.Year()))
+	y += letterLeading
+	doc.TextAligned(letterMargin+half, y, half, pdf.Regular, letterBodySize, pdf.AlignCenter, "Guru Bimbingan Konseling")
+	y += 60
+	counselor := "(.............................)"
+	if escalation.Counselor != nil {
+		counselor = userDisplayName(escalation.Counselor)
+	}
+	doc.TextAligned(letterMargin+half, y, half, pdf.Bold, letterBodySize, pdf.AlignCenter, counselor)
+
+	return doc.Bytes()
+}
+
+// contactLine joins the school's phone and email for the letterhead
+func contactLine(school models.School) string {
+	var parts []string
+	if school.Phone != "" {
+		parts = append(parts, "Telp. "+school.Phone)
+	}
+	if school.Email != "" {
+		parts = append(parts, "Email: "+school.Email)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// userDisplayName returns the name of a user, or the username when the name is empty
+func userDisplayName(user *models.User) string {
+	if strings.TrimSpace(user.Name) != "" {
+		return user.Name
+	}
+	return user.Username
+}
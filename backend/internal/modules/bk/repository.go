@@ -8,6 +8,9 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/audit"
+	"github.com/school-management/backend/internal/shared/enrollment"
+	"github.com/school-management/backend/internal/shared/outbox"
 )
 
 var (
@@ -23,7 +26,7 @@ var (
 // Repository defines the interface for BK data operations
 type Repository interface {
 	// Violation operations
-	CreateViolation(ctx context.Context, violation *models.Violation) error
+	CreateViolation(ctx context.Context, violation *models.Violation, event *outbox.Event) error
 	FindViolationByID(ctx context.Context, id uint) (*models.Violation, error)
 	FindViolationsByStudent(ctx context.Context, studentID uint) ([]models.Violation, error)
 	FindViolations(ctx context.Context, schoolID uint, filter ViolationFilter) ([]models.Violation, int64, error)
@@ -38,7 +41,7 @@ type Repository interface {
 	DeleteViolationCategory(ctx context.Context, id uint) error
 
 	// Achievement operations
-	CreateAchievement(ctx context.Context, achievement *models.Achievement) error
+	CreateAchievement(ctx context.Context, achievement *models.Achievement, event *outbox.Event) error
 	FindAchievementByID(ctx context.Context, id uint) (*models.Achievement, error)
 	FindAchievementsByStudent(ctx context.Context, studentID uint) ([]models.Achievement, error)
 	FindAchievements(ctx context.Context, schoolID uint, filter AchievementFilter) ([]models.Achievement, int64, error)
@@ -46,7 +49,7 @@ type Repository interface {
 	DeleteAchievement(ctx context.Context, id uint) error
 
 	// Permit operations
-	CreatePermit(ctx context.Context, permit *models.Permit) error
+	CreatePermit(ctx context.Context, permit *models.Permit, event *outbox.Event) error
 	FindPermitByID(ctx context.Context, id uint) (*models.Permit, error)
 	FindPermitsByStudent(ctx context.Context, studentID uint) ([]models.Permit, error)
 	FindPermits(ctx context.Context, schoolID uint, filter PermitFilter) ([]models.Permit, int64, error)
@@ -60,6 +63,42 @@ type Repository interface {
 	FindCounselingNotes(ctx context.Context, schoolID uint, filter CounselingNoteFilter) ([]models.CounselingNote, int64, error)
 	UpdateCounselingNote(ctx context.Context, note *models.CounselingNote) error
 	DeleteCounselingNote(ctx context.Context, id uint) error
+	RecordInternalNoteReads(ctx context.Context, table string, ids ...uint) error
+
+	// Violation Threshold operations
+	CreateViolationThreshold(ctx context.Context, threshold *models.ViolationThreshold) error
+	FindViolationThresholdByID(ctx context.Context, schoolID, id uint) (*models.ViolationThreshold, error)
+	FindViolationThresholds(ctx context.Context, schoolID uint, activeOnly bool) ([]models.ViolationThreshold, error)
+	ViolationThresholdPointsExist(ctx context.Context, schoolID uint, points int, excludeID uint) (bool, error)
+	UpdateViolationThreshold(ctx context.Context, threshold *models.ViolationThreshold) error
+	DeleteViolationThreshold(ctx context.Context, schoolID, id uint) error
+
+	// Escalation operations
+	FindEscalatedThresholdIDs(ctx context.Context, studentID uint) ([]uint, error)
+	FindClassCounselorIDs(ctx context.Context, classID uint) ([]uint, error)
+	FindSchoolCounselorIDs(ctx context.Context, schoolID uint) ([]uint, error)
+	CreateEscalation(ctx context.Context, escalation *models.ViolationEscalation, events ...*outbox.Event) (bool, error)
+	FindEscalationByID(ctx context.Context, schoolID, id uint) (*models.ViolationEscalation, error)
+	FindEscalationsByStudent(ctx context.Context, studentID uint) ([]models.ViolationEscalation, error)
+	FindEscalations(ctx context.Context, schoolID uint, filter EscalationFilter) ([]models.ViolationEscalation, int64, error)
+	CompleteEscalation(ctx context.Context, escalation *models.ViolationEscalation) error
+
+	// Counseling Case operations
+	CreateCounselingCase(ctx context.Context, counselingCase *models.CounselingCase) error
+	FindCounselingCaseByID(ctx context.Context, schoolID, id uint) (*models.CounselingCase, error)
+	FindCounselingCases(ctx context.Context, schoolID uint, filter CounselingCaseFilter) ([]models.CounselingCase, int64, error)
+	UpdateCounselingCase(ctx context.Context, counselingCase *models.CounselingCase, violations *[]models.Violation, permits *[]models.Permit) error
+	CloseCounselingCase(ctx context.Context, counselingCase *models.CounselingCase) error
+	FindStudentViolationsByIDs(ctx context.Context, studentID uint, ids []uint) ([]models.Violation, error)
+	FindStudentPermitsByIDs(ctx context.Context, studentID uint, ids []uint) ([]models.Permit, error)
+	CreateCounselingSession(ctx context.Context, session *models.CounselingSession) error
+	FindCounselingSessionByID(ctx context.Context, caseID, id uint) (*models.CounselingSession, error)
+	UpdateCounselingSession(ctx context.Context, session *models.CounselingSession) error
+	CreateCounselingFollowUp(ctx context.Context, followUp *models.CounselingFollowUp) error
+	FindCounselingFollowUpByID(ctx context.Context, caseID, id uint) (*models.CounselingFollowUp, error)
+	CompleteCounselingFollowUp(ctx context.Context, followUp *models.CounselingFollowUp) error
+	FindDueFollowUps(ctx context.Context, now time.Time, limit int) ([]DueFollowUp, error)
+	MarkFollowUpReminded(ctx context.Context, id uint, at time.Time, event *outbox.Event) (bool, error)
 
 	// Student lookup
 	FindStudentByID(ctx context.Context, studentID uint) (*models.Student, error)
@@ -71,6 +110,10 @@ type Repository interface {
 	GetPermitCount(ctx context.Context, schoolID uint) (int64, error)
 	GetActivePermitCount(ctx context.Context, schoolID uint) (int64, error)
 	GetCounselingCount(ctx context.Context, schoolID uint) (int64, error)
+	GetCounselingCaseCounts(ctx context.Context, schoolID uint) (map[models.CounselingCaseStatus]int64, error)
+	GetOverdueFollowUpCount(ctx context.Context, schoolID uint, now time.Time) (int64, error)
+	GetUpcomingSessionCount(ctx context.Context, schoolID uint, from, to time.Time) (int64, error)
+	FindRecentlyClosedCases(ctx context.Context, schoolID uint, limit int) ([]models.CounselingCase, error)
 	GetStudentsNeedingAttention(ctx context.Context, schoolID uint, limit int) ([]StudentAttentionItem, error)
 }
 
@@ -88,8 +131,13 @@ func NewRepository(db *gorm.DB) Repository {
 
 // CreateViolation creates a new violation record
 // Requirements: 6.1 - WHEN a Guru_BK records a violation
-func (r *repository) CreateViolation(ctx context.Context, violation *models.Violation) error {
-	return r.db.WithContext(ctx).Create(violation).Error
+func (r *repository) CreateViolation(ctx context.Context, violation *models.Violation, event *outbox.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(violation).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, violation.ID, event)
+	})
 }
 
 // FindViolationByID retrieves a violation by ID
@@ -141,7 +189,7 @@ func (r *repository) FindViolations(ctx context.Context, schoolID uint, filter V
 		query = query.Where("violations.student_id = ?", *filter.StudentID)
 	}
 	if filter.ClassID != nil {
-		query = query.Where("students.class_id = ?", *filter.ClassID)
+		query = query.Where(enrollment.ClassAt("violations", "violations.created_at::date")+" = ?", *filter.ClassID)
 	}
 	if filter.Level != nil && *filter.Level != "" {
 		query = query.Where("violations.level = ?", *filter.Level)
@@ -292,8 +340,13 @@ func (r *repository) DeleteViolationCategory(ctx context.Context, id uint) error
 
 // CreateAchievement creates a new achievement record
 // Requirements: 7.1 - WHEN a Guru_BK records an achievement
-func (r *repository) CreateAchievement(ctx context.Context, achievement *models.Achievement) error {
-	return r.db.WithContext(ctx).Create(achievement).Error
+func (r *repository) CreateAchievement(ctx context.Context, achievement *models.Achievement, event *outbox.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(achievement).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, achievement.ID, event)
+	})
 }
 
 // FindAchievementByID retrieves an achievement by ID
@@ -344,7 +397,7 @@ func (r *repository) FindAchievements(ctx context.Context, schoolID uint, filter
 		query = query.Where("achievements.student_id = ?", *filter.StudentID)
 	}
 	if filter.ClassID != nil {
-		query = query.Where("students.class_id = ?", *filter.ClassID)
+		query = query.Where(enrollment.ClassAt("achievements", "achievements.created_at::date")+" = ?", *filter.ClassID)
 	}
 	if filter.StartDate != nil {
 		startDate, err := time.Parse("2006-01-02", *filter.StartDate)
@@ -421,8 +474,13 @@ func (r *repository) DeleteAchievement(ctx context.Context, id uint) error {
 
 // CreatePermit creates a new permit record
 // Requirements: 8.1 - WHEN a Guru_BK creates an exit permit
-func (r *repository) CreatePermit(ctx context.Context, permit *models.Permit) error {
-	return r.db.WithContext(ctx).Create(permit).Error
+func (r *repository) CreatePermit(ctx context.Context, permit *models.Permit, event *outbox.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(permit).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, permit.ID, event)
+	})
 }
 
 // FindPermitByID retrieves a permit by ID
@@ -475,7 +533,7 @@ func (r *repository) FindPermits(ctx context.Context, schoolID uint, filter Perm
 		query = query.Where("permits.student_id = ?", *filter.StudentID)
 	}
 	if filter.ClassID != nil {
-		query = query.Where("students.class_id = ?", *filter.ClassID)
+		query = query.Where(enrollment.ClassAt("permits", "permits.exit_time::date")+" = ?", *filter.ClassID)
 	}
 	if filter.TeacherID != nil {
 		query = query.Where("permits.responsible_teacher = ?", *filter.TeacherID)
@@ -625,7 +683,7 @@ func (r *repository) FindCounselingNotes(ctx context.Context, schoolID uint, fil
 		query = query.Where("counseling_notes.student_id = ?", *filter.StudentID)
 	}
 	if filter.ClassID != nil {
-		query = query.Where("students.class_id = ?", *filter.ClassID)
+		query = query.Where(enrollment.ClassAt("counseling_notes", "counseling_notes.created_at::date")+" = ?", *filter.ClassID)
 	}
 	if filter.StartDate != nil {
 		startDate, err := time.Parse("2006-01-02", *filter.StartDate)
@@ -704,6 +762,12 @@ func (r *repository) DeleteCounselingNote(ctx context.Context, id uint) error {
 	return nil
 }
 
+// RecordInternalNoteReads records in the audit log that the current user read the internal notes
+// of counseling notes, cases or sessions
+func (r *repository) RecordInternalNoteReads(ctx context.Context, table string, ids ...uint) error {
+	return audit.RecordRead(ctx, r.db, table, ids...)
+}
+
 
 // ==================== Student/User Lookup ====================
 
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/outbox"
 )
 
 var (
@@ -70,6 +72,30 @@ type Service interface {
 	UpdateCounselingNote(ctx context.Context, id uint, req UpdateCounselingNoteRequest) (*CounselingNoteFullResponse, error)
 	DeleteCounselingNote(ctx context.Context, id uint) error
 
+	// Violation Threshold operations
+	CreateViolationThreshold(ctx context.Context, schoolID uint, req CreateViolationThresholdRequest) (*ViolationThresholdResponse, error)
+	GetViolationThresholds(ctx context.Context, schoolID uint, activeOnly bool) (*ViolationThresholdListResponse, error)
+	UpdateViolationThreshold(ctx context.Context, schoolID, id uint, req UpdateViolationThresholdRequest) (*ViolationThresholdResponse, error)
+	DeleteViolationThreshold(ctx context.Context, schoolID, id uint) error
+
+	// Escalation operations
+	GetEscalations(ctx context.Context, schoolID uint, filter EscalationFilter) (*EscalationListResponse, error)
+	GetEscalationByID(ctx context.Context, schoolID, id uint) (*EscalationResponse, error)
+	CompleteEscalation(ctx context.Context, schoolID, userID, id uint, req CompleteEscalationRequest) (*EscalationResponse, error)
+	GetEscalationLetter(ctx context.Context, schoolID, id uint) ([]byte, string, error)
+
+	// Counseling Case operations
+	CreateCounselingCase(ctx context.Context, schoolID, userID uint, req CreateCounselingCaseRequest) (*CounselingCaseResponse, error)
+	GetCounselingCases(ctx context.Context, schoolID uint, filter CounselingCaseFilter, includeInternal bool) (*CounselingCaseListResponse, error)
+	GetCounselingCaseByID(ctx context.Context, schoolID, id uint, includeInternal bool) (*CounselingCaseResponse, error)
+	UpdateCounselingCase(ctx context.Context, schoolID, id uint, req UpdateCounselingCaseRequest) (*CounselingCaseResponse, error)
+	CloseCounselingCase(ctx context.Context, schoolID, userID, id uint, req CloseCounselingCaseRequest) (*CounselingCaseResponse, error)
+	ReopenCounselingCase(ctx context.Context, schoolID, id uint) (*CounselingCaseResponse, error)
+	ScheduleCounselingSession(ctx context.Context, schoolID, userID, caseID uint, req CreateCounselingSessionRequest) (*CounselingSessionResponse, error)
+	UpdateCounselingSession(ctx context.Context, schoolID, caseID, sessionID uint, req UpdateCounselingSessionRequest) (*CounselingSessionResponse, error)
+	AddCounselingFollowUp(ctx context.Context, schoolID, userID, caseID uint, req CreateCounselingFollowUpRequest) (*CounselingFollowUpResponse, error)
+	CompleteCounselingFollowUp(ctx context.Context, schoolID, userID, caseID, followUpID uint) (*CounselingFollowUpResponse, error)
+
 	// Student BK Profile
 	GetStudentBKProfile(ctx context.Context, studentID uint, includeInternal bool) (interface{}, error)
 
@@ -147,19 +173,37 @@ func (s *service) CreateViolation(ctx context.Context, schoolID, createdBy uint,
 		CreatedBy:   createdBy,
 	}
 
-	if err := s.repo.CreateViolation(ctx, violation); err != nil {
+	// Requirements: 6.2 - Parent notification is stored in the outbox with the violation
+	event := outbox.NewEvent(outbox.EventViolationCreated, outbox.Payload{
+		SchoolID:  schoolID,
+		StudentID: student.ID,
+		Notification: outbox.ParentNotification(
+			models.NotificationTypeViolation,
+			"Pelanggaran Siswa",
+			fmt.Sprintf("%s tercatat melakukan pelanggaran %s (%s): %s", student.Name, violation.Category, violation.Level, violation.Description),
+			map[string]interface{}{
+				"student_id": fmt.Sprintf("%d", student.ID),
+				"level":      string(violation.Level),
+				"point":      fmt.Sprintf("%d", violation.Point),
+			},
+		),
+	})
+
+	if err := s.repo.CreateViolation(ctx, violation, event); err != nil {
 		return nil, err
 	}
 
+	// The violation stands even if escalating fails; missed thresholds are caught up on the next one
+	if err := s.escalate(ctx, schoolID, student, violation); err != nil {
+		log.Printf("Failed to escalate violation %d of student %d: %v", violation.ID, student.ID, err)
+	}
+
 	// Reload with relations
 	violation, err = s.repo.FindViolationByID(ctx, violation.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: Trigger notification to parent (async)
-	// Requirements: 6.2 - WHEN a violation is saved, THE System SHALL trigger notification to the parent
-
 	return toViolationResponse(violation), nil
 }
 
@@ -378,7 +422,22 @@ func (s *service) CreateAchievement(ctx context.Context, schoolID, createdBy uin
 		CreatedBy:   createdBy,
 	}
 
-	if err := s.repo.CreateAchievement(ctx, achievement); err != nil {
+	// Requirements: 7.4 - Parent notification is stored in the outbox with the achievement
+	event := outbox.NewEvent(outbox.EventAchievementCreated, outbox.Payload{
+		SchoolID:  schoolID,
+		StudentID: student.ID,
+		Notification: outbox.ParentNotification(
+			models.NotificationTypeAchievement,
+			"Prestasi Siswa",
+			fmt.Sprintf("%s meraih prestasi: %s (+%d poin)", student.Name, achievement.Title, achievement.Point),
+			map[string]interface{}{
+				"student_id": fmt.Sprintf("%d", student.ID),
+				"point":      fmt.Sprintf("%d", achievement.Point),
+			},
+		),
+	})
+
+	if err := s.repo.CreateAchievement(ctx, achievement, event); err != nil {
 		return nil, err
 	}
 
@@ -388,9 +447,6 @@ func (s *service) CreateAchievement(ctx context.Context, schoolID, createdBy uin
 		return nil, err
 	}
 
-	// TODO: Trigger notification to parent (async)
-	// Requirements: 7.4 - WHEN an achievement is recorded, THE System SHALL trigger notification to the parent
-
 	return toAchievementResponse(achievement), nil
 }
 
@@ -523,7 +579,22 @@ func (s *service) CreatePermit(ctx context.Context, schoolID, createdBy uint, re
 		CreatedBy:          createdBy,
 	}
 
-	if err := s.repo.CreatePermit(ctx, permit); err != nil {
+	// Requirements: 8.3 - Parent notification is stored in the outbox with the permit
+	event := outbox.NewEvent(outbox.EventPermitCreated, outbox.Payload{
+		SchoolID:  schoolID,
+		StudentID: student.ID,
+		Notification: outbox.ParentNotification(
+			models.NotificationTypePermit,
+			"Izin Keluar Sekolah",
+			fmt.Sprintf("%s mendapat izin keluar sekolah pukul %s. Alasan: %s", student.Name, permit.ExitTime.Format("15:04"), permit.Reason),
+			map[string]interface{}{
+				"student_id": fmt.Sprintf("%d", student.ID),
+				"exit_time":  permit.ExitTime.Format(time.RFC3339),
+			},
+		),
+	})
+
+	if err := s.repo.CreatePermit(ctx, permit, event); err != nil {
 		return nil, err
 	}
 
@@ -533,9 +604,6 @@ func (s *service) CreatePermit(ctx context.Context, schoolID, createdBy uint, re
 		return nil, err
 	}
 
-	// TODO: Trigger notification to parent (async)
-	// Requirements: 8.3 - WHEN a permit is issued, THE System SHALL trigger notification to the parent
-
 	return toPermitResponse(permit), nil
 }
 
@@ -710,6 +778,9 @@ func (s *service) GetCounselingNoteByID(ctx context.Context, id uint, includeInt
 	}
 
 	if includeInternal {
+		if err := s.repo.RecordInternalNoteReads(ctx, "counseling_notes", note.ID); err != nil {
+			return nil, err
+		}
 		return toCounselingNoteFullResponse(note), nil
 	}
 	return toCounselingNoteResponse(note), nil
@@ -724,6 +795,9 @@ func (s *service) GetStudentCounselingNotes(ctx context.Context, studentID uint,
 	}
 
 	if includeInternal {
+		if err := s.recordNoteReads(ctx, notes); err != nil {
+			return nil, err
+		}
 		responses := make([]CounselingNoteFullResponse, len(notes))
 		for i, n := range notes {
 			responses[i] = *toCounselingNoteFullResponse(&n)
@@ -765,6 +839,9 @@ func (s *service) GetCounselingNotes(ctx context.Context, schoolID uint, filter
 	}
 
 	if includeInternal {
+		if err := s.recordNoteReads(ctx, notes); err != nil {
+			return nil, err
+		}
 		responses := make([]CounselingNoteFullResponse, len(notes))
 		for i, n := range notes {
 			responses[i] = *toCounselingNoteFullResponse(&n)
@@ -811,6 +888,15 @@ func (s *service) DeleteCounselingNote(ctx context.Context, id uint) error {
 	return s.repo.DeleteCounselingNote(ctx, id)
 }
 
+// recordNoteReads records in the audit log that the internal notes of counseling notes were shown
+func (s *service) recordNoteReads(ctx context.Context, notes []models.CounselingNote) error {
+	ids := make([]uint, len(notes))
+	for i := range notes {
+		ids[i] = notes[i].ID
+	}
+	return s.repo.RecordInternalNoteReads(ctx, "counseling_notes", ids...)
+}
+
 // ==================== Student BK Profile ====================
 
 // GetStudentBKProfile retrieves a student's complete BK profile
@@ -829,6 +915,15 @@ func (s *service) GetStudentBKProfile(ctx context.Context, studentID uint, inclu
 
 	// Get total points
 	totalPoints, _ := s.repo.GetStudentAchievementPoints(ctx, studentID)
+	violationPoints, _ := s.repo.GetStudentViolationPoints(ctx, studentID)
+
+	// Escalation history, newest first
+	escalationRecords, _ := s.repo.FindEscalationsByStudent(ctx, studentID)
+	escalations := make([]EscalationResponse, len(escalationRecords))
+	for i := range escalationRecords {
+		escalationRecords[i].Student = *student
+		escalations[i] = *toEscalationResponse(&escalationRecords[i])
+	}
 
 	// Limit to recent items (5 each)
 	recentViolations := make([]ViolationResponse, 0)
@@ -856,11 +951,14 @@ func (s *service) GetStudentBKProfile(ctx context.Context, studentID uint, inclu
 	}
 
 	className := ""
-	if student.Class.ID != 0 {
+	if student.Class != nil {
 		className = student.Class.Name
 	}
 
 	if includeInternal {
+		if err := s.recordNoteReads(ctx, counselingNotes[:min(len(counselingNotes), 5)]); err != nil {
+			return nil, err
+		}
 		recentCounseling := make([]CounselingNoteFullResponse, 0)
 		for i, n := range counselingNotes {
 			if i >= 5 {
@@ -884,6 +982,8 @@ func (s *service) GetStudentBKProfile(ctx context.Context, studentID uint, inclu
 			RecentAchievements: recentAchievements,
 			RecentPermits:      recentPermits,
 			RecentCounseling:   recentCounseling,
+			ViolationPoints:    -violationPoints,
+			Escalations:        escalations,
 		}, nil
 	}
 
@@ -910,6 +1010,8 @@ func (s *service) GetStudentBKProfile(ctx context.Context, studentID uint, inclu
 		RecentAchievements: recentAchievements,
 		RecentPermits:      recentPermits,
 		RecentCounseling:   recentCounseling,
+		ViolationPoints:    -violationPoints,
+		Escalations:        escalations,
 	}, nil
 }
 
@@ -944,15 +1046,33 @@ func (s *service) GetBKDashboard(ctx context.Context, schoolID uint) (*BKDashboa
 	// Get students needing attention
 	studentsNeedingAttention, _ := s.repo.GetStudentsNeedingAttention(ctx, schoolID, 10)
 
+	// Counseling cases: open workload and how closed cases ended
+	now := time.Now()
+	caseCounts, _ := s.repo.GetCounselingCaseCounts(ctx, schoolID)
+	overdueFollowUps, _ := s.repo.GetOverdueFollowUpCount(ctx, schoolID, now)
+	upcomingSessions, _ := s.repo.GetUpcomingSessionCount(ctx, schoolID, now, now.AddDate(0, 0, 7))
+	closedCases, _ := s.repo.FindRecentlyClosedCases(ctx, schoolID, 5)
+	recentCaseOutcomes := make([]CounselingCaseResponse, len(closedCases))
+	for i := range closedCases {
+		recentCaseOutcomes[i] = *toCounselingCaseResponse(&closedCases[i], false, now)
+	}
+
 	return &BKDashboardResponse{
 		TotalViolations:          int(violationCount),
 		TotalAchievements:        int(achievementCount),
 		TotalPermits:             int(permitCount),
 		ActivePermits:            int(activePermitCount),
 		TotalCounseling:          int(counselingCount),
+		OpenCases:                int(caseCounts[models.CounselingCaseStatusOpen]),
+		InProgressCases:          int(caseCounts[models.CounselingCaseStatusInProgress]),
+		ResolvedCases:            int(caseCounts[models.CounselingCaseStatusResolved]),
+		ReferredCases:            int(caseCounts[models.CounselingCaseStatusReferred]),
+		OverdueFollowUps:         int(overdueFollowUps),
+		UpcomingSessions:         int(upcomingSessions),
 		RecentViolations:         recentViolations,
 		RecentAchievements:       recentAchievements,
 		StudentsNeedingAttention: studentsNeedingAttention,
+		RecentCaseOutcomes:       recentCaseOutcomes,
 	}, nil
 }
 
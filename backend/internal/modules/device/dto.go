@@ -1,6 +1,10 @@
 package device
 
-import "time"
+import (
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
 
 // RegisterDeviceRequest represents the request to register a new device
 // Requirements: 2.1 - WHEN a Super_Admin registers a new device, THE System SHALL generate a unique API key
@@ -28,20 +32,30 @@ type DeviceResponse struct {
 	LastSeenAt  *time.Time `json:"last_seen_at"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Connection state, derived from the last contact and the offline threshold
+	IsOnline        bool                          `json:"is_online"`
+	Status          models.DeviceConnectionStatus `json:"status"` // online, offline or inactive
+	FirmwareVersion string                        `json:"firmware_version,omitempty"`
+	LastHeartbeatAt *time.Time                    `json:"last_heartbeat_at"`
 }
 
 // DeviceWithAPIKeyResponse includes the API key (only returned on creation/regeneration)
 type DeviceWithAPIKeyResponse struct {
 	DeviceResponse
-	APIKey string `json:"api_key"`
+	APIKey        string `json:"api_key"`
+	SigningSecret string `json:"signing_secret"` // Key for request signatures, flashed into the device with the API key
 }
 
-// DeviceAPIKeyResponse represents the response for getting a device's API key
+// DeviceAPIKeyResponse represents the response for getting a device's API key information
+// The key itself is only returned on creation/regeneration; this identifies it by its prefix
 type DeviceAPIKeyResponse struct {
-	DeviceID   uint   `json:"device_id"`
-	DeviceCode string `json:"device_code"`
-	SchoolName string `json:"school_name,omitempty"`
-	APIKey     string `json:"api_key"`
+	DeviceID             uint       `json:"device_id"`
+	DeviceCode           string     `json:"device_code"`
+	SchoolName           string     `json:"school_name,omitempty"`
+	APIKeyPrefix         string     `json:"api_key_prefix"`
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at,omitempty"`
+	Message              string     `json:"message"`
 }
 
 // DeviceListResponse represents a paginated list of devices
@@ -98,12 +112,19 @@ type APIKeyValidationResponse struct {
 	Message  string `json:"message,omitempty"`
 }
 
+// RegenerateAPIKeyRequest represents the optional request body for API key regeneration
+type RegenerateAPIKeyRequest struct {
+	RevokePrevious bool `json:"revoke_previous"` // Invalidate the old key immediately, e.g. when it was leaked
+}
+
 // RegenerateAPIKeyResponse represents the response for API key regeneration
 type RegenerateAPIKeyResponse struct {
-	DeviceID   uint   `json:"device_id"`
-	DeviceCode string `json:"device_code"`
-	APIKey     string `json:"api_key"`
-	Message    string `json:"message"`
+	DeviceID             uint       `json:"device_id"`
+	DeviceCode           string     `json:"device_code"`
+	APIKey               string     `json:"api_key"`
+	SigningSecret        string     `json:"signing_secret"` // Key for request signatures, flashed into the device with the API key
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at,omitempty"`
+	Message              string     `json:"message"`
 }
 
 // RevokeAPIKeyResponse represents the response for API key revocation
@@ -112,3 +133,47 @@ type RevokeAPIKeyResponse struct {
 	DeviceCode string `json:"device_code"`
 	Message    string `json:"message"`
 }
+
+// HeartbeatRequest represents a health report from an ESP32 device
+type HeartbeatRequest struct {
+	APIKey          string `json:"api_key"` // Not needed for signed requests
+	FirmwareVersion string `json:"firmware_version"`
+	RSSI            int    `json:"rssi"`
+	UptimeSeconds   int64  `json:"uptime_seconds"`
+	FreeHeap        int64  `json:"free_heap"`
+	QueuedTaps      int    `json:"queued_taps"`
+}
+
+// HeartbeatResponse represents the response to a device heartbeat
+// The server time lets the device correct its clock for signed requests
+type HeartbeatResponse struct {
+	DeviceID   uint      `json:"device_id"`
+	ServerTime time.Time `json:"server_time"`
+}
+
+// HeartbeatItem represents a stored heartbeat in the device history
+type HeartbeatItem struct {
+	ID              uint      `json:"id"`
+	FirmwareVersion string    `json:"firmware_version"`
+	RSSI            int       `json:"rssi"`
+	UptimeSeconds   int64     `json:"uptime_seconds"`
+	FreeHeap        int64     `json:"free_heap"`
+	QueuedTaps      int       `json:"queued_taps"`
+	IPAddress       string    `json:"ip_address,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// HeartbeatListResponse represents a paginated device heartbeat history
+type HeartbeatListResponse struct {
+	Device     DeviceResponse  `json:"device"`
+	Heartbeats []HeartbeatItem `json:"heartbeats"`
+	Pagination PaginationMeta  `json:"pagination"`
+}
+
+// HeartbeatFilter represents filter options for the heartbeat history
+type HeartbeatFilter struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+	Page      int
+	PageSize  int
+}
@@ -0,0 +1,142 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+const (
+	// MaxFirmwareSize is the maximum firmware binary size (3MB)
+	MaxFirmwareSize = 3 * 1024 * 1024
+	// MaxFirmwareInstallAttempts is how many failed installs stop a release from being offered to a device again
+	MaxFirmwareInstallAttempts = 3
+)
+
+var (
+	ErrFirmwareNotFound         = errors.New("firmware tidak ditemukan")
+	ErrFirmwareVersionRequired  = errors.New("versi firmware wajib diisi")
+	ErrFirmwareVersionExists    = errors.New("versi firmware sudah ada")
+	ErrFirmwareFileRequired     = errors.New("file firmware wajib diunggah")
+	ErrFirmwareFileTooLarge     = errors.New("ukuran file firmware melebihi batas maksimum 3MB")
+	ErrInvalidFirmwareFile      = errors.New("file bukan firmware ESP8266 yang valid")
+	ErrFirmwareChecksumMismatch = errors.New("checksum file firmware tidak cocok")
+	ErrFirmwareInUse            = errors.New("firmware masih memiliki rollout aktif")
+	ErrRolloutNotFound          = errors.New("rollout firmware tidak ditemukan")
+	ErrInvalidRollout           = errors.New("data rollout firmware tidak valid")
+	ErrFirmwareNotAssigned      = errors.New("firmware tidak ditugaskan ke perangkat ini")
+	ErrInvalidInstallStatus     = errors.New("status instalasi harus downloading, installed, atau failed")
+)
+
+// FirmwareService defines the interface for over-the-air firmware updates
+type FirmwareService interface {
+	// Super admin management
+	UploadFirmware(ctx context.Context, req UploadFirmwareRequest, fileName string, file io.Reader, uploadedBy uint) (*FirmwareResponse, error)
+	GetFirmwareList(ctx context.Context) ([]FirmwareResponse, error)
+	GetFirmware(ctx context.Context, id uint) (*FirmwareDetailResponse, error)
+	DeleteFirmware(ctx context.Context, id uint) error
+	CreateRollout(ctx context.Context, firmwareID uint, req CreateRolloutRequest, createdBy uint) (*RolloutResponse, error)
+	UpdateRollout(ctx context.Context, rolloutID uint, req UpdateRolloutRequest) (*RolloutResponse, error)
+	GetFirmwareInstalls(ctx context.Context, firmwareID uint) ([]FirmwareInstallResponse, error)
+
+	// Device endpoints
+	CheckUpdate(ctx context.Context, req FirmwareCheckRequest) (*FirmwareCheckResponse, error)
+	GetDownload(ctx context.Context, apiKey string, firmwareID uint) (*models.FirmwareRelease, error)
+	ReportInstall(ctx context.Context, req FirmwareReportRequest) (*FirmwareInstallResponse, error)
+}
+
+// UploadFirmwareRequest represents the form fields of a firmware upload
+type UploadFirmwareRequest struct {
+	Version  string `form:"version"`
+	Notes    string `form:"notes"`
+	Checksum string `form:"checksum"` // Optional SHA-256 to verify the upload against
+}
+
+// FirmwareResponse represents a firmware release in responses
+type FirmwareResponse struct {
+	ID         uint      `json:"id"`
+	Version    string    `json:"version"`
+	Checksum   string    `json:"checksum"`
+	MD5        string    `json:"md5"`
+	Size       int64     `json:"size"`
+	FileName   string    `json:"file_name"`
+	Notes      string    `json:"notes"`
+	UploadedBy uint      `json:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FirmwareDetailResponse represents a firmware release with its rollouts and install summary
+type FirmwareDetailResponse struct {
+	FirmwareResponse
+	Rollouts       []RolloutResponse `json:"rollouts"`
+	InstalledCount int64             `json:"installed_count"`
+	FailedCount    int64             `json:"failed_count"`
+}
+
+// CreateRolloutRequest represents the request to assign a firmware release
+type CreateRolloutRequest struct {
+	TargetType models.FirmwareTargetType `json:"target_type" validate:"required,oneof=device school all"`
+	TargetID   *uint                     `json:"target_id"`
+	Percentage int                       `json:"percentage"` // Defaults to 100
+}
+
+// UpdateRolloutRequest represents the request to change a staged rollout
+type UpdateRolloutRequest struct {
+	Percentage *int  `json:"percentage"`
+	IsActive   *bool `json:"is_active"`
+}
+
+// RolloutResponse represents a firmware rollout in responses
+type RolloutResponse struct {
+	ID              uint                      `json:"id"`
+	FirmwareID      uint                      `json:"firmware_id"`
+	FirmwareVersion string                    `json:"firmware_version,omitempty"`
+	TargetType      models.FirmwareTargetType `json:"target_type"`
+	TargetID        *uint                     `json:"target_id,omitempty"`
+	Percentage      int                       `json:"percentage"`
+	IsActive        bool                      `json:"is_active"`
+	CreatedBy       uint                      `json:"created_by"`
+	CreatedAt       time.Time                 `json:"created_at"`
+	UpdatedAt       time.Time                 `json:"updated_at"`
+}
+
+// FirmwareInstallResponse represents an install report in responses
+type FirmwareInstallResponse struct {
+	ID              uint                         `json:"id"`
+	DeviceID        uint                         `json:"device_id"`
+	DeviceCode      string                       `json:"device_code,omitempty"`
+	FirmwareID      uint                         `json:"firmware_id"`
+	Status          models.FirmwareInstallStatus `json:"status"`
+	PreviousVersion string                       `json:"previous_version"`
+	Message         string                       `json:"message,omitempty"`
+	CreatedAt       time.Time                    `json:"created_at"`
+}
+
+// FirmwareCheckRequest represents an update check from an ESP32 device
+type FirmwareCheckRequest struct {
+	APIKey         string `json:"api_key"`         // Not needed for signed requests
+	CurrentVersion string `json:"current_version"` // Defaults to the version of the last heartbeat
+}
+
+// FirmwareCheckResponse tells a device whether an update is pending
+type FirmwareCheckResponse struct {
+	UpdateAvailable bool   `json:"update_available"`
+	FirmwareID      uint   `json:"firmware_id,omitempty"`
+	Version         string `json:"version,omitempty"`
+	Checksum        string `json:"checksum,omitempty"`
+	MD5             string `json:"md5,omitempty"`
+	Size            int64  `json:"size,omitempty"`
+	DownloadURL     string `json:"download_url,omitempty"`
+	Message         string `json:"message,omitempty"`
+}
+
+// FirmwareReportRequest represents an install progress or result from a device
+type FirmwareReportRequest struct {
+	APIKey     string                       `json:"api_key"` // Not needed for signed requests
+	FirmwareID uint                         `json:"firmware_id"`
+	Status     models.FirmwareInstallStatus `json:"status"`
+	Message    string                       `json:"message"`
+}
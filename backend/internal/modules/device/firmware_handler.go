@@ -0,0 +1,578 @@
+package device
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FirmwareHandler handles HTTP requests for over-the-air firmware updates
+type FirmwareHandler struct {
+	service FirmwareService
+}
+
+// NewFirmwareHandler creates a new firmware handler
+func NewFirmwareHandler(service FirmwareService) *FirmwareHandler {
+	return &FirmwareHandler{service: service}
+}
+
+// RegisterRoutesWithoutGroup registers firmware management routes (Super Admin only)
+// Use this when the router already has the correct path prefix
+func (h *FirmwareHandler) RegisterRoutesWithoutGroup(router fiber.Router) {
+	router.Post("", h.UploadFirmware)
+	router.Get("", h.GetFirmwareList)
+	router.Put("/rollouts/:rolloutId", h.UpdateRollout)
+	router.Get("/:id", h.GetFirmware)
+	router.Delete("/:id", h.DeleteFirmware)
+	router.Get("/:id/installs", h.GetFirmwareInstalls)
+	router.Post("/:id/rollouts", h.CreateRollout)
+}
+
+// RegisterPublicRoutes registers public firmware routes (for NodeMCU devices)
+func (h *FirmwareHandler) RegisterPublicRoutes(router fiber.Router) {
+	router.Post("/firmware/check", h.CheckUpdate)
+	router.Get("/firmware/:id/download", h.Download)
+	router.Post("/firmware/report", h.ReportInstall)
+}
+
+// UploadFirmware handles uploading a firmware build
+// @Summary Upload firmware
+// @Description Upload a firmware binary (.bin, max 3MB). The SHA-256 and MD5 are computed on upload; an optional checksum is verified
+// @Tags Firmware
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Firmware binary"
+// @Param version formData string true "Firmware version"
+// @Param notes formData string false "Release notes"
+// @Param checksum formData string false "Expected SHA-256 of the binary"
+// @Success 201 {object} FirmwareResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/firmware [post]
+func (h *FirmwareHandler) UploadFirmware(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+
+	var req UploadFirmwareRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_REQUIRED",
+				"message": "File firmware wajib diunggah",
+			},
+		})
+	}
+	if fileHeader.Size > MaxFirmwareSize {
+		return h.handleError(c, ErrFirmwareFileTooLarge)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_INVALID",
+				"message": "Gagal membuka file",
+			},
+		})
+	}
+	defer file.Close()
+
+	response, err := h.service.UploadFirmware(c.Context(), req, fileHeader.Filename, file, userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Firmware berhasil diunggah",
+	})
+}
+
+// GetFirmwareList handles listing firmware releases
+// @Summary List firmware releases
+// @Description Get all uploaded firmware releases, newest first
+// @Tags Firmware
+// @Produce json
+// @Success 200 {array} FirmwareResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/firmware [get]
+func (h *FirmwareHandler) GetFirmwareList(c *fiber.Ctx) error {
+	response, err := h.service.GetFirmwareList(c.Context())
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetFirmware handles getting a firmware release
+// @Summary Get firmware release
+// @Description Get a firmware release with its rollouts and install summary
+// @Tags Firmware
+// @Produce json
+// @Param id path int true "Firmware ID"
+// @Success 200 {object} FirmwareDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/firmware/{id} [get]
+func (h *FirmwareHandler) GetFirmware(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID firmware tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.GetFirmware(c.Context(), uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// DeleteFirmware handles deleting a firmware release
+// @Summary Delete firmware release
+// @Description Delete a firmware release without active rollouts, with its install reports and binary
+// @Tags Firmware
+// @Produce json
+// @Param id path int true "Firmware ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/firmware/{id} [delete]
+func (h *FirmwareHandler) DeleteFirmware(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID firmware tidak valid",
+			},
+		})
+	}
+
+	if err := h.service.DeleteFirmware(c.Context(), uint(id)); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Firmware berhasil dihapus",
+	})
+}
+
+// GetFirmwareInstalls handles listing the install reports of a firmware release
+// @Summary Get firmware install reports
+// @Description Get the latest install reports sent by devices for a firmware release
+// @Tags Firmware
+// @Produce json
+// @Param id path int true "Firmware ID"
+// @Success 200 {array} FirmwareInstallResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/firmware/{id}/installs [get]
+func (h *FirmwareHandler) GetFirmwareInstalls(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID firmware tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.GetFirmwareInstalls(c.Context(), uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// CreateRollout handles assigning a firmware release
+// @Summary Create firmware rollout
+// @Description Assign a firmware release to a device, a school or all devices, optionally to a percentage of them
+// @Tags Firmware
+// @Accept json
+// @Produce json
+// @Param id path int true "Firmware ID"
+// @Param request body CreateRolloutRequest true "Rollout target"
+// @Success 201 {object} RolloutResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/firmware/{id}/rollouts [post]
+func (h *FirmwareHandler) CreateRollout(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID firmware tidak valid",
+			},
+		})
+	}
+
+	var req CreateRolloutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.CreateRollout(c.Context(), uint(id), req, userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Rollout firmware berhasil dibuat",
+	})
+}
+
+// UpdateRollout handles changing a firmware rollout
+// @Summary Update firmware rollout
+// @Description Change the percentage of a staged rollout, or pause and resume it
+// @Tags Firmware
+// @Accept json
+// @Produce json
+// @Param rolloutId path int true "Rollout ID"
+// @Param request body UpdateRolloutRequest true "Rollout changes"
+// @Success 200 {object} RolloutResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/firmware/rollouts/{rolloutId} [put]
+func (h *FirmwareHandler) UpdateRollout(c *fiber.Ctx) error {
+	rolloutID, err := strconv.ParseUint(c.Params("rolloutId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID rollout tidak valid",
+			},
+		})
+	}
+
+	var req UpdateRolloutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.UpdateRollout(c.Context(), uint(rolloutID), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Rollout firmware berhasil diperbarui",
+	})
+}
+
+// CheckUpdate handles an update check from a device
+// @Summary Check for firmware update
+// @Description Tell a device whether a firmware update is assigned to it. Signed requests may omit api_key
+// @Tags Firmware
+// @Accept json
+// @Produce json
+// @Param request body FirmwareCheckRequest true "Current firmware"
+// @Success 200 {object} FirmwareCheckResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/public/firmware/check [post]
+func (h *FirmwareHandler) CheckUpdate(c *fiber.Ctx) error {
+	var req FirmwareCheckRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "VAL_INVALID_FORMAT",
+					"message": "Format data tidak valid",
+				},
+			})
+		}
+	}
+
+	response, err := h.service.CheckUpdate(AuthenticatedContext(c), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// Download handles a firmware download from a device
+// @Summary Download firmware
+// @Description Download the firmware binary assigned to the device. Supports Range requests to resume an interrupted download.
+// @Description Unsigned requests pass the API key in the X-API-Key header
+// @Tags Firmware
+// @Produce application/octet-stream
+// @Param id path int true "Firmware ID"
+// @Param X-API-Key header string false "Device API key (unsigned requests)"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/public/firmware/{id}/download [get]
+func (h *FirmwareHandler) Download(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID firmware tidak valid",
+			},
+		})
+	}
+
+	release, err := h.service.GetDownload(AuthenticatedContext(c), c.Get("X-API-Key"), uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	// The ESP8266 updater verifies the image against x-MD5
+	c.Set("X-Checksum-SHA256", release.Checksum)
+	c.Set("x-MD5", release.MD5)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="firmware-`+release.Version+`.bin"`)
+
+	// SendFile serves Range requests with 206 Partial Content
+	if err := c.SendFile(release.FilePath); err != nil {
+		return h.handleError(c, err)
+	}
+	return nil
+}
+
+// ReportInstall handles an install report from a device
+// @Summary Report firmware install
+// @Description Report download progress or the install result of a firmware update. Signed requests may omit api_key
+// @Tags Firmware
+// @Accept json
+// @Produce json
+// @Param request body FirmwareReportRequest true "Install report"
+// @Success 200 {object} FirmwareInstallResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/public/firmware/report [post]
+func (h *FirmwareHandler) ReportInstall(c *fiber.Ctx) error {
+	var req FirmwareReportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.ReportInstall(AuthenticatedContext(c), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// handleError handles service errors and returns appropriate HTTP responses
+func (h *FirmwareHandler) handleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrFirmwareNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_FIRMWARE",
+				"message": "Firmware tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrRolloutNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_ROLLOUT",
+				"message": "Rollout firmware tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrDeviceNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_DEVICE",
+				"message": "Perangkat tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrFirmwareVersionRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Versi firmware wajib diisi",
+			},
+		})
+	case errors.Is(err, ErrFirmwareVersionExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_DUPLICATE_ENTRY",
+				"message": "Versi firmware sudah ada",
+			},
+		})
+	case errors.Is(err, ErrFirmwareFileRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_REQUIRED",
+				"message": "File firmware wajib diunggah",
+			},
+		})
+	case errors.Is(err, ErrFirmwareFileTooLarge):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_TOO_LARGE",
+				"message": "Ukuran file firmware melebihi batas maksimum 3MB",
+			},
+		})
+	case errors.Is(err, ErrInvalidFirmwareFile):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_INVALID",
+				"message": "File bukan firmware ESP8266 yang valid",
+			},
+		})
+	case errors.Is(err, ErrFirmwareChecksumMismatch):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_CHECKSUM_MISMATCH",
+				"message": "Checksum file firmware tidak cocok",
+			},
+		})
+	case errors.Is(err, ErrFirmwareInUse):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_STATE",
+				"message": "Firmware masih memiliki rollout aktif, nonaktifkan rollout terlebih dahulu",
+			},
+		})
+	case errors.Is(err, ErrInvalidRollout):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrInvalidInstallStatus):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Status instalasi harus downloading, installed, atau failed",
+			},
+		})
+	case errors.Is(err, ErrFirmwareNotAssigned):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_FIRMWARE_NOT_ASSIGNED",
+				"message": "Firmware tidak ditugaskan ke perangkat ini",
+			},
+		})
+	case errors.Is(err, ErrInvalidAPIKey):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTH_INVALID_API_KEY",
+				"message": "API key tidak valid",
+			},
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "INTERNAL_ERROR",
+				"message": "Terjadi kesalahan pada server",
+			},
+		})
+	}
+}
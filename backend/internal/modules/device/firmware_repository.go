@@ -0,0 +1,225 @@
+package device
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// FirmwareRepository defines the interface for firmware data operations
+type FirmwareRepository interface {
+	// Releases
+	CreateRelease(ctx context.Context, release *models.FirmwareRelease) error
+	FindReleases(ctx context.Context) ([]models.FirmwareRelease, error)
+	FindReleaseByID(ctx context.Context, id uint) (*models.FirmwareRelease, error)
+	ReleaseVersionExists(ctx context.Context, version string) (bool, error)
+	DeleteRelease(ctx context.Context, id uint) error
+
+	// Rollouts
+	CreateRollout(ctx context.Context, rollout *models.FirmwareRollout) error
+	FindRolloutByID(ctx context.Context, id uint) (*models.FirmwareRollout, error)
+	FindRollouts(ctx context.Context, firmwareID uint) ([]models.FirmwareRollout, error)
+	FindActiveRollouts(ctx context.Context) ([]models.FirmwareRollout, error)
+	CountActiveRollouts(ctx context.Context, firmwareID uint) (int64, error)
+	UpdateRollout(ctx context.Context, rollout *models.FirmwareRollout) error
+
+	// Install reports
+	CreateInstall(ctx context.Context, install *models.FirmwareInstall, version string) error
+	FindInstalls(ctx context.Context, firmwareID uint) ([]models.FirmwareInstall, error)
+	CountInstalls(ctx context.Context, firmwareID uint, status models.FirmwareInstallStatus) (int64, error)
+	CountDeviceInstalls(ctx context.Context, deviceID, firmwareID uint, status models.FirmwareInstallStatus) (int64, error)
+}
+
+// firmwareRepository implements the FirmwareRepository interface
+type firmwareRepository struct {
+	db *gorm.DB
+}
+
+// NewFirmwareRepository creates a new firmware repository
+func NewFirmwareRepository(db *gorm.DB) FirmwareRepository {
+	return &firmwareRepository{db: db}
+}
+
+// CreateRelease creates a new firmware release
+func (r *firmwareRepository) CreateRelease(ctx context.Context, release *models.FirmwareRelease) error {
+	result := r.db.WithContext(ctx).Create(release)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return ErrFirmwareVersionExists
+		}
+		return result.Error
+	}
+	return nil
+}
+
+// FindReleases retrieves all firmware releases, newest first
+func (r *firmwareRepository) FindReleases(ctx context.Context) ([]models.FirmwareRelease, error) {
+	var releases []models.FirmwareRelease
+	err := r.db.WithContext(ctx).
+		Order("created_at DESC").
+		Find(&releases).Error
+	return releases, err
+}
+
+// FindReleaseByID retrieves a firmware release by ID
+func (r *firmwareRepository) FindReleaseByID(ctx context.Context, id uint) (*models.FirmwareRelease, error) {
+	var release models.FirmwareRelease
+	err := r.db.WithContext(ctx).First(&release, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFirmwareNotFound
+		}
+		return nil, err
+	}
+	return &release, nil
+}
+
+// ReleaseVersionExists checks if a firmware version was already uploaded
+func (r *firmwareRepository) ReleaseVersionExists(ctx context.Context, version string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.FirmwareRelease{}).
+		Where("version = ?", version).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteRelease deletes a firmware release with its rollouts and install reports
+func (r *firmwareRepository) DeleteRelease(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("firmware_id = ?", id).Delete(&models.FirmwareInstall{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("firmware_id = ?", id).Delete(&models.FirmwareRollout{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.FirmwareRelease{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrFirmwareNotFound
+		}
+		return nil
+	})
+}
+
+// CreateRollout creates a new firmware rollout
+func (r *firmwareRepository) CreateRollout(ctx context.Context, rollout *models.FirmwareRollout) error {
+	return r.db.WithContext(ctx).Create(rollout).Error
+}
+
+// FindRolloutByID retrieves a firmware rollout by ID
+func (r *firmwareRepository) FindRolloutByID(ctx context.Context, id uint) (*models.FirmwareRollout, error) {
+	var rollout models.FirmwareRollout
+	err := r.db.WithContext(ctx).
+		Preload("Firmware").
+		First(&rollout, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRolloutNotFound
+		}
+		return nil, err
+	}
+	return &rollout, nil
+}
+
+// FindRollouts retrieves the rollouts of a firmware release, newest first
+func (r *firmwareRepository) FindRollouts(ctx context.Context, firmwareID uint) ([]models.FirmwareRollout, error) {
+	var rollouts []models.FirmwareRollout
+	err := r.db.WithContext(ctx).
+		Where("firmware_id = ?", firmwareID).
+		Order("created_at DESC").
+		Find(&rollouts).Error
+	return rollouts, err
+}
+
+// FindActiveRollouts retrieves all active rollouts with their firmware release
+func (r *firmwareRepository) FindActiveRollouts(ctx context.Context) ([]models.FirmwareRollout, error) {
+	var rollouts []models.FirmwareRollout
+	err := r.db.WithContext(ctx).
+		Preload("Firmware").
+		Where("is_active = ?", true).
+		Order("created_at DESC").
+		Find(&rollouts).Error
+	return rollouts, err
+}
+
+// CountActiveRollouts counts the active rollouts of a firmware release
+func (r *firmwareRepository) CountActiveRollouts(ctx context.Context, firmwareID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.FirmwareRollout{}).
+		Where("firmware_id = ? AND is_active = ?", firmwareID, true).
+		Count(&count).Error
+	return count, err
+}
+
+// UpdateRollout updates the percentage and state of a rollout
+func (r *firmwareRepository) UpdateRollout(ctx context.Context, rollout *models.FirmwareRollout) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.FirmwareRollout{}).
+		Where("id = ?", rollout.ID).
+		Updates(map[string]interface{}{
+			"percentage": rollout.Percentage,
+			"is_active":  rollout.IsActive,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRolloutNotFound
+	}
+	return nil
+}
+
+// CreateInstall stores an install report; a successful install also updates the device firmware version
+func (r *firmwareRepository) CreateInstall(ctx context.Context, install *models.FirmwareInstall, version string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(install).Error; err != nil {
+			return err
+		}
+		if install.Status != models.FirmwareInstallInstalled {
+			return nil
+		}
+		return tx.Model(&models.Device{}).
+			Where("id = ?", install.DeviceID).
+			Update("firmware_version", version).Error
+	})
+}
+
+// FindInstalls retrieves the latest install reports of a firmware release
+func (r *firmwareRepository) FindInstalls(ctx context.Context, firmwareID uint) ([]models.FirmwareInstall, error) {
+	var installs []models.FirmwareInstall
+	err := r.db.WithContext(ctx).
+		Preload("Device").
+		Where("firmware_id = ?", firmwareID).
+		Order("created_at DESC").
+		Limit(500).
+		Find(&installs).Error
+	return installs, err
+}
+
+// CountInstalls counts the devices that reported a status for a firmware release
+func (r *firmwareRepository) CountInstalls(ctx context.Context, firmwareID uint, status models.FirmwareInstallStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.FirmwareInstall{}).
+		Where("firmware_id = ? AND status = ?", firmwareID, status).
+		Distinct("device_id").
+		Count(&count).Error
+	return count, err
+}
+
+// CountDeviceInstalls counts the reports with a status from one device for a firmware release
+func (r *firmwareRepository) CountDeviceInstalls(ctx context.Context, deviceID, firmwareID uint, status models.FirmwareInstallStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.FirmwareInstall{}).
+		Where("device_id = ? AND firmware_id = ? AND status = ?", deviceID, firmwareID, status).
+		Count(&count).Error
+	return count, err
+}
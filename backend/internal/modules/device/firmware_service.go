@@ -0,0 +1,480 @@
+package device
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// firmwareService implements the FirmwareService interface
+type firmwareService struct {
+	repo       FirmwareRepository
+	deviceRepo Repository
+	storageDir string
+}
+
+// NewFirmwareService creates a new firmware service that stores binaries in storageDir
+func NewFirmwareService(repo FirmwareRepository, deviceRepo Repository, storageDir string) FirmwareService {
+	return &firmwareService{
+		repo:       repo,
+		deviceRepo: deviceRepo,
+		storageDir: storageDir,
+	}
+}
+
+// UploadFirmware stores a firmware binary and registers it as a new release
+// The binary is checksummed while it is written; an optional checksum in the request must match.
+func (s *firmwareService) UploadFirmware(ctx context.Context, req UploadFirmwareRequest, fileName string, file io.Reader, uploadedBy uint) (*FirmwareResponse, error) {
+	version := strings.TrimSpace(req.Version)
+	if version == "" {
+		return nil, ErrFirmwareVersionRequired
+	}
+	if file == nil {
+		return nil, ErrFirmwareFileRequired
+	}
+
+	exists, err := s.repo.ReleaseVersionExists(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrFirmwareVersionExists
+	}
+
+	if err := os.MkdirAll(s.storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create firmware storage: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.storageDir, "upload-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("create firmware file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	sha := sha256.New()
+	sum := md5.New()
+	size, err := io.Copy(io.MultiWriter(tmp, sha, sum), io.LimitReader(file, MaxFirmwareSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("write firmware file: %w", err)
+	}
+	if size == 0 {
+		return nil, ErrFirmwareFileRequired
+	}
+	if size > MaxFirmwareSize {
+		return nil, ErrFirmwareFileTooLarge
+	}
+	if err := checkFirmwareImage(tmp.Name()); err != nil {
+		return nil, err
+	}
+
+	checksum := hex.EncodeToString(sha.Sum(nil))
+	if expected := strings.ToLower(strings.TrimSpace(req.Checksum)); expected != "" && expected != checksum {
+		return nil, ErrFirmwareChecksumMismatch
+	}
+
+	// Files are named by content, so re-uploading the same build under another version reuses it
+	path := filepath.Join(s.storageDir, checksum+".bin")
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("store firmware file: %w", err)
+	}
+
+	release := &models.FirmwareRelease{
+		Version:    version,
+		Checksum:   checksum,
+		MD5:        hex.EncodeToString(sum.Sum(nil)),
+		Size:       size,
+		FileName:   filepath.Base(fileName),
+		FilePath:   path,
+		Notes:      strings.TrimSpace(req.Notes),
+		UploadedBy: uploadedBy,
+	}
+	if err := release.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRelease(ctx, release); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Firmware %s uploaded (%d bytes, sha256 %s)", release.Version, release.Size, release.Checksum)
+
+	return toFirmwareResponse(release), nil
+}
+
+// checkFirmwareImage rejects files that are not an ESP8266 image or a gzip-compressed image
+func checkFirmwareImage(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return ErrInvalidFirmwareFile
+	}
+	if magic[0] == 0xE9 || (magic[0] == 0x1F && magic[1] == 0x8B) {
+		return nil
+	}
+	return ErrInvalidFirmwareFile
+}
+
+// GetFirmwareList retrieves all firmware releases
+func (s *firmwareService) GetFirmwareList(ctx context.Context) ([]FirmwareResponse, error) {
+	releases, err := s.repo.FindReleases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]FirmwareResponse, len(releases))
+	for i := range releases {
+		responses[i] = *toFirmwareResponse(&releases[i])
+	}
+	return responses, nil
+}
+
+// GetFirmware retrieves a firmware release with its rollouts and install summary
+func (s *firmwareService) GetFirmware(ctx context.Context, id uint) (*FirmwareDetailResponse, error) {
+	release, err := s.repo.FindReleaseByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rollouts, err := s.repo.FindRollouts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	installed, err := s.repo.CountInstalls(ctx, id, models.FirmwareInstallInstalled)
+	if err != nil {
+		return nil, err
+	}
+	failed, err := s.repo.CountInstalls(ctx, id, models.FirmwareInstallFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &FirmwareDetailResponse{
+		FirmwareResponse: *toFirmwareResponse(release),
+		Rollouts:         make([]RolloutResponse, len(rollouts)),
+		InstalledCount:   installed,
+		FailedCount:      failed,
+	}
+	for i := range rollouts {
+		rollouts[i].Firmware = *release
+		response.Rollouts[i] = *toRolloutResponse(&rollouts[i])
+	}
+	return response, nil
+}
+
+// DeleteFirmware deletes a firmware release that is no longer rolled out
+func (s *firmwareService) DeleteFirmware(ctx context.Context, id uint) error {
+	release, err := s.repo.FindReleaseByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	active, err := s.repo.CountActiveRollouts(ctx, id)
+	if err != nil {
+		return err
+	}
+	if active > 0 {
+		return ErrFirmwareInUse
+	}
+
+	if err := s.repo.DeleteRelease(ctx, id); err != nil {
+		return err
+	}
+
+	// The file may be shared with another release of the same build
+	releases, err := s.repo.FindReleases(ctx)
+	if err != nil {
+		return nil
+	}
+	for _, other := range releases {
+		if other.FilePath == release.FilePath {
+			return nil
+		}
+	}
+	if err := os.Remove(release.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Failed to remove firmware file %s: %v", release.FilePath, err)
+	}
+	return nil
+}
+
+// CreateRollout assigns a firmware release to a device, a school or all devices
+func (s *firmwareService) CreateRollout(ctx context.Context, firmwareID uint, req CreateRolloutRequest, createdBy uint) (*RolloutResponse, error) {
+	release, err := s.repo.FindReleaseByID(ctx, firmwareID)
+	if err != nil {
+		return nil, err
+	}
+
+	rollout := &models.FirmwareRollout{
+		FirmwareID: firmwareID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Percentage: req.Percentage,
+		IsActive:   true,
+		CreatedBy:  createdBy,
+	}
+	if rollout.Percentage == 0 {
+		rollout.Percentage = 100
+	}
+	if err := rollout.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRollout, err.Error())
+	}
+
+	switch rollout.TargetType {
+	case models.FirmwareTargetDevice:
+		if _, err := s.deviceRepo.FindByID(ctx, *rollout.TargetID); err != nil {
+			return nil, err
+		}
+	case models.FirmwareTargetSchool:
+		devices, err := s.deviceRepo.FindBySchoolID(ctx, *rollout.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		if len(devices) == 0 {
+			return nil, fmt.Errorf("%w: sekolah tidak memiliki perangkat", ErrInvalidRollout)
+		}
+	}
+
+	if err := s.repo.CreateRollout(ctx, rollout); err != nil {
+		return nil, err
+	}
+	rollout.Firmware = *release
+
+	return toRolloutResponse(rollout), nil
+}
+
+// UpdateRollout changes the percentage of a staged rollout or pauses/resumes it
+func (s *firmwareService) UpdateRollout(ctx context.Context, rolloutID uint, req UpdateRolloutRequest) (*RolloutResponse, error) {
+	rollout, err := s.repo.FindRolloutByID(ctx, rolloutID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Percentage != nil {
+		rollout.Percentage = *req.Percentage
+	}
+	if req.IsActive != nil {
+		rollout.IsActive = *req.IsActive
+	}
+	if err := rollout.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRollout, err.Error())
+	}
+
+	if err := s.repo.UpdateRollout(ctx, rollout); err != nil {
+		return nil, err
+	}
+
+	return toRolloutResponse(rollout), nil
+}
+
+// GetFirmwareInstalls retrieves the install reports of a firmware release
+func (s *firmwareService) GetFirmwareInstalls(ctx context.Context, firmwareID uint) ([]FirmwareInstallResponse, error) {
+	if _, err := s.repo.FindReleaseByID(ctx, firmwareID); err != nil {
+		return nil, err
+	}
+
+	installs, err := s.repo.FindInstalls(ctx, firmwareID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]FirmwareInstallResponse, len(installs))
+	for i := range installs {
+		responses[i] = *toFirmwareInstallResponse(&installs[i])
+	}
+	return responses, nil
+}
+
+// CheckUpdate tells a device whether a firmware update is assigned to it
+// The most specific matching rollout wins (device, then school, then all devices); among
+// rollouts of the same scope the newest wins. Assigning an older build is a rollback.
+func (s *firmwareService) CheckUpdate(ctx context.Context, req FirmwareCheckRequest) (*FirmwareCheckResponse, error) {
+	device, err := authenticateDevice(ctx, s.deviceRepo, req.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := s.assignedRelease(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+
+	currentVersion := strings.TrimSpace(req.CurrentVersion)
+	if currentVersion == "" {
+		currentVersion = device.FirmwareVersion
+	}
+	if release == nil || release.Version == currentVersion {
+		return &FirmwareCheckResponse{
+			UpdateAvailable: false,
+			Message:         "Firmware sudah terbaru",
+		}, nil
+	}
+
+	// Stop offering a build that keeps failing on this device
+	failed, err := s.repo.CountDeviceInstalls(ctx, device.ID, release.ID, models.FirmwareInstallFailed)
+	if err != nil {
+		return nil, err
+	}
+	if failed >= MaxFirmwareInstallAttempts {
+		return &FirmwareCheckResponse{
+			UpdateAvailable: false,
+			Message:         fmt.Sprintf("Instalasi firmware %s gagal %d kali, pembaruan dihentikan", release.Version, failed),
+		}, nil
+	}
+
+	return &FirmwareCheckResponse{
+		UpdateAvailable: true,
+		FirmwareID:      release.ID,
+		Version:         release.Version,
+		Checksum:        release.Checksum,
+		MD5:             release.MD5,
+		Size:            release.Size,
+		DownloadURL:     fmt.Sprintf("/api/v1/public/firmware/%d/download", release.ID),
+		Message:         "Pembaruan firmware tersedia",
+	}, nil
+}
+
+// GetDownload returns the firmware release a device may download
+// Devices can only download a build that is currently assigned to them.
+func (s *firmwareService) GetDownload(ctx context.Context, apiKey string, firmwareID uint) (*models.FirmwareRelease, error) {
+	device, err := authenticateDevice(ctx, s.deviceRepo, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := s.assignedRelease(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	if release == nil || release.ID != firmwareID {
+		return nil, ErrFirmwareNotAssigned
+	}
+	return release, nil
+}
+
+// ReportInstall stores the progress or result of an update reported by a device
+func (s *firmwareService) ReportInstall(ctx context.Context, req FirmwareReportRequest) (*FirmwareInstallResponse, error) {
+	device, err := authenticateDevice(ctx, s.deviceRepo, req.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Status.IsValid() {
+		return nil, ErrInvalidInstallStatus
+	}
+
+	release, err := s.repo.FindReleaseByID(ctx, req.FirmwareID)
+	if err != nil {
+		return nil, err
+	}
+
+	message := strings.TrimSpace(req.Message)
+	if len(message) > 1000 {
+		message = message[:1000]
+	}
+
+	install := &models.FirmwareInstall{
+		DeviceID:        device.ID,
+		FirmwareID:      release.ID,
+		Status:          req.Status,
+		PreviousVersion: device.FirmwareVersion,
+		Message:         message,
+	}
+	if err := install.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateInstall(ctx, install, release.Version); err != nil {
+		return nil, err
+	}
+
+	if install.Status == models.FirmwareInstallFailed {
+		log.Printf("Firmware %s failed to install on device %s: %s", release.Version, device.DeviceCode, message)
+	}
+
+	install.Device = *device
+	return toFirmwareInstallResponse(install), nil
+}
+
+// assignedRelease returns the firmware release assigned to a device, or nil if there is none
+func (s *firmwareService) assignedRelease(ctx context.Context, device *models.Device) (*models.FirmwareRelease, error) {
+	rollouts, err := s.repo.FindActiveRollouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rollouts are ordered newest first, so the first match of the highest priority wins
+	var selected *models.FirmwareRollout
+	for i := range rollouts {
+		rollout := &rollouts[i]
+		if !rollout.Includes(device) {
+			continue
+		}
+		if selected == nil || rollout.TargetType.Priority() > selected.TargetType.Priority() {
+			selected = rollout
+		}
+	}
+
+	if selected == nil {
+		return nil, nil
+	}
+	return &selected.Firmware, nil
+}
+
+// toFirmwareResponse converts a FirmwareRelease model to FirmwareResponse DTO
+func toFirmwareResponse(release *models.FirmwareRelease) *FirmwareResponse {
+	return &FirmwareResponse{
+		ID:         release.ID,
+		Version:    release.Version,
+		Checksum:   release.Checksum,
+		MD5:        release.MD5,
+		Size:       release.Size,
+		FileName:   release.FileName,
+		Notes:      release.Notes,
+		UploadedBy: release.UploadedBy,
+		CreatedAt:  release.CreatedAt,
+	}
+}
+
+// toRolloutResponse converts a FirmwareRollout model to RolloutResponse DTO
+func toRolloutResponse(rollout *models.FirmwareRollout) *RolloutResponse {
+	return &RolloutResponse{
+		ID:              rollout.ID,
+		FirmwareID:      rollout.FirmwareID,
+		FirmwareVersion: rollout.Firmware.Version,
+		TargetType:      rollout.TargetType,
+		TargetID:        rollout.TargetID,
+		Percentage:      rollout.Percentage,
+		IsActive:        rollout.IsActive,
+		CreatedBy:       rollout.CreatedBy,
+		CreatedAt:       rollout.CreatedAt,
+		UpdatedAt:       rollout.UpdatedAt,
+	}
+}
+
+// toFirmwareInstallResponse converts a FirmwareInstall model to FirmwareInstallResponse DTO
+func toFirmwareInstallResponse(install *models.FirmwareInstall) *FirmwareInstallResponse {
+	return &FirmwareInstallResponse{
+		ID:              install.ID,
+		DeviceID:        install.DeviceID,
+		DeviceCode:      install.Device.DeviceCode,
+		FirmwareID:      install.FirmwareID,
+		Status:          install.Status,
+		PreviousVersion: install.PreviousVersion,
+		Message:         install.Message,
+		CreatedAt:       install.CreatedAt,
+	}
+}
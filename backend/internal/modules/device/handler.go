@@ -26,6 +26,7 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 	devices.Get("/grouped", h.GetDevicesGrouped)
 	devices.Get("/:id", h.GetDevice)
 	devices.Get("/:id/api-key", h.GetDeviceAPIKey)
+	devices.Get("/:id/heartbeats", h.GetDeviceHeartbeats)
 	devices.Put("/:id", h.UpdateDevice)
 	devices.Post("/:id/revoke", h.RevokeAPIKey)
 	devices.Post("/:id/regenerate", h.RegenerateAPIKey)
@@ -40,6 +41,7 @@ func (h *Handler) RegisterRoutesWithoutGroup(router fiber.Router) {
 	router.Get("/grouped", h.GetDevicesGrouped)
 	router.Get("/:id", h.GetDevice)
 	router.Get("/:id/api-key", h.GetDeviceAPIKey)
+	router.Get("/:id/heartbeats", h.GetDeviceHeartbeats)
 	router.Put("/:id", h.UpdateDevice)
 	router.Post("/:id/revoke", h.RevokeAPIKey)
 	router.Post("/:id/regenerate", h.RegenerateAPIKey)
@@ -49,6 +51,7 @@ func (h *Handler) RegisterRoutesWithoutGroup(router fiber.Router) {
 // RegisterPublicRoutes registers public device routes (for ESP32 devices)
 func (h *Handler) RegisterPublicRoutes(router fiber.Router) {
 	router.Post("/devices/validate-key", h.ValidateAPIKey)
+	router.Post("/devices/heartbeat", h.Heartbeat)
 }
 
 // RegisterDevice handles device registration
@@ -111,7 +114,7 @@ func (h *Handler) RegisterDevice(c *fiber.Ctx) error {
 
 // GetDevices handles listing all devices
 // @Summary List all devices
-// @Description Get a paginated list of all RFID devices
+// @Description Get a paginated list of all RFID devices with their online/offline state
 // @Tags Devices
 // @Produce json
 // @Param school_id query int false "Filter by school ID"
@@ -219,8 +222,8 @@ func (h *Handler) GetDevice(c *fiber.Ctx) error {
 }
 
 // GetDeviceAPIKey handles getting a device's API key
-// @Summary Get device API key
-// @Description Get the API key for a specific device (Super Admin only)
+// @Summary Get device API key information
+// @Description Get the API key prefix and rotation status for a specific device (Super Admin only)
 // @Tags Devices
 // @Produce json
 // @Param id path int true "Device ID"
@@ -341,10 +344,12 @@ func (h *Handler) RevokeAPIKey(c *fiber.Ctx) error {
 
 // RegenerateAPIKey handles regenerating a device's API key
 // @Summary Regenerate device API key
-// @Description Generate a new API key for a device
+// @Description Generate a new API key for a device. The previous key keeps working during the rotation grace period unless revoke_previous is set
 // @Tags Devices
+// @Accept json
 // @Produce json
 // @Param id path int true "Device ID"
+// @Param request body RegenerateAPIKeyRequest false "Rotation options"
 // @Success 200 {object} RegenerateAPIKeyResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -364,7 +369,20 @@ func (h *Handler) RegenerateAPIKey(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.service.RegenerateAPIKey(c.Context(), uint(id))
+	var req RegenerateAPIKeyRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "VAL_INVALID_FORMAT",
+					"message": "Format data tidak valid",
+				},
+			})
+		}
+	}
+
+	response, err := h.service.RegenerateAPIKey(c.Context(), uint(id), req)
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -412,11 +430,11 @@ func (h *Handler) DeleteDevice(c *fiber.Ctx) error {
 
 // ValidateAPIKey handles API key validation (for ESP32 devices)
 // @Summary Validate device API key
-// @Description Validate an API key and return device information
+// @Description Validate an API key and return device information. Signed requests may send an empty body
 // @Tags Devices
 // @Accept json
 // @Produce json
-// @Param request body map[string]string true "API key"
+// @Param request body map[string]string false "API key"
 // @Success 200 {object} APIKeyValidationResponse
 // @Failure 400 {object} ErrorResponse
 // @Router /api/v1/devices/validate-key [post]
@@ -424,7 +442,7 @@ func (h *Handler) ValidateAPIKey(c *fiber.Ctx) error {
 	var req struct {
 		APIKey string `json:"api_key"`
 	}
-	if err := c.BodyParser(&req); err != nil {
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
@@ -434,7 +452,7 @@ func (h *Handler) ValidateAPIKey(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.service.ValidateAPIKey(c.Context(), req.APIKey)
+	response, err := h.service.ValidateAPIKey(AuthenticatedContext(c), req.APIKey)
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -512,6 +530,14 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 				"message": "API key tidak valid",
 			},
 		})
+	case errors.Is(err, ErrInvalidHeartbeat):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Data heartbeat tidak valid",
+			},
+		})
 	default:
 		// Return the actual error message for better debugging
 		errMsg := err.Error()
@@ -0,0 +1,103 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+var (
+	ErrInvalidHeartbeat = errors.New("data heartbeat tidak valid")
+)
+
+// RecordHeartbeat stores a health report from a device
+// The heartbeat also counts as device activity, so it keeps the device online and ends
+// an offline alert.
+func (s *service) RecordHeartbeat(ctx context.Context, req HeartbeatRequest, ipAddress string) (*HeartbeatResponse, error) {
+	device, err := authenticateDevice(ctx, s.repo, req.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	heartbeat := &models.DeviceHeartbeat{
+		DeviceID:        device.ID,
+		FirmwareVersion: strings.TrimSpace(req.FirmwareVersion),
+		RSSI:            req.RSSI,
+		UptimeSeconds:   req.UptimeSeconds,
+		FreeHeap:        req.FreeHeap,
+		QueuedTaps:      req.QueuedTaps,
+		IPAddress:       ipAddress,
+		CreatedAt:       now,
+	}
+	if err := heartbeat.Validate(); err != nil {
+		return nil, ErrInvalidHeartbeat
+	}
+
+	if err := s.repo.RecordHeartbeat(ctx, heartbeat); err != nil {
+		return nil, err
+	}
+
+	return &HeartbeatResponse{
+		DeviceID:   device.ID,
+		ServerTime: now,
+	}, nil
+}
+
+// GetDeviceHeartbeats retrieves the heartbeat history of a device
+func (s *service) GetDeviceHeartbeats(ctx context.Context, id uint, filter HeartbeatFilter) (*HeartbeatListResponse, error) {
+	device, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set defaults
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 50
+	}
+	if filter.PageSize > 500 {
+		filter.PageSize = 500
+	}
+
+	heartbeats, total, err := s.repo.FindHeartbeats(ctx, id, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]HeartbeatItem, len(heartbeats))
+	for i, heartbeat := range heartbeats {
+		items[i] = HeartbeatItem{
+			ID:              heartbeat.ID,
+			FirmwareVersion: heartbeat.FirmwareVersion,
+			RSSI:            heartbeat.RSSI,
+			UptimeSeconds:   heartbeat.UptimeSeconds,
+			FreeHeap:        heartbeat.FreeHeap,
+			QueuedTaps:      heartbeat.QueuedTaps,
+			IPAddress:       heartbeat.IPAddress,
+			CreatedAt:       heartbeat.CreatedAt,
+		}
+	}
+
+	// Calculate total pages
+	totalPages := int(total) / filter.PageSize
+	if int(total)%filter.PageSize > 0 {
+		totalPages++
+	}
+
+	return &HeartbeatListResponse{
+		Device:     *toDeviceResponse(device, s.offlineAfter),
+		Heartbeats: items,
+		Pagination: PaginationMeta{
+			Page:       filter.Page,
+			PageSize:   filter.PageSize,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
@@ -0,0 +1,134 @@
+package device
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Heartbeat handles a health report from an ESP32 device
+// @Summary Device heartbeat
+// @Description Report firmware version, WiFi signal, uptime, free heap and queued taps. Signed requests may omit api_key
+// @Tags Devices
+// @Accept json
+// @Produce json
+// @Param request body HeartbeatRequest true "Device health data"
+// @Success 200 {object} HeartbeatResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/public/devices/heartbeat [post]
+func (h *Handler) Heartbeat(c *fiber.Ctx) error {
+	var req HeartbeatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.RecordHeartbeat(AuthenticatedContext(c), req, c.IP())
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetDeviceHeartbeats handles getting the heartbeat history of a device
+// @Summary Get device heartbeat history
+// @Description Get the health reports of a device, newest first
+// @Tags Devices
+// @Produce json
+// @Param id path int true "Device ID"
+// @Param start_date query string false "Start date (YYYY-MM-DD or RFC3339)"
+// @Param end_date query string false "End date (YYYY-MM-DD or RFC3339)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(50)
+// @Success 200 {object} HeartbeatListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/devices/{id}/heartbeats [get]
+func (h *Handler) GetDeviceHeartbeats(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID perangkat tidak valid",
+			},
+		})
+	}
+
+	var filter HeartbeatFilter
+
+	if startDate := c.Query("start_date"); startDate != "" {
+		start, _, err := parseHeartbeatTime(startDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "VAL_INVALID_FORMAT",
+					"message": "Format tanggal mulai tidak valid",
+				},
+			})
+		}
+		filter.StartDate = &start
+	}
+
+	if endDate := c.Query("end_date"); endDate != "" {
+		end, dateOnly, err := parseHeartbeatTime(endDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "VAL_INVALID_FORMAT",
+					"message": "Format tanggal selesai tidak valid",
+				},
+			})
+		}
+		// A date without time includes the whole day
+		if dateOnly {
+			end = end.AddDate(0, 0, 1)
+		}
+		filter.EndDate = &end
+	}
+
+	if page, err := strconv.Atoi(c.Query("page", "1")); err == nil && page > 0 {
+		filter.Page = page
+	}
+
+	if pageSize, err := strconv.Atoi(c.Query("page_size", "50")); err == nil && pageSize > 0 {
+		filter.PageSize = pageSize
+	}
+
+	response, err := h.service.GetDeviceHeartbeats(c.Context(), uint(id), filter)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// parseHeartbeatTime parses an RFC3339 timestamp or a YYYY-MM-DD date in server local time
+// It reports whether the value was a date without time
+func parseHeartbeatTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	return t, true, err
+}
@@ -0,0 +1,214 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/school-management/backend/internal/config"
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/outbox"
+)
+
+// DefaultOfflineThreshold is the default silence after which a device is considered offline
+const DefaultOfflineThreshold = 5 * time.Minute
+
+// OfflineThreshold returns the configured offline threshold
+func OfflineThreshold(cfg config.DeviceConfig) time.Duration {
+	if cfg.OfflineThresholdMinutes <= 0 {
+		return DefaultOfflineThreshold
+	}
+	return time.Duration(cfg.OfflineThresholdMinutes) * time.Minute
+}
+
+// OfflineMonitor alerts school admins and super admins when a device stops contacting the
+// server while an attendance schedule is open. A device is alerted once per outage; its next
+// contact clears the alert. It also prunes heartbeat history past the retention period.
+type OfflineMonitor struct {
+	repo         Repository
+	interval     time.Duration
+	offlineAfter time.Duration
+	retention    time.Duration
+	lastPrune    time.Time
+	stopCh       chan struct{}
+	wg           sync.WaitGroup
+	running      bool
+	mu           sync.Mutex
+}
+
+// NewOfflineMonitor creates a new offline monitor that checks devices every minute
+func NewOfflineMonitor(repo Repository, cfg config.DeviceConfig) *OfflineMonitor {
+	return &OfflineMonitor{
+		repo:         repo,
+		interval:     time.Minute,
+		offlineAfter: OfflineThreshold(cfg),
+		retention:    time.Duration(cfg.HeartbeatRetentionDays) * 24 * time.Hour,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start starts the offline monitor
+func (m *OfflineMonitor) Start() {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = true
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go m.runLoop()
+
+	log.Println("Device offline monitor started")
+}
+
+// Stop stops the offline monitor gracefully
+func (m *OfflineMonitor) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	m.mu.Unlock()
+
+	close(m.stopCh)
+	m.wg.Wait()
+
+	log.Println("Device offline monitor stopped")
+}
+
+// runLoop runs a check immediately and then on every tick
+func (m *OfflineMonitor) runLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.RunOnce(context.Background(), time.Now())
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case now := <-ticker.C:
+			m.RunOnce(context.Background(), now)
+		}
+	}
+}
+
+// RunOnce alerts devices that went silent during an open schedule of their school
+func (m *OfflineMonitor) RunOnce(ctx context.Context, now time.Time) {
+	devices, err := m.repo.FindSilentDevices(ctx, now.Add(-m.offlineAfter))
+	if err != nil {
+		log.Printf("Device offline monitor: failed to load silent devices: %v", err)
+		return
+	}
+
+	// Schedule state and recipients are shared by the devices of a school
+	scheduleOpen := make(map[uint]bool)
+	recipients := make(map[uint][]uint)
+
+	for i := range devices {
+		device := &devices[i]
+
+		open, checked := scheduleOpen[device.SchoolID]
+		if !checked {
+			open, err = m.hasOpenSchedule(ctx, &device.School, now)
+			if err != nil {
+				log.Printf("Device offline monitor: failed to load schedules for school %d: %v", device.SchoolID, err)
+				continue
+			}
+			scheduleOpen[device.SchoolID] = open
+		}
+		if !open {
+			continue // Devices may be switched off outside school hours
+		}
+
+		userIDs, loaded := recipients[device.SchoolID]
+		if !loaded {
+			userIDs, err = m.repo.FindAlertRecipientIDs(ctx, device.SchoolID)
+			if err != nil {
+				log.Printf("Device offline monitor: failed to load recipients for school %d: %v", device.SchoolID, err)
+				continue
+			}
+			recipients[device.SchoolID] = userIDs
+		}
+
+		marked, err := m.repo.MarkOfflineAlerted(ctx, device, now, offlineEvent(device, userIDs))
+		if err != nil {
+			log.Printf("Device offline monitor: failed to alert device %d: %v", device.ID, err)
+			continue
+		}
+		if marked {
+			log.Printf("Device offline monitor: device %s (school %d) silent since %s",
+				device.DeviceCode, device.SchoolID, device.LastSeenAt.Format(time.RFC3339))
+		}
+	}
+
+	m.pruneHeartbeats(ctx, now)
+}
+
+// hasOpenSchedule checks if an attendance schedule of the school is open now, in the school's timezone
+func (m *OfflineMonitor) hasOpenSchedule(ctx context.Context, school *models.School, now time.Time) (bool, error) {
+	localNow := now.In(school.GetLocation())
+	date := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, localNow.Location())
+
+	// Holidays yield no schedules; date overrides are already applied
+	schedules, err := m.repo.FindSchedulesForDate(ctx, school.ID, date)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range schedules {
+		if !localNow.Before(schedules[i].StartTimeOn(date)) && !localNow.After(schedules[i].EndTimeOn(date)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// pruneHeartbeats removes heartbeat history past the retention period, at most once an hour
+func (m *OfflineMonitor) pruneHeartbeats(ctx context.Context, now time.Time) {
+	if m.retention <= 0 || now.Sub(m.lastPrune) < time.Hour {
+		return
+	}
+	m.lastPrune = now
+
+	deleted, err := m.repo.DeleteHeartbeatsBefore(ctx, now.Add(-m.retention))
+	if err != nil {
+		log.Printf("Device offline monitor: failed to prune heartbeats: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Device offline monitor: pruned %d old heartbeats", deleted)
+	}
+}
+
+// offlineEvent builds the outbox event that notifies the admins of an offline device
+func offlineEvent(device *models.Device, userIDs []uint) *outbox.Event {
+	lastSeen := device.LastSeenAt.In(device.School.GetLocation())
+
+	name := device.DeviceCode
+	if device.Description != "" {
+		name = fmt.Sprintf("%s (%s)", device.DeviceCode, device.Description)
+	}
+
+	return outbox.NewEvent(outbox.EventDeviceOffline, outbox.Payload{
+		SchoolID: device.SchoolID,
+		Notification: &outbox.NotificationPayload{
+			Type:    models.NotificationTypeDevice,
+			Title:   "Perangkat RFID Offline",
+			Message: fmt.Sprintf("Perangkat %s di %s tidak terhubung sejak pukul %s", name, device.School.Name, lastSeen.Format("15:04")),
+			Data: map[string]interface{}{
+				"device_id":    device.ID,
+				"device_code":  device.DeviceCode,
+				"last_seen_at": device.LastSeenAt.Format(time.RFC3339),
+			},
+			UserIDs: userIDs,
+		},
+	})
+}
@@ -2,127 +2,190 @@ package device
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"sync"
+	"fmt"
+	"log"
 	"time"
+
+	"github.com/school-management/backend/internal/shared/redis"
 )
 
 var (
-	ErrNoPairingSession    = errors.New("tidak ada sesi pairing aktif")
+	ErrNoPairingSession      = errors.New("tidak ada sesi pairing aktif")
 	ErrPairingSessionExpired = errors.New("sesi pairing sudah kadaluarsa")
-	ErrStudentAlreadyPaired = errors.New("siswa sudah memiliki kartu RFID")
-	ErrRFIDAlreadyUsed     = errors.New("kartu RFID sudah digunakan siswa lain")
+	ErrStudentAlreadyPaired  = errors.New("siswa sudah memiliki kartu RFID")
+	ErrRFIDAlreadyUsed       = errors.New("kartu RFID sudah digunakan siswa lain")
+	ErrPairingBusy           = errors.New("sesi pairing sedang diproses, coba lagi")
+	ErrNotBulkPairing        = errors.New("sesi pairing bukan pairing massal")
+	ErrClassNotFound         = errors.New("kelas tidak ditemukan")
+	ErrEmptyPairingRoster    = errors.New("semua siswa di kelas ini sudah memiliki kartu RFID")
+)
+
+const (
+	pairingSessionKeyPrefix = "pairing:session:"
+	pairingLockKeyPrefix    = "pairing:lock:"
+	pairingLockTTL          = 10 * time.Second
+)
+
+// PairingMode represents how a pairing session assigns cards
+type PairingMode string
+
+const (
+	PairingModeSingle PairingMode = "single" // One card for one student
+	PairingModeBulk   PairingMode = "bulk"   // Cards for a class roster, one student after another
 )
 
+// PairingStudent represents a student on a bulk pairing roster
+type PairingStudent struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	NIS  string `json:"nis"`
+}
+
 // PairingSession represents an active RFID pairing session
+// For bulk pairing, StudentID and StudentName are the roster student waiting for a card.
 type PairingSession struct {
-	StudentID   uint      `json:"student_id"`
-	StudentName string    `json:"student_name"`
-	SchoolID    uint      `json:"school_id"`
-	DeviceID    uint      `json:"device_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	ExpiresAt   time.Time `json:"expires_at"`
+	Mode        PairingMode      `json:"mode"`
+	StudentID   uint             `json:"student_id"`
+	StudentName string           `json:"student_name"`
+	SchoolID    uint             `json:"school_id"`
+	DeviceID    uint             `json:"device_id"`
+	ClassID     *uint            `json:"class_id,omitempty"`
+	ClassName   string           `json:"class_name,omitempty"`
+	Roster      []PairingStudent `json:"roster,omitempty"`
+	Position    int              `json:"position"` // Index of the current roster student
+	Paired      int              `json:"paired"`
+	Skipped     int              `json:"skipped"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ExpiresAt   time.Time        `json:"expires_at"`
 }
 
-// PairingManager manages RFID pairing sessions
-type PairingManager struct {
-	sessions map[uint]*PairingSession // key: device_id
-	mu       sync.RWMutex
+// IsBulk checks if the session walks through a class roster
+func (s *PairingSession) IsBulk() bool {
+	return s.Mode == PairingModeBulk
 }
 
-// NewPairingManager creates a new pairing manager
-func NewPairingManager() *PairingManager {
-	pm := &PairingManager{
-		sessions: make(map[uint]*PairingSession),
-	}
-	// Start cleanup goroutine
-	go pm.cleanupExpiredSessions()
-	return pm
-}
-
-// StartPairingSession starts a new pairing session for a device
-func (pm *PairingManager) StartPairingSession(deviceID, studentID uint, studentName string, schoolID uint, duration time.Duration) *PairingSession {
-	pm.mu.Lock()
-	defer pm.mu.Unlock()
-
-	session := &PairingSession{
-		StudentID:   studentID,
-		StudentName: studentName,
-		SchoolID:    schoolID,
-		DeviceID:    deviceID,
-		CreatedAt:   time.Now(),
-		ExpiresAt:   time.Now().Add(duration),
+// Advance moves a bulk session to the next roster student
+// It returns false when the roster is finished.
+func (s *PairingSession) Advance() bool {
+	s.Position++
+	if s.Position >= len(s.Roster) {
+		return false
 	}
+	s.StudentID = s.Roster[s.Position].ID
+	s.StudentName = s.Roster[s.Position].Name
+	return true
+}
+
+// PairingEventStatus represents what happened in a pairing session
+type PairingEventStatus string
 
-	pm.sessions[deviceID] = session
-	return session
+const (
+	PairingEventStarted   PairingEventStatus = "started"
+	PairingEventPaired    PairingEventStatus = "paired"
+	PairingEventRejected  PairingEventStatus = "rejected" // Card already used by another student
+	PairingEventSkipped   PairingEventStatus = "skipped"
+	PairingEventCancelled PairingEventStatus = "cancelled"
+	PairingEventCompleted PairingEventStatus = "completed" // Bulk roster finished
+)
+
+// PairingEvent is pushed to school admins over WebSocket when a pairing session changes
+type PairingEvent struct {
+	Status      PairingEventStatus      `json:"status"`
+	SchoolID    uint                    `json:"school_id"`
+	DeviceID    uint                    `json:"device_id"`
+	StudentID   uint                    `json:"student_id,omitempty"` // Student the event is about
+	StudentName string                  `json:"student_name,omitempty"`
+	RFIDCode    string                  `json:"rfid_code,omitempty"`
+	Message     string                  `json:"message"`
+	Session     *PairingSessionResponse `json:"session"` // Session state after the event
+	Timestamp   time.Time               `json:"timestamp"`
 }
 
-// GetPairingSession gets the active pairing session for a device
-func (pm *PairingManager) GetPairingSession(deviceID uint) (*PairingSession, error) {
-	pm.mu.RLock()
-	defer pm.mu.RUnlock()
+// PairingNotifier pushes pairing events to the school admins
+type PairingNotifier interface {
+	BroadcastPairingStatus(schoolID uint, status interface{})
+}
+
+// PairingManager stores RFID pairing sessions in Redis, so a session started on one
+// instance is visible to the instance that receives the device tap. Sessions expire with
+// their Redis TTL.
+type PairingManager struct {
+	redis *redis.Client
+}
 
-	session, exists := pm.sessions[deviceID]
-	if !exists {
+// NewPairingManager creates a new Redis-backed pairing manager
+func NewPairingManager(redisClient *redis.Client) *PairingManager {
+	return &PairingManager{redis: redisClient}
+}
+
+// SaveSession stores a pairing session until it expires
+func (pm *PairingManager) SaveSession(ctx context.Context, session *PairingSession) error {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return ErrPairingSessionExpired
+	}
+	return pm.redis.Set(ctx, pairingSessionKey(session.DeviceID), session, ttl)
+}
+
+// GetPairingSession gets the active pairing session for a device
+func (pm *PairingManager) GetPairingSession(ctx context.Context, deviceID uint) (*PairingSession, error) {
+	data, err := pm.redis.Get(ctx, pairingSessionKey(deviceID))
+	if err != nil {
+		return nil, err
+	}
+	if data == "" {
 		return nil, ErrNoPairingSession
 	}
 
+	var session PairingSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, fmt.Errorf("decode pairing session: %w", err)
+	}
+
+	// Guards against clock drift between instances around the TTL
 	if time.Now().After(session.ExpiresAt) {
 		return nil, ErrPairingSessionExpired
 	}
 
-	return session, nil
+	return &session, nil
 }
 
-// CompletePairingSession completes and removes a pairing session
-func (pm *PairingManager) CompletePairingSession(deviceID uint) {
-	pm.mu.Lock()
-	defer pm.mu.Unlock()
-	delete(pm.sessions, deviceID)
+// DeleteSession removes the pairing session of a device
+func (pm *PairingManager) DeleteSession(ctx context.Context, deviceID uint) error {
+	return pm.redis.Delete(ctx, pairingSessionKey(deviceID))
 }
 
-// CancelPairingSession cancels a pairing session
-func (pm *PairingManager) CancelPairingSession(deviceID uint) {
-	pm.mu.Lock()
-	defer pm.mu.Unlock()
-	delete(pm.sessions, deviceID)
-}
-
-// GetAllActiveSessions returns all active pairing sessions
-func (pm *PairingManager) GetAllActiveSessions() []*PairingSession {
-	pm.mu.RLock()
-	defer pm.mu.RUnlock()
-
-	sessions := make([]*PairingSession, 0)
-	now := time.Now()
-	for _, session := range pm.sessions {
-		if now.Before(session.ExpiresAt) {
-			sessions = append(sessions, session)
-		}
+// Lock serializes changes to the pairing session of a device across instances
+// The returned function releases the lock.
+func (pm *PairingManager) Lock(ctx context.Context, deviceID uint) (func(), error) {
+	key := fmt.Sprintf("%s%d", pairingLockKeyPrefix, deviceID)
+	acquired, err := pm.redis.SetNX(ctx, key, time.Now().Unix(), pairingLockTTL)
+	if err != nil {
+		return nil, err
 	}
-	return sessions
-}
-
-// cleanupExpiredSessions periodically removes expired sessions
-func (pm *PairingManager) cleanupExpiredSessions() {
-	ticker := time.NewTicker(30 * time.Second)
-	for range ticker.C {
-		pm.mu.Lock()
-		now := time.Now()
-		for deviceID, session := range pm.sessions {
-			if now.After(session.ExpiresAt) {
-				delete(pm.sessions, deviceID)
-			}
-		}
-		pm.mu.Unlock()
+	if !acquired {
+		return nil, ErrPairingBusy
 	}
+	return func() {
+		if err := pm.redis.Delete(context.Background(), key); err != nil {
+			log.Printf("Failed to release pairing lock for device %d: %v", deviceID, err)
+		}
+	}, nil
+}
+
+// pairingSessionKey returns the Redis key of the pairing session of a device
+func pairingSessionKey(deviceID uint) string {
+	return fmt.Sprintf("%s%d", pairingSessionKeyPrefix, deviceID)
 }
 
 // PairingService handles RFID card pairing operations
 type PairingService interface {
 	StartPairing(ctx context.Context, req StartPairingRequest) (*PairingSessionResponse, error)
+	StartBulkPairing(ctx context.Context, req StartBulkPairingRequest) (*PairingSessionResponse, error)
 	ProcessRFIDPairing(ctx context.Context, req RFIDPairingRequest) (*RFIDPairingResponse, error)
+	SkipPairingStudent(ctx context.Context, deviceID uint) (*PairingSessionResponse, error)
 	CancelPairing(ctx context.Context, deviceID uint) error
 	GetPairingStatus(ctx context.Context, deviceID uint) (*PairingSessionResponse, error)
 }
@@ -133,20 +196,34 @@ type StartPairingRequest struct {
 	StudentID uint `json:"student_id" validate:"required"`
 }
 
+// StartBulkPairingRequest represents the request to pair cards for a class roster
+// The roster holds the active students of the class without a card, ordered by name.
+type StartBulkPairingRequest struct {
+	DeviceID uint `json:"device_id" validate:"required"`
+	ClassID  uint `json:"class_id" validate:"required"`
+}
+
 // RFIDPairingRequest represents the request from ESP32 during pairing mode
 type RFIDPairingRequest struct {
-	APIKey   string `json:"api_key" validate:"required"`
+	APIKey   string `json:"api_key"` // Not needed for signed requests
 	RFIDCode string `json:"rfid_code" validate:"required"`
 }
 
 // PairingSessionResponse represents the pairing session status
 type PairingSessionResponse struct {
-	Active      bool      `json:"active"`
-	StudentID   uint      `json:"student_id,omitempty"`
-	StudentName string    `json:"student_name,omitempty"`
-	DeviceID    uint      `json:"device_id,omitempty"`
-	ExpiresAt   time.Time `json:"expires_at,omitempty"`
-	Message     string    `json:"message"`
+	Active      bool        `json:"active"`
+	Mode        PairingMode `json:"mode,omitempty"`
+	StudentID   uint        `json:"student_id,omitempty"`
+	StudentName string      `json:"student_name,omitempty"`
+	DeviceID    uint        `json:"device_id,omitempty"`
+	ClassID     *uint       `json:"class_id,omitempty"`
+	ClassName   string      `json:"class_name,omitempty"`
+	Position    int         `json:"position,omitempty"` // 1-based position of the current student in a bulk roster
+	Total       int         `json:"total,omitempty"`
+	Paired      int         `json:"paired,omitempty"`
+	Skipped     int         `json:"skipped,omitempty"`
+	ExpiresAt   time.Time   `json:"expires_at,omitempty"`
+	Message     string      `json:"message"`
 }
 
 // RFIDPairingResponse represents the response after RFID pairing
@@ -155,5 +232,6 @@ type RFIDPairingResponse struct {
 	StudentID   uint   `json:"student_id,omitempty"`
 	StudentName string `json:"student_name,omitempty"`
 	RFIDCode    string `json:"rfid_code,omitempty"`
+	NextStudent string `json:"next_student,omitempty"` // Bulk pairing: student waiting for the next card
 	Message     string `json:"message"`
 }
@@ -21,6 +21,8 @@ func NewPairingHandler(service PairingService) *PairingHandler {
 func (h *PairingHandler) RegisterRoutes(router fiber.Router) {
 	pairing := router.Group("/pairing")
 	pairing.Post("/start", h.StartPairing)
+	pairing.Post("/bulk/start", h.StartBulkPairing)
+	pairing.Post("/skip/:deviceId", h.SkipPairingStudent)
 	pairing.Post("/cancel/:deviceId", h.CancelPairing)
 	pairing.Get("/status/:deviceId", h.GetPairingStatus)
 }
@@ -28,6 +30,8 @@ func (h *PairingHandler) RegisterRoutes(router fiber.Router) {
 // RegisterRoutesWithoutGroup registers pairing routes without creating a sub-group
 func (h *PairingHandler) RegisterRoutesWithoutGroup(router fiber.Router) {
 	router.Post("/start", h.StartPairing)
+	router.Post("/bulk/start", h.StartBulkPairing)
+	router.Post("/skip/:deviceId", h.SkipPairingStudent)
 	router.Post("/cancel/:deviceId", h.CancelPairing)
 	router.Get("/status/:deviceId", h.GetPairingStatus)
 }
@@ -128,6 +132,62 @@ func (h *PairingHandler) StartPairing(c *fiber.Ctx) error {
 	})
 }
 
+// StartBulkPairing handles starting a bulk pairing session for a class
+// @Summary Start bulk RFID pairing
+// @Description Pair cards for every active student of a class without a card, one student after another in name order. Progress is pushed over WebSocket as pairing_status messages
+// @Tags Pairing
+// @Accept json
+// @Produce json
+// @Param request body StartBulkPairingRequest true "Bulk pairing request"
+// @Success 200 {object} PairingSessionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/pairing/bulk/start [post]
+func (h *PairingHandler) StartBulkPairing(c *fiber.Ctx) error {
+	var req StartBulkPairingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	// Validate required fields
+	if req.DeviceID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "ID perangkat wajib diisi",
+			},
+		})
+	}
+	if req.ClassID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "ID kelas wajib diisi",
+			},
+		})
+	}
+
+	response, err := h.service.StartBulkPairing(c.Context(), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
 // ProcessRFIDPairing handles RFID tap during pairing mode (from ESP32)
 // @Summary Process RFID pairing
 // @Description Process an RFID card tap during pairing mode
@@ -150,7 +210,8 @@ func (h *PairingHandler) ProcessRFIDPairing(c *fiber.Ctx) error {
 		})
 	}
 
-	if req.APIKey == "" {
+	ctx := AuthenticatedContext(c)
+	if _, signed := AuthenticatedDevice(ctx); !signed && req.APIKey == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
@@ -169,7 +230,7 @@ func (h *PairingHandler) ProcessRFIDPairing(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.service.ProcessRFIDPairing(c.Context(), req)
+	response, err := h.service.ProcessRFIDPairing(ctx, req)
 	if err != nil {
 		// For pairing errors, still return the response with success=false
 		if errors.Is(err, ErrRFIDAlreadyUsed) {
@@ -187,6 +248,40 @@ func (h *PairingHandler) ProcessRFIDPairing(c *fiber.Ctx) error {
 	})
 }
 
+// SkipPairingStudent handles skipping the current student of a bulk pairing session
+// @Summary Skip student in bulk pairing
+// @Description Move a bulk pairing session to the next student without pairing a card
+// @Tags Pairing
+// @Produce json
+// @Param deviceId path int true "Device ID"
+// @Success 200 {object} PairingSessionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/pairing/skip/{deviceId} [post]
+func (h *PairingHandler) SkipPairingStudent(c *fiber.Ctx) error {
+	deviceID, err := strconv.ParseUint(c.Params("deviceId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID perangkat tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.SkipPairingStudent(c.Context(), uint(deviceID))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
 // CancelPairing handles cancelling a pairing session
 // @Summary Cancel pairing session
 // @Description Cancel an active pairing session
@@ -303,6 +398,38 @@ func (h *PairingHandler) handleError(c *fiber.Ctx, err error) error {
 				"message": "Sesi pairing sudah kadaluarsa",
 			},
 		})
+	case errors.Is(err, ErrPairingBusy):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_SESSION_BUSY",
+				"message": "Sesi pairing sedang diproses, coba lagi",
+			},
+		})
+	case errors.Is(err, ErrNotBulkPairing):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_STATE",
+				"message": "Sesi pairing bukan pairing massal",
+			},
+		})
+	case errors.Is(err, ErrClassNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_CLASS",
+				"message": "Kelas tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrEmptyPairingRoster):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_EMPTY_ROSTER",
+				"message": "Semua siswa aktif di kelas ini sudah memiliki kartu RFID",
+			},
+		})
 	case errors.Is(err, ErrInvalidAPIKey):
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
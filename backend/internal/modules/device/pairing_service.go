@@ -3,6 +3,7 @@ package device
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 
 const (
 	DefaultPairingDuration = 60 * time.Second // 60 seconds to tap the card
+	BulkPairingIdleTimeout = 3 * time.Minute  // Bulk session ends when no card is tapped for this long
 )
 
 // pairingService implements the PairingService interface
@@ -18,6 +20,7 @@ type pairingService struct {
 	deviceRepo     Repository
 	studentRepo    StudentRepository
 	pairingManager *PairingManager
+	notifier       PairingNotifier
 }
 
 // StudentRepository defines the interface for student operations needed by pairing service
@@ -25,27 +28,28 @@ type StudentRepository interface {
 	FindByID(ctx context.Context, id uint) (*models.Student, error)
 	FindByRFIDCode(ctx context.Context, rfidCode string) (*models.Student, error)
 	UpdateRFIDCode(ctx context.Context, studentID uint, rfidCode string) error
+	FindClassByID(ctx context.Context, id uint) (*models.Class, error)
+	FindUnpairedByClass(ctx context.Context, classID uint) ([]models.Student, error)
 }
 
 // NewPairingService creates a new pairing service
-func NewPairingService(deviceRepo Repository, studentRepo StudentRepository) PairingService {
+// notifier may be nil when pairing progress is not pushed to admins.
+func NewPairingService(deviceRepo Repository, studentRepo StudentRepository, pairingManager *PairingManager, notifier PairingNotifier) PairingService {
 	return &pairingService{
 		deviceRepo:     deviceRepo,
 		studentRepo:    studentRepo,
-		pairingManager: NewPairingManager(),
+		pairingManager: pairingManager,
+		notifier:       notifier,
 	}
 }
 
 // StartPairing starts a new pairing session
 func (s *pairingService) StartPairing(ctx context.Context, req StartPairingRequest) (*PairingSessionResponse, error) {
 	// Validate device exists and is active
-	device, err := s.deviceRepo.FindByID(ctx, req.DeviceID)
+	device, err := s.findActiveDevice(ctx, req.DeviceID)
 	if err != nil {
 		return nil, err
 	}
-	if !device.IsActive {
-		return nil, ErrDeviceInactive
-	}
 
 	// Validate student exists
 	student, err := s.studentRepo.FindByID(ctx, req.StudentID)
@@ -64,38 +68,112 @@ func (s *pairingService) StartPairing(ctx context.Context, req StartPairingReque
 	}
 
 	// Start pairing session
-	session := s.pairingManager.StartPairingSession(
-		device.ID,
-		student.ID,
-		student.Name,
-		device.SchoolID,
-		DefaultPairingDuration,
-	)
+	now := time.Now()
+	session := &PairingSession{
+		Mode:        PairingModeSingle,
+		StudentID:   student.ID,
+		StudentName: student.Name,
+		SchoolID:    device.SchoolID,
+		DeviceID:    device.ID,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(DefaultPairingDuration),
+	}
+	if err := s.pairingManager.SaveSession(ctx, session); err != nil {
+		return nil, err
+	}
 
 	log.Printf("Pairing session started: device=%d, student=%d (%s), expires=%s",
 		device.ID, student.ID, student.Name, session.ExpiresAt.Format("15:04:05"))
 
-	return &PairingSessionResponse{
-		Active:      true,
-		StudentID:   session.StudentID,
-		StudentName: session.StudentName,
-		DeviceID:    session.DeviceID,
-		ExpiresAt:   session.ExpiresAt,
-		Message:     "Sesi pairing dimulai. Silakan tap kartu RFID pada perangkat dalam 60 detik.",
-	}, nil
+	response := toPairingSessionResponse(session, "Sesi pairing dimulai. Silakan tap kartu RFID pada perangkat dalam 60 detik.")
+	s.publish(session, PairingEventStarted, response)
+
+	return response, nil
+}
+
+// StartBulkPairing starts a pairing session that walks through a class roster card by card
+// Each tapped card is paired with the current student and the session moves to the next one.
+func (s *pairingService) StartBulkPairing(ctx context.Context, req StartBulkPairingRequest) (*PairingSessionResponse, error) {
+	device, err := s.findActiveDevice(ctx, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	class, err := s.studentRepo.FindClassByID(ctx, req.ClassID)
+	if err != nil {
+		return nil, err
+	}
+	if class.SchoolID != device.SchoolID {
+		return nil, errors.New("kelas tidak terdaftar di sekolah yang sama dengan perangkat")
+	}
+
+	students, err := s.studentRepo.FindUnpairedByClass(ctx, class.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(students) == 0 {
+		return nil, ErrEmptyPairingRoster
+	}
+
+	roster := make([]PairingStudent, len(students))
+	for i, student := range students {
+		roster[i] = PairingStudent{
+			ID:   student.ID,
+			Name: student.Name,
+			NIS:  student.NIS,
+		}
+	}
+
+	now := time.Now()
+	session := &PairingSession{
+		Mode:        PairingModeBulk,
+		StudentID:   roster[0].ID,
+		StudentName: roster[0].Name,
+		SchoolID:    device.SchoolID,
+		DeviceID:    device.ID,
+		ClassID:     &class.ID,
+		ClassName:   class.Name,
+		Roster:      roster,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(BulkPairingIdleTimeout),
+	}
+	if err := s.pairingManager.SaveSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Bulk pairing session started: device=%d, class=%d (%s), students=%d",
+		device.ID, class.ID, class.Name, len(roster))
+
+	response := toPairingSessionResponse(session, fmt.Sprintf("Pairing massal kelas %s dimulai. Tap kartu untuk %s.", class.Name, session.StudentName))
+	s.publish(session, PairingEventStarted, response)
+
+	return response, nil
 }
 
 // ProcessRFIDPairing processes an RFID tap during pairing mode
 func (s *pairingService) ProcessRFIDPairing(ctx context.Context, req RFIDPairingRequest) (*RFIDPairingResponse, error) {
-	// Validate API key
-	device, err := s.deviceRepo.FindByAPIKey(ctx, req.APIKey)
+	// Use the device authenticated by the request signature, or validate the API key
+	device, signed := AuthenticatedDevice(ctx)
+	if !signed {
+		var err error
+		device, err = s.deviceRepo.FindByAPIKey(ctx, req.APIKey)
+		if err != nil {
+			return nil, ErrInvalidAPIKey
+		}
+	}
+
+	unlock, err := s.pairingManager.Lock(ctx, device.ID)
 	if err != nil {
-		return nil, ErrInvalidAPIKey
+		return nil, err
 	}
+	defer unlock()
 
 	// Check for active pairing session
-	session, err := s.pairingManager.GetPairingSession(device.ID)
+	session, err := s.pairingManager.GetPairingSession(ctx, device.ID)
 	if err != nil {
+		if !errors.Is(err, ErrNoPairingSession) && !errors.Is(err, ErrPairingSessionExpired) {
+			return nil, err
+		}
 		// No pairing session - this is a normal attendance tap, not pairing
 		return &RFIDPairingResponse{
 			Success: false,
@@ -106,9 +184,20 @@ func (s *pairingService) ProcessRFIDPairing(ctx context.Context, req RFIDPairing
 	// Check if RFID code is already used by another student
 	existingStudent, err := s.studentRepo.FindByRFIDCode(ctx, req.RFIDCode)
 	if err == nil && existingStudent != nil {
+		message := "Kartu RFID sudah digunakan oleh siswa lain: " + existingStudent.Name
+		s.notify(&PairingEvent{
+			Status:      PairingEventRejected,
+			SchoolID:    session.SchoolID,
+			DeviceID:    session.DeviceID,
+			StudentID:   session.StudentID,
+			StudentName: session.StudentName,
+			RFIDCode:    req.RFIDCode,
+			Message:     message,
+			Session:     toPairingSessionResponse(session, "Sesi pairing aktif. Menunggu tap kartu RFID."),
+		})
 		return &RFIDPairingResponse{
 			Success: false,
-			Message: "Kartu RFID sudah digunakan oleh siswa lain: " + existingStudent.Name,
+			Message: message,
 		}, ErrRFIDAlreadyUsed
 	}
 
@@ -117,44 +206,231 @@ func (s *pairingService) ProcessRFIDPairing(ctx context.Context, req RFIDPairing
 		return nil, err
 	}
 
-	// Complete pairing session
-	s.pairingManager.CompletePairingSession(device.ID)
-
 	log.Printf("RFID pairing completed: student=%d (%s), rfid=%s",
 		session.StudentID, session.StudentName, req.RFIDCode)
 
-	return &RFIDPairingResponse{
+	response := &RFIDPairingResponse{
 		Success:     true,
 		StudentID:   session.StudentID,
 		StudentName: session.StudentName,
 		RFIDCode:    req.RFIDCode,
 		Message:     "Kartu RFID berhasil dipasangkan dengan siswa " + session.StudentName,
-	}, nil
+	}
+	event := &PairingEvent{
+		Status:      PairingEventPaired,
+		SchoolID:    session.SchoolID,
+		DeviceID:    session.DeviceID,
+		StudentID:   session.StudentID,
+		StudentName: session.StudentName,
+		RFIDCode:    req.RFIDCode,
+		Message:     response.Message,
+	}
+
+	if session.IsBulk() {
+		session.Paired++
+		if session.Advance() {
+			response.NextStudent = session.StudentName
+		}
+	}
+
+	next, err := s.continueSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	event.Session = next
+	s.notify(event)
+
+	if !next.Active && session.IsBulk() {
+		s.publishCompleted(session, next)
+	}
+
+	return response, nil
+}
+
+// SkipPairingStudent moves a bulk pairing session past the current student
+func (s *pairingService) SkipPairingStudent(ctx context.Context, deviceID uint) (*PairingSessionResponse, error) {
+	unlock, err := s.pairingManager.Lock(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	session, err := s.pairingManager.GetPairingSession(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if !session.IsBulk() {
+		return nil, ErrNotBulkPairing
+	}
+
+	event := &PairingEvent{
+		Status:      PairingEventSkipped,
+		SchoolID:    session.SchoolID,
+		DeviceID:    session.DeviceID,
+		StudentID:   session.StudentID,
+		StudentName: session.StudentName,
+		Message:     "Siswa " + session.StudentName + " dilewati",
+	}
+
+	session.Skipped++
+	session.Advance()
+
+	response, err := s.continueSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	event.Session = response
+	s.notify(event)
+
+	if !response.Active {
+		s.publishCompleted(session, response)
+	}
+
+	return response, nil
 }
 
 // CancelPairing cancels an active pairing session
 func (s *pairingService) CancelPairing(ctx context.Context, deviceID uint) error {
-	s.pairingManager.CancelPairingSession(deviceID)
+	session, err := s.pairingManager.GetPairingSession(ctx, deviceID)
+	if err != nil && !errors.Is(err, ErrNoPairingSession) && !errors.Is(err, ErrPairingSessionExpired) {
+		return err
+	}
+
+	if err := s.pairingManager.DeleteSession(ctx, deviceID); err != nil {
+		return err
+	}
 	log.Printf("Pairing session cancelled: device=%d", deviceID)
+
+	if session != nil {
+		s.notify(&PairingEvent{
+			Status:   PairingEventCancelled,
+			SchoolID: session.SchoolID,
+			DeviceID: session.DeviceID,
+			Message:  "Sesi pairing dibatalkan",
+			Session: &PairingSessionResponse{
+				Active:   false,
+				Mode:     session.Mode,
+				DeviceID: session.DeviceID,
+				Message:  "Sesi pairing dibatalkan",
+			},
+		})
+	}
 	return nil
 }
 
 // GetPairingStatus gets the status of a pairing session
 func (s *pairingService) GetPairingStatus(ctx context.Context, deviceID uint) (*PairingSessionResponse, error) {
-	session, err := s.pairingManager.GetPairingSession(deviceID)
+	session, err := s.pairingManager.GetPairingSession(ctx, deviceID)
 	if err != nil {
+		if !errors.Is(err, ErrNoPairingSession) && !errors.Is(err, ErrPairingSessionExpired) {
+			return nil, err
+		}
 		return &PairingSessionResponse{
 			Active:  false,
 			Message: "Tidak ada sesi pairing aktif",
 		}, nil
 	}
 
-	return &PairingSessionResponse{
+	return toPairingSessionResponse(session, "Sesi pairing aktif. Menunggu tap kartu RFID."), nil
+}
+
+// findActiveDevice retrieves a device that can be used for pairing
+func (s *pairingService) findActiveDevice(ctx context.Context, deviceID uint) (*models.Device, error) {
+	device, err := s.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if !device.IsActive {
+		return nil, ErrDeviceInactive
+	}
+	return device, nil
+}
+
+// continueSession stores a bulk session that has students left with a fresh idle timeout,
+// or ends the session. It returns the resulting session state.
+func (s *pairingService) continueSession(ctx context.Context, session *PairingSession) (*PairingSessionResponse, error) {
+	if session.IsBulk() && session.Position < len(session.Roster) {
+		session.ExpiresAt = time.Now().Add(BulkPairingIdleTimeout)
+		if err := s.pairingManager.SaveSession(ctx, session); err != nil {
+			return nil, err
+		}
+		return toPairingSessionResponse(session, "Tap kartu untuk "+session.StudentName), nil
+	}
+
+	if err := s.pairingManager.DeleteSession(ctx, session.DeviceID); err != nil {
+		return nil, err
+	}
+
+	response := &PairingSessionResponse{
+		Active:    false,
+		Mode:      session.Mode,
+		DeviceID:  session.DeviceID,
+		ClassID:   session.ClassID,
+		ClassName: session.ClassName,
+		Total:     len(session.Roster),
+		Paired:    session.Paired,
+		Skipped:   session.Skipped,
+		Message:   "Sesi pairing selesai",
+	}
+	if session.IsBulk() {
+		response.Message = fmt.Sprintf("Pairing massal selesai: %d kartu dipasangkan, %d siswa dilewati", session.Paired, session.Skipped)
+		log.Printf("Bulk pairing session completed: device=%d, paired=%d, skipped=%d",
+			session.DeviceID, session.Paired, session.Skipped)
+	}
+	return response, nil
+}
+
+// notify pushes a pairing event to the school admins
+func (s *pairingService) notify(event *PairingEvent) {
+	if s.notifier == nil {
+		return
+	}
+	event.Timestamp = time.Now()
+	s.notifier.BroadcastPairingStatus(event.SchoolID, event)
+}
+
+// publish announces a session change with the session state after it
+func (s *pairingService) publish(session *PairingSession, status PairingEventStatus, response *PairingSessionResponse) {
+	s.notify(&PairingEvent{
+		Status:      status,
+		SchoolID:    session.SchoolID,
+		DeviceID:    session.DeviceID,
+		StudentID:   session.StudentID,
+		StudentName: session.StudentName,
+		Message:     response.Message,
+		Session:     response,
+	})
+}
+
+// publishCompleted announces that a bulk roster is finished
+func (s *pairingService) publishCompleted(session *PairingSession, response *PairingSessionResponse) {
+	s.notify(&PairingEvent{
+		Status:   PairingEventCompleted,
+		SchoolID: session.SchoolID,
+		DeviceID: session.DeviceID,
+		Message:  response.Message,
+		Session:  response,
+	})
+}
+
+// toPairingSessionResponse converts an active PairingSession to PairingSessionResponse DTO
+func toPairingSessionResponse(session *PairingSession, message string) *PairingSessionResponse {
+	response := &PairingSessionResponse{
 		Active:      true,
+		Mode:        session.Mode,
 		StudentID:   session.StudentID,
 		StudentName: session.StudentName,
 		DeviceID:    session.DeviceID,
+		ClassID:     session.ClassID,
+		ClassName:   session.ClassName,
 		ExpiresAt:   session.ExpiresAt,
-		Message:     "Sesi pairing aktif. Menunggu tap kartu RFID.",
-	}, nil
+		Message:     message,
+	}
+	if session.IsBulk() {
+		response.Position = session.Position + 1
+		response.Total = len(session.Roster)
+		response.Paired = session.Paired
+		response.Skipped = session.Skipped
+	}
+	return response
 }
@@ -8,6 +8,8 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/calendar"
+	"github.com/school-management/backend/internal/shared/outbox"
 )
 
 var (
@@ -25,11 +27,22 @@ type Repository interface {
 	FindByID(ctx context.Context, id uint) (*models.Device, error)
 	FindByDeviceCode(ctx context.Context, code string) (*models.Device, error)
 	FindByAPIKey(ctx context.Context, apiKey string) (*models.Device, error)
+	FindByKeyPrefix(ctx context.Context, prefix string) ([]models.Device, error)
 	FindBySchoolID(ctx context.Context, schoolID uint) ([]models.Device, error)
 	Update(ctx context.Context, device *models.Device) error
 	UpdateLastSeen(ctx context.Context, id uint) error
 	Deactivate(ctx context.Context, id uint) error
 	Delete(ctx context.Context, id uint) error
+	HashLegacyAPIKeys(ctx context.Context) (int, error)
+
+	// Health monitoring
+	RecordHeartbeat(ctx context.Context, heartbeat *models.DeviceHeartbeat) error
+	FindHeartbeats(ctx context.Context, deviceID uint, filter HeartbeatFilter) ([]models.DeviceHeartbeat, int64, error)
+	DeleteHeartbeatsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	FindSilentDevices(ctx context.Context, silentSince time.Time) ([]models.Device, error)
+	FindSchedulesForDate(ctx context.Context, schoolID uint, date time.Time) ([]models.AttendanceSchedule, error)
+	FindAlertRecipientIDs(ctx context.Context, schoolID uint) ([]uint, error)
+	MarkOfflineAlerted(ctx context.Context, device *models.Device, at time.Time, event *outbox.Event) (bool, error)
 }
 
 // repository implements the Repository interface
@@ -140,13 +153,17 @@ func (r *repository) FindByDeviceCode(ctx context.Context, code string) (*models
 	return &device, nil
 }
 
-// FindByAPIKey retrieves a device by API key
+// FindByAPIKey retrieves an active device by its plaintext API key
+// The key is matched by hash; a key replaced by a rotation matches until its grace period ends
 // Requirements: 2.2 - WHEN a device sends attendance data, THE System SHALL validate the API key before processing
 func (r *repository) FindByAPIKey(ctx context.Context, apiKey string) (*models.Device, error) {
+	hash := models.HashAPIKey(apiKey)
+
 	var device models.Device
 	err := r.db.WithContext(ctx).
 		Preload("School").
-		Where("api_key = ? AND is_active = ?", apiKey, true).
+		Where("is_active = ?", true).
+		Where("api_key = ? OR (previous_key_hash = ? AND previous_key_expires_at > ?)", hash, hash, time.Now()).
 		First(&device).Error
 
 	if err != nil {
@@ -159,6 +176,22 @@ func (r *repository) FindByAPIKey(ctx context.Context, apiKey string) (*models.D
 	return &device, nil
 }
 
+// FindByKeyPrefix retrieves the active devices whose current or unexpired previous key has the prefix
+func (r *repository) FindByKeyPrefix(ctx context.Context, prefix string) ([]models.Device, error) {
+	var devices []models.Device
+	err := r.db.WithContext(ctx).
+		Preload("School").
+		Where("is_active = ?", true).
+		Where("api_key_prefix = ? OR (previous_key_prefix = ? AND previous_key_expires_at > ?)", prefix, prefix, time.Now()).
+		Find(&devices).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
 // FindBySchoolID retrieves all devices for a school
 func (r *repository) FindBySchoolID(ctx context.Context, schoolID uint) ([]models.Device, error) {
 	var devices []models.Device
@@ -180,11 +213,15 @@ func (r *repository) Update(ctx context.Context, device *models.Device) error {
 		Model(&models.Device{}).
 		Where("id = ?", device.ID).
 		Updates(map[string]interface{}{
-			"school_id":   device.SchoolID,
-			"device_code": device.DeviceCode,
-			"description": device.Description,
-			"is_active":   device.IsActive,
-			"api_key":     device.APIKey,
+			"school_id":               device.SchoolID,
+			"device_code":             device.DeviceCode,
+			"description":             device.Description,
+			"is_active":               device.IsActive,
+			"api_key":                 device.APIKeyHash,
+			"api_key_prefix":          device.APIKeyPrefix,
+			"previous_key_hash":       device.PreviousKeyHash,
+			"previous_key_prefix":     device.PreviousKeyPrefix,
+			"previous_key_expires_at": device.PreviousKeyExpiresAt,
 		})
 	if result.Error != nil {
 		return result.Error
@@ -196,12 +233,16 @@ func (r *repository) Update(ctx context.Context, device *models.Device) error {
 }
 
 // UpdateLastSeen updates the last seen timestamp for a device
+// Any contact ends an offline period, so a later outage is alerted again
 func (r *repository) UpdateLastSeen(ctx context.Context, id uint) error {
 	now := time.Now()
 	result := r.db.WithContext(ctx).
 		Model(&models.Device{}).
 		Where("id = ?", id).
-		Update("last_seen_at", now)
+		Updates(map[string]interface{}{
+			"last_seen_at":       now,
+			"offline_alerted_at": nil,
+		})
 
 	if result.Error != nil {
 		return result.Error
@@ -277,6 +318,9 @@ func (r *repository) FindAllGroupedBySchool(ctx context.Context) ([]SchoolDevice
 				LastSeenAt:  device.LastSeenAt,
 				CreatedAt:   device.CreatedAt,
 				UpdatedAt:   device.UpdatedAt,
+
+				FirmwareVersion: device.FirmwareVersion,
+				LastHeartbeatAt: device.LastHeartbeatAt,
 			}
 		}
 
@@ -291,3 +335,144 @@ func (r *repository) FindAllGroupedBySchool(ctx context.Context) ([]SchoolDevice
 
 	return result, nil
 }
+
+// HashLegacyAPIKeys replaces API keys stored in plaintext by their hash
+// Devices created before keys were hashed have no key prefix; they keep using the same key.
+func (r *repository) HashLegacyAPIKeys(ctx context.Context) (int, error) {
+	var devices []models.Device
+	err := r.db.WithContext(ctx).
+		Select("id", "api_key").
+		Where("api_key_prefix IS NULL OR api_key_prefix = ''").
+		Find(&devices).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for _, device := range devices {
+		plaintext := device.APIKeyHash
+		err := r.db.WithContext(ctx).
+			Model(&models.Device{}).
+			Where("id = ? AND api_key = ?", device.ID, plaintext).
+			Updates(map[string]interface{}{
+				"api_key":        models.HashAPIKey(plaintext),
+				"api_key_prefix": models.APIKeyPrefix(plaintext),
+			}).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(devices), nil
+}
+
+// RecordHeartbeat stores a heartbeat and updates the device health fields
+func (r *repository) RecordHeartbeat(ctx context.Context, heartbeat *models.DeviceHeartbeat) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(heartbeat).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"last_seen_at":       heartbeat.CreatedAt,
+			"last_heartbeat_at":  heartbeat.CreatedAt,
+			"offline_alerted_at": nil,
+		}
+		if heartbeat.FirmwareVersion != "" {
+			updates["firmware_version"] = heartbeat.FirmwareVersion
+		}
+		return tx.Model(&models.Device{}).
+			Where("id = ?", heartbeat.DeviceID).
+			Updates(updates).Error
+	})
+}
+
+// FindHeartbeats retrieves the heartbeat history of a device, newest first
+func (r *repository) FindHeartbeats(ctx context.Context, deviceID uint, filter HeartbeatFilter) ([]models.DeviceHeartbeat, int64, error) {
+	var heartbeats []models.DeviceHeartbeat
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.DeviceHeartbeat{}).Where("device_id = ?", deviceID)
+	if filter.StartDate != nil {
+		query = query.Where("created_at >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("created_at < ?", *filter.EndDate)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(filter.PageSize).
+		Find(&heartbeats).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return heartbeats, total, nil
+}
+
+// DeleteHeartbeatsBefore removes heartbeats older than the retention cutoff
+func (r *repository) DeleteHeartbeatsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ?", cutoff).
+		Delete(&models.DeviceHeartbeat{})
+	return result.RowsAffected, result.Error
+}
+
+// FindSilentDevices retrieves active devices of active schools that have not contacted the
+// server since the given time and were not alerted yet. Devices that never connected are
+// not installed yet and are skipped.
+func (r *repository) FindSilentDevices(ctx context.Context, silentSince time.Time) ([]models.Device, error) {
+	var devices []models.Device
+	err := r.db.WithContext(ctx).
+		Preload("School").
+		Joins("JOIN schools ON schools.id = devices.school_id").
+		Where("devices.is_active = ? AND schools.is_active = ?", true, true).
+		Where("devices.last_seen_at IS NOT NULL AND devices.last_seen_at < ?", silentSince).
+		Where("devices.offline_alerted_at IS NULL").
+		Find(&devices).Error
+	return devices, err
+}
+
+// FindSchedulesForDate retrieves the schedules that apply on a date, following the school calendar
+func (r *repository) FindSchedulesForDate(ctx context.Context, schoolID uint, date time.Time) ([]models.AttendanceSchedule, error) {
+	return calendar.SchedulesForDate(ctx, r.db, schoolID, date)
+}
+
+// FindAlertRecipientIDs retrieves the active school admins of a school and all active super admins
+func (r *repository) FindAlertRecipientIDs(ctx context.Context, schoolID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("is_active = ?", true).
+		Where("(role = ? AND school_id = ?) OR role = ?", models.RoleAdminSekolah, schoolID, models.RoleSuperAdmin).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// MarkOfflineAlerted records that an offline alert was sent and writes the alert event to the outbox
+// It reports false when the device was already alerted (e.g. by another instance) or has
+// contacted the server since it was loaded
+func (r *repository) MarkOfflineAlerted(ctx context.Context, device *models.Device, at time.Time, event *outbox.Event) (bool, error) {
+	marked := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Device{}).
+			Where("id = ? AND offline_alerted_at IS NULL AND last_seen_at = ?", device.ID, device.LastSeenAt).
+			Update("offline_alerted_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		marked = true
+		return outbox.Append(tx, device.ID, event)
+	})
+	return marked, err
+}
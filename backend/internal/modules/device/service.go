@@ -5,7 +5,9 @@ import (
 	"errors"
 	"log"
 	"strings"
+	"time"
 
+	"github.com/school-management/backend/internal/config"
 	"github.com/school-management/backend/internal/domain/models"
 )
 
@@ -28,18 +30,30 @@ type Service interface {
 	UpdateDevice(ctx context.Context, id uint, req UpdateDeviceRequest) (*DeviceResponse, error)
 	ValidateAPIKey(ctx context.Context, apiKey string) (*APIKeyValidationResponse, error)
 	RevokeAPIKey(ctx context.Context, id uint) (*RevokeAPIKeyResponse, error)
-	RegenerateAPIKey(ctx context.Context, id uint) (*RegenerateAPIKeyResponse, error)
+	RegenerateAPIKey(ctx context.Context, id uint, req RegenerateAPIKeyRequest) (*RegenerateAPIKeyResponse, error)
 	DeleteDevice(ctx context.Context, id uint) error
+
+	// Health monitoring
+	RecordHeartbeat(ctx context.Context, req HeartbeatRequest, ipAddress string) (*HeartbeatResponse, error)
+	GetDeviceHeartbeats(ctx context.Context, id uint, filter HeartbeatFilter) (*HeartbeatListResponse, error)
 }
 
 // service implements the Service interface
 type service struct {
-	repo Repository
+	repo          Repository
+	rotationGrace time.Duration // How long a regenerated device keeps accepting its previous API key
+	offlineAfter  time.Duration // Silence after which a device is shown as offline
+	pepper        string        // Server-side key the signing secrets are derived with
 }
 
 // NewService creates a new device service
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, cfg config.DeviceConfig) Service {
+	return &service{
+		repo:          repo,
+		rotationGrace: time.Duration(cfg.KeyRotationGraceHours) * time.Hour,
+		offlineAfter:  OfflineThreshold(cfg),
+		pepper:        cfg.SigningPepper,
+	}
 }
 
 // RegisterDevice registers a new device with a generated API key
@@ -83,8 +97,9 @@ func (s *service) RegisterDevice(ctx context.Context, req RegisterDeviceRequest)
 
 	// Return response with API key (only shown once)
 	return &DeviceWithAPIKeyResponse{
-		DeviceResponse: *toDeviceResponse(device),
+		DeviceResponse: *toDeviceResponse(device, s.offlineAfter),
 		APIKey:         device.APIKey,
+		SigningSecret:  models.SigningSecret(s.pepper, device.APIKeyHash),
 	}, nil
 }
 
@@ -108,7 +123,7 @@ func (s *service) GetAllDevices(ctx context.Context, filter DeviceFilter) (*Devi
 	// Convert to response
 	deviceResponses := make([]DeviceResponse, len(devices))
 	for i, device := range devices {
-		deviceResponses[i] = *toDeviceResponse(&device)
+		deviceResponses[i] = *toDeviceResponse(&device, s.offlineAfter)
 	}
 
 	// Calculate total pages
@@ -135,10 +150,11 @@ func (s *service) GetDeviceByID(ctx context.Context, id uint) (*DeviceResponse,
 		return nil, err
 	}
 
-	return toDeviceResponse(device), nil
+	return toDeviceResponse(device, s.offlineAfter), nil
 }
 
-// GetDeviceAPIKey retrieves a device's API key
+// GetDeviceAPIKey retrieves a device's API key information
+// Only the key hash is stored, so the key itself cannot be shown again; a lost key must be regenerated
 func (s *service) GetDeviceAPIKey(ctx context.Context, id uint) (*DeviceAPIKeyResponse, error) {
 	device, err := s.repo.FindByID(ctx, id)
 	if err != nil {
@@ -151,10 +167,12 @@ func (s *service) GetDeviceAPIKey(ctx context.Context, id uint) (*DeviceAPIKeyRe
 	}
 
 	return &DeviceAPIKeyResponse{
-		DeviceID:   device.ID,
-		DeviceCode: device.DeviceCode,
-		SchoolName: schoolName,
-		APIKey:     device.APIKey,
+		DeviceID:             device.ID,
+		DeviceCode:           device.DeviceCode,
+		SchoolName:           schoolName,
+		APIKeyPrefix:         device.APIKeyPrefix,
+		PreviousKeyExpiresAt: device.PreviousKeyExpiresAt,
+		Message:              "API key hanya ditampilkan sekali saat dibuat. Buat ulang API key jika key hilang.",
 	}, nil
 }
 
@@ -167,7 +185,7 @@ func (s *service) GetDevicesBySchool(ctx context.Context, schoolID uint) ([]Devi
 
 	responses := make([]DeviceResponse, len(devices))
 	for i, device := range devices {
-		responses[i] = *toDeviceResponse(&device)
+		responses[i] = *toDeviceResponse(&device, s.offlineAfter)
 	}
 
 	return responses, nil
@@ -193,13 +211,18 @@ func (s *service) UpdateDevice(ctx context.Context, id uint, req UpdateDeviceReq
 		return nil, err
 	}
 
-	return toDeviceResponse(device), nil
+	return toDeviceResponse(device, s.offlineAfter), nil
 }
 
 // ValidateAPIKey validates an API key and returns device info
 // Requirements: 2.2 - WHEN a device sends attendance data, THE System SHALL validate the API key before processing
 // Requirements: 2.3 - IF an invalid API key is used, THEN THE System SHALL reject the request and log the attempt
 func (s *service) ValidateAPIKey(ctx context.Context, apiKey string) (*APIKeyValidationResponse, error) {
+	// A signed request was already authenticated by the signature middleware
+	if device, ok := AuthenticatedDevice(ctx); ok {
+		return s.validDevice(ctx, device), nil
+	}
+
 	if apiKey == "" {
 		log.Printf("API key validation failed: empty API key")
 		return &APIKeyValidationResponse{
@@ -221,6 +244,11 @@ func (s *service) ValidateAPIKey(ctx context.Context, apiKey string) (*APIKeyVal
 		return nil, err
 	}
 
+	return s.validDevice(ctx, device), nil
+}
+
+// validDevice records device activity and builds a successful validation response
+func (s *service) validDevice(ctx context.Context, device *models.Device) *APIKeyValidationResponse {
 	// Update last seen timestamp
 	if err := s.repo.UpdateLastSeen(ctx, device.ID); err != nil {
 		// Log but don't fail the validation
@@ -232,7 +260,7 @@ func (s *service) ValidateAPIKey(ctx context.Context, apiKey string) (*APIKeyVal
 		DeviceID: device.ID,
 		SchoolID: device.SchoolID,
 		Message:  "API key valid",
-	}, nil
+	}
 }
 
 // RevokeAPIKey revokes a device's API key by deactivating the device
@@ -262,15 +290,23 @@ func (s *service) RevokeAPIKey(ctx context.Context, id uint) (*RevokeAPIKeyRespo
 }
 
 // RegenerateAPIKey generates a new API key for a device
-func (s *service) RegenerateAPIKey(ctx context.Context, id uint) (*RegenerateAPIKeyResponse, error) {
+// The previous key of an active device keeps working during the rotation grace period so the
+// device can be updated without downtime, unless the request revokes it immediately.
+func (s *service) RegenerateAPIKey(ctx context.Context, id uint, req RegenerateAPIKeyRequest) (*RegenerateAPIKeyResponse, error) {
 	// Get existing device
 	device, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	// A revoked key never gets a grace period
+	grace := s.rotationGrace
+	if req.RevokePrevious || !device.IsActive {
+		grace = 0
+	}
+
 	// Generate new API key
-	if err := device.GenerateAPIKey(); err != nil {
+	if err := device.RotateAPIKey(grace); err != nil {
 		return nil, ErrAPIKeyGeneration
 	}
 
@@ -281,11 +317,19 @@ func (s *service) RegenerateAPIKey(ctx context.Context, id uint) (*RegenerateAPI
 		return nil, err
 	}
 
+	message := "API key berhasil dibuat ulang. Silakan perbarui perangkat dengan key baru."
+	if device.PreviousKeyExpiresAt != nil {
+		message = "API key berhasil dibuat ulang. Key lama masih berlaku sampai " +
+			device.PreviousKeyExpiresAt.Format("02/01/2006 15:04") + ", segera perbarui perangkat dengan key baru."
+	}
+
 	return &RegenerateAPIKeyResponse{
-		DeviceID:   device.ID,
-		DeviceCode: device.DeviceCode,
-		APIKey:     device.APIKey,
-		Message:    "API key berhasil dibuat ulang. Silakan perbarui perangkat dengan key baru.",
+		DeviceID:             device.ID,
+		DeviceCode:           device.DeviceCode,
+		APIKey:               device.APIKey,
+		SigningSecret:        models.SigningSecret(s.pepper, device.APIKeyHash),
+		PreviousKeyExpiresAt: device.PreviousKeyExpiresAt,
+		Message:              message,
 	}, nil
 }
 
@@ -301,9 +345,13 @@ func (s *service) GetDevicesGroupedBySchool(ctx context.Context) (*GroupedDevice
 		return nil, err
 	}
 
+	now := time.Now()
 	total := 0
 	for i := range schoolDevices {
 		total += len(schoolDevices[i].Devices)
+		for j := range schoolDevices[i].Devices {
+			setConnectionStatus(&schoolDevices[i].Devices[j], now, s.offlineAfter)
+		}
 	}
 
 	return &GroupedDevicesResponse{
@@ -313,7 +361,7 @@ func (s *service) GetDevicesGroupedBySchool(ctx context.Context) (*GroupedDevice
 }
 
 // toDeviceResponse converts a Device model to DeviceResponse DTO
-func toDeviceResponse(device *models.Device) *DeviceResponse {
+func toDeviceResponse(device *models.Device, offlineAfter time.Duration) *DeviceResponse {
 	response := &DeviceResponse{
 		ID:          device.ID,
 		SchoolID:    device.SchoolID,
@@ -323,6 +371,9 @@ func toDeviceResponse(device *models.Device) *DeviceResponse {
 		LastSeenAt:  device.LastSeenAt,
 		CreatedAt:   device.CreatedAt,
 		UpdatedAt:   device.UpdatedAt,
+
+		FirmwareVersion: device.FirmwareVersion,
+		LastHeartbeatAt: device.LastHeartbeatAt,
 	}
 
 	// Include school name if loaded
@@ -330,5 +381,14 @@ func toDeviceResponse(device *models.Device) *DeviceResponse {
 		response.SchoolName = device.School.Name
 	}
 
+	setConnectionStatus(response, time.Now(), offlineAfter)
+
 	return response
 }
+
+// setConnectionStatus marks a device response online or offline from its last activity
+func setConnectionStatus(response *DeviceResponse, now time.Time, offlineAfter time.Duration) {
+	device := models.Device{IsActive: response.IsActive, LastSeenAt: response.LastSeenAt}
+	response.Status = device.ConnectionStatus(now, offlineAfter)
+	response.IsOnline = response.Status == models.DeviceStatusOnline
+}
@@ -0,0 +1,246 @@
+package device
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/redis"
+)
+
+// Signed device request headers
+// The signature is the hex HMAC-SHA256 of the canonical request, keyed with the
+// signing secret handed out with the device API key (see models.SigningSecret):
+//
+//	METHOD\nPATH\nTIMESTAMP\nNONCE\nhex(SHA-256(body))
+const (
+	HeaderDeviceKey = "X-Device-Key" // API key prefix identifying the key
+	HeaderTimestamp = "X-Timestamp"  // Unix seconds
+	HeaderNonce     = "X-Nonce"      // Unique per request
+	HeaderSignature = "X-Signature"
+)
+
+const (
+	// DefaultSignatureMaxSkew is the default allowed clock difference between device and server
+	DefaultSignatureMaxSkew = 5 * time.Minute
+	minNonceLength          = 8
+	maxNonceLength          = 64
+	nonceKeyPrefix          = "device:nonce:"
+	authenticatedDeviceKey  = "authenticated_device"
+)
+
+// SignatureVerifier authenticates ESP32 requests signed with the device API key
+// Nonces are stored in Redis for twice the allowed clock skew, so a captured request
+// cannot be replayed while its timestamp is still accepted.
+type SignatureVerifier struct {
+	repo             Repository
+	redis            *redis.Client
+	pepper           string // Server-side key the signing secrets are derived with
+	maxSkew          time.Duration
+	requireSignature bool
+}
+
+// NewSignatureVerifier creates a new device request signature verifier
+// When requireSignature is false, unsigned requests are passed on to the handler,
+// which authenticates them with the API key in the body.
+func NewSignatureVerifier(repo Repository, redisClient *redis.Client, pepper string, maxSkew time.Duration, requireSignature bool) *SignatureVerifier {
+	if maxSkew <= 0 {
+		maxSkew = DefaultSignatureMaxSkew
+	}
+	return &SignatureVerifier{
+		repo:             repo,
+		redis:            redisClient,
+		pepper:           pepper,
+		maxSkew:          maxSkew,
+		requireSignature: requireSignature,
+	}
+}
+
+// Middleware verifies the request signature and stores the authenticated device for the handler
+func (v *SignatureVerifier) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		signature := c.Get(HeaderSignature)
+		if signature == "" {
+			if v.requireSignature {
+				log.Printf("Device request rejected: unsigned request to %s from %s", c.Path(), c.IP())
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"success": false,
+					"error": fiber.Map{
+						"code":    "AUTH_SIGNATURE_REQUIRED",
+						"message": "Permintaan perangkat wajib ditandatangani",
+					},
+				})
+			}
+			return c.Next()
+		}
+
+		prefix := c.Get(HeaderDeviceKey)
+		nonce := c.Get(HeaderNonce)
+		timestamp, err := strconv.ParseInt(c.Get(HeaderTimestamp), 10, 64)
+		if prefix == "" || err != nil || len(nonce) < minNonceLength || len(nonce) > maxNonceLength {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "AUTH_INVALID_SIGNATURE",
+					"message": "Header tanda tangan perangkat tidak lengkap",
+				},
+			})
+		}
+
+		// Reject requests outside the clock skew window; their nonces may have expired
+		requestTime := time.Unix(timestamp, 0)
+		now := time.Now()
+		if requestTime.Before(now.Add(-v.maxSkew)) || requestTime.After(now.Add(v.maxSkew)) {
+			log.Printf("Device request rejected: stale timestamp %d for key %s", timestamp, prefix)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "AUTH_STALE_REQUEST",
+					"message": "Waktu permintaan tidak valid, periksa jam perangkat",
+				},
+			})
+		}
+
+		device, err := v.verify(c, prefix, timestamp, nonce, signature, now)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "INTERNAL_ERROR",
+					"message": "Terjadi kesalahan pada server",
+				},
+			})
+		}
+		if device == nil {
+			// Log invalid signature attempt (Requirements: 2.3)
+			log.Printf("Device request rejected: invalid signature for key %s from %s", prefix, c.IP())
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "AUTH_INVALID_SIGNATURE",
+					"message": "Tanda tangan permintaan tidak valid",
+				},
+			})
+		}
+
+		// The nonce is only recorded for authentic requests, so forged requests cannot burn nonces
+		fresh, err := v.redis.SetNX(c.Context(), fmt.Sprintf("%s%d:%s", nonceKeyPrefix, device.ID, nonce), timestamp, 2*v.maxSkew)
+		if err != nil {
+			log.Printf("Device request rejected: failed to store nonce for device %d: %v", device.ID, err)
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "SERVICE_UNAVAILABLE",
+					"message": "Layanan sedang tidak tersedia, coba lagi nanti",
+				},
+			})
+		}
+		if !fresh {
+			log.Printf("Device request rejected: replayed nonce from device %d", device.ID)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "AUTH_REPLAYED_NONCE",
+					"message": "Permintaan sudah pernah diproses",
+				},
+			})
+		}
+
+		c.Locals(authenticatedDeviceKey, device)
+		return c.Next()
+	}
+}
+
+// verify finds the device whose key signed the request
+// It returns nil without an error when no key matches the signature.
+func (v *SignatureVerifier) verify(c *fiber.Ctx, prefix string, timestamp int64, nonce, signature string, now time.Time) (*models.Device, error) {
+	provided, err := hex.DecodeString(strings.ToLower(signature))
+	if err != nil {
+		return nil, nil
+	}
+
+	devices, err := v.repo.FindByKeyPrefix(c.Context(), prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical := CanonicalRequest(c.Method(), c.Path(), timestamp, nonce, c.Body())
+	for i := range devices {
+		for _, secret := range devices[i].SigningSecrets(v.pepper, prefix, now) {
+			if hmac.Equal(provided, SignRequest(secret, canonical)) {
+				return &devices[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// CanonicalRequest builds the string a device signs for a request
+func CanonicalRequest(method, path string, timestamp int64, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		strconv.FormatInt(timestamp, 10),
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// SignRequest computes the HMAC-SHA256 signature of a canonical request
+// The secret is the hex signing secret of the API key (see models.SigningSecret)
+func SignRequest(secret, canonical string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+	return mac.Sum(nil)
+}
+
+// AuthenticatedContext returns the request context, carrying the device authenticated
+// by a valid signature if there is one
+func AuthenticatedContext(c *fiber.Ctx) context.Context {
+	if device, ok := c.Locals(authenticatedDeviceKey).(*models.Device); ok {
+		return context.WithValue(c.Context(), authenticatedDeviceCtxKey{}, device)
+	}
+	return c.Context()
+}
+
+// IsSigned reports whether a request carries a signature, which the middleware verifies.
+// The device rate limit counts rejected requests per client IP: keying on the key ID a request
+// claims would let anyone lock out a reader by sending bad requests with its (visible) key ID,
+// while a guesser simply changed the ID every time. Guessing is what the per-address lock
+// stops; the lockout abuse it enables against devices sharing the address, e.g. behind a
+// school NAT, is avoided by letting signed requests pass the lock to be verified, since only
+// a device holding the signing secret can sign (see middleware.RateLimitVerifiable).
+func IsSigned(c *fiber.Ctx) bool {
+	return c.Get(HeaderSignature) != ""
+}
+
+// AuthenticatedDevice returns the device authenticated by a request signature
+func AuthenticatedDevice(ctx context.Context) (*models.Device, bool) {
+	device, ok := ctx.Value(authenticatedDeviceCtxKey{}).(*models.Device)
+	return device, ok && device != nil
+}
+
+// authenticateDevice returns the device authenticated by the request signature, or the
+// active device owning the API key of an unsigned request
+func authenticateDevice(ctx context.Context, repo Repository, apiKey string) (*models.Device, error) {
+	if device, ok := AuthenticatedDevice(ctx); ok {
+		return device, nil
+	}
+	if apiKey == "" {
+		return nil, ErrInvalidAPIKey
+	}
+	return repo.FindByAPIKey(ctx, apiKey)
+}
+
+// authenticatedDeviceCtxKey is the context key of the signature-authenticated device
+type authenticatedDeviceCtxKey struct{}
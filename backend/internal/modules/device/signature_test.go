@@ -0,0 +1,110 @@
+package device
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestCanonicalRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		timestamp int64
+		nonce     string
+		body      []byte
+		want      string
+	}{
+		{
+			name:      "json body",
+			method:    "POST",
+			path:      "/api/v1/attendance/rfid",
+			timestamp: 1760000000,
+			nonce:     "abcdef0123456789",
+			body:      []byte(`{"uid":"04A1B2C3"}`),
+			want: "POST\n/api/v1/attendance/rfid\n1760000000\nabcdef0123456789\n" +
+				"5f97c4cade46a304d020327ebc47523d388f7d4db05f7e3243be69b06281fc5a",
+		},
+		{
+			name:      "empty body",
+			method:    "GET",
+			path:      "/api/v1/devices/ping",
+			timestamp: 1760000000,
+			nonce:     "0011223344556677",
+			body:      nil,
+			want: "GET\n/api/v1/devices/ping\n1760000000\n0011223344556677\n" +
+				"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+		{
+			name:      "method is upper-cased",
+			method:    "post",
+			path:      "/api/v1/attendance/rfid",
+			timestamp: 1760000000,
+			nonce:     "abcdef0123456789",
+			body:      []byte(`{"uid":"04A1B2C3"}`),
+			want: "POST\n/api/v1/attendance/rfid\n1760000000\nabcdef0123456789\n" +
+				"5f97c4cade46a304d020327ebc47523d388f7d4db05f7e3243be69b06281fc5a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CanonicalRequest(tt.method, tt.path, tt.timestamp, tt.nonce, tt.body)
+			if got != tt.want {
+				t.Errorf("CanonicalRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+	// Signing secret of the key "dev_0123456789abcdef" under the pepper "test-pepper"
+	const secret = "69dfef50e9d2ba174c3f52fac39d6af37055926da4f5f63ff233a547ef662b73"
+	canonical := CanonicalRequest("POST", "/api/v1/attendance/rfid", 1760000000, "abcdef0123456789", []byte(`{"uid":"04A1B2C3"}`))
+
+	tests := []struct {
+		name      string
+		secret    string
+		canonical string
+		want      string
+		match     bool
+	}{
+		{
+			name:      "known signature",
+			secret:    secret,
+			canonical: canonical,
+			want:      "3db77c7c8e64673323acf44950be8a34f81828874ec27bf4bee9ce9c9895f3c8",
+			match:     true,
+		},
+		{
+			name:      "other secret",
+			secret:    "00" + secret[2:],
+			canonical: canonical,
+			want:      "3db77c7c8e64673323acf44950be8a34f81828874ec27bf4bee9ce9c9895f3c8",
+			match:     false,
+		},
+		{
+			name:      "tampered body",
+			secret:    secret,
+			canonical: CanonicalRequest("POST", "/api/v1/attendance/rfid", 1760000000, "abcdef0123456789", []byte(`{"uid":"04A1B2C4"}`)),
+			want:      "3db77c7c8e64673323acf44950be8a34f81828874ec27bf4bee9ce9c9895f3c8",
+			match:     false,
+		},
+		{
+			name:      "replayed with another nonce",
+			secret:    secret,
+			canonical: CanonicalRequest("POST", "/api/v1/attendance/rfid", 1760000000, "abcdef0123456780", []byte(`{"uid":"04A1B2C3"}`)),
+			want:      "3db77c7c8e64673323acf44950be8a34f81828874ec27bf4bee9ce9c9895f3c8",
+			match:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hex.EncodeToString(SignRequest(tt.secret, tt.canonical))
+			if (got == tt.want) != tt.match {
+				t.Errorf("SignRequest() = %s, want match with %s: %v", got, tt.want, tt.match)
+			}
+		})
+	}
+}
@@ -89,3 +89,31 @@ func (r *studentRepository) ClearRFIDCode(ctx context.Context, studentID uint) e
 	}
 	return nil
 }
+
+// FindClassByID retrieves a class by ID
+func (r *studentRepository) FindClassByID(ctx context.Context, id uint) (*models.Class, error) {
+	var class models.Class
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&class).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClassNotFound
+		}
+		return nil, err
+	}
+
+	return &class, nil
+}
+
+// FindUnpairedByClass retrieves the active students of a class without an RFID card, ordered by name
+func (r *studentRepository) FindUnpairedByClass(ctx context.Context, classID uint) ([]models.Student, error) {
+	var students []models.Student
+	err := r.db.WithContext(ctx).
+		Where("class_id = ? AND is_active = ?", classID, true).
+		Where("rf_id_code = '' OR rf_id_code IS NULL").
+		Order("name ASC").
+		Find(&students).Error
+	return students, err
+}
@@ -0,0 +1,219 @@
+package grade
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/middleware"
+	"github.com/school-management/backend/internal/shared/gradebook"
+)
+
+// AssignmentHandler handles HTTP requests for teaching assignments
+type AssignmentHandler struct {
+	service AssignmentService
+}
+
+// NewAssignmentHandler creates a new teaching assignment handler
+func NewAssignmentHandler(service AssignmentService) *AssignmentHandler {
+	return &AssignmentHandler{service: service}
+}
+
+// RegisterRoutes registers teaching assignment routes
+// Admin sekolah manage the assignments; teachers can list their own.
+func (h *AssignmentHandler) RegisterRoutes(router fiber.Router) {
+	assignments := router.Group("/teaching-assignments")
+	assignments.Get("", middleware.RoleMiddleware(models.RoleAdminSekolah, models.RoleGuru, models.RoleWaliKelas), h.GetAssignments)
+	assignments.Post("", middleware.AdminSekolahOnly(), h.CreateAssignment)
+	assignments.Delete("/:id", middleware.AdminSekolahOnly(), h.DeleteAssignment)
+}
+
+// CreateAssignment handles assigning a teacher to a subject and class
+// @Summary Create teaching assignment
+// @Description Assign a guru or wali kelas to teach a subject in a class for a semester. Assigned teachers can manage the grades of that subject for the class
+// @Tags Teaching Assignments
+// @Accept json
+// @Produce json
+// @Param request body CreateTeachingAssignmentRequest true "Assignment data"
+// @Success 201 {object} TeachingAssignmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/teaching-assignments [post]
+func (h *AssignmentHandler) CreateAssignment(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	var req CreateTeachingAssignmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invalidBody(c)
+	}
+
+	response, err := h.service.CreateAssignment(c.Context(), schoolID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Penugasan mengajar berhasil dibuat",
+	})
+}
+
+// GetAssignments handles listing teaching assignments
+// @Summary List teaching assignments
+// @Description Get the school's teaching assignments. Guru and wali kelas only see their own
+// @Tags Teaching Assignments
+// @Produce json
+// @Param teacher_id query int false "Filter by teacher ID"
+// @Param class_id query int false "Filter by class ID"
+// @Param subject_id query int false "Filter by subject ID"
+// @Param academic_year query string false "Filter by academic year (e.g. 2024/2025)"
+// @Param semester query int false "Filter by semester (1 or 2)"
+// @Success 200 {object} TeachingAssignmentListResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/teaching-assignments [get]
+func (h *AssignmentHandler) GetAssignments(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	var filter TeachingAssignmentFilter
+	if teacherID, err := strconv.ParseUint(c.Query("teacher_id"), 10, 32); err == nil {
+		id := uint(teacherID)
+		filter.TeacherID = &id
+	}
+	if classID, err := strconv.ParseUint(c.Query("class_id"), 10, 32); err == nil {
+		id := uint(classID)
+		filter.ClassID = &id
+	}
+	if subjectID, err := strconv.ParseUint(c.Query("subject_id"), 10, 32); err == nil {
+		id := uint(subjectID)
+		filter.SubjectID = &id
+	}
+	if academicYear := c.Query("academic_year"); academicYear != "" {
+		filter.AcademicYear = &academicYear
+	}
+	if semester, err := strconv.Atoi(c.Query("semester")); err == nil {
+		filter.Semester = &semester
+	}
+
+	// Teachers only see their own assignments
+	if role, _ := c.Locals("role").(string); models.UserRole(role) != models.RoleAdminSekolah {
+		userID, _ := c.Locals("user_id").(uint)
+		filter.TeacherID = &userID
+	}
+
+	response, err := h.service.GetAssignments(c.Context(), schoolID, filter)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// DeleteAssignment handles removing a teaching assignment
+// @Summary Delete teaching assignment
+// @Description Remove a teaching assignment. Grades already recorded are kept
+// @Tags Teaching Assignments
+// @Produce json
+// @Param id path int true "Assignment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/teaching-assignments/{id} [delete]
+func (h *AssignmentHandler) DeleteAssignment(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return invalidID(c, "ID penugasan mengajar tidak valid")
+	}
+
+	if err := h.service.DeleteAssignment(c.Context(), schoolID, uint(id)); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Penugasan mengajar berhasil dihapus",
+	})
+}
+
+func (h *AssignmentHandler) handleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrTeachingAssignmentNotFound), errors.Is(err, ErrTeacherNotFound),
+		errors.Is(err, ErrSubjectNotFound), errors.Is(err, ErrClassNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrTeachingAssignmentIncomplete):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrTeacherCannotTeach), errors.Is(err, ErrSubjectInactive):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_ASSIGNMENT",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, gradebook.ErrInvalidPeriod):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_PERIOD",
+				"message": "Tahun ajaran harus berformat YYYY/YYYY dan semester 1 atau 2",
+			},
+		})
+	case errors.Is(err, ErrClassArchived):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "CLASS_ARCHIVED",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrTeachingAssignmentExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_DUPLICATE_ENTRY",
+				"message": err.Error(),
+			},
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "INTERNAL_ERROR",
+				"message": "Terjadi kesalahan pada server",
+			},
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package grade
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
+)
+
+var (
+	ErrTeachingAssignmentNotFound = errors.New("penugasan mengajar tidak ditemukan")
+	ErrTeacherNotFound            = errors.New("guru tidak ditemukan")
+	ErrClassNotFound              = errors.New("kelas tidak ditemukan")
+)
+
+// AssignmentRepository defines data operations for teaching assignments
+type AssignmentRepository interface {
+	Create(ctx context.Context, assignment *models.TeachingAssignment) error
+	FindByID(ctx context.Context, schoolID, id uint) (*models.TeachingAssignment, error)
+	FindAll(ctx context.Context, schoolID uint, filter TeachingAssignmentFilter) ([]models.TeachingAssignment, error)
+	Exists(ctx context.Context, assignment *models.TeachingAssignment) (bool, error)
+	Delete(ctx context.Context, schoolID, id uint) error
+
+	// IsAssigned checks if a teacher teaches a subject in a class during a period
+	IsAssigned(ctx context.Context, teacherID, subjectID, classID uint, period gradebook.Period) (bool, error)
+
+	// Lookups used to validate assignments
+	FindTeacher(ctx context.Context, schoolID, teacherID uint) (*models.User, error)
+	FindClass(ctx context.Context, schoolID, classID uint) (*models.Class, error)
+}
+
+// assignmentRepository implements the AssignmentRepository interface
+type assignmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAssignmentRepository creates a new teaching assignment repository
+func NewAssignmentRepository(db *gorm.DB) AssignmentRepository {
+	return &assignmentRepository{db: db}
+}
+
+// Create creates a new teaching assignment
+func (r *assignmentRepository) Create(ctx context.Context, assignment *models.TeachingAssignment) error {
+	return r.db.WithContext(ctx).Create(assignment).Error
+}
+
+// FindByID retrieves a teaching assignment of a school by ID
+func (r *assignmentRepository) FindByID(ctx context.Context, schoolID, id uint) (*models.TeachingAssignment, error) {
+	var assignment models.TeachingAssignment
+	err := r.db.WithContext(ctx).
+		Preload("Teacher").
+		Preload("Subject").
+		Preload("Class").
+		Where("id = ? AND school_id = ?", id, schoolID).
+		First(&assignment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeachingAssignmentNotFound
+		}
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// FindAll retrieves the teaching assignments of a school matching the filter
+func (r *assignmentRepository) FindAll(ctx context.Context, schoolID uint, filter TeachingAssignmentFilter) ([]models.TeachingAssignment, error) {
+	query := r.db.WithContext(ctx).
+		Preload("Teacher").
+		Preload("Subject").
+		Preload("Class").
+		Where("teaching_assignments.school_id = ?", schoolID)
+
+	if filter.TeacherID != nil {
+		query = query.Where("teaching_assignments.teacher_id = ?", *filter.TeacherID)
+	}
+	if filter.ClassID != nil {
+		query = query.Where("teaching_assignments.class_id = ?", *filter.ClassID)
+	}
+	if filter.SubjectID != nil {
+		query = query.Where("teaching_assignments.subject_id = ?", *filter.SubjectID)
+	}
+	if filter.AcademicYear != nil {
+		query = query.Where("teaching_assignments.academic_year = ?", *filter.AcademicYear)
+	}
+	if filter.Semester != nil {
+		query = query.Where("teaching_assignments.semester = ?", *filter.Semester)
+	}
+
+	var assignments []models.TeachingAssignment
+	err := query.
+		Order("teaching_assignments.academic_year DESC, teaching_assignments.semester DESC, teaching_assignments.class_id ASC, teaching_assignments.subject_id ASC").
+		Find(&assignments).Error
+	return assignments, err
+}
+
+// Exists checks if the same teacher, subject, class and period is already assigned
+func (r *assignmentRepository) Exists(ctx context.Context, assignment *models.TeachingAssignment) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.TeachingAssignment{}).
+		Where("teacher_id = ? AND subject_id = ? AND class_id = ? AND academic_year = ? AND semester = ?",
+			assignment.TeacherID, assignment.SubjectID, assignment.ClassID, assignment.AcademicYear, assignment.Semester).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Delete deletes a teaching assignment of a school
+func (r *assignmentRepository) Delete(ctx context.Context, schoolID, id uint) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		Delete(&models.TeachingAssignment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTeachingAssignmentNotFound
+	}
+	return nil
+}
+
+// IsAssigned checks if a teacher teaches a subject in a class during a period
+func (r *assignmentRepository) IsAssigned(ctx context.Context, teacherID, subjectID, classID uint, period gradebook.Period) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.TeachingAssignment{}).
+		Where("teacher_id = ? AND subject_id = ? AND class_id = ? AND academic_year = ? AND semester = ?",
+			teacherID, subjectID, classID, period.AcademicYear, period.Semester).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindTeacher retrieves an active user of a school
+func (r *assignmentRepository) FindTeacher(ctx context.Context, schoolID, teacherID uint) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ? AND is_active = ?", teacherID, schoolID, true).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeacherNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindClass retrieves a class of a school
+func (r *assignmentRepository) FindClass(ctx context.Context, schoolID, classID uint) (*models.Class, error) {
+	var class models.Class
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", classID, schoolID).
+		First(&class).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClassNotFound
+		}
+		return nil, err
+	}
+	return &class, nil
+}
@@ -0,0 +1,148 @@
+package grade
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
+)
+
+var (
+	ErrTeacherCannotTeach           = errors.New("hanya guru atau wali kelas yang dapat ditugaskan mengajar")
+	ErrTeachingAssignmentExists     = errors.New("guru sudah ditugaskan mengajar mata pelajaran ini di kelas ini")
+	ErrTeachingAssignmentIncomplete = errors.New("guru, mata pelajaran dan kelas wajib diisi")
+	ErrClassArchived                = errors.New("kelas sudah diarsipkan dan hanya dapat dibaca")
+)
+
+// AssignmentService defines the business logic for teaching assignments
+type AssignmentService interface {
+	CreateAssignment(ctx context.Context, schoolID uint, req CreateTeachingAssignmentRequest) (*TeachingAssignmentResponse, error)
+	GetAssignments(ctx context.Context, schoolID uint, filter TeachingAssignmentFilter) (*TeachingAssignmentListResponse, error)
+	DeleteAssignment(ctx context.Context, schoolID, id uint) error
+}
+
+// assignmentService implements the AssignmentService interface
+type assignmentService struct {
+	repo        AssignmentRepository
+	subjectRepo SubjectRepository
+	db          *gorm.DB
+}
+
+// NewAssignmentService creates a new teaching assignment service
+func NewAssignmentService(repo AssignmentRepository, subjectRepo SubjectRepository, db *gorm.DB) AssignmentService {
+	return &assignmentService{repo: repo, subjectRepo: subjectRepo, db: db}
+}
+
+// CreateAssignment assigns a teacher to teach a subject in a class for a semester
+func (s *assignmentService) CreateAssignment(ctx context.Context, schoolID uint, req CreateTeachingAssignmentRequest) (*TeachingAssignmentResponse, error) {
+	if req.TeacherID == 0 || req.SubjectID == 0 || req.ClassID == 0 {
+		return nil, ErrTeachingAssignmentIncomplete
+	}
+
+	teacher, err := s.repo.FindTeacher(ctx, schoolID, req.TeacherID)
+	if err != nil {
+		return nil, err
+	}
+	if !models.CanTeach(teacher.Role) {
+		return nil, ErrTeacherCannotTeach
+	}
+
+	subject, err := s.subjectRepo.FindSubjectByID(ctx, schoolID, req.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+	if !subject.IsActive {
+		return nil, ErrSubjectInactive
+	}
+
+	class, err := s.repo.FindClass(ctx, schoolID, req.ClassID)
+	if err != nil {
+		return nil, err
+	}
+	if class.IsArchived {
+		return nil, ErrClassArchived
+	}
+
+	period, err := gradebook.ResolvePeriod(ctx, s.db, schoolID, gradebook.Period{
+		AcademicYear: req.AcademicYear,
+		Semester:     req.Semester,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := period.DateRange(time.UTC); err != nil {
+		return nil, err
+	}
+
+	assignment := &models.TeachingAssignment{
+		SchoolID:     schoolID,
+		TeacherID:    teacher.ID,
+		SubjectID:    subject.ID,
+		ClassID:      class.ID,
+		AcademicYear: period.AcademicYear,
+		Semester:     period.Semester,
+	}
+
+	exists, err := s.repo.Exists(ctx, assignment)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrTeachingAssignmentExists
+	}
+
+	if err := s.repo.Create(ctx, assignment); err != nil {
+		return nil, err
+	}
+
+	assignment, err = s.repo.FindByID(ctx, schoolID, assignment.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toTeachingAssignmentResponse(assignment), nil
+}
+
+// GetAssignments retrieves the teaching assignments of a school
+func (s *assignmentService) GetAssignments(ctx context.Context, schoolID uint, filter TeachingAssignmentFilter) (*TeachingAssignmentListResponse, error) {
+	assignments, err := s.repo.FindAll(ctx, schoolID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]TeachingAssignmentResponse, len(assignments))
+	for i := range assignments {
+		responses[i] = *toTeachingAssignmentResponse(&assignments[i])
+	}
+	return &TeachingAssignmentListResponse{Assignments: responses}, nil
+}
+
+// DeleteAssignment removes a teaching assignment
+// Grades already recorded under the assignment are kept.
+func (s *assignmentService) DeleteAssignment(ctx context.Context, schoolID, id uint) error {
+	return s.repo.Delete(ctx, schoolID, id)
+}
+
+// toTeachingAssignmentResponse converts a teaching assignment to its response
+func toTeachingAssignmentResponse(a *models.TeachingAssignment) *TeachingAssignmentResponse {
+	teacherName := a.Teacher.Name
+	if teacherName == "" {
+		teacherName = a.Teacher.Username
+	}
+	return &TeachingAssignmentResponse{
+		ID:           a.ID,
+		TeacherID:    a.TeacherID,
+		TeacherName:  teacherName,
+		SubjectID:    a.SubjectID,
+		SubjectCode:  a.Subject.Code,
+		SubjectName:  a.Subject.Name,
+		ClassID:      a.ClassID,
+		ClassName:    a.Class.Name,
+		AcademicYear: a.AcademicYear,
+		Semester:     a.Semester,
+		CreatedAt:    a.CreatedAt,
+	}
+}
@@ -2,6 +2,8 @@ package grade
 
 import (
 	"time"
+
+	"github.com/school-management/backend/internal/shared/gradebook"
 )
 
 // ==================== Pagination ====================
@@ -18,18 +20,24 @@ type PaginationMeta struct {
 
 // CreateGradeRequest represents the request to create a grade
 // Requirements: 10.1 - Grade SHALL require title, score, and student_id
+// The grade is recorded in the school's current academic year and semester. Grades without a
+// subject and assessment category are not part of the report-card averages.
 type CreateGradeRequest struct {
-	StudentID   uint    `json:"student_id" validate:"required"`
-	Title       string  `json:"title" validate:"required"`
-	Score       float64 `json:"score" validate:"required,min=0,max=100"`
-	Description string  `json:"description"`
+	StudentID            uint    `json:"student_id" validate:"required"`
+	SubjectID            *uint   `json:"subject_id"`
+	AssessmentCategoryID *uint   `json:"assessment_category_id"`
+	Title                string  `json:"title" validate:"required"`
+	Score                float64 `json:"score" validate:"required,min=0,max=100"`
+	Description          string  `json:"description"`
 }
 
 // UpdateGradeRequest represents the request to update a grade
 type UpdateGradeRequest struct {
-	Title       string  `json:"title"`
-	Score       float64 `json:"score" validate:"min=0,max=100"`
-	Description string  `json:"description"`
+	SubjectID            *uint   `json:"subject_id"`
+	AssessmentCategoryID *uint   `json:"assessment_category_id"`
+	Title                string  `json:"title"`
+	Score                float64 `json:"score" validate:"min=0,max=100"`
+	Description          string  `json:"description"`
 }
 
 // GradeResponse represents a grade in responses
@@ -47,6 +55,13 @@ type GradeResponse struct {
 	CreatorName string    `json:"creator_name,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	SubjectID              *uint  `json:"subject_id,omitempty"`
+	SubjectName            string `json:"subject_name,omitempty"`
+	AssessmentCategoryID   *uint  `json:"assessment_category_id,omitempty"`
+	AssessmentCategoryName string `json:"assessment_category_name,omitempty"`
+	AcademicYear           string `json:"academic_year,omitempty"`
+	Semester               int    `json:"semester,omitempty"`
 }
 
 // GradeListResponse represents a paginated list of grades
@@ -57,19 +72,135 @@ type GradeListResponse struct {
 
 // GradeFilter represents filter options for listing grades
 type GradeFilter struct {
-	StudentID *uint   `query:"student_id"`
-	ClassID   *uint   `query:"class_id"`
-	StartDate *string `query:"start_date"`
-	EndDate   *string `query:"end_date"`
-	Page      int     `query:"page"`
-	PageSize  int     `query:"page_size"`
+	StudentID    *uint   `query:"student_id"`
+	ClassID      *uint   `query:"class_id"`
+	SubjectID    *uint   `query:"subject_id"`
+	AcademicYear *string `query:"academic_year"`
+	Semester     *int    `query:"semester"`
+	StartDate    *string `query:"start_date"`
+	EndDate      *string `query:"end_date"`
+	Page         int     `query:"page"`
+	PageSize     int     `query:"page_size"`
 }
 
 // StudentGradeSummary represents grade summary for a student
+// TotalGrades and AverageScore cover every grade of the student; the embedded report card
+// holds the weighted per-subject averages of one semester.
 type StudentGradeSummary struct {
 	StudentID    uint    `json:"student_id"`
 	StudentName  string  `json:"student_name"`
 	ClassName    string  `json:"class_name"`
 	TotalGrades  int     `json:"total_grades"`
 	AverageScore float64 `json:"average_score"`
+
+	gradebook.Summary
+}
+
+// ==================== Subject DTOs ====================
+
+// CreateSubjectRequest represents the request to create a subject
+type CreateSubjectRequest struct {
+	Code        string   `json:"code" validate:"required"`
+	Name        string   `json:"name" validate:"required"`
+	KKM         *float64 `json:"kkm" validate:"omitempty,min=0,max=100"` // Defaults to 75
+	Description string   `json:"description"`
+}
+
+// UpdateSubjectRequest represents the request to update a subject
+type UpdateSubjectRequest struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	KKM         *float64 `json:"kkm" validate:"omitempty,min=0,max=100"`
+	Description *string  `json:"description"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// SubjectResponse represents a subject in responses
+type SubjectResponse struct {
+	ID          uint      `json:"id"`
+	SchoolID    uint      `json:"school_id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	KKM         float64   `json:"kkm"`
+	Description string    `json:"description"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SubjectListResponse represents a list of subjects
+type SubjectListResponse struct {
+	Subjects []SubjectResponse `json:"subjects"`
+}
+
+// CreateAssessmentCategoryRequest represents the request to create an assessment category
+type CreateAssessmentCategoryRequest struct {
+	Code   string  `json:"code" validate:"required"`
+	Name   string  `json:"name" validate:"required"`
+	Weight float64 `json:"weight" validate:"required,gt=0,max=100"`
+}
+
+// UpdateAssessmentCategoryRequest represents the request to update an assessment category
+type UpdateAssessmentCategoryRequest struct {
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Weight   *float64 `json:"weight" validate:"omitempty,gt=0,max=100"`
+	IsActive *bool    `json:"is_active"`
+}
+
+// AssessmentCategoryResponse represents an assessment category in responses
+type AssessmentCategoryResponse struct {
+	ID        uint      `json:"id"`
+	SchoolID  uint      `json:"school_id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Weight    float64   `json:"weight"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AssessmentCategoryListResponse represents a list of assessment categories
+type AssessmentCategoryListResponse struct {
+	Categories []AssessmentCategoryResponse `json:"categories"`
+}
+
+// ==================== Teaching Assignment DTOs ====================
+
+// CreateTeachingAssignmentRequest represents the request to assign a teacher to a subject and class
+type CreateTeachingAssignmentRequest struct {
+	TeacherID    uint   `json:"teacher_id" validate:"required"`
+	SubjectID    uint   `json:"subject_id" validate:"required"`
+	ClassID      uint   `json:"class_id" validate:"required"`
+	AcademicYear string `json:"academic_year"` // Defaults to the school's current academic year
+	Semester     int    `json:"semester"`      // Defaults to the school's current semester
+}
+
+// TeachingAssignmentFilter represents filter options for listing teaching assignments
+type TeachingAssignmentFilter struct {
+	TeacherID    *uint
+	ClassID      *uint
+	SubjectID    *uint
+	AcademicYear *string
+	Semester     *int
+}
+
+// TeachingAssignmentResponse represents a teaching assignment in responses
+type TeachingAssignmentResponse struct {
+	ID           uint      `json:"id"`
+	TeacherID    uint      `json:"teacher_id"`
+	TeacherName  string    `json:"teacher_name"`
+	SubjectID    uint      `json:"subject_id"`
+	SubjectCode  string    `json:"subject_code"`
+	SubjectName  string    `json:"subject_name"`
+	ClassID      uint      `json:"class_id"`
+	ClassName    string    `json:"class_name"`
+	AcademicYear string    `json:"academic_year"`
+	Semester     int       `json:"semester"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TeachingAssignmentListResponse represents a list of teaching assignments
+type TeachingAssignmentListResponse struct {
+	Assignments []TeachingAssignmentResponse `json:"assignments"`
 }
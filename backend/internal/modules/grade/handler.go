@@ -5,6 +5,10 @@ import (
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/middleware"
+	"github.com/school-management/backend/internal/shared/gradebook"
 )
 
 // Handler handles HTTP requests for Grade management
@@ -24,10 +28,10 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 
 	// Grade CRUD
 	grades.Get("", h.GetGrades)
-	grades.Post("", h.CreateGrade)
+	grades.Post("", middleware.GradeWriteAccess(), h.CreateGrade)
 	grades.Get("/:id", h.GetGradeByID)
-	grades.Put("/:id", h.UpdateGrade)
-	grades.Delete("/:id", h.DeleteGrade)
+	grades.Put("/:id", middleware.GradeWriteAccess(), h.UpdateGrade)
+	grades.Delete("/:id", middleware.GradeWriteAccess(), h.DeleteGrade)
 
 	// Student grades
 	grades.Get("/student/:studentId", h.GetStudentGrades)
@@ -41,10 +45,10 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 func (h *Handler) RegisterRoutesWithoutGroup(router fiber.Router) {
 	// Grade CRUD
 	router.Get("", h.GetGrades)
-	router.Post("", h.CreateGrade)
+	router.Post("", middleware.GradeWriteAccess(), h.CreateGrade)
 	router.Get("/:id", h.GetGradeByID)
-	router.Put("/:id", h.UpdateGrade)
-	router.Delete("/:id", h.DeleteGrade)
+	router.Put("/:id", middleware.GradeWriteAccess(), h.UpdateGrade)
+	router.Delete("/:id", middleware.GradeWriteAccess(), h.DeleteGrade)
 
 	// Student grades
 	router.Get("/student/:studentId", h.GetStudentGrades)
@@ -58,7 +62,7 @@ func (h *Handler) RegisterRoutesWithoutGroup(router fiber.Router) {
 
 // CreateGrade handles creating a new grade
 // @Summary Create grade
-// @Description Input a new grade for a student
+// @Description Input a new grade for a student. Wali kelas grade their homeroom class; guru only the subjects they are assigned to teach in the student's class
 // @Tags Grades
 // @Accept json
 // @Produce json
@@ -80,12 +84,14 @@ func (h *Handler) CreateGrade(c *fiber.Ctx) error {
 		return h.authRequiredError(c)
 	}
 
+	role, _ := c.Locals("role").(string)
+
 	var req CreateGradeRequest
 	if err := c.BodyParser(&req); err != nil {
 		return h.invalidBodyError(c)
 	}
 
-	response, err := h.service.CreateGrade(c.Context(), schoolID, userID, req)
+	response, err := h.service.CreateGrade(c.Context(), schoolID, userID, models.UserRole(role), req)
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -106,6 +112,9 @@ func (h *Handler) CreateGrade(c *fiber.Ctx) error {
 // @Param class_id query int false "Filter by class ID"
 // @Param start_date query string false "Filter by start date (YYYY-MM-DD)"
 // @Param end_date query string false "Filter by end date (YYYY-MM-DD)"
+// @Param subject_id query int false "Filter by subject ID"
+// @Param academic_year query string false "Filter by academic year (e.g. 2024/2025)"
+// @Param semester query int false "Filter by semester (1 or 2)"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
 // @Success 200 {object} GradeListResponse
@@ -193,10 +202,12 @@ func (h *Handler) GetStudentGrades(c *fiber.Ctx) error {
 
 // GetStudentGradeSummary handles getting grade summary for a student
 // @Summary Get student grade summary
-// @Description Get grade summary (total grades, average score) for a student
+// @Description Get grade summary (total grades, average score) and the weighted report-card averages per subject for a student
 // @Tags Grades
 // @Produce json
 // @Param studentId path int true "Student ID"
+// @Param academic_year query string false "Academic year, defaults to the school's current one"
+// @Param semester query int false "Semester (1 or 2), defaults to the school's current one"
 // @Success 200 {object} StudentGradeSummary
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -209,7 +220,12 @@ func (h *Handler) GetStudentGradeSummary(c *fiber.Ctx) error {
 		return h.invalidIDError(c, "student")
 	}
 
-	response, err := h.service.GetStudentGradeSummary(c.Context(), uint(studentID))
+	period := gradebook.Period{
+		AcademicYear: c.Query("academic_year"),
+		Semester:     c.QueryInt("semester"),
+	}
+
+	response, err := h.service.GetStudentGradeSummary(c.Context(), uint(studentID), period)
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -228,6 +244,9 @@ func (h *Handler) GetStudentGradeSummary(c *fiber.Ctx) error {
 // @Param classId path int true "Class ID"
 // @Param start_date query string false "Filter by start date (YYYY-MM-DD)"
 // @Param end_date query string false "Filter by end date (YYYY-MM-DD)"
+// @Param subject_id query int false "Filter by subject ID"
+// @Param academic_year query string false "Filter by academic year (e.g. 2024/2025)"
+// @Param semester query int false "Filter by semester (1 or 2)"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
 // @Success 200 {object} GradeListResponse
@@ -256,7 +275,7 @@ func (h *Handler) GetClassGrades(c *fiber.Ctx) error {
 
 // UpdateGrade handles updating a grade
 // @Summary Update grade
-// @Description Update an existing grade
+// @Description Update an existing grade the user is allowed to manage
 // @Tags Grades
 // @Accept json
 // @Produce json
@@ -270,6 +289,17 @@ func (h *Handler) GetClassGrades(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Router /api/v1/grades/{id} [put]
 func (h *Handler) UpdateGrade(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
 		return h.invalidIDError(c, "grade")
@@ -280,7 +310,7 @@ func (h *Handler) UpdateGrade(c *fiber.Ctx) error {
 		return h.invalidBodyError(c)
 	}
 
-	response, err := h.service.UpdateGrade(c.Context(), uint(id), req)
+	response, err := h.service.UpdateGrade(c.Context(), schoolID, userID, models.UserRole(role), uint(id), req)
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -294,7 +324,7 @@ func (h *Handler) UpdateGrade(c *fiber.Ctx) error {
 
 // DeleteGrade handles deleting a grade
 // @Summary Delete grade
-// @Description Delete a specific grade record
+// @Description Delete a specific grade record the user is allowed to manage
 // @Tags Grades
 // @Produce json
 // @Param id path int true "Grade ID"
@@ -305,12 +335,23 @@ func (h *Handler) UpdateGrade(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Router /api/v1/grades/{id} [delete]
 func (h *Handler) DeleteGrade(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
 		return h.invalidIDError(c, "grade")
 	}
 
-	if err := h.service.DeleteGrade(c.Context(), uint(id)); err != nil {
+	if err := h.service.DeleteGrade(c.Context(), schoolID, userID, models.UserRole(role), uint(id)); err != nil {
 		return h.handleError(c, err)
 	}
 
@@ -342,6 +383,23 @@ func (h *Handler) parseGradeFilter(c *fiber.Ctx) GradeFilter {
 		}
 	}
 
+	if subjectIDStr := c.Query("subject_id"); subjectIDStr != "" {
+		if subjectID, err := strconv.ParseUint(subjectIDStr, 10, 32); err == nil {
+			id := uint(subjectID)
+			filter.SubjectID = &id
+		}
+	}
+
+	if academicYear := c.Query("academic_year"); academicYear != "" {
+		filter.AcademicYear = &academicYear
+	}
+
+	if semesterStr := c.Query("semester"); semesterStr != "" {
+		if semester, err := strconv.Atoi(semesterStr); err == nil {
+			filter.Semester = &semester
+		}
+	}
+
 	if startDate := c.Query("start_date"); startDate != "" {
 		filter.StartDate = &startDate
 	}
@@ -469,6 +527,70 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 				"message": "Tidak ada kelas yang ditugaskan untuk guru ini",
 			},
 		})
+	case errors.Is(err, ErrSubjectNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_SUBJECT",
+				"message": "Mata pelajaran tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrAssessmentCategoryNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_ASSESSMENT_CATEGORY",
+				"message": "Kategori penilaian tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrSubjectRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Mata pelajaran wajib diisi jika kategori penilaian diisi",
+			},
+		})
+	case errors.Is(err, ErrCategoryRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Kategori penilaian wajib diisi jika mata pelajaran diisi",
+			},
+		})
+	case errors.Is(err, ErrSubjectInactive):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_VALUE",
+				"message": "Mata pelajaran tidak aktif",
+			},
+		})
+	case errors.Is(err, ErrCategoryInactive):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_VALUE",
+				"message": "Kategori penilaian tidak aktif",
+			},
+		})
+	case errors.Is(err, ErrSubjectRequiredForTeacher):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Guru mata pelajaran wajib memilih mata pelajaran",
+			},
+		})
+	case errors.Is(err, ErrNotTeachingAssignment):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_NOT_TEACHING_ASSIGNMENT",
+				"message": "Anda tidak ditugaskan mengajar mata pelajaran ini di kelas siswa",
+			},
+		})
 	case errors.Is(err, ErrNotAuthorized):
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"success": false,
@@ -6,8 +6,11 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/enrollment"
+	"github.com/school-management/backend/internal/shared/outbox"
 )
 
 var (
@@ -19,7 +22,7 @@ var (
 // Repository defines the interface for Grade data operations
 type Repository interface {
 	// Grade operations
-	Create(ctx context.Context, grade *models.Grade) error
+	Create(ctx context.Context, grade *models.Grade, event *outbox.Event) error
 	FindByID(ctx context.Context, id uint) (*models.Grade, error)
 	FindByStudent(ctx context.Context, studentID uint) ([]models.Grade, error)
 	FindAll(ctx context.Context, schoolID uint, filter GradeFilter) ([]models.Grade, int64, error)
@@ -47,8 +50,14 @@ func NewRepository(db *gorm.DB) Repository {
 
 // Create creates a new grade record
 // Requirements: 10.1 - WHEN a Wali_Kelas inputs a grade
-func (r *repository) Create(ctx context.Context, grade *models.Grade) error {
-	return r.db.WithContext(ctx).Create(grade).Error
+func (r *repository) Create(ctx context.Context, grade *models.Grade, event *outbox.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Subject and category are loaded for the event; they must not be written back
+		if err := tx.Omit(clause.Associations).Create(grade).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, grade.ID, event)
+	})
 }
 
 // FindByID retrieves a grade by ID
@@ -57,6 +66,8 @@ func (r *repository) FindByID(ctx context.Context, id uint) (*models.Grade, erro
 	err := r.db.WithContext(ctx).
 		Preload("Student").
 		Preload("Student.Class").
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
 		Where("id = ?", id).
 		First(&grade).Error
@@ -78,6 +89,8 @@ func (r *repository) FindByStudent(ctx context.Context, studentID uint) ([]model
 	err := r.db.WithContext(ctx).
 		Preload("Student").
 		Preload("Student.Class").
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
 		Where("student_id = ?", studentID).
 		Order("created_at DESC").
@@ -100,20 +113,9 @@ func (r *repository) FindAll(ctx context.Context, schoolID uint, filter GradeFil
 		query = query.Where("grades.student_id = ?", *filter.StudentID)
 	}
 	if filter.ClassID != nil {
-		query = query.Where("students.class_id = ?", *filter.ClassID)
-	}
-	if filter.StartDate != nil {
-		startDate, err := time.Parse("2006-01-02", *filter.StartDate)
-		if err == nil {
-			query = query.Where("grades.created_at >= ?", startDate)
-		}
-	}
-	if filter.EndDate != nil {
-		endDate, err := time.Parse("2006-01-02", *filter.EndDate)
-		if err == nil {
-			query = query.Where("grades.created_at <= ?", endDate.Add(24*time.Hour))
-		}
+		query = query.Where(enrollment.ClassAt("grades", "grades.created_at::date")+" = ?", *filter.ClassID)
 	}
+	query = applyGradeFilter(query, filter).Session(&gorm.Session{})
 
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
@@ -130,12 +132,12 @@ func (r *repository) FindAll(ctx context.Context, schoolID uint, filter GradeFil
 	}
 
 	// Fetch records
-	err := r.db.WithContext(ctx).
+	err := query.
 		Preload("Student").
 		Preload("Student.Class").
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
-		Joins("JOIN students ON students.id = grades.student_id").
-		Where("students.school_id = ?", schoolID).
 		Order("grades.created_at DESC").
 		Offset(offset).
 		Limit(filter.PageSize).
@@ -154,10 +156,12 @@ func (r *repository) Update(ctx context.Context, grade *models.Grade) error {
 		Model(&models.Grade{}).
 		Where("id = ?", grade.ID).
 		Updates(map[string]interface{}{
-			"student_id":  grade.StudentID,
-			"title":       grade.Title,
-			"score":       grade.Score,
-			"description": grade.Description,
+			"student_id":             grade.StudentID,
+			"subject_id":             grade.SubjectID,
+			"assessment_category_id": grade.AssessmentCategoryID,
+			"title":                  grade.Title,
+			"score":                  grade.Score,
+			"description":            grade.Description,
 		})
 	if result.Error != nil {
 		return result.Error
@@ -258,21 +262,10 @@ func (r *repository) GetClassGrades(ctx context.Context, classID uint, filter Gr
 	query := r.db.WithContext(ctx).
 		Model(&models.Grade{}).
 		Joins("JOIN students ON students.id = grades.student_id").
-		Where("students.class_id = ?", classID)
+		Where(enrollment.ClassAt("grades", "grades.created_at::date")+" = ?", classID)
 
-	// Apply date filters
-	if filter.StartDate != nil {
-		startDate, err := time.Parse("2006-01-02", *filter.StartDate)
-		if err == nil {
-			query = query.Where("grades.created_at >= ?", startDate)
-		}
-	}
-	if filter.EndDate != nil {
-		endDate, err := time.Parse("2006-01-02", *filter.EndDate)
-		if err == nil {
-			query = query.Where("grades.created_at <= ?", endDate.Add(24*time.Hour))
-		}
-	}
+	// Apply subject, period and date filters
+	query = applyGradeFilter(query, filter).Session(&gorm.Session{})
 
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
@@ -289,12 +282,12 @@ func (r *repository) GetClassGrades(ctx context.Context, classID uint, filter Gr
 	}
 
 	// Fetch records
-	err := r.db.WithContext(ctx).
+	err := query.
 		Preload("Student").
 		Preload("Student.Class").
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
-		Joins("JOIN students ON students.id = grades.student_id").
-		Where("students.class_id = ?", classID).
 		Order("grades.created_at DESC").
 		Offset(offset).
 		Limit(filter.PageSize).
@@ -306,3 +299,30 @@ func (r *repository) GetClassGrades(ctx context.Context, classID uint, filter Gr
 
 	return grades, total, nil
 }
+
+// applyGradeFilter applies the subject, period and date filters shared by grade listings
+// Callers start a new session on the result so the count and fetch queries share the filters.
+func applyGradeFilter(query *gorm.DB, filter GradeFilter) *gorm.DB {
+	if filter.SubjectID != nil {
+		query = query.Where("grades.subject_id = ?", *filter.SubjectID)
+	}
+	if filter.AcademicYear != nil {
+		query = query.Where("grades.academic_year = ?", *filter.AcademicYear)
+	}
+	if filter.Semester != nil {
+		query = query.Where("grades.semester = ?", *filter.Semester)
+	}
+	if filter.StartDate != nil {
+		startDate, err := time.Parse("2006-01-02", *filter.StartDate)
+		if err == nil {
+			query = query.Where("grades.created_at >= ?", startDate)
+		}
+	}
+	if filter.EndDate != nil {
+		endDate, err := time.Parse("2006-01-02", *filter.EndDate)
+		if err == nil {
+			query = query.Where("grades.created_at <= ?", endDate.Add(24*time.Hour))
+		}
+	}
+	return query
+}
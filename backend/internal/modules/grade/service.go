@@ -3,57 +3,68 @@ package grade
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
+	"github.com/school-management/backend/internal/shared/outbox"
 )
 
 var (
-	ErrStudentIDRequired   = errors.New("ID siswa wajib diisi")
-	ErrTitleRequired       = errors.New("judul wajib diisi")
-	ErrScoreInvalid        = errors.New("nilai harus antara 0 dan 100")
-	ErrStudentNotInSchool  = errors.New("student does not belong to this school")
-	ErrStudentNotInClass   = errors.New("student does not belong to your assigned class")
-	ErrNotAuthorized       = errors.New("not authorized to perform this action")
-	ErrNoClassAssigned     = errors.New("no class assigned to this teacher")
+	ErrStudentIDRequired  = errors.New("ID siswa wajib diisi")
+	ErrTitleRequired      = errors.New("judul wajib diisi")
+	ErrScoreInvalid       = errors.New("nilai harus antara 0 dan 100")
+	ErrStudentNotInSchool = errors.New("student does not belong to this school")
+	ErrStudentNotInClass  = errors.New("student does not belong to your assigned class")
+	ErrNotAuthorized      = errors.New("not authorized to perform this action")
+	ErrNoClassAssigned    = errors.New("no class assigned to this teacher")
+	ErrSubjectRequired    = errors.New("mata pelajaran wajib diisi bersama kategori penilaian")
+	ErrCategoryRequired   = errors.New("kategori penilaian wajib diisi bersama mata pelajaran")
+
+	ErrSubjectRequiredForTeacher = errors.New("guru mata pelajaran wajib memilih mata pelajaran")
+	ErrNotTeachingAssignment     = errors.New("anda tidak ditugaskan mengajar mata pelajaran ini di kelas siswa")
 )
 
 // Service defines the interface for Grade business logic
 type Service interface {
 	// Grade operations
-	CreateGrade(ctx context.Context, schoolID, teacherID uint, req CreateGradeRequest) (*GradeResponse, error)
+	CreateGrade(ctx context.Context, schoolID, teacherID uint, role models.UserRole, req CreateGradeRequest) (*GradeResponse, error)
 	GetGradeByID(ctx context.Context, id uint) (*GradeResponse, error)
 	GetStudentGrades(ctx context.Context, studentID uint) ([]GradeResponse, error)
 	GetGrades(ctx context.Context, schoolID uint, filter GradeFilter) (*GradeListResponse, error)
-	UpdateGrade(ctx context.Context, gradeID uint, req UpdateGradeRequest) (*GradeResponse, error)
-	DeleteGrade(ctx context.Context, gradeID uint) error
+	UpdateGrade(ctx context.Context, schoolID, userID uint, role models.UserRole, gradeID uint, req UpdateGradeRequest) (*GradeResponse, error)
+	DeleteGrade(ctx context.Context, schoolID, userID uint, role models.UserRole, gradeID uint) error
 
 	// Teacher validation
-	ValidateTeacherAccess(ctx context.Context, teacherID, studentID uint) error
+	ValidateTeacherAccess(ctx context.Context, teacherID uint, role models.UserRole, studentID uint, subjectID *uint, period gradebook.Period) error
 	GetTeacherClassID(ctx context.Context, teacherID uint) (*uint, error)
 
 	// Summary
-	GetStudentGradeSummary(ctx context.Context, studentID uint) (*StudentGradeSummary, error)
+	GetStudentGradeSummary(ctx context.Context, studentID uint, period gradebook.Period) (*StudentGradeSummary, error)
 	GetClassGrades(ctx context.Context, classID uint, filter GradeFilter) (*GradeListResponse, error)
 }
 
 // service implements the Service interface
 type service struct {
-	repo Repository
-	db   *gorm.DB
+	repo           Repository
+	subjectRepo    SubjectRepository
+	assignmentRepo AssignmentRepository
+	db             *gorm.DB
 }
 
 // NewService creates a new Grade service
-func NewService(repo Repository, db *gorm.DB) Service {
-	return &service{repo: repo, db: db}
+func NewService(repo Repository, subjectRepo SubjectRepository, assignmentRepo AssignmentRepository, db *gorm.DB) Service {
+	return &service{repo: repo, subjectRepo: subjectRepo, assignmentRepo: assignmentRepo, db: db}
 }
 
 // CreateGrade creates a new grade record
 // Requirements: 10.1 - WHEN a Wali_Kelas inputs a grade, THE System SHALL require title, score
 // Requirements: 10.2 - WHEN a grade is saved, THE System SHALL associate it with the student and the Wali_Kelas
 // Requirements: 10.5 - THE System SHALL validate that Wali_Kelas can only input grades for students in their assigned class
-func (s *service) CreateGrade(ctx context.Context, schoolID, teacherID uint, req CreateGradeRequest) (*GradeResponse, error) {
+// A guru can only input grades of the subjects they are assigned to teach in the student's class.
+func (s *service) CreateGrade(ctx context.Context, schoolID, teacherID uint, role models.UserRole, req CreateGradeRequest) (*GradeResponse, error) {
 	// Validate required fields
 	if req.StudentID == 0 {
 		return nil, ErrStudentIDRequired
@@ -74,20 +85,40 @@ func (s *service) CreateGrade(ctx context.Context, schoolID, teacherID uint, req
 		return nil, ErrStudentNotInSchool
 	}
 
-	// Validate teacher has access to this student (wali kelas validation)
-	if err := s.ValidateTeacherAccess(ctx, teacherID, req.StudentID); err != nil {
+	// Grades belong to the semester that is current when they are recorded
+	period, err := gradebook.CurrentPeriod(ctx, s.db, schoolID)
+	if err != nil {
 		return nil, err
 	}
 
-	grade := &models.Grade{
-		StudentID:   req.StudentID,
-		Title:       req.Title,
-		Score:       req.Score,
-		Description: req.Description,
-		CreatedBy:   teacherID,
+	// Validate teacher has access to this student (wali kelas or teaching assignment)
+	if err := s.ValidateTeacherAccess(ctx, teacherID, role, req.StudentID, req.SubjectID, period); err != nil {
+		return nil, err
+	}
+
+	subject, category, err := s.resolveAssessment(ctx, schoolID, req.SubjectID, req.AssessmentCategoryID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.Create(ctx, grade); err != nil {
+	grade := &models.Grade{
+		StudentID:          req.StudentID,
+		Title:              req.Title,
+		Score:              req.Score,
+		Description:        req.Description,
+		AcademicYear:       period.AcademicYear,
+		Semester:           period.Semester,
+		Subject:            subject,
+		AssessmentCategory: category,
+		CreatedBy:          teacherID,
+	}
+	if subject != nil {
+		grade.SubjectID = &subject.ID
+		grade.AssessmentCategoryID = &category.ID
+	}
+
+	// Requirements: 10.3 - Parent notification is stored in the outbox with the grade
+	if err := s.repo.Create(ctx, grade, newGradeEvent(student, grade)); err != nil {
 		return nil, err
 	}
 
@@ -97,12 +128,31 @@ func (s *service) CreateGrade(ctx context.Context, schoolID, teacherID uint, req
 		return nil, err
 	}
 
-	// TODO: Trigger notification to parent (async)
-	// Requirements: 10.3 - WHEN a grade is recorded, THE System SHALL optionally trigger notification to the parent
-
 	return toGradeResponse(grade), nil
 }
 
+// newGradeEvent builds the outbox event for a newly recorded grade
+// Requirements: 10.3 - WHEN a grade is recorded, THE System SHALL optionally trigger notification to the parent
+func newGradeEvent(student *models.Student, grade *models.Grade) *outbox.Event {
+	title := grade.Title
+	if grade.Subject != nil {
+		title = fmt.Sprintf("%s (%s)", grade.Title, grade.Subject.Name)
+	}
+
+	return outbox.NewEvent(outbox.EventGradeCreated, outbox.Payload{
+		SchoolID:  student.SchoolID,
+		StudentID: student.ID,
+		Notification: outbox.ParentNotification(
+			models.NotificationTypeGrade,
+			"Nilai Baru",
+			fmt.Sprintf("Nilai %s untuk %s: %.1f", title, student.Name, grade.Score),
+			map[string]interface{}{
+				"student_id": fmt.Sprintf("%d", student.ID),
+				"score":      fmt.Sprintf("%.1f", grade.Score),
+			},
+		),
+	})
+}
 
 // GetGradeByID retrieves a grade by ID
 func (s *service) GetGradeByID(ctx context.Context, id uint) (*GradeResponse, error) {
@@ -164,8 +214,9 @@ func (s *service) GetGrades(ctx context.Context, schoolID uint, filter GradeFilt
 }
 
 // UpdateGrade updates a grade record
-func (s *service) UpdateGrade(ctx context.Context, gradeID uint, req UpdateGradeRequest) (*GradeResponse, error) {
-	grade, err := s.repo.FindByID(ctx, gradeID)
+// Teachers can only update grades they may record, for the semester the grade belongs to.
+func (s *service) UpdateGrade(ctx context.Context, schoolID, userID uint, role models.UserRole, gradeID uint, req UpdateGradeRequest) (*GradeResponse, error) {
+	grade, err := s.findManagedGrade(ctx, schoolID, userID, role, gradeID)
 	if err != nil {
 		return nil, err
 	}
@@ -179,6 +230,28 @@ func (s *service) UpdateGrade(ctx context.Context, gradeID uint, req UpdateGrade
 	if req.Description != "" {
 		grade.Description = req.Description
 	}
+	if req.SubjectID != nil || req.AssessmentCategoryID != nil {
+		subjectID, categoryID := req.SubjectID, req.AssessmentCategoryID
+		if subjectID == nil {
+			subjectID = grade.SubjectID
+		}
+		if categoryID == nil {
+			categoryID = grade.AssessmentCategoryID
+		}
+		subject, category, err := s.resolveAssessment(ctx, grade.Student.SchoolID, subjectID, categoryID)
+		if err != nil {
+			return nil, err
+		}
+		// Moving a grade to another subject needs access to that subject too
+		if grade.SubjectID == nil || *grade.SubjectID != subject.ID {
+			period := gradebook.Period{AcademicYear: grade.AcademicYear, Semester: grade.Semester}
+			if err := s.ValidateTeacherAccess(ctx, userID, role, grade.StudentID, &subject.ID, period); err != nil {
+				return nil, err
+			}
+		}
+		grade.SubjectID, grade.Subject = &subject.ID, subject
+		grade.AssessmentCategoryID, grade.AssessmentCategory = &category.ID, category
+	}
 
 	if err := s.repo.Update(ctx, grade); err != nil {
 		return nil, err
@@ -188,20 +261,42 @@ func (s *service) UpdateGrade(ctx context.Context, gradeID uint, req UpdateGrade
 }
 
 // DeleteGrade deletes a grade record
-func (s *service) DeleteGrade(ctx context.Context, gradeID uint) error {
-	return s.repo.Delete(ctx, gradeID)
+func (s *service) DeleteGrade(ctx context.Context, schoolID, userID uint, role models.UserRole, gradeID uint) error {
+	grade, err := s.findManagedGrade(ctx, schoolID, userID, role, gradeID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, grade.ID)
 }
 
-// ValidateTeacherAccess validates that a teacher can access a student's grades
-// Requirements: 10.5 - THE System SHALL validate that Wali_Kelas can only input grades for students in their assigned class
-func (s *service) ValidateTeacherAccess(ctx context.Context, teacherID, studentID uint) error {
-	// Get teacher's assigned class
-	classID, err := s.GetTeacherClassID(ctx, teacherID)
+// findManagedGrade retrieves a grade of the school that the user may update or delete
+func (s *service) findManagedGrade(ctx context.Context, schoolID, userID uint, role models.UserRole, gradeID uint) (*models.Grade, error) {
+	grade, err := s.repo.FindByID(ctx, gradeID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if grade.Student.SchoolID != schoolID {
+		return nil, ErrGradeNotFound
 	}
-	if classID == nil {
-		return ErrNoClassAssigned
+
+	period := gradebook.Period{AcademicYear: grade.AcademicYear, Semester: grade.Semester}
+	if err := s.ValidateTeacherAccess(ctx, userID, role, grade.StudentID, grade.SubjectID, period); err != nil {
+		return nil, err
+	}
+	return grade, nil
+}
+
+// ValidateTeacherAccess validates that a teacher can manage a student's grades of a subject in a period
+// Requirements: 10.5 - THE System SHALL validate that Wali_Kelas can only input grades for students in their assigned class
+// A wali kelas manages every grade of their homeroom class and, like a guru, the grades of
+// the subjects they are assigned to teach in other classes.
+func (s *service) ValidateTeacherAccess(ctx context.Context, teacherID uint, role models.UserRole, studentID uint, subjectID *uint, period gradebook.Period) error {
+	switch role {
+	case models.RoleSuperAdmin, models.RoleAdminSekolah:
+		return nil
+	case models.RoleWaliKelas, models.RoleGuru:
+	default:
+		return ErrNotAuthorized
 	}
 
 	// Get student's class
@@ -210,11 +305,39 @@ func (s *service) ValidateTeacherAccess(ctx context.Context, teacherID, studentI
 		return err
 	}
 
-	// Check if student is in teacher's class (handle nullable ClassID)
-	if student.ClassID == nil || *student.ClassID != *classID {
-		return ErrStudentNotInClass
+	if role == models.RoleWaliKelas {
+		// Get teacher's assigned class
+		classID, err := s.GetTeacherClassID(ctx, teacherID)
+		if err != nil {
+			return err
+		}
+
+		// Check if student is in teacher's class (handle nullable ClassID)
+		if classID != nil && student.ClassID != nil && *student.ClassID == *classID {
+			return nil
+		}
+		if subjectID == nil {
+			if classID == nil {
+				return ErrNoClassAssigned
+			}
+			return ErrStudentNotInClass
+		}
 	}
 
+	if subjectID == nil {
+		return ErrSubjectRequiredForTeacher
+	}
+	if student.ClassID == nil {
+		return ErrNotTeachingAssignment
+	}
+
+	assigned, err := s.assignmentRepo.IsAssigned(ctx, teacherID, *subjectID, *student.ClassID, period)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return ErrNotTeachingAssignment
+	}
 	return nil
 }
 
@@ -222,7 +345,7 @@ func (s *service) ValidateTeacherAccess(ctx context.Context, teacherID, studentI
 func (s *service) GetTeacherClassID(ctx context.Context, teacherID uint) (*uint, error) {
 	var class models.Class
 	err := s.db.WithContext(ctx).
-		Where("homeroom_teacher_id = ?", teacherID).
+		Where("homeroom_teacher_id = ? AND is_archived = ?", teacherID, false).
 		First(&class).Error
 
 	if err != nil {
@@ -236,8 +359,62 @@ func (s *service) GetTeacherClassID(ctx context.Context, teacherID uint) (*uint,
 }
 
 // GetStudentGradeSummary retrieves grade summary for a student
-func (s *service) GetStudentGradeSummary(ctx context.Context, studentID uint) (*StudentGradeSummary, error) {
-	return s.repo.GetStudentGradeSummary(ctx, studentID)
+// The report card covers the given period; missing parts default to the current semester.
+func (s *service) GetStudentGradeSummary(ctx context.Context, studentID uint, period gradebook.Period) (*StudentGradeSummary, error) {
+	summary, err := s.repo.GetStudentGradeSummary(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	student, err := s.repo.FindStudentByID(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err = gradebook.ResolvePeriod(ctx, s.db, student.SchoolID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := gradebook.StudentSummary(ctx, s.db, studentID, period)
+	if err != nil {
+		return nil, err
+	}
+	summary.Summary = *report
+
+	return summary, nil
+}
+
+// resolveAssessment validates the subject and assessment category of a grade
+// Both are optional, but must be given together and be active subjects of the school.
+func (s *service) resolveAssessment(ctx context.Context, schoolID uint, subjectID, categoryID *uint) (*models.Subject, *models.AssessmentCategory, error) {
+	if subjectID == nil && categoryID == nil {
+		return nil, nil, nil
+	}
+	if subjectID == nil {
+		return nil, nil, ErrSubjectRequired
+	}
+	if categoryID == nil {
+		return nil, nil, ErrCategoryRequired
+	}
+
+	subject, err := s.subjectRepo.FindSubjectByID(ctx, schoolID, *subjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !subject.IsActive {
+		return nil, nil, ErrSubjectInactive
+	}
+
+	category, err := s.subjectRepo.FindCategoryByID(ctx, schoolID, *categoryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !category.IsActive {
+		return nil, nil, ErrCategoryInactive
+	}
+
+	return subject, category, nil
 }
 
 // GetClassGrades retrieves grades for all students in a class
@@ -303,5 +480,16 @@ func toGradeResponse(g *models.Grade) *GradeResponse {
 		response.CreatorName = g.Creator.Username
 	}
 
+	response.SubjectID = g.SubjectID
+	response.AssessmentCategoryID = g.AssessmentCategoryID
+	response.AcademicYear = g.AcademicYear
+	response.Semester = g.Semester
+	if g.Subject != nil {
+		response.SubjectName = g.Subject.Name
+	}
+	if g.AssessmentCategory != nil {
+		response.AssessmentCategoryName = g.AssessmentCategory.Name
+	}
+
 	return response
 }
@@ -0,0 +1,464 @@
+package grade
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/middleware"
+)
+
+// SubjectHandler handles HTTP requests for subjects and assessment categories
+type SubjectHandler struct {
+	service SubjectService
+}
+
+// NewSubjectHandler creates a new subject handler
+func NewSubjectHandler(service SubjectService) *SubjectHandler {
+	return &SubjectHandler{service: service}
+}
+
+// RegisterRoutes registers subject and assessment category routes
+// Every school user can read them; only admin sekolah can change them.
+func (h *SubjectHandler) RegisterRoutes(router fiber.Router) {
+	subjects := router.Group("/subjects")
+	subjects.Get("", h.GetSubjects)
+	subjects.Post("", middleware.AdminSekolahOnly(), h.CreateSubject)
+	subjects.Get("/:id", h.GetSubjectByID)
+	subjects.Put("/:id", middleware.AdminSekolahOnly(), h.UpdateSubject)
+	subjects.Delete("/:id", middleware.AdminSekolahOnly(), h.DeleteSubject)
+
+	categories := router.Group("/assessment-categories")
+	categories.Get("", h.GetCategories)
+	categories.Post("", middleware.AdminSekolahOnly(), h.CreateCategory)
+	categories.Post("/initialize", middleware.AdminSekolahOnly(), h.InitializeDefaultCategories)
+	categories.Put("/:id", middleware.AdminSekolahOnly(), h.UpdateCategory)
+	categories.Delete("/:id", middleware.AdminSekolahOnly(), h.DeleteCategory)
+}
+
+// ==================== Subject Handlers ====================
+
+// CreateSubject handles creating a subject
+// @Summary Create subject
+// @Description Create a subject (mata pelajaran) with its KKM
+// @Tags Subjects
+// @Accept json
+// @Produce json
+// @Param request body CreateSubjectRequest true "Subject data"
+// @Success 201 {object} SubjectResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/subjects [post]
+func (h *SubjectHandler) CreateSubject(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	var req CreateSubjectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invalidBody(c)
+	}
+
+	response, err := h.service.CreateSubject(c.Context(), schoolID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Mata pelajaran berhasil dibuat",
+	})
+}
+
+// GetSubjects handles listing subjects
+// @Summary List subjects
+// @Description Get the subjects of the school ordered by name
+// @Tags Subjects
+// @Produce json
+// @Param active query bool false "Only active subjects"
+// @Success 200 {object} SubjectListResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/subjects [get]
+func (h *SubjectHandler) GetSubjects(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	response, err := h.service.GetSubjects(c.Context(), schoolID, c.QueryBool("active", false))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetSubjectByID handles getting a subject
+// @Summary Get subject by ID
+// @Description Get a subject of the school
+// @Tags Subjects
+// @Produce json
+// @Param id path int true "Subject ID"
+// @Success 200 {object} SubjectResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/subjects/{id} [get]
+func (h *SubjectHandler) GetSubjectByID(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return invalidID(c, "ID mata pelajaran tidak valid")
+	}
+
+	response, err := h.service.GetSubjectByID(c.Context(), schoolID, uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// UpdateSubject handles updating a subject
+// @Summary Update subject
+// @Description Update a subject, its KKM or deactivate it
+// @Tags Subjects
+// @Accept json
+// @Produce json
+// @Param id path int true "Subject ID"
+// @Param request body UpdateSubjectRequest true "Subject data"
+// @Success 200 {object} SubjectResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/subjects/{id} [put]
+func (h *SubjectHandler) UpdateSubject(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return invalidID(c, "ID mata pelajaran tidak valid")
+	}
+
+	var req UpdateSubjectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invalidBody(c)
+	}
+
+	response, err := h.service.UpdateSubject(c.Context(), schoolID, uint(id), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Mata pelajaran berhasil diperbarui",
+	})
+}
+
+// DeleteSubject handles deleting a subject
+// @Summary Delete subject
+// @Description Delete a subject without grades
+// @Tags Subjects
+// @Produce json
+// @Param id path int true "Subject ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/subjects/{id} [delete]
+func (h *SubjectHandler) DeleteSubject(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return invalidID(c, "ID mata pelajaran tidak valid")
+	}
+
+	if err := h.service.DeleteSubject(c.Context(), schoolID, uint(id)); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Mata pelajaran berhasil dihapus",
+	})
+}
+
+// ==================== Assessment Category Handlers ====================
+
+// CreateCategory handles creating an assessment category
+// @Summary Create assessment category
+// @Description Create a weighted assessment category (e.g. tugas, UH, UTS, UAS, praktik)
+// @Tags Subjects
+// @Accept json
+// @Produce json
+// @Param request body CreateAssessmentCategoryRequest true "Category data"
+// @Success 201 {object} AssessmentCategoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/assessment-categories [post]
+func (h *SubjectHandler) CreateCategory(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	var req CreateAssessmentCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invalidBody(c)
+	}
+
+	response, err := h.service.CreateCategory(c.Context(), schoolID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Kategori penilaian berhasil dibuat",
+	})
+}
+
+// GetCategories handles listing assessment categories
+// @Summary List assessment categories
+// @Description Get the assessment categories of the school with their weights
+// @Tags Subjects
+// @Produce json
+// @Param active query bool false "Only active categories"
+// @Success 200 {object} AssessmentCategoryListResponse
+// @Security BearerAuth
+// @Router /api/v1/assessment-categories [get]
+func (h *SubjectHandler) GetCategories(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	response, err := h.service.GetCategories(c.Context(), schoolID, c.QueryBool("active", false))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// UpdateCategory handles updating an assessment category
+// @Summary Update assessment category
+// @Description Update an assessment category, its weight or deactivate it
+// @Tags Subjects
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param request body UpdateAssessmentCategoryRequest true "Category data"
+// @Success 200 {object} AssessmentCategoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/assessment-categories/{id} [put]
+func (h *SubjectHandler) UpdateCategory(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return invalidID(c, "ID kategori penilaian tidak valid")
+	}
+
+	var req UpdateAssessmentCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invalidBody(c)
+	}
+
+	response, err := h.service.UpdateCategory(c.Context(), schoolID, uint(id), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Kategori penilaian berhasil diperbarui",
+	})
+}
+
+// DeleteCategory handles deleting an assessment category
+// @Summary Delete assessment category
+// @Description Delete an assessment category without grades
+// @Tags Subjects
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/assessment-categories/{id} [delete]
+func (h *SubjectHandler) DeleteCategory(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return invalidID(c, "ID kategori penilaian tidak valid")
+	}
+
+	if err := h.service.DeleteCategory(c.Context(), schoolID, uint(id)); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Kategori penilaian berhasil dihapus",
+	})
+}
+
+// InitializeDefaultCategories handles creating the default assessment categories
+// @Summary Initialize default assessment categories
+// @Description Create tugas, UH, UTS, UAS and praktik with default weights if the school has no categories yet
+// @Tags Subjects
+// @Produce json
+// @Success 200 {object} AssessmentCategoryListResponse
+// @Security BearerAuth
+// @Router /api/v1/assessment-categories/initialize [post]
+func (h *SubjectHandler) InitializeDefaultCategories(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return tenantRequired(c)
+	}
+
+	response, err := h.service.InitializeDefaultCategories(c.Context(), schoolID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Kategori penilaian default berhasil dibuat",
+	})
+}
+
+// ==================== Error Handlers ====================
+
+func tenantRequired(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "AUTHZ_TENANT_REQUIRED",
+			"message": "Konteks sekolah diperlukan",
+		},
+	})
+}
+
+func invalidBody(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "VAL_INVALID_FORMAT",
+			"message": "Format data tidak valid",
+		},
+	})
+}
+
+func invalidID(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "VAL_INVALID_FORMAT",
+			"message": message,
+		},
+	})
+}
+
+func (h *SubjectHandler) handleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrSubjectNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_SUBJECT",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrAssessmentCategoryNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_ASSESSMENT_CATEGORY",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrSubjectCodeRequired), errors.Is(err, ErrSubjectNameRequired),
+		errors.Is(err, ErrCategoryCodeRequired), errors.Is(err, ErrCategoryNameRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrKKMInvalid), errors.Is(err, ErrWeightInvalid):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_VALUE",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrSubjectCodeExists), errors.Is(err, ErrCategoryCodeExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "CONFLICT_DUPLICATE_CODE",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrSubjectInUse), errors.Is(err, ErrSubjectAssigned), errors.Is(err, ErrCategoryInUse):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "CONFLICT_IN_USE",
+				"message": err.Error(),
+			},
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "INTERNAL_ERROR",
+				"message": "Terjadi kesalahan pada server",
+			},
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package grade
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+var (
+	ErrSubjectNotFound            = errors.New("mata pelajaran tidak ditemukan")
+	ErrAssessmentCategoryNotFound = errors.New("kategori penilaian tidak ditemukan")
+)
+
+// SubjectRepository defines data operations for subjects and assessment categories
+type SubjectRepository interface {
+	// Subject operations
+	CreateSubject(ctx context.Context, subject *models.Subject) error
+	FindSubjectByID(ctx context.Context, schoolID, id uint) (*models.Subject, error)
+	FindSubjectByCode(ctx context.Context, schoolID uint, code string) (*models.Subject, error)
+	FindSubjects(ctx context.Context, schoolID uint, activeOnly bool) ([]models.Subject, error)
+	UpdateSubject(ctx context.Context, subject *models.Subject) error
+	DeleteSubject(ctx context.Context, schoolID, id uint) error
+	CountSubjectGrades(ctx context.Context, subjectID uint) (int64, error)
+	CountSubjectAssignments(ctx context.Context, subjectID uint) (int64, error)
+
+	// Assessment category operations
+	CreateCategory(ctx context.Context, category *models.AssessmentCategory) error
+	FindCategoryByID(ctx context.Context, schoolID, id uint) (*models.AssessmentCategory, error)
+	FindCategoryByCode(ctx context.Context, schoolID uint, code string) (*models.AssessmentCategory, error)
+	FindCategories(ctx context.Context, schoolID uint, activeOnly bool) ([]models.AssessmentCategory, error)
+	UpdateCategory(ctx context.Context, category *models.AssessmentCategory) error
+	DeleteCategory(ctx context.Context, schoolID, id uint) error
+	CountCategoryGrades(ctx context.Context, categoryID uint) (int64, error)
+}
+
+// subjectRepository implements the SubjectRepository interface
+type subjectRepository struct {
+	db *gorm.DB
+}
+
+// NewSubjectRepository creates a new subject repository
+func NewSubjectRepository(db *gorm.DB) SubjectRepository {
+	return &subjectRepository{db: db}
+}
+
+// ==================== Subjects ====================
+
+// CreateSubject creates a new subject
+func (r *subjectRepository) CreateSubject(ctx context.Context, subject *models.Subject) error {
+	return r.db.WithContext(ctx).Create(subject).Error
+}
+
+// FindSubjectByID retrieves a subject of a school by ID
+func (r *subjectRepository) FindSubjectByID(ctx context.Context, schoolID, id uint) (*models.Subject, error) {
+	var subject models.Subject
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		First(&subject).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubjectNotFound
+		}
+		return nil, err
+	}
+	return &subject, nil
+}
+
+// FindSubjectByCode retrieves a subject of a school by code
+func (r *subjectRepository) FindSubjectByCode(ctx context.Context, schoolID uint, code string) (*models.Subject, error) {
+	var subject models.Subject
+	err := r.db.WithContext(ctx).
+		Where("school_id = ? AND LOWER(code) = LOWER(?)", schoolID, code).
+		First(&subject).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubjectNotFound
+		}
+		return nil, err
+	}
+	return &subject, nil
+}
+
+// FindSubjects retrieves the subjects of a school ordered by name
+func (r *subjectRepository) FindSubjects(ctx context.Context, schoolID uint, activeOnly bool) ([]models.Subject, error) {
+	var subjects []models.Subject
+	query := r.db.WithContext(ctx).Where("school_id = ?", schoolID)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("name ASC").Find(&subjects).Error
+	return subjects, err
+}
+
+// UpdateSubject updates a subject
+func (r *subjectRepository) UpdateSubject(ctx context.Context, subject *models.Subject) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.Subject{}).
+		Where("id = ? AND school_id = ?", subject.ID, subject.SchoolID).
+		Updates(map[string]interface{}{
+			"code":        subject.Code,
+			"name":        subject.Name,
+			"kkm":         subject.KKM,
+			"description": subject.Description,
+			"is_active":   subject.IsActive,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSubjectNotFound
+	}
+	return nil
+}
+
+// DeleteSubject deletes a subject of a school
+func (r *subjectRepository) DeleteSubject(ctx context.Context, schoolID, id uint) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		Delete(&models.Subject{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSubjectNotFound
+	}
+	return nil
+}
+
+// CountSubjectGrades counts the grades recorded for a subject
+func (r *subjectRepository) CountSubjectGrades(ctx context.Context, subjectID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Grade{}).
+		Where("subject_id = ?", subjectID).
+		Count(&count).Error
+	return count, err
+}
+
+// CountSubjectAssignments counts the teaching assignments of a subject
+func (r *subjectRepository) CountSubjectAssignments(ctx context.Context, subjectID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.TeachingAssignment{}).
+		Where("subject_id = ?", subjectID).
+		Count(&count).Error
+	return count, err
+}
+
+// ==================== Assessment Categories ====================
+
+// CreateCategory creates a new assessment category
+func (r *subjectRepository) CreateCategory(ctx context.Context, category *models.AssessmentCategory) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+// FindCategoryByID retrieves an assessment category of a school by ID
+func (r *subjectRepository) FindCategoryByID(ctx context.Context, schoolID, id uint) (*models.AssessmentCategory, error) {
+	var category models.AssessmentCategory
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		First(&category).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssessmentCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// FindCategoryByCode retrieves an assessment category of a school by code
+func (r *subjectRepository) FindCategoryByCode(ctx context.Context, schoolID uint, code string) (*models.AssessmentCategory, error) {
+	var category models.AssessmentCategory
+	err := r.db.WithContext(ctx).
+		Where("school_id = ? AND LOWER(code) = LOWER(?)", schoolID, code).
+		First(&category).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssessmentCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// FindCategories retrieves the assessment categories of a school
+func (r *subjectRepository) FindCategories(ctx context.Context, schoolID uint, activeOnly bool) ([]models.AssessmentCategory, error) {
+	var categories []models.AssessmentCategory
+	query := r.db.WithContext(ctx).Where("school_id = ?", schoolID)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("id ASC").Find(&categories).Error
+	return categories, err
+}
+
+// UpdateCategory updates an assessment category
+func (r *subjectRepository) UpdateCategory(ctx context.Context, category *models.AssessmentCategory) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.AssessmentCategory{}).
+		Where("id = ? AND school_id = ?", category.ID, category.SchoolID).
+		Updates(map[string]interface{}{
+			"code":      category.Code,
+			"name":      category.Name,
+			"weight":    category.Weight,
+			"is_active": category.IsActive,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAssessmentCategoryNotFound
+	}
+	return nil
+}
+
+// DeleteCategory deletes an assessment category of a school
+func (r *subjectRepository) DeleteCategory(ctx context.Context, schoolID, id uint) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		Delete(&models.AssessmentCategory{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAssessmentCategoryNotFound
+	}
+	return nil
+}
+
+// CountCategoryGrades counts the grades recorded in an assessment category
+func (r *subjectRepository) CountCategoryGrades(ctx context.Context, categoryID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Grade{}).
+		Where("assessment_category_id = ?", categoryID).
+		Count(&count).Error
+	return count, err
+}
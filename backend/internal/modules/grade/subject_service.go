@@ -0,0 +1,353 @@
+package grade
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+var (
+	ErrSubjectCodeRequired  = errors.New("kode mata pelajaran wajib diisi")
+	ErrSubjectNameRequired  = errors.New("nama mata pelajaran wajib diisi")
+	ErrSubjectCodeExists    = errors.New("kode mata pelajaran sudah digunakan")
+	ErrSubjectInUse         = errors.New("mata pelajaran sudah memiliki nilai, nonaktifkan saja")
+	ErrSubjectAssigned      = errors.New("mata pelajaran masih memiliki penugasan mengajar")
+	ErrSubjectInactive      = errors.New("mata pelajaran tidak aktif")
+	ErrKKMInvalid           = errors.New("KKM harus antara 0 dan 100")
+	ErrCategoryCodeRequired = errors.New("kode kategori penilaian wajib diisi")
+	ErrCategoryNameRequired = errors.New("nama kategori penilaian wajib diisi")
+	ErrCategoryCodeExists   = errors.New("kode kategori penilaian sudah digunakan")
+	ErrCategoryInUse        = errors.New("kategori penilaian sudah memiliki nilai, nonaktifkan saja")
+	ErrCategoryInactive     = errors.New("kategori penilaian tidak aktif")
+	ErrWeightInvalid        = errors.New("bobot harus lebih dari 0 dan paling besar 100")
+)
+
+// SubjectService defines the business logic for subjects and assessment categories
+type SubjectService interface {
+	// Subjects
+	CreateSubject(ctx context.Context, schoolID uint, req CreateSubjectRequest) (*SubjectResponse, error)
+	GetSubjects(ctx context.Context, schoolID uint, activeOnly bool) (*SubjectListResponse, error)
+	GetSubjectByID(ctx context.Context, schoolID, id uint) (*SubjectResponse, error)
+	UpdateSubject(ctx context.Context, schoolID, id uint, req UpdateSubjectRequest) (*SubjectResponse, error)
+	DeleteSubject(ctx context.Context, schoolID, id uint) error
+
+	// Assessment categories
+	CreateCategory(ctx context.Context, schoolID uint, req CreateAssessmentCategoryRequest) (*AssessmentCategoryResponse, error)
+	GetCategories(ctx context.Context, schoolID uint, activeOnly bool) (*AssessmentCategoryListResponse, error)
+	UpdateCategory(ctx context.Context, schoolID, id uint, req UpdateAssessmentCategoryRequest) (*AssessmentCategoryResponse, error)
+	DeleteCategory(ctx context.Context, schoolID, id uint) error
+	InitializeDefaultCategories(ctx context.Context, schoolID uint) (*AssessmentCategoryListResponse, error)
+}
+
+// subjectService implements the SubjectService interface
+type subjectService struct {
+	repo SubjectRepository
+}
+
+// NewSubjectService creates a new subject service
+func NewSubjectService(repo SubjectRepository) SubjectService {
+	return &subjectService{repo: repo}
+}
+
+// ==================== Subjects ====================
+
+// CreateSubject creates a new subject
+func (s *subjectService) CreateSubject(ctx context.Context, schoolID uint, req CreateSubjectRequest) (*SubjectResponse, error) {
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		return nil, ErrSubjectCodeRequired
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, ErrSubjectNameRequired
+	}
+
+	kkm := float64(models.DefaultKKM)
+	if req.KKM != nil {
+		kkm = *req.KKM
+	}
+	if kkm < 0 || kkm > 100 {
+		return nil, ErrKKMInvalid
+	}
+
+	if err := s.ensureSubjectCodeAvailable(ctx, schoolID, code, 0); err != nil {
+		return nil, err
+	}
+
+	subject := &models.Subject{
+		SchoolID:    schoolID,
+		Code:        code,
+		Name:        strings.TrimSpace(req.Name),
+		KKM:         kkm,
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if err := s.repo.CreateSubject(ctx, subject); err != nil {
+		return nil, err
+	}
+
+	return toSubjectResponse(subject), nil
+}
+
+// GetSubjects retrieves the subjects of a school
+func (s *subjectService) GetSubjects(ctx context.Context, schoolID uint, activeOnly bool) (*SubjectListResponse, error) {
+	subjects, err := s.repo.FindSubjects(ctx, schoolID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]SubjectResponse, len(subjects))
+	for i := range subjects {
+		responses[i] = *toSubjectResponse(&subjects[i])
+	}
+	return &SubjectListResponse{Subjects: responses}, nil
+}
+
+// GetSubjectByID retrieves a subject by ID
+func (s *subjectService) GetSubjectByID(ctx context.Context, schoolID, id uint) (*SubjectResponse, error) {
+	subject, err := s.repo.FindSubjectByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toSubjectResponse(subject), nil
+}
+
+// UpdateSubject updates a subject
+// A new KKM applies to every summary computed afterwards, including past semesters.
+func (s *subjectService) UpdateSubject(ctx context.Context, schoolID, id uint, req UpdateSubjectRequest) (*SubjectResponse, error) {
+	subject, err := s.repo.FindSubjectByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if code := strings.TrimSpace(req.Code); code != "" && code != subject.Code {
+		if err := s.ensureSubjectCodeAvailable(ctx, schoolID, code, subject.ID); err != nil {
+			return nil, err
+		}
+		subject.Code = code
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		subject.Name = name
+	}
+	if req.KKM != nil {
+		if *req.KKM < 0 || *req.KKM > 100 {
+			return nil, ErrKKMInvalid
+		}
+		subject.KKM = *req.KKM
+	}
+	if req.Description != nil {
+		subject.Description = *req.Description
+	}
+	if req.IsActive != nil {
+		subject.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.UpdateSubject(ctx, subject); err != nil {
+		return nil, err
+	}
+	return toSubjectResponse(subject), nil
+}
+
+// DeleteSubject deletes a subject without grades
+// Subjects with grades must be deactivated instead, so report cards keep their subjects.
+func (s *subjectService) DeleteSubject(ctx context.Context, schoolID, id uint) error {
+	subject, err := s.repo.FindSubjectByID(ctx, schoolID, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.repo.CountSubjectGrades(ctx, subject.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSubjectInUse
+	}
+
+	assignments, err := s.repo.CountSubjectAssignments(ctx, subject.ID)
+	if err != nil {
+		return err
+	}
+	if assignments > 0 {
+		return ErrSubjectAssigned
+	}
+
+	return s.repo.DeleteSubject(ctx, schoolID, subject.ID)
+}
+
+// ensureSubjectCodeAvailable checks that no other subject of the school uses the code
+func (s *subjectService) ensureSubjectCodeAvailable(ctx context.Context, schoolID uint, code string, exceptID uint) error {
+	existing, err := s.repo.FindSubjectByCode(ctx, schoolID, code)
+	if err != nil {
+		if errors.Is(err, ErrSubjectNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != exceptID {
+		return ErrSubjectCodeExists
+	}
+	return nil
+}
+
+// ==================== Assessment Categories ====================
+
+// CreateCategory creates a new assessment category
+func (s *subjectService) CreateCategory(ctx context.Context, schoolID uint, req CreateAssessmentCategoryRequest) (*AssessmentCategoryResponse, error) {
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if code == "" {
+		return nil, ErrCategoryCodeRequired
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, ErrCategoryNameRequired
+	}
+	if req.Weight <= 0 || req.Weight > 100 {
+		return nil, ErrWeightInvalid
+	}
+
+	if err := s.ensureCategoryCodeAvailable(ctx, schoolID, code, 0); err != nil {
+		return nil, err
+	}
+
+	category := &models.AssessmentCategory{
+		SchoolID: schoolID,
+		Code:     code,
+		Name:     strings.TrimSpace(req.Name),
+		Weight:   req.Weight,
+		IsActive: true,
+	}
+	if err := s.repo.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return toAssessmentCategoryResponse(category), nil
+}
+
+// GetCategories retrieves the assessment categories of a school
+func (s *subjectService) GetCategories(ctx context.Context, schoolID uint, activeOnly bool) (*AssessmentCategoryListResponse, error) {
+	categories, err := s.repo.FindCategories(ctx, schoolID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]AssessmentCategoryResponse, len(categories))
+	for i := range categories {
+		responses[i] = *toAssessmentCategoryResponse(&categories[i])
+	}
+	return &AssessmentCategoryListResponse{Categories: responses}, nil
+}
+
+// UpdateCategory updates an assessment category
+func (s *subjectService) UpdateCategory(ctx context.Context, schoolID, id uint, req UpdateAssessmentCategoryRequest) (*AssessmentCategoryResponse, error) {
+	category, err := s.repo.FindCategoryByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if code := strings.ToLower(strings.TrimSpace(req.Code)); code != "" && code != category.Code {
+		if err := s.ensureCategoryCodeAvailable(ctx, schoolID, code, category.ID); err != nil {
+			return nil, err
+		}
+		category.Code = code
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		category.Name = name
+	}
+	if req.Weight != nil {
+		if *req.Weight <= 0 || *req.Weight > 100 {
+			return nil, ErrWeightInvalid
+		}
+		category.Weight = *req.Weight
+	}
+	if req.IsActive != nil {
+		category.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return toAssessmentCategoryResponse(category), nil
+}
+
+// DeleteCategory deletes an assessment category without grades
+func (s *subjectService) DeleteCategory(ctx context.Context, schoolID, id uint) error {
+	category, err := s.repo.FindCategoryByID(ctx, schoolID, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.repo.CountCategoryGrades(ctx, category.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCategoryInUse
+	}
+
+	return s.repo.DeleteCategory(ctx, schoolID, category.ID)
+}
+
+// InitializeDefaultCategories creates the default assessment categories for a school
+// Schools that already have categories are left unchanged.
+func (s *subjectService) InitializeDefaultCategories(ctx context.Context, schoolID uint) (*AssessmentCategoryListResponse, error) {
+	existing, err := s.repo.FindCategories(ctx, schoolID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(existing) == 0 {
+		defaults := models.DefaultAssessmentCategories(schoolID)
+		for i := range defaults {
+			if err := s.repo.CreateCategory(ctx, &defaults[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s.GetCategories(ctx, schoolID, false)
+}
+
+// ensureCategoryCodeAvailable checks that no other category of the school uses the code
+func (s *subjectService) ensureCategoryCodeAvailable(ctx context.Context, schoolID uint, code string, exceptID uint) error {
+	existing, err := s.repo.FindCategoryByCode(ctx, schoolID, code)
+	if err != nil {
+		if errors.Is(err, ErrAssessmentCategoryNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != exceptID {
+		return ErrCategoryCodeExists
+	}
+	return nil
+}
+
+// ==================== Response Converters ====================
+
+func toSubjectResponse(s *models.Subject) *SubjectResponse {
+	return &SubjectResponse{
+		ID:          s.ID,
+		SchoolID:    s.SchoolID,
+		Code:        s.Code,
+		Name:        s.Name,
+		KKM:         s.KKM,
+		Description: s.Description,
+		IsActive:    s.IsActive,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+func toAssessmentCategoryResponse(c *models.AssessmentCategory) *AssessmentCategoryResponse {
+	return &AssessmentCategoryResponse{
+		ID:        c.ID,
+		SchoolID:  c.SchoolID,
+		Code:      c.Code,
+		Name:      c.Name,
+		Weight:    c.Weight,
+		IsActive:  c.IsActive,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
@@ -91,16 +91,22 @@ type HomeroomStatsResponse struct {
 
 // GradeResponse represents a grade in responses (for dashboard)
 type GradeResponse struct {
-	ID          uint      `json:"id"`
-	StudentID   uint      `json:"studentId"`
-	StudentName string    `json:"studentName"`
-	StudentNIS  string    `json:"studentNis"`
-	Title       string    `json:"title"`
-	Score       float64   `json:"score"`
-	Description string    `json:"description,omitempty"`
-	CreatedBy   uint      `json:"createdBy"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID                     uint      `json:"id"`
+	StudentID              uint      `json:"studentId"`
+	StudentName            string    `json:"studentName"`
+	StudentNIS             string    `json:"studentNis"`
+	SubjectID              *uint     `json:"subjectId,omitempty"`
+	SubjectName            string    `json:"subjectName,omitempty"`
+	AssessmentCategoryID   *uint     `json:"assessmentCategoryId,omitempty"`
+	AssessmentCategoryName string    `json:"assessmentCategoryName,omitempty"`
+	AcademicYear           string    `json:"academicYear,omitempty"`
+	Semester               int       `json:"semester,omitempty"`
+	Title                  string    `json:"title"`
+	Score                  float64   `json:"score"`
+	Description            string    `json:"description,omitempty"`
+	CreatedBy              uint      `json:"createdBy"`
+	CreatedAt              time.Time `json:"createdAt"`
+	UpdatedAt              time.Time `json:"updatedAt"`
 }
 
 // GradeListResponse represents a paginated list of grades
@@ -111,24 +117,30 @@ type GradeListResponse struct {
 
 // CreateGradeRequest represents the request to create a grade
 type CreateGradeRequest struct {
-	StudentID   uint    `json:"studentId" validate:"required"`
-	Title       string  `json:"title" validate:"required"`
-	Score       float64 `json:"score" validate:"required,min=0,max=100"`
-	Description string  `json:"description,omitempty"`
+	StudentID            uint    `json:"studentId" validate:"required"`
+	SubjectID            *uint   `json:"subjectId,omitempty"`
+	AssessmentCategoryID *uint   `json:"assessmentCategoryId,omitempty"`
+	Title                string  `json:"title" validate:"required"`
+	Score                float64 `json:"score" validate:"required,min=0,max=100"`
+	Description          string  `json:"description,omitempty"`
 }
 
 // UpdateGradeRequest represents the request to update a grade
 type UpdateGradeRequest struct {
-	Title       string  `json:"title,omitempty"`
-	Score       float64 `json:"score,omitempty"`
-	Description string  `json:"description,omitempty"`
+	SubjectID            *uint   `json:"subjectId,omitempty"`
+	AssessmentCategoryID *uint   `json:"assessmentCategoryId,omitempty"`
+	Title                string  `json:"title,omitempty"`
+	Score                float64 `json:"score,omitempty"`
+	Description          string  `json:"description,omitempty"`
 }
 
 // BatchGradeRequest represents the request to create multiple grades
 type BatchGradeRequest struct {
-	Title       string       `json:"title" validate:"required"`
-	Description string       `json:"description,omitempty"`
-	Grades      []GradeEntry `json:"grades" validate:"required,min=1"`
+	SubjectID            *uint        `json:"subjectId,omitempty"`
+	AssessmentCategoryID *uint        `json:"assessmentCategoryId,omitempty"`
+	Title                string       `json:"title" validate:"required"`
+	Description          string       `json:"description,omitempty"`
+	Grades               []GradeEntry `json:"grades" validate:"required,min=1"`
 }
 
 // GradeEntry represents a single grade entry in batch request
@@ -6,6 +6,8 @@ import (
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/shared/gradebook"
 )
 
 // Handler handles HTTP requests for Homeroom Note management
@@ -61,6 +63,7 @@ func (h *Handler) RegisterRoutesWithoutGroup(router fiber.Router) {
 
 	// Student grades
 	router.Get("/students/:studentId/grades", h.GetStudentGrades)
+	router.Get("/students/:studentId/grades/summary", h.GetStudentGradeSummary)
 
 	// Note CRUD
 	router.Get("/notes", h.GetNotes)
@@ -576,6 +579,47 @@ func (h *Handler) GetStudentGrades(c *fiber.Ctx) error {
 	})
 }
 
+// GetStudentGradeSummary handles getting the report-card summary of a student
+// @Summary Get student report-card summary
+// @Description Get the weighted average per subject and KKM pass status of a student in wali kelas's class
+// @Tags Homeroom
+// @Produce json
+// @Param studentId path int true "Student ID"
+// @Param academicYear query string false "Academic year, defaults to the school's current one"
+// @Param semester query int false "Semester (1 or 2), defaults to the school's current one"
+// @Success 200 {object} gradebook.Summary
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/homeroom/students/{studentId}/grades/summary [get]
+func (h *Handler) GetStudentGradeSummary(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	studentID, err := strconv.ParseUint(c.Params("studentId"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "student")
+	}
+
+	period := gradebook.Period{
+		AcademicYear: c.Query("academicYear"),
+		Semester:     c.QueryInt("semester"),
+	}
+
+	response, err := h.service.GetStudentGradeSummary(c.Context(), userID, uint(studentID), period)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
 // ==================== Note Handlers ====================
 
 // CreateNote handles creating a new homeroom note
@@ -1049,6 +1093,54 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 				"message": "Tidak memiliki izin untuk melakukan aksi ini",
 			},
 		})
+	case errors.Is(err, ErrSubjectNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_SUBJECT",
+				"message": "Mata pelajaran tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrCategoryNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_ASSESSMENT_CATEGORY",
+				"message": "Kategori penilaian tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrSubjectRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Mata pelajaran wajib diisi jika kategori penilaian diisi",
+			},
+		})
+	case errors.Is(err, ErrCategoryRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Kategori penilaian wajib diisi jika mata pelajaran diisi",
+			},
+		})
+	case errors.Is(err, ErrSubjectInactive):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_VALUE",
+				"message": "Mata pelajaran tidak aktif",
+			},
+		})
+	case errors.Is(err, ErrCategoryInactive):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_VALUE",
+				"message": "Kategori penilaian tidak aktif",
+			},
+		})
 	case errors.Is(err, ErrAttendanceNotFound):
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
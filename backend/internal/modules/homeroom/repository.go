@@ -8,6 +8,8 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/enrollment"
+	"github.com/school-management/backend/internal/shared/outbox"
 )
 
 var (
@@ -19,7 +21,7 @@ var (
 // Repository defines the interface for Homeroom Note data operations
 type Repository interface {
 	// Note operations
-	Create(ctx context.Context, note *models.HomeroomNote) error
+	Create(ctx context.Context, note *models.HomeroomNote, event *outbox.Event) error
 	FindByID(ctx context.Context, id uint) (*models.HomeroomNote, error)
 	FindByStudent(ctx context.Context, studentID uint) ([]models.HomeroomNote, error)
 	FindAll(ctx context.Context, schoolID uint, filter NoteFilter) ([]models.HomeroomNote, int64, error)
@@ -47,8 +49,13 @@ func NewRepository(db *gorm.DB) Repository {
 
 // Create creates a new homeroom note
 // Requirements: 11.1 - WHEN a Wali_Kelas creates a note
-func (r *repository) Create(ctx context.Context, note *models.HomeroomNote) error {
-	return r.db.WithContext(ctx).Create(note).Error
+func (r *repository) Create(ctx context.Context, note *models.HomeroomNote, event *outbox.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(note).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, note.ID, event)
+	})
 }
 
 // FindByID retrieves a homeroom note by ID
@@ -102,7 +109,7 @@ func (r *repository) FindAll(ctx context.Context, schoolID uint, filter NoteFilt
 		countQuery = countQuery.Where("homeroom_notes.student_id = ?", *filter.StudentID)
 	}
 	if filter.ClassID != nil {
-		countQuery = countQuery.Where("students.class_id = ?", *filter.ClassID)
+		countQuery = countQuery.Where(enrollment.ClassAt("homeroom_notes", "homeroom_notes.created_at::date")+" = ?", *filter.ClassID)
 	}
 	if filter.TeacherID != nil {
 		countQuery = countQuery.Where("homeroom_notes.teacher_id = ?", *filter.TeacherID)
@@ -147,7 +154,7 @@ func (r *repository) FindAll(ctx context.Context, schoolID uint, filter NoteFilt
 		idQuery = idQuery.Where("homeroom_notes.student_id = ?", *filter.StudentID)
 	}
 	if filter.ClassID != nil {
-		idQuery = idQuery.Where("students.class_id = ?", *filter.ClassID)
+		idQuery = idQuery.Where(enrollment.ClassAt("homeroom_notes", "homeroom_notes.created_at::date")+" = ?", *filter.ClassID)
 	}
 	if filter.TeacherID != nil {
 		idQuery = idQuery.Where("homeroom_notes.teacher_id = ?", *filter.TeacherID)
@@ -302,7 +309,7 @@ func (r *repository) GetClassNotes(ctx context.Context, classID uint, filter Not
 	query := r.db.WithContext(ctx).
 		Model(&models.HomeroomNote{}).
 		Joins("JOIN students ON students.id = homeroom_notes.student_id").
-		Where("students.class_id = ?", classID)
+		Where(enrollment.ClassAt("homeroom_notes", "homeroom_notes.created_at::date")+" = ?", classID)
 
 	// Apply date filters
 	if filter.StartDate != nil {
@@ -338,7 +345,7 @@ func (r *repository) GetClassNotes(ctx context.Context, classID uint, filter Not
 		Preload("Student.Class").
 		Preload("Teacher").
 		Joins("JOIN students ON students.id = homeroom_notes.student_id").
-		Where("students.class_id = ?", classID).
+		Where(enrollment.ClassAt("homeroom_notes", "homeroom_notes.created_at::date")+" = ?", classID).
 		Order("homeroom_notes.created_at DESC").
 		Offset(offset).
 		Limit(filter.PageSize).
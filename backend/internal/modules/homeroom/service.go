@@ -3,11 +3,16 @@ package homeroom
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/calendar"
+	"github.com/school-management/backend/internal/shared/gradebook"
+	"github.com/school-management/backend/internal/shared/enrollment"
+	"github.com/school-management/backend/internal/shared/outbox"
 )
 
 var (
@@ -23,6 +28,12 @@ var (
 	ErrAttendanceNotFound     = errors.New("data absensi tidak ditemukan")
 	ErrAttendanceAlreadyExists = errors.New("data absensi sudah ada")
 	ErrInvalidStatus          = errors.New("status absensi tidak valid")
+	ErrSubjectNotFound        = errors.New("mata pelajaran tidak ditemukan")
+	ErrCategoryNotFound       = errors.New("kategori penilaian tidak ditemukan")
+	ErrSubjectRequired        = errors.New("mata pelajaran wajib diisi bersama kategori penilaian")
+	ErrCategoryRequired       = errors.New("kategori penilaian wajib diisi bersama mata pelajaran")
+	ErrSubjectInactive        = errors.New("mata pelajaran tidak aktif")
+	ErrCategoryInactive       = errors.New("kategori penilaian tidak aktif")
 )
 
 // Service defines the interface for Homeroom Note business logic
@@ -43,6 +54,7 @@ type Service interface {
 	GetStudentGrades(ctx context.Context, teacherID, studentID uint, page, pageSize int) (*GradeListResponse, error)
 	UpdateGrade(ctx context.Context, gradeID uint, req UpdateGradeRequest) (*GradeResponse, error)
 	DeleteGrade(ctx context.Context, gradeID uint) error
+	GetStudentGradeSummary(ctx context.Context, teacherID, studentID uint, period gradebook.Period) (*gradebook.Summary, error)
 
 	// Teacher validation
 	ValidateTeacherAccess(ctx context.Context, teacherID, studentID uint) error
@@ -109,7 +121,21 @@ func (s *service) CreateNote(ctx context.Context, schoolID, teacherID uint, req
 		Content:   req.Content,
 	}
 
-	if err := s.repo.Create(ctx, note); err != nil {
+	// Requirements: 11.2 - Parent notification is stored in the outbox with the note
+	event := outbox.NewEvent(outbox.EventHomeroomNoteCreated, outbox.Payload{
+		SchoolID:  schoolID,
+		StudentID: student.ID,
+		Notification: outbox.ParentNotification(
+			models.NotificationTypeHomeroomNote,
+			"Catatan Wali Kelas",
+			fmt.Sprintf("Wali kelas menambahkan catatan baru untuk %s.", student.Name),
+			map[string]interface{}{
+				"student_id": fmt.Sprintf("%d", student.ID),
+			},
+		),
+	})
+
+	if err := s.repo.Create(ctx, note, event); err != nil {
 		return nil, err
 	}
 
@@ -119,9 +145,6 @@ func (s *service) CreateNote(ctx context.Context, schoolID, teacherID uint, req
 		return nil, err
 	}
 
-	// TODO: Trigger notification to parent (async)
-	// Requirements: 11.2 - WHEN a note is saved, THE System SHALL trigger notification to the parent
-
 	return toNoteResponse(note), nil
 }
 
@@ -239,7 +262,7 @@ func (s *service) ValidateTeacherAccess(ctx context.Context, teacherID, studentI
 func (s *service) GetTeacherClassID(ctx context.Context, teacherID uint) (*uint, error) {
 	var class models.Class
 	err := s.db.WithContext(ctx).
-		Where("homeroom_teacher_id = ?", teacherID).
+		Where("homeroom_teacher_id = ? AND is_archived = ?", teacherID, false).
 		First(&class).Error
 
 	if err != nil {
@@ -360,7 +383,7 @@ func (s *service) GetHomeroomStats(ctx context.Context, schoolID, teacherID uint
 	s.db.WithContext(ctx).
 		Model(&models.Attendance{}).
 		Joins("JOIN students ON students.id = attendances.student_id").
-		Where("students.class_id = ? AND attendances.date = ? AND attendances.status = ?", *classID, today, "on_time").
+		Where(enrollment.ClassAt("attendances", "attendances.date")+" = ? AND attendances.date = ? AND attendances.status = ?", *classID, today, "on_time").
 		Count(&onTimeCount)
 	todayAttendance.Present = onTimeCount
 
@@ -369,7 +392,7 @@ func (s *service) GetHomeroomStats(ctx context.Context, schoolID, teacherID uint
 	s.db.WithContext(ctx).
 		Model(&models.Attendance{}).
 		Joins("JOIN students ON students.id = attendances.student_id").
-		Where("students.class_id = ? AND attendances.date = ? AND attendances.status IN ?", *classID, today, []string{"late", "very_late"}).
+		Where(enrollment.ClassAt("attendances", "attendances.date")+" = ? AND attendances.date = ? AND attendances.status IN ?", *classID, today, []string{"late", "very_late"}).
 		Count(&lateCount)
 	todayAttendance.Late = lateCount
 
@@ -381,7 +404,7 @@ func (s *service) GetHomeroomStats(ctx context.Context, schoolID, teacherID uint
 	s.db.WithContext(ctx).
 		Preload("Student").
 		Joins("JOIN students ON students.id = grades.student_id").
-		Where("students.class_id = ?", *classID).
+		Where(enrollment.ClassAt("grades", "grades.created_at::date")+" = ?", *classID).
 		Order("grades.created_at DESC").
 		Limit(5).
 		Find(&grades)
@@ -407,7 +430,7 @@ func (s *service) GetHomeroomStats(ctx context.Context, schoolID, teacherID uint
 		Preload("Student").
 		Preload("Teacher").
 		Joins("JOIN students ON students.id = homeroom_notes.student_id").
-		Where("students.class_id = ?", *classID).
+		Where(enrollment.ClassAt("homeroom_notes", "homeroom_notes.created_at::date")+" = ?", *classID).
 		Order("homeroom_notes.created_at DESC").
 		Limit(5).
 		Find(&notes)
@@ -433,7 +456,7 @@ func (s *service) GetMyClass(ctx context.Context, teacherID uint) (*ClassInfoRes
 	var class models.Class
 	if err := s.db.WithContext(ctx).
 		Preload("HomeroomTeacher").
-		Where("homeroom_teacher_id = ?", teacherID).
+		Where("homeroom_teacher_id = ? AND is_archived = ?", teacherID, false).
 		First(&class).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrNoClassAssigned
@@ -537,10 +560,16 @@ func (s *service) GetClassAttendance(ctx context.Context, teacherID uint, date s
 		return nil, ErrNoClassAssigned
 	}
 
-	// Get all students in class
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, errors.New("format tanggal tidak valid")
+	}
+
+	// Get the active students who were in the class on the date
 	var students []models.Student
 	s.db.WithContext(ctx).
-		Where("class_id = ? AND is_active = ?", *classID, true).
+		Scopes(enrollment.EnrolledOn(*classID, day)).
+		Where("is_active = ?", true).
 		Order("name ASC").
 		Find(&students)
 
@@ -548,8 +577,7 @@ func (s *service) GetClassAttendance(ctx context.Context, teacherID uint, date s
 	var attendances []models.Attendance
 	s.db.WithContext(ctx).
 		Preload("Student").
-		Joins("JOIN students ON students.id = attendances.student_id").
-		Where("students.class_id = ? AND attendances.date = ?", *classID, date).
+		Where(enrollment.ClassAt("attendances", "attendances.date")+" = ? AND attendances.date = ?", *classID, date).
 		Find(&attendances)
 
 	// Create a map of student_id -> attendance
@@ -630,15 +658,21 @@ func (s *service) CreateGrade(ctx context.Context, schoolID, teacherID uint, req
 		return nil, err
 	}
 
+	if err := s.validateAssessment(ctx, schoolID, req.SubjectID, req.AssessmentCategoryID); err != nil {
+		return nil, err
+	}
+
 	grade := &models.Grade{
-		StudentID:   req.StudentID,
-		Title:       req.Title,
-		Score:       req.Score,
-		Description: req.Description,
-		CreatedBy:   teacherID,
+		StudentID:            req.StudentID,
+		SubjectID:            req.SubjectID,
+		AssessmentCategoryID: req.AssessmentCategoryID,
+		Title:                req.Title,
+		Score:                req.Score,
+		Description:          req.Description,
+		CreatedBy:            teacherID,
 	}
 
-	if err := s.db.WithContext(ctx).Create(grade).Error; err != nil {
+	if err := s.createGradeWithEvent(ctx, student, grade); err != nil {
 		return nil, err
 	}
 
@@ -646,6 +680,8 @@ func (s *service) CreateGrade(ctx context.Context, schoolID, teacherID uint, req
 	if err := s.db.WithContext(ctx).
 		Preload("Student").
 		Preload("Student.Class").
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
 		First(grade, grade.ID).Error; err != nil {
 		return nil, err
@@ -672,6 +708,10 @@ func (s *service) CreateBatchGrades(ctx context.Context, schoolID, teacherID uin
 		return nil, ErrNoClassAssigned
 	}
 
+	if err := s.validateAssessment(ctx, schoolID, req.SubjectID, req.AssessmentCategoryID); err != nil {
+		return nil, err
+	}
+
 	var responses []GradeResponse
 
 	for _, entry := range req.Grades {
@@ -692,14 +732,16 @@ func (s *service) CreateBatchGrades(ctx context.Context, schoolID, teacherID uin
 		}
 
 		grade := &models.Grade{
-			StudentID:   entry.StudentID,
-			Title:       req.Title,
-			Score:       entry.Score,
-			Description: req.Description,
-			CreatedBy:   teacherID,
+			StudentID:            entry.StudentID,
+			SubjectID:            req.SubjectID,
+			AssessmentCategoryID: req.AssessmentCategoryID,
+			Title:                req.Title,
+			Score:                entry.Score,
+			Description:          req.Description,
+			CreatedBy:            teacherID,
 		}
 
-		if err := s.db.WithContext(ctx).Create(grade).Error; err != nil {
+		if err := s.createGradeWithEvent(ctx, student, grade); err != nil {
 			continue
 		}
 
@@ -707,6 +749,8 @@ func (s *service) CreateBatchGrades(ctx context.Context, schoolID, teacherID uin
 		if err := s.db.WithContext(ctx).
 			Preload("Student").
 			Preload("Student.Class").
+			Preload("Subject").
+			Preload("AssessmentCategory").
 			Preload("Creator").
 			First(grade, grade.ID).Error; err != nil {
 			continue
@@ -718,12 +762,47 @@ func (s *service) CreateBatchGrades(ctx context.Context, schoolID, teacherID uin
 	return responses, nil
 }
 
+// createGradeWithEvent stores a grade and its parent notification event in one transaction
+// Requirements: 10.3 - WHEN a grade is recorded, THE System SHALL optionally trigger notification to the parent
+func (s *service) createGradeWithEvent(ctx context.Context, student *models.Student, grade *models.Grade) error {
+	// Grades are recorded in the school's current academic year and semester
+	period, err := gradebook.CurrentPeriod(ctx, s.db, student.SchoolID)
+	if err != nil {
+		return err
+	}
+	grade.AcademicYear = period.AcademicYear
+	grade.Semester = period.Semester
+
+	event := outbox.NewEvent(outbox.EventGradeCreated, outbox.Payload{
+		SchoolID:  student.SchoolID,
+		StudentID: student.ID,
+		Notification: outbox.ParentNotification(
+			models.NotificationTypeGrade,
+			"Nilai Baru",
+			fmt.Sprintf("Nilai %s untuk %s: %.1f", grade.Title, student.Name, grade.Score),
+			map[string]interface{}{
+				"student_id": fmt.Sprintf("%d", student.ID),
+				"score":      fmt.Sprintf("%.1f", grade.Score),
+			},
+		),
+	})
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(grade).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, grade.ID, event)
+	})
+}
+
 // GetGradeByID retrieves a grade by ID
 func (s *service) GetGradeByID(ctx context.Context, id uint) (*GradeResponse, error) {
 	var grade models.Grade
 	if err := s.db.WithContext(ctx).
 		Preload("Student").
 		Preload("Student.Class").
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
 		First(&grade, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -757,7 +836,7 @@ func (s *service) GetClassGrades(ctx context.Context, schoolID, teacherID uint,
 	s.db.WithContext(ctx).
 		Model(&models.Grade{}).
 		Joins("JOIN students ON students.id = grades.student_id").
-		Where("students.class_id = ?", *classID).
+		Where(enrollment.ClassAt("grades", "grades.created_at::date")+" = ?", *classID).
 		Count(&total)
 
 	// Get grades
@@ -766,9 +845,11 @@ func (s *service) GetClassGrades(ctx context.Context, schoolID, teacherID uint,
 	s.db.WithContext(ctx).
 		Preload("Student").
 		Preload("Student.Class").
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
 		Joins("JOIN students ON students.id = grades.student_id").
-		Where("students.class_id = ?", *classID).
+		Where(enrollment.ClassAt("grades", "grades.created_at::date")+" = ?", *classID).
 		Order("grades.created_at DESC").
 		Offset(offset).
 		Limit(pageSize).
@@ -822,6 +903,8 @@ func (s *service) GetStudentGrades(ctx context.Context, teacherID, studentID uin
 	s.db.WithContext(ctx).
 		Preload("Student").
 		Preload("Student.Class").
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
 		Where("student_id = ?", studentID).
 		Order("created_at DESC").
@@ -869,6 +952,25 @@ func (s *service) UpdateGrade(ctx context.Context, gradeID uint, req UpdateGrade
 	if req.Description != "" {
 		grade.Description = req.Description
 	}
+	if req.SubjectID != nil || req.AssessmentCategoryID != nil {
+		subjectID, categoryID := grade.SubjectID, grade.AssessmentCategoryID
+		if req.SubjectID != nil {
+			subjectID = req.SubjectID
+		}
+		if req.AssessmentCategoryID != nil {
+			categoryID = req.AssessmentCategoryID
+		}
+
+		student, err := s.repo.FindStudentByID(ctx, grade.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.validateAssessment(ctx, student.SchoolID, subjectID, categoryID); err != nil {
+			return nil, err
+		}
+		grade.SubjectID = subjectID
+		grade.AssessmentCategoryID = categoryID
+	}
 
 	if err := s.db.WithContext(ctx).Save(&grade).Error; err != nil {
 		return nil, err
@@ -878,6 +980,8 @@ func (s *service) UpdateGrade(ctx context.Context, gradeID uint, req UpdateGrade
 	if err := s.db.WithContext(ctx).
 		Preload("Student").
 		Preload("Student.Class").
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
 		First(&grade, gradeID).Error; err != nil {
 		return nil, err
@@ -915,10 +1019,82 @@ func toGradeResponse(g *models.Grade) *GradeResponse {
 		response.StudentName = g.Student.Name
 		response.StudentNIS = g.Student.NIS
 	}
+	response.SubjectID = g.SubjectID
+	if g.Subject != nil {
+		response.SubjectName = g.Subject.Name
+	}
+	response.AssessmentCategoryID = g.AssessmentCategoryID
+	if g.AssessmentCategory != nil {
+		response.AssessmentCategoryName = g.AssessmentCategory.Name
+	}
+	response.AcademicYear = g.AcademicYear
+	response.Semester = g.Semester
 
 	return response
 }
 
+// GetStudentGradeSummary computes the report-card averages of a student in wali kelas's class
+// An empty period defaults to the school's current academic year and semester.
+func (s *service) GetStudentGradeSummary(ctx context.Context, teacherID, studentID uint, period gradebook.Period) (*gradebook.Summary, error) {
+	if err := s.ValidateTeacherAccess(ctx, teacherID, studentID); err != nil {
+		return nil, err
+	}
+
+	student, err := s.repo.FindStudentByID(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err = gradebook.ResolvePeriod(ctx, s.db, student.SchoolID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return gradebook.StudentSummary(ctx, s.db, studentID, period)
+}
+
+// validateAssessment checks the optional subject and assessment category of a grade
+// Both must be given together and be active in the school.
+func (s *service) validateAssessment(ctx context.Context, schoolID uint, subjectID, categoryID *uint) error {
+	if subjectID == nil && categoryID == nil {
+		return nil
+	}
+	if subjectID == nil {
+		return ErrSubjectRequired
+	}
+	if categoryID == nil {
+		return ErrCategoryRequired
+	}
+
+	var subject models.Subject
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", *subjectID, schoolID).
+		First(&subject).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSubjectNotFound
+		}
+		return err
+	}
+	if !subject.IsActive {
+		return ErrSubjectInactive
+	}
+
+	var category models.AssessmentCategory
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", *categoryID, schoolID).
+		First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+	if !category.IsActive {
+		return ErrCategoryInactive
+	}
+
+	return nil
+}
+
 // ==================== Manual Attendance Methods ====================
 
 // RecordManualAttendance records manual attendance for a student
@@ -1132,29 +1308,23 @@ func (s *service) GetActiveSchedules(ctx context.Context, schoolID uint, date st
 		return nil, errors.New("format tanggal tidak valid")
 	}
 
-	// Get all active schedules for the school
-	var schedules []models.AttendanceSchedule
-	if err := s.db.WithContext(ctx).
-		Where("school_id = ? AND is_active = ?", schoolID, true).
-		Order("start_time ASC").
-		Find(&schedules).Error; err != nil {
+	// Get the schedules that apply on this date (none on holidays, date overrides applied)
+	schedules, err := calendar.SchedulesForDate(ctx, s.db, schoolID, parsedDate)
+	if err != nil {
 		return nil, err
 	}
 
-	// Filter schedules that are active on the given day
 	var result []ScheduleResponse
 	for _, schedule := range schedules {
-		if schedule.IsActiveOnDay(parsedDate.Weekday()) {
-			result = append(result, ScheduleResponse{
-				ID:                schedule.ID,
-				Name:              schedule.Name,
-				StartTime:         schedule.StartTime,
-				EndTime:           schedule.EndTime,
-				LateThreshold:     schedule.LateThreshold,
-				VeryLateThreshold: schedule.VeryLateThreshold,
-				IsDefault:         schedule.IsDefault,
-			})
-		}
+		result = append(result, ScheduleResponse{
+			ID:                schedule.ID,
+			Name:              schedule.Name,
+			StartTime:         schedule.StartTime,
+			EndTime:           schedule.EndTime,
+			LateThreshold:     schedule.LateThreshold,
+			VeryLateThreshold: schedule.VeryLateThreshold,
+			IsDefault:         schedule.IsDefault,
+		})
 	}
 
 	// If no schedules found for the day, return empty array
@@ -6,8 +6,10 @@ import (
 	"mime/multipart"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/enrollment"
 	"github.com/xuri/excelize/v2"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -153,6 +155,7 @@ func (s *service) ImportStudents(ctx context.Context, schoolID uint, file multip
 	// Process within transaction
 	// Requirements: 5.6 - Process import within database transaction
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var enrolledIDs []uint
 		for _, row := range rows {
 			// Validate required fields
 			// Requirements: 3.1, 3.2, 3.3
@@ -231,10 +234,12 @@ func (s *service) ImportStudents(ctx context.Context, schoolID uint, file multip
 			result.SuccessCount++
 			if classID == nil {
 				result.StudentsWithoutClass++
+			} else {
+				enrolledIDs = append(enrolledIDs, student.ID)
 			}
 		}
 
-		return nil
+		return enrollment.Sync(ctx, tx, enrollment.Change{StudentIDs: enrolledIDs, Date: time.Now()})
 	})
 
 	if err != nil {
@@ -0,0 +1,80 @@
+package leave
+
+import (
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// ==================== Request DTOs ====================
+
+// SubmitLeaveRequest represents a parent's leave request (sent as multipart form with an optional "attachment" photo)
+type SubmitLeaveRequest struct {
+	Type      string `json:"type" form:"type" validate:"required"`             // sick (sakit) or excused (izin)
+	StartDate string `json:"start_date" form:"start_date" validate:"required"` // YYYY-MM-DD
+	EndDate   string `json:"end_date" form:"end_date"`                         // YYYY-MM-DD, defaults to start_date
+	Reason    string `json:"reason" form:"reason" validate:"required"`
+}
+
+// ReviewLeaveRequest represents the wali kelas's decision on a leave request
+type ReviewLeaveRequest struct {
+	Note string `json:"note"` // Shown to the parent, required when rejecting
+}
+
+// LeaveFilter represents filter options for listing leave requests
+type LeaveFilter struct {
+	ClassID   *uint
+	StudentID *uint
+	Status    *models.LeaveStatus
+	Page      int
+	PageSize  int
+}
+
+// ==================== Response DTOs ====================
+
+// LeaveResponse represents a leave request in API responses
+type LeaveResponse struct {
+	ID             uint               `json:"id"`
+	StudentID      uint               `json:"student_id"`
+	StudentName    string             `json:"student_name"`
+	StudentNIS     string             `json:"student_nis"`
+	ClassID        *uint              `json:"class_id"`
+	ClassName      string             `json:"class_name,omitempty"`
+	Type           models.LeaveType   `json:"type"`
+	StartDate      string             `json:"start_date"`
+	EndDate        string             `json:"end_date"`
+	Reason         string             `json:"reason"`
+	HasAttachment  bool               `json:"has_attachment"`
+	AttachmentName string             `json:"attachment_name,omitempty"`
+	Status         models.LeaveStatus `json:"status"`
+	SubmittedBy    uint               `json:"submitted_by"`
+	SubmitterName  string             `json:"submitter_name,omitempty"`
+	ReviewedBy     *uint              `json:"reviewed_by,omitempty"`
+	ReviewerName   string             `json:"reviewer_name,omitempty"`
+	ReviewedAt     *time.Time         `json:"reviewed_at,omitempty"`
+	ReviewNote     string             `json:"review_note,omitempty"`
+	FlaggedTaps    []FlaggedTap       `json:"flagged_taps,omitempty"` // RFID taps on days of the approved leave
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// FlaggedTap is an RFID check-in recorded on a day the student is on approved leave
+type FlaggedTap struct {
+	AttendanceID uint                    `json:"attendance_id"`
+	Date         string                  `json:"date"`
+	CheckInTime  string                  `json:"check_in_time,omitempty"`
+	Status       models.AttendanceStatus `json:"status"`
+}
+
+// LeaveListResponse represents a paginated list of leave requests
+type LeaveListResponse struct {
+	LeaveRequests []LeaveResponse `json:"leave_requests"`
+	Pagination    PaginationMeta  `json:"pagination"`
+}
+
+// PaginationMeta represents pagination metadata
+type PaginationMeta struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
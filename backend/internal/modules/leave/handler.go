@@ -0,0 +1,724 @@
+package leave
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/middleware"
+)
+
+// Handler handles HTTP requests for leave requests (pengajuan sakit/izin)
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new leave request handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the routes where staff review leave requests
+// Admin sekolah see every request; wali kelas review those of their own classes.
+func (h *Handler) RegisterRoutes(router fiber.Router) {
+	leaves := router.Group("/leave-requests", middleware.RoleMiddleware(models.RoleAdminSekolah, models.RoleWaliKelas))
+	leaves.Get("", h.GetLeaves)
+	leaves.Get("/:id", h.GetLeave)
+	leaves.Get("/:id/attachment", h.DownloadAttachment)
+	leaves.Post("/:id/approve", h.ApproveLeave)
+	leaves.Post("/:id/reject", h.RejectLeave)
+	leaves.Post("/:id/taps/:attendanceId/resolve", h.ResolveFlaggedTap)
+}
+
+// RegisterParentRoutes registers the routes where parents submit and follow leave requests
+func (h *Handler) RegisterParentRoutes(router fiber.Router) {
+	parent := router.Group("/parent")
+	parent.Post("/children/:id/leave-requests", h.SubmitLeave)
+	parent.Get("/children/:id/leave-requests", h.GetChildLeaves)
+	parent.Get("/leave-requests/:id", h.GetParentLeave)
+	parent.Get("/leave-requests/:id/attachment", h.DownloadParentAttachment)
+	parent.Post("/leave-requests/:id/cancel", h.CancelLeave)
+}
+
+// ==================== Parent Handlers ====================
+
+// SubmitLeave handles a parent submitting a leave request for a child
+// @Summary Submit leave request
+// @Description Ask sick (sakit) or excused (izin) leave for a child over a date range, optionally with a photo of a doctor's note. The wali kelas is notified.
+// @Tags Leave Requests
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Student ID"
+// @Param type formData string true "sick or excused"
+// @Param start_date formData string true "First day of leave (YYYY-MM-DD)"
+// @Param end_date formData string false "Last day of leave (YYYY-MM-DD), defaults to start_date"
+// @Param reason formData string true "Reason"
+// @Param attachment formData file false "Photo of the doctor's note (JPEG, PNG or WebP, max 5 MB)"
+// @Success 201 {object} LeaveResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/parent/children/{id}/leave-requests [post]
+func (h *Handler) SubmitLeave(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	studentID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "student")
+	}
+
+	var req SubmitLeaveRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	var attachment *Attachment
+	if fileHeader, err := c.FormFile("attachment"); err == nil {
+		if fileHeader.Size > MaxAttachmentSize {
+			return h.handleError(c, ErrAttachmentTooLarge)
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "VAL_FILE_INVALID",
+					"message": "Gagal membuka file",
+				},
+			})
+		}
+		defer file.Close()
+		attachment = &Attachment{FileName: fileHeader.Filename, Reader: file}
+	}
+
+	response, err := h.service.SubmitLeave(c.Context(), userID, uint(studentID), req, attachment)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Pengajuan izin berhasil dikirim",
+	})
+}
+
+// GetChildLeaves handles getting a child's leave requests
+// @Summary Get child leave requests
+// @Description Get the leave requests submitted for a linked child, newest first
+// @Tags Leave Requests
+// @Produce json
+// @Param id path int true "Student ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} LeaveListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/parent/children/{id}/leave-requests [get]
+func (h *Handler) GetChildLeaves(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	studentID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "student")
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size", "20"))
+
+	response, err := h.service.GetChildLeaves(c.Context(), userID, uint(studentID), page, pageSize)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetParentLeave handles getting one of the parent's leave requests
+// @Summary Get leave request (parent)
+// @Description Get a leave request of one of the parent's children
+// @Tags Leave Requests
+// @Produce json
+// @Param id path int true "Leave request ID"
+// @Success 200 {object} LeaveResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/parent/leave-requests/{id} [get]
+func (h *Handler) GetParentLeave(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "leave request")
+	}
+
+	response, err := h.service.GetParentLeave(c.Context(), userID, uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// DownloadParentAttachment handles a parent downloading the photo of a leave request
+// @Summary Download leave attachment (parent)
+// @Description Download the photo attached to a leave request of one of the parent's children
+// @Tags Leave Requests
+// @Produce image/jpeg
+// @Produce image/png
+// @Produce image/webp
+// @Param id path int true "Leave request ID"
+// @Success 200 {file} binary
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/parent/leave-requests/{id}/attachment [get]
+func (h *Handler) DownloadParentAttachment(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "leave request")
+	}
+
+	leave, err := h.service.GetParentAttachment(c.Context(), userID, uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Download(leave.AttachmentPath, leave.AttachmentName)
+}
+
+// CancelLeave handles a parent withdrawing a leave request
+// @Summary Cancel leave request
+// @Description Withdraw a leave request the wali kelas has not reviewed yet
+// @Tags Leave Requests
+// @Produce json
+// @Param id path int true "Leave request ID"
+// @Success 200 {object} LeaveResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/parent/leave-requests/{id}/cancel [post]
+func (h *Handler) CancelLeave(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "leave request")
+	}
+
+	response, err := h.service.CancelLeave(c.Context(), userID, uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Pengajuan izin dibatalkan",
+	})
+}
+
+// ==================== Staff Handlers ====================
+
+// GetLeaves handles listing leave requests
+// @Summary List leave requests
+// @Description List leave requests of the school; a wali kelas only sees their own classes
+// @Tags Leave Requests
+// @Produce json
+// @Param class_id query int false "Filter by class ID"
+// @Param student_id query int false "Filter by student ID"
+// @Param status query string false "Filter by status (pending, approved, rejected, cancelled)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} LeaveListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/leave-requests [get]
+func (h *Handler) GetLeaves(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	var filter LeaveFilter
+	if classID, err := strconv.ParseUint(c.Query("class_id"), 10, 32); err == nil {
+		id := uint(classID)
+		filter.ClassID = &id
+	}
+	if studentID, err := strconv.ParseUint(c.Query("student_id"), 10, 32); err == nil {
+		id := uint(studentID)
+		filter.StudentID = &id
+	}
+	if status := c.Query("status"); status != "" {
+		leaveStatus := models.LeaveStatus(status)
+		if !leaveStatus.IsValid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "VAL_INVALID_STATUS",
+					"message": "Status harus pending, approved, rejected atau cancelled",
+				},
+			})
+		}
+		filter.Status = &leaveStatus
+	}
+	filter.Page, _ = strconv.Atoi(c.Query("page", "1"))
+	filter.PageSize, _ = strconv.Atoi(c.Query("page_size", "20"))
+
+	response, err := h.service.GetLeaves(c.Context(), schoolID, userID, models.UserRole(role), filter)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetLeave handles getting a leave request
+// @Summary Get leave request
+// @Description Get a leave request with the RFID taps flagged for review on its days
+// @Tags Leave Requests
+// @Produce json
+// @Param id path int true "Leave request ID"
+// @Success 200 {object} LeaveResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/leave-requests/{id} [get]
+func (h *Handler) GetLeave(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "leave request")
+	}
+
+	response, err := h.service.GetLeave(c.Context(), schoolID, userID, models.UserRole(role), uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// DownloadAttachment handles downloading the photo of a leave request
+// @Summary Download leave attachment
+// @Description Download the photo of the doctor's note attached to a leave request
+// @Tags Leave Requests
+// @Produce image/jpeg
+// @Produce image/png
+// @Produce image/webp
+// @Param id path int true "Leave request ID"
+// @Success 200 {file} binary
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/leave-requests/{id}/attachment [get]
+func (h *Handler) DownloadAttachment(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "leave request")
+	}
+
+	leave, err := h.service.GetAttachment(c.Context(), schoolID, userID, models.UserRole(role), uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Download(leave.AttachmentPath, leave.AttachmentName)
+}
+
+// ApproveLeave handles approving a leave request
+// @Summary Approve leave request
+// @Description Approve a pending leave request. Every school day in its range is recorded as sick or excused, and the parents are notified.
+// @Tags Leave Requests
+// @Accept json
+// @Produce json
+// @Param id path int true "Leave request ID"
+// @Param request body ReviewLeaveRequest false "Optional note for the parent"
+// @Success 200 {object} LeaveResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/leave-requests/{id}/approve [post]
+func (h *Handler) ApproveLeave(c *fiber.Ctx) error {
+	return h.review(c, true)
+}
+
+// RejectLeave handles rejecting a leave request
+// @Summary Reject leave request
+// @Description Reject a pending leave request with a note; the parents are notified
+// @Tags Leave Requests
+// @Accept json
+// @Produce json
+// @Param id path int true "Leave request ID"
+// @Param request body ReviewLeaveRequest true "Reason for rejecting"
+// @Success 200 {object} LeaveResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/leave-requests/{id}/reject [post]
+func (h *Handler) RejectLeave(c *fiber.Ctx) error {
+	return h.review(c, false)
+}
+
+// review handles both decisions on a leave request
+func (h *Handler) review(c *fiber.Ctx, approve bool) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "leave request")
+	}
+
+	var req ReviewLeaveRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return h.invalidBodyError(c)
+		}
+	}
+
+	var response *LeaveResponse
+	message := "Pengajuan izin disetujui"
+	if approve {
+		response, err = h.service.ApproveLeave(c.Context(), schoolID, userID, models.UserRole(role), uint(id), req)
+	} else {
+		response, err = h.service.RejectLeave(c.Context(), schoolID, userID, models.UserRole(role), uint(id), req)
+		message = "Pengajuan izin ditolak"
+	}
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": message,
+	})
+}
+
+// ResolveFlaggedTap handles clearing the review flag of an RFID tap on a leave day
+// @Summary Resolve flagged tap
+// @Description Mark an RFID tap recorded on a day of approved leave as reviewed. Correct the attendance itself through the attendance endpoints.
+// @Tags Leave Requests
+// @Produce json
+// @Param id path int true "Leave request ID"
+// @Param attendanceId path int true "Attendance ID"
+// @Success 200 {object} LeaveResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/leave-requests/{id}/taps/{attendanceId}/resolve [post]
+func (h *Handler) ResolveFlaggedTap(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "leave request")
+	}
+
+	attendanceID, err := strconv.ParseUint(c.Params("attendanceId"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "attendance")
+	}
+
+	response, err := h.service.ResolveFlaggedTap(c.Context(), schoolID, userID, models.UserRole(role), uint(id), uint(attendanceID))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Tap RFID telah ditinjau",
+	})
+}
+
+// ==================== Error Helpers ====================
+
+func (h *Handler) tenantRequiredError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "AUTHZ_TENANT_REQUIRED",
+			"message": "Konteks sekolah diperlukan",
+		},
+	})
+}
+
+func (h *Handler) authRequiredError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "AUTH_REQUIRED",
+			"message": "Autentikasi diperlukan",
+		},
+	})
+}
+
+func (h *Handler) invalidBodyError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "VAL_INVALID_FORMAT",
+			"message": "Format data tidak valid",
+		},
+	})
+}
+
+func (h *Handler) invalidIDError(c *fiber.Ctx, resource string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "VAL_INVALID_FORMAT",
+			"message": "Invalid " + resource + " ID",
+		},
+	})
+}
+
+func (h *Handler) handleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrLeaveNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_LEAVE_REQUEST",
+				"message": "Pengajuan izin tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrStudentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_STUDENT",
+				"message": "Siswa tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrParentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_PARENT",
+				"message": "Data orang tua tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrTapNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_FLAGGED_TAP",
+				"message": "Tap RFID yang perlu ditinjau tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrAttachmentNotExists):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_ATTACHMENT",
+				"message": "Pengajuan izin ini tidak memiliki lampiran",
+			},
+		})
+	case errors.Is(err, ErrLeaveNotPending):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "LEAVE_ALREADY_REVIEWED",
+				"message": "Pengajuan izin sudah diproses",
+			},
+		})
+	case errors.Is(err, ErrLeaveOverlap):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "LEAVE_OVERLAP",
+				"message": "Sudah ada pengajuan izin untuk tanggal tersebut",
+			},
+		})
+	case errors.Is(err, ErrInvalidLeaveType):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_LEAVE_TYPE",
+				"message": "Jenis izin harus sick (sakit) atau excused (izin)",
+			},
+		})
+	case errors.Is(err, ErrInvalidDate):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format tanggal harus YYYY-MM-DD",
+			},
+		})
+	case errors.Is(err, ErrDateOrder), errors.Is(err, ErrRangeTooLong), errors.Is(err, ErrTooLate), errors.Is(err, ErrNoSchoolDays):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_DATE_RANGE",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrReasonRequired), errors.Is(err, ErrReviewNoteRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrStudentNoClass):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_STUDENT_NO_CLASS",
+				"message": "Siswa belum terdaftar di kelas",
+			},
+		})
+	case errors.Is(err, ErrAttachmentTooLarge):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_TOO_LARGE",
+				"message": "Foto surat keterangan maksimal 5 MB",
+			},
+		})
+	case errors.Is(err, ErrAttachmentInvalid):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_INVALID",
+				"message": "Lampiran harus berupa foto JPEG, PNG atau WebP",
+			},
+		})
+	case errors.Is(err, ErrNotLinked):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_NOT_LINKED",
+				"message": "Siswa tidak terhubung dengan akun Anda",
+			},
+		})
+	case errors.Is(err, ErrNotHomeroomTeacher):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_NOT_HOMEROOM_TEACHER",
+				"message": "Anda bukan wali kelas dari siswa ini",
+			},
+		})
+	case errors.Is(err, ErrNotAuthorized):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_NOT_AUTHORIZED",
+				"message": "Tidak memiliki izin untuk melakukan aksi ini",
+			},
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "INTERNAL_ERROR",
+				"message": "Terjadi kesalahan pada server",
+			},
+		})
+	}
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Success bool `json:"success"`
+	Error   struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
@@ -0,0 +1,320 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/calendar"
+	"github.com/school-management/backend/internal/shared/outbox"
+)
+
+var (
+	ErrLeaveNotFound   = errors.New("pengajuan izin tidak ditemukan")
+	ErrStudentNotFound = errors.New("siswa tidak ditemukan")
+	ErrParentNotFound  = errors.New("orang tua tidak ditemukan")
+	ErrLeaveNotPending = errors.New("pengajuan izin sudah diproses")
+	ErrTapNotFound     = errors.New("tap RFID yang perlu ditinjau tidak ditemukan")
+)
+
+// Repository defines data operations for leave requests
+type Repository interface {
+	// Parent and student lookups
+	FindParentByUserID(ctx context.Context, userID uint) (*models.Parent, error)
+	IsStudentLinked(ctx context.Context, parentID, studentID uint) (bool, error)
+	FindStudentByID(ctx context.Context, studentID uint) (*models.Student, error)
+	FindHomeroomClassIDs(ctx context.Context, schoolID, teacherID uint) ([]uint, error)
+	FindHolidays(ctx context.Context, schoolID uint, start, end time.Time) (map[string]bool, error)
+
+	// Leave request operations
+	HasOverlap(ctx context.Context, studentID uint, start, end time.Time) (bool, error)
+	Create(ctx context.Context, leave *models.LeaveRequest, event *outbox.Event) error
+	FindByID(ctx context.Context, schoolID, id uint) (*models.LeaveRequest, error)
+	FindAll(ctx context.Context, schoolID uint, classIDs []uint, filter LeaveFilter) ([]models.LeaveRequest, int64, error)
+	FindFlaggedTaps(ctx context.Context, leaveID uint) ([]models.Attendance, error)
+
+	// Review operations; each only succeeds while the request is pending
+	Approve(ctx context.Context, leave *models.LeaveRequest, days []time.Time, event *outbox.Event) error
+	Reject(ctx context.Context, leave *models.LeaveRequest, event *outbox.Event) error
+	Cancel(ctx context.Context, leave *models.LeaveRequest) error
+	ClearTapReview(ctx context.Context, leaveID, attendanceID uint) error
+}
+
+// repository implements the Repository interface
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new leave request repository
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// ==================== Lookups ====================
+
+// FindParentByUserID retrieves the parent record of a user
+func (r *repository) FindParentByUserID(ctx context.Context, userID uint) (*models.Parent, error) {
+	var parent models.Parent
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&parent).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrParentNotFound
+		}
+		return nil, err
+	}
+	return &parent, nil
+}
+
+// IsStudentLinked checks if a student is linked to a parent
+func (r *repository) IsStudentLinked(ctx context.Context, parentID, studentID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("student_parents").
+		Where("parent_id = ? AND student_id = ?", parentID, studentID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindStudentByID retrieves a student with their class
+func (r *repository) FindStudentByID(ctx context.Context, studentID uint) (*models.Student, error) {
+	var student models.Student
+	err := r.db.WithContext(ctx).
+		Preload("Class").
+		First(&student, studentID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStudentNotFound
+		}
+		return nil, err
+	}
+	return &student, nil
+}
+
+// FindHomeroomClassIDs retrieves the classes a wali kelas is homeroom teacher of
+func (r *repository) FindHomeroomClassIDs(ctx context.Context, schoolID, teacherID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.Class{}).
+		Where("school_id = ? AND homeroom_teacher_id = ? AND is_archived = ?", schoolID, teacherID, false).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FindHolidays retrieves the school's holidays between two dates
+func (r *repository) FindHolidays(ctx context.Context, schoolID uint, start, end time.Time) (map[string]bool, error) {
+	return calendar.HolidayDates(ctx, r.db, schoolID, start, end)
+}
+
+// ==================== Leave Requests ====================
+
+// HasOverlap checks if a student already has a pending or approved leave on any of the days
+func (r *repository) HasOverlap(ctx context.Context, studentID uint, start, end time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.LeaveRequest{}).
+		Where("student_id = ? AND status IN ? AND start_date <= ?::date AND end_date >= ?::date",
+			studentID, []models.LeaveStatus{models.LeaveStatusPending, models.LeaveStatusApproved},
+			end.Format("2006-01-02"), start.Format("2006-01-02")).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create creates a leave request and writes its notification event in the same transaction
+func (r *repository) Create(ctx context.Context, leave *models.LeaveRequest, event *outbox.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Student", "Class", "Submitter", "Reviewer").Create(leave).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, leave.ID, event)
+	})
+}
+
+// FindByID retrieves a leave request of a school
+func (r *repository) FindByID(ctx context.Context, schoolID, id uint) (*models.LeaveRequest, error) {
+	var leave models.LeaveRequest
+	err := r.db.WithContext(ctx).
+		Preload("Student").
+		Preload("Class").
+		Preload("Submitter").
+		Preload("Reviewer").
+		Where("id = ? AND school_id = ?", id, schoolID).
+		First(&leave).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLeaveNotFound
+		}
+		return nil, err
+	}
+	return &leave, nil
+}
+
+// FindAll retrieves the leave requests of a school, newest first
+// A non-nil classIDs limits the result to those classes (a wali kelas's own classes).
+func (r *repository) FindAll(ctx context.Context, schoolID uint, classIDs []uint, filter LeaveFilter) ([]models.LeaveRequest, int64, error) {
+	var leaves []models.LeaveRequest
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&models.LeaveRequest{}).
+		Where("school_id = ?", schoolID)
+
+	if classIDs != nil {
+		query = query.Where("class_id IN ?", classIDs)
+	}
+	if filter.ClassID != nil {
+		query = query.Where("class_id = ?", *filter.ClassID)
+	}
+	if filter.StudentID != nil {
+		query = query.Where("student_id = ?", *filter.StudentID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Student").
+		Preload("Class").
+		Preload("Submitter").
+		Preload("Reviewer").
+		Order("created_at DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&leaves).Error
+
+	return leaves, total, err
+}
+
+// FindFlaggedTaps retrieves the RFID taps still waiting for review on the days of a leave
+func (r *repository) FindFlaggedTaps(ctx context.Context, leaveID uint) ([]models.Attendance, error) {
+	var taps []models.Attendance
+	err := r.db.WithContext(ctx).
+		Where("leave_request_id = ? AND needs_review = ?", leaveID, true).
+		Order("date ASC, check_in_time ASC").
+		Find(&taps).Error
+	return taps, err
+}
+
+// ==================== Review ====================
+
+// Approve approves a leave request and records the leave on the student's attendance
+// Absences already marked by the scheduler on those days become sick/excused, taps already
+// recorded are flagged for review, and every other school day gets a new record.
+func (r *repository) Approve(ctx context.Context, leave *models.LeaveRequest, days []time.Time, event *outbox.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := review(tx, leave); err != nil {
+			return err
+		}
+
+		status := leave.Type.AttendanceStatus()
+		if err := tx.Model(&models.Attendance{}).
+			Where("student_id = ? AND date IN ? AND method = ? AND status = ? AND check_in_time IS NULL",
+				leave.StudentID, days, models.AttendanceMethodAuto, models.AttendanceStatusAbsent).
+			Updates(map[string]interface{}{"status": status, "leave_request_id": leave.ID}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Attendance{}).
+			Where("student_id = ? AND date IN ? AND check_in_time IS NOT NULL", leave.StudentID, days).
+			Updates(map[string]interface{}{"needs_review": true, "leave_request_id": leave.ID}).Error; err != nil {
+			return err
+		}
+
+		var recorded []time.Time
+		if err := tx.Model(&models.Attendance{}).
+			Where("student_id = ? AND date IN ?", leave.StudentID, days).
+			Distinct().
+			Pluck("date", &recorded).Error; err != nil {
+			return err
+		}
+		seen := make(map[string]bool, len(recorded))
+		for _, day := range recorded {
+			seen[day.Format("2006-01-02")] = true
+		}
+
+		for _, day := range days {
+			if seen[day.Format("2006-01-02")] {
+				continue
+			}
+			attendance := &models.Attendance{
+				StudentID:      leave.StudentID,
+				Date:           day,
+				Status:         status,
+				Method:         models.AttendanceMethodAuto,
+				LeaveRequestID: &leave.ID,
+			}
+			if err := tx.Omit("Student", "Schedule").Create(attendance).Error; err != nil {
+				return err
+			}
+		}
+
+		return outbox.Append(tx, leave.ID, event)
+	})
+}
+
+// Reject rejects a leave request
+func (r *repository) Reject(ctx context.Context, leave *models.LeaveRequest, event *outbox.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := review(tx, leave); err != nil {
+			return err
+		}
+		return outbox.Append(tx, leave.ID, event)
+	})
+}
+
+// Cancel withdraws a leave request that has not been reviewed
+func (r *repository) Cancel(ctx context.Context, leave *models.LeaveRequest) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.LeaveRequest{}).
+		Where("id = ? AND status = ?", leave.ID, models.LeaveStatusPending).
+		Update("status", models.LeaveStatusCancelled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLeaveNotPending
+	}
+	return nil
+}
+
+// ClearTapReview marks a flagged tap as reviewed
+func (r *repository) ClearTapReview(ctx context.Context, leaveID, attendanceID uint) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.Attendance{}).
+		Where("id = ? AND leave_request_id = ? AND needs_review = ?", attendanceID, leaveID, true).
+		Update("needs_review", false)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTapNotFound
+	}
+	return nil
+}
+
+// review writes the reviewer's decision, guarding against a concurrent review of the same request
+func review(tx *gorm.DB, leave *models.LeaveRequest) error {
+	result := tx.Model(&models.LeaveRequest{}).
+		Where("id = ? AND status = ?", leave.ID, models.LeaveStatusPending).
+		Updates(map[string]interface{}{
+			"status":      leave.Status,
+			"reviewed_by": leave.ReviewedBy,
+			"reviewed_at": leave.ReviewedAt,
+			"review_note": leave.ReviewNote,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLeaveNotPending
+	}
+	return nil
+}
@@ -0,0 +1,617 @@
+package leave
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/calendar"
+	"github.com/school-management/backend/internal/shared/outbox"
+)
+
+const (
+	// MaxLeaveDays is the longest range a single leave request may cover
+	MaxLeaveDays = 14
+	// MaxBackdateDays is how long after the first day of absence a parent may still ask leave for it
+	MaxBackdateDays = 7
+	// MaxAttachmentSize is the largest accepted photo of a doctor's note
+	MaxAttachmentSize = 5 * 1024 * 1024
+)
+
+// attachmentTypes maps the accepted photo content types to their file extension
+var attachmentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+var (
+	ErrInvalidLeaveType    = errors.New("jenis izin harus sick (sakit) atau excused (izin)")
+	ErrInvalidDate         = errors.New("format tanggal harus YYYY-MM-DD")
+	ErrDateOrder           = errors.New("tanggal selesai tidak boleh sebelum tanggal mulai")
+	ErrRangeTooLong        = fmt.Errorf("satu pengajuan izin paling lama %d hari", MaxLeaveDays)
+	ErrTooLate             = fmt.Errorf("izin hanya dapat diajukan paling lambat %d hari setelah tanggal mulai", MaxBackdateDays)
+	ErrReasonRequired      = errors.New("alasan wajib diisi")
+	ErrNoSchoolDays        = errors.New("tidak ada hari sekolah pada rentang tanggal ini")
+	ErrLeaveOverlap        = errors.New("sudah ada pengajuan izin untuk tanggal tersebut")
+	ErrStudentNoClass      = errors.New("siswa belum terdaftar di kelas")
+	ErrNotLinked           = errors.New("siswa tidak terhubung dengan orang tua ini")
+	ErrNotAuthorized       = errors.New("tidak memiliki izin untuk melakukan aksi ini")
+	ErrNotHomeroomTeacher  = errors.New("anda bukan wali kelas dari siswa ini")
+	ErrReviewNoteRequired  = errors.New("alasan penolakan wajib diisi")
+	ErrAttachmentTooLarge  = errors.New("foto surat keterangan maksimal 5 MB")
+	ErrAttachmentInvalid   = errors.New("lampiran harus berupa foto JPEG, PNG atau WebP")
+	ErrAttachmentNotExists = errors.New("pengajuan izin ini tidak memiliki lampiran")
+)
+
+// Attachment is the photo uploaded with a leave request
+type Attachment struct {
+	FileName string
+	Reader   io.Reader
+}
+
+// Service defines the interface for leave request business logic
+type Service interface {
+	// Parent operations
+	SubmitLeave(ctx context.Context, userID, studentID uint, req SubmitLeaveRequest, attachment *Attachment) (*LeaveResponse, error)
+	GetChildLeaves(ctx context.Context, userID, studentID uint, page, pageSize int) (*LeaveListResponse, error)
+	GetParentLeave(ctx context.Context, userID, id uint) (*LeaveResponse, error)
+	GetParentAttachment(ctx context.Context, userID, id uint) (*models.LeaveRequest, error)
+	CancelLeave(ctx context.Context, userID, id uint) (*LeaveResponse, error)
+
+	// Wali kelas and admin sekolah operations
+	GetLeaves(ctx context.Context, schoolID, userID uint, role models.UserRole, filter LeaveFilter) (*LeaveListResponse, error)
+	GetLeave(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint) (*LeaveResponse, error)
+	GetAttachment(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint) (*models.LeaveRequest, error)
+	ApproveLeave(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint, req ReviewLeaveRequest) (*LeaveResponse, error)
+	RejectLeave(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint, req ReviewLeaveRequest) (*LeaveResponse, error)
+	ResolveFlaggedTap(ctx context.Context, schoolID, userID uint, role models.UserRole, id, attendanceID uint) (*LeaveResponse, error)
+}
+
+// service implements the Service interface
+type service struct {
+	repo       Repository
+	storageDir string
+}
+
+// NewService creates a new leave request service
+func NewService(repo Repository, storageDir string) Service {
+	return &service{repo: repo, storageDir: storageDir}
+}
+
+// ==================== Parent Operations ====================
+
+// SubmitLeave submits a sick or excused leave request for a linked child
+// The request goes to the wali kelas of the child's class, who is notified.
+func (s *service) SubmitLeave(ctx context.Context, userID, studentID uint, req SubmitLeaveRequest, attachment *Attachment) (*LeaveResponse, error) {
+	parent, err := s.linkedParent(ctx, userID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaveType := models.LeaveType(strings.TrimSpace(req.Type))
+	if !leaveType.IsValid() {
+		return nil, ErrInvalidLeaveType
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+	end := start
+	if req.EndDate != "" {
+		if end, err = time.Parse("2006-01-02", req.EndDate); err != nil {
+			return nil, ErrInvalidDate
+		}
+	}
+	if end.Before(start) {
+		return nil, ErrDateOrder
+	}
+	if end.Sub(start) >= MaxLeaveDays*24*time.Hour {
+		return nil, ErrRangeTooLong
+	}
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	if start.Before(today.AddDate(0, 0, -MaxBackdateDays)) {
+		return nil, ErrTooLate
+	}
+
+	student, err := s.repo.FindStudentByID(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student.ClassID == nil || student.Class == nil {
+		return nil, ErrStudentNoClass
+	}
+
+	holidays, err := s.repo.FindHolidays(ctx, student.SchoolID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(calendar.SchoolDays(start, end, holidays)) == 0 {
+		return nil, ErrNoSchoolDays
+	}
+
+	overlap, err := s.repo.HasOverlap(ctx, studentID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if overlap {
+		return nil, ErrLeaveOverlap
+	}
+
+	leave := &models.LeaveRequest{
+		SchoolID:    student.SchoolID,
+		StudentID:   student.ID,
+		ClassID:     student.ClassID,
+		Type:        leaveType,
+		StartDate:   start,
+		EndDate:     end,
+		Reason:      reason,
+		Status:      models.LeaveStatusPending,
+		SubmittedBy: userID,
+	}
+	if err := leave.Validate(); err != nil {
+		return nil, err
+	}
+
+	if attachment != nil {
+		if err := s.storeAttachment(leave, attachment); err != nil {
+			return nil, err
+		}
+	}
+
+	// The wali kelas hears about the request through the outbox
+	var event *outbox.Event
+	if teacherID := student.Class.HomeroomTeacherID; teacherID != nil {
+		event = outbox.NewEvent(outbox.EventLeaveRequested, outbox.Payload{
+			SchoolID:  student.SchoolID,
+			StudentID: student.ID,
+			Notification: &outbox.NotificationPayload{
+				Type:    models.NotificationTypeLeaveRequest,
+				Title:   "Pengajuan Izin Baru",
+				Message: fmt.Sprintf("%s mengajukan %s untuk %s (%s).", parent.Name, typeLabel(leaveType), student.Name, dateRangeLabel(start, end)),
+				Data: map[string]interface{}{
+					"student_id": fmt.Sprintf("%d", student.ID),
+				},
+				UserIDs: []uint{*teacherID},
+			},
+		})
+	}
+
+	if err := s.repo.Create(ctx, leave, event); err != nil {
+		if leave.AttachmentPath != "" {
+			os.Remove(leave.AttachmentPath)
+		}
+		return nil, err
+	}
+
+	return s.reload(ctx, leave.SchoolID, leave.ID)
+}
+
+// GetChildLeaves retrieves the leave requests of a linked child, newest first
+func (s *service) GetChildLeaves(ctx context.Context, userID, studentID uint, page, pageSize int) (*LeaveListResponse, error) {
+	parent, err := s.linkedParent(ctx, userID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.list(ctx, parent.SchoolID, nil, LeaveFilter{
+		StudentID: &studentID,
+		Page:      page,
+		PageSize:  pageSize,
+	})
+}
+
+// GetParentLeave retrieves a leave request of one of the parent's children
+func (s *service) GetParentLeave(ctx context.Context, userID, id uint) (*LeaveResponse, error) {
+	leave, err := s.parentLeave(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toLeaveResponse(leave, nil), nil
+}
+
+// GetParentAttachment retrieves a leave request of one of the parent's children for downloading its photo
+func (s *service) GetParentAttachment(ctx context.Context, userID, id uint) (*models.LeaveRequest, error) {
+	leave, err := s.parentLeave(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if leave.AttachmentPath == "" {
+		return nil, ErrAttachmentNotExists
+	}
+	return leave, nil
+}
+
+// CancelLeave withdraws a leave request the wali kelas has not reviewed yet
+func (s *service) CancelLeave(ctx context.Context, userID, id uint) (*LeaveResponse, error) {
+	leave, err := s.parentLeave(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !leave.IsPending() {
+		return nil, ErrLeaveNotPending
+	}
+
+	if err := s.repo.Cancel(ctx, leave); err != nil {
+		return nil, err
+	}
+
+	return s.reload(ctx, leave.SchoolID, leave.ID)
+}
+
+// ==================== Staff Operations ====================
+
+// GetLeaves retrieves leave requests; a wali kelas only sees their own classes
+func (s *service) GetLeaves(ctx context.Context, schoolID, userID uint, role models.UserRole, filter LeaveFilter) (*LeaveListResponse, error) {
+	var classIDs []uint
+	switch role {
+	case models.RoleAdminSekolah:
+	case models.RoleWaliKelas:
+		ids, err := s.repo.FindHomeroomClassIDs(ctx, schoolID, userID)
+		if err != nil {
+			return nil, err
+		}
+		classIDs = append([]uint{}, ids...)
+	default:
+		return nil, ErrNotAuthorized
+	}
+
+	return s.list(ctx, schoolID, classIDs, filter)
+}
+
+// GetLeave retrieves a leave request with the RFID taps flagged on its days
+func (s *service) GetLeave(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint) (*LeaveResponse, error) {
+	leave, err := s.staffLeave(ctx, schoolID, userID, role, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withTaps(ctx, leave)
+}
+
+// GetAttachment retrieves a leave request for downloading its photo
+func (s *service) GetAttachment(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint) (*models.LeaveRequest, error) {
+	leave, err := s.staffLeave(ctx, schoolID, userID, role, id)
+	if err != nil {
+		return nil, err
+	}
+	if leave.AttachmentPath == "" {
+		return nil, ErrAttachmentNotExists
+	}
+	return leave, nil
+}
+
+// ApproveLeave approves a leave request and records sick/excused attendance for its school days
+func (s *service) ApproveLeave(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint, req ReviewLeaveRequest) (*LeaveResponse, error) {
+	leave, err := s.staffLeave(ctx, schoolID, userID, role, id)
+	if err != nil {
+		return nil, err
+	}
+	if !leave.IsPending() {
+		return nil, ErrLeaveNotPending
+	}
+
+	holidays, err := s.repo.FindHolidays(ctx, schoolID, leave.StartDate, leave.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	days := calendar.SchoolDays(leave.StartDate, leave.EndDate, holidays)
+
+	s.markReviewed(leave, userID, models.LeaveStatusApproved, req.Note)
+	event := reviewEvent(leave, "Pengajuan Izin Disetujui",
+		fmt.Sprintf("Pengajuan %s untuk %s (%s) telah disetujui wali kelas.", typeLabel(leave.Type), leave.Student.Name, dateRangeLabel(leave.StartDate, leave.EndDate)))
+
+	if err := s.repo.Approve(ctx, leave, days, event); err != nil {
+		return nil, err
+	}
+
+	return s.reloadWithTaps(ctx, schoolID, leave.ID)
+}
+
+// RejectLeave rejects a leave request; the note tells the parent why
+func (s *service) RejectLeave(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint, req ReviewLeaveRequest) (*LeaveResponse, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, ErrReviewNoteRequired
+	}
+
+	leave, err := s.staffLeave(ctx, schoolID, userID, role, id)
+	if err != nil {
+		return nil, err
+	}
+	if !leave.IsPending() {
+		return nil, ErrLeaveNotPending
+	}
+
+	s.markReviewed(leave, userID, models.LeaveStatusRejected, note)
+	event := reviewEvent(leave, "Pengajuan Izin Ditolak",
+		fmt.Sprintf("Pengajuan %s untuk %s (%s) ditolak: %s", typeLabel(leave.Type), leave.Student.Name, dateRangeLabel(leave.StartDate, leave.EndDate), note))
+
+	if err := s.repo.Reject(ctx, leave, event); err != nil {
+		return nil, err
+	}
+
+	return s.reload(ctx, schoolID, leave.ID)
+}
+
+// ResolveFlaggedTap marks an RFID tap on a day of the leave as reviewed
+// Correcting the attendance itself is done through the attendance endpoints.
+func (s *service) ResolveFlaggedTap(ctx context.Context, schoolID, userID uint, role models.UserRole, id, attendanceID uint) (*LeaveResponse, error) {
+	leave, err := s.staffLeave(ctx, schoolID, userID, role, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ClearTapReview(ctx, leave.ID, attendanceID); err != nil {
+		return nil, err
+	}
+
+	return s.withTaps(ctx, leave)
+}
+
+// ==================== Helpers ====================
+
+// linkedParent retrieves the parent of a user and checks the student is their child
+func (s *service) linkedParent(ctx context.Context, userID, studentID uint) (*models.Parent, error) {
+	parent, err := s.repo.FindParentByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	linked, err := s.repo.IsStudentLinked(ctx, parent.ID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if !linked {
+		return nil, ErrNotLinked
+	}
+	return parent, nil
+}
+
+// parentLeave retrieves a leave request and checks it is for one of the parent's children
+func (s *service) parentLeave(ctx context.Context, userID, id uint) (*models.LeaveRequest, error) {
+	parent, err := s.repo.FindParentByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	leave, err := s.repo.FindByID(ctx, parent.SchoolID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	linked, err := s.repo.IsStudentLinked(ctx, parent.ID, leave.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	if !linked {
+		return nil, ErrLeaveNotFound
+	}
+	return leave, nil
+}
+
+// staffLeave retrieves a leave request and checks the user may review it
+// Admin sekolah may review any request; a wali kelas only those of their own classes.
+func (s *service) staffLeave(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint) (*models.LeaveRequest, error) {
+	leave, err := s.repo.FindByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch role {
+	case models.RoleAdminSekolah:
+		return leave, nil
+	case models.RoleWaliKelas:
+		classIDs, err := s.repo.FindHomeroomClassIDs(ctx, schoolID, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, classID := range classIDs {
+			if leave.ClassID != nil && *leave.ClassID == classID {
+				return leave, nil
+			}
+		}
+		return nil, ErrNotHomeroomTeacher
+	default:
+		return nil, ErrNotAuthorized
+	}
+}
+
+func (s *service) list(ctx context.Context, schoolID uint, classIDs []uint, filter LeaveFilter) (*LeaveListResponse, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	leaves, total, err := s.repo.FindAll(ctx, schoolID, classIDs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]LeaveResponse, len(leaves))
+	for i := range leaves {
+		responses[i] = *toLeaveResponse(&leaves[i], nil)
+	}
+
+	totalPages := int(total) / filter.PageSize
+	if int(total)%filter.PageSize > 0 {
+		totalPages++
+	}
+
+	return &LeaveListResponse{
+		LeaveRequests: responses,
+		Pagination: PaginationMeta{
+			Page:       filter.Page,
+			PageSize:   filter.PageSize,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+func (s *service) reload(ctx context.Context, schoolID, id uint) (*LeaveResponse, error) {
+	leave, err := s.repo.FindByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toLeaveResponse(leave, nil), nil
+}
+
+func (s *service) reloadWithTaps(ctx context.Context, schoolID, id uint) (*LeaveResponse, error) {
+	leave, err := s.repo.FindByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withTaps(ctx, leave)
+}
+
+func (s *service) withTaps(ctx context.Context, leave *models.LeaveRequest) (*LeaveResponse, error) {
+	taps, err := s.repo.FindFlaggedTaps(ctx, leave.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toLeaveResponse(leave, taps), nil
+}
+
+func (s *service) markReviewed(leave *models.LeaveRequest, userID uint, status models.LeaveStatus, note string) {
+	now := time.Now()
+	leave.Status = status
+	leave.ReviewedBy = &userID
+	leave.ReviewedAt = &now
+	leave.ReviewNote = strings.TrimSpace(note)
+}
+
+// storeAttachment saves the photo of the doctor's note under the school's directory
+// The content type is sniffed from the file itself rather than trusted from the upload.
+func (s *service) storeAttachment(leave *models.LeaveRequest, attachment *Attachment) error {
+	data, err := io.ReadAll(io.LimitReader(attachment.Reader, MaxAttachmentSize+1))
+	if err != nil {
+		return fmt.Errorf("read attachment: %w", err)
+	}
+	if len(data) > MaxAttachmentSize {
+		return ErrAttachmentTooLarge
+	}
+	if len(data) == 0 {
+		return ErrAttachmentInvalid
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := attachmentTypes[contentType]
+	if !ok {
+		return ErrAttachmentInvalid
+	}
+
+	dir := filepath.Join(s.storageDir, fmt.Sprintf("%d", leave.SchoolID))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create attachment storage: %w", err)
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return fmt.Errorf("name attachment: %w", err)
+	}
+	path := filepath.Join(dir, hex.EncodeToString(token)+ext)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("store attachment: %w", err)
+	}
+
+	leave.AttachmentPath = path
+	leave.AttachmentName = filepath.Base(attachment.FileName)
+	leave.AttachmentType = contentType
+	return nil
+}
+
+// reviewEvent builds the notification telling the parents about the decision
+func reviewEvent(leave *models.LeaveRequest, title, message string) *outbox.Event {
+	return outbox.NewEvent(outbox.EventLeaveReviewed, outbox.Payload{
+		SchoolID:  leave.SchoolID,
+		StudentID: leave.StudentID,
+		Notification: outbox.ParentNotification(
+			models.NotificationTypeLeaveRequest,
+			title,
+			message,
+			map[string]interface{}{
+				"student_id":       fmt.Sprintf("%d", leave.StudentID),
+				"leave_request_id": fmt.Sprintf("%d", leave.ID),
+			},
+		),
+	})
+}
+
+func typeLabel(t models.LeaveType) string {
+	if t == models.LeaveTypeSick {
+		return "izin sakit"
+	}
+	return "izin"
+}
+
+func dateRangeLabel(start, end time.Time) string {
+	if start.Equal(end) {
+		return start.Format("02/01/2006")
+	}
+	return start.Format("02/01/2006") + " - " + end.Format("02/01/2006")
+}
+
+func toLeaveResponse(leave *models.LeaveRequest, taps []models.Attendance) *LeaveResponse {
+	response := &LeaveResponse{
+		ID:             leave.ID,
+		StudentID:      leave.StudentID,
+		StudentName:    leave.Student.Name,
+		StudentNIS:     leave.Student.NIS,
+		ClassID:        leave.ClassID,
+		Type:           leave.Type,
+		StartDate:      leave.StartDate.Format("2006-01-02"),
+		EndDate:        leave.EndDate.Format("2006-01-02"),
+		Reason:         leave.Reason,
+		HasAttachment:  leave.AttachmentPath != "",
+		AttachmentName: leave.AttachmentName,
+		Status:         leave.Status,
+		SubmittedBy:    leave.SubmittedBy,
+		SubmitterName:  leave.Submitter.Name,
+		ReviewedBy:     leave.ReviewedBy,
+		ReviewedAt:     leave.ReviewedAt,
+		ReviewNote:     leave.ReviewNote,
+		CreatedAt:      leave.CreatedAt,
+	}
+	if leave.Class != nil {
+		response.ClassName = leave.Class.Name
+	}
+	if leave.Reviewer != nil {
+		response.ReviewerName = leave.Reviewer.Name
+		if response.ReviewerName == "" {
+			response.ReviewerName = leave.Reviewer.Username
+		}
+	}
+
+	for _, tap := range taps {
+		flagged := FlaggedTap{
+			AttendanceID: tap.ID,
+			Date:         tap.Date.Format("2006-01-02"),
+			Status:       tap.Status,
+		}
+		if tap.CheckInTime != nil {
+			flagged.CheckInTime = tap.CheckInTime.Format("15:04")
+		}
+		response.FlaggedTaps = append(response.FlaggedTaps, flagged)
+	}
+
+	return response
+}
@@ -0,0 +1,86 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/outbox"
+)
+
+// OutboxPublisher delivers outbox events that carry a notification payload.
+// It resolves the recipients at publish time and hands each one to SendNotification,
+// which stores the notification and queues it for FCM.
+type OutboxPublisher struct {
+	service Service
+	repo    Repository
+}
+
+// NewOutboxPublisher creates a new notification outbox publisher
+func NewOutboxPublisher(service Service, repo Repository) *OutboxPublisher {
+	return &OutboxPublisher{
+		service: service,
+		repo:    repo,
+	}
+}
+
+// Name identifies the publisher in the delivery record of an event
+func (p *OutboxPublisher) Name() string {
+	return "notification"
+}
+
+// Publish sends the event's notification to its recipients
+// Requirements: Property 17 - Notifications SHALL only be sent if the corresponding notification setting is enabled
+func (p *OutboxPublisher) Publish(ctx context.Context, event *models.OutboxEvent, payload *outbox.Payload) error {
+	notif := payload.Notification
+	if notif == nil {
+		return nil
+	}
+
+	if payload.SchoolID != 0 {
+		settings, err := p.repo.FindSchoolSettings(ctx, payload.SchoolID)
+		if err != nil {
+			return err
+		}
+		if !settings.ShouldSendNotification(notif.Type) {
+			return nil
+		}
+	}
+
+	recipients := append([]uint{}, notif.UserIDs...)
+	if notif.NotifyParents && payload.StudentID != 0 {
+		parentIDs, err := p.repo.FindParentUserIDsByStudent(ctx, payload.StudentID)
+		if err != nil {
+			return err
+		}
+		recipients = append(recipients, parentIDs...)
+	}
+
+	data := make(map[string]interface{}, len(notif.Data)+2)
+	for k, v := range notif.Data {
+		data[k] = v
+	}
+	data["event_type"] = event.EventType
+	data["reference_id"] = fmt.Sprintf("%d", event.AggregateID)
+
+	seen := make(map[uint]bool, len(recipients))
+	for _, userID := range recipients {
+		if userID == 0 || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		if err := p.service.SendNotification(ctx, userID, notif.Type, notif.Title, notif.Message, data); err != nil {
+			// A recipient that no longer exists must not block the event forever
+			if errors.Is(err, ErrUserNotFound) {
+				log.Printf("Skipping outbox notification for missing user %d (event %d)", userID, event.ID)
+				continue
+			}
+			return fmt.Errorf("send notification to user %d: %w", userID, err)
+		}
+	}
+
+	return nil
+}
@@ -39,6 +39,10 @@ type Repository interface {
 
 	// User lookup
 	FindUserByID(ctx context.Context, userID uint) (*models.User, error)
+
+	// Outbox delivery lookups
+	FindParentUserIDsByStudent(ctx context.Context, studentID uint) ([]uint, error)
+	FindSchoolSettings(ctx context.Context, schoolID uint) (*models.SchoolSettings, error)
 }
 
 // repository implements the Repository interface
@@ -266,3 +270,35 @@ func (r *repository) FindUserByID(ctx context.Context, userID uint) (*models.Use
 	}
 	return &user, nil
 }
+
+// FindParentUserIDsByStudent retrieves the user IDs of all parents linked to a student
+func (r *repository) FindParentUserIDsByStudent(ctx context.Context, studentID uint) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.WithContext(ctx).
+		Table("parents").
+		Joins("JOIN student_parents ON student_parents.parent_id = parents.id").
+		Where("student_parents.student_id = ?", studentID).
+		Where("parents.user_id <> 0").
+		Distinct().
+		Pluck("parents.user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// FindSchoolSettings retrieves school settings, falling back to defaults when none are stored
+func (r *repository) FindSchoolSettings(ctx context.Context, schoolID uint) (*models.SchoolSettings, error) {
+	var settings models.SchoolSettings
+	err := r.db.WithContext(ctx).
+		Where("school_id = ?", schoolID).
+		First(&settings).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.DefaultSchoolSettings(schoolID), nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
@@ -48,17 +48,25 @@ type Service interface {
 	SendNotification(ctx context.Context, userID uint, notifType models.NotificationType, title, message string, data map[string]interface{}) error
 }
 
+// RealtimePusher pushes a stored notification to the recipient's open WebSocket connections
+type RealtimePusher interface {
+	PushNotification(schoolID, userID uint, notification interface{})
+}
+
 // service implements the Service interface
 type service struct {
 	repo        Repository
 	redisClient *redis.Client
+	pusher      RealtimePusher
 }
 
 // NewService creates a new notification service
-func NewService(repo Repository, redisClient *redis.Client) Service {
+// pusher may be nil when notifications are not pushed over WebSocket.
+func NewService(repo Repository, redisClient *redis.Client, pusher RealtimePusher) Service {
 	return &service{
 		repo:        repo,
 		redisClient: redisClient,
+		pusher:      pusher,
 	}
 }
 
@@ -82,7 +90,7 @@ func (s *service) CreateNotification(ctx context.Context, req CreateNotification
 	}
 
 	// Verify user exists
-	_, err := s.repo.FindUserByID(ctx, req.UserID)
+	user, err := s.repo.FindUserByID(ctx, req.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +114,14 @@ func (s *service) CreateNotification(ctx context.Context, req CreateNotification
 		return nil, err
 	}
 
-	return toNotificationResponse(notification), nil
+	response := toNotificationResponse(notification)
+
+	// WebSocket connections are scoped to a school; users without one only get FCM
+	if s.pusher != nil && user.SchoolID != nil {
+		s.pusher.PushNotification(*user.SchoolID, user.ID, response)
+	}
+
+	return response, nil
 }
 
 // GetNotificationByID retrieves a notification by ID
@@ -1,6 +1,10 @@
 package parent
 
-import "time"
+import (
+	"time"
+
+	"github.com/school-management/backend/internal/shared/gradebook"
+)
 
 // ==================== Child DTOs ====================
 
@@ -53,12 +57,16 @@ type AttendanceSummaryResponse struct {
 
 // ChildGradeResponse represents a grade for a child
 type ChildGradeResponse struct {
-	ID          uint      `json:"id"`
-	Title       string    `json:"title"`
-	Score       float64   `json:"score"`
-	Description string    `json:"description"`
-	TeacherName string    `json:"teacher_name"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID                     uint      `json:"id"`
+	SubjectName            string    `json:"subject_name,omitempty"`
+	AssessmentCategoryName string    `json:"assessment_category_name,omitempty"`
+	AcademicYear           string    `json:"academic_year,omitempty"`
+	Semester               int       `json:"semester,omitempty"`
+	Title                  string    `json:"title"`
+	Score                  float64   `json:"score"`
+	Description            string    `json:"description"`
+	TeacherName            string    `json:"teacher_name"`
+	CreatedAt              time.Time `json:"created_at"`
 }
 
 // ChildGradeListResponse represents paginated grade list
@@ -68,6 +76,7 @@ type ChildGradeListResponse struct {
 }
 
 // GradeSummaryResponse represents grade summary for a child
+// The embedded report card holds the weighted subject averages of one semester.
 type GradeSummaryResponse struct {
 	StudentID    uint    `json:"student_id"`
 	StudentName  string  `json:"student_name"`
@@ -75,6 +84,7 @@ type GradeSummaryResponse struct {
 	AverageScore float64 `json:"average_score"`
 	HighestScore float64 `json:"highest_score"`
 	LowestScore  float64 `json:"lowest_score"`
+	gradebook.Summary
 }
 
 // ==================== Homeroom Note DTOs ====================
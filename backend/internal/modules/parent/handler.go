@@ -7,6 +7,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/school-management/backend/internal/middleware"
+	"github.com/school-management/backend/internal/shared/gradebook"
 )
 
 // Handler handles HTTP requests for parent API
@@ -295,6 +296,8 @@ func (h *Handler) GetChildGrades(c *fiber.Ctx) error {
 // @Tags Parent
 // @Produce json
 // @Param id path int true "Student ID"
+// @Param academic_year query string false "Academic year, defaults to the school's current one"
+// @Param semester query int false "Semester (1 or 2), defaults to the school's current one"
 // @Success 200 {object} GradeSummaryResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -324,7 +327,12 @@ func (h *Handler) GetChildGradeSummary(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.service.GetChildGradeSummary(c.Context(), userID, uint(studentID))
+	period := gradebook.Period{
+		AcademicYear: c.Query("academic_year"),
+		Semester:     c.QueryInt("semester"),
+	}
+
+	response, err := h.service.GetChildGradeSummary(c.Context(), userID, uint(studentID), period)
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
 )
 
 var (
@@ -32,7 +33,7 @@ type Repository interface {
 
 	// Grade operations
 	GetStudentGrades(ctx context.Context, studentID uint, page, pageSize int) ([]models.Grade, int64, error)
-	GetGradeSummary(ctx context.Context, studentID uint) (*GradeSummaryResponse, error)
+	GetGradeSummary(ctx context.Context, studentID uint, period gradebook.Period) (*GradeSummaryResponse, error)
 
 	// Homeroom note operations
 	GetStudentNotes(ctx context.Context, studentID uint, page, pageSize int) ([]models.HomeroomNote, int64, error)
@@ -219,6 +220,8 @@ func (r *repository) GetStudentGrades(ctx context.Context, studentID uint, page,
 
 	offset := (page - 1) * pageSize
 	err := query.
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
 		Order("created_at DESC").
 		Offset(offset).
@@ -233,7 +236,8 @@ func (r *repository) GetStudentGrades(ctx context.Context, studentID uint, page,
 }
 
 // GetGradeSummary retrieves grade summary for a student
-func (r *repository) GetGradeSummary(ctx context.Context, studentID uint) (*GradeSummaryResponse, error) {
+// The report card is computed for the given period, defaulting to the school's current one.
+func (r *repository) GetGradeSummary(ctx context.Context, studentID uint, period gradebook.Period) (*GradeSummaryResponse, error) {
 	var student models.Student
 	if err := r.db.WithContext(ctx).First(&student, studentID).Error; err != nil {
 		return nil, err
@@ -255,6 +259,16 @@ func (r *repository) GetGradeSummary(ctx context.Context, studentID uint) (*Grad
 		return nil, err
 	}
 
+	period, err = gradebook.ResolvePeriod(ctx, r.db, student.SchoolID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := gradebook.StudentSummary(ctx, r.db, studentID, period)
+	if err != nil {
+		return nil, err
+	}
+
 	return &GradeSummaryResponse{
 		StudentID:    studentID,
 		StudentName:  student.Name,
@@ -262,6 +276,7 @@ func (r *repository) GetGradeSummary(ctx context.Context, studentID uint) (*Grad
 		AverageScore: result.Average,
 		HighestScore: result.Highest,
 		LowestScore:  result.Lowest,
+		Summary:      *report,
 	}, nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
 )
 
 // Service defines the interface for parent business logic
@@ -19,7 +20,7 @@ type Service interface {
 
 	// Grade operations
 	GetChildGrades(ctx context.Context, userID, studentID uint, filter GradeFilter) (*ChildGradeListResponse, error)
-	GetChildGradeSummary(ctx context.Context, userID, studentID uint) (*GradeSummaryResponse, error)
+	GetChildGradeSummary(ctx context.Context, userID, studentID uint, period gradebook.Period) (*GradeSummaryResponse, error)
 
 	// Homeroom note operations
 	GetChildNotes(ctx context.Context, userID, studentID uint, filter NoteFilter) (*ChildNoteListResponse, error)
@@ -80,7 +81,7 @@ func (s *service) GetChildDashboard(ctx context.Context, userID, studentID uint)
 	}
 
 	// Get grade summary
-	gradeSummary, _ := s.repo.GetGradeSummary(ctx, studentID)
+	gradeSummary, _ := s.repo.GetGradeSummary(ctx, studentID, gradebook.Period{})
 	if gradeSummary == nil {
 		gradeSummary = &GradeSummaryResponse{StudentID: studentID, StudentName: student.Name}
 	}
@@ -222,12 +223,12 @@ func (s *service) GetChildGrades(ctx context.Context, userID, studentID uint, fi
 }
 
 // GetChildGradeSummary retrieves grade summary for a child
-func (s *service) GetChildGradeSummary(ctx context.Context, userID, studentID uint) (*GradeSummaryResponse, error) {
+func (s *service) GetChildGradeSummary(ctx context.Context, userID, studentID uint, period gradebook.Period) (*GradeSummaryResponse, error) {
 	if err := s.validateAccess(ctx, userID, studentID); err != nil {
 		return nil, err
 	}
 
-	return s.repo.GetGradeSummary(ctx, studentID)
+	return s.repo.GetGradeSummary(ctx, studentID, period)
 }
 
 // GetChildNotes retrieves homeroom notes for a child
@@ -382,14 +383,24 @@ func toChildGradeResponse(g *models.Grade) ChildGradeResponse {
 		}
 	}
 
-	return ChildGradeResponse{
-		ID:          g.ID,
-		Title:       g.Title,
-		Score:       g.Score,
-		Description: g.Description,
-		TeacherName: teacherName,
-		CreatedAt:   g.CreatedAt,
+	response := ChildGradeResponse{
+		ID:           g.ID,
+		AcademicYear: g.AcademicYear,
+		Semester:     g.Semester,
+		Title:        g.Title,
+		Score:        g.Score,
+		Description:  g.Description,
+		TeacherName:  teacherName,
+		CreatedAt:    g.CreatedAt,
+	}
+	if g.Subject != nil {
+		response.SubjectName = g.Subject.Name
 	}
+	if g.AssessmentCategory != nil {
+		response.AssessmentCategoryName = g.AssessmentCategory.Name
+	}
+
+	return response
 }
 
 func toChildNoteResponse(n *models.HomeroomNote) ChildNoteResponse {
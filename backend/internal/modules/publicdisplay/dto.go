@@ -28,6 +28,8 @@ type PublicAttendanceStats struct {
 	VeryLate      int     `json:"very_late"`
 	Absent        int     `json:"absent"`
 	Percentage    float64 `json:"percentage"`
+	IsHoliday     bool    `json:"is_holiday"`
+	HolidayName   string  `json:"holiday_name,omitempty"`
 }
 
 // PublicLiveFeedEntry represents a single entry in the public live feed
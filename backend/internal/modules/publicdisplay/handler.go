@@ -3,12 +3,15 @@ package publicdisplay
 import (
 	"context"
 	"encoding/json"
+	"log"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
 
+	"github.com/school-management/backend/internal/middleware"
 	"github.com/school-management/backend/internal/modules/realtime"
+	"github.com/school-management/backend/internal/shared/ratelimit"
 )
 
 // Handler handles HTTP and WebSocket requests for public display
@@ -16,21 +19,25 @@ type Handler struct {
 	service        Service
 	realtimeHub    *realtime.Hub
 	realtimeRepo   realtime.Repository
+	limiter        *ratelimit.Limiter
 }
 
 // NewHandler creates a new public display handler
-func NewHandler(service Service, realtimeHub *realtime.Hub, realtimeRepo realtime.Repository) *Handler {
+// The limiter locks out addresses that present invalid display tokens too often.
+func NewHandler(service Service, realtimeHub *realtime.Hub, realtimeRepo realtime.Repository, limiter *ratelimit.Limiter) *Handler {
 	return &Handler{
 		service:        service,
 		realtimeHub:    realtimeHub,
 		realtimeRepo:   realtimeRepo,
+		limiter:        limiter,
 	}
 }
 
 // RegisterPublicRoutes registers public display routes (no auth required)
 // Requirements: 5.3 - Accessing public display URL with valid token SHALL show attendance data without login
 func (h *Handler) RegisterPublicRoutes(router fiber.Router) {
-	public := router.Group("/public/display")
+	// Display tokens are long random strings; guessing them gets the address locked
+	public := router.Group("/public/display", middleware.RateLimit(h.limiter, middleware.ClientIP))
 
 	// REST endpoint for public display data
 	// GET /api/v1/public/display/:token
@@ -40,6 +47,7 @@ func (h *Handler) RegisterPublicRoutes(router fiber.Router) {
 	public.Use("/:token/ws", func(c *fiber.Ctx) error {
 		if websocket.IsWebSocketUpgrade(c) {
 			c.Locals("allowed", true)
+			c.Locals("ip", c.IP()) // The WebSocket handler counts invalid tokens per address
 			return c.Next()
 		}
 		return fiber.ErrUpgradeRequired
@@ -111,6 +119,11 @@ func (h *Handler) HandlePublicWebSocket(c *websocket.Conn) {
 		if validation != nil && validation.Error != "" {
 			errorMsg = validation.Error
 		}
+		if ip, ok := c.Locals("ip").(string); ok {
+			if _, failErr := h.limiter.Fail(context.Background(), ip); failErr != nil {
+				log.Printf("Public display: failed to record invalid token from %s: %v", ip, failErr)
+			}
+		}
 		h.sendPublicWSError(c, "TOKEN_INVALID", errorMsg)
 		c.Close()
 		return
@@ -248,6 +261,8 @@ func (h *Handler) transformForPublicDisplay(message []byte) []byte {
 			VeryLate:      event.Stats.VeryLate,
 			Absent:        event.Stats.Absent,
 			Percentage:    event.Stats.Percentage,
+			IsHoliday:     event.Stats.IsHoliday,
+			HolidayName:   event.Stats.HolidayName,
 		}
 	}
 
@@ -7,6 +7,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/calendar"
 )
 
 // Repository defines the interface for public display data operations
@@ -164,6 +165,17 @@ func (r *repository) GetPublicStats(ctx context.Context, schoolID uint, date tim
 		stats.Absent = 0
 	}
 
+	// Nobody is absent on a holiday
+	holiday, err := calendar.FindHoliday(ctx, r.db, schoolID, dateOnly)
+	if err != nil {
+		return nil, err
+	}
+	if holiday != nil {
+		stats.IsHoliday = true
+		stats.HolidayName = holiday.Name
+		stats.Absent = 0
+	}
+
 	// Calculate percentage
 	if totalStudents > 0 {
 		stats.Percentage = float64(presentCount) / float64(totalStudents) * 100
@@ -98,6 +98,8 @@ func (s *service) GetPublicDisplayData(ctx context.Context, token string) (*Publ
 			VeryLate:      stats.VeryLate,
 			Absent:        stats.Absent,
 			Percentage:    stats.Percentage,
+			IsHoliday:     stats.IsHoliday,
+			HolidayName:   stats.HolidayName,
 		},
 		LiveFeed:    liveFeed,
 		Leaderboard: leaderboard,
@@ -0,0 +1,296 @@
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// schoolChannelPrefix is the prefix of the Redis channel carrying a school's broadcasts
+	schoolChannelPrefix = "realtime:school:"
+	// nodesKey is the sorted set of hub instances, scored by their last sync time
+	nodesKey = "realtime:nodes"
+	// nodeKeyPrefix is the prefix of the hash of client counts per school of an instance
+	nodeKeyPrefix = "realtime:node:"
+
+	nodeSyncInterval = 10 * time.Second
+	nodeTTL          = 3 * nodeSyncInterval // An instance missing three syncs is considered gone
+	redisTimeout     = 2 * time.Second
+)
+
+// Cluster message types
+const (
+	clusterMessageAttendance = "attendance"
+	clusterMessageEvent      = "event"
+)
+
+// clusterMessage is a hub broadcast published to the other instances
+type clusterMessage struct {
+	Type     string           `json:"type"`
+	SchoolID uint             `json:"school_id"`
+	Event    *AttendanceEvent `json:"event,omitempty"`
+	Envelope *EventEnvelope   `json:"envelope,omitempty"`
+}
+
+// NodeClientCount represents the clients of a school connected to one instance
+type NodeClientCount struct {
+	NodeID  string `json:"node_id"`
+	Clients int    `json:"clients"`
+}
+
+// newNodeID returns an identifier for this hub instance
+func newNodeID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "node"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// NodeID returns the identifier of this hub instance
+func (h *Hub) NodeID() string {
+	return h.nodeID
+}
+
+// Stop stops the cluster fan-out; the hub keeps serving the clients of this instance
+// The instance's client counts are removed so other instances stop counting them.
+func (h *Hub) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.stopCh)
+		h.wg.Wait()
+
+		if h.redis != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+			defer cancel()
+			if err := h.redis.Delete(ctx, nodeKeyPrefix+h.nodeID); err != nil {
+				log.Printf("Realtime hub: failed to remove node %s: %v", h.nodeID, err)
+			}
+		}
+	})
+}
+
+// publish sends a broadcast to every instance, including this one
+// It returns false if Redis is unavailable, so the caller can deliver locally instead.
+func (h *Hub) publish(schoolID uint, msg *clusterMessage) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if err := h.redis.Publish(ctx, schoolChannel(schoolID), msg); err != nil {
+		log.Printf("Realtime hub: failed to publish to school %d, delivering locally: %v", schoolID, err)
+		return false
+	}
+	return true
+}
+
+// subscribeLoop delivers broadcasts published by any instance to the clients of this one
+// Messages for schools without local clients are dropped by the delivery functions.
+func (h *Hub) subscribeLoop() {
+	defer h.wg.Done()
+
+	pubsub := h.redis.PSubscribe(context.Background(), schoolChannelPrefix+"*")
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-h.stopCh:
+			return
+		case raw, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			var msg clusterMessage
+			if err := json.Unmarshal([]byte(raw.Payload), &msg); err != nil {
+				log.Printf("Realtime hub: ignoring malformed message on %s: %v", raw.Channel, err)
+				continue
+			}
+
+			switch msg.Type {
+			case clusterMessageAttendance:
+				if msg.Event == nil {
+					continue
+				}
+				select {
+				case h.broadcast <- msg.Event:
+				case <-h.stopCh:
+					return
+				}
+			case clusterMessageEvent:
+				if msg.Envelope == nil {
+					continue
+				}
+				select {
+				case h.events <- msg.Envelope:
+				case <-h.stopCh:
+					return
+				}
+			}
+		}
+	}
+}
+
+// syncLoop publishes this instance's client counts until the hub stops
+func (h *Hub) syncLoop() {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(nodeSyncInterval)
+	defer ticker.Stop()
+
+	// Schools reported before; they are reset to zero once their last client leaves
+	reported := make(map[uint]bool)
+	h.syncCounts(reported)
+
+	for {
+		select {
+		case <-h.stopCh:
+			return
+		case <-ticker.C:
+			h.syncCounts(reported)
+		}
+	}
+}
+
+// syncCounts stores this instance's client count per school in Redis
+func (h *Hub) syncCounts(reported map[uint]bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	values := make(map[string]interface{})
+	h.mu.RLock()
+	for schoolID, clients := range h.clients {
+		values[strconv.FormatUint(uint64(schoolID), 10)] = len(clients)
+		reported[schoolID] = true
+	}
+	h.mu.RUnlock()
+
+	for schoolID := range reported {
+		field := strconv.FormatUint(uint64(schoolID), 10)
+		if _, ok := values[field]; !ok {
+			values[field] = 0
+			delete(reported, schoolID)
+		}
+	}
+
+	now := time.Now()
+	key := nodeKeyPrefix + h.nodeID
+	if len(values) > 0 {
+		if err := h.redis.HSet(ctx, key, values); err != nil {
+			log.Printf("Realtime hub: failed to store client counts: %v", err)
+			return
+		}
+	}
+	if err := h.redis.Expire(ctx, key, nodeTTL); err != nil {
+		log.Printf("Realtime hub: failed to refresh client counts: %v", err)
+	}
+	if err := h.redis.ZAdd(ctx, nodesKey, float64(now.Unix()), h.nodeID); err != nil {
+		log.Printf("Realtime hub: failed to register node: %v", err)
+	}
+	if err := h.redis.ZRemRangeByScore(ctx, nodesKey, "-inf", strconv.FormatInt(now.Add(-nodeTTL).Unix(), 10)); err != nil {
+		log.Printf("Realtime hub: failed to prune nodes: %v", err)
+	}
+}
+
+// liveNodes returns the instances that synced their client counts recently
+func (h *Hub) liveNodes(ctx context.Context) ([]string, error) {
+	minScore := strconv.FormatInt(time.Now().Add(-nodeTTL).Unix(), 10)
+	return h.redis.ZRangeByScore(ctx, nodesKey, minScore, "+inf")
+}
+
+// GetNodeClientCounts returns the clients of a school connected to each instance
+// This instance reports its live count; other instances report their last sync.
+func (h *Hub) GetNodeClientCounts(ctx context.Context, schoolID uint) ([]NodeClientCount, error) {
+	counts := []NodeClientCount{{NodeID: h.nodeID, Clients: h.GetLocalClientCount(schoolID)}}
+	if h.redis == nil {
+		return counts, nil
+	}
+
+	nodes, err := h.liveNodes(ctx)
+	if err != nil {
+		return counts, err
+	}
+
+	field := strconv.FormatUint(uint64(schoolID), 10)
+	for _, node := range nodes {
+		if node == h.nodeID {
+			continue
+		}
+		value, err := h.redis.HGet(ctx, nodeKeyPrefix+node, field)
+		if err != nil {
+			return counts, err
+		}
+		clients, _ := strconv.Atoi(value)
+		if clients > 0 {
+			counts = append(counts, NodeClientCount{NodeID: node, Clients: clients})
+		}
+	}
+	return counts, nil
+}
+
+// GetClientCount returns the number of connected clients for a school across all instances
+// It falls back to this instance's clients when Redis is unavailable.
+func (h *Hub) GetClientCount(schoolID uint) int {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	counts, err := h.GetNodeClientCounts(ctx, schoolID)
+	if err != nil {
+		log.Printf("Realtime hub: failed to load client counts: %v", err)
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count.Clients
+	}
+	return total
+}
+
+// GetTotalClientCount returns the total number of connected clients across all instances
+// It falls back to this instance's clients when Redis is unavailable.
+func (h *Hub) GetTotalClientCount() int {
+	total := h.GetLocalTotalClientCount()
+	if h.redis == nil {
+		return total
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	nodes, err := h.liveNodes(ctx)
+	if err != nil {
+		log.Printf("Realtime hub: failed to load nodes: %v", err)
+		return total
+	}
+
+	for _, node := range nodes {
+		if node == h.nodeID {
+			continue
+		}
+		values, err := h.redis.HGetAll(ctx, nodeKeyPrefix+node)
+		if err != nil {
+			log.Printf("Realtime hub: failed to load client counts of node %s: %v", node, err)
+			continue
+		}
+		for _, value := range values {
+			clients, _ := strconv.Atoi(value)
+			total += clients
+		}
+	}
+	return total
+}
+
+// schoolChannel returns the Redis channel of a school's broadcasts
+func schoolChannel(schoolID uint) string {
+	return schoolChannelPrefix + strconv.FormatUint(uint64(schoolID), 10)
+}
@@ -15,6 +15,7 @@ const (
 	EventTypeNewAttendance EventType = "new_attendance"
 	EventTypeStatsUpdate   EventType = "stats_update"
 	EventTypeLeaderboard   EventType = "leaderboard_update"
+	EventTypePairingStatus EventType = "pairing_status"
 )
 
 // ==================== Real-Time DTOs ====================
@@ -62,6 +63,8 @@ type AttendanceStats struct {
 	VeryLate      int     `json:"very_late"`
 	Absent        int     `json:"absent"`
 	Percentage    float64 `json:"percentage"` // (present / total_students) * 100
+	IsHoliday     bool    `json:"is_holiday"`
+	HolidayName   string  `json:"holiday_name,omitempty"`
 }
 
 // ==================== Request/Response DTOs ====================
@@ -83,8 +86,18 @@ type LeaderboardResponse struct {
 	Date        string             `json:"date"` // Format: YYYY-MM-DD
 }
 
+// ConnectionStatsResponse represents the WebSocket clients of a school on each API instance
+type ConnectionStatsResponse struct {
+	SchoolID uint              `json:"school_id"`
+	Total    int               `json:"total"`
+	Nodes    []NodeClientCount `json:"nodes"`
+}
+
 // ==================== WebSocket DTOs ====================
 
+// wsMessageEvent is the WSMessage type of topic events
+const wsMessageEvent = "event"
+
 // WSMessage represents a WebSocket message
 type WSMessage struct {
 	Type    string      `json:"type"`
@@ -96,6 +109,18 @@ type WSSubscribeRequest struct {
 	ClassID *uint `json:"class_id,omitempty"` // Optional filter by class
 }
 
+// WSTopicRequest represents a topic subscribe or unsubscribe request on /api/v1/ws
+type WSTopicRequest struct {
+	Topic   Topic `json:"topic"`
+	ClassID *uint `json:"class_id,omitempty"` // Required for attendance.class, except for wali kelas
+}
+
+// WSTopicResponse confirms a topic subscription change
+type WSTopicResponse struct {
+	Topic   Topic `json:"topic"`
+	ClassID *uint `json:"class_id,omitempty"` // Class the subscription is limited to
+}
+
 // WSConnectionStatus represents connection status
 type WSConnectionStatus struct {
 	Connected bool   `json:"connected"`
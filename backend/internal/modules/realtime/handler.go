@@ -1,7 +1,9 @@
 package realtime
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"strconv"
 	"strings"
 	"time"
@@ -9,7 +11,9 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
 
+	"github.com/school-management/backend/internal/domain/models"
 	"github.com/school-management/backend/internal/modules/auth"
+	"github.com/school-management/backend/internal/policy"
 )
 
 // Handler handles HTTP and WebSocket requests for real-time attendance
@@ -32,6 +36,7 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 	router.Get("/live-feed", h.GetLiveFeed)
 	router.Get("/stats", h.GetStats)
 	router.Get("/leaderboard", h.GetLeaderboard)
+	router.Get("/connections", h.GetConnections)
 }
 
 // RegisterWebSocketRoutes registers WebSocket routes
@@ -52,6 +57,12 @@ func (h *Handler) RegisterWebSocketRoutes(app *fiber.App) {
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 	}))
+
+	// WebSocket endpoint for topic subscriptions (attendance, notifications, BK, devices)
+	app.Get("/api/v1/ws", websocket.New(h.HandleTopicWebSocket, websocket.Config{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+	}))
 }
 
 // GetLiveFeed handles getting live attendance feed
@@ -200,10 +211,123 @@ func (h *Handler) GetLeaderboard(c *fiber.Ctx) error {
 	})
 }
 
+// GetConnections handles getting the connected WebSocket clients
+// @Summary Get real-time connections
+// @Description Get the number of WebSocket clients of the school connected to each API instance
+// @Tags Real-Time
+// @Produce json
+// @Success 200 {object} ConnectionStatsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/realtime/connections [get]
+func (h *Handler) GetConnections(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	response, err := h.service.GetConnectionStats(c.Context(), schoolID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "INTERNAL_ERROR",
+				"message": "Gagal mengambil data koneksi real-time",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
 // HandleWebSocket handles WebSocket connections for real-time updates
 // Requirements: 4.8 - THE System SHALL use WebSocket for real-time updates
 // Requirements: 4.9 - IF connection is lost, THE System SHALL attempt to reconnect automatically
 func (h *Handler) HandleWebSocket(c *websocket.Conn) {
+	claims, ok := h.authenticate(c)
+	if !ok {
+		return
+	}
+
+	// Create client
+	hub := h.service.GetHub()
+	client := &Client{
+		Hub:      hub,
+		Conn:     c,
+		Send:     make(chan []byte, 256),
+		SchoolID: *claims.SchoolID,
+		UserID:   claims.UserID,
+		Role:     claims.Role,
+		IsPublic: false,
+	}
+
+	// Register client
+	hub.Register(client)
+
+	// Send connection success message
+	h.sendWSMessage(c, "connected", WSConnectionStatus{
+		Connected: true,
+		Message:   "Terhubung ke server real-time",
+	})
+
+	// Start goroutines for reading and writing
+	go h.writePump(client)
+	h.readPump(client)
+}
+
+// HandleTopicWebSocket handles WebSocket connections with topic subscriptions
+// Clients send {"type":"subscribe","payload":{"topic":"attendance.class","class_id":1}} and
+// receive {"type":"event","payload":EventEnvelope} for every event of their topics. Each
+// subscription is checked against the access policy, so a wali kelas only receives the
+// events of their own class. The user's own notifications are subscribed on connect.
+func (h *Handler) HandleTopicWebSocket(c *websocket.Conn) {
+	claims, ok := h.authenticate(c)
+	if !ok {
+		return
+	}
+
+	hub := h.service.GetHub()
+	client := &Client{
+		Hub:        hub,
+		Conn:       c,
+		Send:       make(chan []byte, 256),
+		SchoolID:   *claims.SchoolID,
+		UserID:     claims.UserID,
+		Role:       claims.Role,
+		UsesTopics: true,
+	}
+	client.subscribe(TopicNotifications, nil)
+
+	user := &policy.UserContext{
+		UserID:   claims.UserID,
+		SchoolID: claims.SchoolID,
+		Role:     models.UserRole(claims.Role),
+	}
+
+	hub.Register(client)
+
+	h.sendWSMessage(c, "connected", WSConnectionStatus{
+		Connected: true,
+		Message:   "Terhubung ke server real-time",
+	})
+
+	go h.writePump(client)
+	h.readTopicPump(client, user)
+}
+
+// authenticate validates the access token of a WebSocket connection
+// The connection is closed with an error message when the token is missing or invalid.
+func (h *Handler) authenticate(c *websocket.Conn) (*auth.TokenClaims, bool) {
 	// Get token from query parameter or header
 	token := c.Query("token")
 	if token == "" {
@@ -215,7 +339,7 @@ func (h *Handler) HandleWebSocket(c *websocket.Conn) {
 	if token == "" {
 		h.sendWSError(c, "AUTH_TOKEN_MISSING", "Token diperlukan")
 		c.Close()
-		return
+		return nil, false
 	}
 
 	// Remove "Bearer " prefix if present
@@ -226,39 +350,84 @@ func (h *Handler) HandleWebSocket(c *websocket.Conn) {
 	if err != nil {
 		h.sendWSError(c, "AUTH_TOKEN_INVALID", "Token tidak valid")
 		c.Close()
-		return
+		return nil, false
+	}
+
+	// Sessions started with a password reset code have to set a new password first
+	if claims.ResetRequired {
+		h.sendWSError(c, "AUTH_PASSWORD_CHANGE_REQUIRED", "Buat password baru terlebih dahulu")
+		c.Close()
+		return nil, false
 	}
 
 	// Get school ID from claims
 	if claims.SchoolID == nil {
 		h.sendWSError(c, "AUTHZ_TENANT_REQUIRED", "Konteks sekolah diperlukan")
 		c.Close()
-		return
+		return nil, false
 	}
 
-	// Create client
-	hub := h.service.GetHub()
-	client := &Client{
-		Hub:      hub,
-		Conn:     c,
-		Send:     make(chan []byte, 256),
-		SchoolID: *claims.SchoolID,
-		UserID:   claims.UserID,
-		IsPublic: false,
-	}
+	return claims, true
+}
 
-	// Register client
-	hub.Register(client)
+// readTopicPump handles subscription requests of a topic client until it disconnects
+func (h *Handler) readTopicPump(client *Client, user *policy.UserContext) {
+	defer func() {
+		client.Hub.Unregister(client)
+		client.Conn.Close()
+	}()
 
-	// Send connection success message
-	h.sendWSMessage(c, "connected", WSConnectionStatus{
-		Connected: true,
-		Message:   "Terhubung ke server real-time",
-	})
+	for {
+		_, message, err := client.Conn.ReadMessage()
+		if err != nil {
+			break
+		}
 
-	// Start goroutines for reading and writing
-	go h.writePump(client)
-	h.readPump(client)
+		var msg struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(message, &msg); err != nil {
+			continue
+		}
+
+		switch msg.Type {
+		case "subscribe", "unsubscribe":
+			var req WSTopicRequest
+			if len(msg.Payload) == 0 || json.Unmarshal(msg.Payload, &req) != nil {
+				h.sendWSError(client.Conn, "VAL_INVALID_FORMAT", "Format permintaan tidak valid")
+				continue
+			}
+			if !req.Topic.IsValid() {
+				h.sendTopicError(client.Conn, req.Topic, ErrUnknownTopic)
+				continue
+			}
+
+			if msg.Type == "unsubscribe" {
+				client.unsubscribe(req.Topic)
+				h.sendWSMessage(client.Conn, "unsubscribed", WSTopicResponse{Topic: req.Topic})
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			classID, err := h.service.AuthorizeTopic(ctx, user, req.Topic, req.ClassID)
+			cancel()
+			if err != nil {
+				h.sendTopicError(client.Conn, req.Topic, err)
+				continue
+			}
+
+			client.subscribe(req.Topic, classID)
+			h.sendWSMessage(client.Conn, "subscribed", WSTopicResponse{
+				Topic:   req.Topic,
+				ClassID: classID,
+			})
+		case "ping":
+			h.sendWSMessage(client.Conn, "pong", map[string]interface{}{
+				"timestamp": time.Now().Unix(),
+			})
+		}
+	}
 }
 
 // readPump pumps messages from the WebSocket connection to the hub
@@ -353,6 +522,30 @@ func (h *Handler) sendWSError(c *websocket.Conn, code string, message string) {
 	})
 }
 
+// sendTopicError sends the error of a rejected topic subscription
+func (h *Handler) sendTopicError(c *websocket.Conn, topic Topic, err error) {
+	code := "INTERNAL_ERROR"
+	message := "Gagal memproses langganan topik"
+
+	switch {
+	case errors.Is(err, ErrUnknownTopic):
+		code = "VAL_INVALID_TOPIC"
+		message = err.Error()
+	case errors.Is(err, ErrTopicClassRequired):
+		code = "VAL_REQUIRED_FIELD"
+		message = err.Error()
+	case errors.Is(err, ErrTopicAccessDenied), errors.Is(err, ErrNoAssignedClass):
+		code = "AUTHZ_TOPIC_DENIED"
+		message = err.Error()
+	}
+
+	h.sendWSMessage(c, "error", map[string]string{
+		"code":    code,
+		"message": message,
+		"topic":   string(topic),
+	})
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Success bool `json:"success"`
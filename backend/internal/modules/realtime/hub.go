@@ -2,9 +2,13 @@ package realtime
 
 import (
 	"encoding/json"
+	"log"
 	"sync"
+	"time"
 
 	"github.com/gofiber/websocket/v2"
+
+	"github.com/school-management/backend/internal/shared/redis"
 )
 
 // Client represents a WebSocket client connection
@@ -17,11 +21,22 @@ type Client struct {
 	IsPublic bool   // For public display
 	Token    string // Display token for public
 	UserID   uint   // User ID for authenticated clients
+	Role     string // User role for authenticated clients
+
+	// UsesTopics is set for clients of /api/v1/ws, which receive EventEnvelope messages for
+	// their topic subscriptions instead of raw attendance events
+	UsesTopics bool
+	topics     map[Topic]subscription
+	topicsMu   sync.RWMutex
 }
 
 // Hub maintains the set of active clients and broadcasts messages to them
 // Requirements: 4.2 - Broadcast to school-specific clients
 // Requirements: 4.5 - Filter by class_id if specified
+//
+// With a Redis client, broadcasts are published on a Redis channel per school and every
+// instance delivers them to its own clients (see cluster.go). Without one, broadcasts only
+// reach clients of this process.
 type Hub struct {
 	// Registered clients grouped by school ID
 	clients map[uint]map[*Client]bool
@@ -29,6 +44,9 @@ type Hub struct {
 	// Broadcast channel for attendance events
 	broadcast chan *AttendanceEvent
 
+	// Events for topic subscribers
+	events chan *EventEnvelope
+
 	// Register requests from clients
 	register chan *Client
 
@@ -37,20 +55,38 @@ type Hub struct {
 
 	// Mutex for thread-safe operations
 	mu sync.RWMutex
+
+	// Cluster fan-out, nil when running as a single instance
+	redis    *redis.Client
+	nodeID   string
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
 }
 
 // NewHub creates a new Hub instance
-func NewHub() *Hub {
+// redisClient may be nil to keep broadcasts local to this process.
+func NewHub(redisClient *redis.Client) *Hub {
 	return &Hub{
 		clients:    make(map[uint]map[*Client]bool),
 		broadcast:  make(chan *AttendanceEvent, 256),
+		events:     make(chan *EventEnvelope, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		redis:      redisClient,
+		nodeID:     newNodeID(),
+		stopCh:     make(chan struct{}),
 	}
 }
 
 // Run starts the hub's main loop
 func (h *Hub) Run() {
+	if h.redis != nil {
+		h.wg.Add(2)
+		go h.subscribeLoop()
+		go h.syncLoop()
+	}
+
 	for {
 		select {
 		case client := <-h.register:
@@ -59,6 +95,8 @@ func (h *Hub) Run() {
 			h.unregisterClient(client)
 		case event := <-h.broadcast:
 			h.broadcastEvent(event)
+		case event := <-h.events:
+			h.deliverEvent(event)
 		}
 	}
 }
@@ -107,14 +145,70 @@ func (h *Hub) broadcastEvent(event *AttendanceEvent) {
 		return
 	}
 
+	// Topic subscribers receive the event as attendance.school or, without the school-wide
+	// stats and leaderboard, as attendance.class
+	schoolEvent, classEvent := attendanceEnvelopes(event)
+	var schoolMessage, classMessage []byte
+
 	for client := range clients {
-		// Filter by class if client has class filter and event has attendance data
-		if client.ClassID != nil && event.Attendance != nil {
+		data := message
+		if client.UsesTopics {
+			switch {
+			case client.matches(schoolEvent):
+				if schoolMessage == nil {
+					if schoolMessage, err = encodeEnvelope(schoolEvent); err != nil {
+						return
+					}
+				}
+				data = schoolMessage
+			case classEvent != nil && client.matches(classEvent):
+				if classMessage == nil {
+					if classMessage, err = encodeEnvelope(classEvent); err != nil {
+						return
+					}
+				}
+				data = classMessage
+			default:
+				continue
+			}
+		} else if client.ClassID != nil && event.Attendance != nil {
+			// Filter by class if client has class filter and event has attendance data
 			if event.Attendance.ClassID != *client.ClassID {
 				continue
 			}
 		}
 
+		select {
+		case client.Send <- data:
+		default:
+			// Client's send buffer is full, close connection
+			h.mu.RUnlock()
+			h.unregisterClient(client)
+			h.mu.RLock()
+		}
+	}
+}
+
+// deliverEvent sends a topic event to the subscribed clients of its school
+// Public display and attendance-only clients never receive topic events.
+func (h *Hub) deliverEvent(event *EventEnvelope) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var message []byte
+	for client := range h.clients[event.SchoolID] {
+		if client.IsPublic || !client.UsesTopics || !client.matches(event) {
+			continue
+		}
+
+		if message == nil {
+			var err error
+			if message, err = encodeEnvelope(event); err != nil {
+				log.Printf("Realtime hub: failed to encode %s event: %v", event.Type, err)
+				return
+			}
+		}
+
 		select {
 		case client.Send <- message:
 		default:
@@ -136,13 +230,31 @@ func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
 
-// Broadcast sends an event to all relevant clients
+// Broadcast sends an event to all relevant clients on every instance
 func (h *Hub) Broadcast(event *AttendanceEvent) {
+	if h.redis != nil {
+		if h.publish(event.SchoolID, &clusterMessage{Type: clusterMessageAttendance, SchoolID: event.SchoolID, Event: event}) {
+			return
+		}
+	}
 	h.broadcast <- event
 }
 
-// GetClientCount returns the number of connected clients for a school
-func (h *Hub) GetClientCount(schoolID uint) int {
+// Publish sends a topic event to the subscribed clients on every instance
+func (h *Hub) Publish(event *EventEnvelope) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if h.redis != nil {
+		if h.publish(event.SchoolID, &clusterMessage{Type: clusterMessageEvent, SchoolID: event.SchoolID, Envelope: event}) {
+			return
+		}
+	}
+	h.events <- event
+}
+
+// GetLocalClientCount returns the number of clients of a school connected to this instance
+func (h *Hub) GetLocalClientCount(schoolID uint) int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
@@ -152,8 +264,8 @@ func (h *Hub) GetClientCount(schoolID uint) int {
 	return 0
 }
 
-// GetTotalClientCount returns the total number of connected clients
-func (h *Hub) GetTotalClientCount() int {
+// GetLocalTotalClientCount returns the total number of clients connected to this instance
+func (h *Hub) GetLocalTotalClientCount() int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
@@ -163,3 +275,45 @@ func (h *Hub) GetTotalClientCount() int {
 	}
 	return total
 }
+
+// attendanceEnvelopes wraps an attendance event for the attendance.school and
+// attendance.class topics; the class envelope is nil for events without attendance data
+func attendanceEnvelopes(event *AttendanceEvent) (*EventEnvelope, *EventEnvelope) {
+	now := time.Now()
+	schoolEvent := &EventEnvelope{
+		Topic:     TopicSchoolAttendance,
+		Type:      event.Type,
+		SchoolID:  event.SchoolID,
+		Data:      event,
+		Timestamp: now,
+	}
+	if event.Attendance == nil {
+		return schoolEvent, nil
+	}
+
+	classID := event.Attendance.ClassID
+	schoolEvent.ClassID = &classID
+	schoolEvent.StudentID = event.Attendance.StudentID
+
+	classEvent := &EventEnvelope{
+		Topic:     TopicClassAttendance,
+		Type:      event.Type,
+		SchoolID:  event.SchoolID,
+		ClassID:   &classID,
+		StudentID: event.Attendance.StudentID,
+		Data:      event.Attendance,
+		Timestamp: now,
+	}
+	return schoolEvent, classEvent
+}
+
+// encodeEnvelope encodes a topic event as a WebSocket message
+// Notification recipients are dropped so clients never learn who else was notified.
+func encodeEnvelope(event *EventEnvelope) ([]byte, error) {
+	envelope := *event
+	envelope.UserIDs = nil
+	return json.Marshal(WSMessage{
+		Type:    wsMessageEvent,
+		Payload: envelope,
+	})
+}
@@ -0,0 +1,124 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/outbox"
+)
+
+// OutboxPublisher delivers outbox events to the WebSocket hub: attendance to the dashboards,
+// BK records to bk.activity and device alerts to device.status
+// Requirements: 4.2 - WHEN a student taps RFID card, THE System SHALL update the dashboard within 3 seconds
+type OutboxPublisher struct {
+	service Service
+	repo    Repository
+}
+
+// NewOutboxPublisher creates a new realtime outbox publisher
+func NewOutboxPublisher(service Service, repo Repository) *OutboxPublisher {
+	return &OutboxPublisher{
+		service: service,
+		repo:    repo,
+	}
+}
+
+// Name identifies the publisher in the delivery record of an event
+func (p *OutboxPublisher) Name() string {
+	return "realtime"
+}
+
+// Publish broadcasts the record referenced by the event
+func (p *OutboxPublisher) Publish(ctx context.Context, event *models.OutboxEvent, payload *outbox.Payload) error {
+	switch event.EventType {
+	case outbox.EventViolationCreated, outbox.EventAchievementCreated, outbox.EventPermitCreated:
+		return p.publishBKActivity(ctx, event, payload)
+	case outbox.EventDeviceOffline:
+		p.publishDeviceOffline(event, payload)
+		return nil
+	}
+
+	if payload.AttendanceType == "" {
+		return nil
+	}
+
+	attendance, err := p.repo.FindAttendanceByID(ctx, event.AggregateID)
+	if err != nil {
+		// The record was deleted before the event was relayed; nothing to show
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Skipping realtime broadcast for missing attendance %d", event.AggregateID)
+			return nil
+		}
+		return err
+	}
+
+	p.service.BroadcastAttendance(ctx, payload.SchoolID, attendance, &attendance.Student, payload.AttendanceType)
+	return nil
+}
+
+// publishBKActivity publishes a new violation, achievement or permit on bk.activity
+// The event carries the student's class, so read-only subscribers only get their class.
+func (p *OutboxPublisher) publishBKActivity(ctx context.Context, event *models.OutboxEvent, payload *outbox.Payload) error {
+	student, err := p.repo.FindStudentByID(ctx, payload.StudentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Skipping realtime broadcast of %s %d for missing student %d", event.EventType, event.AggregateID, payload.StudentID)
+			return nil
+		}
+		return err
+	}
+
+	activity := &BKActivity{
+		ID:          event.AggregateID,
+		StudentID:   student.ID,
+		StudentName: student.Name,
+	}
+	if payload.Notification != nil {
+		activity.Title = payload.Notification.Title
+		activity.Message = payload.Notification.Message
+	}
+
+	envelope := &EventEnvelope{
+		Topic:     TopicBKActivity,
+		Type:      EventType(event.EventType),
+		SchoolID:  payload.SchoolID,
+		StudentID: student.ID,
+		Data:      activity,
+		Timestamp: event.CreatedAt,
+	}
+	if student.ClassID != nil {
+		classID := *student.ClassID
+		envelope.ClassID = &classID
+		activity.ClassID = classID
+		if student.Class != nil {
+			activity.ClassName = student.Class.Name
+		}
+	}
+
+	p.service.PublishEvent(envelope)
+	return nil
+}
+
+// publishDeviceOffline publishes an offline device alert on device.status
+func (p *OutboxPublisher) publishDeviceOffline(event *models.OutboxEvent, payload *outbox.Payload) {
+	status := &DeviceStatus{
+		DeviceID: event.AggregateID,
+		Online:   false,
+	}
+	if payload.Notification != nil {
+		status.Message = payload.Notification.Message
+		status.Details = payload.Notification.Data
+	}
+
+	p.service.PublishEvent(&EventEnvelope{
+		Topic:     TopicDeviceStatus,
+		Type:      EventTypeDeviceOffline,
+		SchoolID:  payload.SchoolID,
+		Data:      status,
+		Timestamp: event.CreatedAt,
+	})
+}
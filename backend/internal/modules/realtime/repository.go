@@ -7,6 +7,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/calendar"
 )
 
 // Repository defines the interface for real-time data operations
@@ -25,6 +26,12 @@ type Repository interface {
 
 	// GetTotalStudents retrieves total active students count
 	GetTotalStudents(ctx context.Context, schoolID uint, classID *uint) (int, error)
+
+	// FindAttendanceByID retrieves an attendance record with its student and class
+	FindAttendanceByID(ctx context.Context, id uint) (*models.Attendance, error)
+
+	// FindStudentByID retrieves a student with their class
+	FindStudentByID(ctx context.Context, id uint) (*models.Student, error)
 }
 
 // repository implements the Repository interface
@@ -162,6 +169,17 @@ func (r *repository) GetAttendanceStats(ctx context.Context, schoolID uint, clas
 		stats.Absent = 0
 	}
 
+	// Nobody is absent on a holiday
+	holiday, err := calendar.FindHoliday(ctx, r.db, schoolID, dateOnly)
+	if err != nil {
+		return nil, err
+	}
+	if holiday != nil {
+		stats.IsHoliday = true
+		stats.HolidayName = holiday.Name
+		stats.Absent = 0
+	}
+
 	// Calculate percentage
 	if totalStudents > 0 {
 		stats.Percentage = float64(presentCount) / float64(totalStudents) * 100
@@ -237,3 +255,30 @@ func (r *repository) GetTotalStudents(ctx context.Context, schoolID uint, classI
 	err := query.Count(&count).Error
 	return int(count), err
 }
+
+// FindAttendanceByID retrieves an attendance record with its student and class
+func (r *repository) FindAttendanceByID(ctx context.Context, id uint) (*models.Attendance, error) {
+	var attendance models.Attendance
+	err := r.db.WithContext(ctx).
+		Preload("Student").
+		Preload("Student.Class").
+		Where("id = ?", id).
+		First(&attendance).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attendance, nil
+}
+
+// FindStudentByID retrieves a student with their class
+func (r *repository) FindStudentByID(ctx context.Context, id uint) (*models.Student, error) {
+	var student models.Student
+	err := r.db.WithContext(ctx).
+		Preload("Class").
+		Where("id = ?", id).
+		First(&student).Error
+	if err != nil {
+		return nil, err
+	}
+	return &student, nil
+}
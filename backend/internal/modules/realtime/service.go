@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/policy"
 )
 
 // Service defines the interface for real-time operations
@@ -25,21 +26,39 @@ type Service interface {
 	// Requirements: 4.2 - Update dashboard within 3 seconds without page refresh
 	BroadcastAttendance(ctx context.Context, schoolID uint, attendance *models.Attendance, student *models.Student, attendanceType string)
 
+	// GetConnectionStats retrieves the connected WebSocket clients of a school per instance
+	GetConnectionStats(ctx context.Context, schoolID uint) (*ConnectionStatsResponse, error)
+
+	// BroadcastPairingStatus pushes an RFID pairing session change to the school admins
+	BroadcastPairingStatus(schoolID uint, status interface{})
+
+	// PushNotification pushes a stored notification to the recipient's WebSocket connections
+	PushNotification(schoolID, userID uint, notification interface{})
+
+	// PublishEvent sends a topic event to the subscribed clients
+	PublishEvent(event *EventEnvelope)
+
+	// AuthorizeTopic checks a topic subscription against the access policy
+	// It returns the class the subscription is limited to, nil for the whole school.
+	AuthorizeTopic(ctx context.Context, user *policy.UserContext, topic Topic, classID *uint) (*uint, error)
+
 	// GetHub returns the WebSocket hub
 	GetHub() *Hub
 }
 
 // service implements the Service interface
 type service struct {
-	repo Repository
-	hub  *Hub
+	repo         Repository
+	hub          *Hub
+	accessPolicy policy.AccessPolicy
 }
 
 // NewService creates a new real-time service
-func NewService(repo Repository, hub *Hub) Service {
+func NewService(repo Repository, hub *Hub, accessPolicy policy.AccessPolicy) Service {
 	return &service{
-		repo: repo,
-		hub:  hub,
+		repo:         repo,
+		hub:          hub,
+		accessPolicy: accessPolicy,
 	}
 }
 
@@ -139,6 +158,61 @@ func (s *service) BroadcastAttendance(ctx context.Context, schoolID uint, attend
 	s.hub.Broadcast(event)
 }
 
+// GetConnectionStats retrieves the connected WebSocket clients of a school per instance
+func (s *service) GetConnectionStats(ctx context.Context, schoolID uint) (*ConnectionStatsResponse, error) {
+	nodes, err := s.hub.GetNodeClientCounts(ctx, schoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, node := range nodes {
+		total += node.Clients
+	}
+
+	return &ConnectionStatsResponse{
+		SchoolID: schoolID,
+		Total:    total,
+		Nodes:    nodes,
+	}, nil
+}
+
+// BroadcastPairingStatus pushes an RFID pairing session change to the school admins
+// It is published on device.status, which only admins may subscribe to, since it carries
+// student names and card codes.
+func (s *service) BroadcastPairingStatus(schoolID uint, status interface{}) {
+	s.PublishEvent(&EventEnvelope{
+		Topic:    TopicDeviceStatus,
+		Type:     EventTypePairingStatus,
+		SchoolID: schoolID,
+		Data:     status,
+	})
+}
+
+// PushNotification pushes a stored notification to the recipient's WebSocket connections
+func (s *service) PushNotification(schoolID, userID uint, notification interface{}) {
+	s.PublishEvent(&EventEnvelope{
+		Topic:    TopicNotifications,
+		Type:     EventTypeNotification,
+		SchoolID: schoolID,
+		Data:     notification,
+		UserIDs:  []uint{userID},
+	})
+}
+
+// PublishEvent sends a topic event to the subscribed clients
+func (s *service) PublishEvent(event *EventEnvelope) {
+	if s.hub == nil {
+		return
+	}
+	s.hub.Publish(event)
+}
+
+// AuthorizeTopic checks a topic subscription against the access policy
+func (s *service) AuthorizeTopic(ctx context.Context, user *policy.UserContext, topic Topic, classID *uint) (*uint, error) {
+	return authorizeTopic(ctx, s.accessPolicy, user, topic, classID)
+}
+
 // GetHub returns the WebSocket hub
 func (s *service) GetHub() *Hub {
 	return s.hub
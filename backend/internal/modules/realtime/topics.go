@@ -0,0 +1,193 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/school-management/backend/internal/policy"
+)
+
+var (
+	ErrUnknownTopic       = errors.New("topik tidak dikenal")
+	ErrTopicClassRequired = errors.New("ID kelas wajib diisi untuk topik ini")
+	ErrTopicAccessDenied  = errors.New("anda tidak memiliki akses ke topik ini")
+	ErrNoAssignedClass    = errors.New("anda belum ditugaskan sebagai wali kelas")
+)
+
+// Topic names a stream of events a WebSocket client can subscribe to on /api/v1/ws
+type Topic string
+
+const (
+	TopicSchoolAttendance Topic = "attendance.school" // Every attendance of the school
+	TopicClassAttendance  Topic = "attendance.class"  // Attendance of one class, requires class_id
+	TopicNotifications    Topic = "notifications"     // Notifications of the connected user
+	TopicBKActivity       Topic = "bk.activity"       // Violations, achievements and permits
+	TopicDeviceStatus     Topic = "device.status"     // Device offline alerts and RFID pairing progress
+)
+
+// IsValid checks if the topic is known
+func (t Topic) IsValid() bool {
+	switch t {
+	case TopicSchoolAttendance, TopicClassAttendance, TopicNotifications, TopicBKActivity, TopicDeviceStatus:
+		return true
+	}
+	return false
+}
+
+// Topic event types
+const (
+	EventTypeNotification       EventType = "notification"
+	EventTypeViolationCreated   EventType = "violation.created"
+	EventTypeAchievementCreated EventType = "achievement.created"
+	EventTypePermitCreated      EventType = "permit.created"
+	EventTypeDeviceOffline      EventType = "device.offline"
+)
+
+// EventEnvelope is the typed envelope of every event delivered to topic subscribers.
+// It is sent to clients as WSMessage{Type: "event", Payload: envelope}.
+type EventEnvelope struct {
+	Topic     Topic       `json:"topic"`
+	Type      EventType   `json:"type"`
+	SchoolID  uint        `json:"school_id"`
+	ClassID   *uint       `json:"class_id,omitempty"`
+	StudentID uint        `json:"student_id,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+
+	// UserIDs are the recipients of a notification event; never sent to clients
+	UserIDs []uint `json:"user_ids,omitempty"`
+}
+
+// BKActivity is the data of a bk.activity event
+type BKActivity struct {
+	ID          uint   `json:"id"`
+	StudentID   uint   `json:"student_id"`
+	StudentName string `json:"student_name"`
+	ClassID     uint   `json:"class_id,omitempty"`
+	ClassName   string `json:"class_name,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+// DeviceStatus is the data of a device.offline event
+type DeviceStatus struct {
+	DeviceID uint                   `json:"device_id"`
+	Online   bool                   `json:"online"`
+	Message  string                 `json:"message,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// subscription is a client's subscription to a topic
+// ClassID limits the subscription to the events of one class; nil receives the whole school.
+type subscription struct {
+	ClassID *uint
+}
+
+// subscribe adds or replaces a topic subscription of the client
+func (c *Client) subscribe(topic Topic, classID *uint) {
+	c.topicsMu.Lock()
+	defer c.topicsMu.Unlock()
+
+	if c.topics == nil {
+		c.topics = make(map[Topic]subscription)
+	}
+	c.topics[topic] = subscription{ClassID: classID}
+}
+
+// unsubscribe removes a topic subscription of the client
+func (c *Client) unsubscribe(topic Topic) {
+	c.topicsMu.Lock()
+	defer c.topicsMu.Unlock()
+
+	delete(c.topics, topic)
+}
+
+// matches checks if the client subscribed to the event
+func (c *Client) matches(event *EventEnvelope) bool {
+	c.topicsMu.RLock()
+	sub, ok := c.topics[event.Topic]
+	c.topicsMu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	if event.Topic == TopicNotifications {
+		for _, userID := range event.UserIDs {
+			if userID == c.UserID {
+				return true
+			}
+		}
+		return false
+	}
+
+	if sub.ClassID != nil {
+		return event.ClassID != nil && *event.ClassID == *sub.ClassID
+	}
+	return true
+}
+
+// authorizeTopic checks a subscription request against the access policy
+// It returns the class the subscription is limited to, nil for the whole school.
+// Requirements: 4.6 - Wali_Kelas only sees their assigned class
+func authorizeTopic(ctx context.Context, accessPolicy policy.AccessPolicy, user *policy.UserContext, topic Topic, classID *uint) (*uint, error) {
+	switch topic {
+	case TopicSchoolAttendance:
+		if !accessPolicy.CanAccessSchoolAttendance(user) {
+			return nil, ErrTopicAccessDenied
+		}
+		return nil, nil
+
+	case TopicClassAttendance:
+		if classID == nil {
+			// Wali kelas subscribe to their assigned class by default
+			assignedClassID, err := accessPolicy.GetUserAssignedClassID(ctx, user.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if assignedClassID == nil {
+				return nil, ErrTopicClassRequired
+			}
+			classID = assignedClassID
+		}
+		allowed, err := accessPolicy.CanAccessClass(ctx, user, *classID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrTopicAccessDenied
+		}
+		return classID, nil
+
+	case TopicNotifications:
+		// Notification events are only delivered to their recipients
+		return nil, nil
+
+	case TopicBKActivity:
+		switch accessPolicy.CanAccessBKData(user) {
+		case policy.AccessLevelFull:
+			return nil, nil
+		case policy.AccessLevelReadOnly:
+			// Read-only BK access is limited to the user's class
+			assignedClassID, err := accessPolicy.GetUserAssignedClassID(ctx, user.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if assignedClassID == nil {
+				return nil, ErrNoAssignedClass
+			}
+			return assignedClassID, nil
+		default:
+			return nil, ErrTopicAccessDenied
+		}
+
+	case TopicDeviceStatus:
+		if !accessPolicy.CanManageDevices(user) {
+			return nil, ErrTopicAccessDenied
+		}
+		return nil, nil
+
+	default:
+		return nil, ErrUnknownTopic
+	}
+}
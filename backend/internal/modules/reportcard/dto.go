@@ -0,0 +1,114 @@
+package reportcard
+
+import (
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// ==================== Template DTOs ====================
+
+// UpdateTemplateRequest represents the request to update a school's report card template
+// HeaderLines and FooterNote may use Go template fields such as {{.School.Name}},
+// {{.Student.Name}}, {{.Class.Name}} and {{.Period.AcademicYear}}.
+type UpdateTemplateRequest struct {
+	Title            string `json:"title" validate:"required"`
+	HeaderLines      string `json:"header_lines"`
+	FooterNote       string `json:"footer_note"`
+	PaperSize        string `json:"paper_size"`
+	SignaturePlace   string `json:"signature_place"`
+	PrincipalName    string `json:"principal_name"`
+	PrincipalNIP     string `json:"principal_nip"`
+	ShowAchievements *bool  `json:"show_achievements"`
+	ShowViolations   *bool  `json:"show_violations"`
+}
+
+// TemplateResponse represents a report card template in API responses
+type TemplateResponse struct {
+	ID               uint      `json:"id,omitempty"` // Zero while the school uses the default template
+	Title            string    `json:"title"`
+	HeaderLines      string    `json:"header_lines"`
+	FooterNote       string    `json:"footer_note"`
+	PaperSize        string    `json:"paper_size"`
+	SignaturePlace   string    `json:"signature_place"`
+	PrincipalName    string    `json:"principal_name"`
+	PrincipalNIP     string    `json:"principal_nip"`
+	ShowAchievements bool      `json:"show_achievements"`
+	ShowViolations   bool      `json:"show_violations"`
+	IsDefault        bool      `json:"is_default"`
+	UpdatedAt        time.Time `json:"updated_at,omitempty"`
+}
+
+// ==================== Narrative DTOs ====================
+
+// SaveNarrativeRequest represents the request to write a student's report card narrative
+type SaveNarrativeRequest struct {
+	AcademicYear string `json:"academic_year"` // Defaults to the school's current academic year
+	Semester     int    `json:"semester"`      // Defaults to the school's current semester
+	Narrative    string `json:"narrative" validate:"required"`
+}
+
+// NarrativeResponse represents a report card narrative in API responses
+type NarrativeResponse struct {
+	ID           uint      `json:"id,omitempty"`
+	StudentID    uint      `json:"student_id"`
+	AcademicYear string    `json:"academic_year"`
+	Semester     int       `json:"semester"`
+	Narrative    string    `json:"narrative"`
+	WrittenBy    uint      `json:"written_by,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at,omitempty"`
+}
+
+// ==================== Job DTOs ====================
+
+// CreateJobRequest represents the request to generate report cards
+// Without a student_id the whole class is generated as a zip bundle.
+type CreateJobRequest struct {
+	ClassID      uint   `json:"class_id" validate:"required"`
+	StudentID    *uint  `json:"student_id"`
+	AcademicYear string `json:"academic_year"` // Defaults to the school's current academic year
+	Semester     int    `json:"semester"`      // Defaults to the school's current semester
+}
+
+// JobFilter represents filter options for listing jobs
+type JobFilter struct {
+	ClassID  *uint
+	Status   *models.ReportCardJobStatus
+	Page     int
+	PageSize int
+}
+
+// JobResponse represents a report card generation job in API responses
+type JobResponse struct {
+	ID           uint       `json:"id"`
+	ClassID      uint       `json:"class_id"`
+	ClassName    string     `json:"class_name,omitempty"`
+	StudentID    *uint      `json:"student_id,omitempty"`
+	StudentName  string     `json:"student_name,omitempty"`
+	AcademicYear string     `json:"academic_year"`
+	Semester     int        `json:"semester"`
+	Status       string     `json:"status"`
+	Total        int        `json:"total"`
+	Generated    int        `json:"generated"`
+	FileName     string     `json:"file_name,omitempty"`
+	DownloadURL  string     `json:"download_url,omitempty"` // Set once the job is completed
+	Error        string     `json:"error,omitempty"`
+	RequestedBy  uint       `json:"requested_by"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// JobListResponse represents a paginated list of jobs
+type JobListResponse struct {
+	Jobs       []JobResponse  `json:"jobs"`
+	Pagination PaginationMeta `json:"pagination"`
+}
+
+// PaginationMeta represents pagination metadata
+type PaginationMeta struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
@@ -0,0 +1,544 @@
+package reportcard
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/middleware"
+	"github.com/school-management/backend/internal/shared/gradebook"
+)
+
+// Handler handles HTTP requests for report cards (rapor)
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new report card handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers report card routes
+// Admin sekolah manage the template and can generate any class; wali kelas write
+// narratives for and generate their own classes.
+func (h *Handler) RegisterRoutes(router fiber.Router) {
+	reportCards := router.Group("/report-cards", middleware.RoleMiddleware(models.RoleAdminSekolah, models.RoleWaliKelas))
+	reportCards.Get("/template", h.GetTemplate)
+	reportCards.Put("/template", middleware.AdminSekolahOnly(), h.UpdateTemplate)
+	reportCards.Get("/students/:studentId/narrative", h.GetNarrative)
+	reportCards.Put("/students/:studentId/narrative", h.SaveNarrative)
+	reportCards.Post("/jobs", h.CreateJob)
+	reportCards.Get("/jobs", h.GetJobs)
+	reportCards.Get("/jobs/:id", h.GetJob)
+	reportCards.Get("/jobs/:id/download", h.DownloadJob)
+}
+
+// ==================== Template Handlers ====================
+
+// GetTemplate handles getting the school's report card template
+// @Summary Get report card template
+// @Description Get the school's report card layout, or the default layout if it has not customized one
+// @Tags Report Cards
+// @Produce json
+// @Success 200 {object} TemplateResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/report-cards/template [get]
+func (h *Handler) GetTemplate(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	response, err := h.service.GetTemplate(c.Context(), schoolID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// UpdateTemplate handles updating the school's report card template
+// @Summary Update report card template
+// @Description Update the school's report card layout. Header lines and footer note are Go templates, e.g. {{.School.Name}}
+// @Tags Report Cards
+// @Accept json
+// @Produce json
+// @Param request body UpdateTemplateRequest true "Template data"
+// @Success 200 {object} TemplateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/report-cards/template [put]
+func (h *Handler) UpdateTemplate(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	var req UpdateTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.UpdateTemplate(c.Context(), schoolID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Template rapor berhasil disimpan",
+	})
+}
+
+// ==================== Narrative Handlers ====================
+
+// GetNarrative handles getting a student's report card narrative
+// @Summary Get report card narrative
+// @Description Get the homeroom teacher's narrative of a student for a semester
+// @Tags Report Cards
+// @Produce json
+// @Param studentId path int true "Student ID"
+// @Param academic_year query string false "Academic year, defaults to the school's current one"
+// @Param semester query int false "Semester (1 or 2), defaults to the school's current one"
+// @Success 200 {object} NarrativeResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/report-cards/students/{studentId}/narrative [get]
+func (h *Handler) GetNarrative(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	studentID, err := strconv.ParseUint(c.Params("studentId"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "student")
+	}
+
+	period := gradebook.Period{
+		AcademicYear: c.Query("academic_year"),
+		Semester:     c.QueryInt("semester"),
+	}
+
+	response, err := h.service.GetNarrative(c.Context(), schoolID, userID, models.UserRole(role), uint(studentID), period)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// SaveNarrative handles writing a student's report card narrative
+// @Summary Save report card narrative
+// @Description Write the homeroom teacher's narrative of a student for a semester
+// @Tags Report Cards
+// @Accept json
+// @Produce json
+// @Param studentId path int true "Student ID"
+// @Param request body SaveNarrativeRequest true "Narrative data"
+// @Success 200 {object} NarrativeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/report-cards/students/{studentId}/narrative [put]
+func (h *Handler) SaveNarrative(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	studentID, err := strconv.ParseUint(c.Params("studentId"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "student")
+	}
+
+	var req SaveNarrativeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.SaveNarrative(c.Context(), schoolID, userID, models.UserRole(role), uint(studentID), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Catatan wali kelas berhasil disimpan",
+	})
+}
+
+// ==================== Job Handlers ====================
+
+// CreateJob handles queuing report card generation
+// @Summary Generate report cards
+// @Description Queue the generation of a student's report card (PDF) or of a whole class (zip of PDFs). Poll the job until it is completed, then download it
+// @Tags Report Cards
+// @Accept json
+// @Produce json
+// @Param request body CreateJobRequest true "Job data"
+// @Success 202 {object} JobResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/report-cards/jobs [post]
+func (h *Handler) CreateJob(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	var req CreateJobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.CreateJob(c.Context(), schoolID, userID, models.UserRole(role), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Rapor sedang dibuat",
+	})
+}
+
+// GetJobs handles listing report card jobs
+// @Summary List report card jobs
+// @Description Get a paginated list of report card jobs, newest first. Wali kelas only see the jobs of their classes
+// @Tags Report Cards
+// @Produce json
+// @Param class_id query int false "Filter by class ID"
+// @Param status query string false "Filter by status (pending, processing, completed, failed)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} JobListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/report-cards/jobs [get]
+func (h *Handler) GetJobs(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	filter := JobFilter{
+		Page:     c.QueryInt("page", 1),
+		PageSize: c.QueryInt("page_size", 20),
+	}
+	if classIDStr := c.Query("class_id"); classIDStr != "" {
+		if classID, err := strconv.ParseUint(classIDStr, 10, 32); err == nil {
+			id := uint(classID)
+			filter.ClassID = &id
+		}
+	}
+	if statusStr := c.Query("status"); statusStr != "" {
+		status := models.ReportCardJobStatus(statusStr)
+		filter.Status = &status
+	}
+
+	response, err := h.service.GetJobs(c.Context(), schoolID, userID, models.UserRole(role), filter)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetJob handles getting a report card job
+// @Summary Get report card job
+// @Description Get a report card job with its progress
+// @Tags Report Cards
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} JobResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/report-cards/jobs/{id} [get]
+func (h *Handler) GetJob(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "job")
+	}
+
+	response, err := h.service.GetJob(c.Context(), schoolID, userID, models.UserRole(role), uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// DownloadJob handles downloading a generated report card or class bundle
+// @Summary Download report cards
+// @Description Download the PDF of a student's report card or the zip bundle of a class
+// @Tags Report Cards
+// @Produce application/pdf
+// @Produce application/zip
+// @Param id path int true "Job ID"
+// @Success 200 {file} binary
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/report-cards/jobs/{id}/download [get]
+func (h *Handler) DownloadJob(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return h.authRequiredError(c)
+	}
+	role, _ := c.Locals("role").(string)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return h.invalidIDError(c, "job")
+	}
+
+	job, err := h.service.GetJobFile(c.Context(), schoolID, userID, models.UserRole(role), uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Download(job.FilePath, job.FileName)
+}
+
+// ==================== Error Helpers ====================
+
+func (h *Handler) tenantRequiredError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "AUTHZ_TENANT_REQUIRED",
+			"message": "Konteks sekolah diperlukan",
+		},
+	})
+}
+
+func (h *Handler) authRequiredError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "AUTH_REQUIRED",
+			"message": "Autentikasi diperlukan",
+		},
+	})
+}
+
+func (h *Handler) invalidBodyError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "VAL_INVALID_FORMAT",
+			"message": "Format data tidak valid",
+		},
+	})
+}
+
+func (h *Handler) invalidIDError(c *fiber.Ctx, resource string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "VAL_INVALID_FORMAT",
+			"message": "Invalid " + resource + " ID",
+		},
+	})
+}
+
+func (h *Handler) handleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrClassNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_CLASS",
+				"message": "Kelas tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrStudentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_STUDENT",
+				"message": "Siswa tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrJobNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_REPORT_CARD_JOB",
+				"message": "Pembuatan rapor tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrJobNotReady):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "REPORT_CARD_NOT_READY",
+				"message": "Rapor belum selesai dibuat",
+			},
+		})
+	case errors.Is(err, ErrStudentNotInClass):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_STUDENT_NOT_IN_CLASS",
+				"message": "Siswa tidak terdaftar di kelas ini",
+			},
+		})
+	case errors.Is(err, ErrClassEmpty):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_CLASS_EMPTY",
+				"message": "Tidak ada siswa aktif di kelas ini",
+			},
+		})
+	case errors.Is(err, ErrNarrativeRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Catatan wali kelas wajib diisi",
+			},
+		})
+	case errors.Is(err, ErrTitleRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Judul rapor wajib diisi",
+			},
+		})
+	case errors.Is(err, ErrPaperSizeInvalid):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_PAPER_SIZE",
+				"message": "Ukuran kertas harus A4 atau F4",
+			},
+		})
+	case errors.Is(err, ErrTemplateInvalid):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_TEMPLATE",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, gradebook.ErrInvalidPeriod):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_PERIOD",
+				"message": "Tahun ajaran harus berformat YYYY/YYYY dan semester 1 atau 2",
+			},
+		})
+	case errors.Is(err, ErrNotHomeroomTeacher):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_NOT_HOMEROOM_TEACHER",
+				"message": "Anda bukan wali kelas dari kelas ini",
+			},
+		})
+	case errors.Is(err, ErrNotAuthorized):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_NOT_AUTHORIZED",
+				"message": "Tidak memiliki izin untuk melakukan aksi ini",
+			},
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "INTERNAL_ERROR",
+				"message": "Terjadi kesalahan pada server",
+			},
+		})
+	}
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Success bool `json:"success"`
+	Error   struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
@@ -0,0 +1,348 @@
+package reportcard
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
+	"github.com/school-management/backend/internal/shared/pdf"
+)
+
+// ReportCard is everything printed on one student's report card
+// It is also the data of the template's header lines and footer note, e.g. {{.Student.Name}}.
+type ReportCard struct {
+	School          models.School
+	Class           models.Class
+	Student         models.Student
+	HomeroomTeacher string
+	Period          gradebook.Period
+	Grades          gradebook.Summary
+	Attendance      AttendanceRecap
+	Achievements    []models.Achievement
+	Violations      []models.Violation
+	ViolationPoints int
+	Narrative       string
+	IssuedAt        time.Time
+}
+
+// SemesterName returns the Indonesian name of the semester
+func (rc *ReportCard) SemesterName() string {
+	if rc.Period.Semester == 2 {
+		return "2 (Genap)"
+	}
+	return "1 (Ganjil)"
+}
+
+// IssuedDate returns the issue date in Indonesian, e.g. "16 Oktober 2026"
+func (rc *ReportCard) IssuedDate() string {
+	return fmt.Sprintf("%d %s %d", rc.IssuedAt.Day(), monthNames[rc.IssuedAt.Month()-1], rc.IssuedAt.Year())
+}
+
+var monthNames = [12]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// Page layout in points
+const (
+	margin     = 50.0
+	rowHeight  = 18.0
+	bodySize   = 10.0
+	smallSize  = 8.0
+	headerSize = 14.0
+	titleSize  = 12.0
+)
+
+// page tracks the write position while laying out a report card
+type page struct {
+	doc *pdf.Document
+	y   float64
+}
+
+// width returns the printable width
+func (p *page) width() float64 {
+	return p.doc.Size().Width - 2*margin
+}
+
+// ensure starts a new page when height does not fit on the current one
+func (p *page) ensure(height float64) {
+	if p.y+height > p.doc.Size().Height-margin {
+		p.doc.AddPage()
+		p.y = margin
+	}
+}
+
+// section prints a section heading
+func (p *page) section(title string) {
+	p.ensure(rowHeight * 3)
+	p.y += rowHeight
+	p.doc.Text(margin, p.y, pdf.Bold, bodySize+1, title)
+	p.y += 6
+}
+
+// column is a table column
+type column struct {
+	title string
+	width float64 // Fraction of the printable width
+	align pdf.Align
+}
+
+// table prints a table with a shaded header row
+func (p *page) table(columns []column, rows [][]string) {
+	row := func(cells []string, font pdf.Font, shaded bool) {
+		p.ensure(rowHeight)
+		x := margin
+		for i, col := range columns {
+			width := col.width * p.width()
+			if shaded {
+				p.doc.FillRect(x, p.y, width, rowHeight, 0.9)
+			}
+			p.doc.Rect(x, p.y, width, rowHeight, 0.5)
+			text := ""
+			if i < len(cells) {
+				text = fit(font, cells[i], width-8)
+			}
+			p.doc.TextAligned(x+4, p.y+12.5, width-8, font, bodySize, col.align, text)
+			x += width
+		}
+		p.y += rowHeight
+	}
+
+	titles := make([]string, len(columns))
+	for i, col := range columns {
+		titles[i] = col.title
+	}
+	row(titles, pdf.Bold, true)
+	for _, cells := range rows {
+		row(cells, pdf.Regular, false)
+	}
+}
+
+// fit shortens text with an ellipsis so it fits in width
+func fit(font pdf.Font, text string, width float64) string {
+	if pdf.TextWidth(font, bodySize, text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.TextWidth(font, bodySize, string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// renderTemplate executes one of the school's template fields
+func renderTemplate(name, text string, card *ReportCard) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, card); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderPDF lays out a student's report card with the school's template
+func renderPDF(tmpl *models.ReportCardTemplate, card *ReportCard) ([]byte, error) {
+	header, err := renderTemplate("header", tmpl.HeaderLines, card)
+	if err != nil {
+		return nil, fmt.Errorf("render header: %w", err)
+	}
+	footer, err := renderTemplate("footer", tmpl.FooterNote, card)
+	if err != nil {
+		return nil, fmt.Errorf("render footer: %w", err)
+	}
+
+	size := pdf.A4
+	if tmpl.PaperSize == models.ReportCardPaperF4 {
+		size = pdf.F4
+	}
+	doc := pdf.New(size)
+	doc.SetTitle(fmt.Sprintf("Rapor %s - %s Semester %d", card.Student.Name, card.Period.AcademicYear, card.Period.Semester))
+	p := &page{doc: doc, y: margin}
+	doc.AddPage()
+
+	// Letterhead: the first non-empty line is the school name
+	first := true
+	for _, line := range strings.Split(header, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if first {
+			p.y += headerSize
+			doc.TextAligned(margin, p.y, p.width(), pdf.Bold, headerSize, pdf.AlignCenter, line)
+			first = false
+		} else {
+			p.y += bodySize + 3
+			doc.TextAligned(margin, p.y, p.width(), pdf.Regular, bodySize, pdf.AlignCenter, line)
+		}
+	}
+	p.y += 8
+	doc.Line(margin, p.y, margin+p.width(), p.y, 1.5)
+	p.y += 2
+	doc.Line(margin, p.y, margin+p.width(), p.y, 0.5)
+
+	p.y += 24
+	doc.TextAligned(margin, p.y, p.width(), pdf.Bold, titleSize, pdf.AlignCenter, tmpl.Title)
+
+	// Student identity
+	p.y += 12
+	identity := [][2]string{
+		{"Nama Peserta Didik", card.Student.Name},
+		{"NIS / NISN", card.Student.NIS + " / " + card.Student.NISN},
+		{"Kelas", card.Class.Name},
+		{"Tahun Pelajaran", card.Period.AcademicYear},
+		{"Semester", card.SemesterName()},
+	}
+	for _, field := range identity {
+		p.y += bodySize + 5
+		doc.Text(margin, p.y, pdf.Regular, bodySize, field[0])
+		doc.Text(margin+120, p.y, pdf.Regular, bodySize, ": "+field[1])
+	}
+
+	// A. Grades
+	p.section("A. Nilai Akademik")
+	gradeRows := make([][]string, 0, len(card.Grades.Subjects)+1)
+	for i, subject := range card.Grades.Subjects {
+		status := "Belum Tuntas"
+		if subject.Passed {
+			status = "Tuntas"
+		}
+		gradeRows = append(gradeRows, []string{
+			fmt.Sprintf("%d", i+1),
+			subject.Name,
+			formatScore(subject.KKM),
+			formatScore(subject.WeightedAverage),
+			status,
+		})
+	}
+	if len(gradeRows) == 0 {
+		gradeRows = append(gradeRows, []string{"", "Belum ada nilai pada semester ini"})
+	} else {
+		gradeRows = append(gradeRows, []string{"", "Rata-rata", "", formatScore(card.Grades.WeightedAverage), ""})
+	}
+	p.table([]column{
+		{title: "No", width: 0.07, align: pdf.AlignCenter},
+		{title: "Mata Pelajaran", width: 0.45},
+		{title: "KKM", width: 0.12, align: pdf.AlignCenter},
+		{title: "Nilai", width: 0.12, align: pdf.AlignCenter},
+		{title: "Keterangan", width: 0.24, align: pdf.AlignCenter},
+	}, gradeRows)
+
+	// B. Attendance
+	p.section("B. Ketidakhadiran")
+	p.table([]column{
+		{title: "Keterangan", width: 0.5},
+		{title: "Jumlah", width: 0.25, align: pdf.AlignCenter},
+	}, [][]string{
+		{"Sakit", fmt.Sprintf("%d hari", card.Attendance.Sick)},
+		{"Izin", fmt.Sprintf("%d hari", card.Attendance.Excused)},
+		{"Tanpa Keterangan", fmt.Sprintf("%d hari", card.Attendance.Absent)},
+	})
+
+	next := 'C'
+	if tmpl.ShowAchievements {
+		p.section(fmt.Sprintf("%c. Prestasi", next))
+		next++
+		rows := make([][]string, 0, len(card.Achievements))
+		for i, achievement := range card.Achievements {
+			rows = append(rows, []string{fmt.Sprintf("%d", i+1), achievement.Title, fmt.Sprintf("%d", achievement.Point)})
+		}
+		if len(rows) == 0 {
+			rows = append(rows, []string{"", "-", ""})
+		}
+		p.table([]column{
+			{title: "No", width: 0.07, align: pdf.AlignCenter},
+			{title: "Prestasi", width: 0.73},
+			{title: "Poin", width: 0.2, align: pdf.AlignCenter},
+		}, rows)
+	}
+
+	if tmpl.ShowViolations {
+		p.section(fmt.Sprintf("%c. Catatan Pelanggaran", next))
+		next++
+		p.table([]column{
+			{title: "Jumlah Pelanggaran", width: 0.5, align: pdf.AlignCenter},
+			{title: "Total Poin", width: 0.5, align: pdf.AlignCenter},
+		}, [][]string{
+			{fmt.Sprintf("%d", len(card.Violations)), fmt.Sprintf("%d", card.ViolationPoints)},
+		})
+	}
+
+	// Homeroom teacher's narrative
+	p.section(fmt.Sprintf("%c. Catatan Wali Kelas", next))
+	narrative := card.Narrative
+	if strings.TrimSpace(narrative) == "" {
+		narrative = "-"
+	}
+	lines := pdf.WrapText(pdf.Regular, bodySize, narrative, p.width()-16)
+	boxHeight := float64(len(lines))*(bodySize+4) + 12
+	p.ensure(boxHeight)
+	doc.Rect(margin, p.y, p.width(), boxHeight, 0.5)
+	lineY := p.y + 6
+	for _, line := range lines {
+		lineY += bodySize + 4
+		doc.Text(margin+8, lineY, pdf.Regular, bodySize, line)
+	}
+	p.y += boxHeight
+
+	// Signatures: parent and homeroom teacher side by side, the principal below
+	p.ensure(190)
+	third := p.width() / 3
+	place := strings.TrimSpace(tmpl.SignaturePlace)
+	date := card.IssuedDate()
+	if place != "" {
+		date = place + ", " + date
+	}
+	p.y += 30
+	doc.TextAligned(margin+2*third, p.y, third, pdf.Regular, bodySize, pdf.AlignCenter, date)
+	p.y += bodySize + 4
+	doc.TextAligned(margin, p.y, third, pdf.Regular, bodySize, pdf.AlignCenter, "Orang Tua/Wali")
+	doc.TextAligned(margin+2*third, p.y, third, pdf.Regular, bodySize, pdf.AlignCenter, "Wali Kelas")
+	p.y += 60
+	doc.TextAligned(margin, p.y, third, pdf.Regular, bodySize, pdf.AlignCenter, "(.............................)")
+	doc.TextAligned(margin+2*third, p.y, third, pdf.Bold, bodySize, pdf.AlignCenter, signatureName(card.HomeroomTeacher))
+
+	p.y += 30
+	doc.TextAligned(margin+third, p.y, third, pdf.Regular, bodySize, pdf.AlignCenter, "Mengetahui,")
+	p.y += bodySize + 4
+	doc.TextAligned(margin+third, p.y, third, pdf.Regular, bodySize, pdf.AlignCenter, "Kepala Sekolah")
+	p.y += 60
+	doc.TextAligned(margin+third, p.y, third, pdf.Bold, bodySize, pdf.AlignCenter, signatureName(tmpl.PrincipalName))
+	if tmpl.PrincipalNIP != "" {
+		p.y += bodySize + 4
+		doc.TextAligned(margin+third, p.y, third, pdf.Regular, bodySize, pdf.AlignCenter, "NIP. "+tmpl.PrincipalNIP)
+	}
+
+	// Footer note below the signatures
+	if footer = strings.TrimSpace(footer); footer != "" {
+		p.ensure(rowHeight * 2)
+		p.y += rowHeight * 2
+		for _, line := range pdf.WrapText(pdf.Regular, smallSize, footer, p.width()) {
+			p.ensure(smallSize + 3)
+			doc.Text(margin, p.y, pdf.Regular, smallSize, line)
+			p.y += smallSize + 3
+		}
+	}
+
+	return doc.Bytes()
+}
+
+// formatScore prints a score without trailing zeros, e.g. 80 or 78.5
+func formatScore(score float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", score), "0"), ".")
+}
+
+// signatureName prints a name under a signature, or a blank line when it is unknown
+func signatureName(name string) string {
+	if strings.TrimSpace(name) == "" {
+		return "(.............................)"
+	}
+	return name
+}
@@ -0,0 +1,374 @@
+package reportcard
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+var (
+	ErrTemplateNotFound  = errors.New("template rapor tidak ditemukan")
+	ErrNarrativeNotFound = errors.New("catatan wali kelas tidak ditemukan")
+	ErrClassNotFound     = errors.New("kelas tidak ditemukan")
+	ErrStudentNotFound   = errors.New("siswa tidak ditemukan")
+	ErrJobNotFound       = errors.New("pembuatan rapor tidak ditemukan")
+)
+
+// AttendanceRecap counts the absences printed on a report card
+type AttendanceRecap struct {
+	Sick    int // Sakit
+	Excused int // Izin
+	Absent  int // Tanpa keterangan (alpa)
+}
+
+// Repository defines data operations for report cards
+type Repository interface {
+	// Template operations
+	FindTemplate(ctx context.Context, schoolID uint) (*models.ReportCardTemplate, error)
+	SaveTemplate(ctx context.Context, template *models.ReportCardTemplate) error
+
+	// Narrative operations
+	FindNarrative(ctx context.Context, studentID uint, academicYear string, semester int) (*models.ReportCardNarrative, error)
+	SaveNarrative(ctx context.Context, narrative *models.ReportCardNarrative) error
+
+	// Class and student lookups
+	FindClass(ctx context.Context, schoolID, classID uint) (*models.Class, error)
+	FindStudent(ctx context.Context, schoolID, studentID uint) (*models.Student, error)
+	FindClassStudents(ctx context.Context, classID uint) ([]models.Student, error)
+	FindHomeroomClassIDs(ctx context.Context, teacherID uint) ([]uint, error)
+
+	// Report data
+	GetAttendanceRecap(ctx context.Context, studentID uint, start, end time.Time) (*AttendanceRecap, error)
+	GetAchievements(ctx context.Context, studentID uint, start, end time.Time) ([]models.Achievement, error)
+	GetViolations(ctx context.Context, studentID uint, start, end time.Time) ([]models.Violation, error)
+
+	// Job operations
+	CreateJob(ctx context.Context, job *models.ReportCardJob) error
+	FindJobByID(ctx context.Context, schoolID, id uint) (*models.ReportCardJob, error)
+	FindJobs(ctx context.Context, schoolID uint, classIDs []uint, filter JobFilter) ([]models.ReportCardJob, int64, error)
+	ClaimNextJob(ctx context.Context, staleBefore time.Time) (*models.ReportCardJob, error)
+	UpdateJobProgress(ctx context.Context, id uint, total, generated int) error
+	CompleteJob(ctx context.Context, id uint, fileName, filePath string) error
+	FailJob(ctx context.Context, id uint, message string) error
+}
+
+// repository implements the Repository interface
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new report card repository
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// ==================== Templates ====================
+
+// FindTemplate retrieves the report card template of a school
+func (r *repository) FindTemplate(ctx context.Context, schoolID uint) (*models.ReportCardTemplate, error) {
+	var template models.ReportCardTemplate
+	err := r.db.WithContext(ctx).
+		Where("school_id = ?", schoolID).
+		First(&template).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+// SaveTemplate creates or replaces the report card template of a school
+func (r *repository) SaveTemplate(ctx context.Context, template *models.ReportCardTemplate) error {
+	return r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "school_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"title", "header_lines", "footer_note", "paper_size", "signature_place",
+				"principal_name", "principal_nip", "show_achievements", "show_violations", "updated_at",
+			}),
+		}).
+		Create(template).Error
+}
+
+// ==================== Narratives ====================
+
+// FindNarrative retrieves a student's narrative for a semester
+func (r *repository) FindNarrative(ctx context.Context, studentID uint, academicYear string, semester int) (*models.ReportCardNarrative, error) {
+	var narrative models.ReportCardNarrative
+	err := r.db.WithContext(ctx).
+		Where("student_id = ? AND academic_year = ? AND semester = ?", studentID, academicYear, semester).
+		First(&narrative).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNarrativeNotFound
+		}
+		return nil, err
+	}
+	return &narrative, nil
+}
+
+// SaveNarrative creates or replaces a student's narrative for a semester
+func (r *repository) SaveNarrative(ctx context.Context, narrative *models.ReportCardNarrative) error {
+	return r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "student_id"}, {Name: "academic_year"}, {Name: "semester"}},
+			DoUpdates: clause.AssignmentColumns([]string{"narrative", "written_by", "updated_at"}),
+		}).
+		Create(narrative).Error
+}
+
+// ==================== Classes & Students ====================
+
+// FindClass retrieves a class of a school with its school and homeroom teacher
+func (r *repository) FindClass(ctx context.Context, schoolID, classID uint) (*models.Class, error) {
+	var class models.Class
+	err := r.db.WithContext(ctx).
+		Preload("School").
+		Preload("HomeroomTeacher").
+		Where("id = ? AND school_id = ?", classID, schoolID).
+		First(&class).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClassNotFound
+		}
+		return nil, err
+	}
+	return &class, nil
+}
+
+// FindStudent retrieves a student of a school
+func (r *repository) FindStudent(ctx context.Context, schoolID, studentID uint) (*models.Student, error) {
+	var student models.Student
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", studentID, schoolID).
+		First(&student).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStudentNotFound
+		}
+		return nil, err
+	}
+	return &student, nil
+}
+
+// FindClassStudents retrieves the active students of a class ordered by name
+func (r *repository) FindClassStudents(ctx context.Context, classID uint) ([]models.Student, error) {
+	var students []models.Student
+	err := r.db.WithContext(ctx).
+		Where("class_id = ? AND is_active = ?", classID, true).
+		Order("name ASC").
+		Find(&students).Error
+	return students, err
+}
+
+// FindHomeroomClassIDs retrieves the classes a teacher is wali kelas of
+func (r *repository) FindHomeroomClassIDs(ctx context.Context, teacherID uint) ([]uint, error) {
+	var classIDs []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.Class{}).
+		Where("homeroom_teacher_id = ?", teacherID).
+		Pluck("id", &classIDs).Error
+	return classIDs, err
+}
+
+// ==================== Report Data ====================
+
+// GetAttendanceRecap counts a student's sick, excused and unexcused absences in [start, end)
+func (r *repository) GetAttendanceRecap(ctx context.Context, studentID uint, start, end time.Time) (*AttendanceRecap, error) {
+	var rows []struct {
+		Status models.AttendanceStatus
+		Count  int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.Attendance{}).
+		Select("status, COUNT(*) as count").
+		Where("student_id = ? AND date >= ? AND date < ?", studentID, start, end).
+		Where("status IN ?", []models.AttendanceStatus{
+			models.AttendanceStatusSick, models.AttendanceStatusExcused, models.AttendanceStatusAbsent,
+		}).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	recap := &AttendanceRecap{}
+	for _, row := range rows {
+		switch row.Status {
+		case models.AttendanceStatusSick:
+			recap.Sick = row.Count
+		case models.AttendanceStatusExcused:
+			recap.Excused = row.Count
+		case models.AttendanceStatusAbsent:
+			recap.Absent = row.Count
+		}
+	}
+	return recap, nil
+}
+
+// GetAchievements retrieves a student's achievements in [start, end)
+func (r *repository) GetAchievements(ctx context.Context, studentID uint, start, end time.Time) ([]models.Achievement, error) {
+	var achievements []models.Achievement
+	err := r.db.WithContext(ctx).
+		Where("student_id = ? AND created_at >= ? AND created_at < ?", studentID, start, end).
+		Order("created_at ASC").
+		Find(&achievements).Error
+	return achievements, err
+}
+
+// GetViolations retrieves a student's violations in [start, end)
+func (r *repository) GetViolations(ctx context.Context, studentID uint, start, end time.Time) ([]models.Violation, error) {
+	var violations []models.Violation
+	err := r.db.WithContext(ctx).
+		Where("student_id = ? AND created_at >= ? AND created_at < ?", studentID, start, end).
+		Order("created_at ASC").
+		Find(&violations).Error
+	return violations, err
+}
+
+// ==================== Jobs ====================
+
+// CreateJob queues a report card generation job
+func (r *repository) CreateJob(ctx context.Context, job *models.ReportCardJob) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(job).Error
+}
+
+// FindJobByID retrieves a job of a school with its class and student
+func (r *repository) FindJobByID(ctx context.Context, schoolID, id uint) (*models.ReportCardJob, error) {
+	var job models.ReportCardJob
+	err := r.db.WithContext(ctx).
+		Preload("Class").
+		Preload("Student").
+		Where("id = ? AND school_id = ?", id, schoolID).
+		First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindJobs retrieves the jobs of a school, newest first
+// A non-nil classIDs limits the result to those classes.
+func (r *repository) FindJobs(ctx context.Context, schoolID uint, classIDs []uint, filter JobFilter) ([]models.ReportCardJob, int64, error) {
+	var jobs []models.ReportCardJob
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&models.ReportCardJob{}).
+		Where("school_id = ?", schoolID)
+	if classIDs != nil {
+		query = query.Where("class_id IN ?", classIDs)
+	}
+	if filter.ClassID != nil {
+		query = query.Where("class_id = ?", *filter.ClassID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	query = query.Session(&gorm.Session{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	err := query.
+		Preload("Class").
+		Preload("Student").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(filter.PageSize).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, total, nil
+}
+
+// ClaimNextJob marks the oldest pending job as processing and returns it
+// Jobs left processing since before staleBefore (e.g. by a crashed server) are picked up again.
+// Rows are locked with SKIP LOCKED so several server instances can run a worker.
+func (r *repository) ClaimNextJob(ctx context.Context, staleBefore time.Time) (*models.ReportCardJob, error) {
+	var job models.ReportCardJob
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND started_at < ?)",
+				models.ReportCardJobPending, models.ReportCardJobProcessing, staleBefore).
+			Order("id ASC").
+			First(&job).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		job.Status = models.ReportCardJobProcessing
+		job.StartedAt = &now
+		job.Generated = 0
+		job.Error = ""
+		return tx.Model(&models.ReportCardJob{}).
+			Where("id = ?", job.ID).
+			Updates(map[string]interface{}{
+				"status":     job.Status,
+				"started_at": job.StartedAt,
+				"generated":  0,
+				"error":      "",
+			}).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// UpdateJobProgress records how many report cards of a job have been rendered
+func (r *repository) UpdateJobProgress(ctx context.Context, id uint, total, generated int) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ReportCardJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"total":     total,
+			"generated": generated,
+		}).Error
+}
+
+// CompleteJob marks a job as completed with its output file
+func (r *repository) CompleteJob(ctx context.Context, id uint, fileName, filePath string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ReportCardJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.ReportCardJobCompleted,
+			"file_name":    fileName,
+			"file_path":    filePath,
+			"completed_at": time.Now(),
+		}).Error
+}
+
+// FailJob marks a job as failed
+func (r *repository) FailJob(ctx context.Context, id uint, message string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ReportCardJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.ReportCardJobFailed,
+			"error":        message,
+			"completed_at": time.Now(),
+		}).Error
+}
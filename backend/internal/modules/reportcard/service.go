@@ -0,0 +1,649 @@
+package reportcard
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
+)
+
+var (
+	ErrNotAuthorized      = errors.New("tidak memiliki izin untuk melakukan aksi ini")
+	ErrNotHomeroomTeacher = errors.New("anda bukan wali kelas dari kelas ini")
+	ErrStudentNotInClass  = errors.New("siswa tidak terdaftar di kelas ini")
+	ErrNarrativeRequired  = errors.New("catatan wali kelas wajib diisi")
+	ErrTitleRequired      = errors.New("judul rapor wajib diisi")
+	ErrPaperSizeInvalid   = errors.New("ukuran kertas harus A4 atau F4")
+	ErrTemplateInvalid    = errors.New("template rapor tidak valid")
+	ErrClassEmpty         = errors.New("tidak ada siswa aktif di kelas ini")
+	ErrJobNotReady        = errors.New("rapor belum selesai dibuat")
+)
+
+// Service defines the interface for report card business logic
+type Service interface {
+	// Template operations
+	GetTemplate(ctx context.Context, schoolID uint) (*TemplateResponse, error)
+	UpdateTemplate(ctx context.Context, schoolID uint, req UpdateTemplateRequest) (*TemplateResponse, error)
+
+	// Narrative operations
+	GetNarrative(ctx context.Context, schoolID, userID uint, role models.UserRole, studentID uint, period gradebook.Period) (*NarrativeResponse, error)
+	SaveNarrative(ctx context.Context, schoolID, userID uint, role models.UserRole, studentID uint, req SaveNarrativeRequest) (*NarrativeResponse, error)
+
+	// Job operations
+	CreateJob(ctx context.Context, schoolID, userID uint, role models.UserRole, req CreateJobRequest) (*JobResponse, error)
+	GetJobs(ctx context.Context, schoolID, userID uint, role models.UserRole, filter JobFilter) (*JobListResponse, error)
+	GetJob(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint) (*JobResponse, error)
+	GetJobFile(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint) (*models.ReportCardJob, error)
+
+	// ProcessNextJob generates the oldest queued job; it returns false when the queue is empty
+	ProcessNextJob(ctx context.Context) (bool, error)
+}
+
+// service implements the Service interface
+type service struct {
+	repo       Repository
+	db         *gorm.DB
+	storageDir string
+	staleAfter time.Duration
+}
+
+// NewService creates a new report card service that stores generated files in storageDir
+func NewService(repo Repository, db *gorm.DB, storageDir string) Service {
+	return &service{
+		repo:       repo,
+		db:         db,
+		storageDir: storageDir,
+		staleAfter: 30 * time.Minute,
+	}
+}
+
+// ==================== Templates ====================
+
+// GetTemplate retrieves the school's template, or the default one if it has none
+func (s *service) GetTemplate(ctx context.Context, schoolID uint) (*TemplateResponse, error) {
+	template, err := s.repo.FindTemplate(ctx, schoolID)
+	if err != nil {
+		if !errors.Is(err, ErrTemplateNotFound) {
+			return nil, err
+		}
+		defaults := models.DefaultReportCardTemplate(schoolID)
+		return toTemplateResponse(&defaults), nil
+	}
+	return toTemplateResponse(template), nil
+}
+
+// UpdateTemplate creates or replaces the school's template
+func (s *service) UpdateTemplate(ctx context.Context, schoolID uint, req UpdateTemplateRequest) (*TemplateResponse, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, ErrTitleRequired
+	}
+
+	template := models.DefaultReportCardTemplate(schoolID)
+	if existing, err := s.repo.FindTemplate(ctx, schoolID); err == nil {
+		template = *existing
+	} else if !errors.Is(err, ErrTemplateNotFound) {
+		return nil, err
+	}
+
+	template.Title = strings.TrimSpace(req.Title)
+	template.HeaderLines = req.HeaderLines
+	template.FooterNote = req.FooterNote
+	template.SignaturePlace = strings.TrimSpace(req.SignaturePlace)
+	template.PrincipalName = strings.TrimSpace(req.PrincipalName)
+	template.PrincipalNIP = strings.TrimSpace(req.PrincipalNIP)
+	if req.PaperSize != "" {
+		template.PaperSize = models.ReportCardPaperSize(strings.ToUpper(req.PaperSize))
+		if !template.PaperSize.IsValid() {
+			return nil, ErrPaperSizeInvalid
+		}
+	}
+	if req.ShowAchievements != nil {
+		template.ShowAchievements = *req.ShowAchievements
+	}
+	if req.ShowViolations != nil {
+		template.ShowViolations = *req.ShowViolations
+	}
+
+	if err := template.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTemplateInvalid, err)
+	}
+	// Render a sample so fields that parse but fail at run time (e.g. {{.Unknown}}) are caught now
+	if _, err := renderPDF(&template, &ReportCard{IssuedAt: time.Now()}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTemplateInvalid, err)
+	}
+
+	if err := s.repo.SaveTemplate(ctx, &template); err != nil {
+		return nil, err
+	}
+
+	return s.GetTemplate(ctx, schoolID)
+}
+
+// template retrieves the school's template for rendering
+func (s *service) template(ctx context.Context, schoolID uint) (*models.ReportCardTemplate, error) {
+	template, err := s.repo.FindTemplate(ctx, schoolID)
+	if errors.Is(err, ErrTemplateNotFound) {
+		defaults := models.DefaultReportCardTemplate(schoolID)
+		return &defaults, nil
+	}
+	return template, err
+}
+
+// ==================== Narratives ====================
+
+// GetNarrative retrieves the homeroom teacher's narrative of a student for a semester
+func (s *service) GetNarrative(ctx context.Context, schoolID, userID uint, role models.UserRole, studentID uint, period gradebook.Period) (*NarrativeResponse, error) {
+	student, err := s.authorizeStudent(ctx, schoolID, userID, role, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err = gradebook.ResolvePeriod(ctx, s.db, schoolID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	narrative, err := s.repo.FindNarrative(ctx, student.ID, period.AcademicYear, period.Semester)
+	if err != nil {
+		if errors.Is(err, ErrNarrativeNotFound) {
+			return &NarrativeResponse{
+				StudentID:    student.ID,
+				AcademicYear: period.AcademicYear,
+				Semester:     period.Semester,
+			}, nil
+		}
+		return nil, err
+	}
+	return toNarrativeResponse(narrative), nil
+}
+
+// SaveNarrative writes the homeroom teacher's narrative of a student for a semester
+func (s *service) SaveNarrative(ctx context.Context, schoolID, userID uint, role models.UserRole, studentID uint, req SaveNarrativeRequest) (*NarrativeResponse, error) {
+	if strings.TrimSpace(req.Narrative) == "" {
+		return nil, ErrNarrativeRequired
+	}
+
+	student, err := s.authorizeStudent(ctx, schoolID, userID, role, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := s.resolvePeriod(ctx, schoolID, gradebook.Period{AcademicYear: req.AcademicYear, Semester: req.Semester})
+	if err != nil {
+		return nil, err
+	}
+
+	narrative := &models.ReportCardNarrative{
+		StudentID:    student.ID,
+		AcademicYear: period.AcademicYear,
+		Semester:     period.Semester,
+		Narrative:    strings.TrimSpace(req.Narrative),
+		WrittenBy:    userID,
+	}
+	if err := s.repo.SaveNarrative(ctx, narrative); err != nil {
+		return nil, err
+	}
+
+	saved, err := s.repo.FindNarrative(ctx, student.ID, period.AcademicYear, period.Semester)
+	if err != nil {
+		return nil, err
+	}
+	return toNarrativeResponse(saved), nil
+}
+
+// ==================== Jobs ====================
+
+// CreateJob queues the generation of a student's report card or of a whole class
+func (s *service) CreateJob(ctx context.Context, schoolID, userID uint, role models.UserRole, req CreateJobRequest) (*JobResponse, error) {
+	class, err := s.repo.FindClass(ctx, schoolID, req.ClassID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeClass(userID, role, class); err != nil {
+		return nil, err
+	}
+
+	total := 1
+	if req.StudentID != nil {
+		student, err := s.repo.FindStudent(ctx, schoolID, *req.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		if student.ClassID == nil || *student.ClassID != class.ID {
+			return nil, ErrStudentNotInClass
+		}
+	} else {
+		students, err := s.repo.FindClassStudents(ctx, class.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(students) == 0 {
+			return nil, ErrClassEmpty
+		}
+		total = len(students)
+	}
+
+	period, err := s.resolvePeriod(ctx, schoolID, gradebook.Period{AcademicYear: req.AcademicYear, Semester: req.Semester})
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ReportCardJob{
+		SchoolID:     schoolID,
+		ClassID:      class.ID,
+		StudentID:    req.StudentID,
+		AcademicYear: period.AcademicYear,
+		Semester:     period.Semester,
+		Status:       models.ReportCardJobPending,
+		Total:        total,
+		RequestedBy:  userID,
+	}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return s.GetJob(ctx, schoolID, userID, role, job.ID)
+}
+
+// GetJobs lists the school's jobs; wali kelas only see the jobs of their classes
+func (s *service) GetJobs(ctx context.Context, schoolID, userID uint, role models.UserRole, filter JobFilter) (*JobListResponse, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	var classIDs []uint
+	switch role {
+	case models.RoleAdminSekolah:
+	case models.RoleWaliKelas:
+		ids, err := s.repo.FindHomeroomClassIDs(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		classIDs = append([]uint{}, ids...)
+	default:
+		return nil, ErrNotAuthorized
+	}
+
+	jobs, total, err := s.repo.FindJobs(ctx, schoolID, classIDs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]JobResponse, len(jobs))
+	for i := range jobs {
+		responses[i] = *toJobResponse(&jobs[i])
+	}
+
+	totalPages := int(total) / filter.PageSize
+	if int(total)%filter.PageSize > 0 {
+		totalPages++
+	}
+
+	return &JobListResponse{
+		Jobs: responses,
+		Pagination: PaginationMeta{
+			Page:       filter.Page,
+			PageSize:   filter.PageSize,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+// GetJob retrieves a job
+func (s *service) GetJob(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint) (*JobResponse, error) {
+	job, err := s.findJob(ctx, schoolID, userID, role, id)
+	if err != nil {
+		return nil, err
+	}
+	return toJobResponse(job), nil
+}
+
+// GetJobFile retrieves a completed job for download
+func (s *service) GetJobFile(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint) (*models.ReportCardJob, error) {
+	job, err := s.findJob(ctx, schoolID, userID, role, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.ReportCardJobCompleted || job.FilePath == "" {
+		return nil, ErrJobNotReady
+	}
+	return job, nil
+}
+
+// findJob retrieves a job the user may access
+func (s *service) findJob(ctx context.Context, schoolID, userID uint, role models.UserRole, id uint) (*models.ReportCardJob, error) {
+	job, err := s.repo.FindJobByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeClass(userID, role, &job.Class); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ==================== Generation ====================
+
+// ProcessNextJob claims and generates the oldest queued job
+func (s *service) ProcessNextJob(ctx context.Context) (bool, error) {
+	job, err := s.repo.ClaimNextJob(ctx, time.Now().Add(-s.staleAfter))
+	if err != nil {
+		if errors.Is(err, ErrJobNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	fileName, filePath, err := s.generate(ctx, job)
+	if err != nil {
+		if failErr := s.repo.FailJob(ctx, job.ID, err.Error()); failErr != nil {
+			return true, failErr
+		}
+		return true, fmt.Errorf("report card job %d: %w", job.ID, err)
+	}
+
+	return true, s.repo.CompleteJob(ctx, job.ID, fileName, filePath)
+}
+
+// generate renders a job's report cards and stores the PDF or zip bundle
+func (s *service) generate(ctx context.Context, job *models.ReportCardJob) (string, string, error) {
+	class, err := s.repo.FindClass(ctx, job.SchoolID, job.ClassID)
+	if err != nil {
+		return "", "", err
+	}
+	template, err := s.template(ctx, job.SchoolID)
+	if err != nil {
+		return "", "", err
+	}
+
+	var students []models.Student
+	if job.StudentID != nil {
+		student, err := s.repo.FindStudent(ctx, job.SchoolID, *job.StudentID)
+		if err != nil {
+			return "", "", err
+		}
+		students = []models.Student{*student}
+	} else {
+		students, err = s.repo.FindClassStudents(ctx, class.ID)
+		if err != nil {
+			return "", "", err
+		}
+		if len(students) == 0 {
+			return "", "", ErrClassEmpty
+		}
+	}
+
+	period := gradebook.Period{AcademicYear: job.AcademicYear, Semester: job.Semester}
+	dir := filepath.Join(s.storageDir, fmt.Sprintf("school_%d", job.SchoolID))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", fmt.Errorf("create report card storage: %w", err)
+	}
+
+	if job.StudentID != nil {
+		card, err := s.buildReportCard(ctx, class, &students[0], period)
+		if err != nil {
+			return "", "", err
+		}
+		data, err := renderPDF(template, card)
+		if err != nil {
+			return "", "", err
+		}
+
+		fileName := fmt.Sprintf("rapor_%s_%s_%s_s%d.pdf", slug(students[0].NIS), slug(students[0].Name), slug(period.AcademicYear), period.Semester)
+		filePath := filepath.Join(dir, fmt.Sprintf("job_%d_%s", job.ID, fileName))
+		if err := writeFile(filePath, data); err != nil {
+			return "", "", err
+		}
+		if err := s.repo.UpdateJobProgress(ctx, job.ID, 1, 1); err != nil {
+			return "", "", err
+		}
+		return fileName, filePath, nil
+	}
+
+	fileName := fmt.Sprintf("rapor_%s_%s_s%d.zip", slug(class.Name), slug(period.AcademicYear), period.Semester)
+	filePath := filepath.Join(dir, fmt.Sprintf("job_%d_%s", job.ID, fileName))
+	tmp, err := os.CreateTemp(dir, "bundle-*.tmp")
+	if err != nil {
+		return "", "", fmt.Errorf("create report card bundle: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	archive := zip.NewWriter(tmp)
+	for i := range students {
+		card, err := s.buildReportCard(ctx, class, &students[i], period)
+		if err != nil {
+			return "", "", err
+		}
+		data, err := renderPDF(template, card)
+		if err != nil {
+			return "", "", err
+		}
+
+		entry, err := archive.Create(fmt.Sprintf("%02d_%s_%s.pdf", i+1, slug(students[i].NIS), slug(students[i].Name)))
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := entry.Write(data); err != nil {
+			return "", "", err
+		}
+		if err := s.repo.UpdateJobProgress(ctx, job.ID, len(students), i+1); err != nil {
+			return "", "", err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return "", "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", "", err
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return "", "", fmt.Errorf("store report card bundle: %w", err)
+	}
+
+	return fileName, filePath, nil
+}
+
+// buildReportCard gathers a student's grades, absences, BK records and narrative for a semester
+func (s *service) buildReportCard(ctx context.Context, class *models.Class, student *models.Student, period gradebook.Period) (*ReportCard, error) {
+	loc, err := time.LoadLocation(class.School.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	start, end, err := period.DateRange(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	grades, err := gradebook.StudentSummary(ctx, s.db, student.ID, period)
+	if err != nil {
+		return nil, err
+	}
+	attendance, err := s.repo.GetAttendanceRecap(ctx, student.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	achievements, err := s.repo.GetAchievements(ctx, student.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	violations, err := s.repo.GetViolations(ctx, student.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	card := &ReportCard{
+		School:       class.School,
+		Class:        *class,
+		Student:      *student,
+		Period:       period,
+		Grades:       *grades,
+		Attendance:   *attendance,
+		Achievements: achievements,
+		Violations:   violations,
+		IssuedAt:     time.Now().In(loc),
+	}
+	if class.HomeroomTeacher != nil {
+		card.HomeroomTeacher = class.HomeroomTeacher.Name
+	}
+	for _, violation := range violations {
+		card.ViolationPoints += violation.Point
+	}
+
+	narrative, err := s.repo.FindNarrative(ctx, student.ID, period.AcademicYear, period.Semester)
+	if err != nil && !errors.Is(err, ErrNarrativeNotFound) {
+		return nil, err
+	}
+	if narrative != nil {
+		card.Narrative = narrative.Narrative
+	}
+
+	return card, nil
+}
+
+// ==================== Helpers ====================
+
+// resolvePeriod fills in the school's current period and checks that it is valid
+func (s *service) resolvePeriod(ctx context.Context, schoolID uint, period gradebook.Period) (gradebook.Period, error) {
+	period, err := gradebook.ResolvePeriod(ctx, s.db, schoolID, period)
+	if err != nil {
+		return gradebook.Period{}, err
+	}
+	if _, _, err := period.DateRange(time.UTC); err != nil {
+		return gradebook.Period{}, err
+	}
+	return period, nil
+}
+
+// authorizeStudent retrieves a student whose class the user may access
+func (s *service) authorizeStudent(ctx context.Context, schoolID, userID uint, role models.UserRole, studentID uint) (*models.Student, error) {
+	student, err := s.repo.FindStudent(ctx, schoolID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if role == models.RoleAdminSekolah {
+		return student, nil
+	}
+	if student.ClassID == nil {
+		return nil, ErrNotHomeroomTeacher
+	}
+
+	class, err := s.repo.FindClass(ctx, schoolID, *student.ClassID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeClass(userID, role, class); err != nil {
+		return nil, err
+	}
+	return student, nil
+}
+
+// authorizeClass allows school admins and the class's wali kelas
+func authorizeClass(userID uint, role models.UserRole, class *models.Class) error {
+	switch role {
+	case models.RoleAdminSekolah:
+		return nil
+	case models.RoleWaliKelas:
+		if class.HomeroomTeacherID == nil || *class.HomeroomTeacherID != userID {
+			return ErrNotHomeroomTeacher
+		}
+		return nil
+	}
+	return ErrNotAuthorized
+}
+
+var slugPattern = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// slug turns a name into a safe file name part, e.g. "VII A" -> "VII_A"
+func slug(text string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(text, "_"), "_")
+}
+
+// writeFile writes data through a temporary file so a partial file is never served
+func writeFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write report card: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("store report card: %w", err)
+	}
+	return nil
+}
+
+// toTemplateResponse converts a template to its response
+func toTemplateResponse(t *models.ReportCardTemplate) *TemplateResponse {
+	return &TemplateResponse{
+		ID:               t.ID,
+		Title:            t.Title,
+		HeaderLines:      t.HeaderLines,
+		FooterNote:       t.FooterNote,
+		PaperSize:        string(t.PaperSize),
+		SignaturePlace:   t.SignaturePlace,
+		PrincipalName:    t.PrincipalName,
+		PrincipalNIP:     t.PrincipalNIP,
+		ShowAchievements: t.ShowAchievements,
+		ShowViolations:   t.ShowViolations,
+		IsDefault:        t.ID == 0,
+		UpdatedAt:        t.UpdatedAt,
+	}
+}
+
+// toNarrativeResponse converts a narrative to its response
+func toNarrativeResponse(n *models.ReportCardNarrative) *NarrativeResponse {
+	return &NarrativeResponse{
+		ID:           n.ID,
+		StudentID:    n.StudentID,
+		AcademicYear: n.AcademicYear,
+		Semester:     n.Semester,
+		Narrative:    n.Narrative,
+		WrittenBy:    n.WrittenBy,
+		UpdatedAt:    n.UpdatedAt,
+	}
+}
+
+// toJobResponse converts a job to its response
+func toJobResponse(j *models.ReportCardJob) *JobResponse {
+	response := &JobResponse{
+		ID:           j.ID,
+		ClassID:      j.ClassID,
+		ClassName:    j.Class.Name,
+		StudentID:    j.StudentID,
+		AcademicYear: j.AcademicYear,
+		Semester:     j.Semester,
+		Status:       string(j.Status),
+		Total:        j.Total,
+		Generated:    j.Generated,
+		FileName:     j.FileName,
+		Error:        j.Error,
+		RequestedBy:  j.RequestedBy,
+		StartedAt:    j.StartedAt,
+		CompletedAt:  j.CompletedAt,
+		CreatedAt:    j.CreatedAt,
+	}
+	if j.Student != nil {
+		response.StudentName = j.Student.Name
+	}
+	if j.Status == models.ReportCardJobCompleted {
+		response.DownloadURL = fmt.Sprintf("/api/v1/report-cards/jobs/%d/download", j.ID)
+	}
+	return response
+}
@@ -0,0 +1,91 @@
+package reportcard
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Worker generates queued report card jobs in the background.
+// Jobs are claimed with SKIP LOCKED so several server instances can run a worker.
+type Worker struct {
+	service      Service
+	pollInterval time.Duration
+	stopCh       chan struct{}
+	wg           sync.WaitGroup
+	running      bool
+	mu           sync.Mutex
+}
+
+// NewWorker creates a new report card worker that polls for jobs every pollInterval
+func NewWorker(service Service, pollInterval time.Duration) *Worker {
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+	return &Worker{
+		service:      service,
+		pollInterval: pollInterval,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start starts the worker
+func (w *Worker) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.processLoop()
+
+	log.Println("Report card worker started")
+}
+
+// Stop stops the worker gracefully, waiting for the job in progress
+func (w *Worker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	close(w.stopCh)
+	w.wg.Wait()
+
+	log.Println("Report card worker stopped")
+}
+
+// processLoop generates jobs until the worker is stopped
+func (w *Worker) processLoop() {
+	defer w.wg.Done()
+
+	for {
+		processed, err := w.service.ProcessNextJob(context.Background())
+		if err != nil {
+			log.Printf("Error generating report cards: %v", err)
+		}
+
+		// Keep draining while there is work, otherwise wait for the next poll
+		if processed {
+			select {
+			case <-w.stopCh:
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-w.stopCh:
+			return
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
@@ -0,0 +1,466 @@
+package schedule
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetHolidays handles listing the school's holidays
+// @Summary List holidays
+// @Description Get the school's holidays, optionally limited to a date range
+// @Tags Schedules
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} HolidayListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/schedules/holidays [get]
+func (h *Handler) GetHolidays(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	response, err := h.service.GetHolidays(c.Context(), schoolID, c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// CreateHoliday handles adding a holiday
+// @Summary Create holiday
+// @Description Add a holiday or non-school date range to the school's calendar
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param request body CreateHolidayRequest true "Holiday data"
+// @Success 201 {object} HolidayResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/schedules/holidays [post]
+func (h *Handler) CreateHoliday(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	var req CreateHolidayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.CreateHoliday(c.Context(), schoolID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Hari libur berhasil ditambahkan",
+	})
+}
+
+// UpdateHoliday handles updating a holiday
+// @Summary Update holiday
+// @Description Update a holiday in the school's calendar
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param holidayId path int true "Holiday ID"
+// @Param request body UpdateHolidayRequest true "Holiday data"
+// @Success 200 {object} HolidayResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/schedules/holidays/{holidayId} [put]
+func (h *Handler) UpdateHoliday(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Params("holidayId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID hari libur tidak valid",
+			},
+		})
+	}
+
+	var req UpdateHolidayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.UpdateHoliday(c.Context(), schoolID, uint(id), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Hari libur berhasil diperbarui",
+	})
+}
+
+// DeleteHoliday handles deleting a holiday
+// @Summary Delete holiday
+// @Description Remove a holiday from the school's calendar
+// @Tags Schedules
+// @Produce json
+// @Param holidayId path int true "Holiday ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/schedules/holidays/{holidayId} [delete]
+func (h *Handler) DeleteHoliday(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Params("holidayId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID hari libur tidak valid",
+			},
+		})
+	}
+
+	if err := h.service.DeleteHoliday(c.Context(), schoolID, uint(id)); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Hari libur berhasil dihapus",
+	})
+}
+
+// ImportHolidays handles bulk holiday import from an Excel or iCalendar file
+// @Summary Import holidays
+// @Description Import holidays from an Excel (.xlsx) or iCalendar (.ics) file. Existing holidays are skipped.
+// @Tags Schedules
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Calendar file (.xlsx or .ics)"
+// @Param type formData string false "Holiday type for iCal events (default: national)"
+// @Success 200 {object} HolidayImportResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/schedules/holidays/import [post]
+func (h *Handler) ImportHolidays(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_REQUIRED",
+				"message": "File wajib diunggah",
+			},
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_INVALID",
+				"message": "Gagal membuka file",
+			},
+		})
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportHolidays(c.Context(), schoolID, fileHeader.Filename, file, fileHeader.Size, c.FormValue("type"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Import selesai",
+	})
+}
+
+// GetOverrides handles listing schedule overrides
+// @Summary List schedule overrides
+// @Description Get the school's per-date schedule overrides
+// @Tags Schedules
+// @Produce json
+// @Param schedule_id query int false "Filter by schedule ID"
+// @Success 200 {object} OverrideListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/schedules/overrides [get]
+func (h *Handler) GetOverrides(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	var scheduleID *uint
+	if value := c.Query("schedule_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "VAL_INVALID_FORMAT",
+					"message": "ID jadwal tidak valid",
+				},
+			})
+		}
+		uid := uint(id)
+		scheduleID = &uid
+	}
+
+	response, err := h.service.GetOverrides(c.Context(), schoolID, scheduleID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// CreateOverride handles creating a schedule override
+// @Summary Create schedule override
+// @Description Replace a schedule's timetable (or cancel it) for a date range, e.g. during Ramadan
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param request body CreateOverrideRequest true "Override data"
+// @Success 201 {object} OverrideResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/schedules/overrides [post]
+func (h *Handler) CreateOverride(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	var req CreateOverrideRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.CreateOverride(c.Context(), schoolID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Penyesuaian jadwal berhasil dibuat",
+	})
+}
+
+// UpdateOverride handles updating a schedule override
+// @Summary Update schedule override
+// @Description Update a per-date schedule override
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param overrideId path int true "Override ID"
+// @Param request body UpdateOverrideRequest true "Override data"
+// @Success 200 {object} OverrideResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/schedules/overrides/{overrideId} [put]
+func (h *Handler) UpdateOverride(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Params("overrideId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID penyesuaian jadwal tidak valid",
+			},
+		})
+	}
+
+	var req UpdateOverrideRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format data tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.UpdateOverride(c.Context(), schoolID, uint(id), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Penyesuaian jadwal berhasil diperbarui",
+	})
+}
+
+// DeleteOverride handles deleting a schedule override
+// @Summary Delete schedule override
+// @Description Delete a per-date schedule override; the regular timetable applies again
+// @Tags Schedules
+// @Produce json
+// @Param overrideId path int true "Override ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/schedules/overrides/{overrideId} [delete]
+func (h *Handler) DeleteOverride(c *fiber.Ctx) error {
+	schoolID, ok := c.Locals("school_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Params("overrideId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID penyesuaian jadwal tidak valid",
+			},
+		})
+	}
+
+	if err := h.service.DeleteOverride(c.Context(), schoolID, uint(id)); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Penyesuaian jadwal berhasil dihapus",
+	})
+}
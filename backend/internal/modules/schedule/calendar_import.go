@@ -0,0 +1,270 @@
+package schedule
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// MaxImportFileSize is the maximum allowed calendar file size (5MB)
+const MaxImportFileSize = 5 * 1024 * 1024
+
+var (
+	ErrInvalidImportFile  = errors.New("format file tidak valid, hanya menerima file .xlsx atau .ics")
+	ErrImportFileTooLarge = errors.New("ukuran file melebihi batas maksimum 5MB")
+	ErrImportFileEmpty    = errors.New("file tidak memiliki data hari libur")
+)
+
+// holidayRow is a single holiday read from an import file
+type holidayRow struct {
+	Row         int // Excel row number or iCal event number
+	Name        string
+	Type        models.HolidayType
+	StartDate   time.Time
+	EndDate     time.Time
+	Description string
+	Error       string // Set when the row cannot be imported
+}
+
+// parseHolidayExcel parses an Excel calendar with the columns
+// Tanggal Mulai | Tanggal Selesai | Nama | Jenis | Keterangan
+// Data starts at row 2; an empty Tanggal Selesai means a single-day holiday
+func parseHolidayExcel(content []byte) ([]holidayRow, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrInvalidImportFile
+	}
+	defer f.Close()
+
+	sheetName := f.GetSheetName(0)
+	if sheetName == "" {
+		return nil, ErrImportFileEmpty
+	}
+
+	// Raw values keep date cells as Excel serial numbers regardless of their display format
+	rows, err := f.GetRows(sheetName, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, ErrInvalidImportFile
+	}
+	if len(rows) < 2 {
+		return nil, ErrImportFileEmpty
+	}
+
+	var result []holidayRow
+	for i := 1; i < len(rows); i++ {
+		cells := make([]string, 5)
+		for j := 0; j < len(cells) && j < len(rows[i]); j++ {
+			cells[j] = strings.TrimSpace(rows[i][j])
+		}
+		if strings.Join(cells, "") == "" {
+			continue
+		}
+
+		row := holidayRow{
+			Row:         i + 1,
+			Name:        cells[2],
+			Description: cells[4],
+		}
+
+		start, err := parseImportDate(cells[0])
+		if err != nil {
+			row.Error = "Tanggal mulai tidak valid"
+			result = append(result, row)
+			continue
+		}
+		row.StartDate = start
+		row.EndDate = start
+		if cells[1] != "" {
+			end, err := parseImportDate(cells[1])
+			if err != nil {
+				row.Error = "Tanggal selesai tidak valid"
+				result = append(result, row)
+				continue
+			}
+			row.EndDate = end
+		}
+
+		holidayType, ok := parseHolidayType(cells[3], models.HolidayTypeSchool)
+		if !ok {
+			row.Error = "Jenis hari libur tidak dikenal"
+			result = append(result, row)
+			continue
+		}
+		row.Type = holidayType
+
+		result = append(result, row)
+	}
+
+	if len(result) == 0 {
+		return nil, ErrImportFileEmpty
+	}
+	return result, nil
+}
+
+// parseHolidayICal parses the VEVENT entries of an iCalendar (.ics) file
+// All-day events use an exclusive DTEND, so the last holiday date is the day before it
+func parseHolidayICal(content []byte, holidayType models.HolidayType) ([]holidayRow, error) {
+	lines := unfoldICalLines(content)
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, ErrInvalidImportFile
+	}
+
+	var (
+		result  []holidayRow
+		current *holidayRow
+		endSet  bool
+		allDay  bool
+		eventNo int
+	)
+	for _, line := range lines {
+		name, params, value := splitICalProperty(line)
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			eventNo++
+			current = &holidayRow{Row: eventNo, Type: holidayType}
+			endSet = false
+			allDay = false
+		case current == nil:
+			continue
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current.Error == "" {
+				switch {
+				case current.StartDate.IsZero():
+					current.Error = "DTSTART tidak ditemukan"
+				case !endSet:
+					current.EndDate = current.StartDate
+				case allDay && current.EndDate.After(current.StartDate):
+					current.EndDate = current.EndDate.AddDate(0, 0, -1)
+				}
+			}
+			result = append(result, *current)
+			current = nil
+		case name == "SUMMARY":
+			current.Name = unescapeICalText(value)
+		case name == "DESCRIPTION":
+			current.Description = unescapeICalText(value)
+		case name == "DTSTART":
+			date, dateOnly, err := parseICalDate(value, params)
+			if err != nil {
+				current.Error = "DTSTART tidak valid"
+				continue
+			}
+			current.StartDate = date
+			allDay = dateOnly
+		case name == "DTEND":
+			date, _, err := parseICalDate(value, params)
+			if err != nil {
+				current.Error = "DTEND tidak valid"
+				continue
+			}
+			current.EndDate = date
+			endSet = true
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, ErrImportFileEmpty
+	}
+	return result, nil
+}
+
+// unfoldICalLines splits an iCalendar file into logical lines
+// RFC 5545 folds long lines by starting continuation lines with a space or tab
+func unfoldICalLines(content []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), MaxImportFileSize)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitICalProperty splits "NAME;PARAM=X:VALUE" into its name, parameters and value
+func splitICalProperty(line string) (string, string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), "", ""
+	}
+	head, value := line[:colon], line[colon+1:]
+
+	name, params := head, ""
+	if semi := strings.Index(head, ";"); semi >= 0 {
+		name, params = head[:semi], head[semi+1:]
+	}
+	return strings.ToUpper(name), strings.ToUpper(params), strings.TrimSpace(value)
+}
+
+// parseICalDate parses a DATE (20250101) or DATE-TIME (20250101T070000Z) value
+// Only the calendar date is kept; it reports whether the value was a date without time
+func parseICalDate(value, params string) (time.Time, bool, error) {
+	dateOnly := strings.Contains(params, "VALUE=DATE") && !strings.Contains(params, "VALUE=DATE-TIME")
+	if dateOnly || len(value) == 8 {
+		date, err := time.Parse("20060102", value)
+		return date, true, err
+	}
+	if len(value) < 8 {
+		return time.Time{}, false, errors.New("invalid date")
+	}
+	date, err := time.Parse("20060102", value[:8])
+	return date, false, err
+}
+
+// unescapeICalText reverses the TEXT escaping of RFC 5545
+func unescapeICalText(value string) string {
+	replacer := strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return strings.TrimSpace(replacer.Replace(value))
+}
+
+// parseImportDate parses a date cell: an Excel serial number or YYYY-MM-DD / DD/MM/YYYY / DD-MM-YYYY text
+func parseImportDate(value string) (time.Time, error) {
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	for _, layout := range []string{"2006-01-02", "02/01/2006", "2/1/2006", "02-01-2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid date")
+}
+
+// parseHolidayType maps an English or Indonesian holiday type to a HolidayType
+func parseHolidayType(value string, fallback models.HolidayType) (models.HolidayType, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return fallback, true
+	case "national", "nasional", "libur nasional", "cuti bersama":
+		return models.HolidayTypeNational, true
+	case "school", "sekolah", "libur sekolah":
+		return models.HolidayTypeSchool, true
+	case "break", "libur semester", "semester", "kenaikan kelas":
+		return models.HolidayTypeBreak, true
+	case "exam", "ujian":
+		return models.HolidayTypeExam, true
+	case "other", "lainnya", "lain-lain":
+		return models.HolidayTypeOther, true
+	}
+	return "", false
+}
@@ -0,0 +1,456 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// MaxCalendarRangeDays is the longest date range a single holiday or override may cover
+const MaxCalendarRangeDays = 366
+
+var (
+	ErrHolidayNameRequired  = errors.New("nama hari libur wajib diisi")
+	ErrInvalidHolidayType   = errors.New("jenis hari libur harus national, school, break, exam, atau other")
+	ErrInvalidDate          = errors.New("format tanggal tidak valid (gunakan YYYY-MM-DD)")
+	ErrEndDateBeforeStart   = errors.New("tanggal selesai tidak boleh sebelum tanggal mulai")
+	ErrDateRangeTooLong     = errors.New("rentang tanggal maksimal 366 hari")
+	ErrOverrideNameRequired = errors.New("nama penyesuaian jadwal wajib diisi")
+	ErrOverrideEmpty        = errors.New("penyesuaian harus mengubah waktu jadwal atau membatalkan jadwal")
+)
+
+// GetHolidays retrieves the school's holidays, optionally limited to a date range (YYYY-MM-DD)
+func (s *service) GetHolidays(ctx context.Context, schoolID uint, startDate, endDate string) (*HolidayListResponse, error) {
+	var start, end *time.Time
+	if startDate != "" {
+		t, err := parseDate(startDate)
+		if err != nil {
+			return nil, err
+		}
+		start = &t
+	}
+	if endDate != "" {
+		t, err := parseDate(endDate)
+		if err != nil {
+			return nil, err
+		}
+		end = &t
+	}
+
+	holidays, err := s.repo.FindHolidays(ctx, schoolID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]HolidayResponse, len(holidays))
+	for i := range holidays {
+		responses[i] = *toHolidayResponse(&holidays[i])
+	}
+
+	return &HolidayListResponse{
+		Holidays: responses,
+		Total:    len(responses),
+	}, nil
+}
+
+// CreateHoliday adds a holiday to the school's calendar
+func (s *service) CreateHoliday(ctx context.Context, schoolID uint, req CreateHolidayRequest) (*HolidayResponse, error) {
+	holiday := &models.SchoolHoliday{
+		SchoolID:    schoolID,
+		Name:        strings.TrimSpace(req.Name),
+		Type:        models.HolidayTypeSchool,
+		Description: strings.TrimSpace(req.Description),
+	}
+	if req.Type != "" {
+		holiday.Type = models.HolidayType(req.Type)
+	}
+
+	start, end, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	holiday.StartDate = start
+	holiday.EndDate = end
+
+	if err := validateHoliday(holiday); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateHoliday(ctx, holiday); err != nil {
+		return nil, err
+	}
+
+	return toHolidayResponse(holiday), nil
+}
+
+// UpdateHoliday updates a holiday in the school's calendar
+func (s *service) UpdateHoliday(ctx context.Context, schoolID, id uint, req UpdateHolidayRequest) (*HolidayResponse, error) {
+	holiday, err := s.repo.FindHolidayByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		holiday.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Type != nil {
+		holiday.Type = models.HolidayType(*req.Type)
+	}
+	if req.Description != nil {
+		holiday.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.StartDate != nil {
+		if holiday.StartDate, err = parseDate(*req.StartDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.EndDate != nil {
+		if holiday.EndDate, err = parseDate(*req.EndDate); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateHoliday(holiday); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateHoliday(ctx, holiday); err != nil {
+		return nil, err
+	}
+
+	return toHolidayResponse(holiday), nil
+}
+
+// DeleteHoliday removes a holiday from the school's calendar
+func (s *service) DeleteHoliday(ctx context.Context, schoolID, id uint) error {
+	return s.repo.DeleteHoliday(ctx, schoolID, id)
+}
+
+// ImportHolidays imports holidays from an Excel (.xlsx) or iCalendar (.ics) file
+// Holidays already in the calendar (same name and dates) are skipped, so importing
+// the same file twice is harmless. defaultType applies to iCal events, which carry no type.
+func (s *service) ImportHolidays(ctx context.Context, schoolID uint, filename string, file io.Reader, fileSize int64, defaultType string) (*HolidayImportResult, error) {
+	if fileSize > MaxImportFileSize {
+		return nil, ErrImportFileTooLarge
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, MaxImportFileSize+1))
+	if err != nil || len(content) > MaxImportFileSize {
+		return nil, ErrInvalidImportFile
+	}
+
+	var rows []holidayRow
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		rows, err = parseHolidayExcel(content)
+	case ".ics", ".ical":
+		holidayType, ok := parseHolidayType(defaultType, models.HolidayTypeNational)
+		if !ok {
+			return nil, ErrInvalidHolidayType
+		}
+		rows, err = parseHolidayICal(content, holidayType)
+	default:
+		return nil, ErrInvalidImportFile
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HolidayImportResult{TotalRows: len(rows)}
+	for _, row := range rows {
+		if row.Error != "" {
+			result.addError(row.Row, row.Error)
+			continue
+		}
+
+		holiday := &models.SchoolHoliday{
+			SchoolID:    schoolID,
+			Name:        strings.TrimSpace(row.Name),
+			Type:        row.Type,
+			StartDate:   row.StartDate,
+			EndDate:     row.EndDate,
+			Description: row.Description,
+		}
+		if err := validateHoliday(holiday); err != nil {
+			result.addError(row.Row, err.Error())
+			continue
+		}
+
+		exists, err := s.repo.HolidayExists(ctx, schoolID, holiday.Name, holiday.StartDate, holiday.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			result.SkippedCount++
+			continue
+		}
+
+		if err := s.repo.CreateHoliday(ctx, holiday); err != nil {
+			result.addError(row.Row, "Gagal menyimpan hari libur")
+			continue
+		}
+		result.CreatedCount++
+	}
+
+	return result, nil
+}
+
+// addError records a row that could not be imported
+func (r *HolidayImportResult) addError(row int, message string) {
+	r.FailedCount++
+	r.Errors = append(r.Errors, HolidayImportError{Row: row, Message: message})
+}
+
+// GetOverrides retrieves the school's schedule overrides, optionally for a single schedule
+func (s *service) GetOverrides(ctx context.Context, schoolID uint, scheduleID *uint) (*OverrideListResponse, error) {
+	overrides, err := s.repo.FindOverrides(ctx, schoolID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]OverrideResponse, len(overrides))
+	for i := range overrides {
+		responses[i] = *toOverrideResponse(&overrides[i])
+	}
+
+	return &OverrideListResponse{
+		Overrides: responses,
+		Total:     len(responses),
+	}, nil
+}
+
+// CreateOverride replaces a schedule's timetable for a date range (e.g. a Ramadan timetable)
+func (s *service) CreateOverride(ctx context.Context, schoolID uint, req CreateOverrideRequest) (*OverrideResponse, error) {
+	schedule, err := s.repo.FindByID(ctx, schoolID, req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	override := &models.ScheduleOverride{
+		SchoolID:          schoolID,
+		ScheduleID:        schedule.ID,
+		Name:              strings.TrimSpace(req.Name),
+		StartDate:         start,
+		EndDate:           end,
+		StartTime:         emptyToNil(req.StartTime),
+		EndTime:           emptyToNil(req.EndTime),
+		LateThreshold:     req.LateThreshold,
+		VeryLateThreshold: req.VeryLateThreshold,
+		DismissalTime:     emptyToNil(req.DismissalTime),
+		IsCancelled:       req.IsCancelled,
+	}
+
+	if err := s.validateOverride(override, schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	return toOverrideResponse(override), nil
+}
+
+// UpdateOverride updates a schedule override
+func (s *service) UpdateOverride(ctx context.Context, schoolID, id uint, req UpdateOverrideRequest) (*OverrideResponse, error) {
+	override, err := s.repo.FindOverrideByID(ctx, schoolID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.repo.FindByID(ctx, schoolID, override.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		override.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.StartDate != nil {
+		if override.StartDate, err = parseDate(*req.StartDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.EndDate != nil {
+		if override.EndDate, err = parseDate(*req.EndDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.StartTime != nil {
+		override.StartTime = emptyToNil(req.StartTime)
+	}
+	if req.EndTime != nil {
+		override.EndTime = emptyToNil(req.EndTime)
+	}
+	if req.LateThreshold != nil {
+		override.LateThreshold = req.LateThreshold
+	}
+	if req.VeryLateThreshold != nil {
+		override.VeryLateThreshold = req.VeryLateThreshold
+	}
+	if req.DismissalTime != nil {
+		override.DismissalTime = emptyToNil(req.DismissalTime)
+	}
+	if req.IsCancelled != nil {
+		override.IsCancelled = *req.IsCancelled
+	}
+
+	if err := s.validateOverride(override, schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	return toOverrideResponse(override), nil
+}
+
+// DeleteOverride deletes a schedule override; the regular timetable applies again
+func (s *service) DeleteOverride(ctx context.Context, schoolID, id uint) error {
+	return s.repo.DeleteOverride(ctx, schoolID, id)
+}
+
+// validateOverride validates an override against the schedule it modifies
+// The overridden timetable must satisfy the same rules as a regular schedule
+func (s *service) validateOverride(override *models.ScheduleOverride, schedule *models.AttendanceSchedule) error {
+	if override.Name == "" {
+		return ErrOverrideNameRequired
+	}
+	if err := validateDateRange(override.StartDate, override.EndDate); err != nil {
+		return err
+	}
+
+	if !override.IsCancelled {
+		if override.StartTime == nil && override.EndTime == nil && override.LateThreshold == nil &&
+			override.VeryLateThreshold == nil && override.DismissalTime == nil {
+			return ErrOverrideEmpty
+		}
+		if override.StartTime != nil && !isValidTimeFormat(*override.StartTime) {
+			return ErrInvalidStartTime
+		}
+		if override.EndTime != nil && !isValidTimeFormat(*override.EndTime) {
+			return ErrInvalidEndTime
+		}
+		if override.LateThreshold != nil && *override.LateThreshold < 0 {
+			return ErrLateThresholdRequired
+		}
+
+		effective := override.Apply(*schedule)
+		if err := s.validateTimeRange(effective.StartTime, effective.EndTime); err != nil {
+			return err
+		}
+		if effective.VeryLateThreshold != nil && *effective.VeryLateThreshold < effective.LateThreshold {
+			return ErrVeryLateThreshold
+		}
+		if err := s.validateCheckOutSettings(string(effective.Type), effective.StartTime, effective.EndTime, effective.DismissalTime); err != nil {
+			return err
+		}
+	}
+
+	return override.Validate()
+}
+
+// validateHoliday validates a holiday before it is stored
+func validateHoliday(holiday *models.SchoolHoliday) error {
+	if holiday.Name == "" {
+		return ErrHolidayNameRequired
+	}
+	if !holiday.Type.IsValid() {
+		return ErrInvalidHolidayType
+	}
+	if err := validateDateRange(holiday.StartDate, holiday.EndDate); err != nil {
+		return err
+	}
+	return holiday.Validate()
+}
+
+// validateDateRange checks that a date range is ordered and not unreasonably long
+func validateDateRange(start, end time.Time) error {
+	if end.Before(start) {
+		return ErrEndDateBeforeStart
+	}
+	if end.Sub(start) > MaxCalendarRangeDays*24*time.Hour {
+		return ErrDateRangeTooLong
+	}
+	return nil
+}
+
+// parseDateRange parses a start date and an optional end date (defaults to the start date)
+func parseDateRange(startDate, endDate string) (time.Time, time.Time, error) {
+	start, err := parseDate(startDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if endDate == "" {
+		return start, start, nil
+	}
+	end, err := parseDate(endDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// parseDate parses a date in YYYY-MM-DD format
+func parseDate(value string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return t, nil
+}
+
+// emptyToNil treats an empty optional time as not set
+func emptyToNil(value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
+
+// toHolidayResponse converts a holiday model to response DTO
+func toHolidayResponse(holiday *models.SchoolHoliday) *HolidayResponse {
+	return &HolidayResponse{
+		ID:          holiday.ID,
+		SchoolID:    holiday.SchoolID,
+		Name:        holiday.Name,
+		Type:        string(holiday.Type),
+		StartDate:   holiday.StartDate.Format("2006-01-02"),
+		EndDate:     holiday.EndDate.Format("2006-01-02"),
+		Description: holiday.Description,
+		CreatedAt:   holiday.CreatedAt,
+		UpdatedAt:   holiday.UpdatedAt,
+	}
+}
+
+// toOverrideResponse converts an override model to response DTO
+func toOverrideResponse(override *models.ScheduleOverride) *OverrideResponse {
+	return &OverrideResponse{
+		ID:                override.ID,
+		SchoolID:          override.SchoolID,
+		ScheduleID:        override.ScheduleID,
+		Name:              override.Name,
+		StartDate:         override.StartDate.Format("2006-01-02"),
+		EndDate:           override.EndDate.Format("2006-01-02"),
+		StartTime:         override.StartTime,
+		EndTime:           override.EndTime,
+		LateThreshold:     override.LateThreshold,
+		VeryLateThreshold: override.VeryLateThreshold,
+		DismissalTime:     override.DismissalTime,
+		IsCancelled:       override.IsCancelled,
+		CreatedAt:         override.CreatedAt,
+		UpdatedAt:         override.UpdatedAt,
+	}
+}
@@ -9,13 +9,15 @@ import (
 // CreateScheduleRequest represents the request to create a new attendance schedule
 // Requirements: 3.1 - Schedule creation SHALL require name, start_time, end_time, and late_threshold
 type CreateScheduleRequest struct {
-	Name              string `json:"name" validate:"required,max=100"`
-	StartTime         string `json:"start_time" validate:"required"`         // Format: HH:MM
-	EndTime           string `json:"end_time" validate:"required"`           // Format: HH:MM
-	LateThreshold     int    `json:"late_threshold" validate:"required,min=0"` // minutes after start_time
-	VeryLateThreshold *int   `json:"very_late_threshold,omitempty"`          // optional, minutes after start_time
-	DaysOfWeek        string `json:"days_of_week,omitempty"`                 // e.g., "1,2,3,4,5" (Mon-Fri)
-	IsActive          *bool  `json:"is_active,omitempty"`                    // defaults to true
+	Name              string  `json:"name" validate:"required,max=100"`
+	StartTime         string  `json:"start_time" validate:"required"`           // Format: HH:MM
+	EndTime           string  `json:"end_time" validate:"required"`             // Format: HH:MM
+	LateThreshold     int     `json:"late_threshold" validate:"required,min=0"` // minutes after start_time
+	VeryLateThreshold *int    `json:"very_late_threshold,omitempty"`            // optional, minutes after start_time
+	DaysOfWeek        string  `json:"days_of_week,omitempty"`                   // e.g., "1,2,3,4,5" (Mon-Fri)
+	IsActive          *bool   `json:"is_active,omitempty"`                      // defaults to true
+	Type              string  `json:"type,omitempty"`                           // check_in (default) or check_out
+	DismissalTime     *string `json:"dismissal_time,omitempty"`                 // check_out only, format: HH:MM
 }
 
 // UpdateScheduleRequest represents the request to update an attendance schedule
@@ -28,6 +30,55 @@ type UpdateScheduleRequest struct {
 	VeryLateThreshold *int    `json:"very_late_threshold,omitempty"`
 	DaysOfWeek        *string `json:"days_of_week,omitempty"`
 	IsActive          *bool   `json:"is_active,omitempty"`
+	Type              *string `json:"type,omitempty"`
+	DismissalTime     *string `json:"dismissal_time,omitempty"`
+}
+
+// CreateHolidayRequest represents the request to add a non-school day to the calendar
+type CreateHolidayRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Type        string `json:"type,omitempty"`     // national, school (default), break, exam, other
+	StartDate   string `json:"start_date"`         // Format: YYYY-MM-DD
+	EndDate     string `json:"end_date,omitempty"` // Format: YYYY-MM-DD, defaults to start_date
+	Description string `json:"description,omitempty"`
+}
+
+// UpdateHolidayRequest represents the request to update a holiday
+type UpdateHolidayRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,max=255"`
+	Type        *string `json:"type,omitempty"`
+	StartDate   *string `json:"start_date,omitempty"`
+	EndDate     *string `json:"end_date,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// CreateOverrideRequest represents the request to override a schedule for a date range
+// Only the provided fields replace the regular timetable; is_cancelled disables the schedule
+type CreateOverrideRequest struct {
+	ScheduleID        uint    `json:"schedule_id"`
+	Name              string  `json:"name" validate:"required,max=100"`
+	StartDate         string  `json:"start_date"`         // Format: YYYY-MM-DD
+	EndDate           string  `json:"end_date,omitempty"` // Format: YYYY-MM-DD, defaults to start_date
+	StartTime         *string `json:"start_time,omitempty"`
+	EndTime           *string `json:"end_time,omitempty"`
+	LateThreshold     *int    `json:"late_threshold,omitempty"`
+	VeryLateThreshold *int    `json:"very_late_threshold,omitempty"`
+	DismissalTime     *string `json:"dismissal_time,omitempty"`
+	IsCancelled       bool    `json:"is_cancelled"`
+}
+
+// UpdateOverrideRequest represents the request to update a schedule override
+// An empty string clears an overridden time
+type UpdateOverrideRequest struct {
+	Name              *string `json:"name,omitempty" validate:"omitempty,max=100"`
+	StartDate         *string `json:"start_date,omitempty"`
+	EndDate           *string `json:"end_date,omitempty"`
+	StartTime         *string `json:"start_time,omitempty"`
+	EndTime           *string `json:"end_time,omitempty"`
+	LateThreshold     *int    `json:"late_threshold,omitempty"`
+	VeryLateThreshold *int    `json:"very_late_threshold,omitempty"`
+	DismissalTime     *string `json:"dismissal_time,omitempty"`
+	IsCancelled       *bool   `json:"is_cancelled,omitempty"`
 }
 
 // ==================== Response DTOs ====================
@@ -42,6 +93,8 @@ type ScheduleResponse struct {
 	LateThreshold     int       `json:"late_threshold"`
 	VeryLateThreshold *int      `json:"very_late_threshold,omitempty"`
 	DaysOfWeek        string    `json:"days_of_week"`
+	Type              string    `json:"type"`
+	DismissalTime     *string   `json:"dismissal_time,omitempty"`
 	IsActive          bool      `json:"is_active"`
 	IsDefault         bool      `json:"is_default"`
 	CreatedAt         time.Time `json:"created_at"`
@@ -56,6 +109,65 @@ type ScheduleListResponse struct {
 
 // ActiveScheduleResponse represents the currently active schedule
 type ActiveScheduleResponse struct {
-	Schedule *ScheduleResponse `json:"schedule,omitempty"`
-	Message  string            `json:"message,omitempty"`
+	Schedule  *ScheduleResponse `json:"schedule,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	IsHoliday bool              `json:"is_holiday,omitempty"`
+}
+
+// HolidayResponse represents a holiday in responses
+type HolidayResponse struct {
+	ID          uint      `json:"id"`
+	SchoolID    uint      `json:"school_id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	StartDate   string    `json:"start_date"`
+	EndDate     string    `json:"end_date"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// HolidayListResponse represents a list of holidays
+type HolidayListResponse struct {
+	Holidays []HolidayResponse `json:"holidays"`
+	Total    int               `json:"total"`
+}
+
+// HolidayImportResult represents the result of a holiday calendar import
+type HolidayImportResult struct {
+	TotalRows    int                  `json:"total_rows"`
+	CreatedCount int                  `json:"created_count"`
+	SkippedCount int                  `json:"skipped_count"` // Already in the calendar
+	FailedCount  int                  `json:"failed_count"`
+	Errors       []HolidayImportError `json:"errors,omitempty"`
+}
+
+// HolidayImportError represents a row that could not be imported
+type HolidayImportError struct {
+	Row     int    `json:"row"` // Excel row number or iCal event number
+	Message string `json:"message"`
+}
+
+// OverrideResponse represents a schedule override in responses
+type OverrideResponse struct {
+	ID                uint      `json:"id"`
+	SchoolID          uint      `json:"school_id"`
+	ScheduleID        uint      `json:"schedule_id"`
+	Name              string    `json:"name"`
+	StartDate         string    `json:"start_date"`
+	EndDate           string    `json:"end_date"`
+	StartTime         *string   `json:"start_time,omitempty"`
+	EndTime           *string   `json:"end_time,omitempty"`
+	LateThreshold     *int      `json:"late_threshold,omitempty"`
+	VeryLateThreshold *int      `json:"very_late_threshold,omitempty"`
+	DismissalTime     *string   `json:"dismissal_time,omitempty"`
+	IsCancelled       bool      `json:"is_cancelled"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// OverrideListResponse represents a list of schedule overrides
+type OverrideListResponse struct {
+	Overrides []OverrideResponse `json:"overrides"`
+	Total     int                `json:"total"`
 }
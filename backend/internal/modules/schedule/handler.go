@@ -25,6 +25,15 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 	schedules.Get("", h.GetAllSchedules)
 	schedules.Post("", h.CreateSchedule)
 	schedules.Get("/active", h.GetActiveSchedule)
+	schedules.Get("/holidays", h.GetHolidays)
+	schedules.Post("/holidays", h.CreateHoliday)
+	schedules.Post("/holidays/import", h.ImportHolidays)
+	schedules.Put("/holidays/:holidayId", h.UpdateHoliday)
+	schedules.Delete("/holidays/:holidayId", h.DeleteHoliday)
+	schedules.Get("/overrides", h.GetOverrides)
+	schedules.Post("/overrides", h.CreateOverride)
+	schedules.Put("/overrides/:overrideId", h.UpdateOverride)
+	schedules.Delete("/overrides/:overrideId", h.DeleteOverride)
 	schedules.Get("/:id", h.GetScheduleByID)
 	schedules.Put("/:id", h.UpdateSchedule)
 	schedules.Delete("/:id", h.DeleteSchedule)
@@ -36,6 +45,15 @@ func (h *Handler) RegisterRoutesWithoutGroup(router fiber.Router) {
 	router.Get("", h.GetAllSchedules)
 	router.Post("", h.CreateSchedule)
 	router.Get("/active", h.GetActiveSchedule)
+	router.Get("/holidays", h.GetHolidays)
+	router.Post("/holidays", h.CreateHoliday)
+	router.Post("/holidays/import", h.ImportHolidays)
+	router.Put("/holidays/:holidayId", h.UpdateHoliday)
+	router.Delete("/holidays/:holidayId", h.DeleteHoliday)
+	router.Get("/overrides", h.GetOverrides)
+	router.Post("/overrides", h.CreateOverride)
+	router.Put("/overrides/:overrideId", h.UpdateOverride)
+	router.Delete("/overrides/:overrideId", h.DeleteOverride)
 	router.Get("/:id", h.GetScheduleByID)
 	router.Put("/:id", h.UpdateSchedule)
 	router.Delete("/:id", h.DeleteSchedule)
@@ -431,6 +449,30 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 				"message": "Waktu akhir harus setelah waktu mulai",
 			},
 		})
+	case errors.Is(err, ErrInvalidScheduleType):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_TYPE",
+				"message": "Tipe jadwal harus check_in atau check_out",
+			},
+		})
+	case errors.Is(err, ErrInvalidDismissalTime):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format waktu pulang tidak valid (gunakan HH:MM)",
+			},
+		})
+	case errors.Is(err, ErrDismissalOutOfRange):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_TIME",
+				"message": "Waktu pulang harus berada di antara waktu mulai dan waktu akhir",
+			},
+		})
 	case errors.Is(err, ErrVeryLateThreshold):
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
@@ -439,6 +481,102 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 				"message": "Batas sangat terlambat harus lebih besar dari batas terlambat",
 			},
 		})
+	case errors.Is(err, ErrHolidayNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_HOLIDAY",
+				"message": "Hari libur tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrOverrideNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "NOT_FOUND_SCHEDULE_OVERRIDE",
+				"message": "Penyesuaian jadwal tidak ditemukan",
+			},
+		})
+	case errors.Is(err, ErrHolidayNameRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Nama hari libur wajib diisi",
+			},
+		})
+	case errors.Is(err, ErrOverrideNameRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_REQUIRED_FIELD",
+				"message": "Nama penyesuaian jadwal wajib diisi",
+			},
+		})
+	case errors.Is(err, ErrInvalidHolidayType):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_TYPE",
+				"message": "Jenis hari libur harus national, school, break, exam, atau other",
+			},
+		})
+	case errors.Is(err, ErrInvalidDate):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format tanggal tidak valid (gunakan YYYY-MM-DD)",
+			},
+		})
+	case errors.Is(err, ErrEndDateBeforeStart):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_DATE_RANGE",
+				"message": "Tanggal selesai tidak boleh sebelum tanggal mulai",
+			},
+		})
+	case errors.Is(err, ErrDateRangeTooLong):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_DATE_RANGE",
+				"message": "Rentang tanggal maksimal 366 hari",
+			},
+		})
+	case errors.Is(err, ErrOverrideEmpty):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_OVERRIDE_EMPTY",
+				"message": "Penyesuaian harus mengubah waktu jadwal atau membatalkan jadwal",
+			},
+		})
+	case errors.Is(err, ErrInvalidImportFile):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_INVALID",
+				"message": "Format file tidak valid, hanya menerima file .xlsx atau .ics",
+			},
+		})
+	case errors.Is(err, ErrImportFileEmpty):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_EMPTY",
+				"message": "File tidak memiliki data hari libur",
+			},
+		})
+	case errors.Is(err, ErrImportFileTooLarge):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_FILE_TOO_LARGE",
+				"message": "Ukuran file melebihi batas maksimum 5MB",
+			},
+		})
 	default:
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
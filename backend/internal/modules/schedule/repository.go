@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/calendar"
 )
 
 var (
@@ -16,6 +17,8 @@ var (
 	ErrScheduleTimeOverlap  = errors.New("waktu jadwal bertumpang tindih dengan jadwal lain")
 	ErrScheduleInUse        = errors.New("jadwal tidak dapat dihapus karena masih digunakan")
 	ErrInvalidTimeRange     = errors.New("waktu akhir harus setelah waktu mulai")
+	ErrHolidayNotFound      = errors.New("hari libur tidak ditemukan")
+	ErrOverrideNotFound     = errors.New("penyesuaian jadwal tidak ditemukan")
 )
 
 // Repository defines the interface for schedule data operations
@@ -44,6 +47,22 @@ type Repository interface {
 	
 	// Check for time overlap with existing schedules
 	CheckTimeOverlap(ctx context.Context, schoolID uint, startTime, endTime, daysOfWeek string, excludeID *uint) (bool, error)
+
+	// Holiday calendar operations
+	FindHolidays(ctx context.Context, schoolID uint, startDate, endDate *time.Time) ([]models.SchoolHoliday, error)
+	FindHolidayByID(ctx context.Context, schoolID, id uint) (*models.SchoolHoliday, error)
+	FindHolidayOn(ctx context.Context, schoolID uint, date time.Time) (*models.SchoolHoliday, error)
+	HolidayExists(ctx context.Context, schoolID uint, name string, startDate, endDate time.Time) (bool, error)
+	CreateHoliday(ctx context.Context, holiday *models.SchoolHoliday) error
+	UpdateHoliday(ctx context.Context, holiday *models.SchoolHoliday) error
+	DeleteHoliday(ctx context.Context, schoolID, id uint) error
+
+	// Schedule override operations
+	FindOverrides(ctx context.Context, schoolID uint, scheduleID *uint) ([]models.ScheduleOverride, error)
+	FindOverrideByID(ctx context.Context, schoolID, id uint) (*models.ScheduleOverride, error)
+	CreateOverride(ctx context.Context, override *models.ScheduleOverride) error
+	UpdateOverride(ctx context.Context, override *models.ScheduleOverride) error
+	DeleteOverride(ctx context.Context, schoolID, id uint) error
 }
 
 // repository implements the Repository interface
@@ -62,8 +81,8 @@ func (r *repository) Create(ctx context.Context, schedule *models.AttendanceSche
 	// Use raw SQL to properly handle TIME type
 	query := `
 		INSERT INTO attendance_schedules 
-		(school_id, name, start_time, end_time, late_threshold, very_late_threshold, days_of_week, is_active, is_default, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		(school_id, name, start_time, end_time, late_threshold, very_late_threshold, days_of_week, is_active, is_default, created_at, updated_at, type, dismissal_time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id
 	`
 	now := time.Now()
@@ -82,6 +101,8 @@ func (r *repository) Create(ctx context.Context, schedule *models.AttendanceSche
 		schedule.IsDefault,
 		schedule.CreatedAt,
 		schedule.UpdatedAt,
+		schedule.Type,
+		schedule.DismissalTime,
 	).Scan(&schedule.ID).Error
 	
 	return err
@@ -129,7 +150,9 @@ func (r *repository) Update(ctx context.Context, schedule *models.AttendanceSche
 			days_of_week = $6, 
 			is_active = $7, 
 			is_default = $8,
-			updated_at = $9
+			updated_at = $9,
+			type = $12,
+			dismissal_time = $13
 		WHERE id = $10 AND school_id = $11
 	`
 	schedule.UpdatedAt = time.Now()
@@ -146,6 +169,8 @@ func (r *repository) Update(ctx context.Context, schedule *models.AttendanceSche
 		schedule.UpdatedAt,
 		schedule.ID,
 		schedule.SchoolID,
+		schedule.Type,
+		schedule.DismissalTime,
 	)
 
 	if result.Error != nil {
@@ -176,41 +201,26 @@ func (r *repository) Delete(ctx context.Context, schoolID, id uint) error {
 // FindActiveSchedule finds the active schedule for a given time and day
 // Requirements: 3.4 - Determine which schedule is currently active based on current time
 // Property 8: Active Schedule Selection
+// No schedule is active on a holiday, and date overrides replace the regular timetable
 func (r *repository) FindActiveSchedule(ctx context.Context, schoolID uint, timestamp time.Time) (*models.AttendanceSchedule, error) {
-	var schedules []models.AttendanceSchedule
-	
-	// Get current time in HH:MM:SS format
-	currentTime := timestamp.Format("15:04:05")
-	
-	// Find all active schedules for this school
-	err := r.db.WithContext(ctx).
-		Where("school_id = ? AND is_active = ?", schoolID, true).
-		Find(&schedules).Error
-
+	holiday, err := calendar.FindHoliday(ctx, r.db, schoolID, timestamp)
 	if err != nil {
 		return nil, err
 	}
+	if holiday != nil {
+		return nil, nil
+	}
 
-	// Find the schedule that matches the current time and day
-	for _, schedule := range schedules {
-		// Check if schedule is active on this day
-		if !schedule.IsActiveOnDay(timestamp.Weekday()) {
-			continue
-		}
-
-		// Normalize times for comparison
-		startTime := schedule.StartTime
-		if len(startTime) == 5 {
-			startTime += ":00"
-		}
-		endTime := schedule.EndTime
-		if len(endTime) == 5 {
-			endTime += ":00"
-		}
+	// Active schedules running today, with date overrides applied
+	schedules, err := calendar.SchedulesForDate(ctx, r.db, schoolID, timestamp)
+	if err != nil {
+		return nil, err
+	}
 
-		// Check if current time is within schedule range
-		if currentTime >= startTime && currentTime <= endTime {
-			return &schedule, nil
+	// Find the schedule that matches the current time
+	for i := range schedules {
+		if schedules[i].IsTimeInRange(timestamp) {
+			return &schedules[i], nil
 		}
 	}
 
@@ -219,7 +229,8 @@ func (r *repository) FindActiveSchedule(ctx context.Context, schoolID uint, time
 	if err == nil && defaultSchedule != nil {
 		// Check if default schedule is active on this day
 		if defaultSchedule.IsActive && defaultSchedule.IsActiveOnDay(timestamp.Weekday()) {
-			return defaultSchedule, nil
+			// A cancelled default schedule is not used on this date
+			return calendar.ApplyOverride(ctx, r.db, defaultSchedule, timestamp)
 		}
 	}
 
@@ -385,3 +396,215 @@ func daysOverlap(days1, days2 map[int]bool) bool {
 	}
 	return false
 }
+
+// FindHolidays retrieves the holidays of a school, optionally limited to those overlapping a date range
+func (r *repository) FindHolidays(ctx context.Context, schoolID uint, startDate, endDate *time.Time) ([]models.SchoolHoliday, error) {
+	var holidays []models.SchoolHoliday
+	query := r.db.WithContext(ctx).Where("school_id = ?", schoolID)
+
+	if startDate != nil {
+		query = query.Where("end_date >= ?::date", startDate.Format("2006-01-02"))
+	}
+	if endDate != nil {
+		query = query.Where("start_date <= ?::date", endDate.Format("2006-01-02"))
+	}
+
+	err := query.Order("start_date ASC, name ASC").Find(&holidays).Error
+	return holidays, err
+}
+
+// FindHolidayByID retrieves a holiday by ID for a specific school
+func (r *repository) FindHolidayByID(ctx context.Context, schoolID, id uint) (*models.SchoolHoliday, error) {
+	var holiday models.SchoolHoliday
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		First(&holiday).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHolidayNotFound
+		}
+		return nil, err
+	}
+
+	return &holiday, nil
+}
+
+// FindHolidayOn returns the holiday covering the date, or nil if it is a school day
+func (r *repository) FindHolidayOn(ctx context.Context, schoolID uint, date time.Time) (*models.SchoolHoliday, error) {
+	return calendar.FindHoliday(ctx, r.db, schoolID, date)
+}
+
+// HolidayExists checks if a holiday with the same name and dates is already recorded
+// Used to skip duplicates when the same calendar is imported twice
+func (r *repository) HolidayExists(ctx context.Context, schoolID uint, name string, startDate, endDate time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.SchoolHoliday{}).
+		Where("school_id = ? AND LOWER(name) = LOWER(?) AND start_date = ?::date AND end_date = ?::date",
+			schoolID, name, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// CreateHoliday creates a new holiday
+func (r *repository) CreateHoliday(ctx context.Context, holiday *models.SchoolHoliday) error {
+	return r.db.WithContext(ctx).Create(holiday).Error
+}
+
+// UpdateHoliday updates an existing holiday
+func (r *repository) UpdateHoliday(ctx context.Context, holiday *models.SchoolHoliday) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.SchoolHoliday{}).
+		Where("id = ? AND school_id = ?", holiday.ID, holiday.SchoolID).
+		Updates(map[string]interface{}{
+			"name":        holiday.Name,
+			"type":        holiday.Type,
+			"start_date":  holiday.StartDate,
+			"end_date":    holiday.EndDate,
+			"description": holiday.Description,
+			"updated_at":  time.Now(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrHolidayNotFound
+	}
+	return nil
+}
+
+// DeleteHoliday deletes a holiday
+func (r *repository) DeleteHoliday(ctx context.Context, schoolID, id uint) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		Delete(&models.SchoolHoliday{})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrHolidayNotFound
+	}
+	return nil
+}
+
+// FindOverrides retrieves the schedule overrides of a school, optionally for a single schedule
+func (r *repository) FindOverrides(ctx context.Context, schoolID uint, scheduleID *uint) ([]models.ScheduleOverride, error) {
+	var overrides []models.ScheduleOverride
+	query := r.db.WithContext(ctx).Where("school_id = ?", schoolID)
+
+	if scheduleID != nil {
+		query = query.Where("schedule_id = ?", *scheduleID)
+	}
+
+	err := query.Order("start_date DESC, id DESC").Find(&overrides).Error
+	return overrides, err
+}
+
+// FindOverrideByID retrieves a schedule override by ID for a specific school
+func (r *repository) FindOverrideByID(ctx context.Context, schoolID, id uint) (*models.ScheduleOverride, error) {
+	var override models.ScheduleOverride
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		First(&override).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOverrideNotFound
+		}
+		return nil, err
+	}
+
+	return &override, nil
+}
+
+// CreateOverride creates a new schedule override
+func (r *repository) CreateOverride(ctx context.Context, override *models.ScheduleOverride) error {
+	// Use raw SQL to properly handle TIME type
+	query := `
+		INSERT INTO schedule_overrides
+		(school_id, schedule_id, name, start_date, end_date, start_time, end_time, late_threshold, very_late_threshold, dismissal_time, is_cancelled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4::date, $5::date, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`
+	now := time.Now()
+	override.CreatedAt = now
+	override.UpdatedAt = now
+
+	return r.db.WithContext(ctx).Raw(query,
+		override.SchoolID,
+		override.ScheduleID,
+		override.Name,
+		override.StartDate.Format("2006-01-02"),
+		override.EndDate.Format("2006-01-02"),
+		override.StartTime,
+		override.EndTime,
+		override.LateThreshold,
+		override.VeryLateThreshold,
+		override.DismissalTime,
+		override.IsCancelled,
+		override.CreatedAt,
+		override.UpdatedAt,
+	).Scan(&override.ID).Error
+}
+
+// UpdateOverride updates an existing schedule override
+func (r *repository) UpdateOverride(ctx context.Context, override *models.ScheduleOverride) error {
+	// Use raw SQL to properly handle TIME type
+	query := `
+		UPDATE schedule_overrides
+		SET name = $1,
+			start_date = $2::date,
+			end_date = $3::date,
+			start_time = $4,
+			end_time = $5,
+			late_threshold = $6,
+			very_late_threshold = $7,
+			dismissal_time = $8,
+			is_cancelled = $9,
+			updated_at = $10
+		WHERE id = $11 AND school_id = $12
+	`
+	override.UpdatedAt = time.Now()
+
+	result := r.db.WithContext(ctx).Exec(query,
+		override.Name,
+		override.StartDate.Format("2006-01-02"),
+		override.EndDate.Format("2006-01-02"),
+		override.StartTime,
+		override.EndTime,
+		override.LateThreshold,
+		override.VeryLateThreshold,
+		override.DismissalTime,
+		override.IsCancelled,
+		override.UpdatedAt,
+		override.ID,
+		override.SchoolID,
+	)
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOverrideNotFound
+	}
+	return nil
+}
+
+// DeleteOverride deletes a schedule override
+func (r *repository) DeleteOverride(ctx context.Context, schoolID, id uint) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND school_id = ?", id, schoolID).
+		Delete(&models.ScheduleOverride{})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOverrideNotFound
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package schedule
 import (
 	"context"
 	"errors"
+	"io"
 	"strings"
 	"time"
 
@@ -24,6 +25,9 @@ var (
 	ErrInvalidEndTime        = errors.New("format waktu akhir tidak valid (gunakan HH:MM)")
 	ErrEndTimeBeforeStart    = errors.New("waktu akhir harus setelah waktu mulai")
 	ErrVeryLateThreshold     = errors.New("batas sangat terlambat harus lebih besar dari batas terlambat")
+	ErrInvalidScheduleType   = errors.New("tipe jadwal harus check_in atau check_out")
+	ErrInvalidDismissalTime  = errors.New("format waktu pulang tidak valid (gunakan HH:MM)")
+	ErrDismissalOutOfRange   = errors.New("waktu pulang harus berada di antara waktu mulai dan waktu akhir")
 )
 
 // Service defines the interface for schedule business logic
@@ -41,6 +45,19 @@ type Service interface {
 
 	// Default schedule operations
 	SetDefaultSchedule(ctx context.Context, schoolID, id uint) error
+
+	// Holiday calendar operations
+	GetHolidays(ctx context.Context, schoolID uint, startDate, endDate string) (*HolidayListResponse, error)
+	CreateHoliday(ctx context.Context, schoolID uint, req CreateHolidayRequest) (*HolidayResponse, error)
+	UpdateHoliday(ctx context.Context, schoolID, id uint, req UpdateHolidayRequest) (*HolidayResponse, error)
+	DeleteHoliday(ctx context.Context, schoolID, id uint) error
+	ImportHolidays(ctx context.Context, schoolID uint, filename string, file io.Reader, fileSize int64, defaultType string) (*HolidayImportResult, error)
+
+	// Schedule override operations
+	GetOverrides(ctx context.Context, schoolID uint, scheduleID *uint) (*OverrideListResponse, error)
+	CreateOverride(ctx context.Context, schoolID uint, req CreateOverrideRequest) (*OverrideResponse, error)
+	UpdateOverride(ctx context.Context, schoolID, id uint, req UpdateOverrideRequest) (*OverrideResponse, error)
+	DeleteOverride(ctx context.Context, schoolID, id uint) error
 }
 
 // service implements the Service interface
@@ -86,6 +103,11 @@ func (s *service) CreateSchedule(ctx context.Context, schoolID uint, req CreateS
 		return nil, ErrScheduleTimeOverlap
 	}
 
+	scheduleType := models.ScheduleTypeCheckIn
+	if req.Type != "" {
+		scheduleType = models.ScheduleType(req.Type)
+	}
+
 	// Create schedule model
 	schedule := &models.AttendanceSchedule{
 		SchoolID:          schoolID,
@@ -95,9 +117,13 @@ func (s *service) CreateSchedule(ctx context.Context, schoolID uint, req CreateS
 		LateThreshold:     req.LateThreshold,
 		VeryLateThreshold: req.VeryLateThreshold,
 		DaysOfWeek:        daysOfWeek,
+		Type:              scheduleType,
 		IsActive:          true,
 		IsDefault:         false,
 	}
+	if scheduleType == models.ScheduleTypeCheckOut && req.DismissalTime != nil && *req.DismissalTime != "" {
+		schedule.DismissalTime = req.DismissalTime
+	}
 
 	// Override IsActive if provided
 	if req.IsActive != nil {
@@ -177,6 +203,24 @@ func (s *service) UpdateSchedule(ctx context.Context, schoolID, id uint, req Upd
 	if req.IsActive != nil {
 		schedule.IsActive = *req.IsActive
 	}
+	if req.Type != nil {
+		schedule.Type = models.ScheduleType(*req.Type)
+	}
+	if req.DismissalTime != nil {
+		if *req.DismissalTime == "" {
+			schedule.DismissalTime = nil
+		} else {
+			schedule.DismissalTime = req.DismissalTime
+		}
+	}
+	if !schedule.IsCheckOut() {
+		schedule.DismissalTime = nil
+	}
+
+	// Validate check-out settings
+	if err := s.validateCheckOutSettings(string(schedule.Type), schedule.StartTime, schedule.EndTime, schedule.DismissalTime); err != nil {
+		return nil, err
+	}
 
 	// Validate the updated model
 	if err := schedule.Validate(); err != nil {
@@ -236,7 +280,20 @@ func (s *service) DeleteSchedule(ctx context.Context, schoolID, id uint) error {
 // GetActiveSchedule finds the active schedule for a given time
 // Requirements: 3.4 - Determine which schedule is currently active based on current time
 // Requirements: 3.6 - IF no schedule is active, use default schedule or reject
+// No schedule is active on a holiday
 func (s *service) GetActiveSchedule(ctx context.Context, schoolID uint, timestamp time.Time) (*ActiveScheduleResponse, error) {
+	holiday, err := s.repo.FindHolidayOn(ctx, schoolID, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if holiday != nil {
+		return &ActiveScheduleResponse{
+			Schedule:  nil,
+			Message:   "Hari libur: " + holiday.Name,
+			IsHoliday: true,
+		}, nil
+	}
+
 	schedule, err := s.repo.FindActiveSchedule(ctx, schoolID, timestamp)
 	if err != nil {
 		return nil, err
@@ -311,6 +368,38 @@ func (s *service) validateCreateRequest(req CreateScheduleRequest) error {
 		return ErrVeryLateThreshold
 	}
 
+	scheduleType := req.Type
+	if scheduleType == "" {
+		scheduleType = string(models.ScheduleTypeCheckIn)
+	}
+	return s.validateCheckOutSettings(scheduleType, req.StartTime, req.EndTime, req.DismissalTime)
+}
+
+// validateCheckOutSettings validates the schedule type and the dismissal time of check-out schedules
+// The dismissal time must fall inside the check-out window; taps before it are flagged as early leave
+func (s *service) validateCheckOutSettings(scheduleType, startTime, endTime string, dismissalTime *string) error {
+	if !models.ScheduleType(scheduleType).IsValid() {
+		return ErrInvalidScheduleType
+	}
+	if models.ScheduleType(scheduleType) != models.ScheduleTypeCheckOut || dismissalTime == nil || *dismissalTime == "" {
+		return nil
+	}
+
+	dismissal, err := parseTimeString(*dismissalTime)
+	if err != nil {
+		return ErrInvalidDismissalTime
+	}
+	start, err := parseTimeString(startTime)
+	if err != nil {
+		return ErrInvalidStartTime
+	}
+	end, err := parseTimeString(endTime)
+	if err != nil {
+		return ErrInvalidEndTime
+	}
+	if dismissal.Before(start) || dismissal.After(end) {
+		return ErrDismissalOutOfRange
+	}
 	return nil
 }
 
@@ -365,6 +454,8 @@ func toScheduleResponse(schedule *models.AttendanceSchedule) *ScheduleResponse {
 		LateThreshold:     schedule.LateThreshold,
 		VeryLateThreshold: schedule.VeryLateThreshold,
 		DaysOfWeek:        schedule.DaysOfWeek,
+		Type:              string(schedule.Type),
+		DismissalTime:     schedule.DismissalTime,
 		IsActive:          schedule.IsActive,
 		IsDefault:         schedule.IsDefault,
 		CreatedAt:         schedule.CreatedAt,
@@ -32,6 +32,8 @@ type ClassResponse struct {
 	HomeroomTeacher   *TeacherResponse   `json:"homeroom_teacher,omitempty"`
 	Counselors        []CounselorResponse `json:"counselors,omitempty"`
 	StudentCount      int64              `json:"student_count"`
+	IsArchived        bool               `json:"is_archived"`
+	ArchivedAt        *time.Time         `json:"archived_at,omitempty"`
 	CreatedAt         time.Time          `json:"created_at"`
 	UpdatedAt         time.Time          `json:"updated_at"`
 }
@@ -47,6 +49,7 @@ type ClassFilter struct {
 	Name     string `query:"name"`
 	Grade    *int   `query:"grade"`
 	Year     string `query:"year"`
+	Archived *bool  `query:"archived"`
 	Page     int    `query:"page"`
 	PageSize int    `query:"page_size"`
 }
@@ -97,6 +100,7 @@ type StudentResponse struct {
 	Name              string         `json:"name"`
 	RFIDCode          string         `json:"rfid_code"`
 	IsActive          bool           `json:"is_active"`
+	GraduatedAt       *time.Time     `json:"graduated_at,omitempty"`
 	HasAccount        bool           `json:"has_account"`
 	Username          string         `json:"username,omitempty"`
 	TemporaryPassword string         `json:"temporary_password,omitempty"`
@@ -298,6 +302,7 @@ type UserResponse struct {
 	AssignedClassName  string              `json:"assigned_class_name,omitempty"`  // For wali_kelas
 	AssignedClasses    []AssignedClassInfo `json:"assigned_classes,omitempty"`     // For guru_bk
 	LastLoginAt        *string             `json:"last_login_at,omitempty"`
+	LockedUntil        *time.Time          `json:"locked_until,omitempty"` // Set while failed logins keep the account locked
 	CreatedAt          time.Time           `json:"created_at"`
 	UpdatedAt          time.Time           `json:"updated_at"`
 }
@@ -316,7 +321,7 @@ type UserListResponse struct {
 
 // CreateUserRequest represents the request to create a new user
 type CreateUserRequest struct {
-	Role             string `json:"role" validate:"required,oneof=wali_kelas guru_bk admin_sekolah"`
+	Role             string `json:"role" validate:"required,oneof=wali_kelas guru_bk guru admin_sekolah"`
 	Username         string `json:"username" validate:"required"`
 	Email            string `json:"email"`
 	Name             string `json:"name"`
@@ -341,6 +346,7 @@ type UpdateUserRequest struct {
 type BulkAssignClassRequest struct {
 	StudentIDs []uint `json:"student_ids" validate:"required,min=1"`
 	ClassID    uint   `json:"class_id" validate:"required"`
+	Reason     string `json:"reason"` // new, transfer_in, promoted or repeated; defaults to new for a first class and transfer_in otherwise
 }
 
 // BulkAssignClassResponse represents the result of bulk class assignment
@@ -350,6 +356,27 @@ type BulkAssignClassResponse struct {
 	Message      string            `json:"message"`
 }
 
+// ==================== Enrollment DTOs ====================
+
+// WithdrawStudentRequest represents the request to take a student out of their class
+type WithdrawStudentRequest struct {
+	Reason string `json:"reason" validate:"required"` // transfer_out, dropped_out or graduated
+	Date   string `json:"date"`                       // YYYY-MM-DD, defaults to today
+}
+
+// EnrollmentResponse represents a period a student spent in a class
+type EnrollmentResponse struct {
+	ID           uint    `json:"id"`
+	ClassID      uint    `json:"class_id"`
+	ClassName    string  `json:"class_name"`
+	Grade        int     `json:"grade"`
+	AcademicYear string  `json:"academic_year"`
+	StartDate    string  `json:"start_date"`
+	EndDate      *string `json:"end_date"`
+	Reason       string  `json:"reason"`
+	EndReason    string  `json:"end_reason,omitempty"`
+}
+
 // ==================== Device DTOs ====================
 
 // DeviceResponse represents the device data in responses
@@ -376,3 +403,75 @@ type StudentSearchResponse struct {
 	ClassName string `json:"class_name,omitempty"`
 	ClassID   *uint  `json:"class_id,omitempty"`
 }
+
+// ==================== Academic Year Rollover DTOs ====================
+
+// RolloverRequest represents the request to roll the school over into a new academic year
+type RolloverRequest struct {
+	FromYear   string              `json:"from_year"`   // Defaults to the academic year of the school settings
+	ToYear     string              `json:"to_year"`     // Defaults to the year after FromYear
+	FinalGrade int                 `json:"final_grade"` // Students of this grade graduate; defaults to the highest grade of FromYear
+	KeepStaff  bool                `json:"keep_staff"`  // Copy homeroom teachers and BK counselors to the cloned classes
+	Exceptions []RolloverException `json:"exceptions"`
+}
+
+// RolloverException overrides the default action of one student (e.g. a repeater)
+type RolloverException struct {
+	StudentID uint   `json:"student_id"`
+	Action    string `json:"action"` // promote, repeat or graduate
+}
+
+// RolloverClassPlan describes how a FromYear class is rolled over
+type RolloverClassPlan struct {
+	ClassID      uint   `json:"class_id"`
+	Name         string `json:"name"`
+	Grade        int    `json:"grade"`
+	StudentCount int    `json:"student_count"`
+	CloneExists  bool   `json:"clone_exists"`          // The class already exists in ToYear and is reused
+	PromotedTo   string `json:"promoted_to,omitempty"` // ToYear class its students are promoted into
+}
+
+// RolloverStudentPlan describes where a student goes
+type RolloverStudentPlan struct {
+	StudentID     uint   `json:"student_id"`
+	NIS           string `json:"nis"`
+	Name          string `json:"name"`
+	FromClassID   uint   `json:"from_class_id"`
+	FromClassName string `json:"from_class_name"`
+	Action        string `json:"action"`
+	IsException   bool   `json:"is_exception"`
+	ToClassID     *uint  `json:"to_class_id,omitempty"`   // Set once the rollover is executed
+	ToClassName   string `json:"to_class_name,omitempty"` // Empty for graduates and unplaced students
+}
+
+// RolloverSummary counts the outcome of a rollover
+type RolloverSummary struct {
+	ClassesCreated  int `json:"classes_created"`
+	ClassesArchived int `json:"classes_archived"`
+	Promoted        int `json:"promoted"`
+	Repeated        int `json:"repeated"`
+	Graduated       int `json:"graduated"`
+	Unplaced        int `json:"unplaced"` // Promoted without a matching ToYear class, to be placed with bulk-assign-class
+}
+
+// RolloverPlanResponse represents the preview or the result of a rollover
+type RolloverPlanResponse struct {
+	ID         *uint                 `json:"id,omitempty"` // Set once the rollover is executed
+	FromYear   string                `json:"from_year"`
+	ToYear     string                `json:"to_year"`
+	FinalGrade int                   `json:"final_grade"`
+	Classes    []RolloverClassPlan   `json:"classes"`
+	Students   []RolloverStudentPlan `json:"students"`
+	Summary    RolloverSummary       `json:"summary"`
+}
+
+// RolloverHistoryResponse represents a past rollover
+type RolloverHistoryResponse struct {
+	ID            uint            `json:"id"`
+	FromYear      string          `json:"from_year"`
+	ToYear        string          `json:"to_year"`
+	Summary       RolloverSummary `json:"summary"`
+	PerformedBy   uint            `json:"performed_by"`
+	PerformerName string          `json:"performer_name"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
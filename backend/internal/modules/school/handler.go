@@ -51,6 +51,8 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 	students.Post("/:id/account", h.CreateStudentAccount)
 	students.Post("/:id/reset-password", h.ResetStudentPassword)
 	students.Post("/:id/clear-rfid", h.ClearStudentRFID)
+	students.Post("/:id/withdraw", h.WithdrawStudent)
+	students.Get("/:id/enrollments", h.GetStudentEnrollments)
 
 	// Parent routes
 	parents := router.Group("/parents")
@@ -70,6 +72,9 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 	users.Put("/:id", h.UpdateUser)
 	users.Delete("/:id", h.DeleteUser)
 	users.Post("/:id/reset-password", h.ResetUserPassword)
+	users.Delete("/:id/sessions", h.RevokeUserSessions)
+	users.Post("/:id/unlock", h.UnlockUser)
+	users.Delete("/:id/two-factor", h.ResetUserTwoFactor)
 }
 
 // GetStats handles getting school statistics for dashboard
@@ -163,6 +168,7 @@ func (h *Handler) CreateClass(c *fiber.Ctx) error {
 // @Param name query string false "Filter by class name"
 // @Param grade query int false "Filter by grade"
 // @Param year query string false "Filter by academic year"
+// @Param archived query bool false "Filter by archived status"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
 // @Success 200 {object} ClassListResponse
@@ -191,6 +197,9 @@ func (h *Handler) GetClasses(c *fiber.Ctx) error {
 			filter.Grade = &grade
 		}
 	}
+	if archived, err := strconv.ParseBool(c.Query("archived")); err == nil {
+		filter.Archived = &archived
+	}
 	if page, err := strconv.Atoi(c.Query("page", "1")); err == nil && page > 0 {
 		filter.Page = page
 	}
@@ -1243,6 +1252,14 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 				"message": "Tidak dapat menghapus kelas yang masih memiliki siswa. Pindahkan atau hapus siswa terlebih dahulu.",
 			},
 		})
+	case errors.Is(err, ErrClassArchived):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "CLASS_ARCHIVED",
+				"message": "Kelas tahun ajaran sebelumnya sudah diarsipkan dan hanya dapat dibaca",
+			},
+		})
 
 	// Student errors
 	case errors.Is(err, ErrStudentNotFound):
@@ -1397,6 +1414,30 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 				"message": "Siswa sudah memiliki akun",
 			},
 		})
+	case errors.Is(err, ErrInvalidReason):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_REASON",
+				"message": "Kode alasan perpindahan tidak valid",
+			},
+		})
+	case errors.Is(err, ErrInvalidDate):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format tanggal harus YYYY-MM-DD",
+			},
+		})
+	case errors.Is(err, ErrStudentNotEnrolled):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_NOT_ENROLLED",
+				"message": "Siswa tidak sedang terdaftar di kelas",
+			},
+		})
 	case errors.Is(err, ErrStudentNoAccount):
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
@@ -1722,6 +1763,168 @@ func (h *Handler) ResetUserPassword(c *fiber.Ctx) error {
 	})
 }
 
+// RevokeUserSessions handles signing a user out of all devices
+// @Summary Revoke user sessions
+// @Description End all sessions of a user, e.g. after a phone was lost. Refresh tokens of the user stop working; access tokens expire on their own.
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/school/users/{id}/sessions [delete]
+func (h *Handler) RevokeUserSessions(c *fiber.Ctx) error {
+	schoolID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID user tidak valid",
+			},
+		})
+	}
+
+	revoked, err := h.service.RevokeUserSessions(c.Context(), schoolID, uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"revoked": revoked,
+		},
+		"message": "Semua sesi user berhasil diakhiri",
+	})
+}
+
+// UnlockUser handles lifting the login lock of a user
+// @Summary Unlock user
+// @Description Lift the temporary lock of a user after too many failed logins
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/school/users/{id}/unlock [post]
+func (h *Handler) UnlockUser(c *fiber.Ctx) error {
+	schoolID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID user tidak valid",
+			},
+		})
+	}
+
+	adminID, _ := c.Locals("userID").(uint)
+	unlocked, err := h.service.UnlockUser(c.Context(), schoolID, uint(id), adminID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	message := "Akun tidak sedang terkunci"
+	if unlocked {
+		message = "Kunci akun berhasil dibuka"
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"unlocked": unlocked,
+		},
+		"message": message,
+	})
+}
+
+// ResetUserTwoFactor handles turning 2FA off for a user
+// @Summary Reset user two-factor
+// @Description Remove the authenticator app and recovery codes of a user who lost both, so the user can sign in with the password again
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/school/users/{id}/two-factor [delete]
+func (h *Handler) ResetUserTwoFactor(c *fiber.Ctx) error {
+	schoolID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID user tidak valid",
+			},
+		})
+	}
+
+	adminID, _ := c.Locals("userID").(uint)
+	removed, err := h.service.ResetUserTwoFactor(c.Context(), schoolID, uint(id), adminID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	message := "User belum mengaktifkan autentikasi dua langkah"
+	if removed {
+		message = "Autentikasi dua langkah user berhasil direset"
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"reset": removed,
+		},
+		"message": message,
+	})
+}
+
 // GetSchoolDevices handles getting all devices for the school
 // @Summary Get school devices
 // @Description Get all RFID devices registered for this school
@@ -1755,6 +1958,112 @@ func (h *Handler) GetSchoolDevices(c *fiber.Ctx) error {
 	})
 }
 
+// WithdrawStudent handles taking a student out of their class
+// @Summary Withdraw student
+// @Description Take a student out of their class because they transferred out, dropped out or graduated. The student is deactivated and their enrollment is closed on the date
+// @Tags Students
+// @Accept json
+// @Produce json
+// @Param id path int true "Student ID"
+// @Param request body WithdrawStudentRequest true "Reason and date"
+// @Success 200 {object} StudentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/school/students/{id}/withdraw [post]
+func (h *Handler) WithdrawStudent(c *fiber.Ctx) error {
+	schoolID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID siswa tidak valid",
+			},
+		})
+	}
+
+	var req WithdrawStudentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "Format request tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.WithdrawStudent(c.Context(), schoolID, uint(id), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Siswa berhasil dikeluarkan dari kelas",
+	})
+}
+
+// GetStudentEnrollments handles getting a student's class timeline
+// @Summary Get student enrollments
+// @Description Get the classes a student has been in with start and end dates and reason codes, oldest first
+// @Tags Students
+// @Produce json
+// @Param id path int true "Student ID"
+// @Success 200 {array} EnrollmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/school/students/{id}/enrollments [get]
+func (h *Handler) GetStudentEnrollments(c *fiber.Ctx) error {
+	schoolID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "AUTHZ_TENANT_REQUIRED",
+				"message": "Konteks sekolah diperlukan",
+			},
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_FORMAT",
+				"message": "ID siswa tidak valid",
+			},
+		})
+	}
+
+	response, err := h.service.GetStudentEnrollments(c.Context(), schoolID, uint(id))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
 // ClearStudentRFID handles clearing a student's RFID code
 // @Summary Clear student RFID
 // @Description Clear the RFID code from a student (unpair the card)
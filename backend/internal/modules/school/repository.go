@@ -3,10 +3,14 @@ package school
 import (
 	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/audit"
+	"github.com/school-management/backend/internal/shared/enrollment"
+	"github.com/school-management/backend/internal/shared/session"
 )
 
 var (
@@ -41,6 +45,8 @@ type Repository interface {
 	UpdateStudent(ctx context.Context, student *models.Student) error
 	UpdateStudentUserID(ctx context.Context, studentID uint, userID uint) error
 	DeleteStudent(ctx context.Context, schoolID uint, id uint) error
+	WithdrawStudent(ctx context.Context, student *models.Student, reason models.EnrollmentReason, date time.Time) error
+	FindStudentEnrollments(ctx context.Context, studentID uint) ([]models.StudentEnrollment, error)
 
 	// Parent operations
 	CreateParent(ctx context.Context, parent *models.Parent) error
@@ -69,6 +75,9 @@ type Repository interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	UpdateUser(ctx context.Context, user *models.User) error
 	DeleteUser(ctx context.Context, schoolID uint, id uint) error
+	RevokeUserSessions(ctx context.Context, userID uint) (int64, error)
+	ResetUserTwoFactor(ctx context.Context, userID uint) (bool, error)
+	RecordUserUnlock(ctx context.Context, user *models.User, wasLocked bool) error
 
 	// Class Counselor operations
 	FindClassCounselorsByClass(ctx context.Context, schoolID uint, classID uint) ([]models.ClassCounselor, error)
@@ -86,7 +95,7 @@ type Repository interface {
 
 	// Bulk operations for import
 	FindStudentsWithoutClass(ctx context.Context, schoolID uint) ([]models.Student, error)
-	BulkUpdateStudentClass(ctx context.Context, studentIDs []uint, classID uint) error
+	BulkUpdateStudentClass(ctx context.Context, studentIDs []uint, classID uint, reason models.EnrollmentReason) error
 
 	// Search operations for parent linking
 	SearchStudents(ctx context.Context, schoolID uint, query string, limit int) ([]models.Student, error)
@@ -128,6 +137,9 @@ func (r *repository) FindAllClasses(ctx context.Context, schoolID uint, filter C
 	if filter.Year != "" {
 		query = query.Where("year = ?", filter.Year)
 	}
+	if filter.Archived != nil {
+		query = query.Where("is_archived = ?", *filter.Archived)
+	}
 
 	// Count total records
 	if err := query.Count(&total).Error; err != nil {
@@ -215,17 +227,24 @@ func (r *repository) UpdateClass(ctx context.Context, class *models.Class) error
 
 // DeleteClass deletes a class
 func (r *repository) DeleteClass(ctx context.Context, schoolID uint, id uint) error {
-	result := r.db.WithContext(ctx).
-		Where("id = ? AND school_id = ?", id, schoolID).
-		Delete(&models.Class{})
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Past enrollments of the students who moved out go with the class
+		if err := tx.Where("class_id = ? AND school_id = ?", id, schoolID).
+			Delete(&models.StudentEnrollment{}).Error; err != nil {
+			return err
+		}
 
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return ErrClassNotFound
-	}
-	return nil
+		result := tx.Where("id = ? AND school_id = ?", id, schoolID).
+			Delete(&models.Class{})
+
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrClassNotFound
+		}
+		return nil
+	})
 }
 
 // GetClassStudentCount returns the number of students in a class
@@ -244,7 +263,15 @@ func (r *repository) GetClassStudentCount(ctx context.Context, classID uint) (in
 // CreateStudent creates a new student
 // Requirements: 3.2 - WHEN an Admin_Sekolah registers a student, THE System SHALL require NIS, NISN, name, and class assignment
 func (r *repository) CreateStudent(ctx context.Context, student *models.Student) error {
-	return r.db.WithContext(ctx).Create(student).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(student).Error; err != nil {
+			return err
+		}
+		return enrollment.Sync(ctx, tx, enrollment.Change{
+			StudentIDs: []uint{student.ID},
+			Date:       student.CreatedAt,
+		})
+	})
 }
 
 // FindAllStudents retrieves all students for a school with pagination and filtering
@@ -366,25 +393,31 @@ func (r *repository) FindStudentsByClass(ctx context.Context, classID uint) ([]m
 }
 
 // UpdateStudent updates a student
+// A class change closes the student's enrollment in the previous class.
 func (r *repository) UpdateStudent(ctx context.Context, student *models.Student) error {
-	result := r.db.WithContext(ctx).
-		Model(&models.Student{}).
-		Where("id = ?", student.ID).
-		Updates(map[string]interface{}{
-			"class_id":   student.ClassID,
-			"nis":        student.NIS,
-			"nisn":       student.NISN,
-			"name":       student.Name,
-			"rf_id_code": student.RFIDCode,
-			"is_active":  student.IsActive,
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Model(&models.Student{}).
+			Where("id = ?", student.ID).
+			Updates(map[string]interface{}{
+				"class_id":   student.ClassID,
+				"nis":        student.NIS,
+				"nisn":       student.NISN,
+				"name":       student.Name,
+				"rf_id_code": student.RFIDCode,
+				"is_active":  student.IsActive,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStudentNotFound
+		}
+		return enrollment.Sync(ctx, tx, enrollment.Change{
+			StudentIDs: []uint{student.ID},
+			Date:       time.Now(),
 		})
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return ErrStudentNotFound
-	}
-	return nil
+	})
 }
 
 // UpdateStudentUserID links a user account to a student
@@ -414,6 +447,35 @@ func (r *repository) DeleteStudent(ctx context.Context, schoolID uint, id uint)
 	return nil
 }
 
+// WithdrawStudent takes a student out of their class and ends their enrollment
+// Graduates are marked graduated; students who transfer out or drop out are only deactivated.
+func (r *repository) WithdrawStudent(ctx context.Context, student *models.Student, reason models.EnrollmentReason, date time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"class_id":  nil,
+			"is_active": false,
+		}
+		if reason == models.EnrollmentReasonGraduated {
+			updates["graduated_at"] = date
+		}
+		if err := tx.Model(&models.Student{}).
+			Where("id = ?", student.ID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		return enrollment.Sync(ctx, tx, enrollment.Change{
+			StudentIDs: []uint{student.ID},
+			Date:       date,
+			EndReason:  reason,
+		})
+	})
+}
+
+// FindStudentEnrollments retrieves the class timeline of a student, oldest first
+func (r *repository) FindStudentEnrollments(ctx context.Context, studentID uint) ([]models.StudentEnrollment, error) {
+	return enrollment.Timeline(ctx, r.db, studentID)
+}
+
 
 // ==================== Parent Repository Methods ====================
 
@@ -537,18 +599,22 @@ func (r *repository) UpdateParentUserEmail(ctx context.Context, userID uint, ema
 }
 
 // ResetUserPassword resets a user's password and sets must_reset_pwd to true
+// The user's sessions end with it, so devices signed in with the old password have to log in again.
 func (r *repository) ResetUserPassword(ctx context.Context, userID uint, passwordHash string) error {
-	result := r.db.WithContext(ctx).
-		Model(&models.User{}).
-		Where("id = ?", userID).
-		Updates(map[string]interface{}{
-			"password_hash":  passwordHash,
-			"must_reset_pwd": true,
-		})
-	if result.Error != nil {
-		return result.Error
-	}
-	return nil
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"password_hash":  passwordHash,
+				"must_reset_pwd": true,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		_, err := session.RevokeUser(ctx, tx, userID, models.SessionRevokedPasswordReset)
+		return err
+	})
 }
 
 // DeleteParent deletes a parent
@@ -688,12 +754,13 @@ func (r *repository) GetSchoolStats(ctx context.Context, schoolID uint) (*School
 		return nil, err
 	}
 
-	// Count total teachers (users with role wali_kelas or guru_bk)
+	// Count total teachers (users with role wali_kelas, guru_bk or guru)
 	if err := r.db.WithContext(ctx).
 		Model(&models.User{}).
 		Where("school_id = ? AND role IN ?", schoolID, []models.UserRole{
 			models.RoleWaliKelas,
 			models.RoleGuruBK,
+			models.RoleGuru,
 		}).
 		Count(&stats.TotalTeachers).Error; err != nil {
 		return nil, err
@@ -827,30 +894,43 @@ func (r *repository) FindUserByID(ctx context.Context, schoolID uint, id uint) (
 	return &user, nil
 }
 
+// RecordUserUnlock records in the audit trail that the signed-in admin lifted the login lock of a user
+func (r *repository) RecordUserUnlock(ctx context.Context, user *models.User, wasLocked bool) error {
+	return audit.RecordUnlock(ctx, r.db, user, wasLocked)
+}
+
 // CreateUser creates a new user
 func (r *repository) CreateUser(ctx context.Context, user *models.User) error {
 	return r.db.WithContext(ctx).Create(user).Error
 }
 
 // UpdateUser updates a user
+// Deactivating a user ends all of the user's sessions.
 func (r *repository) UpdateUser(ctx context.Context, user *models.User) error {
-	result := r.db.WithContext(ctx).
-		Model(&models.User{}).
-		Where("id = ?", user.ID).
-		Updates(map[string]interface{}{
-			"email":         user.Email,
-			"name":          user.Name,
-			"is_active":     user.IsActive,
-			"password_hash": user.PasswordHash,
-			"must_reset_pwd": user.MustResetPwd,
-		})
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return ErrUserNotFound
-	}
-	return nil
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"email":         user.Email,
+				"name":          user.Name,
+				"is_active":     user.IsActive,
+				"password_hash": user.PasswordHash,
+				"must_reset_pwd": user.MustResetPwd,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+
+		if !user.IsActive {
+			if _, err := session.RevokeUser(ctx, tx, user.ID, models.SessionRevokedDeactivated); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // DeleteUser deletes a user
@@ -868,11 +948,37 @@ func (r *repository) DeleteUser(ctx context.Context, schoolID uint, id uint) err
 	return nil
 }
 
+// RevokeUserSessions ends all sessions of a user, e.g. when a phone is lost
+func (r *repository) RevokeUserSessions(ctx context.Context, userID uint) (int64, error) {
+	return session.RevokeUser(ctx, r.db, userID, models.SessionRevokedByAdmin)
+}
+
+// ResetUserTwoFactor removes the authenticator app and recovery codes of a user
+// It returns whether the user had an authenticator app, confirmed or not.
+func (r *repository) ResetUserTwoFactor(ctx context.Context, userID uint) (bool, error) {
+	removed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		removed = result.RowsAffected > 0
+		return nil
+	})
+
+	return removed, err
+}
+
 // FindClassByHomeroomTeacher finds the class assigned to a homeroom teacher
 func (r *repository) FindClassByHomeroomTeacher(ctx context.Context, schoolID uint, teacherID uint) (*models.Class, error) {
 	var class models.Class
 	err := r.db.WithContext(ctx).
-		Where("school_id = ? AND homeroom_teacher_id = ?", schoolID, teacherID).
+		Where("school_id = ? AND homeroom_teacher_id = ? AND is_archived = ?", schoolID, teacherID, false).
 		First(&class).Error
 
 	if err != nil {
@@ -1008,7 +1114,7 @@ func (r *repository) ClearStudentRFID(ctx context.Context, studentID uint) error
 func (r *repository) FindStudentsWithoutClass(ctx context.Context, schoolID uint) ([]models.Student, error) {
 	var students []models.Student
 	err := r.db.WithContext(ctx).
-		Where("school_id = ? AND class_id IS NULL", schoolID).
+		Where("school_id = ? AND class_id IS NULL AND graduated_at IS NULL", schoolID).
 		Order("name ASC").
 		Find(&students).Error
 
@@ -1021,24 +1127,30 @@ func (r *repository) FindStudentsWithoutClass(ctx context.Context, schoolID uint
 
 // BulkUpdateStudentClass updates ClassID and IsActive for multiple students
 // Requirements: 6.3, 6.4 - Bulk class assignment with IsActive update
-func (r *repository) BulkUpdateStudentClass(ctx context.Context, studentIDs []uint, classID uint) error {
+func (r *repository) BulkUpdateStudentClass(ctx context.Context, studentIDs []uint, classID uint, reason models.EnrollmentReason) error {
 	if len(studentIDs) == 0 {
 		return nil
 	}
 
-	result := r.db.WithContext(ctx).
-		Model(&models.Student{}).
-		Where("id IN ?", studentIDs).
-		Updates(map[string]interface{}{
-			"class_id":  classID,
-			"is_active": true,
-		})
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Model(&models.Student{}).
+			Where("id IN ?", studentIDs).
+			Updates(map[string]interface{}{
+				"class_id":  classID,
+				"is_active": true,
+			})
 
-	if result.Error != nil {
-		return result.Error
-	}
+		if result.Error != nil {
+			return result.Error
+		}
 
-	return nil
+		return enrollment.Sync(ctx, tx, enrollment.Change{
+			StudentIDs: studentIDs,
+			Date:       time.Now(),
+			Reason:     reason,
+		})
+	})
 }
 
 // SearchStudents searches students by NISN or name within a school
@@ -0,0 +1,187 @@
+package school
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/school-management/backend/internal/middleware"
+)
+
+// RolloverHandler handles HTTP requests for the academic year rollover
+type RolloverHandler struct {
+	service RolloverService
+}
+
+// NewRolloverHandler creates a new academic year rollover handler
+func NewRolloverHandler(service RolloverService) *RolloverHandler {
+	return &RolloverHandler{service: service}
+}
+
+// RegisterRoutes registers academic year rollover routes (Admin Sekolah)
+func (h *RolloverHandler) RegisterRoutes(router fiber.Router) {
+	rollover := router.Group("/academic-year", middleware.AdminSekolahOnly())
+	rollover.Post("/rollover/preview", h.PreviewRollover)
+	rollover.Post("/rollover", h.ExecuteRollover)
+	rollover.Get("/rollovers", h.GetRollovers)
+}
+
+// PreviewRollover handles previewing the academic year rollover
+// @Summary Preview academic year rollover
+// @Description Show the classes that will be cloned into the new academic year and where every student goes, without saving anything
+// @Tags Academic Year
+// @Accept json
+// @Produce json
+// @Param request body RolloverRequest true "Rollover options and per-student exceptions"
+// @Success 200 {object} RolloverPlanResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/school/academic-year/rollover/preview [post]
+func (h *RolloverHandler) PreviewRollover(c *fiber.Ctx) error {
+	schoolID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return h.tenantRequired(c)
+	}
+
+	var req RolloverRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBody(c)
+	}
+
+	response, err := h.service.PreviewRollover(c.Context(), schoolID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// ExecuteRollover handles rolling the school over into a new academic year
+// @Summary Execute academic year rollover
+// @Description Clone the classes into the new academic year, promote students (repeaters and graduates as exceptions), archive the previous year's classes read-only and switch the school settings to semester 1 of the new year
+// @Tags Academic Year
+// @Accept json
+// @Produce json
+// @Param request body RolloverRequest true "Rollover options and per-student exceptions"
+// @Success 201 {object} RolloverPlanResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/school/academic-year/rollover [post]
+func (h *RolloverHandler) ExecuteRollover(c *fiber.Ctx) error {
+	schoolID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return h.tenantRequired(c)
+	}
+	userID, _ := middleware.GetUserID(c)
+
+	var req RolloverRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBody(c)
+	}
+
+	response, err := h.service.ExecuteRollover(c.Context(), schoolID, userID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Tahun ajaran baru berhasil dimulai",
+	})
+}
+
+// GetRollovers handles listing past rollovers
+// @Summary List academic year rollovers
+// @Description Get the school's past academic year rollovers
+// @Tags Academic Year
+// @Produce json
+// @Success 200 {array} RolloverHistoryResponse
+// @Security BearerAuth
+// @Router /api/v1/school/academic-year/rollovers [get]
+func (h *RolloverHandler) GetRollovers(c *fiber.Ctx) error {
+	schoolID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return h.tenantRequired(c)
+	}
+
+	response, err := h.service.GetRollovers(c.Context(), schoolID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+func (h *RolloverHandler) tenantRequired(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "AUTHZ_TENANT_REQUIRED",
+			"message": "Konteks sekolah diperlukan",
+		},
+	})
+}
+
+func (h *RolloverHandler) invalidBody(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "VAL_INVALID_FORMAT",
+			"message": "Format request tidak valid",
+		},
+	})
+}
+
+func (h *RolloverHandler) handleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrRolloverYearInvalid), errors.Is(err, ErrRolloverYearOrder):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_PERIOD",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrRolloverActionInvalid), errors.Is(err, ErrRolloverStudentNotFound):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_EXCEPTION",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrRolloverNoClasses):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_NO_CLASSES",
+				"message": err.Error(),
+			},
+		})
+	case errors.Is(err, ErrRolloverAlreadyDone):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "ROLLOVER_ALREADY_DONE",
+				"message": err.Error(),
+			},
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "INTERNAL_ERROR",
+				"message": "Terjadi kesalahan pada server",
+			},
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package school
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/audit"
+	"github.com/school-management/backend/internal/shared/enrollment"
+)
+
+// historyTables are the per-student records whose class is frozen at the rollover, with the
+// date each record was made on. Their class_id stays NULL until then, and is frozen to the
+// class the timeline had the student in on that date, not the class at the rollover.
+var historyTables = []struct {
+	name     string
+	dateExpr string
+}{
+	{"attendances", "attendances.date"},
+	{"grades", "grades.created_at::date"},
+	{"violations", "violations.created_at::date"},
+	{"achievements", "achievements.created_at::date"},
+	{"permits", "permits.exit_time::date"},
+	{"counseling_notes", "counseling_notes.created_at::date"},
+	{"homeroom_notes", "homeroom_notes.created_at::date"},
+}
+
+// RolloverClone is a FromYear class cloned into ToYear
+type RolloverClone struct {
+	Class        *models.Class
+	CounselorIDs []uint
+}
+
+// RolloverChanges is everything written by an academic year rollover
+type RolloverChanges struct {
+	Rollover       *models.AcademicYearRollover
+	SourceClassIDs []uint // FromYear classes, archived read-only
+	Clones         []RolloverClone
+	Promotions     map[*models.Class][]uint // ToYear class => students promoted into it
+	Repeats        map[*models.Class][]uint // ToYear class => students repeating the grade in it
+	Graduates      []uint
+	Unplaced       []uint
+}
+
+// RolloverRepository defines data operations for academic year rollovers
+type RolloverRepository interface {
+	FindClassesByYear(ctx context.Context, schoolID uint, year string) ([]models.Class, error)
+	FindStudentsInClasses(ctx context.Context, classIDs []uint) ([]models.Student, error)
+	FindCounselorIDs(ctx context.Context, classIDs []uint) (map[uint][]uint, error)
+	RolloverExists(ctx context.Context, schoolID uint, toYear string) (bool, error)
+	FindRollovers(ctx context.Context, schoolID uint) ([]models.AcademicYearRollover, error)
+
+	// Apply writes a rollover in a single transaction
+	Apply(ctx context.Context, changes *RolloverChanges) error
+}
+
+// rolloverRepository implements the RolloverRepository interface
+type rolloverRepository struct {
+	db *gorm.DB
+}
+
+// NewRolloverRepository creates a new academic year rollover repository
+func NewRolloverRepository(db *gorm.DB) RolloverRepository {
+	return &rolloverRepository{db: db}
+}
+
+// FindClassesByYear retrieves the classes of a school that are not archived for an academic year
+func (r *rolloverRepository) FindClassesByYear(ctx context.Context, schoolID uint, year string) ([]models.Class, error) {
+	var classes []models.Class
+	err := r.db.WithContext(ctx).
+		Where("school_id = ? AND year = ? AND is_archived = ?", schoolID, year, false).
+		Order("grade ASC, name ASC").
+		Find(&classes).Error
+	return classes, err
+}
+
+// FindStudentsInClasses retrieves the students of the given classes
+func (r *rolloverRepository) FindStudentsInClasses(ctx context.Context, classIDs []uint) ([]models.Student, error) {
+	var students []models.Student
+	if len(classIDs) == 0 {
+		return students, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("class_id IN ?", classIDs).
+		Order("class_id ASC, name ASC").
+		Find(&students).Error
+	return students, err
+}
+
+// FindCounselorIDs retrieves the BK counselors of the given classes, keyed by class ID
+func (r *rolloverRepository) FindCounselorIDs(ctx context.Context, classIDs []uint) (map[uint][]uint, error) {
+	counselorIDs := make(map[uint][]uint)
+	if len(classIDs) == 0 {
+		return counselorIDs, nil
+	}
+
+	var counselors []models.ClassCounselor
+	if err := r.db.WithContext(ctx).
+		Where("class_id IN ?", classIDs).
+		Find(&counselors).Error; err != nil {
+		return nil, err
+	}
+	for _, counselor := range counselors {
+		counselorIDs[counselor.ClassID] = append(counselorIDs[counselor.ClassID], counselor.CounselorID)
+	}
+	return counselorIDs, nil
+}
+
+// RolloverExists checks if a school has already been rolled over into an academic year
+func (r *rolloverRepository) RolloverExists(ctx context.Context, schoolID uint, toYear string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.AcademicYearRollover{}).
+		Where("school_id = ? AND to_year = ?", schoolID, toYear).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindRollovers retrieves the rollovers of a school, most recent first
+func (r *rolloverRepository) FindRollovers(ctx context.Context, schoolID uint) ([]models.AcademicYearRollover, error) {
+	var rollovers []models.AcademicYearRollover
+	err := r.db.WithContext(ctx).
+		Preload("Performer").
+		Where("school_id = ?", schoolID).
+		Order("created_at DESC").
+		Find(&rollovers).Error
+	return rollovers, err
+}
+
+// Apply writes a rollover in a single transaction
+// The record is created first so its unique index rejects a concurrent rollover into the same year.
+func (r *rolloverRepository) Apply(ctx context.Context, changes *RolloverChanges) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		rollover := changes.Rollover
+		if err := tx.Omit("School", "Performer").Create(rollover).Error; err != nil {
+			return err
+		}
+
+		// Freeze the class of past records before the students move
+		for _, history := range historyTables {
+			table := history.name
+			// class_id is NULL here, so ClassAt resolves the class from the timeline alone
+			classAt := enrollment.ClassAt(table, history.dateExpr)
+			filter := table + ".student_id = students.id AND " + table + ".class_id IS NULL AND students.class_id IN ? AND " + classAt + " IS NOT NULL"
+			result := tx.Exec(
+				"UPDATE "+table+" SET class_id = "+classAt+" FROM students WHERE "+filter,
+				changes.SourceClassIDs,
+			)
+			if result.Error != nil {
+				return result.Error
+			}
+			if err := audit.RecordBulk(ctx, tx, audit.BulkChange{
+				Action:       models.AuditActionUpdate,
+				Table:        table,
+				SchoolID:     rollover.SchoolID,
+				Filter:       filter,
+				Args:         []interface{}{changes.SourceClassIDs},
+				Set:          map[string]interface{}{"class_id": "class in the enrollment timeline on the record date"},
+				RowsAffected: result.RowsAffected,
+			}); err != nil {
+				return err
+			}
+		}
+
+		for _, clone := range changes.Clones {
+			if err := tx.Omit("School", "Students", "HomeroomTeacher", "Counselors").Create(clone.Class).Error; err != nil {
+				return err
+			}
+			for _, counselorID := range clone.CounselorIDs {
+				if err := tx.Create(&models.ClassCounselor{
+					ClassID:     clone.Class.ID,
+					CounselorID: counselorID,
+					SchoolID:    clone.Class.SchoolID,
+				}).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := r.place(ctx, tx, changes.Promotions, models.EnrollmentReasonPromoted, rollover); err != nil {
+			return err
+		}
+		if err := r.place(ctx, tx, changes.Repeats, models.EnrollmentReasonRepeated, rollover); err != nil {
+			return err
+		}
+
+		if len(changes.Graduates) > 0 {
+			if err := tx.Model(&models.Student{}).
+				Where("id IN ?", changes.Graduates).
+				Updates(map[string]interface{}{"class_id": nil, "is_active": false, "graduated_at": rollover.CreatedAt}).Error; err != nil {
+				return err
+			}
+			if err := enrollment.Sync(ctx, tx, enrollment.Change{
+				StudentIDs: changes.Graduates,
+				Date:       rollover.CreatedAt,
+				EndReason:  models.EnrollmentReasonGraduated,
+			}); err != nil {
+				return err
+			}
+		}
+
+		// Promoted out of a class that has no next grade to go to
+		if len(changes.Unplaced) > 0 {
+			if err := tx.Model(&models.Student{}).
+				Where("id IN ?", changes.Unplaced).
+				Updates(map[string]interface{}{"class_id": nil, "is_active": false}).Error; err != nil {
+				return err
+			}
+			if err := enrollment.Sync(ctx, tx, enrollment.Change{
+				StudentIDs: changes.Unplaced,
+				Date:       rollover.CreatedAt,
+				EndReason:  models.EnrollmentReasonPromoted,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.Class{}).
+			Where("id IN ?", changes.SourceClassIDs).
+			Updates(map[string]interface{}{"is_archived": true, "archived_at": rollover.CreatedAt}).Error; err != nil {
+			return err
+		}
+
+		return r.startAcademicYear(tx, rollover.SchoolID, rollover.ToYear)
+	})
+}
+
+// place moves students into their ToYear classes and records the move on their enrollment timeline
+func (r *rolloverRepository) place(ctx context.Context, tx *gorm.DB, placements map[*models.Class][]uint, reason models.EnrollmentReason, rollover *models.AcademicYearRollover) error {
+	for class, studentIDs := range placements {
+		if err := tx.Model(&models.Student{}).
+			Where("id IN ?", studentIDs).
+			Updates(map[string]interface{}{"class_id": class.ID, "is_active": true}).Error; err != nil {
+			return err
+		}
+		if err := enrollment.Sync(ctx, tx, enrollment.Change{
+			StudentIDs: studentIDs,
+			Date:       rollover.CreatedAt,
+			Reason:     reason,
+			EndReason:  reason,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startAcademicYear moves the school settings to the first semester of the new academic year
+func (r *rolloverRepository) startAcademicYear(tx *gorm.DB, schoolID uint, year string) error {
+	var settings models.SchoolSettings
+	err := tx.Where("school_id = ?", schoolID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		settings = *models.DefaultSchoolSettings(schoolID)
+	} else if err != nil {
+		return err
+	}
+
+	settings.AcademicYear = year
+	settings.Semester = 1
+	return tx.Omit("School").Save(&settings).Error
+}
@@ -0,0 +1,400 @@
+package school
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
+)
+
+var (
+	ErrRolloverYearInvalid     = errors.New("tahun ajaran harus berformat YYYY/YYYY")
+	ErrRolloverYearOrder       = errors.New("tahun ajaran baru harus setelah tahun ajaran lama")
+	ErrRolloverAlreadyDone     = errors.New("sekolah sudah di-rollover ke tahun ajaran ini")
+	ErrRolloverNoClasses       = errors.New("tidak ada kelas aktif pada tahun ajaran lama")
+	ErrRolloverActionInvalid   = errors.New("aksi siswa harus promote, repeat atau graduate")
+	ErrRolloverStudentNotFound = errors.New("siswa pengecualian tidak terdaftar di kelas tahun ajaran lama")
+)
+
+// RolloverService defines the business logic for the academic year rollover
+type RolloverService interface {
+	PreviewRollover(ctx context.Context, schoolID uint, req RolloverRequest) (*RolloverPlanResponse, error)
+	ExecuteRollover(ctx context.Context, schoolID, userID uint, req RolloverRequest) (*RolloverPlanResponse, error)
+	GetRollovers(ctx context.Context, schoolID uint) ([]RolloverHistoryResponse, error)
+}
+
+// rolloverService implements the RolloverService interface
+type rolloverService struct {
+	repo RolloverRepository
+	db   *gorm.DB
+}
+
+// NewRolloverService creates a new academic year rollover service
+func NewRolloverService(repo RolloverRepository, db *gorm.DB) RolloverService {
+	return &rolloverService{repo: repo, db: db}
+}
+
+// PreviewRollover computes what a rollover would do without writing anything
+func (s *rolloverService) PreviewRollover(ctx context.Context, schoolID uint, req RolloverRequest) (*RolloverPlanResponse, error) {
+	plan, _, err := s.buildPlan(ctx, schoolID, req)
+	return plan, err
+}
+
+// ExecuteRollover clones the FromYear classes into ToYear, moves the students and archives the FromYear classes
+// Past attendance, grades, BK records and homeroom notes keep the class they were recorded in.
+func (s *rolloverService) ExecuteRollover(ctx context.Context, schoolID, userID uint, req RolloverRequest) (*RolloverPlanResponse, error) {
+	plan, changes, err := s.buildPlan(ctx, schoolID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.Rollover = &models.AcademicYearRollover{
+		SchoolID:        schoolID,
+		FromYear:        plan.FromYear,
+		ToYear:          plan.ToYear,
+		ClassesCreated:  plan.Summary.ClassesCreated,
+		ClassesArchived: plan.Summary.ClassesArchived,
+		PromotedCount:   plan.Summary.Promoted,
+		RepeatedCount:   plan.Summary.Repeated,
+		GraduatedCount:  plan.Summary.Graduated,
+		UnplacedCount:   plan.Summary.Unplaced,
+		PerformedBy:     userID,
+		CreatedAt:       time.Now(),
+	}
+	if err := s.repo.Apply(ctx, changes); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "idx_rollovers_school_year") {
+			return nil, ErrRolloverAlreadyDone
+		}
+		return nil, err
+	}
+
+	// The clones have IDs now
+	placedIn := make(map[uint]uint)
+	for _, placements := range []map[*models.Class][]uint{changes.Promotions, changes.Repeats} {
+		for class, studentIDs := range placements {
+			for _, studentID := range studentIDs {
+				placedIn[studentID] = class.ID
+			}
+		}
+	}
+	for i := range plan.Students {
+		if classID, ok := placedIn[plan.Students[i].StudentID]; ok {
+			plan.Students[i].ToClassID = &classID
+		}
+	}
+
+	plan.ID = &changes.Rollover.ID
+	return plan, nil
+}
+
+// GetRollovers retrieves the past rollovers of a school
+func (s *rolloverService) GetRollovers(ctx context.Context, schoolID uint) ([]RolloverHistoryResponse, error) {
+	rollovers, err := s.repo.FindRollovers(ctx, schoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]RolloverHistoryResponse, len(rollovers))
+	for i, rollover := range rollovers {
+		performerName := rollover.Performer.Name
+		if performerName == "" {
+			performerName = rollover.Performer.Username
+		}
+		responses[i] = RolloverHistoryResponse{
+			ID:       rollover.ID,
+			FromYear: rollover.FromYear,
+			ToYear:   rollover.ToYear,
+			Summary: RolloverSummary{
+				ClassesCreated:  rollover.ClassesCreated,
+				ClassesArchived: rollover.ClassesArchived,
+				Promoted:        rollover.PromotedCount,
+				Repeated:        rollover.RepeatedCount,
+				Graduated:       rollover.GraduatedCount,
+				Unplaced:        rollover.UnplacedCount,
+			},
+			PerformedBy:   rollover.PerformedBy,
+			PerformerName: performerName,
+			CreatedAt:     rollover.CreatedAt,
+		}
+	}
+	return responses, nil
+}
+
+// buildPlan works out the classes to clone and where every FromYear student goes
+// Classes are cloned with the same name and grade. Students move to the ToYear class of the
+// next grade whose name has the grade number bumped (7A => 8A, VII-B => VIII-B), or to the only
+// class of that grade. Students of the final grade graduate and repeaters stay in their class's clone.
+func (s *rolloverService) buildPlan(ctx context.Context, schoolID uint, req RolloverRequest) (*RolloverPlanResponse, *RolloverChanges, error) {
+	fromYear := strings.TrimSpace(req.FromYear)
+	if fromYear == "" {
+		current, err := gradebook.CurrentPeriod(ctx, s.db, schoolID)
+		if err != nil {
+			return nil, nil, err
+		}
+		fromYear = current.AcademicYear
+	}
+	fromStart, err := academicYearStart(fromYear)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toYear := strings.TrimSpace(req.ToYear)
+	if toYear == "" {
+		toYear = fmt.Sprintf("%d/%d", fromStart+1, fromStart+2)
+	}
+	toStart, err := academicYearStart(toYear)
+	if err != nil {
+		return nil, nil, err
+	}
+	if toStart <= fromStart {
+		return nil, nil, ErrRolloverYearOrder
+	}
+
+	done, err := s.repo.RolloverExists(ctx, schoolID, toYear)
+	if err != nil {
+		return nil, nil, err
+	}
+	if done {
+		return nil, nil, ErrRolloverAlreadyDone
+	}
+
+	sources, err := s.repo.FindClassesByYear(ctx, schoolID, fromYear)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sources) == 0 {
+		return nil, nil, ErrRolloverNoClasses
+	}
+	existing, err := s.repo.FindClassesByYear(ctx, schoolID, toYear)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sourceIDs := make([]uint, len(sources))
+	finalGrade := req.FinalGrade
+	for i, class := range sources {
+		sourceIDs[i] = class.ID
+		if req.FinalGrade <= 0 && class.Grade > finalGrade {
+			finalGrade = class.Grade
+		}
+	}
+
+	students, err := s.repo.FindStudentsInClasses(ctx, sourceIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counselorIDs := map[uint][]uint{}
+	if req.KeepStaff {
+		if counselorIDs, err = s.repo.FindCounselorIDs(ctx, sourceIDs); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	exceptions := make(map[uint]models.RolloverAction, len(req.Exceptions))
+	for _, exception := range req.Exceptions {
+		action := models.RolloverAction(strings.TrimSpace(exception.Action))
+		if !action.IsValid() {
+			return nil, nil, ErrRolloverActionInvalid
+		}
+		exceptions[exception.StudentID] = action
+	}
+
+	plan := &RolloverPlanResponse{
+		FromYear:   fromYear,
+		ToYear:     toYear,
+		FinalGrade: finalGrade,
+		Classes:    make([]RolloverClassPlan, 0, len(sources)),
+		Students:   make([]RolloverStudentPlan, 0, len(students)),
+	}
+	changes := &RolloverChanges{
+		SourceClassIDs: sourceIDs,
+		Promotions:     make(map[*models.Class][]uint),
+		Repeats:        make(map[*models.Class][]uint),
+	}
+
+	// Clone every FromYear class unless the admin already created it in ToYear
+	targets := make(map[string]*models.Class, len(sources))
+	for i := range existing {
+		targets[rolloverClassKey(existing[i].Name, existing[i].Grade)] = &existing[i]
+	}
+	reused := make(map[uint]bool, len(sources))
+	for _, class := range sources {
+		key := rolloverClassKey(class.Name, class.Grade)
+		if _, ok := targets[key]; ok {
+			reused[class.ID] = true
+			continue
+		}
+		clone := &models.Class{
+			SchoolID: schoolID,
+			Name:     class.Name,
+			Grade:    class.Grade,
+			Year:     toYear,
+		}
+		if req.KeepStaff {
+			clone.HomeroomTeacherID = class.HomeroomTeacherID
+		}
+		targets[key] = clone
+		changes.Clones = append(changes.Clones, RolloverClone{Class: clone, CounselorIDs: counselorIDs[class.ID]})
+	}
+
+	promotions := make(map[uint]*models.Class, len(sources))
+	classPlans := make(map[uint]*RolloverClassPlan, len(sources))
+	for _, class := range sources {
+		classPlan := RolloverClassPlan{
+			ClassID:     class.ID,
+			Name:        class.Name,
+			Grade:       class.Grade,
+			CloneExists: reused[class.ID],
+		}
+		if class.Grade < finalGrade {
+			if target := promotionTarget(class, targets); target != nil {
+				promotions[class.ID] = target
+				classPlan.PromotedTo = target.Name
+			}
+		}
+		plan.Classes = append(plan.Classes, classPlan)
+	}
+	for i := range plan.Classes {
+		classPlans[plan.Classes[i].ClassID] = &plan.Classes[i]
+	}
+
+	sourceByID := make(map[uint]models.Class, len(sources))
+	for _, class := range sources {
+		sourceByID[class.ID] = class
+	}
+
+	seen := make(map[uint]bool, len(students))
+	for _, student := range students {
+		class := sourceByID[*student.ClassID]
+		classPlans[class.ID].StudentCount++
+		seen[student.ID] = true
+
+		action := models.RolloverActionPromote
+		if class.Grade >= finalGrade {
+			action = models.RolloverActionGraduate
+		}
+		exception, isException := exceptions[student.ID]
+		if isException {
+			action = exception
+		}
+
+		studentPlan := RolloverStudentPlan{
+			StudentID:     student.ID,
+			NIS:           student.NIS,
+			Name:          student.Name,
+			FromClassID:   class.ID,
+			FromClassName: class.Name,
+			Action:        string(action),
+			IsException:   isException,
+		}
+
+		var target *models.Class
+		switch action {
+		case models.RolloverActionPromote:
+			target = promotions[class.ID]
+			if target == nil && isException {
+				// Graduating class by default, promoted on request
+				target = promotionTarget(class, targets)
+			}
+			if target == nil {
+				changes.Unplaced = append(changes.Unplaced, student.ID)
+				plan.Summary.Unplaced++
+			} else {
+				changes.Promotions[target] = append(changes.Promotions[target], student.ID)
+				plan.Summary.Promoted++
+			}
+		case models.RolloverActionRepeat:
+			target = targets[rolloverClassKey(class.Name, class.Grade)]
+			changes.Repeats[target] = append(changes.Repeats[target], student.ID)
+			plan.Summary.Repeated++
+		case models.RolloverActionGraduate:
+			changes.Graduates = append(changes.Graduates, student.ID)
+			plan.Summary.Graduated++
+		}
+		if target != nil {
+			studentPlan.ToClassName = target.Name
+			if target.ID != 0 {
+				id := target.ID
+				studentPlan.ToClassID = &id
+			}
+		}
+
+		plan.Students = append(plan.Students, studentPlan)
+	}
+
+	for studentID := range exceptions {
+		if !seen[studentID] {
+			return nil, nil, ErrRolloverStudentNotFound
+		}
+	}
+
+	plan.Summary.ClassesCreated = len(changes.Clones)
+	plan.Summary.ClassesArchived = len(sources)
+	return plan, changes, nil
+}
+
+// promotionTarget finds the ToYear class of the next grade for the students of a class
+func promotionTarget(class models.Class, targets map[string]*models.Class) *models.Class {
+	nextGrade := class.Grade + 1
+	if name, ok := promotedClassName(class.Name, class.Grade); ok {
+		if target, ok := targets[rolloverClassKey(name, nextGrade)]; ok {
+			return target
+		}
+	}
+
+	var only *models.Class
+	for _, target := range targets {
+		if target.Grade != nextGrade {
+			continue
+		}
+		if only != nil {
+			return nil
+		}
+		only = target
+	}
+	return only
+}
+
+// romanGrades are the roman numerals used in class names, e.g. "VII-A"
+var romanGrades = []string{"", "I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+
+// promotedClassName bumps the grade number in a class name, e.g. "7A" => "8A" or "X IPA 1" => "XI IPA 1"
+func promotedClassName(name string, grade int) (string, bool) {
+	patterns := []struct{ from, to, other string }{
+		{strconv.Itoa(grade), strconv.Itoa(grade + 1), "0-9"},
+	}
+	if grade+1 < len(romanGrades) {
+		patterns = append(patterns, struct{ from, to, other string }{romanGrades[grade], romanGrades[grade+1], "A-Za-z"})
+	}
+
+	for _, p := range patterns {
+		re := regexp.MustCompile(`(^|[^` + p.other + `])` + p.from + `([^` + p.other + `]|$)`)
+		if re.MatchString(name) {
+			return re.ReplaceAllString(name, "${1}"+p.to+"${2}"), true
+		}
+	}
+	return "", false
+}
+
+// rolloverClassKey identifies a class within an academic year
+func rolloverClassKey(name string, grade int) string {
+	return strconv.Itoa(grade) + "|" + strings.ToLower(strings.TrimSpace(name))
+}
+
+// academicYearStart returns the first calendar year of an academic year such as "2024/2025"
+func academicYearStart(year string) (int, error) {
+	start, _, err := gradebook.Period{AcademicYear: year, Semester: 1}.DateRange(time.UTC)
+	if err != nil {
+		return 0, ErrRolloverYearInvalid
+	}
+	return start.Year(), nil
+}
@@ -3,7 +3,9 @@ package school
 import (
 	"context"
 	"errors"
+	"log"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
@@ -24,6 +26,10 @@ var (
 	ErrPhoneRequired       = errors.New("nomor HP wajib diisi")
 	ErrStudentHasAccount   = errors.New("siswa sudah memiliki akun")
 	ErrStudentNoAccount    = errors.New("siswa belum memiliki akun")
+	ErrClassArchived       = errors.New("kelas sudah diarsipkan dan hanya dapat dibaca")
+	ErrInvalidReason       = errors.New("kode alasan perpindahan tidak valid")
+	ErrInvalidDate         = errors.New("format tanggal harus YYYY-MM-DD")
+	ErrStudentNotEnrolled  = errors.New("siswa tidak sedang terdaftar di kelas")
 )
 
 // Default password for parent and student accounts
@@ -48,6 +54,8 @@ type Service interface {
 	UpdateStudent(ctx context.Context, schoolID uint, id uint, req UpdateStudentRequest) (*StudentResponse, error)
 	DeleteStudent(ctx context.Context, schoolID uint, id uint) error
 	ClearStudentRFID(ctx context.Context, schoolID uint, studentID uint) error
+	WithdrawStudent(ctx context.Context, schoolID uint, studentID uint, req WithdrawStudentRequest) (*StudentResponse, error)
+	GetStudentEnrollments(ctx context.Context, schoolID uint, studentID uint) ([]EnrollmentResponse, error)
 
 	// Parent operations
 	CreateParent(ctx context.Context, schoolID uint, req CreateParentRequest) (*ParentResponse, error)
@@ -72,6 +80,9 @@ type Service interface {
 	UpdateUser(ctx context.Context, schoolID uint, id uint, req UpdateUserRequest) (*UserResponse, error)
 	DeleteUser(ctx context.Context, schoolID uint, id uint) error
 	ResetUserPassword(ctx context.Context, schoolID uint, id uint) (*ResetPasswordResponse, error)
+	RevokeUserSessions(ctx context.Context, schoolID uint, id uint) (int64, error)
+	UnlockUser(ctx context.Context, schoolID uint, id uint, unlockedBy uint) (bool, error)
+	ResetUserTwoFactor(ctx context.Context, schoolID uint, id uint, resetBy uint) (bool, error)
 
 	// Device operations
 	GetSchoolDevices(ctx context.Context, schoolID uint) ([]DeviceResponse, error)
@@ -88,6 +99,7 @@ type Service interface {
 type service struct {
 	repo     Repository
 	userRepo UserRepository
+	locker   LoginLocker
 }
 
 // UserRepository defines the interface for user operations needed by school service
@@ -96,11 +108,18 @@ type UserRepository interface {
 	FindByUsername(ctx context.Context, username string) (*models.User, error)
 }
 
+// LoginLocker defines the login lockout operations needed by school service
+type LoginLocker interface {
+	LockedUntil(ctx context.Context, username string) (*time.Time, error)
+	Unlock(ctx context.Context, username string) (bool, error)
+}
+
 // NewService creates a new school service
-func NewService(repo Repository, userRepo UserRepository) Service {
+func NewService(repo Repository, userRepo UserRepository, locker LoginLocker) Service {
 	return &service{
 		repo:     repo,
 		userRepo: userRepo,
+		locker:   locker,
 	}
 }
 
@@ -215,6 +234,9 @@ func (s *service) UpdateClass(ctx context.Context, schoolID uint, id uint, req U
 	if err != nil {
 		return nil, err
 	}
+	if class.IsArchived {
+		return nil, ErrClassArchived
+	}
 
 	// Update fields if provided
 	if req.Name != nil {
@@ -274,6 +296,15 @@ func (s *service) UpdateClass(ctx context.Context, schoolID uint, id uint, req U
 
 // DeleteClass deletes a class
 func (s *service) DeleteClass(ctx context.Context, schoolID uint, id uint) error {
+	// Archived classes keep the history of past academic years
+	class, err := s.repo.FindClassByID(ctx, schoolID, id)
+	if err != nil {
+		return err
+	}
+	if class.IsArchived {
+		return ErrClassArchived
+	}
+
 	// Check if class has students
 	count, err := s.repo.GetClassStudentCount(ctx, id)
 	if err != nil {
@@ -299,6 +330,9 @@ func (s *service) AssignHomeroomTeacher(ctx context.Context, schoolID uint, clas
 	if err != nil {
 		return nil, err
 	}
+	if class.IsArchived {
+		return nil, ErrClassArchived
+	}
 
 	// Assign teacher
 	class.HomeroomTeacherID = &teacherID
@@ -325,6 +359,8 @@ func (s *service) toClassResponse(ctx context.Context, class *models.Class) *Cla
 		Grade:             class.Grade,
 		Year:              class.Year,
 		HomeroomTeacherID: class.HomeroomTeacherID,
+		IsArchived:        class.IsArchived,
+		ArchivedAt:        class.ArchivedAt,
 		CreatedAt:         class.CreatedAt,
 		UpdatedAt:         class.UpdatedAt,
 	}
@@ -371,10 +407,13 @@ func (s *service) CreateStudent(ctx context.Context, schoolID uint, req CreateSt
 	}
 
 	// Validate class exists
-	_, err := s.repo.FindClassByID(ctx, schoolID, req.ClassID)
+	class, err := s.repo.FindClassByID(ctx, schoolID, req.ClassID)
 	if err != nil {
 		return nil, err
 	}
+	if class.IsArchived {
+		return nil, ErrClassArchived
+	}
 
 	// Check for duplicate NISN (globally unique)
 	existing, err := s.repo.FindStudentByNISN(ctx, nisn)
@@ -580,10 +619,13 @@ func (s *service) UpdateStudent(ctx context.Context, schoolID uint, id uint, req
 		}
 		classID := uint(*req.ClassID)
 		// Validate class exists
-		_, err := s.repo.FindClassByID(ctx, schoolID, classID)
+		class, err := s.repo.FindClassByID(ctx, schoolID, classID)
 		if err != nil {
 			return nil, err
 		}
+		if class.IsArchived {
+			return nil, ErrClassArchived
+		}
 		student.ClassID = &classID
 		// When ClassID is set, student can be active (Requirements: 8.3)
 		student.IsActive = true
@@ -631,6 +673,7 @@ func (s *service) toStudentResponse(student *models.Student) *StudentResponse {
 		RFIDCode:   student.RFIDCode,
 		IsActive:   student.IsActive,
 		HasAccount: student.UserID != nil,
+		GraduatedAt: student.GraduatedAt,
 		CreatedAt:  student.CreatedAt,
 		UpdatedAt:  student.UpdatedAt,
 	}
@@ -1121,6 +1164,11 @@ func (s *service) GetUserByID(ctx context.Context, schoolID uint, id uint) (*Use
 	}
 	
 	response := s.toUserResponse(user)
+
+	// Show whether the account is locked after failed logins
+	if lockedUntil, err := s.locker.LockedUntil(ctx, user.Username); err == nil {
+		response.LockedUntil = lockedUntil
+	}
 	
 	// Get assigned class for wali_kelas
 	if user.Role == models.RoleWaliKelas {
@@ -1194,7 +1242,7 @@ func (s *service) CreateUser(ctx context.Context, schoolID uint, req CreateUserR
 	// If wali_kelas and assigned_class_id is provided, update the class
 	if req.Role == "wali_kelas" && req.AssignedClassID != nil {
 		class, err := s.repo.FindClassByID(ctx, schoolID, *req.AssignedClassID)
-		if err == nil && class != nil {
+		if err == nil && class != nil && !class.IsArchived {
 			class.HomeroomTeacherID = &user.ID
 			if err := s.repo.UpdateClass(ctx, class); err != nil {
 				// Log error but don't fail the user creation
@@ -1279,7 +1327,7 @@ func (s *service) UpdateUser(ctx context.Context, schoolID uint, id uint, req Up
 		// Then assign to the new class
 		if *req.AssignedClassID > 0 {
 			newClass, err := s.repo.FindClassByID(ctx, schoolID, *req.AssignedClassID)
-			if err == nil && newClass != nil {
+			if err == nil && newClass != nil && !newClass.IsArchived {
 				newClass.HomeroomTeacherID = &user.ID
 				s.repo.UpdateClass(ctx, newClass)
 			}
@@ -1373,11 +1421,8 @@ func (s *service) ResetUserPassword(ctx context.Context, schoolID uint, id uint)
 		return nil, err
 	}
 
-	// Update password
-	user.PasswordHash = string(passwordHash)
-	user.MustResetPwd = true
-
-	if err := s.repo.UpdateUser(ctx, user); err != nil {
+	// Update password and end the user's sessions
+	if err := s.repo.ResetUserPassword(ctx, user.ID, string(passwordHash)); err != nil {
 		return nil, err
 	}
 
@@ -1388,6 +1433,55 @@ func (s *service) ResetUserPassword(ctx context.Context, schoolID uint, id uint)
 	}, nil
 }
 
+// RevokeUserSessions signs a user out of all devices
+func (s *service) RevokeUserSessions(ctx context.Context, schoolID uint, id uint) (int64, error) {
+	// Ensure the user belongs to the school
+	user, err := s.repo.FindUserByID(ctx, schoolID, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.repo.RevokeUserSessions(ctx, user.ID)
+}
+
+// UnlockUser lifts the login lock of a user after too many failed logins
+func (s *service) UnlockUser(ctx context.Context, schoolID uint, id uint, unlockedBy uint) (bool, error) {
+	// Ensure the user belongs to the school
+	user, err := s.repo.FindUserByID(ctx, schoolID, id)
+	if err != nil {
+		return false, err
+	}
+
+	unlocked, err := s.locker.Unlock(ctx, user.Username)
+	if err != nil {
+		return false, err
+	}
+
+	log.Printf("Login lock of user %d (%s) in school %d lifted by user %d (was locked: %t)", user.ID, user.Username, schoolID, unlockedBy, unlocked)
+	if err := s.repo.RecordUserUnlock(ctx, user, unlocked); err != nil {
+		log.Printf("Failed to record unlock of user %d in the audit trail: %v", user.ID, err)
+	}
+	return unlocked, nil
+}
+
+// ResetUserTwoFactor turns 2FA off for a user who lost the authenticator app and the recovery codes
+// If the school requires 2FA for the user's role, the next login asks to set it up again.
+func (s *service) ResetUserTwoFactor(ctx context.Context, schoolID uint, id uint, resetBy uint) (bool, error) {
+	// Ensure the user belongs to the school
+	user, err := s.repo.FindUserByID(ctx, schoolID, id)
+	if err != nil {
+		return false, err
+	}
+
+	removed, err := s.repo.ResetUserTwoFactor(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+
+	log.Printf("Two-factor of user %d (%s) in school %d reset by user %d (was set up: %t)", user.ID, user.Username, schoolID, resetBy, removed)
+	return removed, nil
+}
+
 // toUserResponse converts a User model to UserResponse DTO
 func (s *service) toUserResponse(user *models.User) *UserResponse {
 	response := &UserResponse{
@@ -1448,6 +1542,75 @@ func (s *service) ClearStudentRFID(ctx context.Context, schoolID uint, studentID
 	return s.repo.ClearStudentRFID(ctx, studentID)
 }
 
+// WithdrawStudent takes a student out of their class because they transferred out, dropped out or graduated
+// The enrollment is closed on the date so the class keeps the student's past records.
+func (s *service) WithdrawStudent(ctx context.Context, schoolID uint, studentID uint, req WithdrawStudentRequest) (*StudentResponse, error) {
+	reason := models.EnrollmentReason(strings.TrimSpace(req.Reason))
+	switch reason {
+	case models.EnrollmentReasonTransferOut, models.EnrollmentReasonDroppedOut, models.EnrollmentReasonGraduated:
+	default:
+		return nil, ErrInvalidReason
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+		if err != nil {
+			return nil, ErrInvalidDate
+		}
+		date = parsed
+	}
+
+	student, err := s.repo.FindStudentByID(ctx, schoolID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student.ClassID == nil {
+		return nil, ErrStudentNotEnrolled
+	}
+
+	if err := s.repo.WithdrawStudent(ctx, student, reason, date); err != nil {
+		return nil, err
+	}
+
+	student, err = s.repo.FindStudentByID(ctx, schoolID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toStudentResponse(student), nil
+}
+
+// GetStudentEnrollments retrieves the classes a student has been in, oldest first
+func (s *service) GetStudentEnrollments(ctx context.Context, schoolID uint, studentID uint) ([]EnrollmentResponse, error) {
+	if _, err := s.repo.FindStudentByID(ctx, schoolID, studentID); err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.repo.FindStudentEnrollments(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]EnrollmentResponse, len(enrollments))
+	for i, e := range enrollments {
+		responses[i] = EnrollmentResponse{
+			ID:           e.ID,
+			ClassID:      e.ClassID,
+			ClassName:    e.Class.Name,
+			Grade:        e.Class.Grade,
+			AcademicYear: e.AcademicYear,
+			StartDate:    e.StartDate.Format("2006-01-02"),
+			Reason:       string(e.Reason),
+			EndReason:    string(e.EndReason),
+		}
+		if e.EndDate != nil {
+			endDate := e.EndDate.Format("2006-01-02")
+			responses[i].EndDate = &endDate
+		}
+	}
+	return responses, nil
+}
+
 
 // ==================== Bulk Operations Service Methods ====================
 
@@ -1474,6 +1637,10 @@ func (s *service) BulkAssignClass(ctx context.Context, schoolID uint, req BulkAs
 	if len(req.StudentIDs) == 0 {
 		return nil, ErrStudentIDsRequired
 	}
+	reason := models.EnrollmentReason(strings.TrimSpace(req.Reason))
+	if reason != "" && !reason.IsStartReason() {
+		return nil, ErrInvalidReason
+	}
 
 	// Validate class exists and belongs to the same school
 	class, err := s.repo.FindClassByID(ctx, schoolID, req.ClassID)
@@ -1483,6 +1650,9 @@ func (s *service) BulkAssignClass(ctx context.Context, schoolID uint, req BulkAs
 	if class.SchoolID != schoolID {
 		return nil, ErrClassNotFound
 	}
+	if class.IsArchived {
+		return nil, ErrClassArchived
+	}
 
 	// Validate all students exist and belong to the same school
 	for _, studentID := range req.StudentIDs {
@@ -1493,7 +1663,7 @@ func (s *service) BulkAssignClass(ctx context.Context, schoolID uint, req BulkAs
 	}
 
 	// Perform bulk update
-	if err := s.repo.BulkUpdateStudentClass(ctx, req.StudentIDs, req.ClassID); err != nil {
+	if err := s.repo.BulkUpdateStudentClass(ctx, req.StudentIDs, req.ClassID, reason); err != nil {
 		return nil, err
 	}
 
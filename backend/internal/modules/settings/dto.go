@@ -23,6 +23,9 @@ type UpdateSettingsRequest struct {
 	// General Settings
 	AcademicYear *string `json:"academic_year"`
 	Semester     *int    `json:"semester"`
+
+	// Security Settings
+	TwoFactorRequiredRoles *[]string `json:"two_factor_required_roles"` // Roles that must sign in with an authenticator app
 }
 
 // UpdateAttendanceSettingsRequest represents the request to update attendance settings only
@@ -47,6 +50,11 @@ type UpdateAcademicSettingsRequest struct {
 	Semester     *int    `json:"semester"`
 }
 
+// UpdateSecuritySettingsRequest represents the request to update security settings only
+type UpdateSecuritySettingsRequest struct {
+	TwoFactorRequiredRoles *[]string `json:"two_factor_required_roles"` // admin_sekolah and/or guru_bk; empty makes 2FA optional
+}
+
 // SettingsResponse represents school settings in responses
 type SettingsResponse struct {
 	ID       uint `json:"id"`
@@ -68,6 +76,9 @@ type SettingsResponse struct {
 	AcademicYear string `json:"academic_year"`
 	Semester     int    `json:"semester"`
 
+	// Security Settings
+	TwoFactorRequiredRoles []string `json:"two_factor_required_roles"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
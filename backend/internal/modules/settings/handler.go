@@ -64,6 +64,7 @@ func (h *Handler) RegisterRoutes(router fiber.Router) {
 	settings.Put("/attendance", h.UpdateAttendanceSettings)
 	settings.Put("/notifications", h.UpdateNotificationSettings)
 	settings.Put("/academic", h.UpdateAcademicSettings)
+	settings.Put("/security", h.UpdateSecuritySettings)
 
 	// Utility endpoints
 	settings.Get("/attendance-window", h.GetAttendanceTimeWindow)
@@ -301,6 +302,47 @@ func (h *Handler) UpdateAcademicSettings(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateSecuritySettings handles updating security settings only
+// @Summary Update security settings
+// @Description Choose the roles that have to sign in with an authenticator app (2FA)
+// @Tags Settings
+// @Accept json
+// @Produce json
+// @Param request body UpdateSecuritySettingsRequest true "Security settings data"
+// @Success 200 {object} SettingsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/settings/security [put]
+func (h *Handler) UpdateSecuritySettings(c *fiber.Ctx) error {
+	// Check role first
+	if err := h.checkAdminRole(c); err != nil {
+		return err
+	}
+
+	schoolID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return h.tenantRequiredError(c)
+	}
+
+	var req UpdateSecuritySettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.invalidBodyError(c)
+	}
+
+	response, err := h.service.UpdateSecuritySettings(c.Context(), schoolID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+		"message": "Pengaturan keamanan berhasil diperbarui",
+	})
+}
+
 // GetAttendanceTimeWindow handles getting the attendance time window
 // @Summary Get attendance time window
 // @Description Get the calculated attendance time window for a specific date
@@ -434,6 +476,14 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 				"message": "Semester harus 1 atau 2",
 			},
 		})
+	case errors.Is(err, ErrInvalidTwoFactorRole):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "VAL_INVALID_VALUE",
+				"message": "2FA hanya dapat diwajibkan untuk admin_sekolah dan guru_bk",
+			},
+		})
 	default:
 		// Return the actual error message for better debugging
 		errMsg := err.Error()
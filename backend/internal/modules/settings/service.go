@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/school-management/backend/internal/domain/models"
@@ -16,6 +17,7 @@ var (
 	ErrInvalidVeryLateThreshold    = errors.New("batas sangat terlambat harus lebih besar atau sama dengan batas terlambat")
 	ErrInvalidSemester             = errors.New("semester harus 1 atau 2")
 	ErrSchoolIDRequired            = errors.New("ID sekolah wajib diisi")
+	ErrInvalidTwoFactorRole        = errors.New("2FA hanya dapat diwajibkan untuk admin_sekolah dan guru_bk")
 )
 
 // Service defines the interface for SchoolSettings business logic
@@ -29,6 +31,7 @@ type Service interface {
 	UpdateAttendanceSettings(ctx context.Context, schoolID uint, req UpdateAttendanceSettingsRequest) (*SettingsResponse, error)
 	UpdateNotificationSettings(ctx context.Context, schoolID uint, req UpdateNotificationSettingsRequest) (*SettingsResponse, error)
 	UpdateAcademicSettings(ctx context.Context, schoolID uint, req UpdateAcademicSettingsRequest) (*SettingsResponse, error)
+	UpdateSecuritySettings(ctx context.Context, schoolID uint, req UpdateSecuritySettingsRequest) (*SettingsResponse, error)
 
 	// Utility operations
 	GetAttendanceTimeWindow(ctx context.Context, schoolID uint, date time.Time) (*AttendanceTimeWindowResponse, error)
@@ -119,6 +122,18 @@ func (s *service) UpdateSchoolSettings(ctx context.Context, schoolID uint, req U
 		}
 		settings.Semester = *req.Semester
 	}
+	if req.TwoFactorRequiredRoles != nil {
+		roles := make([]string, 0, len(*req.TwoFactorRequiredRoles))
+		for _, role := range *req.TwoFactorRequiredRoles {
+			userRole := models.UserRole(strings.TrimSpace(role))
+			// Super admins have no school; their requirement is set platform-wide
+			if !userRole.SupportsTwoFactor() || userRole == models.RoleSuperAdmin {
+				return nil, ErrInvalidTwoFactorRole
+			}
+			roles = append(roles, string(userRole))
+		}
+		settings.TwoFactorRequiredRoles = strings.Join(roles, ",")
+	}
 
 	// Validate the complete settings
 	if err := settings.Validate(); err != nil {
@@ -157,6 +172,8 @@ func (s *service) ResetToDefaults(ctx context.Context, schoolID uint) (*Settings
 		// Update existing settings with defaults
 		defaultSettings.ID = settings.ID
 		defaultSettings.CreatedAt = settings.CreatedAt
+		// A reset of the school's preferences must not silently lift the 2FA requirement
+		defaultSettings.TwoFactorRequiredRoles = settings.TwoFactorRequiredRoles
 		if err := s.repo.Update(ctx, defaultSettings); err != nil {
 			return nil, err
 		}
@@ -198,6 +215,13 @@ func (s *service) UpdateAcademicSettings(ctx context.Context, schoolID uint, req
 	})
 }
 
+// UpdateSecuritySettings updates only security-related settings
+func (s *service) UpdateSecuritySettings(ctx context.Context, schoolID uint, req UpdateSecuritySettingsRequest) (*SettingsResponse, error) {
+	return s.UpdateSchoolSettings(ctx, schoolID, UpdateSettingsRequest{
+		TwoFactorRequiredRoles: req.TwoFactorRequiredRoles,
+	})
+}
+
 
 // GetAttendanceTimeWindow calculates the attendance time window for a specific date
 // Property 17: School Settings Policy Enforcement - Attendance status SHALL be determined based on school's configured time thresholds
@@ -318,7 +342,17 @@ func toSettingsResponse(s *models.SchoolSettings) *SettingsResponse {
 		EnableHomeroomNotification:   s.EnableHomeroomNotification,
 		AcademicYear:                 s.AcademicYear,
 		Semester:                     s.Semester,
+		TwoFactorRequiredRoles:       twoFactorRoleNames(s),
 		CreatedAt:                    s.CreatedAt,
 		UpdatedAt:                    s.UpdatedAt,
 	}
 }
+
+// twoFactorRoleNames lists the roles that must use 2FA, never nil so the response holds an array
+func twoFactorRoleNames(s *models.SchoolSettings) []string {
+	roles := []string{}
+	for _, role := range s.TwoFactorRoles() {
+		roles = append(roles, string(role))
+	}
+	return roles
+}
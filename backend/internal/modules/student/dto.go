@@ -1,6 +1,10 @@
 package student
 
-import "time"
+import (
+	"time"
+
+	"github.com/school-management/backend/internal/shared/gradebook"
+)
 
 // ==================== Profile DTOs ====================
 
@@ -54,12 +58,16 @@ type AttendanceSummaryResponse struct {
 
 // GradeResponse represents a grade record
 type GradeResponse struct {
-	ID          uint      `json:"id"`
-	Title       string    `json:"title"`
-	Score       float64   `json:"score"`
-	Description string    `json:"description"`
-	TeacherName string    `json:"teacher_name"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID                     uint      `json:"id"`
+	SubjectName            string    `json:"subject_name,omitempty"`
+	AssessmentCategoryName string    `json:"assessment_category_name,omitempty"`
+	AcademicYear           string    `json:"academic_year,omitempty"`
+	Semester               int       `json:"semester,omitempty"`
+	Title                  string    `json:"title"`
+	Score                  float64   `json:"score"`
+	Description            string    `json:"description"`
+	TeacherName            string    `json:"teacher_name"`
+	CreatedAt              time.Time `json:"created_at"`
 }
 
 // GradeListResponse represents paginated grade list
@@ -69,11 +77,13 @@ type GradeListResponse struct {
 }
 
 // GradeSummaryResponse represents grade summary
+// The embedded report card holds the weighted subject averages of one semester.
 type GradeSummaryResponse struct {
 	TotalGrades  int     `json:"total_grades"`
 	AverageScore float64 `json:"average_score"`
 	HighestScore float64 `json:"highest_score"`
 	LowestScore  float64 `json:"lowest_score"`
+	gradebook.Summary
 }
 
 // ==================== BK DTOs ====================
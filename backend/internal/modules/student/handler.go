@@ -7,6 +7,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/school-management/backend/internal/middleware"
+	"github.com/school-management/backend/internal/shared/gradebook"
 )
 
 // Handler handles HTTP requests for student API
@@ -270,9 +271,11 @@ func (h *Handler) GetGrades(c *fiber.Ctx) error {
 
 // GetGradeSummary handles getting the student's grade summary
 // @Summary Get student grade summary
-// @Description Get the authenticated student's grade summary
+// @Description Get the authenticated student's grade summary and report card for a semester
 // @Tags Student
 // @Produce json
+// @Param academic_year query string false "Academic year, defaults to the school's current one"
+// @Param semester query int false "Semester (1 or 2), defaults to the school's current one"
 // @Success 200 {object} GradeSummaryResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
@@ -290,7 +293,12 @@ func (h *Handler) GetGradeSummary(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.service.GetGradeSummary(c.Context(), userID)
+	period := gradebook.Period{
+		AcademicYear: c.Query("academic_year"),
+		Semester:     c.QueryInt("semester"),
+	}
+
+	response, err := h.service.GetGradeSummary(c.Context(), userID, period)
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
 )
 
 var (
@@ -27,7 +28,7 @@ type Repository interface {
 
 	// Grade operations
 	GetStudentGrades(ctx context.Context, studentID uint, page, pageSize int) ([]models.Grade, int64, error)
-	GetGradeSummary(ctx context.Context, studentID uint) (*GradeSummaryResponse, error)
+	GetGradeSummary(ctx context.Context, schoolID, studentID uint, period gradebook.Period) (*GradeSummaryResponse, error)
 
 	// BK operations
 	GetStudentViolations(ctx context.Context, studentID uint, limit int) ([]models.Violation, error)
@@ -166,6 +167,8 @@ func (r *repository) GetStudentGrades(ctx context.Context, studentID uint, page,
 
 	offset := (page - 1) * pageSize
 	err := query.
+		Preload("Subject").
+		Preload("AssessmentCategory").
 		Preload("Creator").
 		Order("created_at DESC").
 		Offset(offset).
@@ -180,7 +183,8 @@ func (r *repository) GetStudentGrades(ctx context.Context, studentID uint, page,
 }
 
 // GetGradeSummary retrieves grade summary for a student
-func (r *repository) GetGradeSummary(ctx context.Context, studentID uint) (*GradeSummaryResponse, error) {
+// The report card is computed for the given period, defaulting to the school's current one.
+func (r *repository) GetGradeSummary(ctx context.Context, schoolID, studentID uint, period gradebook.Period) (*GradeSummaryResponse, error) {
 	var result struct {
 		Count   int64
 		Average float64
@@ -197,11 +201,22 @@ func (r *repository) GetGradeSummary(ctx context.Context, studentID uint) (*Grad
 		return nil, err
 	}
 
+	period, err = gradebook.ResolvePeriod(ctx, r.db, schoolID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := gradebook.StudentSummary(ctx, r.db, studentID, period)
+	if err != nil {
+		return nil, err
+	}
+
 	return &GradeSummaryResponse{
 		TotalGrades:  int(result.Count),
 		AverageScore: result.Average,
 		HighestScore: result.Highest,
 		LowestScore:  result.Lowest,
+		Summary:      *report,
 	}, nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
 )
 
 // Service defines the interface for student business logic
@@ -20,7 +21,7 @@ type Service interface {
 
 	// Grade operations
 	GetGrades(ctx context.Context, userID uint, filter GradeFilter) (*GradeListResponse, error)
-	GetGradeSummary(ctx context.Context, userID uint) (*GradeSummaryResponse, error)
+	GetGradeSummary(ctx context.Context, userID uint, period gradebook.Period) (*GradeSummaryResponse, error)
 
 	// BK operations
 	GetBKInfo(ctx context.Context, userID uint) (*BKInfoResponse, error)
@@ -63,7 +64,7 @@ func (s *service) GetDashboard(ctx context.Context, userID uint) (*DashboardResp
 	}
 
 	// Get grade summary
-	gradeSummary, _ := s.repo.GetGradeSummary(ctx, student.ID)
+	gradeSummary, _ := s.repo.GetGradeSummary(ctx, student.SchoolID, student.ID, gradebook.Period{})
 	if gradeSummary == nil {
 		gradeSummary = &GradeSummaryResponse{}
 	}
@@ -125,7 +126,7 @@ func (s *service) GetSummary(ctx context.Context, userID uint) (*StudentSummaryR
 	}
 
 	// Get grade summary
-	gradeSummary, _ := s.repo.GetGradeSummary(ctx, student.ID)
+	gradeSummary, _ := s.repo.GetGradeSummary(ctx, student.SchoolID, student.ID, gradebook.Period{})
 	if gradeSummary == nil {
 		gradeSummary = &GradeSummaryResponse{}
 	}
@@ -254,13 +255,13 @@ func (s *service) GetGrades(ctx context.Context, userID uint, filter GradeFilter
 }
 
 // GetGradeSummary retrieves grade summary for the student
-func (s *service) GetGradeSummary(ctx context.Context, userID uint) (*GradeSummaryResponse, error) {
+func (s *service) GetGradeSummary(ctx context.Context, userID uint, period gradebook.Period) (*GradeSummaryResponse, error) {
 	student, err := s.repo.FindStudentByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repo.GetGradeSummary(ctx, student.ID)
+	return s.repo.GetGradeSummary(ctx, student.SchoolID, student.ID, period)
 }
 
 // GetBKInfo retrieves BK information for the student
@@ -342,14 +343,24 @@ func toGradeResponse(g *models.Grade) GradeResponse {
 		}
 	}
 
-	return GradeResponse{
-		ID:          g.ID,
-		Title:       g.Title,
-		Score:       g.Score,
-		Description: g.Description,
-		TeacherName: teacherName,
-		CreatedAt:   g.CreatedAt,
+	response := GradeResponse{
+		ID:           g.ID,
+		AcademicYear: g.AcademicYear,
+		Semester:     g.Semester,
+		Title:        g.Title,
+		Score:        g.Score,
+		Description:  g.Description,
+		TeacherName:  teacherName,
+		CreatedAt:    g.CreatedAt,
 	}
+	if g.Subject != nil {
+		response.SubjectName = g.Subject.Name
+	}
+	if g.AssessmentCategory != nil {
+		response.AssessmentCategoryName = g.AssessmentCategory.Name
+	}
+
+	return response
 }
 
 func toViolationSummaryResponse(v *models.Violation) ViolationSummaryResponse {
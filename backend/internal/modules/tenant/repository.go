@@ -7,6 +7,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/audit"
 )
 
 var (
@@ -121,71 +122,125 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 			return err
 		}
 
-		// 3. Delete homeroom notes for students in this school
-		if err := tx.Exec("DELETE FROM homeroom_notes WHERE student_id IN (SELECT id FROM students WHERE school_id = ?)", id).Error; err != nil {
+		// 3. Delete homeroom notes and report card data for students in this school
+		if err := deleteStudentRows(ctx, tx, "homeroom_notes", id); err != nil {
+			return err
+		}
+		if err := deleteStudentRows(ctx, tx, "report_card_narratives", id); err != nil {
+			return err
+		}
+		if err := tx.Where("school_id = ?", id).Delete(&models.ReportCardJob{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("school_id = ?", id).Delete(&models.ReportCardTemplate{}).Error; err != nil {
 			return err
 		}
 
-		// 4. Delete grades for students in this school
-		if err := tx.Exec("DELETE FROM grades WHERE student_id IN (SELECT id FROM students WHERE school_id = ?)", id).Error; err != nil {
+		// 4. Delete grades for students in this school, then the subjects they were graded in
+		if err := deleteStudentRows(ctx, tx, "grades", id); err != nil {
+			return err
+		}
+		if err := tx.Where("school_id = ?", id).Delete(&models.TeachingAssignment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("school_id = ?", id).Delete(&models.AssessmentCategory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("school_id = ?", id).Delete(&models.Subject{}).Error; err != nil {
 			return err
 		}
 
-		// 5. Delete BK records (violations, achievements, permits, counseling notes)
-		if err := tx.Exec("DELETE FROM violations WHERE student_id IN (SELECT id FROM students WHERE school_id = ?)", id).Error; err != nil {
+		// 5. Delete counseling cases with their sessions and follow-ups, and violation escalations
+		// Links of cases to violations and permits are removed by their cascading foreign keys
+		caseIDs := tx.Model(&models.CounselingCase{}).Select("id").Where("school_id = ?", id)
+		if err := tx.Where("case_id IN (?)", caseIDs).Delete(&models.CounselingSession{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("case_id IN (?)", caseIDs).Delete(&models.CounselingFollowUp{}).Error; err != nil {
 			return err
 		}
-		if err := tx.Exec("DELETE FROM achievements WHERE student_id IN (SELECT id FROM students WHERE school_id = ?)", id).Error; err != nil {
+		if err := tx.Where("school_id = ?", id).Delete(&models.CounselingCase{}).Error; err != nil {
 			return err
 		}
-		if err := tx.Exec("DELETE FROM permits WHERE student_id IN (SELECT id FROM students WHERE school_id = ?)", id).Error; err != nil {
+		if err := tx.Where("school_id = ?", id).Delete(&models.ViolationEscalation{}).Error; err != nil {
 			return err
 		}
-		if err := tx.Exec("DELETE FROM counseling_notes WHERE student_id IN (SELECT id FROM students WHERE school_id = ?)", id).Error; err != nil {
+		if err := tx.Where("school_id = ?", id).Delete(&models.ViolationThreshold{}).Error; err != nil {
 			return err
 		}
 
-		// 6. Delete attendance records for students in this school
-		if err := tx.Exec("DELETE FROM attendances WHERE student_id IN (SELECT id FROM students WHERE school_id = ?)", id).Error; err != nil {
+		// 6. Delete BK records (violations, achievements, permits, counseling notes)
+		if err := deleteStudentRows(ctx, tx, "violations", id); err != nil {
+			return err
+		}
+		if err := deleteStudentRows(ctx, tx, "achievements", id); err != nil {
+			return err
+		}
+		if err := deleteStudentRows(ctx, tx, "permits", id); err != nil {
+			return err
+		}
+		if err := deleteStudentRows(ctx, tx, "counseling_notes", id); err != nil {
+			return err
+		}
+		if err := tx.Where("school_id = ?", id).Delete(&models.ViolationCategory{}).Error; err != nil {
+			return err
+		}
+
+		// 7. Delete attendance records and leave requests for students in this school
+		if err := deleteStudentRows(ctx, tx, "attendances", id); err != nil {
+			return err
+		}
+		if err := tx.Where("school_id = ?", id).Delete(&models.LeaveRequest{}).Error; err != nil {
+			return err
+		}
+
+		// 8. Delete student-parent relationships and the class timeline of the students
+		if err := deleteStudentRows(ctx, tx, "student_parents", id); err != nil {
+			return err
+		}
+		if err := tx.Where("school_id = ?", id).Delete(&models.StudentEnrollment{}).Error; err != nil {
 			return err
 		}
 
-		// 7. Delete student-parent relationships
-		if err := tx.Exec("DELETE FROM student_parents WHERE student_id IN (SELECT id FROM students WHERE school_id = ?)", id).Error; err != nil {
+		// 9. Delete class counselors and academic year rollovers
+		if err := tx.Where("school_id = ?", id).Delete(&models.ClassCounselor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("school_id = ?", id).Delete(&models.AcademicYearRollover{}).Error; err != nil {
 			return err
 		}
 
-		// 8. Delete parents for this school
+		// 10. Delete parents for this school
 		if err := tx.Where("school_id = ?", id).Delete(&models.Parent{}).Error; err != nil {
 			return err
 		}
 
-		// 9. Delete students
+		// 11. Delete students
 		if err := tx.Where("school_id = ?", id).Delete(&models.Student{}).Error; err != nil {
 			return err
 		}
 
-		// 10. Delete classes
+		// 12. Delete classes
 		if err := tx.Where("school_id = ?", id).Delete(&models.Class{}).Error; err != nil {
 			return err
 		}
 
-		// 11. Delete devices
+		// 13. Delete devices
 		if err := tx.Where("school_id = ?", id).Delete(&models.Device{}).Error; err != nil {
 			return err
 		}
 
-		// 12. Delete school settings
+		// 14. Delete school settings
 		if err := tx.Where("school_id = ?", id).Delete(&models.SchoolSettings{}).Error; err != nil {
 			return err
 		}
 
-		// 13. Delete users
+		// 15. Delete users
 		if err := tx.Where("school_id = ?", id).Delete(&models.User{}).Error; err != nil {
 			return err
 		}
 
-		// 14. Finally delete the school
+		// 16. Finally delete the school
 		if err := tx.Delete(&school).Error; err != nil {
 			return err
 		}
@@ -194,6 +249,25 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 	})
 }
 
+// deleteStudentRows deletes the rows of a per-student table for the students of a school.
+// The raw DELETE is not seen by the audit plugin, so it is recorded as a bulk change.
+func deleteStudentRows(ctx context.Context, tx *gorm.DB, table string, schoolID uint) error {
+	filter := "student_id IN (SELECT id FROM students WHERE school_id = ?)"
+	result := tx.Exec("DELETE FROM "+table+" WHERE "+filter, schoolID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return audit.RecordBulk(ctx, tx, audit.BulkChange{
+		Action:       models.AuditActionDelete,
+		Table:        table,
+		SchoolID:     schoolID,
+		Filter:       filter,
+		Args:         []interface{}{schoolID},
+		RowsAffected: result.RowsAffected,
+	})
+}
+
 // FindAll retrieves all schools with pagination and filtering
 // Requirements: 1.2 - WHEN a Super_Admin views the tenant list, THE System SHALL display all registered schools
 func (r *repository) FindAll(ctx context.Context, filter SchoolFilter) ([]models.School, int64, error) {
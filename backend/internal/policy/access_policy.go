@@ -55,6 +55,7 @@ type UserContext struct {
 // Requirements: 9.3 - THE System SHALL keep internal_note private and accessible only to Guru_BK
 // Requirements: 9.4 - WHEN a Wali_Kelas views counseling data, THE System SHALL show only parent_summary
 // Requirements: 10.5 - THE System SHALL validate that Wali_Kelas can only input grades for students in their assigned class
+// Guru (subject teachers) can only input grades of the subjects and classes of their teaching assignments
 // Requirements: 11.4 - THE System SHALL validate that Wali_Kelas can only create notes for students in their assigned class
 type AccessPolicy interface {
 	// CanAccessStudent checks if user can access a specific student's data
@@ -78,6 +79,12 @@ type AccessPolicy interface {
 	// CanModifyGrade checks if user can modify a specific grade
 	CanModifyGrade(ctx context.Context, user *UserContext, studentID uint) (bool, error)
 
+	// CanModifySubjectGrade checks if user can modify a student's grades of a subject in the current semester
+	CanModifySubjectGrade(ctx context.Context, user *UserContext, studentID, subjectID uint) (bool, error)
+
+	// IsTeachingClass checks if user is assigned to teach in a class this semester (any subject when subjectID is nil)
+	IsTeachingClass(ctx context.Context, user *UserContext, classID uint, subjectID *uint) (bool, error)
+
 	// CanAccessHomeroomNote checks if user can access homeroom notes
 	CanAccessHomeroomNote(ctx context.Context, user *UserContext, studentID uint) (AccessLevel, error)
 
@@ -92,4 +99,13 @@ type AccessPolicy interface {
 
 	// GetUserAssignedClassID returns the class ID assigned to a wali kelas user
 	GetUserAssignedClassID(ctx context.Context, userID uint) (*uint, error)
+
+	// CanAccessClass checks if user can access class-wide data (attendance, BK activity) of a class
+	CanAccessClass(ctx context.Context, user *UserContext, classID uint) (bool, error)
+
+	// CanAccessSchoolAttendance checks if user can see the attendance of the whole school
+	CanAccessSchoolAttendance(user *UserContext) bool
+
+	// CanManageDevices checks if user can see and manage the school's RFID devices
+	CanManageDevices(user *UserContext) bool
 }
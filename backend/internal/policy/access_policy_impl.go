@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/gradebook"
 )
 
 // accessPolicy implements the AccessPolicy interface
@@ -38,6 +39,10 @@ func (p *accessPolicy) CanAccessStudent(ctx context.Context, user *UserContext,
 		// Wali kelas can access students in their assigned class
 		return p.IsStudentInUserClass(ctx, user, studentID)
 
+	case models.RoleGuru:
+		// Guru can access students of the classes they teach this semester
+		return p.isTeachingStudent(ctx, user, studentID, nil)
+
 	case models.RoleParent:
 		// Parent can only access their linked children
 		return p.isParentOfStudent(ctx, user.UserID, studentID)
@@ -127,6 +132,25 @@ func (p *accessPolicy) CanAccessGrade(ctx context.Context, user *UserContext, st
 		if inClass {
 			return AccessLevelFull, nil
 		}
+		// or for students of the classes they teach
+		teaching, err := p.isTeachingStudent(ctx, user, studentID, nil)
+		if err != nil {
+			return AccessLevelNone, err
+		}
+		if teaching {
+			return AccessLevelFull, nil
+		}
+		return AccessLevelNone, nil
+
+	case models.RoleGuru:
+		// Guru can access grades of students of the classes they teach
+		teaching, err := p.isTeachingStudent(ctx, user, studentID, nil)
+		if err != nil {
+			return AccessLevelNone, err
+		}
+		if teaching {
+			return AccessLevelFull, nil
+		}
 		return AccessLevelNone, nil
 
 	case models.RoleGuruBK:
@@ -176,7 +200,39 @@ func (p *accessPolicy) CanModifyGrade(ctx context.Context, user *UserContext, st
 
 	case models.RoleWaliKelas:
 		// Wali kelas can only modify grades for students in their class
-		return p.IsStudentInUserClass(ctx, user, studentID)
+		inClass, err := p.IsStudentInUserClass(ctx, user, studentID)
+		if err != nil || inClass {
+			return inClass, err
+		}
+		// or of the classes they teach
+		return p.isTeachingStudent(ctx, user, studentID, nil)
+
+	case models.RoleGuru:
+		// Guru can only modify grades for students of the classes they teach
+		return p.isTeachingStudent(ctx, user, studentID, nil)
+
+	default:
+		return false, nil
+	}
+}
+
+// CanModifySubjectGrade checks if user can modify a student's grades of a subject in the current semester
+func (p *accessPolicy) CanModifySubjectGrade(ctx context.Context, user *UserContext, studentID, subjectID uint) (bool, error) {
+	switch user.Role {
+	case models.RoleSuperAdmin, models.RoleAdminSekolah:
+		return true, nil
+
+	case models.RoleWaliKelas:
+		// Wali kelas can modify every subject for students in their class
+		inClass, err := p.IsStudentInUserClass(ctx, user, studentID)
+		if err != nil || inClass {
+			return inClass, err
+		}
+		return p.isTeachingStudent(ctx, user, studentID, &subjectID)
+
+	case models.RoleGuru:
+		// Guru can only modify the subjects they are assigned to teach in the student's class
+		return p.isTeachingStudent(ctx, user, studentID, &subjectID)
 
 	default:
 		return false, nil
@@ -323,7 +379,7 @@ func (p *accessPolicy) IsStudentInUserClass(ctx context.Context, user *UserConte
 func (p *accessPolicy) GetUserAssignedClassID(ctx context.Context, userID uint) (*uint, error) {
 	var class models.Class
 	err := p.db.WithContext(ctx).
-		Where("homeroom_teacher_id = ?", userID).
+		Where("homeroom_teacher_id = ? AND is_archived = ?", userID, false).
 		First(&class).Error
 
 	if err != nil {
@@ -336,8 +392,121 @@ func (p *accessPolicy) GetUserAssignedClassID(ctx context.Context, userID uint)
 	return &class.ID, nil
 }
 
+// CanAccessClass checks if user can access class-wide data of a class
+// Requirements: 4.6 - Wali_Kelas only sees their assigned class
+func (p *accessPolicy) CanAccessClass(ctx context.Context, user *UserContext, classID uint) (bool, error) {
+	switch user.Role {
+	case models.RoleSuperAdmin:
+		return true, nil
+
+	case models.RoleAdminSekolah, models.RoleGuruBK:
+		// Admin and Guru BK can access every class in their school
+		return p.isClassInSchool(ctx, classID, user.SchoolID)
+
+	case models.RoleWaliKelas:
+		// Wali kelas can only access their assigned class
+		assignedClassID, err := p.GetUserAssignedClassID(ctx, user.UserID)
+		if err != nil {
+			return false, err
+		}
+		return assignedClassID != nil && *assignedClassID == classID, nil
+
+	case models.RoleGuru:
+		// Guru can access the classes they teach this semester
+		return p.IsTeachingClass(ctx, user, classID, nil)
+
+	default:
+		return false, nil
+	}
+}
+
+// IsTeachingClass checks if user is assigned to teach in a class this semester
+// With a subject, only an assignment for that subject counts.
+func (p *accessPolicy) IsTeachingClass(ctx context.Context, user *UserContext, classID uint, subjectID *uint) (bool, error) {
+	if user.SchoolID == nil {
+		return false, nil
+	}
+
+	period, err := gradebook.CurrentPeriod(ctx, p.db, *user.SchoolID)
+	if err != nil {
+		return false, err
+	}
+
+	query := p.db.WithContext(ctx).
+		Model(&models.TeachingAssignment{}).
+		Where("teacher_id = ? AND class_id = ? AND school_id = ?", user.UserID, classID, *user.SchoolID).
+		Where("academic_year = ? AND semester = ?", period.AcademicYear, period.Semester)
+	if subjectID != nil {
+		query = query.Where("subject_id = ?", *subjectID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// CanAccessSchoolAttendance checks if user can see the attendance of the whole school
+func (p *accessPolicy) CanAccessSchoolAttendance(user *UserContext) bool {
+	switch user.Role {
+	case models.RoleSuperAdmin, models.RoleAdminSekolah, models.RoleGuruBK:
+		return true
+
+	default:
+		// Wali kelas only see their class; parents and students only their own records
+		return false
+	}
+}
+
+// CanManageDevices checks if user can see and manage the school's RFID devices
+func (p *accessPolicy) CanManageDevices(user *UserContext) bool {
+	return user.Role == models.RoleSuperAdmin || user.Role == models.RoleAdminSekolah
+}
+
 // Helper methods
 
+// isTeachingStudent checks if user is assigned to teach in the student's class this semester
+func (p *accessPolicy) isTeachingStudent(ctx context.Context, user *UserContext, studentID uint, subjectID *uint) (bool, error) {
+	var student models.Student
+	err := p.db.WithContext(ctx).
+		Select("id", "class_id").
+		Where("id = ?", studentID).
+		First(&student).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	if student.ClassID == nil {
+		return false, nil
+	}
+
+	return p.IsTeachingClass(ctx, user, *student.ClassID, subjectID)
+}
+
+// isClassInSchool checks if a class belongs to a specific school
+func (p *accessPolicy) isClassInSchool(ctx context.Context, classID uint, schoolID *uint) (bool, error) {
+	if schoolID == nil {
+		return false, nil
+	}
+
+	var count int64
+	err := p.db.WithContext(ctx).
+		Model(&models.Class{}).
+		Where("id = ? AND school_id = ?", classID, *schoolID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 // isStudentInSchool checks if a student belongs to a specific school
 func (p *accessPolicy) isStudentInSchool(ctx context.Context, studentID uint, schoolID *uint) (bool, error) {
 	if schoolID == nil {
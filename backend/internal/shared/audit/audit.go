@@ -0,0 +1,293 @@
+// Package audit keeps the audit trail of school data.
+// Plugin records every create, update and delete of the audited tables made while handling
+// a signed-in user's request, in the same transaction as the change; bulk changes made with
+// raw SQL are recorded as one entry each with RecordBulk. Reads that are sensitive
+// on their own, such as opening internal counseling notes, are recorded with RecordRead.
+// Changes without a signed-in user, e.g. RFID taps from devices or background jobs, are not
+// recorded: their records already name the device or job that made them.
+// Login locks and their unlocking are recorded with RecordLock and RecordUnlock, since
+// they only live in Redis.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// RequestLocal is the request local holding the Request of an audited change
+const RequestLocal = "auditRequest"
+
+// Modules of the audit trail
+const (
+	ModuleSchool     = "school"
+	ModuleBK         = "bk"
+	ModuleGrade      = "grade"
+	ModuleHomeroom   = "homeroom"
+	ModuleAttendance = "attendance"
+	ModuleDevice     = "device"
+	ModuleSettings   = "settings"
+)
+
+// tables maps each audited table to the module it belongs to
+var tables = map[string]string{
+	"users":                      ModuleSchool,
+	"classes":                    ModuleSchool,
+	"students":                   ModuleSchool,
+	"parents":                    ModuleSchool,
+	"student_parents":            ModuleSchool,
+	"student_enrollments":        ModuleSchool,
+	"class_counselors":           ModuleSchool,
+	"academic_year_rollovers":    ModuleSchool,
+	"violations":                 ModuleBK,
+	"violation_categories":       ModuleBK,
+	"violation_thresholds":       ModuleBK,
+	"violation_escalations":      ModuleBK,
+	"achievements":               ModuleBK,
+	"permits":                    ModuleBK,
+	"counseling_notes":           ModuleBK,
+	"counseling_cases":           ModuleBK,
+	"counseling_sessions":        ModuleBK,
+	"counseling_follow_ups":      ModuleBK,
+	"counseling_case_violations": ModuleBK,
+	"counseling_case_permits":    ModuleBK,
+	"subjects":                   ModuleGrade,
+	"assessment_categories":      ModuleGrade,
+	"teaching_assignments":       ModuleGrade,
+	"grades":                     ModuleGrade,
+	"homeroom_notes":             ModuleHomeroom,
+	"attendances":                ModuleAttendance,
+	"devices":                    ModuleDevice,
+	"firmware_releases":          ModuleDevice,
+	"firmware_rollouts":          ModuleDevice,
+	"school_settings":            ModuleSettings,
+}
+
+// IsModule checks if a name is a module of the audit trail
+func IsModule(name string) bool {
+	for _, module := range tables {
+		if module == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Request describes the HTTP request a change was made in
+type Request struct {
+	IPAddress string
+	Method    string
+	Path      string
+}
+
+// Actor is the signed-in user a change is attributed to
+type Actor struct {
+	UserID   uint
+	Role     string
+	Username string
+	SchoolID *uint
+	Request  Request
+}
+
+// ActorFromContext returns the signed-in user of the request a context belongs to.
+// Handlers pass the request context to services, so it carries the request locals set by the
+// auth middleware. It returns nil outside the request of a signed-in user.
+func ActorFromContext(ctx context.Context) *Actor {
+	if ctx == nil {
+		return nil
+	}
+
+	userID, ok := ctx.Value("userID").(uint)
+	if !ok || userID == 0 {
+		return nil
+	}
+
+	actor := &Actor{UserID: userID}
+	actor.Role, _ = ctx.Value("role").(string)
+	actor.Username, _ = ctx.Value("username").(string)
+	actor.SchoolID, _ = ctx.Value("schoolID").(*uint)
+	actor.Request, _ = ctx.Value(RequestLocal).(Request)
+	return actor
+}
+
+// RecordRead records that the signed-in user read records of an audited table
+func RecordRead(ctx context.Context, db *gorm.DB, table string, ids ...uint) error {
+	actor := ActorFromContext(ctx)
+	module, audited := tables[table]
+	if actor == nil || !audited || len(ids) == 0 {
+		return nil
+	}
+
+	logs := make([]models.AuditLog, 0, len(ids))
+	for _, id := range ids {
+		logs = append(logs, newLog(actor, models.AuditActionRead, module, table, id, nil))
+	}
+
+	return db.WithContext(ctx).Create(&logs).Error
+}
+
+// BulkChange is a change of many rows made with raw SQL, which Plugin does not see
+type BulkChange struct {
+	Action       models.AuditAction
+	Table        string
+	SchoolID     uint                   // School of the changed rows
+	Filter       string                 // Condition selecting the rows, with placeholders for Args
+	Args         []interface{}          // Arguments of Filter
+	Set          map[string]interface{} // Columns set by an update
+	RowsAffected int64
+	Job          string // Background job making the change, recorded as the actor without a signed-in user
+}
+
+// RecordBulk records a bulk change as a single entry with its filter and the number of rows it changed.
+// Unlike single records, which name the job that made them, bulk changes of background jobs are recorded too.
+func RecordBulk(ctx context.Context, db *gorm.DB, change BulkChange) error {
+	actor := ActorFromContext(ctx)
+	if actor == nil && change.Job != "" {
+		actor = &Actor{Role: "system", Username: change.Job}
+	}
+	module, audited := tables[change.Table]
+	if actor == nil || !audited || change.RowsAffected == 0 {
+		return nil
+	}
+
+	entry := newLog(actor, change.Action, module, change.Table, 0, map[string]interface{}{"school_id": change.SchoolID})
+	summary := map[string]interface{}{
+		"filter":        change.Filter,
+		"args":          change.Args,
+		"rows_affected": change.RowsAffected,
+	}
+	if len(change.Set) > 0 {
+		summary["set"] = change.Set
+	}
+	if change.Action == models.AuditActionDelete {
+		entry.Before = encode(summary)
+	} else {
+		entry.After = encode(summary)
+	}
+	return db.WithContext(ctx).Create(&entry).Error
+}
+
+// LockEvent is a username or address locked out after too many failed attempts
+type LockEvent struct {
+	User      *models.User // Nil when the username does not exist or only the address was locked
+	Username  string       // Locked username; empty when the address was locked
+	IPAddress string       // Address of the attempt that caused the lock
+	Reason    string       // What failed too often, e.g. "login" or "two-factor"
+	Lockout   time.Duration
+}
+
+// RecordLock records a lock. It has no signed-in actor, so the entry is attributed to the
+// locked user when known, and to nobody (actor 0) otherwise.
+func RecordLock(ctx context.Context, db *gorm.DB, event LockEvent) error {
+	entry := models.AuditLog{
+		IPAddress:  truncate(event.IPAddress, 45),
+		Action:     models.AuditActionLock,
+		Module:     ModuleSchool,
+		EntityType: "users",
+		After: encode(map[string]interface{}{
+			"username":        event.Username,
+			"reason":          event.Reason,
+			"lockout_minutes": int(event.Lockout / time.Minute),
+		}),
+	}
+	if request, ok := ctx.Value(RequestLocal).(Request); ok {
+		entry.Method = request.Method
+		entry.Path = truncate(request.Path, 255)
+	}
+	if event.User != nil {
+		entry.SchoolID = event.User.SchoolID
+		entry.ActorID = event.User.ID
+		entry.ActorRole = string(event.User.Role)
+		entry.ActorUsername = event.User.Username
+		entry.EntityID = event.User.ID
+	}
+
+	return db.WithContext(ctx).Create(&entry).Error
+}
+
+// RecordUnlock records that the signed-in user lifted the login lock of a user
+func RecordUnlock(ctx context.Context, db *gorm.DB, user *models.User, wasLocked bool) error {
+	actor := ActorFromContext(ctx)
+	if actor == nil {
+		return nil
+	}
+
+	entry := newLog(actor, models.AuditActionUnlock, ModuleSchool, "users", user.ID, map[string]interface{}{"school_id": user.SchoolID})
+	entry.After = encode(map[string]interface{}{
+		"username":   user.Username,
+		"was_locked": wasLocked,
+	})
+	return db.WithContext(ctx).Create(&entry).Error
+}
+
+// newLog creates an entry for a record, in the school of the record when it has one
+func newLog(actor *Actor, action models.AuditAction, module, table string, entityID uint, row map[string]interface{}) models.AuditLog {
+	schoolID := actor.SchoolID
+	if id, ok := toUint(row["school_id"]); ok && id != 0 {
+		schoolID = &id
+	}
+
+	return models.AuditLog{
+		SchoolID:      schoolID,
+		ActorID:       actor.UserID,
+		ActorRole:     actor.Role,
+		ActorUsername: actor.Username,
+		IPAddress:     actor.Request.IPAddress,
+		Method:        actor.Request.Method,
+		Path:          truncate(actor.Request.Path, 255),
+		Action:        action,
+		Module:        module,
+		EntityType:    table,
+		EntityID:      entityID,
+	}
+}
+
+// encode encodes changed columns for the Before or After of an entry
+func encode(values map[string]interface{}) *string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+
+	encoded := string(data)
+	return &encoded
+}
+
+// toUint converts an ID read from a model or a database row
+func toUint(value interface{}) (uint, bool) {
+	switch v := value.(type) {
+	case uint:
+		return v, true
+	case *uint:
+		if v != nil {
+			return *v, true
+		}
+	case uint32:
+		return uint(v), true
+	case uint64:
+		return uint(v), true
+	case int:
+		return uint(v), v >= 0
+	case int32:
+		return uint(v), v >= 0
+	case int64:
+		return uint(v), v >= 0
+	}
+	return 0, false
+}
+
+// truncate shortens a string to fit a column
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
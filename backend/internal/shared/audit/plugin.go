@@ -0,0 +1,403 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// ErrAppendOnly is returned when a statement would change or delete audit log entries
+var ErrAppendOnly = errors.New("audit log entries cannot be changed or deleted")
+
+// guardStatements make the database itself reject changes to audit log entries, including raw
+// SQL and other clients. Only a role allowed to drop the trigger can get around it.
+var guardStatements = []string{
+	`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger LANGUAGE plpgsql AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit log entries cannot be changed or deleted (%)', TG_OP;
+	END;
+	$$`,
+	`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`,
+	`CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only()`,
+}
+
+// InstallGuard installs the trigger keeping the audit_logs table append-only
+func InstallGuard(db *gorm.DB) error {
+	for _, statement := range guardStatements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	// rowsKey is the statement setting holding the rows loaded before an update or delete
+	rowsKey = "audit:rows"
+	// startedTransactionKey is the statement setting marking a transaction the plugin started
+	startedTransactionKey = "audit:started_transaction"
+	// maxRows bounds the rows loaded for a single statement
+	maxRows = 1000
+	// masked replaces the value of a column the API never shows
+	masked = "***"
+)
+
+// ignoredColumns change with every update and are left out of update diffs
+var ignoredColumns = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// Plugin is the GORM plugin recording changes to the audited tables.
+// Rows are loaded before an update or delete with the conditions of the statement, and
+// compared with the rows after it. An audited statement outside a transaction runs in one
+// the plugin starts, since the database skips default transactions, so a change whose
+// entries cannot be written is rolled back. Raw SQL run with Exec is not seen by the
+// plugin; bulk changes made that way are recorded with RecordBulk.
+type Plugin struct{}
+
+// Name returns the name of the plugin
+func (Plugin) Name() string {
+	return "audit"
+}
+
+// Initialize registers the callbacks of the plugin
+func (Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:begin_transaction").Register("audit:begin_transaction", beginTransaction); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("audit:after_create", afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("audit:after_create").Register("audit:commit_or_rollback_transaction", commitOrRollbackTransaction); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:begin_transaction").Register("audit:begin_transaction", beginTransaction); err != nil {
+		return err
+	}
+	// After GORM points the statement at its model, which the rows are looked up by
+	if err := db.Callback().Update().After("gorm:before_update").Before("gorm:update").Register("audit:before_update", beforeChange); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("audit:after_update", afterUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("audit:after_update").Register("audit:commit_or_rollback_transaction", commitOrRollbackTransaction); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:begin_transaction").Register("audit:begin_transaction", beginTransaction); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", beforeChange); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", afterDelete); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("audit:after_delete").Register("audit:commit_or_rollback_transaction", commitOrRollbackTransaction)
+}
+
+// beginTransaction starts a transaction for an audited statement that does not run in one
+func beginTransaction(db *gorm.DB) {
+	if _, _, ok := audited(db); !ok {
+		return
+	}
+
+	// Begin fails with ErrInvalidTransaction inside a transaction, which the entries join instead
+	if tx := db.Begin(); tx.Error == nil {
+		db.Statement.ConnPool = tx.Statement.ConnPool
+		db.InstanceSet(startedTransactionKey, true)
+	} else if !errors.Is(tx.Error, gorm.ErrInvalidTransaction) {
+		db.AddError(tx.Error)
+	}
+}
+
+// commitOrRollbackTransaction ends the transaction started by beginTransaction,
+// rolling the change back if it or its entries failed
+func commitOrRollbackTransaction(db *gorm.DB) {
+	if _, ok := db.InstanceGet(startedTransactionKey); !ok {
+		return
+	}
+
+	if db.Error != nil {
+		db.Rollback()
+	} else {
+		db.Commit()
+	}
+	db.Statement.ConnPool = db.ConnPool
+}
+
+// audited returns the actor and module of a statement whose changes are recorded
+func audited(db *gorm.DB) (*Actor, string, bool) {
+	stmt := db.Statement
+	if db.Error != nil || db.DryRun || stmt.Schema == nil {
+		return nil, "", false
+	}
+
+	module, ok := tables[stmt.Table]
+	if !ok {
+		return nil, "", false
+	}
+
+	actor := ActorFromContext(stmt.Context)
+	if actor == nil {
+		return nil, "", false
+	}
+
+	return actor, module, true
+}
+
+// beforeChange loads the rows an update or delete is about to change
+func beforeChange(db *gorm.DB) {
+	if db.Error == nil && db.Statement.Table == (models.AuditLog{}).TableName() {
+		db.AddError(ErrAppendOnly)
+		return
+	}
+
+	if _, _, ok := audited(db); !ok {
+		return
+	}
+
+	var rows []map[string]interface{}
+	if err := scope(db).Limit(maxRows).Find(&rows).Error; err != nil {
+		db.AddError(fmt.Errorf("audit: failed to load %s rows before change: %w", db.Statement.Table, err))
+		return
+	}
+	db.InstanceSet(rowsKey, rows)
+}
+
+// afterCreate records the rows a statement created
+func afterCreate(db *gorm.DB) {
+	actor, module, ok := audited(db)
+	if !ok || db.RowsAffected == 0 {
+		return
+	}
+
+	hidden := hiddenColumns(db.Statement.Schema)
+	logs := make([]models.AuditLog, 0)
+	for _, row := range createdRows(db.Statement) {
+		id, hasID := toUint(row["id"])
+		if hasID && id == 0 {
+			// Not inserted, e.g. skipped by ON CONFLICT DO NOTHING
+			continue
+		}
+
+		entry := newLog(actor, models.AuditActionCreate, module, db.Statement.Table, id, row)
+		entry.After = encode(maskRow(row, hidden))
+		logs = append(logs, entry)
+	}
+
+	write(db, logs)
+}
+
+// afterUpdate records the columns an update changed, one entry per changed row
+func afterUpdate(db *gorm.DB) {
+	actor, module, ok := audited(db)
+	if !ok || db.RowsAffected == 0 {
+		return
+	}
+
+	before := loadedRows(db)
+	ids := make([]uint, 0, len(before))
+	for _, row := range before {
+		if id, ok := toUint(row["id"]); ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	var after []map[string]interface{}
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Table(db.Statement.Table).
+		Where("id IN ?", ids).
+		Find(&after).Error; err != nil {
+		db.AddError(fmt.Errorf("audit: failed to load %s rows after update: %w", db.Statement.Table, err))
+		return
+	}
+
+	afterByID := make(map[uint]map[string]interface{}, len(after))
+	for _, row := range after {
+		if id, ok := toUint(row["id"]); ok {
+			afterByID[id] = row
+		}
+	}
+
+	hidden := hiddenColumns(db.Statement.Schema)
+	logs := make([]models.AuditLog, 0)
+	for _, old := range before {
+		id, _ := toUint(old["id"])
+		current, ok := afterByID[id]
+		if !ok {
+			continue
+		}
+
+		oldValues, newValues := diff(old, current, hidden)
+		if len(newValues) == 0 {
+			continue
+		}
+
+		entry := newLog(actor, models.AuditActionUpdate, module, db.Statement.Table, id, current)
+		entry.Before = encode(oldValues)
+		entry.After = encode(newValues)
+		logs = append(logs, entry)
+	}
+
+	write(db, logs)
+}
+
+// afterDelete records the rows a delete removed
+func afterDelete(db *gorm.DB) {
+	actor, module, ok := audited(db)
+	if !ok || db.RowsAffected == 0 {
+		return
+	}
+
+	hidden := hiddenColumns(db.Statement.Schema)
+	rows := loadedRows(db)
+	logs := make([]models.AuditLog, 0, len(rows))
+	for _, row := range rows {
+		id, _ := toUint(row["id"])
+		entry := newLog(actor, models.AuditActionDelete, module, db.Statement.Table, id, row)
+		entry.Before = encode(maskRow(row, hidden))
+		logs = append(logs, entry)
+	}
+
+	write(db, logs)
+}
+
+// scope returns a query for the rows an update or delete applies to.
+// Besides the conditions of the statement, GORM limits it to the primary key of its model.
+func scope(db *gorm.DB) *gorm.DB {
+	stmt := db.Statement
+	// A blank model lets the conditions refer to the primary key without adding its values again
+	query := db.Session(&gorm.Session{NewDB: true}).
+		Model(reflect.New(stmt.Schema.ModelType).Interface()).
+		Table(stmt.Table)
+
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			query = query.Clauses(clause.Where{Exprs: where.Exprs})
+		}
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array:
+		_, values := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, queryValues := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, values)
+		if len(queryValues) > 0 {
+			query = query.Clauses(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: queryValues}}})
+		}
+	}
+
+	return query
+}
+
+// loadedRows returns the rows loaded before an update or delete
+func loadedRows(db *gorm.DB) []map[string]interface{} {
+	value, ok := db.InstanceGet(rowsKey)
+	if !ok {
+		return nil
+	}
+	rows, _ := value.([]map[string]interface{})
+	return rows
+}
+
+// createdRows reads the columns of the records a create statement inserted
+func createdRows(stmt *gorm.Statement) []map[string]interface{} {
+	rowOf := func(value reflect.Value) map[string]interface{} {
+		row := make(map[string]interface{}, len(stmt.Schema.DBNames))
+		for _, name := range stmt.Schema.DBNames {
+			row[name], _ = stmt.Schema.FieldsByDBName[name].ValueOf(stmt.Context, value)
+		}
+		return row
+	}
+
+	value := reflect.Indirect(stmt.ReflectValue)
+	switch value.Kind() {
+	case reflect.Struct:
+		return []map[string]interface{}{rowOf(value)}
+	case reflect.Slice, reflect.Array:
+		rows := make([]map[string]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			rows = append(rows, rowOf(reflect.Indirect(value.Index(i))))
+		}
+		return rows
+	}
+	return nil
+}
+
+// hiddenColumns returns the columns of a model the API never shows (json:"-"),
+// e.g. password hashes, device keys and internal counseling notes
+func hiddenColumns(s *schema.Schema) map[string]bool {
+	hidden := make(map[string]bool)
+	for _, field := range s.Fields {
+		if field.DBName != "" && field.Tag.Get("json") == "-" {
+			hidden[field.DBName] = true
+		}
+	}
+	return hidden
+}
+
+// maskRow returns a row with the values of hidden columns masked
+func maskRow(row map[string]interface{}, hidden map[string]bool) map[string]interface{} {
+	values := make(map[string]interface{}, len(row))
+	for column, value := range row {
+		values[column] = maskValue(column, value, hidden)
+	}
+	return values
+}
+
+// diff returns the old and new values of the columns that differ between two rows
+func diff(old, current map[string]interface{}, hidden map[string]bool) (map[string]interface{}, map[string]interface{}) {
+	oldValues := make(map[string]interface{})
+	newValues := make(map[string]interface{})
+	for column, value := range current {
+		if ignoredColumns[column] || equal(old[column], value) {
+			continue
+		}
+		oldValues[column] = maskValue(column, old[column], hidden)
+		newValues[column] = maskValue(column, value, hidden)
+	}
+	return oldValues, newValues
+}
+
+// maskValue masks the value of a hidden column and makes a column value JSON friendly
+func maskValue(column string, value interface{}, hidden map[string]bool) interface{} {
+	if hidden[column] {
+		return masked
+	}
+	if data, ok := value.([]byte); ok {
+		return string(data)
+	}
+	return value
+}
+
+// equal compares two column values by their JSON encoding, which also treats the
+// different types a driver may scan the same value into alike
+func equal(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(maskValue("", a, nil))
+	encodedB, errB := json.Marshal(maskValue("", b, nil))
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+// write stores entries in the transaction of the audited statement.
+// A failure fails the statement, so its transaction rolls the change back.
+func write(db *gorm.DB, logs []models.AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&logs).Error; err != nil {
+		db.AddError(fmt.Errorf("audit: failed to record %d %s changes: %w", len(logs), db.Statement.Table, err))
+	}
+}
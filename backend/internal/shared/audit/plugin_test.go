@@ -0,0 +1,329 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+func TestHiddenColumns(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{}
+		want  map[string]bool
+	}{
+		{
+			name:  "user",
+			model: &models.User{},
+			want:  map[string]bool{"password_hash": true},
+		},
+		{
+			name:  "device",
+			model: &models.Device{},
+			want:  map[string]bool{"api_key": true, "previous_key_hash": true, "previous_key_prefix": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := schema.Parse(tt.model, &sync.Map{}, schema.NamingStrategy{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hiddenColumns(s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hiddenColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	hidden := map[string]bool{"password_hash": true}
+
+	tests := []struct {
+		name    string
+		old     map[string]interface{}
+		current map[string]interface{}
+		wantOld map[string]interface{}
+		wantNew map[string]interface{}
+	}{
+		{
+			name:    "changed column",
+			old:     map[string]interface{}{"id": uint(1), "name": "Budi", "nis": "1001"},
+			current: map[string]interface{}{"id": uint(1), "name": "Budi Santoso", "nis": "1001"},
+			wantOld: map[string]interface{}{"name": "Budi"},
+			wantNew: map[string]interface{}{"name": "Budi Santoso"},
+		},
+		{
+			name:    "nothing changed",
+			old:     map[string]interface{}{"id": uint(1), "name": "Budi"},
+			current: map[string]interface{}{"id": uint(1), "name": "Budi"},
+			wantOld: map[string]interface{}{},
+			wantNew: map[string]interface{}{},
+		},
+		{
+			name:    "timestamps are ignored",
+			old:     map[string]interface{}{"updated_at": "2026-01-05T07:00:00Z", "created_at": "2026-01-01T07:00:00Z"},
+			current: map[string]interface{}{"updated_at": "2026-01-06T07:00:00Z", "created_at": "2026-01-02T07:00:00Z"},
+			wantOld: map[string]interface{}{},
+			wantNew: map[string]interface{}{},
+		},
+		{
+			name:    "hidden column is masked",
+			old:     map[string]interface{}{"password_hash": "$2a$10$old"},
+			current: map[string]interface{}{"password_hash": "$2a$10$new"},
+			wantOld: map[string]interface{}{"password_hash": masked},
+			wantNew: map[string]interface{}{"password_hash": masked},
+		},
+		{
+			name:    "same value scanned into another type",
+			old:     map[string]interface{}{"class_id": int64(3), "nisn": []byte("0012345678")},
+			current: map[string]interface{}{"class_id": uint(3), "nisn": "0012345678"},
+			wantOld: map[string]interface{}{},
+			wantNew: map[string]interface{}{},
+		},
+		{
+			name:    "column set to null",
+			old:     map[string]interface{}{"class_id": int64(3)},
+			current: map[string]interface{}{"class_id": nil},
+			wantOld: map[string]interface{}{"class_id": int64(3)},
+			wantNew: map[string]interface{}{"class_id": nil},
+		},
+		{
+			name:    "bytes are shown as text",
+			old:     map[string]interface{}{"notes": []byte("lama")},
+			current: map[string]interface{}{"notes": []byte("baru")},
+			wantOld: map[string]interface{}{"notes": "lama"},
+			wantNew: map[string]interface{}{"notes": "baru"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOld, gotNew := diff(tt.old, tt.current, hidden)
+			if !reflect.DeepEqual(gotOld, tt.wantOld) {
+				t.Errorf("diff() old = %v, want %v", gotOld, tt.wantOld)
+			}
+			if !reflect.DeepEqual(gotNew, tt.wantNew) {
+				t.Errorf("diff() new = %v, want %v", gotNew, tt.wantNew)
+			}
+		})
+	}
+}
+
+func TestMaskRow(t *testing.T) {
+	row := map[string]interface{}{"id": uint(4), "username": "guru", "password_hash": "$2a$10$hash", "notes": []byte("catatan")}
+	want := map[string]interface{}{"id": uint(4), "username": "guru", "password_hash": masked, "notes": "catatan"}
+
+	if got := maskRow(row, map[string]bool{"password_hash": true}); !reflect.DeepEqual(got, want) {
+		t.Errorf("maskRow() = %v, want %v", got, want)
+	}
+	if row["password_hash"] != "$2a$10$hash" {
+		t.Error("maskRow() changed the row it was given")
+	}
+}
+
+func TestNewLog(t *testing.T) {
+	actorSchool := uint(1)
+	actor := &Actor{
+		UserID:   9,
+		SchoolID: &actorSchool,
+		Role:     "admin_sekolah",
+		Username: "admin",
+		Request:  Request{IPAddress: "10.0.0.1", Method: "PUT", Path: "/api/v1/students/5"},
+	}
+
+	tests := []struct {
+		name       string
+		row        map[string]interface{}
+		wantSchool uint
+	}{
+		{name: "school of the record", row: map[string]interface{}{"school_id": int64(2)}, wantSchool: 2},
+		{name: "record without a school", row: map[string]interface{}{"name": "x"}, wantSchool: 1},
+		{name: "record with a null school", row: map[string]interface{}{"school_id": (*uint)(nil)}, wantSchool: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newLog(actor, models.AuditActionUpdate, ModuleSchool, "students", 5, tt.row)
+			if entry.SchoolID == nil || *entry.SchoolID != tt.wantSchool {
+				t.Errorf("newLog() school = %v, want %d", entry.SchoolID, tt.wantSchool)
+			}
+			if entry.ActorID != 9 || entry.EntityType != "students" || entry.EntityID != 5 || entry.Path != actor.Request.Path {
+				t.Errorf("newLog() = %+v", entry)
+			}
+		})
+	}
+}
+
+// fakeDatabase is a database/sql driver holding the name of a single class, enough to run
+// the plugin's transaction: updates stay pending until the transaction commits, and
+// inserting audit entries fails while failAudit is set.
+type fakeDatabase struct {
+	mu        sync.Mutex
+	name      string  // Committed name of class 1
+	pending   *string // Name written in the open transaction
+	failAudit bool
+	audits    int // Audit entry inserts that succeeded
+	commits   int
+	rollbacks int
+}
+
+func (f *fakeDatabase) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDatabase) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	db *fakeDatabase
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return c, nil }
+
+func (c *fakeConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if c.db.pending != nil {
+		c.db.name = *c.db.pending
+	}
+	c.db.pending = nil
+	c.db.commits++
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.pending = nil
+	c.db.rollbacks++
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if !strings.HasPrefix(query, `UPDATE "classes" SET`) {
+		return nil, fmt.Errorf("unexpected statement: %s", query)
+	}
+	name := args[0].Value.(string)
+	c.db.pending = &name
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, `INSERT INTO "audit_logs"`):
+		if c.db.failAudit {
+			return nil, errors.New("disk full")
+		}
+		c.db.audits++
+		return &fakeRows{columns: []string{"id"}, values: [][]driver.Value{{int64(c.db.audits)}}}, nil
+	case strings.HasPrefix(query, `SELECT * FROM "classes"`):
+		name := c.db.name
+		if c.db.pending != nil {
+			name = *c.db.pending
+		}
+		return &fakeRows{
+			columns: []string{"id", "school_id", "name"},
+			values:  [][]driver.Value{{int64(1), int64(2), name}},
+		}, nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// openFake opens a database with the plugin on a fakeDatabase, configured like the server's
+func openFake(t *testing.T, fake *fakeDatabase) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(fake)}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(Plugin{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestPluginUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		failAudit  bool
+		wantErr    bool
+		wantName   string
+		wantAudits int
+	}{
+		{name: "entry written", wantName: "X-B", wantAudits: 1},
+		{name: "entry fails", failAudit: true, wantErr: true, wantName: "X-A", wantAudits: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeDatabase{name: "X-A", failAudit: tt.failAudit}
+			db := openFake(t, fake)
+
+			ctx := context.WithValue(context.Background(), "userID", uint(9))
+			err := db.WithContext(ctx).Model(&models.Class{ID: 1}).Update("name", "X-B").Error
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update() error = %v, want error: %v", err, tt.wantErr)
+			}
+			if fake.name != tt.wantName {
+				t.Errorf("class name = %q, want %q", fake.name, tt.wantName)
+			}
+			if fake.audits != tt.wantAudits {
+				t.Errorf("audit entries = %d, want %d", fake.audits, tt.wantAudits)
+			}
+			if tt.wantErr && (fake.rollbacks != 1 || fake.commits != 0) {
+				t.Errorf("commits = %d, rollbacks = %d, want the change rolled back", fake.commits, fake.rollbacks)
+			}
+			if !tt.wantErr && (fake.commits != 1 || fake.rollbacks != 0) {
+				t.Errorf("commits = %d, rollbacks = %d, want the change committed", fake.commits, fake.rollbacks)
+			}
+		})
+	}
+}
+
+func TestPluginRefusesAuditLogChanges(t *testing.T) {
+	db := openFake(t, &fakeDatabase{})
+
+	if err := db.Model(&models.AuditLog{ID: 1}).Update("action", "read").Error; !errors.Is(err, ErrAppendOnly) {
+		t.Errorf("Update() error = %v, want %v", err, ErrAppendOnly)
+	}
+	if err := db.Delete(&models.AuditLog{ID: 1}).Error; !errors.Is(err, ErrAppendOnly) {
+		t.Errorf("Delete() error = %v, want %v", err, ErrAppendOnly)
+	}
+}
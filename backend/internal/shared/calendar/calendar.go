@@ -0,0 +1,158 @@
+// Package calendar resolves a school's calendar: holidays and per-date schedule overrides.
+// It is shared by every module that needs to know whether a date is a school day and
+// which attendance schedules apply on it.
+package calendar
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// FindHoliday returns the holiday covering the date, or nil if the date is not a holiday
+func FindHoliday(ctx context.Context, db *gorm.DB, schoolID uint, date time.Time) (*models.SchoolHoliday, error) {
+	day := date.Format(dateLayout)
+
+	var holiday models.SchoolHoliday
+	err := db.WithContext(ctx).
+		Where("school_id = ? AND start_date <= ?::date AND end_date >= ?::date", schoolID, day, day).
+		Order("start_date ASC").
+		First(&holiday).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+// HolidayDates returns the set of dates (YYYY-MM-DD) between start and end that are holidays
+func HolidayDates(ctx context.Context, db *gorm.DB, schoolID uint, start, end time.Time) (map[string]bool, error) {
+	var holidays []models.SchoolHoliday
+	err := db.WithContext(ctx).
+		Where("school_id = ? AND start_date <= ?::date AND end_date >= ?::date",
+			schoolID, end.Format(dateLayout), start.Format(dateLayout)).
+		Find(&holidays).Error
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make(map[string]bool)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		for i := range holidays {
+			if holidays[i].Covers(d) {
+				dates[d.Format(dateLayout)] = true
+				break
+			}
+		}
+	}
+	return dates, nil
+}
+
+// CountSchoolDays counts weekdays (Mon-Fri) between two dates that are not holidays
+func CountSchoolDays(start, end time.Time, holidays map[string]bool) int {
+	return len(SchoolDays(start, end, holidays))
+}
+
+// SchoolDays lists the weekdays (Mon-Fri) between two dates (inclusive) that are not holidays
+func SchoolDays(start, end time.Time, holidays map[string]bool) []time.Time {
+	var days []time.Time
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		weekday := d.Weekday()
+		if weekday == time.Saturday || weekday == time.Sunday {
+			continue
+		}
+		if holidays[d.Format(dateLayout)] {
+			continue
+		}
+		days = append(days, d)
+	}
+	return days
+}
+
+// SchedulesForDate returns the active schedules that apply on the date, with date overrides
+// applied. Schedules not running on that weekday or cancelled by an override are left out,
+// and no schedule applies on a holiday.
+func SchedulesForDate(ctx context.Context, db *gorm.DB, schoolID uint, date time.Time) ([]models.AttendanceSchedule, error) {
+	holiday, err := FindHoliday(ctx, db, schoolID, date)
+	if err != nil {
+		return nil, err
+	}
+	if holiday != nil {
+		return nil, nil
+	}
+
+	var schedules []models.AttendanceSchedule
+	if err := db.WithContext(ctx).
+		Where("school_id = ? AND is_active = ?", schoolID, true).
+		Order("start_time ASC").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	overrides, err := findOverrides(ctx, db, schoolID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.AttendanceSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if !schedule.IsActiveOnDay(date.Weekday()) {
+			continue
+		}
+		if override, ok := overrides[schedule.ID]; ok {
+			if override.IsCancelled {
+				continue
+			}
+			schedule = override.Apply(schedule)
+		}
+		result = append(result, schedule)
+	}
+	return result, nil
+}
+
+// ApplyOverride applies the override for the date (if any) to a single schedule.
+// It returns nil when the schedule is cancelled on that date.
+func ApplyOverride(ctx context.Context, db *gorm.DB, schedule *models.AttendanceSchedule, date time.Time) (*models.AttendanceSchedule, error) {
+	overrides, err := findOverrides(ctx, db, schedule.SchoolID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	override, ok := overrides[schedule.ID]
+	if !ok {
+		return schedule, nil
+	}
+	if override.IsCancelled {
+		return nil, nil
+	}
+	effective := override.Apply(*schedule)
+	return &effective, nil
+}
+
+// findOverrides returns the overrides covering the date, keyed by schedule ID.
+// When several overrides cover the date the most recently created one wins.
+func findOverrides(ctx context.Context, db *gorm.DB, schoolID uint, date time.Time) (map[uint]models.ScheduleOverride, error) {
+	day := date.Format(dateLayout)
+
+	var overrides []models.ScheduleOverride
+	if err := db.WithContext(ctx).
+		Where("school_id = ? AND start_date <= ?::date AND end_date >= ?::date", schoolID, day, day).
+		Order("created_at ASC").
+		Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]models.ScheduleOverride, len(overrides))
+	for _, override := range overrides {
+		result[override.ScheduleID] = override
+	}
+	return result, nil
+}
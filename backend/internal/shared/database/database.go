@@ -11,6 +11,8 @@ import (
 
 	"github.com/school-management/backend/internal/config"
 	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/audit"
+	"github.com/school-management/backend/internal/shared/enrollment"
 )
 
 // Connect establishes a connection to the PostgreSQL database
@@ -74,6 +76,16 @@ func Migrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	// The database rejects changes to audit log entries, whoever makes them
+	if err := audit.InstallGuard(db); err != nil {
+		return fmt.Errorf("failed to install audit log guard: %w", err)
+	}
+
+	// Students created before the enrollment timeline start it in their current class
+	if err := enrollment.Backfill(db); err != nil {
+		return fmt.Errorf("failed to backfill student enrollments: %w", err)
+	}
+
 	log.Println("Database migrations completed successfully")
 	return nil
 }
@@ -0,0 +1,157 @@
+// Package enrollment keeps the class membership timeline of students.
+// Student.ClassID only holds the current class; the student_enrollments table remembers
+// every class a student was in and when, so class-based views of past attendance, grades
+// and notes show the students who were in the class on the record's date.
+package enrollment
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"github.com/school-management/backend/internal/shared/audit"
+)
+
+const dateLayout = "2006-01-02"
+
+// Change describes why the class of some students changed
+// Reason starts the new enrollments and EndReason closes the previous ones. When Reason is
+// empty it is "new" for a student's first class and "transfer_in" afterwards; an empty
+// EndReason is "transfer_out".
+type Change struct {
+	StudentIDs []uint
+	Date       time.Time
+	Reason     models.EnrollmentReason
+	EndReason  models.EnrollmentReason
+}
+
+// Sync brings the timeline of the students in line with their current students.class_id
+// It is called after the class is updated, in the same transaction: the open enrollment
+// of a student whose class changed is closed on the date and a new one is opened for the
+// current class. Students whose class did not change are left alone.
+func Sync(ctx context.Context, db *gorm.DB, change Change) error {
+	if len(change.StudentIDs) == 0 {
+		return nil
+	}
+	day := change.Date.Format(dateLayout)
+
+	endReason := change.EndReason
+	if endReason == "" {
+		endReason = models.EnrollmentReasonTransferOut
+	}
+	closed := db.WithContext(ctx).Exec(`
+		UPDATE student_enrollments SET end_date = ?::date, end_reason = ?, updated_at = NOW()
+		FROM students
+		WHERE student_enrollments.student_id = students.id
+		  AND student_enrollments.student_id IN ?
+		  AND student_enrollments.end_date IS NULL
+		  AND (students.class_id IS NULL OR students.class_id <> student_enrollments.class_id)`,
+		day, endReason, change.StudentIDs,
+	)
+	if closed.Error != nil {
+		return closed.Error
+	}
+	if err := audit.RecordBulk(ctx, db, audit.BulkChange{
+		Action:       models.AuditActionUpdate,
+		Table:        "student_enrollments",
+		Filter:       "open enrollments of student_id IN ? whose class changed",
+		Args:         []interface{}{change.StudentIDs},
+		Set:          map[string]interface{}{"end_date": day, "end_reason": endReason},
+		RowsAffected: closed.RowsAffected,
+	}); err != nil {
+		return err
+	}
+
+	reason := "CASE WHEN EXISTS (SELECT 1 FROM student_enrollments p WHERE p.student_id = students.id) THEN 'transfer_in' ELSE 'new' END"
+	args := []interface{}{day}
+	if change.Reason != "" {
+		reason = "?"
+		args = append(args, change.Reason)
+	}
+	args = append(args, change.StudentIDs)
+
+	opened := db.WithContext(ctx).Exec(`
+		INSERT INTO student_enrollments (school_id, student_id, class_id, academic_year, start_date, reason, created_at, updated_at)
+		SELECT students.school_id, students.id, students.class_id, classes.year, ?::date, `+reason+`, NOW(), NOW()
+		FROM students
+		JOIN classes ON classes.id = students.class_id
+		WHERE students.id IN ?
+		  AND NOT EXISTS (SELECT 1 FROM student_enrollments e WHERE e.student_id = students.id AND e.end_date IS NULL)`,
+		args...,
+	)
+	if opened.Error != nil {
+		return opened.Error
+	}
+	return audit.RecordBulk(ctx, db, audit.BulkChange{
+		Action:       models.AuditActionCreate,
+		Table:        "student_enrollments",
+		Filter:       "students in a class without an open enrollment, student_id IN ?",
+		Args:         []interface{}{change.StudentIDs},
+		Set:          map[string]interface{}{"start_date": day, "reason": change.Reason},
+		RowsAffected: opened.RowsAffected,
+	})
+}
+
+// Backfill opens an enrollment for every student in a class that has no timeline yet
+// The enrollment starts on the day the student was created, so the current class
+// covers all of their existing records.
+func Backfill(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO student_enrollments (school_id, student_id, class_id, academic_year, start_date, reason, created_at, updated_at)
+		SELECT students.school_id, students.id, students.class_id, classes.year, students.created_at::date, ?, NOW(), NOW()
+		FROM students
+		JOIN classes ON classes.id = students.class_id
+		WHERE NOT EXISTS (SELECT 1 FROM student_enrollments e WHERE e.student_id = students.id)`,
+		models.EnrollmentReasonNew,
+	).Error
+}
+
+// ClassAt returns the SQL expression of the class a record's student was in on the record's date
+// The class frozen on the record at the academic year rollover wins over the timeline.
+// table is the record's table and dateExpr its date, e.g. ClassAt("grades", "grades.created_at::date").
+func ClassAt(table, dateExpr string) string {
+	return "COALESCE(" + table + ".class_id, (SELECT e.class_id FROM student_enrollments e" +
+		" WHERE e.student_id = " + table + ".student_id AND e.start_date <= " + dateExpr +
+		" AND (e.end_date IS NULL OR e.end_date > " + dateExpr + ")" +
+		" ORDER BY e.start_date DESC, e.id DESC LIMIT 1))"
+}
+
+// EnrolledOn scopes a students query to the students who were in a class on a day
+func EnrolledOn(classID uint, day time.Time) func(*gorm.DB) *gorm.DB {
+	return EnrolledBetween(classID, day, day)
+}
+
+// EnrolledBetween scopes a students query to the students who were in a class at some point from start to end (inclusive)
+func EnrolledBetween(classID uint, start, end time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			"students.id IN (SELECT student_id FROM student_enrollments WHERE class_id = ? AND start_date <= ?::date AND (end_date IS NULL OR end_date > ?::date))",
+			classID, end.Format(dateLayout), start.Format(dateLayout),
+		)
+	}
+}
+
+// InClass returns the enrollments of a class that overlap the days from start to end (inclusive), with their students
+func InClass(ctx context.Context, db *gorm.DB, classID uint, start, end time.Time) ([]models.StudentEnrollment, error) {
+	var enrollments []models.StudentEnrollment
+	err := db.WithContext(ctx).
+		Preload("Student").
+		Where("class_id = ? AND start_date <= ?::date AND (end_date IS NULL OR end_date > ?::date)",
+			classID, end.Format(dateLayout), start.Format(dateLayout)).
+		Order("start_date ASC, id ASC").
+		Find(&enrollments).Error
+	return enrollments, err
+}
+
+// Timeline returns the enrollments of a student, oldest first
+func Timeline(ctx context.Context, db *gorm.DB, studentID uint) ([]models.StudentEnrollment, error) {
+	var enrollments []models.StudentEnrollment
+	err := db.WithContext(ctx).
+		Preload("Class").
+		Where("student_id = ?", studentID).
+		Order("start_date ASC, id ASC").
+		Find(&enrollments).Error
+	return enrollments, err
+}
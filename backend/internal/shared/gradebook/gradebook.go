@@ -0,0 +1,229 @@
+// Package gradebook computes report-card grades: weighted averages per subject over the
+// assessment categories of a semester, and the pass/fail status against each subject's KKM.
+// It is shared by the grade, homeroom, parent and student modules so every view of a
+// student's grades shows the same numbers.
+package gradebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// Period is an academic year and semester, e.g. "2024/2025" semester 1
+type Period struct {
+	AcademicYear string `json:"academic_year"`
+	Semester     int    `json:"semester"`
+}
+
+// ErrInvalidPeriod is returned for an academic year that is not "YYYY/YYYY" or a semester other than 1 or 2
+var ErrInvalidPeriod = errors.New("periode akademik tidak valid")
+
+// DateRange returns the first day of the period and the first day after it, in loc
+// Semester 1 (ganjil) runs from July to December of the first year and
+// semester 2 (genap) from January to June of the second year.
+func (p Period) DateRange(loc *time.Location) (time.Time, time.Time, error) {
+	var first, second int
+	if _, err := fmt.Sscanf(p.AcademicYear, "%d/%d", &first, &second); err != nil || second != first+1 {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+
+	switch p.Semester {
+	case 1:
+		start := time.Date(first, time.July, 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 6, 0), nil
+	case 2:
+		start := time.Date(second, time.January, 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 6, 0), nil
+	}
+	return time.Time{}, time.Time{}, ErrInvalidPeriod
+}
+
+// CategoryAverage is the average of a subject's grades in one assessment category
+type CategoryAverage struct {
+	CategoryID uint    `json:"category_id"`
+	Code       string  `json:"code"`
+	Name       string  `json:"name"`
+	Weight     float64 `json:"weight"`
+	Count      int     `json:"count"`
+	Average    float64 `json:"average"`
+}
+
+// SubjectAverage is the weighted report-card average of one subject
+type SubjectAverage struct {
+	SubjectID       uint              `json:"subject_id"`
+	Code            string            `json:"code"`
+	Name            string            `json:"name"`
+	KKM             float64           `json:"kkm"`
+	Categories      []CategoryAverage `json:"categories"`
+	WeightedAverage float64           `json:"weighted_average"`
+	Passed          bool              `json:"passed"` // WeightedAverage reached the KKM
+}
+
+// Summary is a student's report card for one period
+type Summary struct {
+	AcademicYear    string           `json:"academic_year"`
+	Semester        int              `json:"semester"`
+	Subjects        []SubjectAverage `json:"subjects"`
+	WeightedAverage float64          `json:"weighted_average"` // Mean of the subject averages
+	PassedSubjects  int              `json:"passed_subjects"`
+	FailedSubjects  int              `json:"failed_subjects"`
+}
+
+// CurrentPeriod returns the school's current academic year and semester from its settings
+func CurrentPeriod(ctx context.Context, db *gorm.DB, schoolID uint) (Period, error) {
+	var settings models.SchoolSettings
+	err := db.WithContext(ctx).
+		Where("school_id = ?", schoolID).
+		First(&settings).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			defaults := models.DefaultSchoolSettings(schoolID)
+			return Period{AcademicYear: defaults.AcademicYear, Semester: defaults.Semester}, nil
+		}
+		return Period{}, err
+	}
+	return Period{AcademicYear: settings.AcademicYear, Semester: settings.Semester}, nil
+}
+
+// ResolvePeriod fills in a partial period from the school's current one
+// An empty academic year or a zero semester is taken from the settings.
+func ResolvePeriod(ctx context.Context, db *gorm.DB, schoolID uint, period Period) (Period, error) {
+	if period.AcademicYear != "" && period.Semester != 0 {
+		return period, nil
+	}
+
+	current, err := CurrentPeriod(ctx, db, schoolID)
+	if err != nil {
+		return Period{}, err
+	}
+	if period.AcademicYear == "" {
+		period.AcademicYear = current.AcademicYear
+	}
+	if period.Semester == 0 {
+		period.Semester = current.Semester
+	}
+	return period, nil
+}
+
+// StudentSummary loads a student's grades of a period and computes their report card
+func StudentSummary(ctx context.Context, db *gorm.DB, studentID uint, period Period) (*Summary, error) {
+	var grades []models.Grade
+	err := db.WithContext(ctx).
+		Preload("Subject").
+		Preload("AssessmentCategory").
+		Where("student_id = ? AND academic_year = ? AND semester = ?", studentID, period.AcademicYear, period.Semester).
+		Where("subject_id IS NOT NULL AND assessment_category_id IS NOT NULL").
+		Find(&grades).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return Summarize(grades, period), nil
+}
+
+// Summarize computes a report card from grades with their subject and category loaded
+// Each category average is weighted by the category weight, normalized over the categories
+// the subject has grades in, so a subject without a UAS yet is not pulled down by it.
+// Grades without a subject or category are ignored.
+func Summarize(grades []models.Grade, period Period) *Summary {
+	type categoryTotal struct {
+		category *models.AssessmentCategory
+		sum      float64
+		count    int
+	}
+	type subjectTotal struct {
+		subject    *models.Subject
+		categories map[uint]*categoryTotal
+	}
+
+	subjects := make(map[uint]*subjectTotal)
+	for i := range grades {
+		grade := &grades[i]
+		if grade.Subject == nil || grade.AssessmentCategory == nil {
+			continue
+		}
+
+		st, ok := subjects[grade.Subject.ID]
+		if !ok {
+			st = &subjectTotal{subject: grade.Subject, categories: make(map[uint]*categoryTotal)}
+			subjects[grade.Subject.ID] = st
+		}
+		ct, ok := st.categories[grade.AssessmentCategory.ID]
+		if !ok {
+			ct = &categoryTotal{category: grade.AssessmentCategory}
+			st.categories[grade.AssessmentCategory.ID] = ct
+		}
+		ct.sum += grade.Score
+		ct.count++
+	}
+
+	summary := &Summary{
+		AcademicYear: period.AcademicYear,
+		Semester:     period.Semester,
+		Subjects:     make([]SubjectAverage, 0, len(subjects)),
+	}
+
+	var total float64
+	for _, st := range subjects {
+		average := SubjectAverage{
+			SubjectID:  st.subject.ID,
+			Code:       st.subject.Code,
+			Name:       st.subject.Name,
+			KKM:        st.subject.KKM,
+			Categories: make([]CategoryAverage, 0, len(st.categories)),
+		}
+
+		var weighted, weights float64
+		for _, ct := range st.categories {
+			categoryAverage := ct.sum / float64(ct.count)
+			average.Categories = append(average.Categories, CategoryAverage{
+				CategoryID: ct.category.ID,
+				Code:       ct.category.Code,
+				Name:       ct.category.Name,
+				Weight:     ct.category.Weight,
+				Count:      ct.count,
+				Average:    round(categoryAverage),
+			})
+			weighted += categoryAverage * ct.category.Weight
+			weights += ct.category.Weight
+		}
+		if weights > 0 {
+			average.WeightedAverage = round(weighted / weights)
+		}
+		average.Passed = average.WeightedAverage >= average.KKM
+
+		sort.Slice(average.Categories, func(i, j int) bool {
+			return average.Categories[i].CategoryID < average.Categories[j].CategoryID
+		})
+
+		if average.Passed {
+			summary.PassedSubjects++
+		} else {
+			summary.FailedSubjects++
+		}
+		total += average.WeightedAverage
+		summary.Subjects = append(summary.Subjects, average)
+	}
+
+	sort.Slice(summary.Subjects, func(i, j int) bool {
+		return summary.Subjects[i].Name < summary.Subjects[j].Name
+	})
+	if len(summary.Subjects) > 0 {
+		summary.WeightedAverage = round(total / float64(len(summary.Subjects)))
+	}
+
+	return summary
+}
+
+// round rounds a score to two decimals
+func round(score float64) float64 {
+	return math.Round(score*100) / 100
+}
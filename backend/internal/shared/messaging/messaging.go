@@ -0,0 +1,138 @@
+// Package messaging delivers short text messages, such as one-time codes, to users.
+// Senders are pluggable: SMTP delivers email, and the log sender prints every message
+// for development, with one-time codes redacted. A WhatsApp gateway can be added as another Sender.
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+
+	"github.com/school-management/backend/internal/config"
+)
+
+// Channel is the way a message reaches its recipient
+type Channel string
+
+const (
+	ChannelEmail    Channel = "email"
+	ChannelWhatsApp Channel = "whatsapp"
+)
+
+// IsValid checks if the channel is known
+func (c Channel) IsValid() bool {
+	return c == ChannelEmail || c == ChannelWhatsApp
+}
+
+var ErrUnsupportedChannel = errors.New("saluran pengiriman tidak didukung")
+
+// Message is a message to a single recipient
+type Message struct {
+	Channel Channel
+	To      string // Email address or phone number, depending on the channel
+	Subject string // Email only
+	Body    string
+	Secret  string // One-time code contained in Body, redacted when the message is logged
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+	Supports(channel Channel) bool
+}
+
+// NewSender creates the sender selected by the configuration
+// The log sender delivers nothing, so it is refused in production.
+func NewSender(cfg config.MessagingConfig, environment string) (Sender, error) {
+	switch cfg.Driver {
+	case "", "log":
+		if environment == "production" {
+			return nil, fmt.Errorf("the log messaging driver cannot be used in production; set MESSAGING_DRIVER=smtp")
+		}
+		return NewLogSender(), nil
+	case "smtp":
+		if cfg.SMTPHost == "" || cfg.SMTPFrom == "" {
+			return nil, fmt.Errorf("SMTP_HOST and SMTP_FROM are required for the smtp messaging driver")
+		}
+		return NewSMTPSender(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown messaging driver %q", cfg.Driver)
+	}
+}
+
+// LogSender prints messages instead of delivering them, for development
+type LogSender struct{}
+
+// NewLogSender creates a new log sender
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send logs the message with its one-time code redacted
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	body := msg.Body
+	if msg.Secret != "" {
+		body = strings.ReplaceAll(body, msg.Secret, strings.Repeat("*", len(msg.Secret)))
+	}
+	log.Printf("Messaging (log): %s to %s: %s", msg.Channel, msg.To, body)
+	return nil
+}
+
+// Supports reports that every channel is logged
+func (s *LogSender) Supports(channel Channel) bool {
+	return channel.IsValid()
+}
+
+// SMTPSender delivers email through an SMTP server
+type SMTPSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPSender creates a new SMTP sender
+// Without a username the server is used without authentication.
+func NewSMTPSender(cfg config.MessagingConfig) *SMTPSender {
+	sender := &SMTPSender{
+		addr: fmt.Sprintf("%s:%s", cfg.SMTPHost, cfg.SMTPPort),
+		from: cfg.SMTPFrom,
+	}
+	if cfg.SMTPUsername != "" {
+		sender.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return sender
+}
+
+// Send delivers an email message
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if msg.Channel != ChannelEmail {
+		return ErrUnsupportedChannel
+	}
+	// Header injection guard; addresses come from user records
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid email header")
+	}
+
+	body := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + msg.To,
+		"Subject: " + msg.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		msg.Body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, []byte(body)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// Supports reports that only email is delivered
+func (s *SMTPSender) Supports(channel Channel) bool {
+	return channel == ChannelEmail
+}
@@ -0,0 +1,112 @@
+// Package outbox implements the transactional outbox used to publish domain events.
+// Domain writes append an OutboxEvent row in the same database transaction as the
+// change itself; the Relay later publishes pending rows to the notification queue
+// and the realtime hub, so an event is never lost between commit and publish.
+package outbox
+
+import (
+	"encoding/json"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// Event types written to the outbox
+const (
+	EventAttendanceCheckIn     = "attendance.check_in"
+	EventAttendanceCheckOut    = "attendance.check_out"
+	EventAttendanceRecorded    = "attendance.recorded"
+	EventViolationCreated      = "violation.created"
+	EventViolationEscalated    = "violation.escalated"
+	EventAchievementCreated    = "achievement.created"
+	EventPermitCreated         = "permit.created"
+	EventCounselingFollowUpDue = "counseling.follow_up_due"
+	EventGradeCreated          = "grade.created"
+	EventHomeroomNoteCreated   = "homeroom_note.created"
+	EventDeviceOffline         = "device.offline"
+	EventLeaveRequested        = "leave_request.submitted"
+	EventLeaveReviewed         = "leave_request.reviewed"
+)
+
+// Payload is the JSON body stored in OutboxEvent.Payload
+type Payload struct {
+	SchoolID  uint `json:"school_id"`
+	StudentID uint `json:"student_id,omitempty"`
+
+	// AttendanceType is set for attendance events that must reach the realtime hub
+	// ("check_in" or "check_out")
+	AttendanceType string `json:"attendance_type,omitempty"`
+
+	// Notification is set when the event must be delivered as a push notification
+	Notification *NotificationPayload `json:"notification,omitempty"`
+}
+
+// NotificationPayload describes the notification produced by an event
+type NotificationPayload struct {
+	Type    models.NotificationType `json:"type"`
+	Title   string                  `json:"title"`
+	Message string                  `json:"message"`
+	Data    map[string]interface{}  `json:"data,omitempty"`
+
+	// NotifyParents sends the notification to every parent linked to Payload.StudentID
+	NotifyParents bool `json:"notify_parents,omitempty"`
+	// UserIDs are additional recipients
+	UserIDs []uint `json:"user_ids,omitempty"`
+}
+
+// Event is a domain event that has not been written yet.
+// The aggregate ID is filled in by Append once the domain row has been inserted.
+type Event struct {
+	Type    string
+	Payload Payload
+}
+
+// NewEvent creates a new event
+func NewEvent(eventType string, payload Payload) *Event {
+	return &Event{Type: eventType, Payload: payload}
+}
+
+// ParentNotification builds a notification payload addressed to the student's parents
+func ParentNotification(notifType models.NotificationType, title, message string, data map[string]interface{}) *NotificationPayload {
+	return &NotificationPayload{
+		Type:          notifType,
+		Title:         title,
+		Message:       message,
+		Data:          data,
+		NotifyParents: true,
+	}
+}
+
+// Append writes the event to the outbox using the given transaction.
+// A nil event is a no-op so callers can append optional events unconditionally.
+func Append(tx *gorm.DB, aggregateID uint, event *Event) error {
+	if event == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+
+	row := &models.OutboxEvent{
+		AggregateID: aggregateID,
+		EventType:   event.Type,
+		Payload:     string(payload),
+		Status:      models.OutboxEventStatusPending,
+	}
+	if err := row.Validate(); err != nil {
+		return err
+	}
+
+	return tx.Create(row).Error
+}
+
+// DecodePayload decodes the payload of a stored outbox event
+func DecodePayload(event *models.OutboxEvent) (*Payload, error) {
+	var payload Payload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
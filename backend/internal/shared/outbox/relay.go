@@ -0,0 +1,284 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/school-management/backend/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Publisher delivers a decoded outbox event to a downstream channel
+// (notification queue, realtime hub, ...)
+type Publisher interface {
+	// Name identifies the publisher in OutboxEvent.DeliveredTo, so it must not change between releases
+	Name() string
+	Publish(ctx context.Context, event *models.OutboxEvent, payload *Payload) error
+}
+
+// RelayConfig holds configuration for the outbox relay
+type RelayConfig struct {
+	PollInterval  time.Duration // Delay between polls when the outbox is empty
+	BatchSize     int           // Maximum number of events claimed per poll
+	LeaseDuration time.Duration // How long a claimed batch is reserved for the relay publishing it
+	MaxRetries    int           // Maximum number of publish attempts
+	InitialDelay  time.Duration // Delay before the first retry
+	MaxDelay      time.Duration // Maximum delay between retries
+	BackoffFactor float64       // Multiplier for exponential backoff
+}
+
+// DefaultRelayConfig returns the default relay configuration
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		PollInterval:  500 * time.Millisecond,
+		BatchSize:     50,
+		LeaseDuration: 2 * time.Minute,
+		MaxRetries:    5,
+		InitialDelay:  1 * time.Second,
+		MaxDelay:      5 * time.Minute,
+		BackoffFactor: 2.0,
+	}
+}
+
+// Relay publishes pending outbox events with retries.
+// Delivery is at-least-once per publisher: an event whose publish fails is only retried on
+// the publishers that have not delivered it yet.
+type Relay struct {
+	db         *gorm.DB
+	publishers []Publisher
+	config     RelayConfig
+	stopCh     chan struct{}
+	wg         sync.WaitGroup
+	running    bool
+	mu         sync.Mutex
+}
+
+// NewRelay creates a new outbox relay
+func NewRelay(db *gorm.DB, publishers ...Publisher) *Relay {
+	return NewRelayWithConfig(db, DefaultRelayConfig(), publishers...)
+}
+
+// NewRelayWithConfig creates a new outbox relay with a custom configuration
+func NewRelayWithConfig(db *gorm.DB, config RelayConfig, publishers ...Publisher) *Relay {
+	return &Relay{
+		db:         db,
+		publishers: publishers,
+		config:     config,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start starts the relay
+func (r *Relay) Start() {
+	r.mu.Lock()
+	if r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = true
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go r.processLoop()
+
+	log.Println("Outbox relay started")
+}
+
+// Stop stops the relay gracefully
+func (r *Relay) Stop() {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = false
+	r.mu.Unlock()
+
+	close(r.stopCh)
+	r.wg.Wait()
+
+	log.Println("Outbox relay stopped")
+}
+
+// processLoop polls the outbox until the relay is stopped
+func (r *Relay) processLoop() {
+	defer r.wg.Done()
+
+	for {
+		processed, err := r.processBatch(context.Background())
+		if err != nil {
+			log.Printf("Error processing outbox batch: %v", err)
+		}
+
+		// Keep draining while there is work, otherwise wait for the next poll
+		if processed >= r.config.BatchSize && err == nil {
+			select {
+			case <-r.stopCh:
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-r.stopCh:
+			return
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// processBatch claims a batch of due events and publishes them.
+// Publishing happens outside the claiming transaction, so no row lock is held during network I/O;
+// the lease keeps other server instances from claiming the batch until it expires.
+func (r *Relay) processBatch(ctx context.Context) (int, error) {
+	events, leaseUntil, err := r.claimBatch(ctx)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	publishCtx, cancel := context.WithDeadline(ctx, leaseUntil)
+	defer cancel()
+
+	processed := 0
+	for i := range events {
+		// The rest of the batch is claimed again once the lease expires
+		if publishCtx.Err() != nil {
+			log.Printf("Outbox lease expired with %d events left in the batch", len(events)-i)
+			break
+		}
+
+		r.publishEvent(publishCtx, &events[i])
+		if err := r.saveResult(ctx, &events[i], leaseUntil); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// claimBatch leases a batch of due events.
+// Rows are locked with SKIP LOCKED only while the lease is written, so several server
+// instances can run a relay without claiming the same events.
+func (r *Relay) claimBatch(ctx context.Context) ([]models.OutboxEvent, time.Time, error) {
+	now := time.Now()
+	// Stored with the microsecond precision of the database, so saveResult can match it
+	leaseUntil := now.Add(r.config.LeaseDuration).Truncate(time.Microsecond)
+
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.OutboxEventStatusPending).
+			Where("next_retry_at IS NULL OR next_retry_at <= ?", now).
+			Where("locked_until IS NULL OR locked_until <= ?", now).
+			Order("id ASC").
+			Limit(r.config.BatchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+		}
+		return tx.Model(&models.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("locked_until", leaseUntil).Error
+	})
+	if err != nil {
+		return nil, leaseUntil, err
+	}
+
+	return events, leaseUntil, nil
+}
+
+// saveResult stores the outcome of a publish and releases the lease.
+// Nothing is saved if the lease expired and another relay claimed the event in the meantime.
+func (r *Relay) saveResult(ctx context.Context, event *models.OutboxEvent, leaseUntil time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id = ? AND locked_until = ?", event.ID, leaseUntil).
+		Updates(map[string]interface{}{
+			"status":        event.Status,
+			"retry_count":   event.RetryCount,
+			"last_error":    event.LastError,
+			"next_retry_at": event.NextRetryAt,
+			"published_at":  event.PublishedAt,
+			"delivered_to":  event.DeliveredTo,
+			"locked_until":  nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("Outbox event %d (%s) was claimed again before its result was saved", event.ID, event.EventType)
+	}
+	return nil
+}
+
+// publishEvent publishes a single event and updates its status in memory
+func (r *Relay) publishEvent(ctx context.Context, event *models.OutboxEvent) {
+	err := r.publish(ctx, event)
+	if err == nil {
+		event.MarkAsPublished()
+		event.LastError = ""
+		event.NextRetryAt = nil
+		return
+	}
+
+	event.MarkAsFailed()
+	event.LastError = err.Error()
+
+	if event.CanRetry(r.config.MaxRetries) {
+		delay := r.calculateDelay(event.RetryCount)
+		event.ScheduleRetry(delay)
+		log.Printf("Outbox event %d (%s) failed, retry %d/%d in %v: %v",
+			event.ID, event.EventType, event.RetryCount, r.config.MaxRetries, delay, err)
+		return
+	}
+
+	log.Printf("Outbox event %d (%s) failed permanently after %d attempts: %v",
+		event.ID, event.EventType, event.RetryCount, err)
+}
+
+// publish decodes the event and hands it to every publisher that has not delivered it yet.
+// A failing publisher does not keep the others from delivering the event.
+func (r *Relay) publish(ctx context.Context, event *models.OutboxEvent) error {
+	payload, err := DecodePayload(event)
+	if err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+
+	var errs []error
+	for _, publisher := range r.publishers {
+		if event.IsDeliveredTo(publisher.Name()) {
+			continue
+		}
+		if err := publisher.Publish(ctx, event, payload); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", publisher.Name(), err))
+			continue
+		}
+		event.MarkDeliveredTo(publisher.Name())
+	}
+	return errors.Join(errs...)
+}
+
+// calculateDelay calculates the delay for exponential backoff
+func (r *Relay) calculateDelay(retryCount int) time.Duration {
+	delay := float64(r.config.InitialDelay) * math.Pow(r.config.BackoffFactor, float64(retryCount-1))
+	if delay > float64(r.config.MaxDelay) {
+		delay = float64(r.config.MaxDelay)
+	}
+	return time.Duration(delay)
+}
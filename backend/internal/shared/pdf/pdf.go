@@ -0,0 +1,293 @@
+// Package pdf writes simple printable PDF documents: text in the standard Helvetica fonts,
+// lines and rectangles. It is enough for tabular reports such as report cards and does not
+// embed fonts, so text is limited to the Windows-1252 (WinAnsi) character set.
+//
+// Coordinates are in points (1/72 inch) from the top-left corner of the page; text is
+// positioned by its baseline.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// PageSize is the size of a page in points
+type PageSize struct {
+	Width  float64
+	Height float64
+}
+
+var (
+	A4 = PageSize{Width: 595.28, Height: 841.89}
+	F4 = PageSize{Width: 609.45, Height: 935.43} // Folio, 215 x 330 mm
+)
+
+// Font selects one of the standard fonts
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+// Align is the horizontal alignment of text in a box
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Document is a PDF document under construction
+type Document struct {
+	size  PageSize
+	title string
+	pages []*bytes.Buffer
+}
+
+// New creates an empty document with the given page size
+func New(size PageSize) *Document {
+	return &Document{size: size}
+}
+
+// Size returns the page size
+func (d *Document) Size() PageSize {
+	return d.size
+}
+
+// SetTitle sets the document title shown by PDF viewers
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+// AddPage starts a new page; drawing always goes to the last page
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// PageCount returns the number of pages
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Text draws text with its baseline starting at (x, y)
+func (d *Document) Text(x, y float64, font Font, size float64, text string) {
+	page := d.page()
+	fmt.Fprintf(page, "BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font+1, size, x, d.size.Height-y, escape(encode(text)))
+}
+
+// TextAligned draws text aligned in a box of the given width starting at x
+func (d *Document) TextAligned(x, y, width float64, font Font, size float64, align Align, text string) {
+	switch align {
+	case AlignCenter:
+		x += (width - TextWidth(font, size, text)) / 2
+	case AlignRight:
+		x += width - TextWidth(font, size, text)
+	}
+	d.Text(x, y, font, size, text)
+}
+
+// Line draws a straight line
+func (d *Document) Line(x1, y1, x2, y2, lineWidth float64) {
+	page := d.page()
+	fmt.Fprintf(page, "%.2f w %.2f %.2f m %.2f %.2f l S\n", lineWidth, x1, d.size.Height-y1, x2, d.size.Height-y2)
+}
+
+// Rect draws the outline of a rectangle whose top-left corner is (x, y)
+func (d *Document) Rect(x, y, width, height, lineWidth float64) {
+	page := d.page()
+	fmt.Fprintf(page, "%.2f w %.2f %.2f %.2f %.2f re S\n", lineWidth, x, d.size.Height-y-height, width, height)
+}
+
+// FillRect fills a rectangle with a gray level between 0 (black) and 1 (white)
+func (d *Document) FillRect(x, y, width, height, gray float64) {
+	page := d.page()
+	fmt.Fprintf(page, "%.2f g %.2f %.2f %.2f %.2f re f 0 g\n", gray, x, d.size.Height-y-height, width, height)
+}
+
+// page returns the current page, starting the first one if needed
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo renders the document to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	out := &countingWriter{w: w}
+	var offsets []int64
+	object := func(body string) {
+		offsets = append(offsets, out.n)
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1-5 are fixed; each page then adds a page and a content object
+	pageIDs := make([]string, len(d.pages))
+	for i := range d.pages {
+		pageIDs[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+
+	io.WriteString(out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %.2f %.2f] >>",
+		strings.Join(pageIDs, " "), len(d.pages), d.size.Width, d.size.Height))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (School Management) /CreationDate (D:%s) >>",
+		escape(encode(d.title)), time.Now().UTC().Format("20060102150405Z")))
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", 7+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.n
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.n, out.err
+}
+
+// TextWidth returns the width of text in points
+func TextWidth(font Font, size float64, text string) float64 {
+	widths := helveticaWidths
+	if font == Bold {
+		widths = helveticaBoldWidths
+	}
+
+	var total int
+	for _, c := range encode(text) {
+		if c >= 32 && c <= 126 {
+			total += widths[c-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// WrapText splits text into lines that fit in width, breaking at spaces
+// Existing line breaks are kept; a single word wider than width gets its own line.
+func WrapText(font Font, size float64, text string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := words[0]
+		for _, word := range words[1:] {
+			if TextWidth(font, size, line+" "+word) > width {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line += " " + word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// encode converts text to WinAnsi bytes, replacing characters it cannot represent
+func encode(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 32 && r <= 126, r >= 160 && r <= 255:
+			out = append(out, byte(r))
+		case r == '‘':
+			out = append(out, 0x91)
+		case r == '’':
+			out = append(out, 0x92)
+		case r == '“':
+			out = append(out, 0x93)
+		case r == '”':
+			out = append(out, 0x94)
+		case r == '•':
+			out = append(out, 0x95)
+		case r == '–':
+			out = append(out, 0x96)
+		case r == '—':
+			out = append(out, 0x97)
+		case r < 32:
+			// Control characters are dropped
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// escape escapes the delimiters of a PDF literal string
+func escape(text []byte) string {
+	var b strings.Builder
+	for _, c := range text {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// countingWriter tracks the byte offset needed for the cross-reference table
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+// Glyph widths of characters 32-126 in 1/1000 em, from the standard Adobe font metrics
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,175 @@
+// Package ratelimit throttles repeated failures, such as wrong passwords or invalid API keys.
+// Failures are counted in Redis per key (a username, an IP address), so every instance
+// of the server sees the same counters. Once a key fails too often it has to wait before
+// it may try again, first briefly and then for the full lockout.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/school-management/backend/internal/shared/redis"
+)
+
+// ErrBlocked is matched by every BlockedError
+var ErrBlocked = errors.New("terlalu banyak percobaan gagal, coba lagi nanti")
+
+// BlockedError is returned for a key that has to wait before trying again
+type BlockedError struct {
+	RetryAfter time.Duration
+	Locked     bool // Locked out after too many failures, rather than delayed
+}
+
+// Error implements the error interface
+func (e *BlockedError) Error() string {
+	return ErrBlocked.Error()
+}
+
+// Is makes errors.Is(err, ErrBlocked) match
+func (e *BlockedError) Is(target error) bool {
+	return target == ErrBlocked
+}
+
+// RetryAfterSeconds returns the wait in whole seconds, rounded up
+func (e *BlockedError) RetryAfterSeconds() int {
+	seconds := int((e.RetryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// Policy describes how failures of a key are throttled
+type Policy struct {
+	MaxFailures int           // Failures within the window that lock the key
+	Window      time.Duration // How long failures are counted
+	Lockout     time.Duration // How long a lock lasts
+	DelayAfter  int           // Failures after which every retry has to wait; 0 disables delays
+	BaseDelay   time.Duration // Wait after the first delayed failure, doubled with every further failure
+}
+
+// Limiter counts failures per key and blocks keys that fail too often
+type Limiter struct {
+	redis  *redis.Client
+	name   string
+	policy Policy
+}
+
+// NewLimiter creates a new limiter; the name separates its keys from other limiters
+func NewLimiter(redisClient *redis.Client, name string, policy Policy) *Limiter {
+	return &Limiter{
+		redis:  redisClient,
+		name:   name,
+		policy: policy,
+	}
+}
+
+// Check returns a BlockedError if the key has to wait before trying again
+func (l *Limiter) Check(ctx context.Context, key string) error {
+	blocked, err := l.Status(ctx, key)
+	if err != nil {
+		return err
+	}
+	if blocked != nil {
+		return blocked
+	}
+	return nil
+}
+
+// Status returns how long the key is blocked for, or nil if it may try now
+func (l *Limiter) Status(ctx context.Context, key string) (*BlockedError, error) {
+	ttl, err := l.redis.TTL(ctx, l.blockedKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		return nil, nil
+	}
+
+	value, err := l.redis.Get(ctx, l.blockedKey(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockedError{RetryAfter: ttl, Locked: value == "true"}, nil
+}
+
+// Fail records a failure of the key and returns the block it caused, if any
+func (l *Limiter) Fail(ctx context.Context, key string) (*BlockedError, error) {
+	failuresKey := l.failuresKey(key)
+	failures, err := l.redis.Increment(ctx, failuresKey)
+	if err != nil {
+		return nil, err
+	}
+	if failures == 1 {
+		if err := l.redis.Expire(ctx, failuresKey, l.policy.Window); err != nil {
+			return nil, err
+		}
+	}
+
+	blocked := l.policy.block(failures)
+	if blocked == nil {
+		return nil, nil
+	}
+
+	if err := l.redis.Set(ctx, l.blockedKey(key), blocked.Locked, blocked.RetryAfter); err != nil {
+		return nil, err
+	}
+	if blocked.Locked {
+		// The key starts over once the lock ends
+		if err := l.redis.Delete(ctx, failuresKey); err != nil {
+			return nil, err
+		}
+		log.Printf("Rate limit: %s %q locked for %s after %d failures", l.name, key, l.policy.Lockout, failures)
+	}
+
+	return blocked, nil
+}
+
+// block returns the block caused by the given number of failures within the window, if any
+func (p Policy) block(failures int64) *BlockedError {
+	if p.MaxFailures > 0 && failures >= int64(p.MaxFailures) {
+		return &BlockedError{RetryAfter: p.Lockout, Locked: true}
+	}
+
+	if p.DelayAfter > 0 && failures > int64(p.DelayAfter) {
+		delay := p.BaseDelay << uint(failures-int64(p.DelayAfter)-1)
+		if delay <= 0 || delay > p.Lockout {
+			delay = p.Lockout
+		}
+		return &BlockedError{RetryAfter: delay}
+	}
+
+	return nil
+}
+
+// Reset forgets the failures of the key, e.g. after a successful login
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.redis.Delete(ctx, l.failuresKey(key))
+}
+
+// Unlock lifts the block of the key and forgets its failures.
+// It returns whether the key was blocked.
+func (l *Limiter) Unlock(ctx context.Context, key string) (bool, error) {
+	blocked, err := l.redis.Exists(ctx, l.blockedKey(key))
+	if err != nil {
+		return false, err
+	}
+
+	if err := l.redis.Delete(ctx, l.blockedKey(key), l.failuresKey(key)); err != nil {
+		return false, err
+	}
+
+	return blocked, nil
+}
+
+func (l *Limiter) failuresKey(key string) string {
+	return fmt.Sprintf("ratelimit:%s:failures:%s", l.name, key)
+}
+
+func (l *Limiter) blockedKey(key string) string {
+	return fmt.Sprintf("ratelimit:%s:blocked:%s", l.name, key)
+}
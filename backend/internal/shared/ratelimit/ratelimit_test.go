@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPolicyBlock(t *testing.T) {
+	policy := Policy{
+		MaxFailures: 10,
+		Window:      15 * time.Minute,
+		Lockout:     15 * time.Minute,
+		DelayAfter:  3,
+		BaseDelay:   time.Second,
+	}
+	longDelays := policy
+	longDelays.MaxFailures = 0
+	longDelays.BaseDelay = time.Minute
+	noDelays := policy
+	noDelays.DelayAfter = 0
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		failures int64
+		want     *BlockedError
+	}{
+		{name: "first failure", policy: policy, failures: 1, want: nil},
+		{name: "last failure without delay", policy: policy, failures: 3, want: nil},
+		{name: "first delayed failure", policy: policy, failures: 4, want: &BlockedError{RetryAfter: time.Second}},
+		{name: "delay doubles", policy: policy, failures: 5, want: &BlockedError{RetryAfter: 2 * time.Second}},
+		{name: "delay keeps doubling", policy: policy, failures: 9, want: &BlockedError{RetryAfter: 32 * time.Second}},
+		{name: "locked at max failures", policy: policy, failures: 10, want: &BlockedError{RetryAfter: 15 * time.Minute, Locked: true}},
+		{name: "locked past max failures", policy: policy, failures: 11, want: &BlockedError{RetryAfter: 15 * time.Minute, Locked: true}},
+		{name: "delay capped at lockout", policy: longDelays, failures: 8, want: &BlockedError{RetryAfter: 15 * time.Minute}},
+		{name: "overflowing delay capped at lockout", policy: longDelays, failures: 200, want: &BlockedError{RetryAfter: 15 * time.Minute}},
+		{name: "delays disabled", policy: noDelays, failures: 9, want: nil},
+		{name: "delays disabled still lock", policy: noDelays, failures: 10, want: &BlockedError{RetryAfter: 15 * time.Minute, Locked: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.block(tt.failures)
+			if got == nil || tt.want == nil {
+				if got != tt.want {
+					t.Fatalf("block(%d) = %+v, want %+v", tt.failures, got, tt.want)
+				}
+				return
+			}
+			if *got != *tt.want {
+				t.Errorf("block(%d) = %+v, want %+v", tt.failures, *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestBlockedError(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       int
+	}{
+		{retryAfter: 0, want: 1},
+		{retryAfter: 200 * time.Millisecond, want: 1},
+		{retryAfter: time.Second, want: 1},
+		{retryAfter: 1500 * time.Millisecond, want: 2},
+		{retryAfter: 15 * time.Minute, want: 900},
+	}
+
+	for _, tt := range tests {
+		err := &BlockedError{RetryAfter: tt.retryAfter}
+		if got := err.RetryAfterSeconds(); got != tt.want {
+			t.Errorf("RetryAfterSeconds() for %s = %d, want %d", tt.retryAfter, got, tt.want)
+		}
+		if !errors.Is(err, ErrBlocked) {
+			t.Errorf("errors.Is(%v, ErrBlocked) = false", err)
+		}
+	}
+}
@@ -160,6 +160,11 @@ func (c *Client) Expire(ctx context.Context, key string, expiration time.Duratio
 	return c.rdb.Expire(ctx, key, expiration).Err()
 }
 
+// TTL returns the remaining time to live of a key, or a negative duration if it does not exist or has no expiration
+func (c *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.rdb.PTTL(ctx, key).Result()
+}
+
 // Pub/Sub Operations
 
 // Publish publishes a message to a channel
@@ -176,3 +181,50 @@ func (c *Client) Publish(ctx context.Context, channel string, message interface{
 func (c *Client) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
 	return c.rdb.Subscribe(ctx, channels...)
 }
+
+// PSubscribe subscribes to channels matching the given patterns
+func (c *Client) PSubscribe(ctx context.Context, patterns ...string) *redis.PubSub {
+	return c.rdb.PSubscribe(ctx, patterns...)
+}
+
+// Hash Operations
+
+// HSet sets fields of a hash
+func (c *Client) HSet(ctx context.Context, key string, values map[string]interface{}) error {
+	return c.rdb.HSet(ctx, key, values).Err()
+}
+
+// HGet retrieves a field of a hash
+func (c *Client) HGet(ctx context.Context, key, field string) (string, error) {
+	result, err := c.rdb.HGet(ctx, key, field).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil // Field not found
+		}
+		return "", fmt.Errorf("failed to get hash field: %w", err)
+	}
+
+	return result, nil
+}
+
+// HGetAll retrieves all fields of a hash
+func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return c.rdb.HGetAll(ctx, key).Result()
+}
+
+// Sorted Set Operations
+
+// ZAdd adds a member to a sorted set or updates its score
+func (c *Client) ZAdd(ctx context.Context, key string, score float64, member string) error {
+	return c.rdb.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+// ZRangeByScore returns the members of a sorted set with a score between min and max
+func (c *Client) ZRangeByScore(ctx context.Context, key, min, max string) ([]string, error) {
+	return c.rdb.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+}
+
+// ZRemRangeByScore removes the members of a sorted set with a score between min and max
+func (c *Client) ZRemRangeByScore(ctx context.Context, key, min, max string) error {
+	return c.rdb.ZRemRangeByScore(ctx, key, min, max).Err()
+}
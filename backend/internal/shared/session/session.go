@@ -0,0 +1,31 @@
+// Package session ends the server-side sessions behind refresh tokens.
+// The auth module creates and rotates sessions; modules that reset passwords or deactivate
+// users revoke them here, in the same transaction as the change, so the user's devices
+// cannot refresh their tokens afterwards.
+package session
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/school-management/backend/internal/domain/models"
+)
+
+// RevokeUser revokes the active sessions of a user except the ones to keep
+// It returns the number of sessions revoked.
+func RevokeUser(ctx context.Context, db *gorm.DB, userID uint, reason models.SessionRevokeReason, keep ...uint) (int64, error) {
+	query := db.WithContext(ctx).
+		Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID)
+	if len(keep) > 0 {
+		query = query.Where("id NOT IN ?", keep)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"revoked_at":     time.Now(),
+		"revoked_reason": reason,
+	})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,119 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors, "12345678901234567890", in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to the last six of the eight digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code() at %d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code() at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+	codeAt := func(step int64) string {
+		code, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", secret: rfcSecret, code: codeAt(current), wantStep: current, wantOK: true},
+		{name: "previous step", secret: rfcSecret, code: codeAt(current - 1), wantStep: current - 1, wantOK: true},
+		{name: "next step", secret: rfcSecret, code: codeAt(current + 1), wantStep: current + 1, wantOK: true},
+		{name: "two steps ago", secret: rfcSecret, code: codeAt(current - 2), wantOK: false},
+		{name: "two steps ahead", secret: rfcSecret, code: codeAt(current + 2), wantOK: false},
+		{name: "typed with spaces", secret: rfcSecret, code: " " + codeAt(current)[:3] + " " + codeAt(current)[3:] + " ", wantStep: current, wantOK: true},
+		{name: "lower-case secret", secret: strings.ToLower(rfcSecret), code: codeAt(current), wantStep: current, wantOK: true},
+		{name: "wrong code", secret: rfcSecret, code: "000000", wantOK: false},
+		{name: "too short", secret: rfcSecret, code: codeAt(current)[:5], wantOK: false},
+		{name: "too long", secret: rfcSecret, code: codeAt(current) + "0", wantOK: false},
+		{name: "empty", secret: rfcSecret, code: "", wantOK: false},
+		{name: "invalid secret", secret: "not base32!", code: codeAt(current), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(tt.secret, tt.code, now)
+			if ok != tt.wantOK {
+				t.Fatalf("Validate(%q) ok = %v, want %v", tt.code, ok, tt.wantOK)
+			}
+			if ok && step != tt.wantStep {
+				t.Errorf("Validate(%q) step = %d, want %d", tt.code, step, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestValidateStepAcrossWindow(t *testing.T) {
+	// A code keeps the step it was generated for while the window moves on, so a
+	// caller that stores the last used step refuses it at every later moment
+	issued := time.Unix(1234567890, 0)
+	code, err := Code(rfcSecret, Step(issued))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, offset := range []time.Duration{-Period, 0, Period} {
+		step, ok := Validate(rfcSecret, code, issued.Add(offset))
+		if !ok || step != Step(issued) {
+			t.Errorf("Validate() %s from issue = (%d, %v), want (%d, true)", offset, step, ok, Step(issued))
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Error("GenerateSecret() returned the same secret twice")
+	}
+	key, err := decodeSecret(first)
+	if err != nil {
+		t.Fatalf("decodeSecret(%q): %v", first, err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("secret is %d bytes, want %d", len(key), secretSize)
+	}
+}
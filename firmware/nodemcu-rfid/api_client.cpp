@@ -3,8 +3,10 @@
  */
 
 #include "api_client.h"
+#include <time.h>
+#include <Crypto.h>
 
-APIClient::APIClient() : serverUrl(""), apiKey(""), deviceId(0) {
+APIClient::APIClient() : serverUrl(""), apiKey(""), signingSecret(""), timeManager(nullptr), deviceId(0) {
 }
 
 void APIClient::begin(const char* serverUrl, const char* apiKey) {
@@ -21,6 +23,17 @@ void APIClient::begin(const char* serverUrl, const char* apiKey) {
   Serial.println(this->serverUrl);
 }
 
+void APIClient::enableSigning(const char* signingSecret, TimeManager* timeManager) {
+  this->signingSecret = String(signingSecret);
+  this->timeManager = timeManager;
+
+  if (this->signingSecret.length() > 0) {
+    Serial.println(F("[OK] APIClient: Request signing enabled"));
+  } else {
+    Serial.println(F("[WARN] APIClient: No signing secret, sending API key in requests"));
+  }
+}
+
 bool APIClient::isConfigured() {
   return serverUrl.length() > 0 && apiKey.length() > 0;
 }
@@ -32,6 +45,7 @@ ValidationResponse APIClient::validateAPIKey() {
   result.deviceId = 0;
   result.schoolId = 0;
   result.message = "";
+  result.networkError = false;
 
   if (!isConfigured()) {
     result.message = F("API not configured");
@@ -40,7 +54,7 @@ ValidationResponse APIClient::validateAPIKey() {
 
   // Build JSON payload
   StaticJsonDocument<128> doc;
-  doc["api_key"] = apiKey;
+  addAPIKey(doc);
   
   String payload;
   serializeJson(doc, payload);
@@ -81,6 +95,7 @@ ValidationResponse APIClient::validateAPIKey() {
     }
   } else {
     result.message = "HTTP error: " + String(httpCode);
+    result.networkError = httpCode <= 0 || httpCode >= 500;
   }
 
   return result;
@@ -93,6 +108,7 @@ AttendanceResponse APIClient::recordAttendance(const String& rfidCode) {
   result.status = "";
   result.message = "";
   result.errorCode = "";
+  result.networkError = false;
 
   if (!isConfigured()) {
     result.message = F("API not configured");
@@ -101,7 +117,7 @@ AttendanceResponse APIClient::recordAttendance(const String& rfidCode) {
 
   // Build JSON payload - AC2.3
   StaticJsonDocument<128> doc;
-  doc["api_key"] = apiKey;
+  addAPIKey(doc);
   doc["rfid_code"] = rfidCode;
   
   String payload;
@@ -160,6 +176,95 @@ AttendanceResponse APIClient::recordAttendance(const String& rfidCode) {
     }
   } else {
     result.message = "HTTP error: " + String(httpCode);
+    result.networkError = httpCode <= 0 || httpCode >= 500;
+  }
+
+  return result;
+}
+
+BatchSyncResponse APIClient::syncAttendanceBatch(const QueuedTap* taps, int count) {
+  BatchSyncResponse result;
+  result.success = false;
+  result.recorded = 0;
+  result.rejected = 0;
+  result.failed = 0;
+  result.doneCount = 0;
+  result.message = "";
+
+  if (!isConfigured() || count <= 0) {
+    result.message = F("API not configured");
+    return result;
+  }
+  if (count > OFFLINE_SYNC_BATCH_SIZE) {
+    count = OFFLINE_SYNC_BATCH_SIZE;
+  }
+
+  // Build JSON payload - timestamps in RFC 3339 (UTC)
+  DynamicJsonDocument doc(256 + count * 160);
+  addAPIKey(doc);
+  JsonArray tapArray = doc.createNestedArray("taps");
+  for (int i = 0; i < count; i++) {
+    char timestamp[21];
+    time_t rawTime = taps[i].timestamp;
+    struct tm* timeInfo = gmtime(&rawTime);
+    snprintf(timestamp, sizeof(timestamp), "%04d-%02d-%02dT%02d:%02d:%02dZ",
+             timeInfo->tm_year + 1900, timeInfo->tm_mon + 1, timeInfo->tm_mday,
+             timeInfo->tm_hour, timeInfo->tm_min, timeInfo->tm_sec);
+
+    JsonObject tap = tapArray.createNestedObject();
+    tap["idempotency_key"] = taps[i].id;
+    tap["rfid_code"] = taps[i].rfidCode;
+    tap["timestamp"] = timestamp;
+  }
+
+  String payload;
+  serializeJson(doc, payload);
+  doc.clear();
+
+  // Send request (using /public prefix to bypass auth)
+  String response;
+  int httpCode = sendPostRequest("/api/v1/public/attendance/rfid/batch", payload, response);
+
+  Serial.print(F("[API] Batch sync response ("));
+  Serial.print(httpCode);
+  Serial.println(F(")"));
+
+  if (httpCode != 200) {
+    result.message = "HTTP error: " + String(httpCode);
+    return result;
+  }
+
+  // Only the fields needed to settle the queue are kept, results carry student names and messages
+  StaticJsonDocument<128> filter;
+  filter["success"] = true;
+  filter["data"]["recorded"] = true;
+  filter["data"]["rejected"] = true;
+  filter["data"]["failed"] = true;
+  filter["data"]["results"][0]["idempotency_key"] = true;
+  filter["data"]["results"][0]["retryable"] = true;
+
+  DynamicJsonDocument responseDoc(256 + count * 96);
+  DeserializationError error = deserializeJson(responseDoc, response, DeserializationOption::Filter(filter));
+  if (error) {
+    result.message = F("JSON parse error");
+    return result;
+  }
+
+  result.success = responseDoc["success"] | false;
+  JsonObject data = responseDoc["data"];
+  result.recorded = data["recorded"] | 0;
+  result.rejected = data["rejected"] | 0;
+  result.failed = data["failed"] | 0;
+
+  // A tap may be dropped from the queue unless the backend asks to resend it
+  for (JsonObject tapResult : data["results"].as<JsonArray>()) {
+    if (result.doneCount >= OFFLINE_SYNC_BATCH_SIZE) break;
+    if (tapResult["retryable"] | true) continue;
+
+    const char* id = tapResult["idempotency_key"] | "";
+    if (strlen(id) > 0) {
+      result.doneIds[result.doneCount++] = String(id);
+    }
   }
 
   return result;
@@ -179,7 +284,7 @@ PairingResponse APIClient::processPairing(const String& rfidCode) {
 
   // Build JSON payload - AC3.4
   StaticJsonDocument<128> doc;
-  doc["api_key"] = apiKey;
+  addAPIKey(doc);
   doc["rfid_code"] = rfidCode;
   
   String payload;
@@ -274,6 +379,51 @@ String APIClient::buildURL(const char* endpoint) {
   return serverUrl + String(endpoint);
 }
 
+bool APIClient::canSign() {
+  return signingSecret.length() > 0 && timeManager != nullptr && timeManager->isSynced();
+}
+
+void APIClient::addAPIKey(JsonDocument& doc) {
+  // Signed requests identify the device by key prefix and signature only
+  if (!canSign()) {
+    doc["api_key"] = apiKey;
+  }
+}
+
+void APIClient::addSignatureHeaders(const char* method, const char* endpoint, const String& body) {
+  if (!canSign()) return;
+
+  String timestamp = String(timeManager->getUTCEpochTime());
+  char nonce[17];  // Backend requires at least 8 characters
+  snprintf(nonce, sizeof(nonce), "%08lx%08lx", (unsigned long)ESP.random(), (unsigned long)ESP.random());
+
+  uint8_t bodyHash[32];
+  experimental::crypto::SHA256::hash(body.c_str(), body.length(), bodyHash);
+
+  String canonical = String(method) + "\n" + String(endpoint) + "\n" + timestamp + "\n" + String(nonce) + "\n" + toHex(bodyHash, sizeof(bodyHash));
+
+  uint8_t signature[32];
+  experimental::crypto::SHA256::hmac(canonical.c_str(), canonical.length(),
+                                     signingSecret.c_str(), signingSecret.length(),
+                                     signature, sizeof(signature));
+
+  http.addHeader("X-Device-Key", apiKey.substring(0, API_KEY_PREFIX_LENGTH));
+  http.addHeader("X-Timestamp", timestamp);
+  http.addHeader("X-Nonce", nonce);
+  http.addHeader("X-Signature", toHex(signature, sizeof(signature)));
+}
+
+String APIClient::toHex(const uint8_t* data, size_t length) {
+  static const char digits[] = "0123456789abcdef";
+  String hex;
+  hex.reserve(length * 2);
+  for (size_t i = 0; i < length; i++) {
+    hex += digits[data[i] >> 4];
+    hex += digits[data[i] & 0x0F];
+  }
+  return hex;
+}
+
 int APIClient::sendPostRequest(const char* endpoint, const String& payload, String& response) {
   int httpCode = -1;
   
@@ -295,6 +445,7 @@ int APIClient::sendPostRequest(const char* endpoint, const String& payload, Stri
     http.begin(wifiClient, url);
     http.setTimeout(API_TIMEOUT);  // AC2.9
     http.addHeader("Content-Type", "application/json");
+    addSignatureHeaders("POST", endpoint, payload);  // New nonce for every attempt
     
     httpCode = http.POST(payload);
     
@@ -331,6 +482,7 @@ int APIClient::sendGetRequest(const char* endpoint, String& response) {
     
     http.begin(wifiClient, url);
     http.setTimeout(API_TIMEOUT);
+    addSignatureHeaders("GET", endpoint, "");
     
     httpCode = http.GET();
     
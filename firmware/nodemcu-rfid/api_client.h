@@ -11,11 +11,16 @@
 #include <ESP8266HTTPClient.h>
 #include <WiFiClient.h>
 #include <ArduinoJson.h>
+#include "offline_queue.h"
+#include "time_manager.h"
 
 // API settings
 #define API_TIMEOUT     3000    // 3 seconds timeout (backend responds in <500ms normally)
 #define API_MAX_RETRIES 2       // Max retry attempts (reduced from 3 for faster failure)
 
+// Request signing headers (see backend device/signature.go)
+#define API_KEY_PREFIX_LENGTH 16  // Key ID sent instead of the key itself
+
 /**
  * Response structure for API key validation
  */
@@ -25,6 +30,7 @@ struct ValidationResponse {
   uint32_t deviceId;
   uint32_t schoolId;
   String message;
+  bool networkError;  // Backend not reachable, validation should be retried later
 };
 
 /**
@@ -36,6 +42,20 @@ struct AttendanceResponse {
   String status;      // on_time, late, very_late
   String message;
   String errorCode;   // Error code from backend (e.g., VAL_NO_SCHEDULE, VAL_INVALID_RFID)
+  bool networkError;  // Backend not reachable or failing, the tap can be buffered
+};
+
+/**
+ * Response structure for offline tap sync
+ */
+struct BatchSyncResponse {
+  bool success;
+  int recorded;
+  int rejected;
+  int failed;         // Retryable failures, kept in the queue
+  int doneCount;      // Number of IDs in doneIds
+  String doneIds[OFFLINE_SYNC_BATCH_SIZE]; // Taps the backend settled (recorded or rejected for good)
+  String message;
 };
 
 /**
@@ -59,6 +79,14 @@ public:
    */
   void begin(const char* serverUrl, const char* apiKey);
 
+  /**
+   * Sign requests with HMAC-SHA256, timestamp and nonce instead of sending the API key
+   * Requests stay unsigned while the time is not synced, since the backend rejects stale timestamps.
+   * @param signingSecret Signing secret shown with the API key
+   * @param timeManager Clock for the request timestamp
+   */
+  void enableSigning(const char* signingSecret, TimeManager* timeManager);
+
   /**
    * Validate API key with backend
    * @return ValidationResponse with result
@@ -72,6 +100,14 @@ public:
    */
   AttendanceResponse recordAttendance(const String& rfidCode);
 
+  /**
+   * Sync taps buffered while offline
+   * @param taps Buffered taps, at most OFFLINE_SYNC_BATCH_SIZE
+   * @param count Number of taps
+   * @return BatchSyncResponse with the taps that may be removed from the queue
+   */
+  BatchSyncResponse syncAttendanceBatch(const QueuedTap* taps, int count);
+
   /**
    * Process RFID pairing
    * @param rfidCode RFID card UID
@@ -104,6 +140,8 @@ public:
 private:
   String serverUrl;
   String apiKey;
+  String signingSecret;
+  TimeManager* timeManager;
   uint32_t deviceId;
   WiFiClient wifiClient;
   HTTPClient http;
@@ -115,6 +153,32 @@ private:
    */
   String buildURL(const char* endpoint);
 
+  /**
+   * Check if the next request can be signed
+   * @return true if a signing secret is set and the time is synced
+   */
+  bool canSign();
+
+  /**
+   * Add the API key to a payload of a request that cannot be signed
+   * @param doc JSON payload
+   */
+  void addAPIKey(JsonDocument& doc);
+
+  /**
+   * Add signature headers for the current request
+   * Signs METHOD\nPATH\nTIMESTAMP\nNONCE\nhex(SHA-256(body))
+   * @param method HTTP method
+   * @param endpoint API endpoint path (without query)
+   * @param body Request body
+   */
+  void addSignatureHeaders(const char* method, const char* endpoint, const String& body);
+
+  /**
+   * Encode bytes as lowercase hex
+   */
+  String toHex(const uint8_t* data, size_t length);
+
   /**
    * Send HTTP POST request with retry logic
    * @param endpoint API endpoint
@@ -25,6 +25,10 @@
 // Device API Key (dari web admin saat register device)
 #define API_KEY         "828c2b1b55befe80ad8a919ad7dfdcb8b6997a397b87b5f69425c49845cc29f4"
 
+// Signing Secret (ditampilkan bersama API key di web admin)
+// Request ditandatangani HMAC-SHA256 dengan secret ini; kosongkan hanya untuk device lama
+#define SIGNING_SECRET  ""
+
 // ============================================================
 // END KONFIGURASI - Jangan edit di bawah ini
 // ============================================================
@@ -3,7 +3,7 @@
  * 
  * Edit konfigurasi di config.h sebelum upload.
  * 
- * @version 2.1.0
+ * @version 2.3.0
  */
 
 #include <ESP8266WiFi.h>
@@ -17,6 +17,7 @@
 #include "wifi_manager.h"
 #include "time_manager.h"
 #include "api_client.h"
+#include "offline_queue.h"
 #include "state_machine.h"
 
 // Global instances
@@ -27,6 +28,7 @@ ConfigManager configManager;
 WiFiSetup wifiSetup;
 TimeManager timeManager;
 APIClient apiClient;
+OfflineQueue offlineQueue;
 StateMachine stateMachine;
 
 #define SERIAL_BAUD 115200
@@ -34,18 +36,26 @@ StateMachine stateMachine;
 #define CARD_COOLDOWN 1000           // 1 second cooldown between taps (backend handles duplicates)
 #define RESULT_DISPLAY_TIME 1500     // Show result for 1.5 seconds (enough to read)
 #define DISPLAY_UPDATE_INTERVAL 1000
+#define OFFLINE_SYNC_INTERVAL 5000   // Sync buffered taps every 5 seconds while online
+#define RECONNECT_INTERVAL 30000     // Retry time sync and API validation after an offline boot
 
 unsigned long lastPairingCheck = 0;
 unsigned long lastCardTap = 0;
 unsigned long lastDisplayUpdate = 0;
 unsigned long resultDisplayStart = 0;
+unsigned long lastOfflineSync = 0;
+unsigned long lastReconnectAttempt = 0;
 bool apiValidated = false;
+bool apiRejected = false;  // Backend answered that the API key is invalid
 
 // Forward declarations
 void checkPairingStatus();
 void handleCardTap();
 void handleAttendanceTap(const String& uid);
 void handlePairingTap(const String& uid);
+void bufferTap(const String& uid);
+void syncOfflineQueue();
+void reconnectServices();
 String mapStatusToIndonesian(const String& status);
 
 void setup() {
@@ -53,7 +63,7 @@ void setup() {
   Serial.println();
   Serial.println(F("================================"));
   Serial.println(F("NodeMCU RFID Attendance System"));
-  Serial.println(F("Version 2.1.0"));
+  Serial.println(F("Version 2.3.0"));
   Serial.println(F("================================"));
 
   // Show config
@@ -67,6 +77,11 @@ void setup() {
   displayManager.begin();
   buzzerManager.begin();
   rfidReader.begin();
+  offlineQueue.begin();
+
+  // Init API client (also when offline, so buffered taps can be synced later)
+  apiClient.begin(SERVER_URL, API_KEY);
+  apiClient.enableSigning(SIGNING_SECRET, &timeManager);
 
   // Connect WiFi
   displayManager.showMessage("Connecting...", WIFI_SSID);
@@ -79,9 +94,6 @@ void setup() {
     // Sync time
     timeManager.begin();
     
-    // Init API client
-    apiClient.begin(SERVER_URL, API_KEY);
-    
     // Validate API key
     displayManager.showMessage("Validating...", "API Key");
     ValidationResponse validation = apiClient.validateAPIKey();
@@ -97,6 +109,7 @@ void setup() {
     } else {
       Serial.print(F("[ERROR] API validation: "));
       Serial.println(validation.message);
+      apiRejected = !validation.networkError;
       displayManager.showError("API Invalid");
       buzzerManager.beepError();
       delay(2000);
@@ -124,6 +137,24 @@ void loop() {
 
   timeManager.update();
 
+  // Recover after booting offline or with the backend down
+  if (wifiSetup.isConnected() && (!timeManager.isSynced() || (!apiValidated && !apiRejected)) &&
+      stateMachine.getState() == DeviceState::IDLE) {
+    if (lastReconnectAttempt == 0 || millis() - lastReconnectAttempt > RECONNECT_INTERVAL) {
+      reconnectServices();
+      lastReconnectAttempt = millis();
+    }
+  }
+
+  // Sync taps buffered while offline
+  if (wifiSetup.isConnected() && offlineQueue.hasPending() && !apiRejected &&
+      stateMachine.getState() == DeviceState::IDLE) {
+    if (millis() - lastOfflineSync > OFFLINE_SYNC_INTERVAL) {
+      syncOfflineQueue();
+      lastOfflineSync = millis();
+    }
+  }
+
   // Check pairing status
   if (apiValidated && wifiSetup.isConnected()) {
     // Don't check pairing status while processing card to avoid WiFi conflicts
@@ -145,9 +176,13 @@ void loop() {
 
   // Update display
   if (stateMachine.getState() == DeviceState::IDLE || 
-      stateMachine.getState() == DeviceState::PAIRING_MODE) {
+      stateMachine.getState() == DeviceState::PAIRING_MODE ||
+      stateMachine.getState() == DeviceState::ERROR_WIFI) {
     if (millis() - lastDisplayUpdate > DISPLAY_UPDATE_INTERVAL) {
-      if (stateMachine.isInPairingMode()) {
+      if (stateMachine.getState() == DeviceState::ERROR_WIFI) {
+        String pending = String(offlineQueue.count()) + " antri";
+        displayManager.showMessage("Offline", pending.c_str());
+      } else if (stateMachine.isInPairingMode()) {
         displayManager.showPairingMode(stateMachine.getCurrentStudentName().c_str());
       } else {
         displayManager.showIdle(timeManager.isSynced() ? 
@@ -213,7 +248,7 @@ void handleCardTap() {
   stateMachine.setState(DeviceState::PROCESSING_CARD);
   displayManager.showMessage("Memproses...", uid.c_str());
   
-  if (!apiClient.isConfigured() || !apiValidated) {
+  if (!apiClient.isConfigured() || apiRejected) {
     displayManager.showMessage("Card UID:", uid.c_str());
     buzzerManager.beepSuccess();
     resultDisplayStart = millis();
@@ -222,8 +257,12 @@ void handleCardTap() {
   }
   
   if (!wifiSetup.isConnected()) {
-    displayManager.showError("WiFi Terputus");
-    buzzerManager.beepNetworkError();
+    if (wasInPairingMode) {
+      displayManager.showError("WiFi Terputus");
+      buzzerManager.beepNetworkError();
+    } else {
+      bufferTap(uid);
+    }
     resultDisplayStart = millis();
     stateMachine.setState(DeviceState::SHOWING_RESULT);
     return;
@@ -240,6 +279,15 @@ void handleCardTap() {
 }
 
 void handleAttendanceTap(const String& uid) {
+  // Older taps are still buffered; queue this one behind them so a check-out never reaches
+  // the backend before its check-in
+  if (offlineQueue.hasPending()) {
+    bufferTap(uid);
+    resultDisplayStart = millis();
+    stateMachine.setState(DeviceState::SHOWING_RESULT);
+    return;
+  }
+
   AttendanceResponse response = apiClient.recordAttendance(uid);
   
   if (response.success) {
@@ -273,6 +321,10 @@ void handleAttendanceTap(const String& uid) {
     buzzerManager.beepError();
     Serial.println(F("[WARN] Already checked out"));
   }
+  else if (response.networkError) {
+    // Backend not reachable, sync the tap later
+    bufferTap(uid);
+  }
   else if (response.errorCode.length() > 0) {
     // Other known error from backend
     displayManager.showError(response.message.c_str());
@@ -311,6 +363,77 @@ void handlePairingTap(const String& uid) {
   stateMachine.setState(DeviceState::SHOWING_RESULT);
 }
 
+void bufferTap(const String& uid) {
+  // Without NTP time the backend cannot place the tap in its schedule
+  if (!timeManager.isSynced()) {
+    displayManager.showError("Error Jaringan");
+    buzzerManager.beepNetworkError();
+    Serial.println(F("[ERROR] Offline before time sync, tap not buffered"));
+    return;
+  }
+
+  if (offlineQueue.enqueue(uid, timeManager.getUTCEpochTime())) {
+    displayManager.showMessage("Tersimpan", "Sinkron nanti");
+    buzzerManager.beepSuccess();
+    return;
+  }
+
+  displayManager.showError(offlineQueue.isFull() ? "Antrian Penuh" : "Gagal Simpan");
+  buzzerManager.beepError();
+  Serial.println(F("[ERROR] Tap not buffered"));
+}
+
+void syncOfflineQueue() {
+  QueuedTap taps[OFFLINE_SYNC_BATCH_SIZE];
+  int count = offlineQueue.peek(taps, OFFLINE_SYNC_BATCH_SIZE);
+  if (count == 0) return;
+
+  Serial.print(F("[QUEUE] Syncing "));
+  Serial.print(count);
+  Serial.print(F(" of "));
+  Serial.print(offlineQueue.count());
+  Serial.println(F(" buffered taps"));
+
+  BatchSyncResponse response = apiClient.syncAttendanceBatch(taps, count);
+  if (!response.success) {
+    Serial.print(F("[ERROR] Batch sync: "));
+    Serial.println(response.message);
+    return;
+  }
+
+  // Retryable taps stay in the queue and are sent again with the same ID
+  if (response.doneCount > 0) {
+    offlineQueue.remove(response.doneIds, response.doneCount);
+  }
+
+  Serial.print(F("[QUEUE] Recorded "));
+  Serial.print(response.recorded);
+  Serial.print(F(", rejected "));
+  Serial.print(response.rejected);
+  Serial.print(F(", retry "));
+  Serial.print(response.failed);
+  Serial.print(F(", pending "));
+  Serial.println(offlineQueue.count());
+}
+
+void reconnectServices() {
+  if (!timeManager.isSynced()) {
+    timeManager.begin();
+  }
+
+  if (!apiValidated && !apiRejected) {
+    ValidationResponse validation = apiClient.validateAPIKey();
+    if (validation.success && validation.valid) {
+      Serial.println(F("[OK] API key valid"));
+      apiValidated = true;
+    } else if (!validation.networkError) {
+      Serial.print(F("[ERROR] API validation: "));
+      Serial.println(validation.message);
+      apiRejected = true;
+    }
+  }
+}
+
 String mapStatusToIndonesian(const String& status) {
   if (status == "on_time") return F("Tepat Waktu");
   if (status == "late") return F("Terlambat");
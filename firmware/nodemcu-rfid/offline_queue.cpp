@@ -0,0 +1,159 @@
+/**
+ * OfflineQueue Implementation
+ *
+ * Taps are stored one per line as "id,rfid_code,timestamp", oldest first.
+ */
+
+#include "offline_queue.h"
+
+OfflineQueue::OfflineQueue() : initialized(false), tapCount(0) {
+}
+
+bool OfflineQueue::begin() {
+  if (!LittleFS.begin()) {
+    Serial.println(F("[ERROR] OfflineQueue: Failed to mount LittleFS"));
+    return false;
+  }
+
+  // A rewrite interrupted by a restart leaves the temp file behind; the queue file is still complete
+  if (LittleFS.exists(OFFLINE_QUEUE_TEMP_FILE)) {
+    LittleFS.remove(OFFLINE_QUEUE_TEMP_FILE);
+  }
+
+  tapCount = 0;
+  File file = LittleFS.open(OFFLINE_QUEUE_FILE, "r");
+  if (file) {
+    QueuedTap tap;
+    while (file.available()) {
+      if (parseLine(file.readStringUntil('\n'), tap)) {
+        tapCount++;
+      }
+    }
+    file.close();
+  }
+
+  initialized = true;
+  Serial.print(F("[OK] OfflineQueue initialized, "));
+  Serial.print(tapCount);
+  Serial.println(F(" taps pending"));
+  return true;
+}
+
+bool OfflineQueue::enqueue(const String& rfidCode, unsigned long timestamp) {
+  if (!initialized || isFull()) {
+    return false;
+  }
+
+  File file = LittleFS.open(OFFLINE_QUEUE_FILE, "a");
+  if (!file) {
+    Serial.println(F("[ERROR] OfflineQueue: Failed to open queue file"));
+    return false;
+  }
+
+  String id = generateTapId(timestamp);
+  String line = id + "," + rfidCode + "," + String(timestamp) + "\n";
+  size_t written = file.print(line);
+  file.close();
+
+  if (written != line.length()) {
+    Serial.println(F("[ERROR] OfflineQueue: Failed to write tap"));
+    return false;
+  }
+
+  tapCount++;
+  Serial.print(F("[QUEUE] Buffered tap "));
+  Serial.print(id);
+  Serial.print(F(" ("));
+  Serial.print(tapCount);
+  Serial.println(F(" pending)"));
+  return true;
+}
+
+int OfflineQueue::peek(QueuedTap* taps, int maxTaps) {
+  if (!initialized || tapCount == 0) {
+    return 0;
+  }
+
+  File file = LittleFS.open(OFFLINE_QUEUE_FILE, "r");
+  if (!file) {
+    return 0;
+  }
+
+  int read = 0;
+  while (read < maxTaps && file.available()) {
+    if (parseLine(file.readStringUntil('\n'), taps[read])) {
+      read++;
+    }
+  }
+  file.close();
+
+  return read;
+}
+
+bool OfflineQueue::remove(const String* ids, int count) {
+  if (!initialized || count == 0) {
+    return false;
+  }
+
+  File source = LittleFS.open(OFFLINE_QUEUE_FILE, "r");
+  if (!source) {
+    return false;
+  }
+  File target = LittleFS.open(OFFLINE_QUEUE_TEMP_FILE, "w");
+  if (!target) {
+    source.close();
+    Serial.println(F("[ERROR] OfflineQueue: Failed to create temp file"));
+    return false;
+  }
+
+  // Copy every tap except the removed ones
+  int kept = 0;
+  QueuedTap tap;
+  while (source.available()) {
+    String line = source.readStringUntil('\n');
+    if (!parseLine(line, tap)) {
+      continue;
+    }
+
+    bool removed = false;
+    for (int i = 0; i < count; i++) {
+      if (ids[i] == tap.id) {
+        removed = true;
+        break;
+      }
+    }
+    if (!removed) {
+      target.print(line);
+      target.print('\n');
+      kept++;
+    }
+  }
+  source.close();
+  target.close();
+
+  // Rename replaces the queue file in one step, so a restart never leaves it half written
+  if (!LittleFS.rename(OFFLINE_QUEUE_TEMP_FILE, OFFLINE_QUEUE_FILE)) {
+    Serial.println(F("[ERROR] OfflineQueue: Failed to replace queue file"));
+    return false;
+  }
+
+  tapCount = kept;
+  return true;
+}
+
+String OfflineQueue::generateTapId(unsigned long timestamp) {
+  return String(ESP.getChipId(), HEX) + "-" + String(timestamp, HEX) + "-" + String(ESP.random(), HEX);
+}
+
+bool OfflineQueue::parseLine(const String& line, QueuedTap& tap) {
+  int first = line.indexOf(',');
+  int second = line.indexOf(',', first + 1);
+  if (first <= 0 || second <= first + 1) {
+    return false;
+  }
+
+  tap.id = line.substring(0, first);
+  tap.rfidCode = line.substring(first + 1, second);
+  tap.timestamp = strtoul(line.substring(second + 1).c_str(), nullptr, 10);
+  return tap.timestamp > 0;
+}
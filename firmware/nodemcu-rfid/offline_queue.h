@@ -0,0 +1,101 @@
+/**
+ * OfflineQueue - Persistent buffer for attendance taps
+ *
+ * Stores taps in LittleFS while the backend is unreachable so they
+ * survive a restart, and hands them to the batch sync endpoint once
+ * the device is back online. Each tap gets an ID generated on the
+ * device, which the backend uses as idempotency key, so resending a
+ * tap after a lost response never records it twice.
+ */
+
+#ifndef OFFLINE_QUEUE_H
+#define OFFLINE_QUEUE_H
+
+#include <Arduino.h>
+#include <LittleFS.h>
+
+// Queue settings
+#define OFFLINE_QUEUE_FILE      "/taps.csv"
+#define OFFLINE_QUEUE_TEMP_FILE "/taps.tmp"
+#define OFFLINE_QUEUE_MAX_TAPS  500   // ~25KB of flash; new taps are refused when full
+#define OFFLINE_SYNC_BATCH_SIZE 10    // Taps per sync request (keeps the response small for the heap)
+
+/**
+ * A buffered tap
+ */
+struct QueuedTap {
+  String id;               // Idempotency key, unique per tap
+  String rfidCode;         // RFID card UID
+  unsigned long timestamp; // Unix time of the tap (UTC)
+};
+
+class OfflineQueue {
+public:
+  OfflineQueue();
+
+  /**
+   * Mount LittleFS and count the taps left from before a restart
+   * @return true if the queue is usable
+   */
+  bool begin();
+
+  /**
+   * Append a tap to the queue
+   * @param rfidCode RFID card UID
+   * @param timestamp Unix time of the tap (UTC)
+   * @return true if stored, false if the queue is full or the write failed
+   */
+  bool enqueue(const String& rfidCode, unsigned long timestamp);
+
+  /**
+   * Read the oldest taps without removing them
+   * @param taps Output array
+   * @param maxTaps Size of the output array
+   * @return Number of taps read
+   */
+  int peek(QueuedTap* taps, int maxTaps);
+
+  /**
+   * Remove synced taps from the queue
+   * @param ids IDs of the taps to remove
+   * @param count Number of IDs
+   * @return true if the queue file was rewritten
+   */
+  bool remove(const String* ids, int count);
+
+  /**
+   * Get number of buffered taps
+   */
+  int count() { return tapCount; }
+
+  /**
+   * Check if there are taps waiting to be synced
+   */
+  bool hasPending() { return tapCount > 0; }
+
+  /**
+   * Check if the queue cannot take more taps
+   */
+  bool isFull() { return tapCount >= OFFLINE_QUEUE_MAX_TAPS; }
+
+private:
+  bool initialized;
+  int tapCount;
+
+  /**
+   * Generate a tap ID from the chip ID, tap time and a hardware random number
+   * @param timestamp Unix time of the tap
+   * @return Tap ID (max 26 characters)
+   */
+  String generateTapId(unsigned long timestamp);
+
+  /**
+   * Parse a queue file line ("id,rfid_code,timestamp")
+   * @param line Line without newline
+   * @param tap Output tap
+   * @return true if the line is valid
+   */
+  bool parseLine(const String& line, QueuedTap& tap);
+};
+
+#endif // OFFLINE_QUEUE_H
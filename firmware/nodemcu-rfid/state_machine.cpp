@@ -89,6 +89,8 @@ bool StateMachine::isInErrorState() {
 }
 
 bool StateMachine::isReadyForCard() {
+  // Taps are buffered while WiFi is down
   return currentState == DeviceState::IDLE || 
-         currentState == DeviceState::PAIRING_MODE;
+         currentState == DeviceState::PAIRING_MODE ||
+         currentState == DeviceState::ERROR_WIFI;
 }
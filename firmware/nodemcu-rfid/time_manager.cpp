@@ -18,6 +18,12 @@ TimeManager::~TimeManager() {
 }
 
 void TimeManager::begin() {
+  // Already started, e.g. when WiFi comes back after an offline boot
+  if (timeClient != nullptr) {
+    forceSync();
+    return;
+  }
+
   // Create UDP instance
   ntpUDP = new WiFiUDP();
   
@@ -127,6 +133,11 @@ unsigned long TimeManager::getEpochTime() {
   return timeClient->getEpochTime();
 }
 
+unsigned long TimeManager::getUTCEpochTime() {
+  if (timeClient == nullptr) return 0;
+  return timeClient->getEpochTime() - NTP_UTC_OFFSET_WITA;
+}
+
 String TimeManager::padZero(int num) {
   if (num < 10) {
     return "0" + String(num);
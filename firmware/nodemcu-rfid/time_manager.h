@@ -84,6 +84,12 @@ public:
    */
   unsigned long getEpochTime();
 
+  /**
+   * Get epoch time without the WITA offset
+   * @return Unix timestamp (UTC), as sent to the backend
+   */
+  unsigned long getUTCEpochTime();
+
 private:
   WiFiUDP* ntpUDP;
   NTPClient* timeClient;
@@ -286,6 +286,7 @@ class AppStrings {
 
   static const String notificationAttendanceIn = 'Kehadiran Masuk';
   static const String notificationAttendanceOut = 'Kehadiran Pulang';
+  static const String notificationAttendanceAbsent = 'Ketidakhadiran';
   static const String notificationViolation = 'Pelanggaran';
   static const String notificationAchievement = 'Prestasi';
   static const String notificationPermit = 'Izin Keluar';
@@ -349,6 +350,8 @@ class AppStrings {
         return notificationAttendanceIn;
       case 'attendance_out':
         return notificationAttendanceOut;
+      case 'attendance_absent':
+        return notificationAttendanceAbsent;
       case 'violation':
         return notificationViolation;
       case 'achievement':
@@ -1,5 +1,5 @@
 import api from './api'
-import type { LoginRequest, LoginResponse, TokenPair, User } from '@/types/user'
+import type { LoginRequest, LoginResponse, TokenPair, TwoFactorChallenge, TwoFactorSetup, User } from '@/types/user'
 
 // API response wrapper
 interface ApiResponse<T> {
@@ -28,6 +28,28 @@ interface BackendLoginResponse {
     must_reset_pwd: boolean
     last_login_at: string | null
   }
+  recovery_codes?: string[]
+}
+
+// Backend answer to a correct password when 2FA is needed
+interface BackendTwoFactorChallenge {
+  two_factor_required: true
+  challenge_token: string
+  expires_in: number
+  enrollment_required: boolean
+}
+
+export function isTwoFactorChallenge(result: LoginResponse | TwoFactorChallenge): result is TwoFactorChallenge {
+  return 'challengeToken' in result
+}
+
+function transformLogin(data: BackendLoginResponse): LoginResponse {
+  return {
+    accessToken: data.access_token,
+    refreshToken: data.refresh_token,
+    user: transformUser(data.user),
+    recoveryCodes: data.recovery_codes,
+  }
 }
 
 // Transform backend user to frontend user
@@ -47,8 +69,8 @@ function transformUser(backendUser: BackendLoginResponse['user']): User {
 }
 
 export const authService = {
-  async login(credentials: LoginRequest): Promise<LoginResponse> {
-    const response = await api.post<ApiResponse<BackendLoginResponse>>('/auth/login', credentials)
+  async login(credentials: LoginRequest): Promise<LoginResponse | TwoFactorChallenge> {
+    const response = await api.post<ApiResponse<BackendLoginResponse | BackendTwoFactorChallenge>>('/auth/login', credentials)
     
     console.log('Raw API response:', response.data)
     
@@ -59,11 +81,33 @@ export const authService = {
     }
     
     const data = response.data.data
+    if ('two_factor_required' in data) {
+      return {
+        challengeToken: data.challenge_token,
+        expiresIn: data.expires_in,
+        enrollmentRequired: data.enrollment_required,
+      }
+    }
     
+    return transformLogin(data)
+  },
+
+  async verifyTwoFactor(challengeToken: string, code: string): Promise<LoginResponse> {
+    const response = await api.post<ApiResponse<BackendLoginResponse>>('/auth/2fa/verify', {
+      challenge_token: challengeToken,
+      code,
+    })
+    return transformLogin(response.data.data)
+  },
+
+  // Starts enrollment when 2FA is required but not set up yet
+  async enrollTwoFactor(challengeToken: string): Promise<TwoFactorSetup> {
+    const response = await api.post<ApiResponse<{ secret: string; otpauth_uri: string }>>('/auth/2fa/enroll', {
+      challenge_token: challengeToken,
+    })
     return {
-      accessToken: data.access_token,
-      refreshToken: data.refresh_token,
-      user: transformUser(data.user),
+      secret: response.data.data.secret,
+      otpauthUri: response.data.data.otpauth_uri,
     }
   },
 
@@ -81,7 +125,8 @@ export const authService = {
   },
 
   async logout(): Promise<void> {
-    await api.post('/auth/logout')
+    // Ends this device's session on the server so its refresh token stops working
+    await api.post('/auth/logout', { refresh_token: localStorage.getItem('refreshToken') ?? '' })
   },
 
   async changePassword(oldPassword: string, newPassword: string): Promise<void> {
@@ -25,9 +25,14 @@ function transformDevice(data: Record<string, unknown>): Device {
     schoolName: data.school_name as string | undefined,
     deviceCode: data.device_code as string,
     apiKey: data.api_key as string || '',
+    signingSecret: data.signing_secret as string | undefined,
     description: data.description as string | undefined,
     isActive: data.is_active as boolean,
     lastSeenAt: data.last_seen_at as string | undefined,
+    isOnline: data.is_online as boolean | undefined,
+    status: data.status as Device['status'],
+    firmwareVersion: data.firmware_version as string | undefined,
+    lastHeartbeatAt: data.last_heartbeat_at as string | undefined,
     createdAt: data.created_at as string,
     updatedAt: data.updated_at as string,
   }
@@ -152,7 +157,8 @@ export const deviceService = {
         deviceId: result.data.device_id,
         deviceCode: result.data.device_code,
         schoolName: result.data.school_name,
-        apiKey: result.data.api_key,
+        // Only the key prefix is available; the full key is shown once on creation/regeneration
+        apiKey: result.data.api_key_prefix ? `${result.data.api_key_prefix}...` : '',
       }
     }
     throw new Error(result.error?.message || 'Failed to get API key')
@@ -166,12 +172,7 @@ export const deviceService = {
       if (result.success && result.data) {
         const devices = result.data.devices || []
         const activeDevices = devices.filter((d: Record<string, unknown>) => d.is_active).length
-        const now = new Date()
-        const fiveMinutesAgo = new Date(now.getTime() - 5 * 60 * 1000)
-        const onlineDevices = devices.filter((d: Record<string, unknown>) => {
-          if (!d.is_active || !d.last_seen_at) return false
-          return new Date(d.last_seen_at as string) > fiveMinutesAgo
-        }).length
+        const onlineDevices = devices.filter((d: Record<string, unknown>) => d.is_online).length
         return {
           totalDevices: devices.length,
           activeDevices,
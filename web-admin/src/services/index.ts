@@ -1,6 +1,6 @@
 export { default as api, getApiErrorMessage, isNetworkError, isTimeoutError } from './api'
 export type { ApiError } from './api'
-export { authService } from './auth'
+export { authService, isTwoFactorChallenge } from './auth'
 export { tenantService } from './tenant'
 export { deviceService } from './device'
 export { schoolService } from './school'
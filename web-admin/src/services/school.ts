@@ -301,9 +301,16 @@ function transformDevice(apiDevice: DeviceApiResponse): Device {
 // Backend response types for Pairing (snake_case)
 interface PairingSessionApiResponse {
   active: boolean
+  mode?: 'single' | 'bulk'
   student_id?: number
   student_name?: string
   device_id?: number
+  class_id?: number
+  class_name?: string
+  position?: number
+  total?: number
+  paired?: number
+  skipped?: number
   expires_at?: string
   message: string
 }
@@ -312,9 +319,16 @@ interface PairingSessionApiResponse {
 function transformPairingSession(apiResponse: PairingSessionApiResponse): PairingSessionResponse {
   return {
     active: apiResponse.active,
+    mode: apiResponse.mode,
     studentId: apiResponse.student_id,
     studentName: apiResponse.student_name,
     deviceId: apiResponse.device_id,
+    classId: apiResponse.class_id,
+    className: apiResponse.class_name,
+    position: apiResponse.position,
+    total: apiResponse.total,
+    paired: apiResponse.paired,
+    skipped: apiResponse.skipped,
     expiresAt: apiResponse.expires_at,
     message: apiResponse.message,
   }
@@ -623,6 +637,19 @@ export const schoolService = {
     return transformPairingSession(response.data.data)
   },
 
+  async startBulkPairing(deviceId: number, classId: number): Promise<PairingSessionResponse> {
+    const response = await api.post<ApiResponse<PairingSessionApiResponse>>('/pairing/bulk/start', {
+      device_id: deviceId,
+      class_id: classId,
+    })
+    return transformPairingSession(response.data.data)
+  },
+
+  async skipPairingStudent(deviceId: number): Promise<PairingSessionResponse> {
+    const response = await api.post<ApiResponse<PairingSessionApiResponse>>(`/pairing/skip/${deviceId}`)
+    return transformPairingSession(response.data.data)
+  },
+
   async cancelPairing(deviceId: number): Promise<void> {
     await api.post(`/pairing/cancel/${deviceId}`)
   },
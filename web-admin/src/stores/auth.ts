@@ -1,7 +1,7 @@
 import { defineStore } from 'pinia'
 import { ref, computed } from 'vue'
-import type { User, LoginRequest, LoginResponse, TokenPair } from '@/types/user'
-import { authService } from '@/services'
+import type { User, LoginRequest, LoginResponse, TokenPair, TwoFactorChallenge } from '@/types/user'
+import { authService, isTwoFactorChallenge } from '@/services'
 
 export const useAuthStore = defineStore('auth', () => {
   const user = ref<User | null>(null)
@@ -53,10 +53,13 @@ export const useAuthStore = defineStore('auth', () => {
     }
   }
 
-  async function login(credentials: LoginRequest): Promise<LoginResponse> {
+  async function login(credentials: LoginRequest): Promise<LoginResponse | TwoFactorChallenge> {
     isLoading.value = true
     try {
       const response = await authService.login(credentials)
+      if (isTwoFactorChallenge(response)) {
+        return response
+      }
       setTokens(response.accessToken, response.refreshToken)
       setUser(response.user)
       return response
@@ -4,9 +4,14 @@ export interface Device {
   schoolName?: string
   deviceCode: string
   apiKey: string
+  signingSecret?: string // Only returned on creation/regeneration, flashed into the device with the key
   description?: string
   isActive: boolean
   lastSeenAt?: string
+  isOnline?: boolean
+  status?: 'online' | 'offline' | 'inactive'
+  firmwareVersion?: string
+  lastHeartbeatAt?: string
   createdAt: string
   updatedAt: string
 }
@@ -282,9 +282,16 @@ export interface Device {
 // Pairing types
 export interface PairingSessionResponse {
   active: boolean
+  mode?: 'single' | 'bulk'
   studentId?: number
   studentName?: string
   deviceId?: number
+  classId?: number
+  className?: string
+  position?: number
+  total?: number
+  paired?: number
+  skipped?: number
   expiresAt?: string
   message: string
 }
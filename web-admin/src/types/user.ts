@@ -22,6 +22,19 @@ export interface LoginResponse {
   user: User
   accessToken: string
   refreshToken: string
+  recoveryCodes?: string[] // Only after 2FA enrollment finished during login
+}
+
+// Returned by login instead of tokens when a second factor is needed
+export interface TwoFactorChallenge {
+  challengeToken: string
+  expiresIn: number
+  enrollmentRequired: boolean
+}
+
+export interface TwoFactorSetup {
+  secret: string
+  otpauthUri: string
 }
 
 export interface TokenPair {